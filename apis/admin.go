@@ -1,13 +1,13 @@
 package apis
 
 import (
-	"log"
 	"net/http"
 
 	"github.com/labstack/echo/v5"
 	"github.com/unkod/space/core"
 	"github.com/unkod/space/forms"
 	"github.com/unkod/space/models"
+	"github.com/unkod/space/models/settings"
 	"github.com/unkod/space/tokens"
 	"github.com/unkod/space/tools/routine"
 	"github.com/unkod/space/tools/search"
@@ -17,11 +17,15 @@ import (
 func bindAdminApi(app core.App, rg *echo.Group) {
 	api := adminApi{app: app}
 
-	subGroup := rg.Group("/admins", ActivityLogger(app))
-	subGroup.POST("/auth-with-password", api.authWithPassword)
-	subGroup.POST("/request-password-reset", api.requestPasswordReset)
-	subGroup.POST("/confirm-password-reset", api.confirmPasswordReset)
+	subGroup := rg.Group("/admins", ActivityLogger(app), AdminIpFilter(app))
+	subGroup.POST("/auth-with-password", api.authWithPassword, RateLimit(app, RateLimitLabelAuth))
+	subGroup.POST("/auth-with-mfa", api.authWithMFA, RateLimit(app, RateLimitLabelAuth))
+	subGroup.POST("/request-password-reset", api.requestPasswordReset, RateLimit(app, RateLimitLabelAuth))
+	subGroup.POST("/confirm-password-reset", api.confirmPasswordReset, RateLimit(app, RateLimitLabelAuth))
 	subGroup.POST("/auth-refresh", api.authRefresh, RequireAdminAuth())
+	subGroup.POST("/mfa/enroll", api.mfaEnroll, RequireAdminAuth())
+	subGroup.POST("/mfa/confirm", api.mfaConfirm, RequireAdminAuth(), RateLimit(app, RateLimitLabelAuth))
+	subGroup.POST("/mfa/disable", api.mfaDisable, RequireAdminAuth(), RateLimit(app, RateLimitLabelAuth))
 	subGroup.GET("", api.list, RequireAdminAuth())
 	subGroup.POST("", api.create, RequireAdminAuthOnlyIfAny(app))
 	subGroup.GET("/:id", api.view, RequireAdminAuth())
@@ -85,6 +89,15 @@ func (api *adminApi) authWithPassword(c echo.Context) error {
 		return NewBadRequestError("An error occurred while loading the submitted data.", err)
 	}
 
+	securityEvent := newSecurityEvent(api.app, c, settings.SecurityEventAdminAuth)
+	securityEvent.Actor.Identity = form.Identity
+
+	lock := newLoginLockout(api.app, c, loginLockoutAdminsScope, form.Identity)
+	if err := lock.check(); err != nil {
+		securityEvent.send(api.app, SecurityEventOutcomeFailure, map[string]any{"reason": "lockout"})
+		return err
+	}
+
 	event := new(core.AdminAuthWithPasswordEvent)
 	event.HttpContext = c
 	event.Password = form.Password
@@ -96,10 +109,21 @@ func (api *adminApi) authWithPassword(c echo.Context) error {
 
 			return api.app.OnAdminBeforeAuthWithPasswordRequest().Trigger(event, func(e *core.AdminAuthWithPasswordEvent) error {
 				if err := next(e.Admin); err != nil {
+					lock.fail()
+					securityEvent.send(api.app, SecurityEventOutcomeFailure, map[string]any{"reason": "invalidCredentials"})
 					return NewBadRequestError("Failed to authenticate.", err)
 				}
 
+				lock.reset()
+
+				securityEvent.setAdmin(e.Admin)
+				securityEvent.send(api.app, SecurityEventOutcomeSuccess, map[string]any{"mfaRequired": e.Admin.MFAEnabled})
+
 				return api.app.OnAdminAfterAuthWithPasswordRequest().Trigger(event, func(e *core.AdminAuthWithPasswordEvent) error {
+					if e.Admin.MFAEnabled {
+						return api.mfaChallengeResponse(e.HttpContext, e.Admin)
+					}
+
 					return api.authResponse(e.HttpContext, e.Admin)
 				})
 			})
@@ -109,6 +133,96 @@ func (api *adminApi) authWithPassword(c echo.Context) error {
 	return submitErr
 }
 
+// mfaChallengeResponse writes a 401 response with the MFA challenge
+// id that is required to complete the authentication with [adminApi.authWithMFA].
+func (api *adminApi) mfaChallengeResponse(c echo.Context, admin *models.Admin) error {
+	mfaId, err := tokens.NewAdminMFAToken(api.app, admin)
+	if err != nil {
+		return NewBadRequestError("Failed to create MFA challenge.", err)
+	}
+
+	return c.JSON(http.StatusUnauthorized, map[string]any{
+		"mfaId": mfaId,
+	})
+}
+
+func (api *adminApi) authWithMFA(c echo.Context) error {
+	form := forms.NewAdminMFALogin(api.app)
+	if err := c.Bind(form); err != nil {
+		return NewBadRequestError("An error occurred while loading the submitted data.", err)
+	}
+
+	lock := newMFALoginLockout(api.app, c, loginLockoutAdminsScope, form.MfaId)
+	if err := lock.check(); err != nil {
+		return err
+	}
+
+	admin, err := form.Submit()
+	if err != nil {
+		if forms.IsInvalidMFACodeError(err) {
+			lock.fail()
+		}
+		return NewBadRequestError("Failed to authenticate.", err)
+	}
+
+	lock.reset()
+
+	return api.authResponse(c, admin)
+}
+
+func (api *adminApi) mfaEnroll(c echo.Context) error {
+	admin, _ := c.Get(ContextAdminKey).(*models.Admin)
+	if admin == nil {
+		return NewNotFoundError("Missing auth admin context.", nil)
+	}
+
+	enrollment, err := forms.NewAdminMFAEnroll(api.app, admin).Submit()
+	if err != nil {
+		return NewBadRequestError("Failed to enroll MFA.", err)
+	}
+
+	return c.JSON(http.StatusOK, enrollment)
+}
+
+func (api *adminApi) mfaConfirm(c echo.Context) error {
+	admin, _ := c.Get(ContextAdminKey).(*models.Admin)
+	if admin == nil {
+		return NewNotFoundError("Missing auth admin context.", nil)
+	}
+
+	form := forms.NewAdminMFAConfirm(api.app, admin)
+	if err := c.Bind(form); err != nil {
+		return NewBadRequestError("An error occurred while loading the submitted data.", err)
+	}
+
+	backupCodes, err := form.Submit()
+	if err != nil {
+		return NewBadRequestError("Failed to confirm MFA.", err)
+	}
+
+	return c.JSON(http.StatusOK, map[string]any{
+		"backupCodes": backupCodes,
+	})
+}
+
+func (api *adminApi) mfaDisable(c echo.Context) error {
+	admin, _ := c.Get(ContextAdminKey).(*models.Admin)
+	if admin == nil {
+		return NewNotFoundError("Missing auth admin context.", nil)
+	}
+
+	form := forms.NewAdminMFADisable(api.app, admin)
+	if err := c.Bind(form); err != nil {
+		return NewBadRequestError("An error occurred while loading the submitted data.", err)
+	}
+
+	if err := form.Submit(); err != nil {
+		return NewBadRequestError("Failed to disable MFA.", err)
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
 func (api *adminApi) requestPasswordReset(c echo.Context) error {
 	form := forms.NewAdminPasswordResetRequest(api.app)
 	if err := c.Bind(form); err != nil {
@@ -129,9 +243,8 @@ func (api *adminApi) requestPasswordReset(c echo.Context) error {
 			return api.app.OnAdminBeforeRequestPasswordResetRequest().Trigger(event, func(e *core.AdminRequestPasswordResetEvent) error {
 				// run in background because we don't need to show the result to the client
 				routine.FireAndForget(func() {
-					if err := next(e.Admin); err != nil && api.app.IsDebug() {
-						// @todo replace after logs generalization
-						log.Println(err)
+					if err := next(e.Admin); err != nil {
+						api.app.Logger().Debug("Failed to send admin password reset email", "error", err)
 					}
 				})
 
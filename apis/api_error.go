@@ -14,6 +14,9 @@ type ApiError struct {
 	Message string         `json:"message"`
 	Data    map[string]any `json:"data"`
 
+	// RequestId is the id of the failed request (see [RequestIdHeader]).
+	RequestId string `json:"requestId,omitempty"`
+
 	// stores unformatted error data (could be an internal error, text, etc.)
 	rawData any
 }
@@ -64,6 +67,33 @@ func NewUnauthorizedError(message string, data any) *ApiError {
 	return NewApiError(http.StatusUnauthorized, message, data)
 }
 
+// NewRequestEntityTooLargeError creates and returns 413 `ApiError`.
+func NewRequestEntityTooLargeError(message string, data any) *ApiError {
+	if message == "" {
+		message = "The request body is too large."
+	}
+
+	return NewApiError(http.StatusRequestEntityTooLarge, message, data)
+}
+
+// NewConflictError creates and returns 409 `ApiError`.
+func NewConflictError(message string, data any) *ApiError {
+	if message == "" {
+		message = "The request conflicts with the current state of the resource."
+	}
+
+	return NewApiError(http.StatusConflict, message, data)
+}
+
+// NewServiceUnavailableError creates and returns 503 `ApiError`.
+func NewServiceUnavailableError(message string, data any) *ApiError {
+	if message == "" {
+		message = "The service is temporarily unavailable."
+	}
+
+	return NewApiError(http.StatusServiceUnavailable, message, data)
+}
+
 // NewApiError creates and returns new normalized `ApiError` instance.
 func NewApiError(status int, message string, data any) *ApiError {
 	return &ApiError{
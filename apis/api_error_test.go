@@ -148,3 +148,66 @@ func TestNewUnauthorizedError(t *testing.T) {
 		}
 	}
 }
+
+func TestNewRequestEntityTooLargeError(t *testing.T) {
+	scenarios := []struct {
+		message  string
+		data     any
+		expected string
+	}{
+		{"", nil, `{"code":413,"message":"The request body is too large.","data":{}}`},
+		{"demo", "rawData_test", `{"code":413,"message":"Demo.","data":{}}`},
+		{"demo", validation.Errors{"err1": validation.NewError("test_code", "test_message")}, `{"code":413,"message":"Demo.","data":{"err1":{"code":"test_code","message":"Test_message."}}}`},
+	}
+
+	for i, scenario := range scenarios {
+		e := apis.NewRequestEntityTooLargeError(scenario.message, scenario.data)
+		result, _ := json.Marshal(e)
+
+		if string(result) != scenario.expected {
+			t.Errorf("(%d) Expected \n%v, \ngot \n%v", i, scenario.expected, string(result))
+		}
+	}
+}
+
+func TestNewConflictError(t *testing.T) {
+	scenarios := []struct {
+		message  string
+		data     any
+		expected string
+	}{
+		{"", nil, `{"code":409,"message":"The request conflicts with the current state of the resource.","data":{}}`},
+		{"demo", "rawData_test", `{"code":409,"message":"Demo.","data":{}}`},
+		{"demo", validation.Errors{"err1": validation.NewError("test_code", "test_message")}, `{"code":409,"message":"Demo.","data":{"err1":{"code":"test_code","message":"Test_message."}}}`},
+	}
+
+	for i, scenario := range scenarios {
+		e := apis.NewConflictError(scenario.message, scenario.data)
+		result, _ := json.Marshal(e)
+
+		if string(result) != scenario.expected {
+			t.Errorf("(%d) Expected \n%v, \ngot \n%v", i, scenario.expected, string(result))
+		}
+	}
+}
+
+func TestNewServiceUnavailableError(t *testing.T) {
+	scenarios := []struct {
+		message  string
+		data     any
+		expected string
+	}{
+		{"", nil, `{"code":503,"message":"The service is temporarily unavailable.","data":{}}`},
+		{"demo", "rawData_test", `{"code":503,"message":"Demo.","data":{}}`},
+		{"demo", validation.Errors{"err1": validation.NewError("test_code", "test_message")}, `{"code":503,"message":"Demo.","data":{"err1":{"code":"test_code","message":"Test_message."}}}`},
+	}
+
+	for i, scenario := range scenarios {
+		e := apis.NewServiceUnavailableError(scenario.message, scenario.data)
+		result, _ := json.Marshal(e)
+
+		if string(result) != scenario.expected {
+			t.Errorf("(%d) Expected \n%v, \ngot \n%v", i, scenario.expected, string(result))
+		}
+	}
+}
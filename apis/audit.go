@@ -0,0 +1,118 @@
+package apis
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo/v5"
+	"github.com/unkod/space/core"
+	"github.com/unkod/space/models"
+	"github.com/unkod/space/tools/search"
+)
+
+// bindAuditApi registers the records audit trail api endpoints and
+// the request hooks that set the audit actor of the records api changes.
+//
+// Note that the audit logs themselves are stored by the Dao record
+// save/delete methods, so the changes outside of the api (Go code,
+// console commands, migrations) are audited with the "system" actor.
+func bindAuditApi(app core.App, rg *echo.Group) {
+	api := auditApi{app: app}
+
+	rg.GET(
+		"/collections/:collection/records/:id/audit",
+		api.history,
+		ActivityLogger(app),
+		RequireAdminAuth(),
+		LoadCollectionContext(app, models.CollectionTypeBase, models.CollectionTypeAuth),
+	)
+
+	api.bindEvents()
+}
+
+type auditApi struct {
+	app core.App
+}
+
+var auditFilterFields = []string{
+	"id", "created", "action", "actorType", "actorId",
+}
+
+func (api *auditApi) history(c echo.Context) error {
+	collection, _ := c.Get(ContextCollectionKey).(*models.Collection)
+	if collection == nil {
+		return NewNotFoundError("", "Missing collection context.")
+	}
+
+	recordId := c.PathParam("id")
+	if recordId == "" {
+		return NewNotFoundError("", nil)
+	}
+
+	fieldResolver := search.NewSimpleFieldResolver(auditFilterFields...)
+
+	provider := search.NewProvider(fieldResolver).
+		Query(api.app.Dao().WithContext(c.Request().Context()).RecordAuditLogQuery(collection.Id, recordId))
+
+	// by default list the changes in chronological order
+	if c.QueryParam(search.SortQueryParam) == "" {
+		provider.AddSort(search.SortField{Name: "created", Direction: search.SortAsc})
+	}
+
+	result, err := provider.ParseAndExec(c.QueryParams().Encode(), &[]*models.AuditLog{})
+	if err != nil {
+		return NewBadRequestError("", err)
+	}
+
+	return c.JSON(http.StatusOK, result)
+}
+
+func (api *auditApi) bindEvents() {
+	// track the actors of the records api changes
+	api.app.OnRecordBeforeCreateRequest().PreAdd(func(e *core.RecordCreateEvent) error {
+		api.trackActor(e.HttpContext, e.Record)
+		return nil
+	})
+
+	api.app.OnRecordBeforeBulkCreateRequest().PreAdd(func(e *core.RecordsBulkCreateEvent) error {
+		for _, record := range e.Records {
+			api.trackActor(e.HttpContext, record)
+		}
+		return nil
+	})
+
+	api.app.OnRecordBeforeUpdateRequest().PreAdd(func(e *core.RecordUpdateEvent) error {
+		api.trackActor(e.HttpContext, e.Record)
+		return nil
+	})
+
+	api.app.OnRecordBeforeDeleteRequest().PreAdd(func(e *core.RecordDeleteEvent) error {
+		api.trackActor(e.HttpContext, e.Record)
+		return nil
+	})
+
+	api.app.OnRecordBeforeSoftDeleteRequest().PreAdd(func(e *core.RecordSoftDeleteEvent) error {
+		api.trackActor(e.HttpContext, e.Record)
+		return nil
+	})
+
+	api.app.OnRecordBeforeRestoreRequest().PreAdd(func(e *core.RecordRestoreEvent) error {
+		api.trackActor(e.HttpContext, e.Record)
+		return nil
+	})
+}
+
+// trackActor sets the current request auth state as the audit actor
+// of the provided record.
+func (api *auditApi) trackActor(c echo.Context, record *models.Record) {
+	if record == nil || record.Collection() == nil || !record.Collection().HasAudit() {
+		return
+	}
+
+	if admin, _ := c.Get(ContextAdminKey).(*models.Admin); admin != nil {
+		record.SetAuditActor(models.AuditActorAdmin, admin.Id)
+	} else if authRecord, _ := c.Get(ContextAuthRecordKey).(*models.Record); authRecord != nil {
+		record.SetAuditActor(models.AuditActorRecord, authRecord.Id)
+	} else {
+		record.SetAuditActor(models.AuditActorGuest, "")
+	}
+}
@@ -2,7 +2,8 @@ package apis
 
 import (
 	"context"
-	"log"
+	"errors"
+	"fmt"
 	"net/http"
 	"path/filepath"
 	"time"
@@ -11,8 +12,7 @@ import (
 	"github.com/spf13/cast"
 	"github.com/unkod/space/core"
 	"github.com/unkod/space/forms"
-	"github.com/unkod/space/models"
-	"github.com/unkod/space/tools/types"
+	"github.com/unkod/space/models/settings"
 )
 
 // bindBackupApi registers the file api endpoints and the corresponding handlers.
@@ -21,7 +21,8 @@ import (
 func bindBackupApi(app core.App, rg *echo.Group) {
 	api := backupApi{app: app}
 
-	subGroup := rg.Group("/backups", ActivityLogger(app))
+	// backups could take a while to be created, uploaded or restored
+	subGroup := rg.Group("/backups", ActivityLogger(app), RequestTimeout(0))
 	subGroup.GET("", api.list, RequireAdminAuth())
 	subGroup.POST("", api.create, RequireAdminAuth())
 	subGroup.GET("/:key", api.download)
@@ -45,24 +46,12 @@ func (api *backupApi) list(c echo.Context) error {
 
 	fsys.SetContext(ctx)
 
-	backups, err := fsys.List("")
+	backups, err := core.ListBackups(fsys, "")
 	if err != nil {
 		return NewBadRequestError("Failed to retrieve backup items. Raw error: \n"+err.Error(), nil)
 	}
 
-	result := make([]models.BackupFileInfo, len(backups))
-
-	for i, obj := range backups {
-		modified, _ := types.ParseDateTime(obj.ModTime)
-
-		result[i] = models.BackupFileInfo{
-			Key:      obj.Key,
-			Size:     obj.Size,
-			Modified: modified,
-		}
-	}
-
-	return c.JSON(http.StatusOK, result)
+	return c.JSON(http.StatusOK, backups)
 }
 
 func (api *backupApi) create(c echo.Context) error {
@@ -75,12 +64,26 @@ func (api *backupApi) create(c echo.Context) error {
 		return NewBadRequestError("An error occurred while loading the submitted data.", err)
 	}
 
+	securityEvent := newSecurityEvent(api.app, c, settings.SecurityEventBackupCreate)
+
 	return form.Submit(func(next forms.InterceptorNextFunc[string]) forms.InterceptorNextFunc[string] {
 		return func(name string) error {
 			if err := next(name); err != nil {
+				securityEvent.send(api.app, SecurityEventOutcomeFailure, map[string]any{"name": name})
+
+				var uploadErr *core.BackupUploadError
+				if errors.As(err, &uploadErr) {
+					return NewBadRequestError(
+						fmt.Sprintf("Failed to upload backup (%d attempts). Raw error: \n%v", uploadErr.Attempts, uploadErr.Err),
+						nil,
+					)
+				}
+
 				return NewBadRequestError("Failed to create backup.", err)
 			}
 
+			securityEvent.send(api.app, SecurityEventOutcomeSuccess, map[string]any{"name": name})
+
 			// we don't retrieve the generated backup file because it may not be
 			// available yet due to the eventually consistent nature of some S3 providers
 			return c.NoContent(http.StatusNoContent)
@@ -144,7 +147,10 @@ func (api *backupApi) restore(c echo.Context) error {
 
 	fsys.SetContext(existsCtx)
 
+	securityEvent := newSecurityEvent(api.app, c, settings.SecurityEventBackupRestore)
+
 	if exists, err := fsys.Exists(key); !exists {
+		securityEvent.send(api.app, SecurityEventOutcomeFailure, map[string]any{"name": key})
 		return NewBadRequestError("Missing or invalid backup file.", err)
 	}
 
@@ -156,8 +162,13 @@ func (api *backupApi) restore(c echo.Context) error {
 		// give some optimistic time to write the response
 		time.Sleep(1 * time.Second)
 
-		if err := api.app.RestoreBackup(ctx, key); err != nil && api.app.IsDebug() {
-			log.Println(err)
+		// queued before the restore because on success the app is restarted
+		// (aka. the event marks the start of the restore process)
+		securityEvent.send(api.app, SecurityEventOutcomeSuccess, map[string]any{"name": key})
+
+		if err := api.app.RestoreBackup(ctx, key); err != nil {
+			api.app.Logger().Error("Backup restore failed", "backup", key, "error", err)
+			securityEvent.send(api.app, SecurityEventOutcomeFailure, map[string]any{"name": key})
 		}
 	}()
 
@@ -182,9 +193,14 @@ func (api *backupApi) delete(c echo.Context) error {
 		return NewBadRequestError("The backup is currently being used and cannot be deleted.", nil)
 	}
 
+	securityEvent := newSecurityEvent(api.app, c, settings.SecurityEventBackupDelete)
+
 	if err := fsys.Delete(key); err != nil {
+		securityEvent.send(api.app, SecurityEventOutcomeFailure, map[string]any{"name": key})
 		return NewBadRequestError("Invalid or already deleted backup file. Raw error: \n"+err.Error(), nil)
 	}
 
+	securityEvent.send(api.app, SecurityEventOutcomeSuccess, map[string]any{"name": key})
+
 	return c.NoContent(http.StatusNoContent)
 }
@@ -6,7 +6,6 @@ import (
 	"errors"
 	"fmt"
 	"io/fs"
-	"log"
 	"net/http"
 	"net/url"
 	"path/filepath"
@@ -15,6 +14,7 @@ import (
 	"github.com/labstack/echo/v5"
 	"github.com/labstack/echo/v5/middleware"
 	"github.com/unkod/space/core"
+	"github.com/unkod/space/models/settings"
 	"github.com/unkod/space/tools/rest"
 )
 
@@ -24,7 +24,7 @@ func InitApi(app core.App) (*echo.Echo, error) {
 	e := echo.New()
 	e.Debug = app.IsDebug()
 	e.JSONSerializer = &rest.Serializer{
-		FieldsParam: "fields",
+		FieldsParam: fieldsQueryParam,
 	}
 
 	// configure a custom router
@@ -38,13 +38,33 @@ func InitApi(app core.App) (*echo.Echo, error) {
 	// default middlewares
 	e.Pre(middleware.RemoveTrailingSlashWithConfig(middleware.RemoveTrailingSlashConfig{
 		Skipper: func(c echo.Context) bool {
-			// enable by default only for the API routes
-			return !strings.HasPrefix(c.Request().URL.Path, "/api/")
+			// always enabled for the API routes
+			// and optional for the custom ones
+			return isCustomRoutePath(c.Request().URL.Path) &&
+				app.Settings().Routes.TrailingSlash != settings.TrailingSlashStrip
 		},
 	}))
+	e.Pre(middleware.AddTrailingSlashWithConfig(middleware.AddTrailingSlashConfig{
+		Skipper: func(c echo.Context) bool {
+			return !isCustomRoutePath(c.Request().URL.Path) ||
+				app.Settings().Routes.TrailingSlash != settings.TrailingSlashAppend
+		},
+	}))
+	e.Pre(caseInsensitiveRoutes(app, e))
+	e.Pre(LoadRequestId())
 	e.Pre(LoadAuthContext(app))
+
+	// registered before the recover middleware so that the panics are also recorded
+	metrics := newApiMetrics(app)
+	e.Use(metrics.middleware())
+
 	e.Use(middleware.Recover())
 	e.Use(middleware.Secure())
+	e.Use(defaultCompression(app))
+	e.Use(readOnlyMode(app))
+	e.Use(defaultBodyLimit(app))
+	e.Use(defaultRequestTimeout(app))
+	e.Use(defaultRequestCancellation(app))
 
 	// custom error handler
 	e.HTTPErrorHandler = func(c echo.Context, err error) {
@@ -53,28 +73,28 @@ func InitApi(app core.App) (*echo.Echo, error) {
 		}
 
 		if c.Response().Committed {
-			if app.IsDebug() {
-				log.Println("HTTPErrorHandler response was already committed:", err)
-			}
+			app.Logger().Debug(
+				"HTTPErrorHandler response was already committed",
+				requestLogAttrs(c, "status", c.Response().Status, "error", err)...,
+			)
 			return
 		}
 
 		var apiErr *ApiError
+		var errDetails any
 
 		if errors.As(err, &apiErr) {
-			if app.IsDebug() && apiErr.RawData() != nil {
-				log.Println(apiErr.RawData())
+			if apiErr.RawData() != nil {
+				errDetails = apiErr.RawData()
 			}
 		} else if v := new(echo.HTTPError); errors.As(err, &v) {
-			if v.Internal != nil && app.IsDebug() {
-				log.Println(v.Internal)
+			if v.Internal != nil {
+				errDetails = v.Internal
 			}
 			msg := fmt.Sprintf("%v", v.Message)
 			apiErr = NewApiError(v.Code, msg, v)
 		} else {
-			if app.IsDebug() {
-				log.Println(err)
-			}
+			errDetails = err
 
 			if errors.Is(err, sql.ErrNoRows) {
 				apiErr = NewNotFoundError("", err)
@@ -83,6 +103,15 @@ func InitApi(app core.App) (*echo.Echo, error) {
 			}
 		}
 
+		if errDetails != nil {
+			app.Logger().Debug(
+				"Request failed",
+				requestLogAttrs(c, "status", apiErr.Code, "message", apiErr.Message, "error", errDetails)...,
+			)
+		}
+
+		apiErr.RequestId = requestId(c)
+
 		event := new(core.ApiErrorEvent)
 		event.HttpContext = c
 		event.Error = apiErr
@@ -102,12 +131,12 @@ func InitApi(app core.App) (*echo.Echo, error) {
 		})
 
 		if hookErr == nil {
-			if err := app.OnAfterApiError().Trigger(event); err != nil && app.IsDebug() {
-				log.Println(hookErr)
+			if err := app.OnAfterApiError().Trigger(event); err != nil {
+				app.Logger().Debug("OnAfterApiError failure", requestLogAttrs(c, "error", err)...)
 			}
-		} else if app.IsDebug() {
+		} else {
 			// truly rare case; eg. client already disconnected
-			log.Println(hookErr)
+			app.Logger().Debug("Failed to send the error response", requestLogAttrs(c, "error", hookErr)...)
 		}
 	}
 
@@ -117,12 +146,16 @@ func InitApi(app core.App) (*echo.Echo, error) {
 	bindAdminApi(app, api)
 	bindCollectionApi(app, api)
 	bindRecordCrudApi(app, api)
+	bindBatchApi(app, api)
 	bindRecordAuthApi(app, api)
 	bindFileApi(app, api)
 	bindRealtimeApi(app, api)
 	bindLogsApi(app, api)
+	bindAuditApi(app, api)
 	bindHealthApi(app, api)
 	bindBackupApi(app, api)
+	bindOpenApi(app, api)
+	bindMetricsApi(app, api, metrics)
 
 	// catch all any route
 	api.Any("/*", func(c echo.Context) error {
@@ -138,6 +171,9 @@ func InitApi(app core.App) (*echo.Echo, error) {
 // If a file resource is missing and indexFallback is set, the request
 // will be forwarded to the base index.html (useful also for SPA).
 //
+// The requested file path is always cleaned, so the handler is not affected by
+// the app.Settings().Routes.TrailingSlash mode (eg. "/app.js/" serves "app.js").
+//
 // @see https://github.com/labstack/echo/issues/2211
 func StaticDirectoryHandler(fileSystem fs.FS, indexFallback bool) echo.HandlerFunc {
 	return func(c echo.Context) error {
@@ -11,6 +11,7 @@ import (
 	"github.com/labstack/echo/v5"
 	"github.com/spf13/cast"
 	"github.com/unkod/space/apis"
+	"github.com/unkod/space/models/settings"
 	"github.com/unkod/space/tests"
 )
 
@@ -99,8 +100,9 @@ func TestCustomRoutesAndErrorsHandling(t *testing.T) {
 					},
 				})
 			},
+			RequestHeaders:  map[string]string{"X-Request-Id": "test_http_error"},
 			ExpectedStatus:  400,
-			ExpectedContent: []string{`{"code":400,"message":"Bad Request.","data":{}}`},
+			ExpectedContent: []string{`{"code":400,"message":"Bad Request.","data":{},"requestId":"test_http_error"}`},
 		},
 		{
 			Name:   "route with api error",
@@ -115,8 +117,9 @@ func TestCustomRoutesAndErrorsHandling(t *testing.T) {
 					},
 				})
 			},
+			RequestHeaders:  map[string]string{"X-Request-Id": "test_api_error"},
 			ExpectedStatus:  500,
-			ExpectedContent: []string{`{"code":500,"message":"Test message.","data":{}}`},
+			ExpectedContent: []string{`{"code":500,"message":"Test message.","data":{},"requestId":"test_api_error"}`},
 		},
 		{
 			Name:   "route with plain error",
@@ -131,8 +134,9 @@ func TestCustomRoutesAndErrorsHandling(t *testing.T) {
 					},
 				})
 			},
+			RequestHeaders:  map[string]string{"X-Request-Id": "test_plain_error"},
 			ExpectedStatus:  400,
-			ExpectedContent: []string{`{"code":400,"message":"Something went wrong while processing your request.","data":{}}`},
+			ExpectedContent: []string{`{"code":400,"message":"Something went wrong while processing your request.","data":{},"requestId":"test_plain_error"}`},
 		},
 	}
 
@@ -214,6 +218,158 @@ func TestRemoveTrailingSlashMiddleware(t *testing.T) {
 	}
 }
 
+func TestCustomRoutesTrailingSlash(t *testing.T) {
+	addRoute := func(path string) func(t *testing.T, app *tests.TestApp, e *echo.Echo) {
+		return func(t *testing.T, app *tests.TestApp, e *echo.Echo) {
+			e.AddRoute(echo.Route{
+				Method: http.MethodGet,
+				Path:   path,
+				Handler: func(c echo.Context) error {
+					return c.String(200, "test123")
+				},
+			})
+		}
+	}
+
+	withMode := func(mode string, path string) func(t *testing.T, app *tests.TestApp, e *echo.Echo) {
+		return func(t *testing.T, app *tests.TestApp, e *echo.Echo) {
+			app.Settings().Routes.TrailingSlash = mode
+			addRoute(path)(t, app, e)
+		}
+	}
+
+	scenarios := []tests.ApiScenario{
+		{
+			Name:            "strip mode (with trailing slash)",
+			Method:          http.MethodGet,
+			Url:             "/custom/",
+			BeforeTestFunc:  withMode(settings.TrailingSlashStrip, "/custom"),
+			ExpectedStatus:  200,
+			ExpectedContent: []string{"test123"},
+		},
+		{
+			Name:            "strip mode (exact match)",
+			Method:          http.MethodGet,
+			Url:             "/custom",
+			BeforeTestFunc:  withMode(settings.TrailingSlashStrip, "/custom"),
+			ExpectedStatus:  200,
+			ExpectedContent: []string{"test123"},
+		},
+		{
+			Name:            "append mode (without trailing slash)",
+			Method:          http.MethodGet,
+			Url:             "/custom",
+			BeforeTestFunc:  withMode(settings.TrailingSlashAppend, "/custom/"),
+			ExpectedStatus:  200,
+			ExpectedContent: []string{"test123"},
+		},
+		{
+			Name:            "append mode (route registered without trailing slash)",
+			Method:          http.MethodGet,
+			Url:             "/custom",
+			BeforeTestFunc:  withMode(settings.TrailingSlashAppend, "/custom"),
+			ExpectedStatus:  404,
+			ExpectedContent: []string{`"data":{}`},
+		},
+		{
+			Name:            "append mode (/api/* route trailing slash is still removed)",
+			Method:          http.MethodGet,
+			Url:             "/api/custom/",
+			BeforeTestFunc:  withMode(settings.TrailingSlashAppend, "/api/custom"),
+			ExpectedStatus:  200,
+			ExpectedContent: []string{"test123"},
+		},
+		{
+			Name:            "append mode (/api/* route exact match)",
+			Method:          http.MethodGet,
+			Url:             "/api/custom",
+			BeforeTestFunc:  withMode(settings.TrailingSlashAppend, "/api/custom"),
+			ExpectedStatus:  200,
+			ExpectedContent: []string{"test123"},
+		},
+	}
+
+	for _, scenario := range scenarios {
+		scenario.Test(t)
+	}
+}
+
+func TestCustomRoutesCaseInsensitive(t *testing.T) {
+	addRoutes := func(caseInsensitive bool) func(t *testing.T, app *tests.TestApp, e *echo.Echo) {
+		return func(t *testing.T, app *tests.TestApp, e *echo.Echo) {
+			app.Settings().Routes.CaseInsensitive = caseInsensitive
+
+			e.GET("/hello/:name", func(c echo.Context) error {
+				return c.String(200, "hello "+c.PathParam("name"))
+			})
+			e.GET("/hello/:name/Info", func(c echo.Context) error {
+				return c.String(200, "info "+c.PathParam("name"))
+			})
+			e.GET("/api/custom", func(c echo.Context) error {
+				return c.String(200, "api")
+			})
+			e.GET("/*", func(c echo.Context) error {
+				return c.String(200, "static "+c.PathParam("*"))
+			})
+		}
+	}
+
+	scenarios := []tests.ApiScenario{
+		{
+			Name:            "disabled",
+			Method:          http.MethodGet,
+			Url:             "/HELLO/AbC",
+			BeforeTestFunc:  addRoutes(false),
+			ExpectedStatus:  200,
+			ExpectedContent: []string{"static HELLO/AbC"},
+		},
+		{
+			Name:            "enabled (exact match)",
+			Method:          http.MethodGet,
+			Url:             "/hello/AbC",
+			BeforeTestFunc:  addRoutes(true),
+			ExpectedStatus:  200,
+			ExpectedContent: []string{"hello AbC"},
+		},
+		{
+			Name:            "enabled (different case with preserved path param)",
+			Method:          http.MethodGet,
+			Url:             "/HELLO/AbC",
+			BeforeTestFunc:  addRoutes(true),
+			ExpectedStatus:  200,
+			ExpectedContent: []string{"hello AbC"},
+		},
+		{
+			Name:            "enabled (most specific route)",
+			Method:          http.MethodGet,
+			Url:             "/Hello/AbC/info",
+			BeforeTestFunc:  addRoutes(true),
+			ExpectedStatus:  200,
+			ExpectedContent: []string{"info AbC"},
+		},
+		{
+			Name:            "enabled (static catch-all)",
+			Method:          http.MethodGet,
+			Url:             "/Missing/File.txt",
+			BeforeTestFunc:  addRoutes(true),
+			ExpectedStatus:  200,
+			ExpectedContent: []string{"static Missing/File.txt"},
+		},
+		{
+			Name:            "enabled (/api/* routes are still case-sensitive)",
+			Method:          http.MethodGet,
+			Url:             "/api/CUSTOM",
+			BeforeTestFunc:  addRoutes(true),
+			ExpectedStatus:  404,
+			ExpectedContent: []string{`"data":{}`},
+		},
+	}
+
+	for _, scenario := range scenarios {
+		scenario.Test(t)
+	}
+}
+
 func TestEagerRequestInfoCache(t *testing.T) {
 	scenarios := []tests.ApiScenario{
 		{
@@ -0,0 +1,490 @@
+package apis
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/labstack/echo/v5"
+	"github.com/pocketbase/dbx"
+	"github.com/unkod/space/core"
+	"github.com/unkod/space/daos"
+	"github.com/unkod/space/forms"
+	"github.com/unkod/space/models"
+	"github.com/unkod/space/resolvers"
+	"github.com/unkod/space/tools/rest"
+	"github.com/unkod/space/tools/search"
+)
+
+// batchMaxOperations is the max allowed number of operations in a single batch request.
+const batchMaxOperations = 100
+
+// batchRefRegex matches a reference to the id of a record
+// created or updated by a previous batch operation (eg. "@request.0.id").
+var batchRefRegex = regexp.MustCompile(`^@request\.(\d+)\.id$`)
+
+// bindBatchApi registers the batch api endpoint.
+func bindBatchApi(app core.App, rg *echo.Group) {
+	api := batchApi{app: app}
+
+	rg.POST("/batch", api.process, ActivityLogger(app))
+}
+
+type batchApi struct {
+	app core.App
+}
+
+// batchOperation defines a single batch request operation.
+type batchOperation struct {
+	// Method is the operation HTTP method - POST (create), PATCH (update) or DELETE.
+	Method string `json:"method"`
+
+	// Collection is the name or id of the operation collection.
+	Collection string `json:"collection"`
+
+	// Id is the id of the record to update/delete.
+	Id string `json:"id"`
+
+	// Body is the record data to create/update.
+	Body map[string]any `json:"body"`
+}
+
+// batchRequest defines the batch request body.
+type batchRequest struct {
+	Operations []batchOperation `json:"operations"`
+}
+
+// batchResult defines a single batch operation result.
+type batchResult struct {
+	Status int            `json:"status"`
+	Body   *models.Record `json:"body"`
+}
+
+// process executes the submitted list of operations in order
+// and in a single transaction.
+//
+// Each operation is checked against its collection API rules and
+// triggers the same request hooks as its regular record crud endpoint.
+// If one of the operations fails the entire batch is rolled back.
+//
+// The record id of a previous operation could be referenced
+// in the next operations id and body values as "@request.N.id".
+//
+// Note that files cannot be uploaded with this handler and that soft-deletable
+// records are soft-deleted (the "deleted" query param is not supported).
+func (api *batchApi) process(c echo.Context) error {
+	data := batchRequest{}
+	if err := rest.CopyJsonBody(c.Request(), &data); err != nil {
+		return NewBadRequestError("Failed to load the submitted data due to invalid formatting.", err)
+	}
+
+	if len(data.Operations) == 0 {
+		return NewBadRequestError("Missing batch operations.", nil)
+	}
+
+	if len(data.Operations) > batchMaxOperations {
+		return NewBadRequestError(fmt.Sprintf("The batch request cannot have more than %d operations.", batchMaxOperations), nil)
+	}
+
+	results := make([]*batchResult, len(data.Operations))
+
+	var failedIndex int
+	var failedErr error
+
+	txErr := api.app.Dao().RunInTransaction(func(txDao *daos.Dao) error {
+		for i, op := range data.Operations {
+			result, err := api.processOperation(c, txDao, op, results[:i])
+			if err != nil {
+				failedIndex = i
+				failedErr = err
+				return err
+			}
+			results[i] = result
+		}
+		return nil
+	})
+
+	if txErr != nil {
+		if failedErr == nil {
+			return NewBadRequestError("Failed to process the batch request.", txErr)
+		}
+
+		return batchOperationError(failedIndex, failedErr)
+	}
+
+	if c.Response().Committed {
+		return nil
+	}
+
+	return c.JSON(http.StatusOK, results)
+}
+
+func (api *batchApi) processOperation(
+	c echo.Context,
+	txDao *daos.Dao,
+	op batchOperation,
+	prevResults []*batchResult,
+) (*batchResult, error) {
+	collection, err := txDao.FindCollectionByNameOrId(op.Collection)
+	if err != nil || collection == nil || collection.IsApiDisabled() {
+		return nil, NewNotFoundError("Missing or invalid collection.", err)
+	}
+
+	if collection.IsView() {
+		return nil, NewBadRequestError("View collection records cannot be modified.", nil)
+	}
+
+	id, err := resolveBatchRefs(op.Id, prevResults)
+	if err != nil {
+		return nil, NewBadRequestError("", err)
+	}
+	recordId, _ := id.(string)
+
+	body, err := resolveBatchRefs(op.Body, prevResults)
+	if err != nil {
+		return nil, NewBadRequestError("", err)
+	}
+	bodyData, _ := body.(map[string]any)
+	if bodyData == nil {
+		bodyData = map[string]any{}
+	}
+
+	requestInfo := *RequestInfo(c)
+	requestInfo.Method = strings.ToUpper(op.Method)
+	requestInfo.Data = bodyData
+
+	switch requestInfo.Method {
+	case http.MethodPost:
+		return api.create(c, txDao, collection, &requestInfo)
+	case http.MethodPatch:
+		if recordId == "" {
+			return nil, NewNotFoundError("", nil)
+		}
+		return api.update(c, txDao, collection, recordId, &requestInfo)
+	case http.MethodDelete:
+		if recordId == "" {
+			return nil, NewNotFoundError("", nil)
+		}
+		return api.delete(c, txDao, collection, recordId, &requestInfo)
+	default:
+		return nil, NewBadRequestError(`Invalid operation method - expected "POST", "PATCH" or "DELETE".`, nil)
+	}
+}
+
+func (api *batchApi) create(
+	c echo.Context,
+	txDao *daos.Dao,
+	collection *models.Collection,
+	requestInfo *models.RequestInfo,
+) (*batchResult, error) {
+	if requestInfo.Admin == nil && collection.CreateRule == nil {
+		// only admins can access if the rule is nil
+		return nil, NewForbiddenError("Only admins can perform this action.", nil)
+	}
+
+	record := models.NewRecord(collection)
+
+	form := forms.NewRecordUpsert(api.app, record)
+	form.SetDao(txDao)
+	form.SetFullManageAccess(requestInfo.Admin != nil)
+	form.SetRequestInfo(requestInfo)
+	if err := form.LoadData(requestInfo.Data); err != nil {
+		return nil, NewBadRequestError("Failed to load the submitted data due to invalid formatting.", err)
+	}
+
+	changedFields := form.ChangedFields()
+
+	event := new(core.RecordCreateEvent)
+	event.HttpContext = c
+	event.Collection = collection
+	event.Record = record
+	event.UploadedFiles = form.FilesToUpload()
+
+	submitErr := form.Submit(func(next forms.InterceptorNextFunc[*models.Record]) forms.InterceptorNextFunc[*models.Record] {
+		return func(m *models.Record) error {
+			event.Record = m
+
+			return api.app.OnRecordBeforeCreateRequest().Trigger(event, func(e *core.RecordCreateEvent) error {
+				if err := next(e.Record); err != nil {
+					return NewBadRequestError("Failed to create record.", err)
+				}
+
+				if requestInfo.Admin == nil && *collection.CreateRule != "" {
+					// replace modifiers fields so that the resolved value is always
+					// available when accessing requestInfo.Data using just the field name
+					ruleInfo := *requestInfo
+					ruleInfo.Data = models.NewRecord(collection).ReplaceModifers(requestInfo.Data)
+
+					// check the persisted record against the create rule
+					ruleFunc := batchRuleFunc(txDao, collection, &ruleInfo, *collection.CreateRule)
+					if _, err := txDao.FindRecordById(collection.Id, e.Record.Id, ruleFunc); err != nil {
+						return NewBadRequestError("Failed to create record.", fmt.Errorf("create rule failure: %w", err))
+					}
+				}
+
+				if err := checkFieldWriteRules(txDao, e.Record, requestInfo, changedFields); err != nil {
+					return err
+				}
+
+				return api.app.OnRecordAfterCreateRequest().Trigger(event, func(e *core.RecordCreateEvent) error {
+					return nil
+				})
+			})
+		}
+	})
+	if submitErr != nil {
+		return nil, submitErr
+	}
+
+	if err := EnrichRecord(c, txDao, event.Record); err != nil {
+		api.app.Logger().Debug("Failed to enrich record", requestLogAttrs(c, "error", err)...)
+	}
+
+	return &batchResult{Status: http.StatusOK, Body: event.Record}, nil
+}
+
+func (api *batchApi) update(
+	c echo.Context,
+	txDao *daos.Dao,
+	collection *models.Collection,
+	recordId string,
+	requestInfo *models.RequestInfo,
+) (*batchResult, error) {
+	if requestInfo.Admin == nil && collection.UpdateRule == nil {
+		// only admins can access if the rule is nil
+		return nil, NewForbiddenError("Only admins can perform this action.", nil)
+	}
+
+	// eager fetch the record so that the modifier field values are replaced
+	// and available when accessing requestInfo.Data using just the field name
+	if requestInfo.HasModifierDataKeys() {
+		record, err := txDao.FindRecordById(
+			collection.Id,
+			recordId,
+			daos.SoftDeletedFilter(collection, daos.SoftDeletedExclude),
+		)
+		if err != nil || record == nil {
+			return nil, NewNotFoundError("", err)
+		}
+		requestInfo.Data = record.ReplaceModifers(requestInfo.Data)
+	}
+
+	var ruleFunc func(q *dbx.SelectQuery) error
+	if requestInfo.Admin == nil && *collection.UpdateRule != "" {
+		ruleFunc = batchRuleFunc(txDao, collection, requestInfo, *collection.UpdateRule)
+	}
+
+	record, fetchErr := txDao.FindRecordById(
+		collection.Id,
+		recordId,
+		daos.SoftDeletedFilter(collection, daos.SoftDeletedExclude),
+		ruleFunc,
+	)
+	if fetchErr != nil || record == nil {
+		return nil, NewNotFoundError("", fetchErr)
+	}
+
+	form := forms.NewRecordUpsert(api.app, record)
+	form.SetDao(txDao)
+	form.SetFullManageAccess(requestInfo.Admin != nil || hasAuthManageAccess(txDao, record, requestInfo))
+	if err := form.LoadData(requestInfo.Data); err != nil {
+		return nil, NewBadRequestError("Failed to load the submitted data due to invalid formatting.", err)
+	}
+
+	if err := checkFieldWriteRules(txDao, record, requestInfo, form.ChangedFields()); err != nil {
+		return nil, err
+	}
+
+	event := new(core.RecordUpdateEvent)
+	event.HttpContext = c
+	event.Collection = collection
+	event.Record = record
+	event.UploadedFiles = form.FilesToUpload()
+
+	submitErr := form.Submit(func(next forms.InterceptorNextFunc[*models.Record]) forms.InterceptorNextFunc[*models.Record] {
+		return func(m *models.Record) error {
+			event.Record = m
+
+			return api.app.OnRecordBeforeUpdateRequest().Trigger(event, func(e *core.RecordUpdateEvent) error {
+				if err := next(e.Record); err != nil {
+					return NewBadRequestError("Failed to update record.", err)
+				}
+
+				return api.app.OnRecordAfterUpdateRequest().Trigger(event, func(e *core.RecordUpdateEvent) error {
+					return nil
+				})
+			})
+		}
+	})
+	if submitErr != nil {
+		return nil, submitErr
+	}
+
+	if err := EnrichRecord(c, txDao, event.Record); err != nil {
+		api.app.Logger().Debug("Failed to enrich record", requestLogAttrs(c, "error", err)...)
+	}
+
+	return &batchResult{Status: http.StatusOK, Body: event.Record}, nil
+}
+
+func (api *batchApi) delete(
+	c echo.Context,
+	txDao *daos.Dao,
+	collection *models.Collection,
+	recordId string,
+	requestInfo *models.RequestInfo,
+) (*batchResult, error) {
+	if requestInfo.Admin == nil && collection.DeleteRule == nil {
+		// only admins can access if the rule is nil
+		return nil, NewForbiddenError("Only admins can perform this action.", nil)
+	}
+
+	var ruleFunc func(q *dbx.SelectQuery) error
+	if requestInfo.Admin == nil && *collection.DeleteRule != "" {
+		ruleFunc = batchRuleFunc(txDao, collection, requestInfo, *collection.DeleteRule)
+	}
+
+	record, fetchErr := txDao.FindRecordById(
+		collection.Id,
+		recordId,
+		daos.SoftDeletedFilter(collection, daos.SoftDeletedExclude),
+		ruleFunc,
+	)
+	if fetchErr != nil || record == nil {
+		return nil, NewNotFoundError("", fetchErr)
+	}
+
+	if collection.HasSoftDelete() {
+		event := new(core.RecordSoftDeleteEvent)
+		event.HttpContext = c
+		event.Collection = collection
+		event.Record = record
+
+		err := api.app.OnRecordBeforeSoftDeleteRequest().Trigger(event, func(e *core.RecordSoftDeleteEvent) error {
+			if err := txDao.SoftDeleteRecord(e.Record); err != nil {
+				return NewBadRequestError("Failed to delete record.", err)
+			}
+
+			return api.app.OnRecordAfterSoftDeleteRequest().Trigger(event, func(e *core.RecordSoftDeleteEvent) error {
+				return nil
+			})
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		return &batchResult{Status: http.StatusNoContent}, nil
+	}
+
+	event := new(core.RecordDeleteEvent)
+	event.HttpContext = c
+	event.Collection = collection
+	event.Record = record
+
+	err := api.app.OnRecordBeforeDeleteRequest().Trigger(event, func(e *core.RecordDeleteEvent) error {
+		if err := txDao.DeleteRecord(e.Record); err != nil {
+			return NewBadRequestError("Failed to delete record. Make sure that the record is not part of a required relation reference.", err)
+		}
+
+		return api.app.OnRecordAfterDeleteRequest().Trigger(event, func(e *core.RecordDeleteEvent) error {
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &batchResult{Status: http.StatusNoContent}, nil
+}
+
+// batchRuleFunc returns a record query filter func for the specified collection rule.
+func batchRuleFunc(
+	dao *daos.Dao,
+	collection *models.Collection,
+	requestInfo *models.RequestInfo,
+	rule string,
+) func(q *dbx.SelectQuery) error {
+	return func(q *dbx.SelectQuery) error {
+		resolver := resolvers.NewRecordFieldResolver(dao, collection, requestInfo, true)
+		expr, err := search.FilterData(rule).BuildExpr(resolver)
+		if err != nil {
+			return err
+		}
+		resolver.UpdateQuery(q)
+		q.AndWhere(expr)
+		return nil
+	}
+}
+
+// resolveBatchRefs recursively replaces the "@request.N.id" string
+// values with the record id of the N-th previous batch operation.
+func resolveBatchRefs(value any, prevResults []*batchResult) (any, error) {
+	switch v := value.(type) {
+	case string:
+		match := batchRefRegex.FindStringSubmatch(v)
+		if len(match) == 0 {
+			return v, nil
+		}
+
+		index, _ := strconv.Atoi(match[1])
+		if index >= len(prevResults) {
+			return nil, fmt.Errorf("%q must reference a previous batch operation", v)
+		}
+
+		if prevResults[index] == nil || prevResults[index].Body == nil {
+			return nil, fmt.Errorf("%q references an operation without record result", v)
+		}
+
+		return prevResults[index].Body.Id, nil
+	case []any:
+		result := make([]any, len(v))
+		for i, item := range v {
+			resolved, err := resolveBatchRefs(item, prevResults)
+			if err != nil {
+				return nil, err
+			}
+			result[i] = resolved
+		}
+		return result, nil
+	case map[string]any:
+		result := make(map[string]any, len(v))
+		for k, item := range v {
+			resolved, err := resolveBatchRefs(item, prevResults)
+			if err != nil {
+				return nil, err
+			}
+			result[k] = resolved
+		}
+		return result, nil
+	default:
+		return v, nil
+	}
+}
+
+// batchOperationError wraps the failed batch operation error
+// into an api error keyed by the operation index.
+func batchOperationError(index int, err error) *ApiError {
+	var opErr *ApiError
+	if !errors.As(err, &opErr) {
+		opErr = NewBadRequestError("", err)
+	}
+
+	result := NewApiError(
+		opErr.Code,
+		fmt.Sprintf("Batch operation %d failed - %s", index, opErr.Message),
+		nil,
+	)
+
+	result.Data = map[string]any{
+		strconv.Itoa(index): map[string]any{
+			"code":    opErr.Code,
+			"message": opErr.Message,
+			"data":    opErr.Data,
+		},
+	}
+
+	return result
+}
@@ -20,6 +20,7 @@ func bindCollectionApi(app core.App, rg *echo.Group) {
 	subGroup.GET("/:collection", api.view)
 	subGroup.PATCH("/:collection", api.update)
 	subGroup.DELETE("/:collection", api.delete)
+	subGroup.POST("/:collection/truncate", api.truncate)
 	subGroup.PUT("/import", api.bulkImport)
 }
 
@@ -175,6 +176,32 @@ func (api *collectionApi) delete(c echo.Context) error {
 	})
 }
 
+// truncate deletes all records of a single collection.
+//
+// NB! The per record model hooks are not triggered if "skipHooks" is set.
+func (api *collectionApi) truncate(c echo.Context) error {
+	collection, err := api.app.Dao().FindCollectionByNameOrId(c.PathParam("collection"))
+	if err != nil || collection == nil {
+		return NewNotFoundError("", err)
+	}
+
+	form := forms.NewCollectionTruncate(api.app, collection)
+
+	// load request data
+	if err := c.Bind(form); err != nil {
+		return NewBadRequestError("Failed to load the submitted data due to invalid formatting.", err)
+	}
+
+	total, submitErr := form.Submit()
+	if submitErr != nil {
+		return NewBadRequestError("Failed to truncate the collection.", submitErr)
+	}
+
+	return c.JSON(http.StatusOK, map[string]any{
+		"totalDeleted": total,
+	})
+}
+
 func (api *collectionApi) bulkImport(c echo.Context) error {
 	form := forms.NewCollectionsImport(api.app)
 
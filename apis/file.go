@@ -4,11 +4,15 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
+	"path/filepath"
 	"strings"
+	"time"
 
+	validation "github.com/go-ozzo/ozzo-validation/v4"
 	"github.com/labstack/echo/v5"
 	"github.com/spf13/cast"
 	"github.com/unkod/space/core"
+	"github.com/unkod/space/daos"
 	"github.com/unkod/space/models"
 	"github.com/unkod/space/models/schema"
 	"github.com/unkod/space/tokens"
@@ -19,6 +23,15 @@ import (
 var imageContentTypes = []string{"image/png", "image/jpg", "image/jpeg", "image/gif"}
 var defaultThumbSizes = []string{"100x100"}
 
+// thumbFormats lists the supported thumb output formats
+// that could be requested with the "format" query parameter.
+//
+// Note that the WebP and AVIF output formats are not supported since
+// there is no encoder for them in the current dependencies
+// (golang.org/x/image can only decode WebP) and they are rejected
+// the same way as any other unsupported format.
+var thumbFormats = []string{"png", "jpg", "jpeg", "gif"}
+
 // bindFileApi registers the file api endpoints and the corresponding handlers.
 func bindFileApi(app core.App, rg *echo.Group) {
 	api := fileApi{app: app}
@@ -27,6 +40,7 @@ func bindFileApi(app core.App, rg *echo.Group) {
 	subGroup.POST("/token", api.fileToken)
 	subGroup.HEAD("/:collection/:recordId/:filename", api.download, LoadCollectionContext(api.app))
 	subGroup.GET("/:collection/:recordId/:filename", api.download, LoadCollectionContext(api.app))
+	subGroup.POST("/:collection/:recordId/:filename/signed-url", api.signedUrl, LoadCollectionContext(api.app))
 }
 
 type fileApi struct {
@@ -62,6 +76,72 @@ func (api *fileApi) fileToken(c echo.Context) error {
 	})
 }
 
+func (api *fileApi) signedUrl(c echo.Context) error {
+	collection, _ := c.Get(ContextCollectionKey).(*models.Collection)
+	if collection == nil {
+		return NewNotFoundError("", nil)
+	}
+
+	record, err := api.app.Dao().FindRecordById(
+		collection.Id,
+		c.PathParam("recordId"),
+		daos.SoftDeletedFilter(collection, daos.SoftDeletedExclude),
+	)
+	if err != nil {
+		return NewNotFoundError("", err)
+	}
+
+	filename := c.PathParam("filename")
+
+	fileField := record.FindFileFieldByFile(filename)
+	if fileField == nil {
+		return NewNotFoundError("", nil)
+	}
+
+	// only clients that are allowed to view the record could share its files
+	if ok, _ := api.app.Dao().CanAccessRecord(record, RequestInfo(c), collection.ViewRule); !ok {
+		return NewForbiddenError("Insufficient permissions to access the file resource.", nil)
+	}
+
+	if !api.canReadFileField(record, fileField, RequestInfo(c)) {
+		return NewNotFoundError("", nil)
+	}
+
+	data := struct {
+		// Duration is the signed url lifetime in seconds
+		// (default to the FileSignedUrlToken.Duration setting).
+		Duration int64 `json:"duration"`
+	}{}
+	if err := c.Bind(&data); err != nil {
+		return NewBadRequestError("An error occurred while loading the submitted data.", err)
+	}
+
+	maxDuration := api.app.Settings().FileSignedUrlToken.Duration
+
+	err = validation.ValidateStruct(&data,
+		validation.Field(&data.Duration, validation.Min(0), validation.Max(maxDuration)),
+	)
+	if err != nil {
+		return NewBadRequestError("An error occurred while validating the submitted data.", err)
+	}
+
+	if data.Duration == 0 {
+		data.Duration = maxDuration
+	}
+
+	expires := time.Now().Add(time.Duration(data.Duration) * time.Second)
+
+	url, err := NewSignedFileUrl(api.app, record, filename, expires)
+	if err != nil {
+		return NewBadRequestError("Failed to generate the file signed url.", err)
+	}
+
+	return c.JSON(http.StatusOK, map[string]any{
+		"url":     url,
+		"expires": expires.Unix(),
+	})
+}
+
 func (api *fileApi) download(c echo.Context) error {
 	collection, _ := c.Get(ContextCollectionKey).(*models.Collection)
 	if collection == nil {
@@ -73,7 +153,12 @@ func (api *fileApi) download(c echo.Context) error {
 		return NewNotFoundError("", nil)
 	}
 
-	record, err := api.app.Dao().FindRecordById(collection.Id, recordId)
+	// note: the files of the soft-deleted records are kept (for restore) but are not served
+	record, err := api.app.Dao().FindRecordById(
+		collection.Id,
+		recordId,
+		daos.SoftDeletedFilter(collection, daos.SoftDeletedExclude),
+	)
 	if err != nil {
 		return NewNotFoundError("", err)
 	}
@@ -91,7 +176,17 @@ func (api *fileApi) download(c echo.Context) error {
 	}
 
 	// check whether the request is authorized to view the protected file
-	if options.Protected {
+	if options.Protected && c.QueryParam(fileSignedUrlSignatureParam) != "" {
+		if !validFileUrlSignature(
+			api.app,
+			record,
+			filename,
+			c.QueryParam(fileSignedUrlExpiresParam),
+			c.QueryParam(fileSignedUrlSignatureParam),
+		) {
+			return NewForbiddenError("The file signed url is invalid or expired.", nil)
+		}
+	} else if options.Protected || fileField.ReadRule != "" {
 		token := c.QueryParam("token")
 
 		adminOrAuthRecord, _ := api.findAdminOrAuthRecordByFileToken(token)
@@ -108,8 +203,16 @@ func (api *fileApi) download(c echo.Context) error {
 			}
 		}
 
-		if ok, _ := api.app.Dao().CanAccessRecord(record, &requestInfo, record.Collection().ViewRule); !ok {
-			return NewForbiddenError("Insufficient permissions to access the file resource.", nil)
+		if options.Protected {
+			if ok, _ := api.app.Dao().CanAccessRecord(record, &requestInfo, record.Collection().ViewRule); !ok {
+				return NewForbiddenError("Insufficient permissions to access the file resource.", nil)
+			}
+		}
+
+		// similar to the record view, the field files are hidden from
+		// the clients that don't satisfy the file field read rule
+		if !api.canReadFileField(record, fileField, &requestInfo) {
+			return NewNotFoundError("", nil)
 		}
 	}
 
@@ -134,9 +237,20 @@ func (api *fileApi) download(c echo.Context) error {
 	servedPath := originalPath
 	servedName := filename
 
+	// check for valid thumb format param
+	thumbFormat := strings.ToLower(c.QueryParam("format"))
+	if thumbFormat != "" && !list.ExistInSlice(thumbFormat, thumbFormats) {
+		return NewBadRequestError(
+			fmt.Sprintf("Unsupported thumb format. Supported formats are: %s.", strings.Join(thumbFormats, ", ")),
+			nil,
+		)
+	}
+
 	// check for valid thumb size param
 	thumbSize := c.QueryParam("thumb")
-	if thumbSize != "" && (list.ExistInSlice(thumbSize, defaultThumbSizes) || list.ExistInSlice(thumbSize, options.Thumbs)) {
+	if thumbSize != "" && (list.ExistInSlice(thumbSize, defaultThumbSizes) ||
+		list.ExistInSlice(thumbSize, options.Thumbs) ||
+		list.ExistInSlice(thumbSize, api.app.Settings().Thumbs.Sizes)) {
 		// extract the original file meta attributes and check it existence
 		oAttrs, oAttrsErr := fs.Attributes(originalPath)
 		if oAttrsErr != nil {
@@ -147,6 +261,14 @@ func (api *fileApi) download(c echo.Context) error {
 		if list.ExistInSlice(oAttrs.ContentType, imageContentTypes) {
 			// add thumb size as file suffix
 			servedName = thumbSize + "_" + filename
+
+			// replace the original extension with the requested thumb format
+			// (the format is part of the name so that each variant is cached separately)
+			ext := filepath.Ext(filename)
+			if thumbFormat != "" && !strings.EqualFold(strings.TrimPrefix(ext, "."), thumbFormat) {
+				servedName = thumbSize + "_" + strings.TrimSuffix(filename, ext) + "." + thumbFormat
+			}
+
 			servedPath = baseFilesPath + "/thumbs_" + filename + "/" + servedName
 
 			// create a new thumb if it doesn exists
@@ -184,6 +306,24 @@ func (api *fileApi) download(c echo.Context) error {
 	})
 }
 
+// canReadFileField checks whether the provided request info
+// satisfies the [schema.SchemaField.ReadRule] of the record file field.
+func (api *fileApi) canReadFileField(
+	record *models.Record,
+	fileField *schema.SchemaField,
+	requestInfo *models.RequestInfo,
+) bool {
+	if fileField.ReadRule == "" {
+		return true
+	}
+
+	rule := fileField.ReadRule
+
+	ok, _ := api.app.Dao().CanAccessRecord(record, requestInfo, &rule)
+
+	return ok
+}
+
 func (api *fileApi) findAdminOrAuthRecordByFileToken(fileToken string) (models.Model, error) {
 	fileToken = strings.TrimSpace(fileToken)
 	if fileToken == "" {
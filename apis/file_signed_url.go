@@ -0,0 +1,71 @@
+package apis
+
+import (
+	"crypto/hmac"
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/unkod/space/core"
+	"github.com/unkod/space/models"
+	"github.com/unkod/space/tools/security"
+)
+
+const (
+	fileSignedUrlExpiresParam   = "expires"
+	fileSignedUrlSignatureParam = "signature"
+)
+
+// NewSignedFileUrl generates a new absolute signed url of the record file
+// that grants access to the file until the specified expiration time
+// without requiring a file token (eg. to share a protected file with
+// third parties or to embed it in an email).
+//
+// Note that the signed url is valid for all thumbs of the file
+// and that it cannot be revoked other than by changing the
+// FileSignedUrlToken.Secret setting.
+func NewSignedFileUrl(app core.App, record *models.Record, filename string, expiresAt time.Time) (string, error) {
+	if record.FindFileFieldByFile(filename) == nil {
+		return "", errors.New("missing record file " + filename)
+	}
+
+	expires := expiresAt.Unix()
+
+	query := url.Values{}
+	query.Set(fileSignedUrlExpiresParam, strconv.FormatInt(expires, 10))
+	query.Set(fileSignedUrlSignatureParam, fileUrlSignature(app, record, filename, expires))
+
+	return fmt.Sprintf(
+		"%s/api/files/%s/%s/%s?%s",
+		strings.TrimRight(app.Settings().Meta.AppUrl, "/"),
+		url.PathEscape(record.Collection().Id),
+		url.PathEscape(record.Id),
+		url.PathEscape(filename),
+		query.Encode(),
+	), nil
+}
+
+// fileUrlSignature returns the HMAC signature of the record file url
+// with the specified unix expiration timestamp.
+func fileUrlSignature(app core.App, record *models.Record, filename string, expires int64) string {
+	return security.HS256(
+		fmt.Sprintf("%s/%s/%s:%d", record.Collection().Id, record.Id, filename, expires),
+		app.Settings().FileSignedUrlToken.Secret,
+	)
+}
+
+// validFileUrlSignature reports whether the provided signature
+// and unix expiration timestamp are valid for the record file.
+func validFileUrlSignature(app core.App, record *models.Record, filename string, rawExpires string, signature string) bool {
+	expires, err := strconv.ParseInt(rawExpires, 10, 64)
+	if err != nil || time.Now().Unix() > expires {
+		return false
+	}
+
+	expected := fileUrlSignature(app, record, filename, expires)
+
+	return hmac.Equal([]byte(expected), []byte(signature))
+}
@@ -0,0 +1,86 @@
+package apis_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/unkod/space/apis"
+	"github.com/unkod/space/tests"
+)
+
+func TestNewSignedFileUrl(t *testing.T) {
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	app.Settings().Meta.AppUrl = "http://example.com/"
+
+	record, err := app.Dao().FindRecordById("demo1", "al1h9ijdeojtsjy")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := apis.NewSignedFileUrl(app, record, "missing.png", time.Now().Add(time.Minute)); err == nil {
+		t.Fatal("Expected error for missing record file")
+	}
+
+	validUrl, err := apis.NewSignedFileUrl(app, record, "300_Jsjq7RdBgA.png", time.Now().Add(time.Minute))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expiredUrl, err := apis.NewSignedFileUrl(app, record, "300_Jsjq7RdBgA.png", time.Now().Add(-time.Second))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !strings.HasPrefix(validUrl, "http://example.com/api/files/wsmn24bux7wo113/al1h9ijdeojtsjy/300_Jsjq7RdBgA.png?") {
+		t.Fatalf("Unexpected signed url %q", validUrl)
+	}
+
+	parsed, err := url.Parse(validUrl)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tamper := func(param string, value string) string {
+		query := parsed.Query()
+		query.Set(param, value)
+		return parsed.Path + "?" + query.Encode()
+	}
+
+	scenarios := []struct {
+		name           string
+		url            string
+		expectedStatus int
+	}{
+		{"valid", parsed.RequestURI(), 200},
+		{"valid with thumb", parsed.RequestURI() + "&thumb=100x100", 200},
+		{"expired", strings.TrimPrefix(expiredUrl, "http://example.com"), 403},
+		{"tampered signature", tamper("signature", strings.Repeat("a", 64)), 403},
+		{"tampered expires", tamper("expires", "4102444800"), 403},
+		{"invalid expires", tamper("expires", "abc"), 403},
+		{"different collection", strings.Replace(parsed.RequestURI(), "wsmn24bux7wo113", "view1", 1), 403},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			e, err := apis.InitApi(app)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			req := httptest.NewRequest(http.MethodGet, s.url, nil)
+			rec := httptest.NewRecorder()
+
+			e.ServeHTTP(rec, req)
+
+			if rec.Code != s.expectedStatus {
+				t.Fatalf("Expected status code %d, got %d (%s)", s.expectedStatus, rec.Code, rec.Body.String())
+			}
+		})
+	}
+}
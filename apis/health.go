@@ -1,10 +1,33 @@
 package apis
 
 import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
 	"net/http"
+	"time"
 
 	"github.com/labstack/echo/v5"
+	"github.com/spf13/cast"
 	"github.com/unkod/space/core"
+	"github.com/unkod/space/tools/security"
+)
+
+// HealthChecksCacheKey is the app cache key of the last detailed health checks result.
+const HealthChecksCacheKey = "@healthChecks"
+
+// healthChecksCacheDuration specifies for how long the detailed health checks
+// result is reused so that the endpoint could be called frequently.
+const healthChecksCacheDuration = 5 * time.Second
+
+// healthCheckTimeout is the max duration of a single component health check.
+const healthCheckTimeout = 5 * time.Second
+
+// list with the detailed health check component statuses
+const (
+	HealthStatusOk    = "ok"
+	HealthStatusError = "error"
 )
 
 // bindHealthApi registers the health api endpoint.
@@ -23,16 +46,145 @@ type healthCheckResponse struct {
 	Code    int    `json:"code"`
 	Message string `json:"message"`
 	Data    struct {
-		CanBackup bool `json:"canBackup"`
+		CanBackup  bool                              `json:"canBackup"`
+		Components map[string]*healthComponentResult `json:"components,omitempty"`
 	} `json:"data"`
 }
 
+type healthComponentResult struct {
+	Status string `json:"status"`
+
+	// Latency is the component check duration in milliseconds.
+	Latency float64 `json:"latency"`
+
+	// Error is the check failure reason (visible only to admins).
+	Error string `json:"error,omitempty"`
+}
+
+type healthChecksResult struct {
+	checkedAt  time.Time
+	components map[string]*healthComponentResult
+}
+
 // healthCheck returns a 200 OK response if the server is healthy.
+//
+// With "?detailed=1" it also checks the data db, the logs db and the
+// configured file storage and returns a 503 response if any of them fails.
 func (api *healthApi) healthCheck(c echo.Context) error {
 	resp := new(healthCheckResponse)
 	resp.Code = http.StatusOK
 	resp.Message = "API is healthy."
 	resp.Data.CanBackup = !api.app.Cache().Has(core.CacheKeyActiveBackup)
 
-	return c.JSON(http.StatusOK, resp)
+	if !cast.ToBool(c.QueryParam("detailed")) {
+		return c.JSON(http.StatusOK, resp)
+	}
+
+	isAdmin := c.Get(ContextAdminKey) != nil
+
+	resp.Data.Components = map[string]*healthComponentResult{}
+
+	for name, result := range api.detailedChecks() {
+		component := *result
+		if !isAdmin {
+			component.Error = ""
+		}
+
+		resp.Data.Components[name] = &component
+
+		if component.Status != HealthStatusOk {
+			resp.Code = http.StatusServiceUnavailable
+			resp.Message = "API is unhealthy."
+		}
+	}
+
+	return c.JSON(resp.Code, resp)
+}
+
+// detailedChecks runs the components health checks
+// (or returns the cached result from a recent run).
+func (api *healthApi) detailedChecks() map[string]*healthComponentResult {
+	if cached, ok := api.app.Cache().Get(HealthChecksCacheKey).(*healthChecksResult); ok &&
+		time.Since(cached.checkedAt) < healthChecksCacheDuration {
+		return cached.components
+	}
+
+	components := map[string]*healthComponentResult{
+		"db":      runHealthCheck(api.checkDataDB),
+		"logsDb":  runHealthCheck(api.checkLogsDB),
+		"storage": runHealthCheck(api.checkStorage),
+	}
+
+	api.app.Cache().Set(HealthChecksCacheKey, &healthChecksResult{
+		checkedAt:  time.Now(),
+		components: components,
+	})
+
+	return components
+}
+
+func runHealthCheck(check func(ctx context.Context) error) *healthComponentResult {
+	ctx, cancel := context.WithTimeout(context.Background(), healthCheckTimeout)
+	defer cancel()
+
+	start := time.Now()
+	err := check(ctx)
+
+	result := &healthComponentResult{
+		Status:  HealthStatusOk,
+		Latency: float64(time.Since(start).Microseconds()) / 1000,
+	}
+
+	if err != nil {
+		result.Status = HealthStatusError
+		result.Error = err.Error()
+	}
+
+	return result
+}
+
+func (api *healthApi) checkDataDB(ctx context.Context) error {
+	_, err := api.app.Dao().DB().NewQuery("SELECT 1").WithContext(ctx).Execute()
+	return err
+}
+
+func (api *healthApi) checkLogsDB(ctx context.Context) error {
+	_, err := api.app.LogsDao().DB().NewQuery("SELECT 1").WithContext(ctx).Execute()
+	return err
+}
+
+// checkStorage performs a small file write, read and delete.
+func (api *healthApi) checkStorage(ctx context.Context) error {
+	fs, err := api.app.NewFilesystem()
+	if err != nil {
+		return err
+	}
+	defer fs.Close()
+
+	fs.SetContext(ctx)
+
+	key := ".health_" + security.PseudorandomString(10)
+	content := []byte("ok")
+
+	if err := fs.Upload(content, key); err != nil {
+		return err
+	}
+	defer fs.Delete(key)
+
+	r, err := fs.GetFile(key)
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+
+	stored, err := io.ReadAll(r)
+	if err != nil {
+		return err
+	}
+
+	if !bytes.Equal(stored, content) {
+		return errors.New("the stored health check file content doesn't match")
+	}
+
+	return nil
 }
@@ -0,0 +1,175 @@
+package apis
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/labstack/echo/v5"
+	"github.com/spf13/cast"
+	"github.com/unkod/space/core"
+	"github.com/unkod/space/tools/lockout"
+	"github.com/unkod/space/tools/security"
+)
+
+// LoginLockoutStoreCacheKey is the app cache key of the store with the
+// failed auth-with-password and auth-with-mfa attempts counters.
+//
+// By default an in-memory store is lazily created (aka. the lockouts are
+// reset on app restart) but you can register your own persistent
+// [lockout.Store] implementation (eg. Redis backed) with:
+//
+//	app.Cache().Set(apis.LoginLockoutStoreCacheKey, myStore)
+const LoginLockoutStoreCacheKey string = "@loginLockoutStore"
+
+// loginLockoutAdminsScope is the lockout identity keys scope of the admin accounts.
+const loginLockoutAdminsScope string = "_admins"
+
+var loginLockoutStoreMux sync.Mutex
+
+// loginLockoutStore returns the app login lockout store (lazily creating
+// a new in-memory one if none is registered).
+func loginLockoutStore(app core.App) lockout.Store {
+	if store, ok := app.Cache().Get(LoginLockoutStoreCacheKey).(lockout.Store); ok {
+		return store
+	}
+
+	loginLockoutStoreMux.Lock()
+	defer loginLockoutStoreMux.Unlock()
+
+	// check again in case it was registered while waiting for the lock
+	if store, ok := app.Cache().Get(LoginLockoutStoreCacheKey).(lockout.Store); ok {
+		return store
+	}
+
+	store := lockout.NewMemoryStore()
+	app.Cache().Set(LoginLockoutStoreCacheKey, store)
+
+	return store
+}
+
+// loginLockoutIdentityKey returns the lockout key of the identity
+// (email or username) within the specified scope (collection id or admins).
+func loginLockoutIdentityKey(scope string, identity string) string {
+	return "identity:" + scope + ":" + strings.ToLower(identity)
+}
+
+// loginLockoutIpKey returns the lockout key of the client IP.
+func loginLockoutIpKey(ip string) string {
+	return "ip:" + ip
+}
+
+// loginLockout tracks the failed auth-with-password (or auth-with-mfa)
+// attempts of a single request identity and client IP.
+//
+// The client IP is resolved from the proxy headers only for the
+// trusted proxies (see [settings.TrustedProxyConfig]).
+//
+// All methods are no-op if the app login lockout is disabled
+// (aka. app.Settings().LoginLockout.Enabled = false).
+type loginLockout struct {
+	app         core.App
+	c           echo.Context
+	identityKey string
+	ipKey       string
+}
+
+func newLoginLockout(app core.App, c echo.Context, scope string, identity string) *loginLockout {
+	return &loginLockout{
+		app:         app,
+		c:           c,
+		identityKey: loginLockoutIdentityKey(scope, identity),
+		ipKey:       loginLockoutIpKey(trustedUserIp(app, c.Request())),
+	}
+}
+
+// newMFALoginLockout creates a new login lockout for the auth-with-mfa
+// code attempts of the mfaId challenge account.
+//
+// The account id is read from the not verified mfaId claims so the
+// failures must be registered only after the challenge is verified
+// (see [forms.IsInvalidMFACodeError]).
+func newMFALoginLockout(app core.App, c echo.Context, scope string, mfaId string) *loginLockout {
+	claims, _ := security.ParseUnverifiedJWT(mfaId)
+
+	return newLoginLockout(app, c, scope, "mfa:"+cast.ToString(claims["id"]))
+}
+
+// check returns 429 Too Many Requests error (with a Retry-After header)
+// if either the request identity or the client IP is locked.
+func (l *loginLockout) check() error {
+	if !l.app.Settings().LoginLockout.Enabled {
+		return nil
+	}
+
+	store := loginLockoutStore(l.app)
+
+	var lockedFor time.Duration
+
+	for _, key := range []string{l.identityKey, l.ipKey} {
+		d, err := store.Check(key)
+		if err != nil {
+			// fail open to prevent locking out all clients on store failure
+			l.app.Logger().Debug("Login lockout check failed", requestLogAttrs(l.c, "error", err)...)
+			continue
+		}
+
+		if d > lockedFor {
+			lockedFor = d
+		}
+	}
+
+	if lockedFor > 0 {
+		l.c.Response().Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(lockedFor.Seconds()))))
+		return NewApiError(http.StatusTooManyRequests, "Too many failed login attempts. Please try again later.", nil)
+	}
+
+	return nil
+}
+
+// fail registers a failed attempt for both the request identity and the client IP.
+func (l *loginLockout) fail() {
+	config := l.app.Settings().LoginLockout
+	if !config.Enabled {
+		return
+	}
+
+	store := loginLockoutStore(l.app)
+
+	for _, key := range []string{l.identityKey, l.ipKey} {
+		lockedFor, err := store.Fail(
+			key,
+			config.MaxAttempts,
+			time.Duration(config.Window)*time.Second,
+			time.Duration(config.Duration)*time.Second,
+		)
+		if err != nil {
+			l.app.Logger().Debug("Failed to register login failure", requestLogAttrs(l.c, "error", err)...)
+			continue
+		}
+
+		if lockedFor > 0 {
+			l.app.Logger().Warn(
+				"Login lockout",
+				requestLogAttrs(l.c, "lockoutKey", key, "lockedForSeconds", int(math.Ceil(lockedFor.Seconds())))...,
+			)
+		}
+	}
+}
+
+// reset clears the failed attempts of the request identity.
+//
+// Note that the client IP counter is not reset to prevent
+// bypassing the IP lockout with a single valid account.
+func (l *loginLockout) reset() {
+	if !l.app.Settings().LoginLockout.Enabled {
+		return
+	}
+
+	if err := loginLockoutStore(l.app).Reset(l.identityKey); err != nil {
+		l.app.Logger().Debug("Failed to reset the login failures", requestLogAttrs(l.c, "error", err)...)
+	}
+}
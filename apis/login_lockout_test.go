@@ -0,0 +1,237 @@
+package apis_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/unkod/space/apis"
+	"github.com/unkod/space/tests"
+	"github.com/unkod/space/tokens"
+	"github.com/unkod/space/tools/lockout"
+)
+
+type loginLockoutRequest struct {
+	url              string
+	body             string
+	ip               string
+	expectedStatus   int
+	expectRetryAfter bool
+}
+
+func testLoginLockoutRequests(t *testing.T, enabled bool, requests []loginLockoutRequest) {
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	app.Settings().LoginLockout.Enabled = enabled
+	app.Settings().LoginLockout.MaxAttempts = 2
+	app.Settings().LoginLockout.Window = 60
+	app.Settings().LoginLockout.Duration = 120
+	app.Settings().TrustedProxy.Proxies = []string{"192.0.2.1"} // the default httptest remote address
+
+	e, err := apis.InitApi(app)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i, r := range requests {
+		req := httptest.NewRequest(http.MethodPost, r.url, strings.NewReader(r.body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("CF-Connecting-IP", r.ip)
+		rec := httptest.NewRecorder()
+
+		e.ServeHTTP(rec, req)
+
+		if rec.Code != r.expectedStatus {
+			t.Fatalf("(%d) Expected status code %d, got %d (%s)", i, r.expectedStatus, rec.Code, rec.Body.String())
+		}
+
+		retryAfter := rec.Header().Get("Retry-After")
+		if r.expectRetryAfter && retryAfter != "120" {
+			t.Fatalf("(%d) Expected Retry-After %q, got %q", i, "120", retryAfter)
+		}
+		if !r.expectRetryAfter && retryAfter != "" {
+			t.Fatalf("(%d) Expected no Retry-After header, got %q", i, retryAfter)
+		}
+	}
+}
+
+func TestLoginLockoutDisabled(t *testing.T) {
+	url := "/api/collections/users/auth-with-password"
+	invalid := `{"identity":"test@example.com","password":"invalid"}`
+	valid := `{"identity":"test@example.com","password":"1234567890"}`
+
+	testLoginLockoutRequests(t, false, []loginLockoutRequest{
+		{url, invalid, "1.1.1.1", 400, false},
+		{url, invalid, "1.1.1.1", 400, false},
+		{url, invalid, "1.1.1.1", 400, false},
+		{url, valid, "1.1.1.1", 200, false},
+	})
+}
+
+func TestLoginLockoutRecordAuthWithPassword(t *testing.T) {
+	url := "/api/collections/users/auth-with-password"
+
+	testLoginLockoutRequests(t, true, []loginLockoutRequest{
+		// failed attempt followed by a successful one should reset the identity counter
+		{url, `{"identity":"test@example.com","password":"invalid"}`, "1.1.1.1", 400, false},
+		{url, `{"identity":"test@example.com","password":"1234567890"}`, "2.2.2.2", 200, false},
+		// lock the identity (from different IPs)
+		{url, `{"identity":"test@example.com","password":"invalid"}`, "3.3.3.3", 400, false},
+		{url, `{"identity":"TEST@example.com","password":"invalid"}`, "4.4.4.4", 400, false},
+		{url, `{"identity":"test@example.com","password":"1234567890"}`, "5.5.5.5", 429, true},
+		// the same identity in another auth collection shouldn't be locked
+		{"/api/collections/clients/auth-with-password", `{"identity":"test@example.com","password":"1234567890"}`, "5.5.5.5", 200, false},
+		// lock the client IP (1.1.1.1 has already 1 failed attempt)
+		{url, `{"identity":"test2@example.com","password":"invalid"}`, "1.1.1.1", 400, false},
+		{url, `{"identity":"test3@example.com","password":"1234567890"}`, "1.1.1.1", 429, true},
+		{url, `{"identity":"test3@example.com","password":"1234567890"}`, "6.6.6.6", 200, false},
+	})
+}
+
+func TestLoginLockoutAdminAuthWithPassword(t *testing.T) {
+	url := "/api/admins/auth-with-password"
+
+	testLoginLockoutRequests(t, true, []loginLockoutRequest{
+		{url, `{"identity":"test@example.com","password":"invalid"}`, "1.1.1.1", 400, false},
+		{url, `{"identity":"test@example.com","password":"1234567890"}`, "2.2.2.2", 200, false},
+		{url, `{"identity":"test@example.com","password":"invalid"}`, "3.3.3.3", 400, false},
+		{url, `{"identity":"test@example.com","password":"invalid"}`, "4.4.4.4", 400, false},
+		{url, `{"identity":"test@example.com","password":"1234567890"}`, "5.5.5.5", 429, true},
+		// record identities shouldn't be affected by the admin lockout
+		{"/api/collections/users/auth-with-password", `{"identity":"test@example.com","password":"1234567890"}`, "5.5.5.5", 200, false},
+	})
+}
+
+func TestLoginLockoutCustomStore(t *testing.T) {
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	app.Settings().LoginLockout.Enabled = true
+	app.Settings().TrustedProxy.Proxies = []string{"192.0.2.1"}
+
+	store := lockout.NewMemoryStore()
+	app.Cache().Set(apis.LoginLockoutStoreCacheKey, store)
+
+	e, err := apis.InitApi(app)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	body := `{"identity":"test@example.com","password":"invalid"}`
+	req := httptest.NewRequest(http.MethodPost, "/api/collections/users/auth-with-password", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("CF-Connecting-IP", "1.1.1.1")
+	rec := httptest.NewRecorder()
+
+	e.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("Expected status code %d, got %d", http.StatusBadRequest, rec.Code)
+	}
+
+	// the failed attempt must be registered in the custom store
+	lockedFor, err := store.Fail("ip:1.1.1.1", 2, time.Minute, time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if lockedFor != time.Minute {
+		t.Fatalf("Expected the custom store client IP to be locked after the second failure, got %v", lockedFor)
+	}
+}
+
+func TestLoginLockoutNotTrustedProxyHeaders(t *testing.T) {
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	app.Settings().LoginLockout.Enabled = true
+	app.Settings().LoginLockout.MaxAttempts = 2
+
+	e, err := apis.InitApi(app)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	requests := []struct {
+		identity       string
+		password       string
+		ip             string
+		expectedStatus int
+	}{
+		{"test@example.com", "invalid", "1.1.1.1", 400},
+		{"test2@example.com", "invalid", "2.2.2.2", 400},
+		// the client IP headers are ignored without trusted proxies
+		// so all attempts are registered for the same remote address
+		{"test3@example.com", "1234567890", "3.3.3.3", 429},
+	}
+
+	for i, r := range requests {
+		body := `{"identity":"` + r.identity + `","password":"` + r.password + `"}`
+		req := httptest.NewRequest(http.MethodPost, "/api/collections/users/auth-with-password", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("X-Forwarded-For", r.ip)
+		rec := httptest.NewRecorder()
+
+		e.ServeHTTP(rec, req)
+
+		if rec.Code != r.expectedStatus {
+			t.Fatalf("(%d) Expected status code %d, got %d (%s)", i, r.expectedStatus, rec.Code, rec.Body.String())
+		}
+	}
+}
+
+func TestLoginLockoutRecordAuthWithMFA(t *testing.T) {
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	app.Settings().LoginLockout.Enabled = true
+	app.Settings().LoginLockout.MaxAttempts = 2
+	app.Settings().TrustedProxy.Proxies = []string{"192.0.2.1"}
+
+	record, err := app.Dao().FindAuthRecordByEmail("users", "test@example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	secret, _ := tests.EnableRecordMFA(t, app, record)
+
+	mfaId, err := tokens.NewRecordMFAToken(app, record)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	e, err := apis.InitApi(app)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	requests := []struct {
+		mfaId          string
+		code           string
+		ip             string
+		expectedStatus int
+	}{
+		// invalid challenges are not registered as failures
+		{"invalid", "000000", "1.1.1.1", 400},
+		{"invalid", "000000", "1.1.1.1", 400},
+		// lock the challenge account (from different IPs)
+		{mfaId, "000000", "2.2.2.2", 400},
+		{mfaId, "000000", "3.3.3.3", 400},
+		{mfaId, tests.MFACode(t, secret, 0), "4.4.4.4", 429},
+	}
+
+	for i, r := range requests {
+		body := `{"mfaId":"` + r.mfaId + `","code":"` + r.code + `"}`
+		req := httptest.NewRequest(http.MethodPost, "/api/collections/users/auth-with-mfa", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("X-Real-IP", r.ip)
+		rec := httptest.NewRecorder()
+
+		e.ServeHTTP(rec, req)
+
+		if rec.Code != r.expectedStatus {
+			t.Fatalf("(%d) Expected status code %d, got %d (%s)", i, r.expectedStatus, rec.Code, rec.Body.String())
+		}
+	}
+}
@@ -34,7 +34,7 @@ func (api *logsApi) requestsList(c echo.Context) error {
 	fieldResolver := search.NewSimpleFieldResolver(requestFilterFields...)
 
 	result, err := search.NewProvider(fieldResolver).
-		Query(api.app.LogsDao().RequestQuery()).
+		Query(api.app.LogsDao().WithContext(c.Request().Context()).RequestQuery()).
 		ParseAndExec(c.QueryParams().Encode(), &[]*models.Request{})
 
 	if err != nil {
@@ -58,7 +58,7 @@ func (api *logsApi) requestsStats(c echo.Context) error {
 		}
 	}
 
-	stats, err := api.app.LogsDao().RequestsStats(expr)
+	stats, err := api.app.LogsDao().WithContext(c.Request().Context()).RequestsStats(expr)
 	if err != nil {
 		return NewBadRequestError("Failed to generate requests stats.", err)
 	}
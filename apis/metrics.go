@@ -0,0 +1,217 @@
+package apis
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/labstack/echo/v5"
+	"github.com/pocketbase/dbx"
+	"github.com/unkod/space/core"
+	"github.com/unkod/space/daos"
+	"github.com/unkod/space/tools/metrics"
+)
+
+// metricsContentType is the Prometheus text exposition format content type.
+const metricsContentType = "text/plain; version=0.0.4; charset=utf-8"
+
+// apiMetrics holds the app metrics registry and the HTTP request collectors.
+type apiMetrics struct {
+	app      core.App
+	registry *metrics.Registry
+
+	requestDuration *metrics.HistogramVec
+}
+
+// newApiMetrics creates a new app metrics registry with the default
+// HTTP request, realtime and db connection pools collectors.
+func newApiMetrics(app core.App) *apiMetrics {
+	registry := metrics.NewRegistry()
+
+	m := &apiMetrics{
+		app:      app,
+		registry: registry,
+		requestDuration: registry.NewHistogramVec(
+			"space_http_request_duration_seconds",
+			"The HTTP requests duration in seconds partitioned by method, route pattern and response status.",
+			metrics.DefaultBuckets,
+			"method", "route", "status",
+		),
+	}
+
+	registry.NewGaugeFunc(
+		"space_realtime_clients",
+		"The number of the active realtime clients.",
+		func() []metrics.GaugeSample {
+			return []metrics.GaugeSample{{Value: float64(len(app.SubscriptionsBroker().Clients()))}}
+		},
+	)
+
+	registry.NewGaugeFunc(
+		"space_db_connections",
+		"The number of the db pool connections partitioned by db, pool and state.",
+		func() []metrics.GaugeSample {
+			samples := []metrics.GaugeSample{}
+			samples = append(samples, dbConnectionsSamples("data", app.Dao())...)
+			samples = append(samples, dbConnectionsSamples("logs", app.LogsDao())...)
+			return samples
+		},
+		"db", "pool", "state",
+	)
+
+	registry.NewGaugeFunc(
+		"go_goroutines",
+		"The number of the currently existing goroutines.",
+		func() []metrics.GaugeSample {
+			return []metrics.GaugeSample{{Value: float64(runtime.NumGoroutine())}}
+		},
+	)
+
+	return m
+}
+
+func dbConnectionsSamples(db string, dao *daos.Dao) []metrics.GaugeSample {
+	if dao == nil {
+		return nil
+	}
+
+	pools := []struct {
+		name    string
+		builder dbx.Builder
+	}{
+		{"concurrent", dao.ConcurrentDB()},
+		{"nonconcurrent", dao.NonconcurrentDB()},
+	}
+
+	samples := make([]metrics.GaugeSample, 0, len(pools)*3)
+
+	for _, p := range pools {
+		dbxDB, ok := p.builder.(*dbx.DB)
+		if !ok || dbxDB.DB() == nil {
+			continue
+		}
+
+		stats := dbxDB.DB().Stats()
+
+		samples = append(samples,
+			metrics.GaugeSample{LabelValues: []string{db, p.name, "open"}, Value: float64(stats.OpenConnections)},
+			metrics.GaugeSample{LabelValues: []string{db, p.name, "in_use"}, Value: float64(stats.InUse)},
+			metrics.GaugeSample{LabelValues: []string{db, p.name, "idle"}, Value: float64(stats.Idle)},
+		)
+	}
+
+	return samples
+}
+
+// middleware records the duration of the HTTP requests while the metrics are enabled.
+//
+// To keep the metrics cardinality bounded the requests are labeled
+// with the matched route pattern (eg. "/api/collections/:collection/records")
+// and not with the raw request path.
+func (m *apiMetrics) middleware() echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if !m.app.Settings().Metrics.Enabled {
+				return next(c)
+			}
+
+			start := time.Now()
+
+			err := next(c)
+
+			m.requestDuration.Observe(
+				time.Since(start).Seconds(),
+				metricsMethod(c.Request().Method),
+				metricsRoute(c),
+				strconv.Itoa(metricsStatus(c, err)),
+			)
+
+			return err
+		}
+	}
+}
+
+// metricsMethod normalizes the request method to one of the standard
+// HTTP methods so that arbitrary values couldn't be used as labels.
+func metricsMethod(method string) string {
+	method = strings.ToUpper(method)
+
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodPost, http.MethodPut,
+		http.MethodPatch, http.MethodDelete, http.MethodOptions:
+		return method
+	default:
+		return "OTHER"
+	}
+}
+
+func metricsRoute(c echo.Context) string {
+	if route := c.Path(); route != "" {
+		return route
+	}
+
+	return "unknown"
+}
+
+// metricsStatus returns the response status of the request
+// (resolving it from the error if the request has failed).
+func metricsStatus(c echo.Context, err error) int {
+	if err == nil {
+		return c.Response().Status
+	}
+
+	switch v := err.(type) {
+	case *echo.HTTPError:
+		return v.Code
+	case *ApiError:
+		return v.Code
+	default:
+		return http.StatusBadRequest
+	}
+}
+
+// bindMetricsApi registers the metrics api endpoint.
+func bindMetricsApi(app core.App, rg *echo.Group, m *apiMetrics) {
+	api := metricsApi{app: app, metrics: m}
+
+	rg.GET("/metrics", api.export)
+}
+
+type metricsApi struct {
+	app     core.App
+	metrics *apiMetrics
+}
+
+func (api *metricsApi) export(c echo.Context) error {
+	config := api.app.Settings().Metrics
+
+	if !config.Enabled {
+		return NewNotFoundError("", nil)
+	}
+
+	if config.RequireAuth && !api.isAuthorized(c, config.Token) {
+		return NewUnauthorizedError("The request requires admin authorization token or a valid metrics token to be set.", nil)
+	}
+
+	c.Response().Header().Set(echo.HeaderContentType, metricsContentType)
+	c.Response().WriteHeader(http.StatusOK)
+
+	return api.metrics.registry.WriteText(c.Response())
+}
+
+func (api *metricsApi) isAuthorized(c echo.Context, token string) bool {
+	if c.Get(ContextAdminKey) != nil {
+		return true
+	}
+
+	if token == "" {
+		return false
+	}
+
+	requestToken := strings.TrimPrefix(c.Request().Header.Get("Authorization"), "Bearer ")
+
+	return subtle.ConstantTimeCompare([]byte(requestToken), []byte(token)) == 1
+}
@@ -1,21 +1,37 @@
 package apis
 
 import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
 	"fmt"
-	"log"
+	"io"
+	"log/slog"
+	"math"
 	"net"
 	"net/http"
+	"net/url"
+	"reflect"
+	"regexp"
+	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/labstack/echo/v5"
+	"github.com/labstack/echo/v5/middleware"
 	"github.com/spf13/cast"
 	"github.com/unkod/space/core"
 	"github.com/unkod/space/models"
+	"github.com/unkod/space/models/settings"
 	"github.com/unkod/space/tokens"
 	"github.com/unkod/space/tools/list"
+	"github.com/unkod/space/tools/ratelimit"
 	"github.com/unkod/space/tools/routine"
 	"github.com/unkod/space/tools/security"
+	"github.com/unkod/space/tools/store"
 	"github.com/unkod/space/tools/types"
 )
 
@@ -24,8 +40,22 @@ const (
 	ContextAdminKey      string = "admin"
 	ContextAuthRecordKey string = "authRecord"
 	ContextCollectionKey string = "collection"
+	ContextRequestIdKey  string = "requestId"
+
+	// ContextImpersonatorKey is the context key of the impersonating
+	// admin when the request is authenticated with an impersonation token.
+	ContextImpersonatorKey string = "impersonator"
 )
 
+// contextAdminIpDeniedKey is the request context key set when a valid
+// admin token was ignored because of the admin IP filter settings.
+const contextAdminIpDeniedKey string = "@adminIpDenied"
+
+// RequestIdHeader is the name of the request/response header with the request id.
+const RequestIdHeader = "X-Request-Id"
+
+var requestIdRegex = regexp.MustCompile(`^[\w\-\.:]{1,100}$`)
+
 // RequireGuestOnly middleware requires a request to NOT have a valid
 // Authorization header.
 //
@@ -115,6 +145,10 @@ func RequireAdminAuth() echo.MiddlewareFunc {
 		return func(c echo.Context) error {
 			admin, _ := c.Get(ContextAdminKey).(*models.Admin)
 			if admin == nil {
+				if isAdminIpDenied(c) {
+					return adminIpDeniedError()
+				}
+
 				return NewUnauthorizedError("The request requires valid admin authorization token to be set.", nil)
 			}
 
@@ -134,6 +168,10 @@ func RequireAdminAuthOnlyIfAny(app core.App) echo.MiddlewareFunc {
 				return next(c)
 			}
 
+			if isAdminIpDenied(c) {
+				return adminIpDeniedError()
+			}
+
 			totalAdmins, err := app.Dao().TotalAdmins()
 			if err != nil {
 				return NewBadRequestError("Failed to fetch admins info.", err)
@@ -161,6 +199,10 @@ func RequireAdminOrRecordAuth(optCollectionNames ...string) echo.MiddlewareFunc
 			record, _ := c.Get(ContextAuthRecordKey).(*models.Record)
 
 			if admin == nil && record == nil {
+				if isAdminIpDenied(c) {
+					return adminIpDeniedError()
+				}
+
 				return NewUnauthorizedError("The request requires admin or record authorization token to be set.", nil)
 			}
 
@@ -189,6 +231,10 @@ func RequireAdminOrOwnerAuth(ownerIdParam string) echo.MiddlewareFunc {
 
 			record, _ := c.Get(ContextAuthRecordKey).(*models.Record)
 			if record == nil {
+				if isAdminIpDenied(c) {
+					return adminIpDeniedError()
+				}
+
 				return NewUnauthorizedError("The request requires admin or record authorization token to be set.", nil)
 			}
 
@@ -208,10 +254,25 @@ func RequireAdminOrOwnerAuth(ownerIdParam string) echo.MiddlewareFunc {
 	}
 }
 
+// isAdminIpDenied reports whether the request admin token was ignored
+// because of the admin IP filter settings (see [LoadAuthContext]).
+func isAdminIpDenied(c echo.Context) bool {
+	denied, _ := c.Get(contextAdminIpDeniedKey).(bool)
+
+	return denied
+}
+
+func adminIpDeniedError() *ApiError {
+	return NewForbiddenError("The request IP address is not allowed.", nil)
+}
+
 // LoadAuthContext middleware reads the Authorization request header
 // and loads the token related record or admin instance into the
 // request's context.
 //
+// Admin tokens from IPs that are not allowed by the admin IP filter
+// settings are ignored (see [AdminIpFilter]).
+//
 // This middleware is expected to be already registered by default for all routes.
 func LoadAuthContext(app core.App) echo.MiddlewareFunc {
 	return func(next echo.HandlerFunc) echo.HandlerFunc {
@@ -234,17 +295,45 @@ func LoadAuthContext(app core.App) echo.MiddlewareFunc {
 					token,
 					app.Settings().AdminAuthToken.Secret,
 				)
-				if err == nil && admin != nil {
-					c.Set(ContextAdminKey, admin)
+				if err != nil || admin == nil {
+					break
+				}
+
+				if !app.Settings().AdminIpFilter.IsAllowed(trustedUserIp(app, c.Request())) {
+					c.Set(contextAdminIpDeniedKey, true)
+					break
 				}
+
+				c.Set(ContextAdminKey, admin)
 			case tokens.TypeAuthRecord:
 				record, err := app.Dao().FindAuthRecordByToken(
 					token,
 					app.Settings().RecordAuthToken.Secret,
 				)
-				if err == nil && record != nil {
-					c.Set(ContextAuthRecordKey, record)
+				if err != nil || record == nil {
+					// fallback to the previous token key of a recently rotated auth record
+					record, err = findAuthRecordByRotatedToken(app, token, app.Settings().RecordAuthToken.Secret)
+					if err != nil || record == nil {
+						break
+					}
+				}
+
+				// impersonation tokens are valid only while the impersonation
+				// is enabled and the impersonating admin still exists
+				if impersonatorId := cast.ToString(claims[tokens.ImpersonatorClaim]); impersonatorId != "" {
+					if !app.Settings().Impersonation.Enabled {
+						break
+					}
+
+					admin, err := app.Dao().FindAdminById(impersonatorId)
+					if err != nil {
+						break
+					}
+
+					c.Set(ContextImpersonatorKey, admin)
 				}
+
+				c.Set(ContextAuthRecordKey, record)
 			}
 
 			return next(c)
@@ -255,13 +344,16 @@ func LoadAuthContext(app core.App) echo.MiddlewareFunc {
 // LoadCollectionContext middleware finds the collection with related
 // path identifier and loads it into the request context.
 //
+// Collections with disabled API (see [models.Collection.IsApiDisabled])
+// are reported as missing regardless of the request auth.
+//
 // Set optCollectionTypes to further filter the found collection by its type.
 func LoadCollectionContext(app core.App, optCollectionTypes ...string) echo.MiddlewareFunc {
 	return func(next echo.HandlerFunc) echo.HandlerFunc {
 		return func(c echo.Context) error {
 			if param := c.PathParam("collection"); param != "" {
 				collection, err := app.Dao().FindCollectionByNameOrId(param)
-				if err != nil || collection == nil {
+				if err != nil || collection == nil || collection.IsApiDisabled() {
 					return NewNotFoundError("", err)
 				}
 
@@ -277,27 +369,70 @@ func LoadCollectionContext(app core.App, optCollectionTypes ...string) echo.Midd
 	}
 }
 
+// LoadRequestId middleware assigns an id to the current request and
+// stores it in the request context and the [RequestIdHeader] response header.
+//
+// The id of the [RequestIdHeader] request header is reused if valid
+// (eg. set by a reverse proxy), otherwise a new random one is generated.
+func LoadRequestId() echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			requestId(c)
+
+			return next(c)
+		}
+	}
+}
+
+// requestId returns the current request id (lazily assigning a new one if missing).
+func requestId(c echo.Context) string {
+	if id, _ := c.Get(ContextRequestIdKey).(string); id != "" {
+		return id
+	}
+
+	id := c.Request().Header.Get(RequestIdHeader)
+	if !requestIdRegex.MatchString(id) {
+		id = security.RandomString(20)
+	}
+
+	c.Set(ContextRequestIdKey, id)
+	c.Response().Header().Set(RequestIdHeader, id)
+
+	return id
+}
+
+// requestLogAttrs returns the common structured log attributes of the current request.
+func requestLogAttrs(c echo.Context, extra ...any) []any {
+	return append([]any{
+		"requestId", requestId(c),
+		"method", strings.ToUpper(c.Request().Method),
+		"path", c.Request().URL.Path,
+	}, extra...)
+}
+
+// pendingRequestLogs tracks the request logs that are still being
+// saved in the background (see [ActivityLogger]).
+var pendingRequestLogs sync.WaitGroup
+
 // ActivityLogger middleware takes care to save the request information
-// into the logs database.
+// into the logs database and to log it with the app structured logger.
+//
+// Server errors (status >= 500) are logged with error level and
+// all other requests with debug level.
 //
-// The middleware does nothing if the app logs retention period is zero
-// (aka. app.Settings().Logs.MaxDays = 0).
+// No request information is saved in the logs database if the app logs
+// retention period is zero (aka. app.Settings().Logs.MaxDays = 0).
 func ActivityLogger(app core.App) echo.MiddlewareFunc {
 	return func(next echo.HandlerFunc) echo.HandlerFunc {
 		return func(c echo.Context) error {
-			err := next(c)
-
-			logsMaxDays := app.Settings().Logs.MaxDays
+			start := time.Now()
 
-			// no logs retention
-			if logsMaxDays == 0 {
-				return err
-			}
+			err := next(c)
 
 			httpRequest := c.Request()
 			httpResponse := c.Response()
 			status := httpResponse.Status
-			meta := types.JsonMap{}
+			meta := types.JsonMap{"requestId": requestId(c)}
 
 			if err != nil {
 				switch v := err.(type) {
@@ -315,6 +450,23 @@ func ActivityLogger(app core.App) echo.MiddlewareFunc {
 				}
 			}
 
+			logLevel := slog.LevelDebug
+			if status >= http.StatusInternalServerError {
+				logLevel = slog.LevelError
+			}
+			logAttrs := requestLogAttrs(c, "status", status, "duration", time.Since(start))
+			if admin, ok := c.Get(ContextImpersonatorKey).(*models.Admin); ok {
+				logAttrs = append(logAttrs, "impersonatorId", admin.Id)
+			}
+			app.Logger().Log(httpRequest.Context(), logLevel, "Request", logAttrs...)
+
+			logsMaxDays := app.Settings().Logs.MaxDays
+
+			// no logs retention
+			if logsMaxDays == 0 {
+				return err
+			}
+
 			requestAuth := models.RequestAuthGuest
 			if c.Get(ContextAuthRecordKey) != nil {
 				requestAuth = models.RequestAuthRecord
@@ -322,6 +474,14 @@ func ActivityLogger(app core.App) echo.MiddlewareFunc {
 				requestAuth = models.RequestAuthAdmin
 			}
 
+			// store both identities of the impersonated requests
+			if record, ok := c.Get(ContextAuthRecordKey).(*models.Record); ok {
+				if admin, ok := c.Get(ContextImpersonatorKey).(*models.Admin); ok {
+					meta["authRecordId"] = record.Id
+					meta["impersonatorId"] = admin.Id
+				}
+			}
+
 			ip, _, _ := net.SplitHostPort(httpRequest.RemoteAddr)
 
 			model := &models.Request{
@@ -340,8 +500,8 @@ func ActivityLogger(app core.App) echo.MiddlewareFunc {
 			model.RefreshUpdated()
 
 			routine.FireAndForget(func() {
-				if err := app.LogsDao().SaveRequest(model); err != nil && app.IsDebug() {
-					log.Println("Log save failed:", err)
+				if err := app.LogsDao().SaveRequest(model); err != nil {
+					app.Logger().Debug("Log save failed", "error", err)
 				}
 
 				// Delete old request logs
@@ -354,20 +514,409 @@ func ActivityLogger(app core.App) echo.MiddlewareFunc {
 					deleteErr := app.LogsDao().DeleteOldRequests(now.AddDate(0, 0, -1*logsMaxDays))
 					if deleteErr == nil {
 						app.Cache().Set("lastLogsDeletedAt", now)
-					} else if app.IsDebug() {
-						log.Println("Logs delete failed:", deleteErr)
+					} else {
+						app.Logger().Debug("Logs delete failed", "error", deleteErr)
 					}
 				}
-			})
+			}, &pendingRequestLogs)
 
 			return err
 		}
 	}
 }
 
+// Rate limit labels of the builtin route groups (see [RateLimit]).
+const (
+	RateLimitLabelAuth string = "auth"
+	RateLimitLabelRead string = "read"
+)
+
+// RateLimitStoreCacheKey is the app cache key of the store used by the [RateLimit] middleware.
+//
+// By default an in-memory store is lazily created but you can register
+// your own [ratelimit.Store] implementation (eg. Redis backed) with:
+//
+//	app.Cache().Set(apis.RateLimitStoreCacheKey, myStore)
+const RateLimitStoreCacheKey string = "@rateLimitStore"
+
+var rateLimitStoreMux sync.Mutex
+
+// RateLimit middleware limits the number of requests per client IP and route
+// based on the app settings rule with the specified label (or the
+// settings.RateLimitDefaultLabel rule if there is no explicit one).
+//
+// Rejected requests receive 429 Too Many Requests error with a Retry-After header.
+//
+// The client IP is resolved from the proxy headers only for the
+// trusted proxies (see [settings.TrustedProxyConfig]).
+//
+// The middleware does nothing if the app rate limits are disabled
+// (aka. app.Settings().RateLimits.Enabled = false).
+func RateLimit(app core.App, label string) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			config := app.Settings().RateLimits
+			if !config.Enabled {
+				return next(c)
+			}
+
+			rule := config.FindRule(label)
+			if rule == nil {
+				return next(c)
+			}
+
+			httpRequest := c.Request()
+			key := label + ":" + httpRequest.Method + " " + c.Path() + ":" + trustedUserIp(app, httpRequest)
+
+			allowed, retryAfter, err := rateLimitStore(app).Allow(
+				key,
+				rule.MaxRequests,
+				time.Duration(rule.Duration)*time.Second,
+			)
+			if err != nil {
+				// fail open to prevent locking out all clients on store failure
+				app.Logger().Debug("Rate limit check failed", requestLogAttrs(c, "error", err)...)
+				return next(c)
+			}
+
+			if !allowed {
+				c.Response().Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(retryAfter.Seconds()))))
+				return NewApiError(http.StatusTooManyRequests, "Too many requests.", nil)
+			}
+
+			return next(c)
+		}
+	}
+}
+
+// rateLimitStore returns the app rate limit store (lazily creating
+// a new in-memory one if none is registered).
+func rateLimitStore(app core.App) ratelimit.Store {
+	if store, ok := app.Cache().Get(RateLimitStoreCacheKey).(ratelimit.Store); ok {
+		return store
+	}
+
+	rateLimitStoreMux.Lock()
+	defer rateLimitStoreMux.Unlock()
+
+	// check again in case it was registered while waiting for the lock
+	if store, ok := app.Cache().Get(RateLimitStoreCacheKey).(ratelimit.Store); ok {
+		return store
+	}
+
+	store := ratelimit.NewMemoryStore()
+	app.Cache().Set(RateLimitStoreCacheKey, store)
+
+	return store
+}
+
+// IdempotencyKeyHeader is the name of the request header with the client idempotency key.
+const IdempotencyKeyHeader = "Idempotency-Key"
+
+// IdempotentReplayedHeader is the name of the response header
+// set when the response is replayed from an earlier request.
+const IdempotentReplayedHeader = "Idempotent-Replayed"
+
+// IdempotencyStoreCacheKey is the app cache key of the store used by the [Idempotency] middleware.
+const IdempotencyStoreCacheKey string = "@idempotencyStore"
+
+// idempotencyKeyTTL is the duration for which the response of an idempotent request is stored.
+const idempotencyKeyTTL = 24 * time.Hour
+
+// idempotencyMaxEntries is the max number of the stored idempotent responses.
+const idempotencyMaxEntries = 10000
+
+var idempotencyMux sync.Mutex
+
+// idempotencyEntry stores the result of a single idempotent request.
+type idempotencyEntry struct {
+	requestHash string
+	expiresAt   time.Time
+	completed   bool
+	status      int
+	contentType string
+	body        []byte
+}
+
+// Idempotency middleware makes the requests with an [IdempotencyKeyHeader]
+// retry safe by storing the successful responses for [idempotencyKeyTTL]
+// and replaying them for the next requests with the same key.
+//
+// The stored responses are scoped by the key, the request method and path
+// and the request auth state (admin, auth record or guest client IP).
+//
+// Requests with an already used key but different query or body are rejected
+// with 422 error and requests with a key that is still being processed with 409 error.
+//
+// Failed (non 2xx) requests are not stored so that the client could retry them.
+func Idempotency(app core.App) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			key := c.Request().Header.Get(IdempotencyKeyHeader)
+			if key == "" {
+				return next(c)
+			}
+
+			if len(key) > 255 {
+				return NewBadRequestError("The idempotency key must be no more than 255 characters.", nil)
+			}
+
+			body, err := io.ReadAll(c.Request().Body)
+			if err != nil {
+				return NewBadRequestError("Failed to read the request body.", err)
+			}
+			c.Request().Body = io.NopCloser(bytes.NewReader(body))
+
+			storeKey := idempotencyStoreKey(app, c, key)
+			requestHash := idempotencyHash(c.Request().URL.RawQuery, string(body))
+
+			entries := idempotencyStore(app)
+
+			idempotencyMux.Lock()
+			existing := entries.Get(storeKey)
+			if existing != nil && time.Now().After(existing.expiresAt) {
+				entries.Remove(storeKey)
+				existing = nil
+			}
+			if existing == nil {
+				pruneIdempotencyStore(entries)
+
+				entry := &idempotencyEntry{
+					requestHash: requestHash,
+					expiresAt:   time.Now().Add(idempotencyKeyTTL),
+				}
+				if !entries.SetIfLessThanLimit(storeKey, entry, idempotencyMaxEntries) {
+					idempotencyMux.Unlock()
+					app.Logger().Debug("Idempotency store limit reached", requestLogAttrs(c)...)
+					return next(c) // fail open
+				}
+			}
+			idempotencyMux.Unlock()
+
+			if existing != nil {
+				if existing.requestHash != requestHash {
+					return NewApiError(http.StatusUnprocessableEntity, "The idempotency key was already used with a different request.", nil)
+				}
+
+				if !existing.completed {
+					return NewConflictError("A request with the same idempotency key is still being processed.", nil)
+				}
+
+				c.Response().Header().Set(IdempotentReplayedHeader, "true")
+
+				return c.Blob(existing.status, existing.contentType, existing.body)
+			}
+
+			var stored bool
+			defer func() {
+				// release the key on failure (or panic) so that the request could be retried
+				if !stored {
+					entries.Remove(storeKey)
+				}
+			}()
+
+			recorder := &idempotencyRecorder{ResponseWriter: c.Response().Writer}
+			c.Response().Writer = recorder
+
+			err = next(c)
+
+			c.Response().Writer = recorder.ResponseWriter
+
+			status := c.Response().Status
+			if err != nil || status < 200 || status > 299 {
+				return err
+			}
+
+			stored = true
+
+			idempotencyMux.Lock()
+			entries.Set(storeKey, &idempotencyEntry{
+				requestHash: requestHash,
+				expiresAt:   time.Now().Add(idempotencyKeyTTL),
+				completed:   true,
+				status:      status,
+				contentType: c.Response().Header().Get(echo.HeaderContentType),
+				body:        recorder.body.Bytes(),
+			})
+			idempotencyMux.Unlock()
+
+			return nil
+		}
+	}
+}
+
+// idempotencyStore returns the app idempotency store
+// (lazily creating a new in-memory one if none is registered).
+func idempotencyStore(app core.App) *store.Store[*idempotencyEntry] {
+	idempotencyMux.Lock()
+	defer idempotencyMux.Unlock()
+
+	if s, ok := app.Cache().Get(IdempotencyStoreCacheKey).(*store.Store[*idempotencyEntry]); ok {
+		return s
+	}
+
+	s := store.New[*idempotencyEntry](nil)
+	app.Cache().Set(IdempotencyStoreCacheKey, s)
+
+	return s
+}
+
+// pruneIdempotencyStore removes the expired store entries
+// if the store is close to its max allowed limit.
+//
+// NB! Must be called while holding the idempotencyMux lock.
+func pruneIdempotencyStore(entries *store.Store[*idempotencyEntry]) {
+	if entries.Length() < idempotencyMaxEntries/2 {
+		return
+	}
+
+	now := time.Now()
+	for k, entry := range entries.GetAll() {
+		if now.After(entry.expiresAt) {
+			entries.Remove(k)
+		}
+	}
+}
+
+// idempotencyStoreKey returns the store key of the idempotency
+// key scoped by the request endpoint and auth state (admin, auth record or guest IP).
+func idempotencyStoreKey(app core.App, c echo.Context, key string) string {
+	auth := "guest:" + trustedUserIp(app, c.Request())
+	if admin, _ := c.Get(ContextAdminKey).(*models.Admin); admin != nil {
+		auth = "admin:" + admin.Id
+	} else if record, _ := c.Get(ContextAuthRecordKey).(*models.Record); record != nil {
+		auth = "record:" + record.Collection().Id + ":" + record.Id
+	}
+
+	return idempotencyHash(key, c.Request().Method, c.Request().URL.Path, auth)
+}
+
+func idempotencyHash(parts ...string) string {
+	h := sha256.New()
+	for _, p := range parts {
+		// length prefix the parts to avoid ambiguous concatenations
+		fmt.Fprintf(h, "%d:%s", len(p), p)
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// idempotencyRecorder is a [http.ResponseWriter] that keeps
+// a copy of the written response body.
+type idempotencyRecorder struct {
+	http.ResponseWriter
+	body bytes.Buffer
+}
+
+func (r *idempotencyRecorder) Write(b []byte) (int, error) {
+	r.body.Write(b)
+	return r.ResponseWriter.Write(b)
+}
+
+// Unwrap returns the original response writer (used by [http.ResponseController]).
+func (r *idempotencyRecorder) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
+}
+
+// AdminIpFilter middleware rejects with 403 error the requests
+// whose client IP is not allowed by the admin IP filter settings
+// (see [settings.IpFilterConfig]).
+//
+// The admin tokens of the not allowed IPs are always ignored by the
+// [LoadAuthContext] middleware and rejected by the RequireAdmin* middlewares,
+// so this middleware is needed only for the unauthenticated admin routes
+// (eg. the admins auth and password reset ones).
+//
+// The client IP is resolved from the proxy headers only for the trusted
+// proxies (see [settings.TrustedProxyConfig]) and the middleware should be
+// registered after the [ActivityLogger] in order the denied requests to be logged.
+func AdminIpFilter(app core.App) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if !app.Settings().AdminIpFilter.IsAllowed(trustedUserIp(app, c.Request())) {
+				return adminIpDeniedError()
+			}
+
+			return next(c)
+		}
+	}
+}
+
+// Cors middleware sets the CORS response headers based on the app
+// Cors settings (see [settings.CorsConfig]) and responds directly to
+// the preflight OPTIONS requests.
+//
+// If the Cors settings are not enabled, fallbackOrigins are allowed instead
+// with the default methods and headers (defaults to "*" if empty).
+func Cors(app core.App, fallbackOrigins []string) echo.MiddlewareFunc {
+	if len(fallbackOrigins) == 0 {
+		fallbackOrigins = []string{"*"}
+	}
+
+	fallback := middleware.CORSWithConfig(middleware.CORSConfig{
+		AllowOrigins: fallbackOrigins,
+		AllowMethods: []string{http.MethodGet, http.MethodHead, http.MethodPut, http.MethodPatch, http.MethodPost, http.MethodDelete},
+	})
+
+	type cachedCors struct {
+		rule       settings.CorsRule
+		middleware echo.MiddlewareFunc
+	}
+
+	var mux sync.Mutex
+	var corsConfig settings.CorsConfig
+	var cached []cachedCors
+
+	// reuses the rules middlewares until the cors settings are changed
+	getCors := func(config settings.CorsConfig, rule settings.CorsRule) echo.MiddlewareFunc {
+		mux.Lock()
+		defer mux.Unlock()
+
+		if !reflect.DeepEqual(corsConfig, config) {
+			corsConfig = config
+			cached = nil
+		}
+
+		for _, item := range cached {
+			if reflect.DeepEqual(item.rule, rule) {
+				return item.middleware
+			}
+		}
+
+		isOriginAllowed := rule.OriginMatcher()
+
+		m := middleware.CORSWithConfig(middleware.CORSConfig{
+			AllowOriginFunc: func(origin string) (bool, error) {
+				return isOriginAllowed(origin), nil
+			},
+			AllowMethods:     rule.AllowMethods,
+			AllowHeaders:     rule.AllowHeaders,
+			AllowCredentials: rule.AllowCredentials,
+			MaxAge:           rule.MaxAge,
+		})
+
+		cached = append(cached, cachedCors{rule: rule, middleware: m})
+
+		return m
+	}
+
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			config := app.Settings().Cors
+			if !config.Enabled {
+				return fallback(next)(c)
+			}
+
+			rule := config.RuleFor(c.Request().URL.Path)
+
+			return getCors(config, rule)(next)(c)
+		}
+	}
+}
+
 // Returns the "real" user IP from common proxy headers (or fallbackIp if none is found).
 //
 // The returned IP value shouldn't be trusted if not behind a trusted reverse proxy!
+// Use [trustedUserIp()] for access control checks.
 func realUserIp(r *http.Request, fallbackIp string) string {
 	if ip := r.Header.Get("CF-Connecting-IP"); ip != "" {
 		return ip
@@ -395,6 +944,558 @@ func realUserIp(r *http.Request, fallbackIp string) string {
 	return fallbackIp
 }
 
+// trustedUserIp returns the request user IP resolved from the
+// proxy headers only if the request remote address is one of the
+// app settings trusted proxies (see [settings.TrustedProxyConfig]).
+//
+// Otherwise, the remote address is returned as it is since the
+// proxy headers could be freely set by the client.
+//
+// For the X-Forwarded-For header the rightmost non-trusted IP is
+// returned because the leftmost entries could be spoofed by the client.
+func trustedUserIp(app core.App, r *http.Request) string {
+	remoteIp, _, _ := net.SplitHostPort(r.RemoteAddr)
+
+	config := app.Settings().TrustedProxy
+	if !config.IsTrusted(remoteIp) {
+		return remoteIp
+	}
+
+	for _, header := range []string{"CF-Connecting-IP", "Fly-Client-IP", "X-Real-IP"} {
+		if ip := strings.TrimSpace(r.Header.Get(header)); ip != "" {
+			return ip
+		}
+	}
+
+	result := remoteIp
+
+	ips := strings.Split(strings.Join(r.Header.Values("X-Forwarded-For"), ","), ",")
+	for i := len(ips) - 1; i >= 0; i-- {
+		ip := strings.TrimSpace(ips[i])
+		if ip == "" {
+			continue
+		}
+
+		result = ip
+
+		if !config.IsTrusted(ip) {
+			break
+		}
+	}
+
+	return result
+}
+
+// ErrBodyLimitExceeded is returned by the limited request body reader
+// when the request body size exceeds the configured limit.
+var ErrBodyLimitExceeded = errors.New("the request body is too large")
+
+const contextBodyLimitReaderKey = "@bodyLimitReader"
+
+// BodyLimit middleware limits the request body size to maxSize bytes
+// (0 or negative value means no limit).
+//
+// It could be used to override the app settings body limits for a
+// specific route or route group.
+//
+// Note that the "/api" group routes bodies are eagerly read (up to the
+// app settings limits) before the route group middlewares, so for these
+// routes the override can only lower the limit and the already read
+// body size is checked before continuing with the route handler.
+//
+// Requests with larger body receive 413 Request Entity Too Large error.
+func BodyLimit(maxSize int64) echo.MiddlewareFunc {
+	return bodyLimit(func(c echo.Context) int64 {
+		return maxSize
+	})
+}
+
+// defaultBodyLimit middleware limits the request body size based on the app
+// settings (using the MaxUploadSize limit for multipart/form-data requests and
+// the MaxSize limit for everything else).
+func defaultBodyLimit(app core.App) echo.MiddlewareFunc {
+	return bodyLimit(func(c echo.Context) int64 {
+		config := app.Settings().BodyLimits
+
+		ctype := c.Request().Header.Get(echo.HeaderContentType)
+		if strings.HasPrefix(ctype, echo.MIMEMultipartForm) {
+			return config.MaxUploadSize
+		}
+
+		return config.MaxSize
+	})
+}
+
+func bodyLimit(limitFunc func(c echo.Context) int64) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			req := c.Request()
+			if req.Body == nil || req.Body == http.NoBody {
+				return next(c)
+			}
+
+			limit := limitFunc(c)
+
+			// replace the limit of an already registered reader
+			// (the size check is performed on the next body read or
+			// immediately if the body was already read, eg. eagerly)
+			if r, ok := c.Get(contextBodyLimitReaderKey).(*limitedBodyReader); ok {
+				r.setLimit(limit)
+				if r.isExceeded() {
+					return NewRequestEntityTooLargeError("", nil)
+				}
+				return next(c)
+			}
+
+			r := &limitedBodyReader{
+				ReadCloser:    req.Body,
+				contentLength: req.ContentLength,
+				limit:         limit,
+			}
+			req.Body = r
+			c.Set(contextBodyLimitReaderKey, r)
+
+			err := next(c)
+
+			if r.isExceeded() {
+				return NewRequestEntityTooLargeError("", nil)
+			}
+
+			return err
+		}
+	}
+}
+
+// isBodyLimitExceeded reports whether the request body has exceeded its size limit.
+func isBodyLimitExceeded(c echo.Context) bool {
+	r, ok := c.Get(contextBodyLimitReaderKey).(*limitedBodyReader)
+
+	return ok && r.isExceeded()
+}
+
+// limitedBodyReader is a request body reader that fails
+// with ErrBodyLimitExceeded after reading more than limit bytes.
+//
+// Requests with larger Content-Length are rejected
+// on the first read without reading the body.
+type limitedBodyReader struct {
+	io.ReadCloser
+
+	mux           sync.Mutex
+	contentLength int64
+	limit         int64
+	read          int64
+	exceeded      bool
+}
+
+func (r *limitedBodyReader) setLimit(limit int64) {
+	r.mux.Lock()
+	defer r.mux.Unlock()
+
+	r.limit = limit
+
+	if r.limit > 0 && r.read > r.limit {
+		r.exceeded = true
+	}
+}
+
+func (r *limitedBodyReader) isExceeded() bool {
+	r.mux.Lock()
+	defer r.mux.Unlock()
+
+	return r.exceeded
+}
+
+// Read implements the [io.Reader] interface.
+func (r *limitedBodyReader) Read(p []byte) (int, error) {
+	r.mux.Lock()
+	defer r.mux.Unlock()
+
+	if r.exceeded {
+		return 0, ErrBodyLimitExceeded
+	}
+
+	if r.limit > 0 && r.contentLength > r.limit {
+		r.exceeded = true
+		return 0, ErrBodyLimitExceeded
+	}
+
+	n, err := r.ReadCloser.Read(p)
+
+	r.read += int64(n)
+
+	if r.limit > 0 && r.read > r.limit {
+		r.exceeded = true
+		return n, ErrBodyLimitExceeded
+	}
+
+	return n, err
+}
+
+// ErrRequestTimeout is the cancellation cause of the request context
+// when the request timeout is exceeded.
+var ErrRequestTimeout = errors.New("the request timeout was exceeded")
+
+const contextRequestTimerKey = "@requestTimer"
+
+// RequestTimeout middleware limits the request handling duration to
+// timeout (0 or negative value means no timeout).
+//
+// It could be used to override the app settings request timeout for a
+// specific route or route group (eg. longer for slow exports).
+//
+// See [defaultRequestTimeout()] for more details how the timeout is applied.
+func RequestTimeout(timeout time.Duration) echo.MiddlewareFunc {
+	return requestTimeout(func(c echo.Context) time.Duration {
+		return timeout
+	})
+}
+
+// defaultRequestTimeout middleware limits the request handling duration
+// based on the app settings.
+//
+// When the timeout is exceeded the request context is cancelled
+// (aborting the running queries of the request bound daos) and the
+// request fails with 503 Service Unavailable error.
+//
+// The timer is stopped once the response is committed, so already
+// started (eg. streamed) responses are never interrupted.
+func defaultRequestTimeout(app core.App) echo.MiddlewareFunc {
+	return requestTimeout(func(c echo.Context) time.Duration {
+		return time.Duration(app.Settings().Timeouts.Request) * time.Second
+	})
+}
+
+func requestTimeout(timeoutFunc func(c echo.Context) time.Duration) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			timeout := timeoutFunc(c)
+
+			// replace the timeout of an already registered timer
+			if t, ok := c.Get(contextRequestTimerKey).(*requestTimer); ok {
+				t.reset(timeout)
+				return next(c)
+			}
+
+			if timeout <= 0 {
+				return next(c)
+			}
+
+			ctx, cancel := context.WithCancelCause(c.Request().Context())
+			defer cancel(nil)
+
+			t := &requestTimer{cancel: cancel}
+			t.reset(timeout)
+			defer t.stop()
+
+			c.Set(contextRequestTimerKey, t)
+			c.SetRequest(c.Request().WithContext(ctx))
+			c.Response().Before(t.stop)
+
+			err := next(c)
+
+			if !c.Response().Committed && errors.Is(context.Cause(ctx), ErrRequestTimeout) {
+				return NewApiError(http.StatusServiceUnavailable, "The request took too long to process.", ErrRequestTimeout)
+			}
+
+			return err
+		}
+	}
+}
+
+// requestTimer cancels the request context with [ErrRequestTimeout]
+// after the configured timeout, unless stopped.
+type requestTimer struct {
+	mux     sync.Mutex
+	timer   *time.Timer
+	cancel  context.CancelCauseFunc
+	version int
+	stopped bool
+}
+
+// reset restarts the timer with the new timeout
+// (0 or negative value disables the timer).
+func (t *requestTimer) reset(timeout time.Duration) {
+	t.mux.Lock()
+	defer t.mux.Unlock()
+
+	if t.stopped {
+		return
+	}
+
+	if t.timer != nil {
+		t.timer.Stop()
+		t.timer = nil
+	}
+
+	// invalidate the already fired but not yet expired timers
+	t.version++
+
+	if timeout > 0 {
+		version := t.version
+		t.timer = time.AfterFunc(timeout, func() {
+			t.expire(version)
+		})
+	}
+}
+
+func (t *requestTimer) stop() {
+	t.mux.Lock()
+	defer t.mux.Unlock()
+
+	t.stopped = true
+
+	if t.timer != nil {
+		t.timer.Stop()
+	}
+}
+
+func (t *requestTimer) expire(version int) {
+	t.mux.Lock()
+	defer t.mux.Unlock()
+
+	if !t.stopped && t.version == version {
+		t.cancel(ErrRequestTimeout)
+	}
+}
+
+// CancelKeyHeader is the name of the request header with the client
+// cancellation key (see [defaultRequestCancellation()]).
+const CancelKeyHeader = "X-Cancel-Key"
+
+// StatusRequestCancelled is the response status code of the requests
+// cancelled by a newer request with the same cancellation key
+// (the nonstandard "499 Client Closed Request" status).
+const StatusRequestCancelled = 499
+
+// ErrRequestCancelled is the cancellation cause of the request context
+// when a newer request with the same cancellation key arrives.
+var ErrRequestCancelled = errors.New("the request was cancelled by a newer one with the same cancel key")
+
+// defaultRequestCancellation middleware cancels the still in-flight
+// request with the same [CancelKeyHeader] value and auth state
+// (admin, auth record or trusted proxy resolved guest IP) when a new one arrives
+// (eg. to skip the no longer needed rapid typeahead queries).
+//
+// The cancelled request context is aborted with [ErrRequestCancelled]
+// (aborting the running queries of the request bound daos) and the
+// request fails with [StatusRequestCancelled] error.
+//
+// Requests without a cancellation key are never cancelled and similar
+// to the request timeout, already committed responses are not interrupted.
+func defaultRequestCancellation(app core.App) echo.MiddlewareFunc {
+	var mux sync.Mutex
+	inflight := map[string]*inflightRequest{}
+
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			key := c.Request().Header.Get(CancelKeyHeader)
+			if key == "" {
+				return next(c)
+			}
+
+			if len(key) > 255 {
+				return NewBadRequestError("The cancel key must be no more than 255 characters.", nil)
+			}
+
+			storeKey := requestCancellationKey(app, c, key)
+
+			ctx, cancel := context.WithCancelCause(c.Request().Context())
+			defer cancel(nil)
+
+			current := &inflightRequest{cancel: cancel}
+
+			mux.Lock()
+			if previous, ok := inflight[storeKey]; ok {
+				previous.cancel(ErrRequestCancelled)
+			}
+			inflight[storeKey] = current
+			mux.Unlock()
+
+			release := func() {
+				mux.Lock()
+				defer mux.Unlock()
+
+				// the key could be already tracked by a newer request
+				if inflight[storeKey] == current {
+					delete(inflight, storeKey)
+				}
+			}
+			defer release()
+
+			c.SetRequest(c.Request().WithContext(ctx))
+			c.Response().Before(release)
+
+			err := next(c)
+
+			if !c.Response().Committed && errors.Is(context.Cause(ctx), ErrRequestCancelled) {
+				return NewApiError(StatusRequestCancelled, "The request was cancelled by a newer request.", ErrRequestCancelled)
+			}
+
+			return err
+		}
+	}
+}
+
+// inflightRequest is a single tracked request with a cancellation key.
+type inflightRequest struct {
+	cancel context.CancelCauseFunc
+}
+
+// requestCancellationKey returns the in-flight requests tracking key
+// of the cancellation key scoped by the request auth state.
+func requestCancellationKey(app core.App, c echo.Context, key string) string {
+	var auth string
+	if admin, _ := c.Get(ContextAdminKey).(*models.Admin); admin != nil {
+		auth = "admin:" + admin.Id
+	} else if record, _ := c.Get(ContextAuthRecordKey).(*models.Record); record != nil {
+		auth = "record:" + record.Collection().Id + ":" + record.Id
+	} else {
+		auth = "guest:" + trustedUserIp(app, c.Request())
+	}
+
+	return idempotencyHash(key, auth)
+}
+
+// compressionSkipPrefixes lists the request path prefixes of the routes
+// whose responses are never compressed (realtime streams and file
+// downloads that are usually already compressed).
+var compressionSkipPrefixes = []string{
+	"/api/realtime",
+	"/api/files/",
+	"/api/backups/",
+}
+
+// defaultCompression middleware gzip compresses the responses
+// based on the app settings and the request Accept-Encoding header.
+//
+// The responses smaller than the configured min length are sent
+// uncompressed. Realtime (SSE) streams and file downloads are skipped.
+func defaultCompression(app core.App) echo.MiddlewareFunc {
+	var mux sync.Mutex
+	var gzipConfig settings.CompressionConfig
+	var gzip echo.MiddlewareFunc
+
+	// reuses the gzip middleware (and its writers pool)
+	// until the compression settings are changed
+	getGzip := func(config settings.CompressionConfig) echo.MiddlewareFunc {
+		mux.Lock()
+		defer mux.Unlock()
+
+		if gzip == nil || gzipConfig != config {
+			gzipConfig = config
+			gzip = middleware.GzipWithConfig(middleware.GzipConfig{
+				Level:     config.Level,
+				MinLength: config.MinLength,
+			})
+		}
+
+		return gzip
+	}
+
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			config := app.Settings().Compression
+			if !config.Enabled || skipCompression(c) {
+				return next(c)
+			}
+
+			return getGzip(config)(next)(c)
+		}
+	}
+}
+
+func skipCompression(c echo.Context) bool {
+	if strings.Contains(c.Request().Header.Get(echo.HeaderAccept), "text/event-stream") {
+		return true
+	}
+
+	path := c.Request().URL.Path
+	for _, prefix := range compressionSkipPrefixes {
+		if strings.HasPrefix(path, prefix) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// readOnlySkipPrefixes lists the request path prefixes of the mutation
+// routes that are still allowed in read-only maintenance mode
+// (the admin settings and backups management and the realtime subscriptions).
+var readOnlySkipPrefixes = []string{
+	"/api/settings",
+	"/api/backups",
+	"/api/realtime",
+	"/api/admins/auth-with-password",
+	"/api/admins/auth-with-mfa",
+	"/api/admins/auth-refresh",
+}
+
+// readOnlySkipSuffixes lists the request path suffixes of the
+// auth record routes that are still allowed in read-only maintenance mode.
+var readOnlySkipSuffixes = []string{
+	"/auth-with-password",
+	"/auth-with-mfa",
+	"/auth-refresh",
+}
+
+// readOnlyMode middleware rejects with 503 error the API mutation
+// requests while the app is in read-only maintenance mode.
+//
+// The safe (GET, HEAD, OPTIONS) requests, the admin maintenance routes
+// and the password/mfa/refresh auth routes are always allowed.
+func readOnlyMode(app core.App) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			config := app.Settings().Maintenance
+			optimizing := app.Cache().Has(core.CacheKeyActiveOptimize)
+			if (!config.ReadOnly && !optimizing) || !isReadOnlyRestricted(c) {
+				return next(c)
+			}
+
+			if !config.ReadOnly {
+				return NewServiceUnavailableError("Database maintenance is in progress. Please try again later.", nil)
+			}
+
+			message := config.Message
+			if message == "" {
+				message = "The server is in read-only maintenance mode. Please try again later."
+			}
+
+			return NewServiceUnavailableError(message, nil)
+		}
+	}
+}
+
+func isReadOnlyRestricted(c echo.Context) bool {
+	switch c.Request().Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return false
+	}
+
+	path := c.Request().URL.Path
+	if !strings.HasPrefix(path, "/api/") {
+		return false
+	}
+
+	for _, prefix := range readOnlySkipPrefixes {
+		if strings.HasPrefix(path, prefix) {
+			return false
+		}
+	}
+
+	if strings.HasPrefix(path, "/api/collections/") {
+		for _, suffix := range readOnlySkipSuffixes {
+			if strings.HasSuffix(path, suffix) {
+				return false
+			}
+		}
+	}
+
+	return true
+}
+
 // eagerRequestInfoCache ensures that the request data is cached in the request
 // context to allow reading for example the json request body data more than once.
 func eagerRequestInfoCache(app core.App) echo.MiddlewareFunc {
@@ -404,9 +1505,135 @@ func eagerRequestInfoCache(app core.App) echo.MiddlewareFunc {
 			// currently we are eagerly caching only the requests with body
 			case "POST", "PUT", "PATCH", "DELETE":
 				RequestInfo(c)
+
+				// reject early instead of continuing with partially read body data
+				if isBodyLimitExceeded(c) {
+					return NewRequestEntityTooLargeError("", nil)
+				}
 			}
 
 			return next(c)
 		}
 	}
 }
+
+// isCustomRoutePath reports whether the request path is not from the "/api/*" routes.
+func isCustomRoutePath(path string) bool {
+	return !strings.HasPrefix(path, "/api/")
+}
+
+// caseInsensitiveRoutes rewrites the custom routes request path
+// static segments to the ones of the best matching registered route
+// (if app.Settings().Routes.CaseInsensitive is enabled).
+func caseInsensitiveRoutes(app core.App, e *echo.Echo) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			u := c.Request().URL
+
+			if app.Settings().Routes.CaseInsensitive && isCustomRoutePath(u.Path) {
+				foldRoutePath(u, e.Router().Routes())
+			}
+
+			return next(c)
+		}
+	}
+}
+
+// foldRoutePath replaces the url path static segments with the
+// ones of the most specific case-insensitively matching route.
+//
+// The url is not changed if the most specific route
+// already matches the url path case-sensitively.
+func foldRoutePath(u *url.URL, routes echo.Routes) {
+	segments := strings.Split(u.Path, "/")
+
+	var best []string
+	bestScore := -1
+	bestExact := false
+
+	for _, r := range routes {
+		if !isCustomRoutePath(r.Path()) {
+			continue
+		}
+
+		static, score, exact, ok := matchRouteSegments(strings.Split(r.Path(), "/"), segments)
+		if !ok {
+			continue
+		}
+
+		if score > bestScore || (score == bestScore && exact && !bestExact) {
+			best = static
+			bestScore = score
+			bestExact = exact
+		}
+	}
+
+	if best == nil || bestExact {
+		return
+	}
+
+	// the escaped path segments must be also replaced since the router matches against it
+	var rawSegments []string
+	if u.RawPath != "" {
+		rawSegments = strings.Split(u.RawPath, "/")
+		if len(rawSegments) != len(segments) {
+			return // encoded path separators
+		}
+	}
+
+	for i, s := range best {
+		if s == "" {
+			continue
+		}
+
+		segments[i] = s
+		if rawSegments != nil {
+			rawSegments[i] = url.PathEscape(s)
+		}
+	}
+
+	u.Path = strings.Join(segments, "/")
+	if rawSegments != nil {
+		u.RawPath = strings.Join(rawSegments, "/")
+	}
+}
+
+// matchRouteSegments case-insensitively matches the route path segments
+// against the request path ones.
+//
+// static contains the matched route static segments at their
+// path position (or empty string for the dynamic ones) and score
+// is the total number of the matched static segments.
+func matchRouteSegments(routeSegments []string, pathSegments []string) (static []string, score int, exact bool, ok bool) {
+	static = make([]string, len(pathSegments))
+	exact = true
+
+	for i, rs := range routeSegments {
+		// wildcard (matches the remaining segments)
+		if strings.Contains(rs, "*") {
+			return static, score, exact, i < len(pathSegments)
+		}
+
+		if i >= len(pathSegments) {
+			return nil, 0, false, false
+		}
+
+		// path param
+		if strings.Contains(rs, ":") {
+			continue
+		}
+
+		if !strings.EqualFold(rs, pathSegments[i]) {
+			return nil, 0, false, false
+		}
+
+		if rs != pathSegments[i] {
+			exact = false
+		}
+
+		static[i] = rs
+		score++
+	}
+
+	return static, score, exact, len(routeSegments) == len(pathSegments)
+}
@@ -0,0 +1,473 @@
+package apis
+
+import (
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/labstack/echo/v5"
+	"github.com/unkod/space/core"
+	"github.com/unkod/space/models"
+	"github.com/unkod/space/models/schema"
+	"github.com/unkod/space/tools/openapi"
+	"github.com/unkod/space/tools/search"
+)
+
+// OpenApiRoutesCacheKey is the app cache key of the registered
+// custom [OpenApiRoute] definitions.
+const OpenApiRoutesCacheKey = "@openApiRoutes"
+
+// OpenApiRoute defines a self-describing api route
+// that is included in the generated OpenAPI document.
+type OpenApiRoute struct {
+	Method      string
+	Path        string
+	Handler     echo.HandlerFunc
+	Middlewares []echo.MiddlewareFunc
+
+	Summary     string
+	Description string
+	Tags        []string
+
+	// Params lists the route path, query and header parameters.
+	//
+	// Undescribed path params are autogenerated from the route path.
+	Params []*openapi.Parameter
+
+	// RequestSchema is the optional json request body schema.
+	RequestSchema *openapi.Schema
+
+	// ResponseSchema is the optional json success (200) response schema.
+	ResponseSchema *openapi.Schema
+}
+
+// OpenApiRouter defines the common route registration
+// interface of [echo.Echo] and [echo.Group].
+type OpenApiRouter interface {
+	Add(method string, path string, handler echo.HandlerFunc, middleware ...echo.MiddlewareFunc) echo.RouteInfo
+}
+
+// AddOpenApiRoute registers the provided route to the router and stores
+// its metadata so that it could be included in the app OpenAPI document.
+//
+// Example:
+//
+//	app.OnBeforeServe().Add(func(e *core.ServeEvent) error {
+//		apis.AddOpenApiRoute(app, e.Router, apis.OpenApiRoute{
+//			Method:  http.MethodGet,
+//			Path:    "/api/hello/:name",
+//			Summary: "Greets the specified name.",
+//			Handler: func(c echo.Context) error {
+//				return c.String(http.StatusOK, "Hello "+c.PathParam("name"))
+//			},
+//			ResponseSchema: &openapi.Schema{Type: "string"},
+//		})
+//		return nil
+//	})
+func AddOpenApiRoute(app core.App, router OpenApiRouter, route OpenApiRoute) echo.RouteInfo {
+	info := router.Add(route.Method, route.Path, route.Handler, route.Middlewares...)
+
+	// store the route with its full path (eg. with the group prefix)
+	route.Path = info.Path()
+
+	openApiRoutesRegistry(app).set(route)
+
+	return info
+}
+
+// NewOpenApiDocument generates a new OpenAPI document describing the
+// custom registered routes (see [AddOpenApiRoute]), the per collection
+// records crud routes and the remaining provided router routes.
+func NewOpenApiDocument(app core.App, routes echo.Routes) (*openapi.Document, error) {
+	doc := openapi.NewDocument(app.Settings().Meta.AppName+" API", "1.0.0")
+
+	// custom routes
+	// ---
+	for _, route := range openApiRoutesRegistry(app).list() {
+		path, params := openapi.ConvertPath(route.Path)
+
+		doc.AddOperation(route.Method, path, openApiRouteOperation(route, params))
+	}
+
+	// records crud routes
+	// ---
+	collections := []*models.Collection{}
+	if err := app.Dao().CollectionQuery().OrderBy("name ASC").All(&collections); err != nil {
+		return nil, err
+	}
+
+	for _, collection := range collections {
+		addOpenApiRecordOperations(doc, collection)
+	}
+
+	// remaining api routes
+	// ---
+	for _, route := range routes {
+		method := route.Method()
+		routePath := route.Path()
+
+		if !strings.HasPrefix(routePath, "/api/") ||
+			routePath == "/api/*" ||
+			method == echo.RouteNotFound ||
+			routePath == "/api/collections/:collection/records" ||
+			routePath == "/api/collections/:collection/records/:id" {
+			continue // not an api route or already described per collection
+		}
+
+		path, params := openapi.ConvertPath(routePath)
+		if doc.HasOperation(method, path) {
+			continue // already described
+		}
+
+		doc.AddOperation(method, path, &openapi.Operation{
+			Tags:       openApiPathTags(routePath),
+			Parameters: openApiPathParams(params, nil),
+			Responses:  openApiDefaultResponses(),
+		})
+	}
+
+	return doc, nil
+}
+
+// bindOpenApi registers the OpenAPI document api endpoint.
+func bindOpenApi(app core.App, rg *echo.Group) {
+	rg.GET("/openapi.json", func(c echo.Context) error {
+		doc, err := NewOpenApiDocument(app, c.Echo().Router().Routes())
+		if err != nil {
+			return NewBadRequestError("Failed to generate the OpenAPI document.", err)
+		}
+
+		return c.JSON(http.StatusOK, doc)
+	}, ActivityLogger(app), RequireAdminAuth())
+}
+
+// -------------------------------------------------------------------
+
+var openApiRoutesRegistryMux sync.Mutex
+
+type openApiRoutes struct {
+	mux    sync.RWMutex
+	routes map[string]OpenApiRoute
+	order  []string
+}
+
+func (r *openApiRoutes) set(route OpenApiRoute) {
+	r.mux.Lock()
+	defer r.mux.Unlock()
+
+	key := route.Method + " " + route.Path
+
+	if _, ok := r.routes[key]; !ok {
+		r.order = append(r.order, key)
+	}
+
+	r.routes[key] = route
+}
+
+func (r *openApiRoutes) list() []OpenApiRoute {
+	r.mux.RLock()
+	defer r.mux.RUnlock()
+
+	result := make([]OpenApiRoute, len(r.order))
+	for i, key := range r.order {
+		result[i] = r.routes[key]
+	}
+
+	return result
+}
+
+func openApiRoutesRegistry(app core.App) *openApiRoutes {
+	openApiRoutesRegistryMux.Lock()
+	defer openApiRoutesRegistryMux.Unlock()
+
+	if registry, ok := app.Cache().Get(OpenApiRoutesCacheKey).(*openApiRoutes); ok {
+		return registry
+	}
+
+	registry := &openApiRoutes{routes: map[string]OpenApiRoute{}}
+	app.Cache().Set(OpenApiRoutesCacheKey, registry)
+
+	return registry
+}
+
+func openApiRouteOperation(route OpenApiRoute, pathParams []string) *openapi.Operation {
+	op := &openapi.Operation{
+		Summary:     route.Summary,
+		Description: route.Description,
+		Tags:        route.Tags,
+		Parameters:  openApiPathParams(pathParams, route.Params),
+		Responses:   openApiDefaultResponses(),
+	}
+
+	if len(op.Tags) == 0 {
+		op.Tags = openApiPathTags(route.Path)
+	}
+
+	if route.RequestSchema != nil {
+		op.RequestBody = &openapi.RequestBody{
+			Required: true,
+			Content:  openapi.JsonContent(route.RequestSchema),
+		}
+	}
+
+	if route.ResponseSchema != nil {
+		op.Responses["200"].Content = openapi.JsonContent(route.ResponseSchema)
+	}
+
+	return op
+}
+
+// openApiPathParams returns the explicit params extended with
+// the missing required path params.
+func openApiPathParams(pathParams []string, explicit []*openapi.Parameter) []*openapi.Parameter {
+	result := make([]*openapi.Parameter, 0, len(pathParams)+len(explicit))
+
+	for _, name := range pathParams {
+		exists := false
+		for _, p := range explicit {
+			if p.In == "path" && p.Name == name {
+				exists = true
+				break
+			}
+		}
+
+		if !exists {
+			result = append(result, &openapi.Parameter{
+				Name:     name,
+				In:       "path",
+				Required: true,
+				Schema:   &openapi.Schema{Type: "string"},
+			})
+		}
+	}
+
+	return append(result, explicit...)
+}
+
+// openApiPathTags returns the tags of an api path based on
+// its first segment after "/api/" (eg. "/api/settings/test" -> "settings").
+func openApiPathTags(path string) []string {
+	segments := strings.Split(strings.TrimPrefix(path, "/api/"), "/")
+
+	if segments[0] == "" || strings.HasPrefix(segments[0], ":") {
+		return nil
+	}
+
+	return []string{segments[0]}
+}
+
+func openApiDefaultResponses() map[string]*openapi.Response {
+	return map[string]*openapi.Response{
+		"200": {Description: "Successful response."},
+	}
+}
+
+func addOpenApiRecordOperations(doc *openapi.Document, collection *models.Collection) {
+	schemaName := collection.Name + "Record"
+	schemaRef := openapi.RefSchema(schemaName)
+	tags := []string{collection.Name + " records"}
+	basePath := "/api/collections/" + collection.Name + "/records"
+
+	doc.Components.Schemas[schemaName] = openApiRecordSchema(collection)
+
+	idParam := &openapi.Parameter{Name: "id", In: "path", Required: true, Schema: &openapi.Schema{Type: "string"}}
+	expandParam := openApiQueryParam(expandQueryParam, "Comma separated list of relations to expand.", "string")
+	fieldsParam := openApiQueryParam(fieldsQueryParam, "Comma separated list of fields to return.", "string")
+	dryRunParam := openApiQueryParam(dryRunQueryParam, "Validates the submitted data without persisting the record.", "boolean")
+
+	listContent := openapi.JsonContent(&openapi.Schema{
+		Type: "object",
+		Properties: map[string]*openapi.Schema{
+			"page":       {Type: "integer"},
+			"perPage":    {Type: "integer"},
+			"totalItems": {Type: "integer"},
+			"totalPages": {Type: "integer"},
+			"items":      {Type: "array", Items: schemaRef},
+		},
+	})
+	// streamed records list (one record per line)
+	listContent[mimeApplicationNdjson] = &openapi.MediaType{Schema: schemaRef}
+	listContent[echo.MIMEApplicationXML] = &openapi.MediaType{Schema: listContent[echo.MIMEApplicationJSON].Schema}
+
+	viewContent := openapi.JsonContent(schemaRef)
+	viewContent[echo.MIMEApplicationXML] = &openapi.MediaType{Schema: schemaRef}
+
+	doc.AddOperation(http.MethodGet, basePath, &openapi.Operation{
+		Summary: "List " + collection.Name + " records.",
+		Description: "Send \"Accept: " + mimeApplicationNdjson + "\" to stream all matching records as newline delimited JSON" +
+			" or \"Accept: " + echo.MIMEApplicationXML + "\" to receive the list as XML.",
+		Tags: tags,
+		Parameters: []*openapi.Parameter{
+			openApiQueryParam(search.PageQueryParam, "The page (aka. offset) of the paginated list.", "integer"),
+			openApiQueryParam(search.PerPageQueryParam, "The max returned records per page.", "integer"),
+			openApiQueryParam(search.SortQueryParam, "Comma separated list of sort fields (eg. \"-created,id\").", "string"),
+			openApiQueryParam(search.FilterQueryParam, "The records filter expression.", "string"),
+			openApiQueryParam(search.SkipTotalQueryParam, "Skips the total counts query.", "boolean"),
+			expandParam,
+			fieldsParam,
+		},
+		Responses: map[string]*openapi.Response{
+			"200": {
+				Description: "Paginated records list.",
+				Content:     listContent,
+			},
+		},
+	})
+
+	doc.AddOperation(http.MethodGet, basePath+"/{id}", &openapi.Operation{
+		Summary:    "View a single " + collection.Name + " record.",
+		Tags:       tags,
+		Parameters: []*openapi.Parameter{idParam, expandParam, fieldsParam},
+		Responses: map[string]*openapi.Response{
+			"200": {Description: "The found record.", Content: viewContent},
+		},
+	})
+
+	if collection.IsView() {
+		return // view records are readonly
+	}
+
+	doc.AddOperation(http.MethodPost, basePath, &openapi.Operation{
+		Summary:     "Create a new " + collection.Name + " record.",
+		Tags:        tags,
+		Parameters:  []*openapi.Parameter{expandParam, fieldsParam, dryRunParam},
+		RequestBody: &openapi.RequestBody{Required: true, Content: openapi.JsonContent(schemaRef)},
+		Responses: map[string]*openapi.Response{
+			"200": {Description: "The created record.", Content: openapi.JsonContent(schemaRef)},
+		},
+	})
+
+	doc.AddOperation(http.MethodPatch, basePath+"/{id}", &openapi.Operation{
+		Summary:     "Update a single " + collection.Name + " record.",
+		Tags:        tags,
+		Parameters:  []*openapi.Parameter{idParam, expandParam, fieldsParam, dryRunParam},
+		RequestBody: &openapi.RequestBody{Required: true, Content: openapi.JsonContent(schemaRef)},
+		Responses: map[string]*openapi.Response{
+			"200": {Description: "The updated record.", Content: openapi.JsonContent(schemaRef)},
+		},
+	})
+
+	doc.AddOperation(http.MethodDelete, basePath+"/{id}", &openapi.Operation{
+		Summary:    "Delete a single " + collection.Name + " record.",
+		Tags:       tags,
+		Parameters: []*openapi.Parameter{idParam},
+		Responses: map[string]*openapi.Response{
+			"204": {Description: "The record was deleted."},
+		},
+	})
+
+	doc.AddOperation(http.MethodDelete, basePath, &openapi.Operation{
+		Summary:     "Delete all " + collection.Name + " records matching the filter.",
+		Description: "The \"filter\" query param is required unless \"all=true\" is set.",
+		Tags:        tags,
+		Parameters: []*openapi.Parameter{
+			openApiQueryParam(search.FilterQueryParam, "The records filter expression.", "string"),
+			openApiQueryParam(allQueryParam, "Allows deleting all records when there is no filter.", "boolean"),
+		},
+		Responses: map[string]*openapi.Response{
+			"200": {
+				Description: "The number of deleted records.",
+				Content: openapi.JsonContent(&openapi.Schema{
+					Type:       "object",
+					Properties: map[string]*openapi.Schema{"deleted": {Type: "integer"}},
+				}),
+			},
+		},
+	})
+}
+
+func openApiQueryParam(name string, description string, paramType string) *openapi.Parameter {
+	return &openapi.Parameter{
+		Name:        name,
+		In:          "query",
+		Description: description,
+		Schema:      &openapi.Schema{Type: paramType},
+	}
+}
+
+// openApiRecordSchema generates the record model schema of the provided collection.
+func openApiRecordSchema(collection *models.Collection) *openapi.Schema {
+	result := &openapi.Schema{
+		Type: "object",
+		Properties: map[string]*openapi.Schema{
+			schema.FieldNameId:             {Type: "string", ReadOnly: true},
+			schema.FieldNameCollectionId:   {Type: "string", ReadOnly: true},
+			schema.FieldNameCollectionName: {Type: "string", ReadOnly: true},
+		},
+	}
+
+	if !collection.IsView() {
+		result.Properties[schema.FieldNameCreated] = &openapi.Schema{Type: "string", ReadOnly: true}
+		result.Properties[schema.FieldNameUpdated] = &openapi.Schema{Type: "string", ReadOnly: true}
+	}
+
+	if collection.IsAuth() {
+		result.Properties[schema.FieldNameUsername] = &openapi.Schema{Type: "string"}
+		result.Properties[schema.FieldNameEmail] = &openapi.Schema{Type: "string", Format: "email"}
+		result.Properties[schema.FieldNameEmailVisibility] = &openapi.Schema{Type: "boolean"}
+		result.Properties[schema.FieldNameVerified] = &openapi.Schema{Type: "boolean"}
+	}
+
+	if collection.HasSoftDelete() {
+		result.Properties[schema.FieldNameDeleted] = &openapi.Schema{Type: "string", ReadOnly: true}
+	}
+
+	for _, field := range collection.Schema.Fields() {
+		result.Properties[field.Name] = openApiFieldSchema(field)
+
+		if field.Required {
+			result.Required = append(result.Required, field.Name)
+		}
+	}
+
+	return result
+}
+
+// openApiFieldSchema returns the value schema of a single collection schema field.
+func openApiFieldSchema(field *schema.SchemaField) *openapi.Schema {
+	field.InitOptions()
+
+	var result *openapi.Schema
+
+	switch field.Type {
+	case schema.FieldTypeText, schema.FieldTypeEditor, schema.FieldTypeDate:
+		result = &openapi.Schema{Type: "string"}
+	case schema.FieldTypeEmail:
+		result = &openapi.Schema{Type: "string", Format: "email"}
+	case schema.FieldTypeUrl:
+		result = &openapi.Schema{Type: "string", Format: "uri"}
+	case schema.FieldTypeNumber:
+		result = &openapi.Schema{Type: "number"}
+	case schema.FieldTypeBool:
+		result = &openapi.Schema{Type: "boolean"}
+	case schema.FieldTypeGeoPoint:
+		result = &openapi.Schema{
+			Type: "object",
+			Properties: map[string]*openapi.Schema{
+				"lon": {Type: "number"},
+				"lat": {Type: "number"},
+			},
+		}
+	case schema.FieldTypeSelect:
+		result = &openapi.Schema{Type: "string"}
+		if options, ok := field.Options.(*schema.SelectOptions); ok {
+			for _, v := range options.Values {
+				result.Enum = append(result.Enum, v)
+			}
+		}
+	case schema.FieldTypeFile, schema.FieldTypeRelation:
+		result = &openapi.Schema{Type: "string"}
+	case schema.FieldTypeComputed:
+		// number or string depending on the expression
+		return &openapi.Schema{ReadOnly: true}
+	default:
+		// json and any other custom field value
+		return &openapi.Schema{}
+	}
+
+	if options, ok := field.Options.(schema.MultiValuer); ok && options.IsMultiple() {
+		return &openapi.Schema{Type: "array", Items: result}
+	}
+
+	return result
+}
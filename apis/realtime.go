@@ -4,22 +4,51 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
-	"log"
+	"fmt"
 	"net/http"
+	"net/url"
 	"strings"
 	"time"
 
 	"github.com/labstack/echo/v5"
 	"github.com/pocketbase/dbx"
+	"github.com/spf13/cast"
 	"github.com/unkod/space/core"
 	"github.com/unkod/space/forms"
 	"github.com/unkod/space/models"
 	"github.com/unkod/space/resolvers"
 	"github.com/unkod/space/tools/routine"
 	"github.com/unkod/space/tools/search"
+	"github.com/unkod/space/tools/security"
 	"github.com/unkod/space/tools/subscriptions"
 )
 
+// RealtimeDisconnectMessage is the name of the final realtime message
+// sent to the clients before their connection is closed by the server
+// (see [DisconnectRealtimeClients]).
+const RealtimeDisconnectMessage = "PB_DISCONNECT"
+
+// DisconnectRealtimeClients sends a final [RealtimeDisconnectMessage] message
+// with the specified reason to all registered realtime clients and closes
+// their connections right after the message delivery.
+//
+// The clients are discarded and no longer receive any other messages.
+func DisconnectRealtimeClients(app core.App, reason string) {
+	data, _ := json.Marshal(map[string]string{"reason": reason})
+
+	for _, client := range app.SubscriptionsBroker().Clients() {
+		client := client
+
+		routine.FireAndForget(func() {
+			client.Send(subscriptions.Message{
+				Name: RealtimeDisconnectMessage,
+				Data: data,
+			})
+			client.Discard()
+		})
+	}
+}
+
 // bindRealtimeApi registers the realtime api endpoints.
 func bindRealtimeApi(app core.App, rg *echo.Group) {
 	api := realtimeApi{app: app}
@@ -27,6 +56,7 @@ func bindRealtimeApi(app core.App, rg *echo.Group) {
 	subGroup := rg.Group("/realtime", ActivityLogger(app))
 	subGroup.GET("", api.connect)
 	subGroup.POST("", api.setSubscriptions)
+	subGroup.GET("/ws", api.connectWebSocket, RequestTimeout(0))
 
 	api.bindEvents()
 }
@@ -49,8 +79,8 @@ func (api *realtimeApi) connect(c echo.Context) error {
 			Client:      client,
 		}
 
-		if err := api.app.OnRealtimeDisconnectRequest().Trigger(disconnectEvent); err != nil && api.app.IsDebug() {
-			log.Println(err)
+		if err := api.app.OnRealtimeDisconnectRequest().Trigger(disconnectEvent); err != nil {
+			api.app.Logger().Debug("OnRealtimeDisconnectRequest failure", "clientId", client.Id(), "error", err)
 		}
 
 		api.app.SubscriptionsBroker().Unregister(client.Id())
@@ -73,9 +103,7 @@ func (api *realtimeApi) connect(c echo.Context) error {
 		return err
 	}
 
-	if api.app.IsDebug() {
-		log.Printf("Realtime connection established: %s\n", client.Id())
-	}
+	api.app.Logger().Debug("Realtime connection established", "clientId", client.Id())
 
 	// signalize established connection (aka. fire "connect" message)
 	connectMsgEvent := &core.RealtimeMessageEvent{
@@ -87,19 +115,17 @@ func (api *realtimeApi) connect(c echo.Context) error {
 		},
 	}
 	connectMsgErr := api.app.OnRealtimeBeforeMessageSend().Trigger(connectMsgEvent, func(e *core.RealtimeMessageEvent) error {
-		w := e.HttpContext.Response()
-		w.Write([]byte("id:" + client.Id() + "\n"))
-		w.Write([]byte("event:" + e.Message.Name + "\n"))
-		w.Write([]byte("data:"))
-		w.Write(e.Message.Data)
-		w.Write([]byte("\n\n"))
-		w.Flush()
+		if err := writeRealtimeSSE(e.HttpContext.Response(), "id:"+client.Id()+"\nevent:"+e.Message.Name+"\ndata:"+string(e.Message.Data)+"\n\n"); err != nil {
+			return err
+		}
 		return api.app.OnRealtimeAfterMessageSend().Trigger(e)
 	})
 	if connectMsgErr != nil {
-		if api.app.IsDebug() {
-			log.Println("Realtime connection closed (failed to deliver PB_CONNECT):", client.Id(), connectMsgErr)
-		}
+		api.app.Logger().Debug(
+			"Realtime connection closed (failed to deliver PB_CONNECT)",
+			"clientId", client.Id(),
+			"error", connectMsgErr,
+		)
 		return nil
 	}
 
@@ -108,16 +134,32 @@ func (api *realtimeApi) connect(c echo.Context) error {
 	idleTimer := time.NewTimer(idleTimeout)
 	defer idleTimer.Stop()
 
+	// send periodically keep-alive pings to prevent the proxies from
+	// dropping the idle connections and to detect earlier the dead ones
+	var keepAlive <-chan time.Time
+	if interval := time.Duration(api.app.Settings().Timeouts.RealtimeKeepAlive) * time.Second; interval > 0 {
+		keepAliveTicker := time.NewTicker(interval)
+		defer keepAliveTicker.Stop()
+		keepAlive = keepAliveTicker.C
+	}
+
 	for {
 		select {
 		case <-idleTimer.C:
 			cancelRequest()
+		case <-keepAlive:
+			if err := writeRealtimeSSE(c.Response(), ":ping\n\n"); err != nil {
+				api.app.Logger().Debug(
+					"Realtime connection closed (failed to deliver keep-alive ping)",
+					"clientId", client.Id(),
+					"error", err,
+				)
+				return nil
+			}
 		case msg, ok := <-client.Channel():
 			if !ok {
 				// channel is closed
-				if api.app.IsDebug() {
-					log.Println("Realtime connection closed (closed channel):", client.Id())
-				}
+				api.app.Logger().Debug("Realtime connection closed (closed channel)", "clientId", client.Id())
 				return nil
 			}
 
@@ -127,19 +169,22 @@ func (api *realtimeApi) connect(c echo.Context) error {
 				Message:     &msg,
 			}
 			msgErr := api.app.OnRealtimeBeforeMessageSend().Trigger(msgEvent, func(e *core.RealtimeMessageEvent) error {
-				w := e.HttpContext.Response()
-				w.Write([]byte("id:" + e.Client.Id() + "\n"))
-				w.Write([]byte("event:" + e.Message.Name + "\n"))
-				w.Write([]byte("data:"))
-				w.Write(e.Message.Data)
-				w.Write([]byte("\n\n"))
-				w.Flush()
+				if err := writeRealtimeSSE(e.HttpContext.Response(), "id:"+e.Client.Id()+"\nevent:"+e.Message.Name+"\ndata:"+string(e.Message.Data)+"\n\n"); err != nil {
+					return err
+				}
 				return api.app.OnRealtimeAfterMessageSend().Trigger(msgEvent)
 			})
 			if msgErr != nil {
-				if api.app.IsDebug() {
-					log.Println("Realtime connection closed (failed to deliver message):", client.Id(), msgErr)
-				}
+				api.app.Logger().Debug(
+					"Realtime connection closed (failed to deliver message)",
+					"clientId", client.Id(),
+					"error", msgErr,
+				)
+				return nil
+			}
+
+			if msg.Name == RealtimeDisconnectMessage {
+				api.app.Logger().Debug("Realtime connection closed (disconnected by the server)", "clientId", client.Id())
 				return nil
 			}
 
@@ -147,14 +192,24 @@ func (api *realtimeApi) connect(c echo.Context) error {
 			idleTimer.Reset(idleTimeout)
 		case <-c.Request().Context().Done():
 			// connection is closed
-			if api.app.IsDebug() {
-				log.Println("Realtime connection closed (cancelled request):", client.Id())
-			}
+			api.app.Logger().Debug("Realtime connection closed (cancelled request)", "clientId", client.Id())
 			return nil
 		}
 	}
 }
 
+// writeRealtimeSSE writes and flushes the raw SSE chunk to the response writer.
+//
+// It returns an error if the client connection is no longer writable
+// (eg. in case of a dead connection).
+func writeRealtimeSSE(w *echo.Response, chunk string) error {
+	if _, err := w.Write([]byte(chunk)); err != nil {
+		return err
+	}
+
+	return http.NewResponseController(w).Flush()
+}
+
 // note: in case of reconnect, clients will have to resubmit all subscriptions again
 func (api *realtimeApi) setSubscriptions(c echo.Context) error {
 	form := forms.NewRealtimeSubscribe()
@@ -189,15 +244,9 @@ func (api *realtimeApi) setSubscriptions(c echo.Context) error {
 	}
 
 	return api.app.OnRealtimeBeforeSubscribeRequest().Trigger(event, func(e *core.RealtimeSubscribeEvent) error {
-		// update auth state
-		e.Client.Set(ContextAdminKey, e.HttpContext.Get(ContextAdminKey))
-		e.Client.Set(ContextAuthRecordKey, e.HttpContext.Get(ContextAuthRecordKey))
-
-		// unsubscribe from any previous existing subscriptions
-		e.Client.Unsubscribe()
-
-		// subscribe to the new subscriptions
-		e.Client.Subscribe(e.Subscriptions...)
+		if err := api.applySubscriptions(e); err != nil {
+			return err
+		}
 
 		return api.app.OnRealtimeAfterSubscribeRequest().Trigger(event, func(e *core.RealtimeSubscribeEvent) error {
 			if e.HttpContext.Response().Committed {
@@ -209,6 +258,177 @@ func (api *realtimeApi) setSubscriptions(c echo.Context) error {
 	})
 }
 
+// applySubscriptions replaces the event client subscriptions with
+// the event ones and updates the client auth state with the one of the request.
+func (api *realtimeApi) applySubscriptions(e *core.RealtimeSubscribeEvent) error {
+	// parse and validate the subscriptions filters
+	parsedSubs, err := api.parseSubscriptions(e.HttpContext, e.Subscriptions)
+	if err != nil {
+		return err
+	}
+
+	// update auth state
+	e.Client.Set(ContextAdminKey, e.HttpContext.Get(ContextAdminKey))
+	e.Client.Set(ContextAuthRecordKey, e.HttpContext.Get(ContextAuthRecordKey))
+	e.Client.Set(realtimeAuthExpiresAtKey, authTokenExpiresAt(e.HttpContext))
+
+	// unsubscribe from any previous existing subscriptions
+	e.Client.Unsubscribe()
+
+	// subscribe to the new subscriptions
+	e.Client.Set(realtimeSubscriptionsKey, parsedSubs)
+	e.Client.Subscribe(e.Subscriptions...)
+
+	return nil
+}
+
+// realtimeSubscriptionsKey is the client context key of the parsed subscriptions.
+const realtimeSubscriptionsKey = "@subscriptions"
+
+// realtimeAuthExpiresAtKey is the client context key of the
+// subscriptions request auth token expiration time.
+const realtimeAuthExpiresAtKey = "@authExpiresAt"
+
+// realtimeSubscription defines a single parsed realtime subscription.
+//
+// The subscription could have an optional url encoded filter expression
+// with the same syntax as the records list filter, eg.:
+//
+//	demo/*?filter=owner%3D%22RECORD_ID%22
+type realtimeSubscription struct {
+	topic  string
+	filter string
+}
+
+// parseRealtimeSubscription parses a raw subscription string
+// in the format "topic" or "topic?filter=...".
+func parseRealtimeSubscription(sub string) (*realtimeSubscription, error) {
+	topic, rawOptions, hasOptions := strings.Cut(sub, "?")
+
+	result := &realtimeSubscription{topic: topic}
+
+	if hasOptions {
+		options, err := url.ParseQuery(rawOptions)
+		if err != nil {
+			return nil, err
+		}
+
+		result.filter = options.Get(search.FilterQueryParam)
+	}
+
+	return result, nil
+}
+
+// parseSubscriptions parses the provided subscriptions and validates
+// their filter expressions against the topic collection so that
+// invalid filters are reported on subscribe and not silently ignored
+// on each broadcast.
+func (api *realtimeApi) parseSubscriptions(c echo.Context, subs []string) (map[string]*realtimeSubscription, error) {
+	result := make(map[string]*realtimeSubscription, len(subs))
+
+	requestInfo := RequestInfo(c)
+
+	for _, sub := range subs {
+		if sub == "" {
+			continue
+		}
+
+		parsed, err := parseRealtimeSubscription(sub)
+		if err != nil {
+			return nil, NewBadRequestError(fmt.Sprintf("Invalid subscription %q options.", sub), err)
+		}
+
+		if parsed.filter != "" {
+			// forbid users and guests to use special filter fields (similar to the records list)
+			if requestInfo.Admin == nil &&
+				(strings.Contains(parsed.filter, "@collection.") || strings.Contains(parsed.filter, "@request.")) {
+				return nil, NewForbiddenError("Only admins can filter by @collection and @request query params", nil)
+			}
+
+			collectionNameOrId, _, _ := strings.Cut(parsed.topic, "/")
+
+			collection, err := api.app.Dao().FindCollectionByNameOrId(collectionNameOrId)
+			if err != nil || collection.IsApiDisabled() {
+				return nil, NewBadRequestError(fmt.Sprintf("Missing subscription %q collection.", sub), err)
+			}
+
+			resolver := resolvers.NewRecordFieldResolver(api.app.Dao(), collection, requestInfo, requestInfo.Admin != nil)
+			if _, err := search.FilterData(parsed.filter).BuildExpr(resolver); err != nil {
+				return nil, NewBadRequestError(fmt.Sprintf("Invalid subscription %q filter.", sub), err)
+			}
+		}
+
+		result[sub] = parsed
+	}
+
+	return result, nil
+}
+
+// clientSubscriptions returns the parsed subscriptions of the provided client.
+//
+// Subscriptions that were registered without the subscribe endpoint
+// (eg. directly from a hook) are parsed on the fly.
+func (api *realtimeApi) clientSubscriptions(client subscriptions.Client) map[string]*realtimeSubscription {
+	cached, _ := client.Get(realtimeSubscriptionsKey).(map[string]*realtimeSubscription)
+
+	subs := client.Subscriptions()
+
+	result := make(map[string]*realtimeSubscription, len(subs))
+
+	for sub := range subs {
+		if parsed, ok := cached[sub]; ok {
+			result[sub] = parsed
+			continue
+		}
+
+		if parsed, err := parseRealtimeSubscription(sub); err == nil {
+			result[sub] = parsed
+		}
+	}
+
+	return result
+}
+
+// authTokenExpiresAt returns the expiration time of the request
+// auth token (or zero time if the request is not authorized).
+func authTokenExpiresAt(c echo.Context) time.Time {
+	if c.Get(ContextAdminKey) == nil && c.Get(ContextAuthRecordKey) == nil {
+		return time.Time{}
+	}
+
+	token := strings.TrimPrefix(c.Request().Header.Get("Authorization"), "Bearer ")
+
+	claims, _ := security.ParseUnverifiedJWT(token)
+
+	exp := cast.ToInt64(claims["exp"])
+	if exp <= 0 {
+		return time.Time{}
+	}
+
+	return time.Unix(exp, 0)
+}
+
+// dropClientIfAuthExpired removes the client subscriptions and auth state
+// if the auth token used for the subscriptions request has expired.
+//
+// Returns true if the client subscriptions were dropped.
+func (api *realtimeApi) dropClientIfAuthExpired(client subscriptions.Client) bool {
+	expiresAt, _ := client.Get(realtimeAuthExpiresAtKey).(time.Time)
+	if expiresAt.IsZero() || time.Now().Before(expiresAt) {
+		return false
+	}
+
+	client.Unsubscribe()
+	client.Unset(realtimeSubscriptionsKey)
+	client.Unset(realtimeAuthExpiresAtKey)
+	client.Unset(ContextAdminKey)
+	client.Unset(ContextAuthRecordKey)
+
+	api.app.Logger().Debug("Realtime subscriptions dropped (expired auth)", "clientId", client.Id())
+
+	return true
+}
+
 // updateClientsAuthModel updates the existing clients auth model with the new one (matched by ID).
 func (api *realtimeApi) updateClientsAuthModel(contextKey string, newModel models.Model) error {
 	for _, client := range api.app.SubscriptionsBroker().Clients() {
@@ -266,8 +486,8 @@ func (api *realtimeApi) bindEvents() {
 
 	api.app.OnModelAfterCreate().PreAdd(func(e *core.ModelEvent) error {
 		if record := api.resolveRecord(e.Model); record != nil {
-			if err := api.broadcastRecord("create", record, false); err != nil && api.app.IsDebug() {
-				log.Println(err)
+			if err := api.broadcastRecord("create", record, false); err != nil {
+				api.app.Logger().Debug("Realtime broadcast failed", "action", "create", "error", err)
 			}
 		}
 		return nil
@@ -275,8 +495,8 @@ func (api *realtimeApi) bindEvents() {
 
 	api.app.OnModelAfterUpdate().PreAdd(func(e *core.ModelEvent) error {
 		if record := api.resolveRecord(e.Model); record != nil {
-			if err := api.broadcastRecord("update", record, false); err != nil && api.app.IsDebug() {
-				log.Println(err)
+			if err := api.broadcastRecord("update", record, false); err != nil {
+				api.app.Logger().Debug("Realtime broadcast failed", "action", "update", "error", err)
 			}
 		}
 		return nil
@@ -284,8 +504,8 @@ func (api *realtimeApi) bindEvents() {
 
 	api.app.OnModelBeforeDelete().Add(func(e *core.ModelEvent) error {
 		if record := api.resolveRecord(e.Model); record != nil {
-			if err := api.broadcastRecord("delete", record, true); err != nil && api.app.IsDebug() {
-				log.Println(err)
+			if err := api.broadcastRecord("delete", record, true); err != nil {
+				api.app.Logger().Debug("Realtime broadcast failed", "action", "delete", "error", err)
 			}
 		}
 		return nil
@@ -293,8 +513,8 @@ func (api *realtimeApi) bindEvents() {
 
 	api.app.OnModelAfterDelete().Add(func(e *core.ModelEvent) error {
 		if record := api.resolveRecord(e.Model); record != nil {
-			if err := api.broadcastDryCachedRecord("delete", record); err != nil && api.app.IsDebug() {
-				log.Println(err)
+			if err := api.broadcastDryCachedRecord("delete", record); err != nil {
+				api.app.Logger().Debug("Realtime broadcast failed", "action", "delete", "error", err)
 			}
 		}
 		return nil
@@ -328,36 +548,48 @@ func (api *realtimeApi) resolveRecordCollection(model models.Model) (collection
 }
 
 // canAccessRecord checks if the subscription client has access to the specified record model.
-func (api *realtimeApi) canAccessRecord(client subscriptions.Client, record *models.Record, accessRule *string) bool {
+//
+// The optional filter is applied in addition to the access rule (admins included).
+func (api *realtimeApi) canAccessRecord(client subscriptions.Client, record *models.Record, accessRule *string, filter string) bool {
 	admin, _ := client.Get(ContextAdminKey).(*models.Admin)
-	if admin != nil {
+	if admin != nil && filter == "" {
 		// admins can access everything
 		return true
 	}
 
-	if accessRule == nil {
+	if admin == nil && accessRule == nil {
 		// only admins can access this record
 		return false
 	}
 
 	ruleFunc := func(q *dbx.SelectQuery) error {
-		if *accessRule == "" {
-			return nil // empty public rule
-		}
-
 		// mock request data
 		requestInfo := &models.RequestInfo{
 			Method: "GET",
+			Admin:  admin,
 		}
 		requestInfo.AuthRecord, _ = client.Get(ContextAuthRecordKey).(*models.Record)
 
-		resolver := resolvers.NewRecordFieldResolver(api.app.Dao(), record.Collection(), requestInfo, true)
-		expr, err := search.FilterData(*accessRule).BuildExpr(resolver)
-		if err != nil {
-			return err
+		if admin == nil && *accessRule != "" {
+			resolver := resolvers.NewRecordFieldResolver(api.app.Dao(), record.Collection(), requestInfo, true)
+			expr, err := search.FilterData(*accessRule).BuildExpr(resolver)
+			if err != nil {
+				return err
+			}
+			resolver.UpdateQuery(q)
+			q.AndWhere(expr)
+		}
+
+		if filter != "" {
+			// hidden fields are searchable only by admins
+			resolver := resolvers.NewRecordFieldResolver(api.app.Dao(), record.Collection(), requestInfo, admin != nil)
+			expr, err := search.FilterData(filter).BuildExpr(resolver)
+			if err != nil {
+				return err
+			}
+			resolver.UpdateQuery(q)
+			q.AndWhere(expr)
 		}
-		resolver.UpdateQuery(q)
-		q.AndWhere(expr)
 
 		return nil
 	}
@@ -370,6 +602,25 @@ func (api *realtimeApi) canAccessRecord(client subscriptions.Client, record *mod
 	return false
 }
 
+// readRestrictedFields returns the names of the record fields
+// whose read rule is not satisfied by the client.
+func (api *realtimeApi) readRestrictedFields(client subscriptions.Client, record *models.Record) []string {
+	var result []string
+
+	for _, field := range record.Collection().Schema.Fields() {
+		if field.ReadRule == "" {
+			continue
+		}
+
+		rule := field.ReadRule
+		if !api.canAccessRecord(client, record, &rule, "") {
+			result = append(result, field.Name)
+		}
+	}
+
+	return result
+}
+
 type recordData struct {
 	Action string         `json:"action"`
 	Record *models.Record `json:"record"`
@@ -381,6 +632,10 @@ func (api *realtimeApi) broadcastRecord(action string, record *models.Record, dr
 		return errors.New("record collection not set")
 	}
 
+	if collection.IsApiDisabled() {
+		return nil // the collection records are not public
+	}
+
 	clients := api.app.SubscriptionsBroker().Clients()
 	if len(clients) == 0 {
 		return nil // no subscribers
@@ -413,12 +668,19 @@ func (api *realtimeApi) broadcastRecord(action string, record *models.Record, dr
 	for _, client := range clients {
 		client := client
 
-		for subscription, rule := range subscriptionRuleMap {
-			if !client.HasSubscription(subscription) {
+		if api.dropClientIfAuthExpired(client) {
+			continue
+		}
+
+		for subscription, parsed := range api.clientSubscriptions(client) {
+			rule, ok := subscriptionRuleMap[parsed.topic]
+			if !ok {
 				continue
 			}
 
-			if !api.canAccessRecord(client, data.Record, rule) {
+			// the access rule is reevaluated on each broadcast
+			// so that access changes take effect immediately
+			if !api.canAccessRecord(client, data.Record, rule, parsed.filter) {
 				continue
 			}
 
@@ -427,17 +689,33 @@ func (api *realtimeApi) broadcastRecord(action string, record *models.Record, dr
 				Data: dataBytes,
 			}
 
+			clientData := data
+
+			// hide the fields whose read rule is not satisfied by the client
+			if hidden := api.readRestrictedFields(client, data.Record); len(hidden) > 0 {
+				clientData = &recordData{
+					Action: action,
+					Record: data.Record.CleanCopy(),
+				}
+				clientData.Record.HideFields(hidden...)
+				if newData, err := json.Marshal(clientData); err == nil {
+					msg.Data = newData
+				} else {
+					continue // don't fallback to the unrestricted data
+				}
+			}
+
 			// ignore the auth record email visibility checks for
 			// auth owner, admin or manager
 			if collection.IsAuth() {
 				authId := extractAuthIdFromGetter(client)
-				if authId == data.Record.Id ||
-					api.canAccessRecord(client, data.Record, collection.AuthOptions().ManageRule) {
-					data.Record.IgnoreEmailVisibility(true) // ignore
-					if newData, err := json.Marshal(data); err == nil {
+				if authId == clientData.Record.Id ||
+					api.canAccessRecord(client, clientData.Record, collection.AuthOptions().ManageRule, "") {
+					clientData.Record.IgnoreEmailVisibility(true) // ignore
+					if newData, err := json.Marshal(clientData); err == nil {
 						msg.Data = newData
 					}
-					data.Record.IgnoreEmailVisibility(false) // restore
+					clientData.Record.IgnoreEmailVisibility(false) // restore
 				}
 			}
 
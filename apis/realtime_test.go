@@ -1,10 +1,13 @@
 package apis_test
 
 import (
+	"bufio"
 	"errors"
 	"net/http"
+	"net/http/httptest"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/labstack/echo/v5"
 	"github.com/pocketbase/dbx"
@@ -14,6 +17,7 @@ import (
 	"github.com/unkod/space/models"
 	"github.com/unkod/space/tests"
 	"github.com/unkod/space/tools/hook"
+	"github.com/unkod/space/tools/list"
 	"github.com/unkod/space/tools/subscriptions"
 )
 
@@ -64,6 +68,36 @@ func TestRealtimeConnect(t *testing.T) {
 				}
 			},
 		},
+		{
+			Name:           "PB_DISCONNECT by the server",
+			Method:         http.MethodGet,
+			Url:            "/api/realtime",
+			ExpectedStatus: 200,
+			ExpectedContent: []string{
+				`event:PB_CONNECT`,
+				`event:PB_DISCONNECT`,
+				`data:{"reason":"shutdown"}`,
+			},
+			ExpectedEvents: map[string]int{
+				"OnRealtimeConnectRequest":    1,
+				"OnRealtimeBeforeMessageSend": 2,
+				"OnRealtimeAfterMessageSend":  2,
+				"OnRealtimeDisconnectRequest": 1,
+			},
+			BeforeTestFunc: func(t *testing.T, app *tests.TestApp, e *echo.Echo) {
+				app.OnRealtimeAfterMessageSend().Add(func(e *core.RealtimeMessageEvent) error {
+					if e.Message.Name == "PB_CONNECT" {
+						apis.DisconnectRealtimeClients(app, "shutdown")
+					}
+					return nil
+				})
+			},
+			AfterTestFunc: func(t *testing.T, app *tests.TestApp, e *echo.Echo) {
+				if len(app.SubscriptionsBroker().Clients()) != 0 {
+					t.Errorf("Expected the subscribers to be removed after connection close, found %d", len(app.SubscriptionsBroker().Clients()))
+				}
+			},
+		},
 		{
 			Name:           "Skipping/ignoring messages",
 			Method:         http.MethodGet,
@@ -92,6 +126,63 @@ func TestRealtimeConnect(t *testing.T) {
 	}
 }
 
+func TestRealtimeConnectKeepAlive(t *testing.T) {
+	testApp, _ := tests.NewTestApp()
+	defer testApp.Cleanup()
+
+	testApp.Settings().Timeouts.RealtimeKeepAlive = 1
+
+	e, err := apis.InitApi(testApp)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	server := httptest.NewServer(e)
+	defer server.Close()
+
+	res, err := http.Get(server.URL + "/api/realtime")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	lines := make(chan string)
+	go func() {
+		defer close(lines)
+		scanner := bufio.NewScanner(res.Body)
+		for scanner.Scan() {
+			lines <- scanner.Text()
+		}
+	}()
+
+	// wait for 2 consecutive pings of the otherwise idle connection
+	pings := 0
+	timeout := time.After(5 * time.Second)
+	for pings < 2 {
+		select {
+		case line, ok := <-lines:
+			if !ok {
+				t.Fatalf("The connection was closed before receiving the keep-alive pings")
+			}
+			if line == ":ping" {
+				pings++
+			}
+		case <-timeout:
+			t.Fatalf("Expected 2 keep-alive pings, got %d", pings)
+		}
+	}
+
+	// close the client connection
+	res.Body.Close()
+
+	for i := 0; i < 150 && len(testApp.SubscriptionsBroker().Clients()) > 0; i++ {
+		time.Sleep(20 * time.Millisecond)
+	}
+
+	if total := len(testApp.SubscriptionsBroker().Clients()); total != 0 {
+		t.Fatalf("Expected the dead connection client to be unregistered, found %d", total)
+	}
+}
+
 func TestRealtimeSubscribe(t *testing.T) {
 	client := subscriptions.NewDefaultClient()
 
@@ -232,6 +323,81 @@ func TestRealtimeSubscribe(t *testing.T) {
 				resetClient()
 			},
 		},
+		{
+			Name:            "existing client - filter with missing collection",
+			Method:          http.MethodPost,
+			Url:             "/api/realtime",
+			Body:            strings.NewReader(`{"clientId":"` + client.Id() + `","subscriptions":["missing/*?filter=title%3D%27test1%27"]}`),
+			ExpectedStatus:  400,
+			ExpectedContent: []string{`"data":{}`},
+			ExpectedEvents: map[string]int{
+				"OnRealtimeBeforeSubscribeRequest": 1,
+			},
+			BeforeTestFunc: func(t *testing.T, app *tests.TestApp, e *echo.Echo) {
+				app.SubscriptionsBroker().Register(client)
+			},
+			AfterTestFunc: func(t *testing.T, app *tests.TestApp, e *echo.Echo) {
+				resetClient()
+			},
+		},
+		{
+			Name:            "existing client - invalid filter",
+			Method:          http.MethodPost,
+			Url:             "/api/realtime",
+			Body:            strings.NewReader(`{"clientId":"` + client.Id() + `","subscriptions":["demo2/*?filter=missing%3D%27test1%27"]}`),
+			ExpectedStatus:  400,
+			ExpectedContent: []string{`"data":{}`},
+			ExpectedEvents: map[string]int{
+				"OnRealtimeBeforeSubscribeRequest": 1,
+			},
+			BeforeTestFunc: func(t *testing.T, app *tests.TestApp, e *echo.Echo) {
+				client.Subscribe("test0")
+				app.SubscriptionsBroker().Register(client)
+			},
+			AfterTestFunc: func(t *testing.T, app *tests.TestApp, e *echo.Echo) {
+				if !client.HasSubscription("test0") {
+					t.Errorf("Expected the previous subscriptions to be preserved, got %v", client.Subscriptions())
+				}
+				resetClient()
+			},
+		},
+		{
+			Name:            "existing client - guest with admin only filter",
+			Method:          http.MethodPost,
+			Url:             "/api/realtime",
+			Body:            strings.NewReader(`{"clientId":"` + client.Id() + `","subscriptions":["demo2/*?filter=%40request.auth.id%3D%27%27"]}`),
+			ExpectedStatus:  403,
+			ExpectedContent: []string{`"data":{}`},
+			ExpectedEvents: map[string]int{
+				"OnRealtimeBeforeSubscribeRequest": 1,
+			},
+			BeforeTestFunc: func(t *testing.T, app *tests.TestApp, e *echo.Echo) {
+				app.SubscriptionsBroker().Register(client)
+			},
+			AfterTestFunc: func(t *testing.T, app *tests.TestApp, e *echo.Echo) {
+				resetClient()
+			},
+		},
+		{
+			Name:           "existing client - valid filter",
+			Method:         http.MethodPost,
+			Url:            "/api/realtime",
+			Body:           strings.NewReader(`{"clientId":"` + client.Id() + `","subscriptions":["demo2/*?filter=title%3D%27test1%27"]}`),
+			ExpectedStatus: 204,
+			ExpectedEvents: map[string]int{
+				"OnRealtimeBeforeSubscribeRequest": 1,
+				"OnRealtimeAfterSubscribeRequest":  1,
+			},
+			BeforeTestFunc: func(t *testing.T, app *tests.TestApp, e *echo.Echo) {
+				app.SubscriptionsBroker().Register(client)
+			},
+			AfterTestFunc: func(t *testing.T, app *tests.TestApp, e *echo.Echo) {
+				if !client.HasSubscription("demo2/*?filter=title%3D%27test1%27") {
+					t.Errorf("Expected the filtered subscription, got %v", client.Subscriptions())
+				}
+				resetClient()
+			},
+		},
 	}
 
 	for _, scenario := range scenarios {
@@ -447,3 +613,122 @@ func TestRealtimeCustomAuthModelUpdateEvent(t *testing.T) {
 		t.Fatalf("Expected authRecord with email %q, got %q", customUser.Email, clientAuthRecord.Email())
 	}
 }
+
+func TestRealtimeBroadcastWithSubscriptionFilter(t *testing.T) {
+	testApp, _ := tests.NewTestApp()
+	defer testApp.Cleanup()
+
+	apis.InitApi(testApp)
+
+	client := subscriptions.NewDefaultClient()
+	client.Subscribe(
+		"demo2/*",
+		"demo2/*?filter=title%3D%27test1%27",
+		"demo2/*?filter=title%3D%27test2%27",
+	)
+	testApp.SubscriptionsBroker().Register(client)
+
+	record, err := testApp.Dao().FindRecordById("demo2", "achvryl401bhse3")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	e := new(core.ModelEvent)
+	e.Dao = testApp.Dao()
+	e.Model = record
+	testApp.OnModelAfterUpdate().Trigger(e)
+
+	names := readRealtimeMessageNames(client)
+
+	expected := []string{"demo2/*", "demo2/*?filter=title%3D%27test2%27"}
+	if len(names) != len(expected) {
+		t.Fatalf("Expected messages %v, got %v", expected, names)
+	}
+	for _, name := range expected {
+		if !list.ExistInSlice(name, names) {
+			t.Fatalf("Missing message %q in %v", name, names)
+		}
+	}
+}
+
+func TestRealtimeBroadcastWithApiDisabledCollection(t *testing.T) {
+	testApp, _ := tests.NewTestApp()
+	defer testApp.Cleanup()
+
+	apis.InitApi(testApp)
+
+	client := subscriptions.NewDefaultClient()
+	client.Set(apis.ContextAdminKey, &models.Admin{})
+	client.Subscribe("demo2/*", "demo2/achvryl401bhse3")
+	testApp.SubscriptionsBroker().Register(client)
+
+	record, err := testApp.Dao().FindRecordById("demo2", "achvryl401bhse3")
+	if err != nil {
+		t.Fatal(err)
+	}
+	record.Collection().Options["apiDisabled"] = true
+
+	e := new(core.ModelEvent)
+	e.Dao = testApp.Dao()
+	e.Model = record
+	testApp.OnModelAfterUpdate().Trigger(e)
+
+	if names := readRealtimeMessageNames(client); len(names) != 0 {
+		t.Fatalf("Expected no messages for the collection with disabled api, got %v", names)
+	}
+}
+
+func TestRealtimeBroadcastWithExpiredAuth(t *testing.T) {
+	testApp, _ := tests.NewTestApp()
+	defer testApp.Cleanup()
+
+	apis.InitApi(testApp)
+
+	authRecord, err := testApp.Dao().FindAuthRecordByEmail("users", "test@example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	client := subscriptions.NewDefaultClient()
+	client.Set(apis.ContextAuthRecordKey, authRecord)
+	client.Set("@authExpiresAt", time.Now().Add(-1*time.Minute)) // the subscriptions auth token expiration time
+	client.Subscribe("demo2/*")
+	testApp.SubscriptionsBroker().Register(client)
+
+	record, err := testApp.Dao().FindRecordById("demo2", "achvryl401bhse3")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	e := new(core.ModelEvent)
+	e.Dao = testApp.Dao()
+	e.Model = record
+	testApp.OnModelAfterUpdate().Trigger(e)
+
+	if names := readRealtimeMessageNames(client); len(names) != 0 {
+		t.Fatalf("Expected no messages, got %v", names)
+	}
+
+	if len(client.Subscriptions()) != 0 {
+		t.Fatalf("Expected the client subscriptions to be dropped, got %v", client.Subscriptions())
+	}
+
+	if client.Get(apis.ContextAuthRecordKey) != nil {
+		t.Fatal("Expected the client auth state to be removed")
+	}
+}
+
+// readRealtimeMessageNames collects the names of the messages
+// sent to the client until no new message is received for a while.
+func readRealtimeMessageNames(client subscriptions.Client) []string {
+	names := []string{}
+
+	for {
+		select {
+		case msg := <-client.Channel():
+			names = append(names, msg.Name)
+		case <-time.After(100 * time.Millisecond):
+			return names
+		}
+	}
+}
@@ -0,0 +1,353 @@
+package apis
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/labstack/echo/v5"
+	"github.com/unkod/space/core"
+	"github.com/unkod/space/tools/list"
+	"github.com/unkod/space/tools/subscriptions"
+	"golang.org/x/net/websocket"
+)
+
+// Realtime WebSocket client message types.
+const (
+	RealtimeWsMessageSubscribe   = "subscribe"
+	RealtimeWsMessageUnsubscribe = "unsubscribe"
+	RealtimeWsMessagePing        = "ping"
+)
+
+// Realtime WebSocket server only message names
+// (the other messages are the same as the SSE ones).
+const (
+	RealtimeWsSubscriptionsMessage = "PB_SUBSCRIPTIONS"
+	RealtimeWsPongMessage          = "PB_PONG"
+	RealtimeWsErrorMessage         = "PB_ERROR"
+)
+
+// realtimeWsMaxMessageSize is the max allowed size of a single client message.
+const realtimeWsMaxMessageSize = 64 << 10
+
+// realtimeWsClientMessage defines a single WebSocket client message.
+//
+// Authorization is an optional auth token used to (re)authorize
+// the connection with the subscribe/unsubscribe messages.
+type realtimeWsClientMessage struct {
+	Type          string   `json:"type"`
+	Subscriptions []string `json:"subscriptions"`
+	Authorization *string  `json:"authorization"`
+}
+
+// realtimeWsServerMessage defines a single WebSocket server message
+// (the same as the SSE message id, event name and data fields).
+type realtimeWsServerMessage struct {
+	Id    string          `json:"id"`
+	Event string          `json:"event"`
+	Data  json.RawMessage `json:"data"`
+}
+
+// connectWebSocket establishes a bidirectional realtime WebSocket connection.
+//
+// The connection shares the same broker, subscriptions and hooks as the SSE
+// connection, with the difference that the subscriptions are managed via
+// the client messages instead of separate HTTP requests:
+//
+//	{"type":"subscribe", "subscriptions":["demo/*"], "authorization":"TOKEN"}
+//	{"type":"unsubscribe", "subscriptions":["demo/*"]}
+//	{"type":"ping"}
+//
+// The connection auth state is loaded from the handshake Authorization header
+// (or the "token" query parameter for the browser clients that cannot set headers)
+// and could be updated only for the same auth model with the "authorization" message field.
+//
+// The "token" query parameter is always stripped from the request url
+// so that it is not stored in the request logs.
+func (api *realtimeApi) connectWebSocket(c echo.Context) error {
+	token := c.QueryParam("token")
+	if token != "" {
+		query := c.Request().URL.Query()
+		query.Del("token")
+		c.Request().URL.RawQuery = query.Encode()
+	}
+
+	if token != "" && c.Get(ContextAdminKey) == nil && c.Get(ContextAuthRecordKey) == nil {
+		c.Request().Header.Set("Authorization", token)
+		if err := api.loadAuth(c); err != nil {
+			return err
+		}
+	}
+
+	server := websocket.Server{
+		// the WebSocket connection is authorized with an explicit token
+		// so, similar to the SSE connection, any origin is allowed
+		Handshake: func(config *websocket.Config, r *http.Request) error {
+			return nil
+		},
+		Handler: func(conn *websocket.Conn) {
+			conn.MaxPayloadBytes = realtimeWsMaxMessageSize
+			api.serveWebSocket(c, conn)
+		},
+	}
+
+	server.ServeHTTP(c.Response(), c.Request())
+
+	return nil
+}
+
+// loadAuth (re)loads the request auth state from the current request Authorization header.
+func (api *realtimeApi) loadAuth(c echo.Context) error {
+	c.Set(ContextAdminKey, nil)
+	c.Set(ContextAuthRecordKey, nil)
+	c.Set(ContextImpersonatorKey, nil)
+	c.Set(ContextRequestInfoKey, nil) // reset the cached request info
+
+	return LoadAuthContext(api.app)(func(c echo.Context) error {
+		return nil
+	})(c)
+}
+
+func (api *realtimeApi) serveWebSocket(c echo.Context, conn *websocket.Conn) {
+	defer conn.Close()
+
+	cancelCtx, cancelRequest := context.WithCancel(c.Request().Context())
+	defer cancelRequest()
+	c.SetRequest(c.Request().Clone(cancelCtx))
+
+	// register new subscription client
+	client := subscriptions.NewDefaultClient()
+	api.app.SubscriptionsBroker().Register(client)
+	defer func() {
+		disconnectEvent := &core.RealtimeDisconnectEvent{
+			HttpContext: c,
+			Client:      client,
+		}
+
+		if err := api.app.OnRealtimeDisconnectRequest().Trigger(disconnectEvent); err != nil {
+			api.app.Logger().Debug("OnRealtimeDisconnectRequest failure", "clientId", client.Id(), "error", err)
+		}
+
+		api.app.SubscriptionsBroker().Unregister(client.Id())
+	}()
+
+	connectEvent := &core.RealtimeConnectEvent{
+		HttpContext: c,
+		Client:      client,
+		IdleTimeout: 5 * time.Minute,
+	}
+
+	if err := api.app.OnRealtimeConnectRequest().Trigger(connectEvent); err != nil {
+		api.sendWebSocketError(conn, client, err)
+		return
+	}
+
+	api.app.Logger().Debug("Realtime WebSocket connection established", "clientId", client.Id())
+
+	// signalize established connection (aka. fire "connect" message)
+	connectMsg := &subscriptions.Message{
+		Name: "PB_CONNECT",
+		Data: []byte(`{"clientId":"` + client.Id() + `"}`),
+	}
+	if err := api.sendWebSocketMessage(c, conn, client, connectMsg); err != nil {
+		api.app.Logger().Debug(
+			"Realtime WebSocket connection closed (failed to deliver PB_CONNECT)",
+			"clientId", client.Id(),
+			"error", err,
+		)
+		return
+	}
+
+	// read the client messages in a separate goroutine
+	// (all writes happen only in the current goroutine)
+	incoming := make(chan *realtimeWsClientMessage)
+	readErr := make(chan error, 1)
+	go func() {
+		for {
+			msg := &realtimeWsClientMessage{}
+			if err := websocket.JSON.Receive(conn, msg); err != nil {
+				readErr <- err
+				return
+			}
+
+			select {
+			case incoming <- msg:
+			case <-cancelCtx.Done():
+				return
+			}
+		}
+	}()
+
+	// start an idle timer to keep track of inactive/forgotten connections
+	idleTimeout := connectEvent.IdleTimeout
+	idleTimer := time.NewTimer(idleTimeout)
+	defer idleTimer.Stop()
+
+	resetIdleTimer := func() {
+		idleTimer.Stop()
+		idleTimer.Reset(idleTimeout)
+	}
+
+	for {
+		select {
+		case <-idleTimer.C:
+			api.app.Logger().Debug("Realtime WebSocket connection closed (idle timeout)", "clientId", client.Id())
+			return
+		case err := <-readErr:
+			api.app.Logger().Debug("Realtime WebSocket connection closed (read failure)", "clientId", client.Id(), "error", err)
+			return
+		case msg := <-incoming:
+			if err := api.handleWebSocketMessage(c, conn, client, msg); err != nil {
+				api.sendWebSocketError(conn, client, err)
+			}
+			resetIdleTimer()
+		case msg, ok := <-client.Channel():
+			if !ok {
+				// channel is closed
+				api.app.Logger().Debug("Realtime WebSocket connection closed (closed channel)", "clientId", client.Id())
+				return
+			}
+
+			if err := api.sendWebSocketMessage(c, conn, client, &msg); err != nil {
+				api.app.Logger().Debug(
+					"Realtime WebSocket connection closed (failed to deliver message)",
+					"clientId", client.Id(),
+					"error", err,
+				)
+				return
+			}
+
+			if msg.Name == RealtimeDisconnectMessage {
+				api.app.Logger().Debug("Realtime WebSocket connection closed (disconnected by the server)", "clientId", client.Id())
+				return
+			}
+
+			resetIdleTimer()
+		case <-cancelCtx.Done():
+			// connection is closed
+			api.app.Logger().Debug("Realtime WebSocket connection closed (cancelled request)", "clientId", client.Id())
+			return
+		}
+	}
+}
+
+// handleWebSocketMessage processes a single client message.
+func (api *realtimeApi) handleWebSocketMessage(
+	c echo.Context,
+	conn *websocket.Conn,
+	client subscriptions.Client,
+	msg *realtimeWsClientMessage,
+) error {
+	switch msg.Type {
+	case RealtimeWsMessagePing:
+		return api.sendWebSocketMessage(c, conn, client, &subscriptions.Message{
+			Name: RealtimeWsPongMessage,
+			Data: []byte(`{}`),
+		})
+	case RealtimeWsMessageSubscribe, RealtimeWsMessageUnsubscribe:
+		if msg.Authorization != nil {
+			c.Request().Header.Set("Authorization", *msg.Authorization)
+			if err := api.loadAuth(c); err != nil {
+				return err
+			}
+		}
+
+		// similar to the SSE subscribe request, the connection
+		// auth couldn't be changed to a different auth model
+		oldAuthId := extractAuthIdFromGetter(client)
+		newAuthId := extractAuthIdFromGetter(c)
+		if oldAuthId != "" && oldAuthId != newAuthId {
+			return NewForbiddenError("The current and the previous request authorization don't match.", nil)
+		}
+
+		subs := msg.Subscriptions
+		if msg.Type == RealtimeWsMessageUnsubscribe {
+			subs = []string{}
+			if len(msg.Subscriptions) > 0 {
+				for sub := range client.Subscriptions() {
+					if !list.ExistInSlice(sub, msg.Subscriptions) {
+						subs = append(subs, sub)
+					}
+				}
+			}
+		}
+
+		event := &core.RealtimeSubscribeEvent{
+			HttpContext:   c,
+			Client:        client,
+			Subscriptions: subs,
+		}
+
+		return api.app.OnRealtimeBeforeSubscribeRequest().Trigger(event, func(e *core.RealtimeSubscribeEvent) error {
+			if err := api.applySubscriptions(e); err != nil {
+				return err
+			}
+
+			return api.app.OnRealtimeAfterSubscribeRequest().Trigger(event, func(e *core.RealtimeSubscribeEvent) error {
+				current := make([]string, 0, len(e.Client.Subscriptions()))
+				for sub := range e.Client.Subscriptions() {
+					current = append(current, sub)
+				}
+
+				data, err := json.Marshal(map[string]any{"subscriptions": list.ToUniqueStringSlice(current)})
+				if err != nil {
+					return err
+				}
+
+				return api.sendWebSocketMessage(e.HttpContext, conn, e.Client, &subscriptions.Message{
+					Name: RealtimeWsSubscriptionsMessage,
+					Data: data,
+				})
+			})
+		})
+	default:
+		return NewBadRequestError(`Invalid message type - expected "subscribe", "unsubscribe" or "ping".`, nil)
+	}
+}
+
+// sendWebSocketMessage sends a single message to the WebSocket client
+// triggering the realtime message send hooks.
+func (api *realtimeApi) sendWebSocketMessage(
+	c echo.Context,
+	conn *websocket.Conn,
+	client subscriptions.Client,
+	msg *subscriptions.Message,
+) error {
+	msgEvent := &core.RealtimeMessageEvent{
+		HttpContext: c,
+		Client:      client,
+		Message:     msg,
+	}
+
+	return api.app.OnRealtimeBeforeMessageSend().Trigger(msgEvent, func(e *core.RealtimeMessageEvent) error {
+		err := websocket.JSON.Send(conn, realtimeWsServerMessage{
+			Id:    e.Client.Id(),
+			Event: e.Message.Name,
+			Data:  e.Message.Data,
+		})
+		if err != nil {
+			return err
+		}
+
+		return api.app.OnRealtimeAfterMessageSend().Trigger(e)
+	})
+}
+
+// sendWebSocketError sends the error as [RealtimeWsErrorMessage] to the WebSocket client
+// (without triggering the realtime message send hooks).
+func (api *realtimeApi) sendWebSocketError(conn *websocket.Conn, client subscriptions.Client, err error) {
+	var apiErr *ApiError
+	if !errors.As(err, &apiErr) {
+		apiErr = NewBadRequestError("", err)
+	}
+
+	data, _ := json.Marshal(apiErr)
+
+	websocket.JSON.Send(conn, realtimeWsServerMessage{
+		Id:    client.Id(),
+		Event: RealtimeWsErrorMessage,
+		Data:  data,
+	})
+}
@@ -4,8 +4,10 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
-	"log"
 	"net/http"
+	"strings"
+	"sync"
+	"time"
 
 	"github.com/labstack/echo/v5"
 	"github.com/pocketbase/dbx"
@@ -13,7 +15,9 @@ import (
 	"github.com/unkod/space/daos"
 	"github.com/unkod/space/forms"
 	"github.com/unkod/space/models"
+	"github.com/unkod/space/models/settings"
 	"github.com/unkod/space/resolvers"
+	"github.com/unkod/space/tokens"
 	"github.com/unkod/space/tools/auth"
 	"github.com/unkod/space/tools/routine"
 	"github.com/unkod/space/tools/search"
@@ -37,21 +41,31 @@ func bindRecordAuthApi(app core.App, rg *echo.Group) {
 		LoadCollectionContext(app, models.CollectionTypeAuth),
 	)
 	subGroup.GET("/auth-methods", api.authMethods)
+	subGroup.POST("/impersonate/:id", api.impersonate, RequireAdminAuth())
 	subGroup.POST("/auth-refresh", api.authRefresh, RequireSameContextRecordAuth())
-	subGroup.POST("/auth-with-oauth2", api.authWithOAuth2)
-	subGroup.POST("/auth-with-password", api.authWithPassword)
-	subGroup.POST("/request-password-reset", api.requestPasswordReset)
-	subGroup.POST("/confirm-password-reset", api.confirmPasswordReset)
-	subGroup.POST("/request-verification", api.requestVerification)
-	subGroup.POST("/confirm-verification", api.confirmVerification)
+	subGroup.POST("/auth-with-oauth2", api.authWithOAuth2, RateLimit(app, RateLimitLabelAuth))
+	subGroup.POST("/auth-with-password", api.authWithPassword, RateLimit(app, RateLimitLabelAuth))
+	subGroup.POST("/auth-with-mfa", api.authWithMFA, RateLimit(app, RateLimitLabelAuth))
+	subGroup.POST("/mfa/enroll", api.mfaEnroll, RequireSameContextRecordAuth())
+	subGroup.POST("/mfa/confirm", api.mfaConfirm, RequireSameContextRecordAuth(), RateLimit(app, RateLimitLabelAuth))
+	subGroup.POST("/mfa/disable", api.mfaDisable, RequireSameContextRecordAuth(), RateLimit(app, RateLimitLabelAuth))
+	subGroup.POST("/request-password-reset", api.requestPasswordReset, RateLimit(app, RateLimitLabelAuth))
+	subGroup.POST("/confirm-password-reset", api.confirmPasswordReset, RateLimit(app, RateLimitLabelAuth))
+	subGroup.POST("/request-verification", api.requestVerification, RateLimit(app, RateLimitLabelAuth))
+	subGroup.POST("/confirm-verification", api.confirmVerification, RateLimit(app, RateLimitLabelAuth))
 	subGroup.POST("/request-email-change", api.requestEmailChange, RequireSameContextRecordAuth())
-	subGroup.POST("/confirm-email-change", api.confirmEmailChange)
+	subGroup.POST("/confirm-email-change", api.confirmEmailChange, RateLimit(app, RateLimitLabelAuth))
 	subGroup.GET("/records/:id/external-auths", api.listExternalAuths, RequireAdminOrOwnerAuth("id"))
+	subGroup.POST("/records/:id/external-auths", api.linkExternalAuth, RequireAdminOrOwnerAuth("id"), RateLimit(app, RateLimitLabelAuth))
 	subGroup.DELETE("/records/:id/external-auths/:provider", api.unlinkExternalAuth, RequireAdminOrOwnerAuth("id"))
 }
 
 type recordAuthApi struct {
 	app core.App
+
+	// rotationMux serializes the auth token rotations so that
+	// concurrent refreshes with the same token rotate the key only once.
+	rotationMux sync.Mutex
 }
 
 func (api *recordAuthApi) authRefresh(c echo.Context) error {
@@ -60,18 +74,119 @@ func (api *recordAuthApi) authRefresh(c echo.Context) error {
 		return NewNotFoundError("Missing auth record context.", nil)
 	}
 
+	// prevent extending the impersonation with a regular auth token
+	if c.Get(ContextImpersonatorKey) != nil {
+		return NewForbiddenError("Impersonation tokens cannot be refreshed.", nil)
+	}
+
 	event := new(core.RecordAuthRefreshEvent)
 	event.HttpContext = c
 	event.Collection = record.Collection()
 	event.Record = record
 
 	return api.app.OnRecordBeforeAuthRefreshRequest().Trigger(event, func(e *core.RecordAuthRefreshEvent) error {
+		if e.Record.Collection().AuthOptions().RotateAuthToken {
+			rotated, err := api.rotateAuthToken(e.HttpContext, e.Record)
+			if err != nil {
+				return NewBadRequestError("Failed to rotate the auth token.", err)
+			}
+			e.Record = rotated
+		}
+
 		return api.app.OnRecordAfterAuthRefreshRequest().Trigger(event, func(e *core.RecordAuthRefreshEvent) error {
 			return RecordAuthResponse(api.app, e.HttpContext, e.Record, nil)
 		})
 	})
 }
 
+// rotateAuthToken regenerates the token key of the provided auth record
+// invalidating all previously issued tokens for the record.
+//
+// The rotation is not scoped per session/device, aka. the other devices
+// of the same auth record are logged out once the grace period expires.
+//
+// The previous token key remains valid for authRotationGracePeriod
+// to allow in-flight and concurrent requests with the old token to complete.
+// Concurrent refreshes with an already rotated token don't rotate the key again
+// and only receive a token signed with the current key.
+func (api *recordAuthApi) rotateAuthToken(c echo.Context, record *models.Record) (*models.Record, error) {
+	api.rotationMux.Lock()
+	defer api.rotationMux.Unlock()
+
+	// reload the record to ensure that the latest token key is used
+	fresh, err := api.app.Dao().FindRecordById(record.Collection().Id, record.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	token := strings.TrimPrefix(c.Request().Header.Get("Authorization"), "Bearer ")
+
+	_, err = security.ParseJWT(token, fresh.TokenKey()+api.app.Settings().RecordAuthToken.Secret)
+	if err != nil {
+		// the token was signed with an already rotated key
+		// (aka. it is still within its grace period)
+		return fresh, nil
+	}
+
+	oldKey := fresh.TokenKey()
+
+	if err := fresh.RefreshTokenKey(); err != nil {
+		return nil, err
+	}
+
+	if err := api.app.Dao().SaveRecord(fresh); err != nil {
+		return nil, err
+	}
+
+	api.app.Cache().Set(authRotationCacheKey(fresh.Collection().Id, fresh.Id), &rotatedTokenKey{
+		Key:       oldKey,
+		ExpiresAt: time.Now().Add(authRotationGracePeriod),
+	})
+
+	return fresh, nil
+}
+
+// impersonate generates a new auth token for the specified auth record
+// flagged as impersonated by the current admin.
+func (api *recordAuthApi) impersonate(c echo.Context) error {
+	if !api.app.Settings().Impersonation.Enabled {
+		return NewForbiddenError("Impersonation is disabled.", nil)
+	}
+
+	admin, _ := c.Get(ContextAdminKey).(*models.Admin)
+	if admin == nil {
+		return NewUnauthorizedError("Missing admin context.", nil)
+	}
+
+	collection, _ := c.Get(ContextCollectionKey).(*models.Collection)
+	if collection == nil {
+		return NewNotFoundError("Missing collection context.", nil)
+	}
+
+	securityEvent := newSecurityEvent(api.app, c, settings.SecurityEventRecordImpersonate)
+	eventData := map[string]any{"collectionId": collection.Id, "recordId": c.PathParam("id")}
+
+	record, err := api.app.Dao().FindRecordById(
+		collection.Id,
+		c.PathParam("id"),
+		daos.SoftDeletedFilter(collection, daos.SoftDeletedExclude),
+	)
+	if err != nil {
+		securityEvent.send(api.app, SecurityEventOutcomeFailure, eventData)
+		return NewNotFoundError("", err)
+	}
+
+	token, err := tokens.NewRecordImpersonateToken(api.app, record, admin)
+	if err != nil {
+		securityEvent.send(api.app, SecurityEventOutcomeFailure, eventData)
+		return NewBadRequestError("Failed to create impersonation token.", err)
+	}
+
+	securityEvent.send(api.app, SecurityEventOutcomeSuccess, eventData)
+
+	return recordAuthResponseWithToken(api.app, c, record, token, nil)
+}
+
 type providerInfo struct {
 	Name                string `json:"name"`
 	State               string `json:"state"`
@@ -111,21 +226,19 @@ func (api *recordAuthApi) authMethods(c echo.Context) error {
 
 		provider, err := auth.NewProviderByName(name)
 		if err != nil {
-			if api.app.IsDebug() {
-				log.Println(err)
-			}
+			api.app.Logger().Debug("Failed to load auth provider", "provider", name, "error", err)
 			continue // skip provider
 		}
 
+		provider.SetContext(c.Request().Context())
+
 		if err := config.SetupProvider(provider); err != nil {
-			if api.app.IsDebug() {
-				log.Println(err)
-			}
+			api.app.Logger().Debug("Failed to setup auth provider", "provider", name, "error", err)
 			continue // skip provider
 		}
 
-		state := security.RandomString(30)
 		codeVerifier := security.RandomString(43)
+		state := forms.NewOAuth2State(api.app, name, codeVerifier)
 		codeChallenge := security.S256Challenge(codeVerifier)
 		codeChallengeMethod := "S256"
 		urlOpts := []oauth2.AuthCodeOption{
@@ -251,6 +364,10 @@ func (api *recordAuthApi) authWithOAuth2(c echo.Context) error {
 				}
 
 				return api.app.OnRecordAfterAuthWithOAuth2Request().Trigger(event, func(e *core.RecordAuthWithOAuth2Event) error {
+					if e.Record.MFAEnabled() {
+						return recordMFAChallengeResponse(api.app, e.HttpContext, e.Record)
+					}
+
 					return RecordAuthResponse(api.app, e.HttpContext, e.Record, meta)
 				})
 			})
@@ -271,6 +388,16 @@ func (api *recordAuthApi) authWithPassword(c echo.Context) error {
 		return NewBadRequestError("An error occurred while loading the submitted data.", readErr)
 	}
 
+	securityEvent := newSecurityEvent(api.app, c, settings.SecurityEventRecordAuth)
+	securityEvent.Actor.CollectionId = collection.Id
+	securityEvent.Actor.Identity = form.Identity
+
+	lock := newLoginLockout(api.app, c, collection.Id, form.Identity)
+	if err := lock.check(); err != nil {
+		securityEvent.send(api.app, SecurityEventOutcomeFailure, map[string]any{"reason": "lockout"})
+		return err
+	}
+
 	event := new(core.RecordAuthWithPasswordEvent)
 	event.HttpContext = c
 	event.Collection = collection
@@ -283,10 +410,21 @@ func (api *recordAuthApi) authWithPassword(c echo.Context) error {
 
 			return api.app.OnRecordBeforeAuthWithPasswordRequest().Trigger(event, func(e *core.RecordAuthWithPasswordEvent) error {
 				if err := next(e.Record); err != nil {
+					lock.fail()
+					securityEvent.send(api.app, SecurityEventOutcomeFailure, map[string]any{"reason": "invalidCredentials"})
 					return NewBadRequestError("Failed to authenticate.", err)
 				}
 
+				lock.reset()
+
+				securityEvent.setAuthRecord(e.Record)
+				securityEvent.send(api.app, SecurityEventOutcomeSuccess, map[string]any{"mfaRequired": e.Record.MFAEnabled()})
+
 				return api.app.OnRecordAfterAuthWithPasswordRequest().Trigger(event, func(e *core.RecordAuthWithPasswordEvent) error {
+					if e.Record.MFAEnabled() {
+						return recordMFAChallengeResponse(api.app, e.HttpContext, e.Record)
+					}
+
 					return RecordAuthResponse(api.app, e.HttpContext, e.Record, nil)
 				})
 			})
@@ -296,6 +434,88 @@ func (api *recordAuthApi) authWithPassword(c echo.Context) error {
 	return submitErr
 }
 
+func (api *recordAuthApi) authWithMFA(c echo.Context) error {
+	collection, _ := c.Get(ContextCollectionKey).(*models.Collection)
+	if collection == nil {
+		return NewNotFoundError("Missing collection context.", nil)
+	}
+
+	form := forms.NewRecordMFALogin(api.app, collection)
+	if readErr := c.Bind(form); readErr != nil {
+		return NewBadRequestError("An error occurred while loading the submitted data.", readErr)
+	}
+
+	lock := newMFALoginLockout(api.app, c, collection.Id, form.MfaId)
+	if err := lock.check(); err != nil {
+		return err
+	}
+
+	record, err := form.Submit()
+	if err != nil {
+		if forms.IsInvalidMFACodeError(err) {
+			lock.fail()
+		}
+		return NewBadRequestError("Failed to authenticate.", err)
+	}
+
+	lock.reset()
+
+	return RecordAuthResponse(api.app, c, record, nil)
+}
+
+func (api *recordAuthApi) mfaEnroll(c echo.Context) error {
+	record, _ := c.Get(ContextAuthRecordKey).(*models.Record)
+	if record == nil {
+		return NewNotFoundError("Missing auth record context.", nil)
+	}
+
+	enrollment, err := forms.NewRecordMFAEnroll(api.app, record).Submit()
+	if err != nil {
+		return NewBadRequestError("Failed to enroll MFA.", err)
+	}
+
+	return c.JSON(http.StatusOK, enrollment)
+}
+
+func (api *recordAuthApi) mfaConfirm(c echo.Context) error {
+	record, _ := c.Get(ContextAuthRecordKey).(*models.Record)
+	if record == nil {
+		return NewNotFoundError("Missing auth record context.", nil)
+	}
+
+	form := forms.NewRecordMFAConfirm(api.app, record)
+	if readErr := c.Bind(form); readErr != nil {
+		return NewBadRequestError("An error occurred while loading the submitted data.", readErr)
+	}
+
+	backupCodes, err := form.Submit()
+	if err != nil {
+		return NewBadRequestError("Failed to confirm MFA.", err)
+	}
+
+	return c.JSON(http.StatusOK, map[string]any{
+		"backupCodes": backupCodes,
+	})
+}
+
+func (api *recordAuthApi) mfaDisable(c echo.Context) error {
+	record, _ := c.Get(ContextAuthRecordKey).(*models.Record)
+	if record == nil {
+		return NewNotFoundError("Missing auth record context.", nil)
+	}
+
+	form := forms.NewRecordMFADisable(api.app, record)
+	if readErr := c.Bind(form); readErr != nil {
+		return NewBadRequestError("An error occurred while loading the submitted data.", readErr)
+	}
+
+	if err := form.Submit(); err != nil {
+		return NewBadRequestError("Failed to disable MFA.", err)
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
 func (api *recordAuthApi) requestPasswordReset(c echo.Context) error {
 	collection, _ := c.Get(ContextCollectionKey).(*models.Collection)
 	if collection == nil {
@@ -327,8 +547,8 @@ func (api *recordAuthApi) requestPasswordReset(c echo.Context) error {
 			return api.app.OnRecordBeforeRequestPasswordResetRequest().Trigger(event, func(e *core.RecordRequestPasswordResetEvent) error {
 				// run in background because we don't need to show the result to the client
 				routine.FireAndForget(func() {
-					if err := next(e.Record); err != nil && api.app.IsDebug() {
-						log.Println(err)
+					if err := next(e.Record); err != nil {
+						api.app.Logger().Debug("Failed to send record password reset email", "error", err)
 					}
 				})
 
@@ -416,8 +636,8 @@ func (api *recordAuthApi) requestVerification(c echo.Context) error {
 			return api.app.OnRecordBeforeRequestVerificationRequest().Trigger(event, func(e *core.RecordRequestVerificationEvent) error {
 				// run in background because we don't need to show the result to the client
 				routine.FireAndForget(func() {
-					if err := next(e.Record); err != nil && api.app.IsDebug() {
-						log.Println(err)
+					if err := next(e.Record); err != nil {
+						api.app.Logger().Debug("Failed to send record verification email", "error", err)
 					}
 				})
 
@@ -589,6 +809,72 @@ func (api *recordAuthApi) listExternalAuths(c echo.Context) error {
 	})
 }
 
+func (api *recordAuthApi) linkExternalAuth(c echo.Context) error {
+	collection, _ := c.Get(ContextCollectionKey).(*models.Collection)
+	if collection == nil {
+		return NewNotFoundError("Missing collection context.", nil)
+	}
+
+	if !collection.AuthOptions().AllowOAuth2Auth {
+		return NewBadRequestError("The collection is not configured to allow OAuth2 authentication.", nil)
+	}
+
+	id := c.PathParam("id")
+	if id == "" {
+		return NewNotFoundError("", nil)
+	}
+
+	record, err := api.app.Dao().FindRecordById(collection.Id, id)
+	if err != nil || record == nil {
+		return NewNotFoundError("", err)
+	}
+
+	form := forms.NewRecordOAuth2Link(api.app, record)
+	if readErr := c.Bind(form); readErr != nil {
+		return NewBadRequestError("An error occurred while loading the submitted data.", readErr)
+	}
+
+	event := new(core.RecordLinkExternalAuthEvent)
+	event.HttpContext = c
+	event.Collection = collection
+	event.Record = record
+
+	_, _, submitErr := form.Submit(func(next forms.InterceptorNextFunc[*forms.RecordOAuth2LinkData]) forms.InterceptorNextFunc[*forms.RecordOAuth2LinkData] {
+		return func(data *forms.RecordOAuth2LinkData) error {
+			event.ExternalAuth = data.ExternalAuth
+			event.OAuth2User = data.OAuth2User
+			event.ProviderClient = data.ProviderClient
+
+			return api.app.OnRecordBeforeLinkExternalAuthRequest().Trigger(event, func(e *core.RecordLinkExternalAuthEvent) error {
+				data.ExternalAuth = e.ExternalAuth
+
+				if err := next(data); err != nil {
+					return NewBadRequestError("Failed to link the external auth provider.", err)
+				}
+
+				return api.app.OnRecordAfterLinkExternalAuthRequest().Trigger(event, func(e *core.RecordLinkExternalAuthEvent) error {
+					if e.HttpContext.Response().Committed {
+						return nil
+					}
+
+					return e.HttpContext.JSON(http.StatusOK, e.ExternalAuth)
+				})
+			})
+		}
+	})
+
+	if submitErr == nil {
+		return nil
+	}
+
+	// the interceptor errors are already api errors
+	if apiErr, ok := submitErr.(*ApiError); ok {
+		return apiErr
+	}
+
+	return NewBadRequestError("Failed to link the external auth provider.", submitErr)
+}
+
 func (api *recordAuthApi) unlinkExternalAuth(c echo.Context) error {
 	collection, _ := c.Get(ContextCollectionKey).(*models.Collection)
 	if collection == nil {
@@ -611,6 +897,15 @@ func (api *recordAuthApi) unlinkExternalAuth(c echo.Context) error {
 		return NewNotFoundError("missing external auth provider relation", err)
 	}
 
+	// prevent the auth record lockout
+	externalAuths, err := api.app.Dao().FindAllExternalAuthsByRecord(record)
+	if err != nil {
+		return NewBadRequestError("Failed to fetch the external auths for the specified auth record.", err)
+	}
+	if len(externalAuths) <= 1 && !hasPasswordAuth(record) {
+		return NewBadRequestError("Cannot unlink the last auth method of an auth record that can't authenticate with password.", nil)
+	}
+
 	event := new(core.RecordUnlinkExternalAuthEvent)
 	event.HttpContext = c
 	event.Collection = collection
@@ -632,6 +927,18 @@ func (api *recordAuthApi) unlinkExternalAuth(c echo.Context) error {
 	})
 }
 
+// hasPasswordAuth checks whether the auth record could still
+// authenticate (or request a password reset) with its password.
+func hasPasswordAuth(record *models.Record) bool {
+	if record.PasswordHash() == "" {
+		return false
+	}
+
+	options := record.Collection().AuthOptions()
+
+	return options.AllowUsernameAuth || (options.AllowEmailAuth && record.Email() != "")
+}
+
 // -------------------------------------------------------------------
 
 const oauth2SubscriptionTopic = "@oauth2"
@@ -1,23 +1,65 @@
 package apis
 
 import (
+	"encoding/csv"
+	"errors"
 	"fmt"
-	"log"
 	"net/http"
+	"strconv"
 	"strings"
 
+	validation "github.com/go-ozzo/ozzo-validation/v4"
 	"github.com/labstack/echo/v5"
 	"github.com/pocketbase/dbx"
+	"github.com/spf13/cast"
 	"github.com/unkod/space/core"
 	"github.com/unkod/space/daos"
 	"github.com/unkod/space/forms"
 	"github.com/unkod/space/models"
+	"github.com/unkod/space/models/schema"
 	"github.com/unkod/space/resolvers"
+	"github.com/unkod/space/tools/list"
+	"github.com/unkod/space/tools/rest"
 	"github.com/unkod/space/tools/search"
 )
 
 const expandQueryParam = "expand"
 
+const fieldsQueryParam = "fields"
+
+const upsertQueryParam = "upsert"
+
+const dryRunQueryParam = "dryRun"
+
+const distinctQueryParam = "distinct"
+
+const deletedQueryParam = "deleted"
+
+const searchQueryParam = "search"
+
+const allQueryParam = "all"
+
+// mimeApplicationNdjson is the newline delimited JSON content type
+// used for streaming the records list (see [recordApi.listNdjson]).
+const mimeApplicationNdjson = "application/x-ndjson"
+
+// negotiateXML middleware marks the route responses to be serialized
+// as XML if it is the preferred media type of the request Accept header
+// (the responses fallback to JSON for any other Accept value).
+func negotiateXML() echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			c.Response().Header().Add(echo.HeaderVary, echo.HeaderAccept)
+
+			if rest.PrefersXML(c.Request().Header.Get(echo.HeaderAccept)) {
+				c.Set(rest.ContextXMLKey, true)
+			}
+
+			return next(c)
+		}
+	}
+}
+
 // bindRecordCrudApi registers the record crud api endpoints and
 // the corresponding handlers.
 func bindRecordCrudApi(app core.App, rg *echo.Group) {
@@ -28,11 +70,15 @@ func bindRecordCrudApi(app core.App, rg *echo.Group) {
 		ActivityLogger(app),
 	)
 
-	subGroup.GET("/records", api.list, LoadCollectionContext(app))
-	subGroup.GET("/records/:id", api.view, LoadCollectionContext(app))
-	subGroup.POST("/records", api.create, LoadCollectionContext(app, models.CollectionTypeBase, models.CollectionTypeAuth))
+	subGroup.GET("/records", api.list, RateLimit(app, RateLimitLabelRead), negotiateXML(), LoadCollectionContext(app))
+	subGroup.GET("/records/export.csv", api.exportCsv, RateLimit(app, RateLimitLabelRead), RequestTimeout(0), LoadCollectionContext(app))
+	subGroup.GET("/records/:id", api.view, RateLimit(app, RateLimitLabelRead), negotiateXML(), LoadCollectionContext(app))
+	subGroup.POST("/records", api.create, LoadCollectionContext(app, models.CollectionTypeBase, models.CollectionTypeAuth), Idempotency(app))
+	subGroup.POST("/records/bulk", api.bulkCreate, LoadCollectionContext(app, models.CollectionTypeBase, models.CollectionTypeAuth))
 	subGroup.PATCH("/records/:id", api.update, LoadCollectionContext(app, models.CollectionTypeBase, models.CollectionTypeAuth))
+	subGroup.DELETE("/records", api.bulkDelete, LoadCollectionContext(app, models.CollectionTypeBase, models.CollectionTypeAuth))
 	subGroup.DELETE("/records/:id", api.delete, LoadCollectionContext(app, models.CollectionTypeBase, models.CollectionTypeAuth))
+	subGroup.POST("/records/:id/restore", api.restore, RequireAdminAuth(), LoadCollectionContext(app, models.CollectionTypeBase, models.CollectionTypeAuth))
 }
 
 type recordApi struct {
@@ -57,6 +103,19 @@ func (api *recordApi) list(c echo.Context) error {
 		return NewForbiddenError("Only admins can perform this action.", nil)
 	}
 
+	deletedMode, err := softDeletedFilterMode(c, requestInfo)
+	if err != nil {
+		return err
+	}
+
+	if err := checkExpandDepth(requestReadDao(api.app, c), c); err != nil {
+		return err
+	}
+
+	if strings.Contains(c.Request().Header.Get(echo.HeaderAccept), mimeApplicationNdjson) {
+		return api.listNdjson(c, collection, requestInfo, deletedMode)
+	}
+
 	fieldsResolver := resolvers.NewRecordFieldResolver(
 		api.app.Dao(),
 		collection,
@@ -65,17 +124,36 @@ func (api *recordApi) list(c echo.Context) error {
 		requestInfo.Admin != nil,
 	)
 
-	searchProvider := search.NewProvider(fieldsResolver).
-		Query(api.app.Dao().RecordQuery(collection))
+	query := requestReadDao(api.app, c).RecordQuery(collection)
+	if err := daos.SoftDeletedFilter(collection, deletedMode)(query); err != nil {
+		return NewBadRequestError("", err)
+	}
+
+	// order by relevance only if there is no explicit sort
+	orderByRank := c.QueryParam(search.SortQueryParam) == "" &&
+		c.QueryParam(search.CursorQueryParam) == "" &&
+		c.QueryParam(distinctQueryParam) == ""
+	if err := applySearchParam(c, requestReadDao(api.app, c), collection, query, orderByRank); err != nil {
+		return err
+	}
+
+	searchProvider := search.NewProvider(fieldsResolver).Query(query)
 
 	if requestInfo.Admin == nil && collection.ListRule != nil {
 		searchProvider.AddFilter(search.FilterData(*collection.ListRule))
 	}
 
+	if raw := c.QueryParam(distinctQueryParam); raw != "" {
+		return api.listDistinct(c, collection, query, searchProvider, raw, requestInfo.Admin != nil)
+	}
+
 	records := []*models.Record{}
 
 	result, err := searchProvider.ParseAndExec(c.QueryParams().Encode(), &records)
 	if err != nil {
+		if errors.Is(err, search.ErrInvalidCursor) {
+			return NewBadRequestError("Invalid or mismatched pagination cursor. Make sure that the sort parameter hasn't changed.", err)
+		}
 		return NewBadRequestError("Invalid filter parameters.", err)
 	}
 
@@ -90,14 +168,394 @@ func (api *recordApi) list(c echo.Context) error {
 			return nil
 		}
 
-		if err := EnrichRecords(e.HttpContext, api.app.Dao(), e.Records); err != nil && api.app.IsDebug() {
-			log.Println(err)
+		if err := EnrichRecords(e.HttpContext, requestReadDao(api.app, e.HttpContext), e.Records); err != nil {
+			api.app.Logger().Debug("Failed to enrich records", requestLogAttrs(e.HttpContext, "error", err)...)
+		}
+
+		if err := triggerRecordsBeforeSend(api.app, e.HttpContext, e.Records); err != nil {
+			return err
+		}
+
+		return e.HttpContext.JSON(http.StatusOK, e.Result)
+	})
+}
+
+// listDistinct returns the unique combinations of the specified
+// comma separated distinct fields of the records matching the search
+// provider filters (eg. "?distinct=category,status&sort=category").
+//
+// The distinct select is applied on the already filtered list base query
+// so that the list soft-delete and search conditions are preserved.
+//
+// Each result item contains only the distinct fields.
+// The total counts are available only for a single distinct field
+// and the cursor pagination is not supported.
+func (api *recordApi) listDistinct(
+	c echo.Context,
+	collection *models.Collection,
+	query *dbx.SelectQuery,
+	searchProvider *search.Provider,
+	distinctParam string,
+	isAdmin bool,
+) error {
+	columns, err := distinctColumns(collection, distinctParam, isAdmin)
+	if err != nil {
+		return NewBadRequestError("Invalid distinct fields.", err)
+	}
+
+	if c.QueryParams().Has(search.CursorQueryParam) {
+		return NewBadRequestError("The cursor pagination is not supported with the distinct param.", nil)
+	}
+
+	selectCols := make([]string, len(columns))
+	for i, col := range columns {
+		selectCols[i] = fmt.Sprintf("[[%s.%s]]", collection.Name, col)
+	}
+
+	searchProvider.Query(query.Select(selectCols...).Distinct(true))
+
+	if err := searchProvider.Parse(c.QueryParams().Encode()); err != nil {
+		return NewBadRequestError("Invalid filter parameters.", err)
+	}
+
+	if len(columns) == 1 {
+		searchProvider.CountCol(columns[0])
+	} else if cast.ToBool(c.QueryParam(search.CountOnlyQueryParam)) {
+		return NewBadRequestError("The countOnly param is supported only with a single distinct field.", nil)
+	} else {
+		// there is no single column that could be used to count the unique rows
+		searchProvider.SkipTotal(true)
+	}
+
+	records := []*models.Record{}
+
+	result, err := searchProvider.Exec(&records)
+	if err != nil {
+		return NewBadRequestError("Invalid filter parameters.", err)
+	}
+
+	if result.Items != nil { // not a countOnly request
+		items := make([]map[string]any, len(records))
+		for i, record := range records {
+			item := make(map[string]any, len(columns))
+			for _, col := range columns {
+				item[col] = record.Get(col)
+			}
+			items[i] = item
+		}
+		result.Items = items
+	}
+
+	event := new(core.RecordsListEvent)
+	event.HttpContext = c
+	event.Collection = collection
+	event.Records = records
+	event.Result = result
+
+	return api.app.OnRecordsListRequest().Trigger(event, func(e *core.RecordsListEvent) error {
+		if e.HttpContext.Response().Committed {
+			return nil
 		}
 
 		return e.HttpContext.JSON(http.StatusOK, e.Result)
 	})
 }
 
+// distinctColumns parses and validates the comma separated distinct param fields.
+//
+// Only the records "id", "created", "updated" and non-computed schema fields
+// (and the public auth fields for auth collections) are allowed.
+//
+// Encrypted fields are not allowed because their values are stored
+// with random nonces and can't be compared.
+//
+// Fields with a read rule are allowed only for admins
+// (similar to the filter and sort expressions).
+func distinctColumns(collection *models.Collection, distinctParam string, isAdmin bool) ([]string, error) {
+	allowed := map[string]struct{}{
+		schema.FieldNameId: {},
+	}
+
+	if !collection.IsView() {
+		allowed[schema.FieldNameCreated] = struct{}{}
+		allowed[schema.FieldNameUpdated] = struct{}{}
+	}
+
+	if collection.IsAuth() {
+		allowed[schema.FieldNameUsername] = struct{}{}
+		allowed[schema.FieldNameVerified] = struct{}{}
+		allowed[schema.FieldNameEmailVisibility] = struct{}{}
+		if isAdmin {
+			allowed[schema.FieldNameEmail] = struct{}{}
+		}
+	}
+
+	for _, field := range collection.Schema.Fields() {
+		if field.Type == schema.FieldTypeComputed {
+			continue // no db column
+		}
+		if daos.IsEncryptedField(field) {
+			continue
+		}
+		if field.ReadRule != "" && !isAdmin {
+			continue
+		}
+		allowed[field.Name] = struct{}{}
+	}
+
+	columns := []string{}
+	existing := map[string]struct{}{}
+
+	for _, name := range strings.Split(distinctParam, ",") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+
+		if _, ok := allowed[name]; !ok {
+			return nil, validation.Errors{
+				distinctQueryParam: validation.NewError(
+					"validation_unknown_distinct_field",
+					fmt.Sprintf("Unknown or unsupported distinct field %q.", name),
+				),
+			}
+		}
+
+		if _, ok := existing[name]; ok {
+			continue // duplicated
+		}
+		existing[name] = struct{}{}
+
+		columns = append(columns, name)
+	}
+
+	if len(columns) == 0 {
+		return nil, validation.Errors{
+			distinctQueryParam: validation.NewError("validation_required", "Cannot be blank."),
+		}
+	}
+
+	return columns, nil
+}
+
+// exportBatchSize specifies the default max number of records that
+// are loaded in memory at once during a CSV or NDJSON records export.
+const exportBatchSize = search.MaxPerPage
+
+// recordsBatchFetcher returns a function that fetches the next batch
+// of collection records for the specified cursor while respecting the
+// same "filter", "sort" and "search" query params and access rules as
+// the list endpoint.
+//
+// The returned next cursor is empty if there are no more records.
+func (api *recordApi) recordsBatchFetcher(
+	c echo.Context,
+	collection *models.Collection,
+	requestInfo *models.RequestInfo,
+	deletedMode string,
+	batchSize int,
+) func(cursor string) ([]*models.Record, string, error) {
+	return func(cursor string) ([]*models.Record, string, error) {
+		fieldsResolver := resolvers.NewRecordFieldResolver(
+			api.app.Dao(),
+			collection,
+			requestInfo,
+			// hidden fields are searchable only by admins
+			requestInfo.Admin != nil,
+		)
+
+		query := requestReadDao(api.app, c).RecordQuery(collection)
+		if err := daos.SoftDeletedFilter(collection, deletedMode)(query); err != nil {
+			return nil, "", err
+		}
+		if err := applySearchParam(c, requestReadDao(api.app, c), collection, query, false); err != nil {
+			return nil, "", err
+		}
+
+		searchProvider := search.NewProvider(fieldsResolver).
+			Query(query).
+			SkipTotal(true).
+			PerPage(batchSize).
+			Cursor(cursor)
+
+		if requestInfo.Admin == nil && collection.ListRule != nil {
+			searchProvider.AddFilter(search.FilterData(*collection.ListRule))
+		}
+
+		if raw := c.QueryParam(search.SortQueryParam); raw != "" {
+			searchProvider.Sort(search.ParseSortFromString(raw))
+		}
+
+		searchProvider.AddFilter(search.FilterData(c.QueryParam(search.FilterQueryParam)))
+
+		records := []*models.Record{}
+
+		result, err := searchProvider.Exec(&records)
+		if err != nil {
+			return nil, "", err
+		}
+
+		if err := autoIgnoreAuthRecordsEmailVisibility(api.app.Dao(), records, requestInfo, requestCache(c, api.app.Dao())); err != nil {
+			api.app.Logger().Debug("Failed to resolve the records email visibility", requestLogAttrs(c, "error", err)...)
+		}
+
+		if err := applyFieldReadRules(requestReadDao(api.app, c), records, requestInfo); err != nil {
+			return nil, "", err
+		}
+
+		return records, result.NextCursor, nil
+	}
+}
+
+// listNdjson streams all collection records matching the list
+// request as newline delimited JSON (one record per line).
+//
+// The "page" and "cursor" query params are ignored and the "perPage"
+// param is used only as batch size. The "fields" and "expand"
+// params are applied to each record line.
+func (api *recordApi) listNdjson(
+	c echo.Context,
+	collection *models.Collection,
+	requestInfo *models.RequestInfo,
+	deletedMode string,
+) error {
+	batchSize := cast.ToInt(c.QueryParam(search.PerPageQueryParam))
+	if batchSize <= 0 || batchSize > exportBatchSize {
+		batchSize = exportBatchSize
+	}
+
+	fetchBatch := api.recordsBatchFetcher(c, collection, requestInfo, deletedMode, batchSize)
+
+	// fetch the first batch before writing the response
+	// so that the query errors could be reported properly
+	records, cursor, err := fetchBatch("")
+	if err != nil {
+		return NewBadRequestError("Invalid filter parameters.", err)
+	}
+
+	res := c.Response()
+	res.Header().Set(echo.HeaderContentType, mimeApplicationNdjson)
+	res.WriteHeader(http.StatusOK)
+
+	for {
+		if err := EnrichRecords(c, requestReadDao(api.app, c), records); err != nil {
+			api.app.Logger().Debug("Failed to enrich records", requestLogAttrs(c, "error", err)...)
+		}
+
+		if err := triggerRecordsBeforeSend(api.app, c, records); err != nil {
+			// the response is already sent and the error cannot be returned to the client
+			api.app.Logger().Debug("Failed to stream records", requestLogAttrs(c, "error", err)...)
+			return nil
+		}
+
+		for _, record := range records {
+			// the json encoder terminates each value with a new line
+			if err := c.Echo().JSONSerializer.Serialize(c, record, ""); err != nil {
+				return err
+			}
+		}
+
+		res.Flush()
+
+		if cursor == "" {
+			return nil
+		}
+
+		records, cursor, err = fetchBatch(cursor)
+		if err != nil {
+			// the response is already sent and the error cannot be returned to the client
+			api.app.Logger().Debug("Failed to stream records", requestLogAttrs(c, "error", err)...)
+			return nil
+		}
+	}
+}
+
+// exportCsv streams the collection records as CSV while
+// respecting the same "filter", "sort" and "fields" query
+// params and access rules as the list endpoint.
+//
+// The records are fetched in batches using the cursor pagination
+// so that the entire collection is never loaded in memory at once.
+func (api *recordApi) exportCsv(c echo.Context) error {
+	collection, _ := c.Get(ContextCollectionKey).(*models.Collection)
+	if collection == nil {
+		return NewNotFoundError("", "Missing collection context.")
+	}
+
+	// forbid users and guests to query special filter/sort fields
+	if err := api.checkForForbiddenQueryFields(c); err != nil {
+		return err
+	}
+
+	requestInfo := RequestInfo(c)
+
+	if requestInfo.Admin == nil && collection.ListRule == nil {
+		// only admins can access if the rule is nil
+		return NewForbiddenError("Only admins can perform this action.", nil)
+	}
+
+	deletedMode, err := softDeletedFilterMode(c, requestInfo)
+	if err != nil {
+		return err
+	}
+
+	columns := csvExportColumns(collection, c.QueryParam(fieldsQueryParam))
+
+	fetchBatch := api.recordsBatchFetcher(c, collection, requestInfo, deletedMode, exportBatchSize)
+
+	// fetch the first batch before writing the response
+	// so that the query errors could be reported properly
+	records, cursor, err := fetchBatch("")
+	if err != nil {
+		return NewBadRequestError("Invalid filter parameters.", err)
+	}
+
+	res := c.Response()
+	res.Header().Set(echo.HeaderContentType, "text/csv; charset=utf-8")
+	res.Header().Set(echo.HeaderContentDisposition, "attachment; filename="+collection.Name+".csv")
+	res.WriteHeader(http.StatusOK)
+
+	writer := csv.NewWriter(res)
+
+	if err := writer.Write(columns); err != nil {
+		return err
+	}
+
+	row := make([]string, len(columns))
+	for {
+		for _, record := range records {
+			data := record.PublicExport()
+			for i, column := range columns {
+				row[i] = csvValue(data[column])
+			}
+			if err := writer.Write(row); err != nil {
+				return err
+			}
+		}
+
+		writer.Flush()
+		if err := writer.Error(); err != nil {
+			return err
+		}
+		res.Flush()
+
+		if cursor == "" {
+			return nil
+		}
+
+		records, cursor, err = fetchBatch(cursor)
+		if err != nil {
+			// the response is already sent and the error cannot be returned to the client
+			api.app.Logger().Debug("Failed to export records", requestLogAttrs(c, "error", err)...)
+			return nil
+		}
+	}
+}
+
+// view returns a single collection record.
+//
+// The response has a weak ETag header (see [RecordETag]) and a 304 Not Modified
+// response with an empty body is returned if it matches the If-None-Match header.
 func (api *recordApi) view(c echo.Context) error {
 	collection, _ := c.Get(ContextCollectionKey).(*models.Collection)
 	if collection == nil {
@@ -116,6 +574,15 @@ func (api *recordApi) view(c echo.Context) error {
 		return NewForbiddenError("Only admins can perform this action.", nil)
 	}
 
+	deletedMode, err := softDeletedFilterMode(c, requestInfo)
+	if err != nil {
+		return err
+	}
+
+	if err := checkExpandDepth(requestReadDao(api.app, c), c); err != nil {
+		return err
+	}
+
 	ruleFunc := func(q *dbx.SelectQuery) error {
 		if requestInfo.Admin == nil && collection.ViewRule != nil && *collection.ViewRule != "" {
 			resolver := resolvers.NewRecordFieldResolver(api.app.Dao(), collection, requestInfo, true)
@@ -129,7 +596,12 @@ func (api *recordApi) view(c echo.Context) error {
 		return nil
 	}
 
-	record, fetchErr := api.app.Dao().FindRecordById(collection.Id, recordId, ruleFunc)
+	record, fetchErr := requestReadDao(api.app, c).FindRecordById(
+		collection.Id,
+		recordId,
+		daos.SoftDeletedFilter(collection, deletedMode),
+		ruleFunc,
+	)
 	if fetchErr != nil || record == nil {
 		return NewNotFoundError("", fetchErr)
 	}
@@ -144,14 +616,32 @@ func (api *recordApi) view(c echo.Context) error {
 			return nil
 		}
 
-		if err := EnrichRecord(e.HttpContext, api.app.Dao(), e.Record); err != nil && api.app.IsDebug() {
-			log.Println(err)
+		if err := EnrichRecord(e.HttpContext, requestReadDao(api.app, e.HttpContext), e.Record); err != nil {
+			api.app.Logger().Debug("Failed to enrich record", requestLogAttrs(e.HttpContext, "error", err)...)
+		}
+
+		etag := RecordETag(e.HttpContext, e.Record)
+		e.HttpContext.Response().Header().Set("ETag", etag)
+
+		if checkIfNoneMatch(e.HttpContext, etag) {
+			return e.HttpContext.NoContent(http.StatusNotModified)
+		}
+
+		if err := triggerRecordsBeforeSend(api.app, e.HttpContext, []*models.Record{e.Record}); err != nil {
+			return err
 		}
 
 		return e.HttpContext.JSON(http.StatusOK, e.Record)
 	})
 }
 
+// create creates a new collection record.
+//
+// With "?upsert=true", if the submitted data conflicts with an existing record
+// on its id or on any of the collection unique indexes, the conflicting record
+// is updated instead (applying the update rule and the update request hooks).
+// In this mode the response status is 201 for the created records
+// and 200 for the updated ones.
 func (api *recordApi) create(c echo.Context) error {
 	collection, _ := c.Get(ContextCollectionKey).(*models.Collection)
 	if collection == nil {
@@ -160,6 +650,33 @@ func (api *recordApi) create(c echo.Context) error {
 
 	requestInfo := RequestInfo(c)
 
+	isUpsert := cast.ToBool(c.QueryParam(upsertQueryParam))
+
+	if isUpsert {
+		conflicts, err := api.app.Dao().FindUniqueConflictRecords(collection, requestInfo.Data)
+		if err != nil {
+			return NewBadRequestError("Failed to check the submitted data for conflicts.", err)
+		}
+
+		if len(conflicts) > 1 {
+			return NewBadRequestError("The submitted data conflicts with more than one existing record.", nil)
+		}
+
+		if len(conflicts) == 1 {
+			if requestInfo.Admin == nil && collection.UpdateRule == nil {
+				// only admins can access if the rule is nil
+				return NewForbiddenError("Only admins can update the conflicting record.", nil)
+			}
+
+			record, err := api.findUpdatableRecord(requestInfo, collection, conflicts[0].Id)
+			if err != nil || record == nil {
+				return NewForbiddenError("You are not allowed to update the conflicting record.", err)
+			}
+
+			return api.updateRecord(c, collection, record.Id)
+		}
+	}
+
 	if requestInfo.Admin == nil && collection.CreateRule == nil {
 		// only admins can access if the rule is nil
 		return NewForbiddenError("Only admins can perform this action.", nil)
@@ -179,10 +696,17 @@ func (api *recordApi) create(c echo.Context) error {
 
 		testForm := forms.NewRecordUpsert(api.app, testRecord)
 		testForm.SetFullManageAccess(true)
+		testForm.SetRequestInfo(requestInfo)
 		if err := testForm.LoadRequest(c.Request(), ""); err != nil {
 			return NewBadRequestError("Failed to load the submitted data due to invalid formatting.", err)
 		}
 
+		// collect the changed fields before the dry submit
+		// because the test record is updated with the form data
+		changedFields := testForm.ChangedFields()
+
+		var fieldRulesErr error
+
 		createRuleFunc := func(q *dbx.SelectQuery) error {
 			if *collection.CreateRule == "" {
 				return nil // no create rule to resolve
@@ -204,17 +728,29 @@ func (api *recordApi) create(c echo.Context) error {
 				return fmt.Errorf("DrySubmit create rule failure: %w", err)
 			}
 			hasFullManageAccess = hasAuthManageAccess(txDao, foundRecord, requestInfo)
-			return nil
+			fieldRulesErr = checkFieldWriteRules(txDao, foundRecord, requestInfo, changedFields)
+			return fieldRulesErr
 		})
 
+		if fieldRulesErr != nil {
+			return fieldRulesErr
+		}
+
 		if testErr != nil {
 			return NewBadRequestError("Failed to create record.", testErr)
 		}
 	}
 
+	isDryRun := cast.ToBool(c.QueryParam(dryRunQueryParam))
+
+	// the dry run response is always with the full record
+	isMinimal := !isDryRun && preferMinimalReturn(c)
+
 	record := models.NewRecord(collection)
 	form := forms.NewRecordUpsert(api.app, record)
 	form.SetFullManageAccess(hasFullManageAccess)
+	form.SetDryRun(isDryRun)
+	form.SetRequestInfo(requestInfo)
 
 	// load request
 	if err := form.LoadRequest(c.Request(), ""); err != nil {
@@ -237,8 +773,16 @@ func (api *recordApi) create(c echo.Context) error {
 					return NewBadRequestError("Failed to create record.", err)
 				}
 
-				if err := EnrichRecord(e.HttpContext, api.app.Dao(), e.Record); err != nil && api.app.IsDebug() {
-					log.Println(err)
+				// the record is not serialized so there is no need to enrich it
+				if !isMinimal {
+					if err := EnrichRecord(e.HttpContext, api.app.Dao(), e.Record); err != nil {
+						api.app.Logger().Debug("Failed to enrich record", requestLogAttrs(e.HttpContext, "error", err)...)
+					}
+				}
+
+				// the record wasn't persisted so skip the after request hooks
+				if isDryRun {
+					return e.HttpContext.JSON(http.StatusOK, dryRunResponse{DryRun: true, Record: e.Record})
 				}
 
 				return api.app.OnRecordAfterCreateRequest().Trigger(event, func(e *core.RecordCreateEvent) error {
@@ -246,68 +790,294 @@ func (api *recordApi) create(c echo.Context) error {
 						return nil
 					}
 
-					return e.HttpContext.JSON(http.StatusOK, e.Record)
+					if isMinimal {
+						return recordMinimalResponse(e.HttpContext, e.Record, true)
+					}
+
+					// distinguish the created records from the upsert updated ones
+					status := http.StatusOK
+					if isUpsert {
+						status = http.StatusCreated
+					}
+
+					return e.HttpContext.JSON(status, e.Record)
 				})
 			})
 		}
 	})
 }
 
-func (api *recordApi) update(c echo.Context) error {
+// dryRunResponse defines the records create/update response structure
+// when the "?dryRun=true" query parameter is set.
+//
+// Record is the would-be created/updated record that wasn't persisted.
+type dryRunResponse struct {
+	DryRun bool           `json:"dryRun"`
+	Record *models.Record `json:"record"`
+}
+
+// bulkCreateResponse defines the records bulk create response structure.
+//
+// Items has the same length and order as the submitted data
+// (with nil for the records that failed to be created) and
+// Errors contains the failed items errors keyed by their index.
+type bulkCreateResponse struct {
+	Items  []*models.Record `json:"items"`
+	Errors map[string]any   `json:"errors"`
+}
+
+// bulkCreate creates multiple records from a JSON array body.
+//
+// By default all records are created in a single transaction and
+// the entire batch is rolled back on any item error.
+// With "?atomic=false" each record is persisted separately
+// and the failed items are reported in the response errors.
+//
+// Note that files cannot be uploaded with this handler and that
+// the submitted data is always loaded without manage access for non-admins.
+func (api *recordApi) bulkCreate(c echo.Context) error {
 	collection, _ := c.Get(ContextCollectionKey).(*models.Collection)
 	if collection == nil {
 		return NewNotFoundError("", "Missing collection context.")
 	}
 
-	recordId := c.PathParam("id")
-	if recordId == "" {
-		return NewNotFoundError("", nil)
-	}
-
 	requestInfo := RequestInfo(c)
 
-	if requestInfo.Admin == nil && collection.UpdateRule == nil {
+	if requestInfo.Admin == nil && collection.CreateRule == nil {
 		// only admins can access if the rule is nil
 		return NewForbiddenError("Only admins can perform this action.", nil)
 	}
 
-	// eager fetch the record so that the modifier field values are replaced
-	// and available when accessing requestInfo.Data using just the field name
-	if requestInfo.HasModifierDataKeys() {
-		record, err := api.app.Dao().FindRecordById(collection.Id, recordId)
-		if err != nil || record == nil {
-			return NewNotFoundError("", err)
-		}
-		requestInfo.Data = record.ReplaceModifers(requestInfo.Data)
+	atomic := true
+	if raw := c.QueryParam("atomic"); raw != "" {
+		atomic, _ = strconv.ParseBool(raw)
 	}
 
-	ruleFunc := func(q *dbx.SelectQuery) error {
-		if requestInfo.Admin == nil && collection.UpdateRule != nil && *collection.UpdateRule != "" {
-			resolver := resolvers.NewRecordFieldResolver(api.app.Dao(), collection, requestInfo, true)
-			expr, err := search.FilterData(*collection.UpdateRule).BuildExpr(resolver)
-			if err != nil {
-				return err
+	items := []map[string]any{}
+	if err := rest.CopyJsonBody(c.Request(), &items); err != nil {
+		return NewBadRequestError("Failed to load the submitted data due to invalid formatting.", err)
+	}
+
+	if len(items) == 0 {
+		return NewBadRequestError("Missing records data.", nil)
+	}
+
+	failed := map[string]any{}
+
+	// load the items data
+	records := make([]*models.Record, len(items))
+	itemForms := make([]*forms.RecordUpsert, len(items))
+	changedFields := make([][]string, len(items))
+	for i, data := range items {
+		record := models.NewRecord(collection)
+
+		form := forms.NewRecordUpsert(api.app, record)
+		form.SetFullManageAccess(requestInfo.Admin != nil)
+		form.SetRequestInfo(requestInfo)
+
+		if err := form.LoadData(data); err != nil {
+			failed[strconv.Itoa(i)] = err
+			continue
+		}
+
+		records[i] = record
+		itemForms[i] = form
+		changedFields[i] = form.ChangedFields()
+	}
+
+	if atomic && len(failed) > 0 {
+		return NewBadRequestError("Failed to load the submitted data.", failed)
+	}
+
+	event := new(core.RecordsBulkCreateEvent)
+	event.HttpContext = c
+	event.Collection = collection
+	event.Atomic = atomic
+	for _, record := range records {
+		if record != nil {
+			event.Records = append(event.Records, record)
+		}
+	}
+
+	return api.app.OnRecordBeforeBulkCreateRequest().Trigger(event, func(e *core.RecordsBulkCreateEvent) error {
+		createItem := func(txDao *daos.Dao, index int) error {
+			form := itemForms[index]
+			form.SetDao(txDao)
+
+			return form.Submit(func(next forms.InterceptorNextFunc[*models.Record]) forms.InterceptorNextFunc[*models.Record] {
+				return func(m *models.Record) error {
+					if err := next(m); err != nil {
+						return err
+					}
+
+					if requestInfo.Admin != nil {
+						return nil // no rules to check
+					}
+
+					// replace modifiers fields so that the resolved value is always
+					// available when accessing requestInfo.Data using just the field name
+					itemInfo := *requestInfo
+					itemInfo.Data = models.NewRecord(collection).ReplaceModifers(items[index])
+
+					if err := checkFieldWriteRules(txDao, m, &itemInfo, changedFields[index]); err != nil {
+						return err
+					}
+
+					if *collection.CreateRule == "" {
+						return nil // no create rule to check
+					}
+
+					// check the persisted record against the create rule
+					resolver := resolvers.NewRecordFieldResolver(txDao, collection, &itemInfo, true)
+					expr, err := search.FilterData(*collection.CreateRule).BuildExpr(resolver)
+					if err != nil {
+						return err
+					}
+
+					_, err = txDao.FindRecordById(collection.Id, m.Id, func(q *dbx.SelectQuery) error {
+						resolver.UpdateQuery(q)
+						q.AndWhere(expr)
+						return nil
+					})
+					if err != nil {
+						return fmt.Errorf("create rule failure: %w", err)
+					}
+
+					return nil
+				}
+			})
+		}
+
+		created := make([]*models.Record, 0, len(e.Records))
+		response := &bulkCreateResponse{
+			Items: make([]*models.Record, len(records)),
+		}
+
+		if atomic {
+			txErr := api.app.Dao().RunInTransaction(func(txDao *daos.Dao) error {
+				for i := range itemForms {
+					if err := createItem(txDao, i); err != nil {
+						failed[strconv.Itoa(i)] = err
+						return err
+					}
+				}
+				return nil
+			})
+			if txErr != nil {
+				return NewBadRequestError("Failed to create records.", failed)
+			}
+
+			copy(response.Items, records)
+			created = append(created, records...)
+		} else {
+			for i, form := range itemForms {
+				if form == nil {
+					continue // failed to load
+				}
+
+				txErr := api.app.Dao().RunInTransaction(func(txDao *daos.Dao) error {
+					return createItem(txDao, i)
+				})
+				if txErr != nil {
+					failed[strconv.Itoa(i)] = txErr
+					continue
+				}
+
+				response.Items[i] = records[i]
+				created = append(created, records[i])
 			}
-			resolver.UpdateQuery(q)
-			q.AndWhere(expr)
 		}
-		return nil
+
+		response.Errors = safeErrorsData(failed)
+
+		if err := EnrichRecords(e.HttpContext, api.app.Dao(), created); err != nil {
+			api.app.Logger().Debug("Failed to enrich records", requestLogAttrs(e.HttpContext, "error", err)...)
+		}
+
+		e.Records = created
+
+		return api.app.OnRecordAfterBulkCreateRequest().Trigger(e, func(e *core.RecordsBulkCreateEvent) error {
+			if e.HttpContext.Response().Committed {
+				return nil
+			}
+
+			return e.HttpContext.JSON(http.StatusOK, response)
+		})
+	})
+}
+
+func (api *recordApi) update(c echo.Context) error {
+	collection, _ := c.Get(ContextCollectionKey).(*models.Collection)
+	if collection == nil {
+		return NewNotFoundError("", "Missing collection context.")
+	}
+
+	recordId := c.PathParam("id")
+	if recordId == "" {
+		return NewNotFoundError("", nil)
+	}
+
+	return api.updateRecord(c, collection, recordId)
+}
+
+// updateRecord updates the collection record with the specified id
+// by applying the collection update rule and the update request hooks.
+func (api *recordApi) updateRecord(c echo.Context, collection *models.Collection, recordId string) error {
+	requestInfo := RequestInfo(c)
+
+	if requestInfo.Admin == nil && collection.UpdateRule == nil {
+		// only admins can access if the rule is nil
+		return NewForbiddenError("Only admins can perform this action.", nil)
+	}
+
+	// eager fetch the record so that the modifier field values are replaced
+	// and available when accessing requestInfo.Data using just the field name
+	if requestInfo.HasModifierDataKeys() {
+		record, err := api.app.Dao().FindRecordById(
+			collection.Id,
+			recordId,
+			daos.SoftDeletedFilter(collection, daos.SoftDeletedExclude),
+		)
+		if err != nil || record == nil {
+			return NewNotFoundError("", err)
+		}
+		requestInfo.Data = record.ReplaceModifers(requestInfo.Data)
 	}
 
 	// fetch record
-	record, fetchErr := api.app.Dao().FindRecordById(collection.Id, recordId, ruleFunc)
+	record, fetchErr := api.findUpdatableRecord(requestInfo, collection, recordId)
 	if fetchErr != nil || record == nil {
 		return NewNotFoundError("", fetchErr)
 	}
 
+	// opt-in optimistic concurrency control
+	version, err := api.recordVersionFromIfMatch(c, record)
+	if err != nil {
+		return err
+	}
+
+	isDryRun := cast.ToBool(c.QueryParam(dryRunQueryParam))
+
+	// the dry run response is always with the full record
+	isMinimal := !isDryRun && preferMinimalReturn(c)
+
 	form := forms.NewRecordUpsert(api.app, record)
 	form.SetFullManageAccess(requestInfo.Admin != nil || hasAuthManageAccess(api.app.Dao(), record, requestInfo))
+	form.SetDryRun(isDryRun)
 
 	// load request
 	if err := form.LoadRequest(c.Request(), ""); err != nil {
 		return NewBadRequestError("Failed to load the submitted data due to invalid formatting.", err)
 	}
 
+	if err := checkFieldWriteRules(api.app.Dao(), record, requestInfo, form.ChangedFields()); err != nil {
+		return err
+	}
+
+	if version != "" {
+		form.SetVersion(version)
+	}
+
 	event := new(core.RecordUpdateEvent)
 	event.HttpContext = c
 	event.Collection = collection
@@ -321,11 +1091,22 @@ func (api *recordApi) update(c echo.Context) error {
 
 			return api.app.OnRecordBeforeUpdateRequest().Trigger(event, func(e *core.RecordUpdateEvent) error {
 				if err := next(e.Record); err != nil {
+					if errors.Is(err, forms.ErrRecordVersionConflict) {
+						return NewConflictError("The record was modified by another request. Reload it and try again.", nil)
+					}
 					return NewBadRequestError("Failed to update record.", err)
 				}
 
-				if err := EnrichRecord(e.HttpContext, api.app.Dao(), e.Record); err != nil && api.app.IsDebug() {
-					log.Println(err)
+				// the record is not serialized so there is no need to enrich it
+				if !isMinimal {
+					if err := EnrichRecord(e.HttpContext, api.app.Dao(), e.Record); err != nil {
+						api.app.Logger().Debug("Failed to enrich record", requestLogAttrs(e.HttpContext, "error", err)...)
+					}
+				}
+
+				// the record wasn't persisted so skip the after request hooks
+				if isDryRun {
+					return e.HttpContext.JSON(http.StatusOK, dryRunResponse{DryRun: true, Record: e.Record})
 				}
 
 				return api.app.OnRecordAfterUpdateRequest().Trigger(event, func(e *core.RecordUpdateEvent) error {
@@ -333,6 +1114,10 @@ func (api *recordApi) update(c echo.Context) error {
 						return nil
 					}
 
+					if isMinimal {
+						return recordMinimalResponse(e.HttpContext, e.Record, false)
+					}
+
 					return e.HttpContext.JSON(http.StatusOK, e.Record)
 				})
 			})
@@ -340,6 +1125,100 @@ func (api *recordApi) update(c echo.Context) error {
 	})
 }
 
+// recordVersionFromIfMatch checks the If-Match request header entity tags
+// against the current record ETag (see [RecordETag]) and returns the
+// fetched record `updated` datetime as the expected form version, so that
+// a modification between the fetch and the save is also detected.
+//
+// Returns an empty string if the header is not set or it is "*".
+//
+// Note that the ETag must be obtained with the same requester and
+// fields and expand query parameters as the update request.
+func (api *recordApi) recordVersionFromIfMatch(c echo.Context, record *models.Record) (string, error) {
+	etags, err := parseIfMatch(c.Request().Header.Get("If-Match"))
+	if err != nil {
+		return "", NewBadRequestError("Invalid If-Match header.", err)
+	}
+
+	if len(etags) == 0 {
+		return "", nil
+	}
+
+	// compute the ETag from an expanded copy similar to the record view
+	current := record.CleanCopy()
+	if err := EnrichRecord(c, requestReadDao(api.app, c), current); err != nil {
+		api.app.Logger().Debug("Failed to enrich record", requestLogAttrs(c, "error", err)...)
+	}
+
+	etag := strings.TrimPrefix(RecordETag(c, current), "W/")
+
+	if !list.ExistInSlice(etag, etags) {
+		return "", NewConflictError("The record was modified by another request. Reload it and try again.", nil)
+	}
+
+	return record.Updated.String(), nil
+}
+
+// parseIfMatch parses the comma separated entity tags of an If-Match
+// header value (the weak "W/" prefix of the tags is trimmed).
+//
+// Returns nil if the header is empty or "*".
+func parseIfMatch(header string) ([]string, error) {
+	header = strings.TrimSpace(header)
+	if header == "" || header == "*" {
+		return nil, nil
+	}
+
+	parts := strings.Split(header, ",")
+
+	result := make([]string, 0, len(parts))
+	for _, tag := range parts {
+		tag = strings.TrimPrefix(strings.TrimSpace(tag), "W/")
+
+		if len(tag) < 2 || tag[0] != '"' || tag[len(tag)-1] != '"' || strings.Contains(tag[1:len(tag)-1], `"`) {
+			return nil, fmt.Errorf("invalid entity tag %q", tag)
+		}
+
+		result = append(result, tag)
+	}
+
+	return result, nil
+}
+
+// findUpdatableRecord returns the collection record with the specified id
+// only if it satisfies the collection update rule and it is not soft-deleted.
+func (api *recordApi) findUpdatableRecord(
+	requestInfo *models.RequestInfo,
+	collection *models.Collection,
+	recordId string,
+) (*models.Record, error) {
+	ruleFunc := func(q *dbx.SelectQuery) error {
+		if requestInfo.Admin == nil && collection.UpdateRule != nil && *collection.UpdateRule != "" {
+			resolver := resolvers.NewRecordFieldResolver(api.app.Dao(), collection, requestInfo, true)
+			expr, err := search.FilterData(*collection.UpdateRule).BuildExpr(resolver)
+			if err != nil {
+				return err
+			}
+			resolver.UpdateQuery(q)
+			q.AndWhere(expr)
+		}
+		return nil
+	}
+
+	return api.app.Dao().FindRecordById(
+		collection.Id,
+		recordId,
+		daos.SoftDeletedFilter(collection, daos.SoftDeletedExclude),
+		ruleFunc,
+	)
+}
+
+// delete deletes a single collection record.
+//
+// For collections with soft-delete enabled the record is only marked as
+// deleted (see [daos.Dao.SoftDeleteRecord]), unless an admin explicitly
+// targets an already soft-deleted record with "?deleted=only|include",
+// in which case the record is permanently deleted.
 func (api *recordApi) delete(c echo.Context) error {
 	collection, _ := c.Get(ContextCollectionKey).(*models.Collection)
 	if collection == nil {
@@ -358,6 +1237,11 @@ func (api *recordApi) delete(c echo.Context) error {
 		return NewForbiddenError("Only admins can perform this action.", nil)
 	}
 
+	deletedMode, err := softDeletedFilterMode(c, requestInfo)
+	if err != nil {
+		return err
+	}
+
 	ruleFunc := func(q *dbx.SelectQuery) error {
 		if requestInfo.Admin == nil && collection.DeleteRule != nil && *collection.DeleteRule != "" {
 			resolver := resolvers.NewRecordFieldResolver(api.app.Dao(), collection, requestInfo, true)
@@ -371,11 +1255,20 @@ func (api *recordApi) delete(c echo.Context) error {
 		return nil
 	}
 
-	record, fetchErr := api.app.Dao().FindRecordById(collection.Id, recordId, ruleFunc)
+	record, fetchErr := api.app.Dao().FindRecordById(
+		collection.Id,
+		recordId,
+		daos.SoftDeletedFilter(collection, deletedMode),
+		ruleFunc,
+	)
 	if fetchErr != nil || record == nil {
 		return NewNotFoundError("", fetchErr)
 	}
 
+	if collection.HasSoftDelete() && !record.IsSoftDeleted() {
+		return api.softDelete(c, collection, record)
+	}
+
 	event := new(core.RecordDeleteEvent)
 	event.HttpContext = c
 	event.Collection = collection
@@ -397,6 +1290,347 @@ func (api *recordApi) delete(c echo.Context) error {
 	})
 }
 
+// bulkDeleteBatchSize is the max number of the bulk delete
+// records that are loaded in memory at once.
+const bulkDeleteBatchSize = 500
+
+// bulkDeleteResponse defines the records bulk delete response.
+type bulkDeleteResponse struct {
+	Deleted int `json:"deleted"`
+}
+
+// bulkDelete deletes in a single transaction all collection records
+// matching the "filter" query param and the collection delete rule.
+//
+// The delete (or soft-delete) request hooks are triggered for each record
+// and the after hooks are triggered once the transaction is committed.
+//
+// To prevent accidental full collection deletes, the "filter" query param
+// is required unless "all=true" is explicitly set.
+func (api *recordApi) bulkDelete(c echo.Context) error {
+	collection, _ := c.Get(ContextCollectionKey).(*models.Collection)
+	if collection == nil {
+		return NewNotFoundError("", "Missing collection context.")
+	}
+
+	// forbid users and guests to query special filter fields
+	if err := api.checkForForbiddenQueryFields(c); err != nil {
+		return err
+	}
+
+	requestInfo := RequestInfo(c)
+
+	if requestInfo.Admin == nil && collection.DeleteRule == nil {
+		// only admins can access if the rule is nil
+		return NewForbiddenError("Only admins can perform this action.", nil)
+	}
+
+	filter := c.QueryParam(search.FilterQueryParam)
+	if filter == "" && !cast.ToBool(c.QueryParam(allQueryParam)) {
+		return NewBadRequestError(`Missing required "filter" query param (use "all=true" to delete all records).`, nil)
+	}
+
+	deletedMode, err := softDeletedFilterMode(c, requestInfo)
+	if err != nil {
+		return err
+	}
+
+	deleteEvents := []*core.RecordDeleteEvent{}
+	softDeleteEvents := []*core.RecordSoftDeleteEvent{}
+
+	txErr := api.app.Dao().RunInTransaction(func(txDao *daos.Dao) error {
+		filterResolver := resolvers.NewRecordFieldResolver(
+			txDao,
+			collection,
+			requestInfo,
+			// hidden fields are searchable only by admins
+			requestInfo.Admin != nil,
+		)
+
+		var filterExpr dbx.Expression
+		if filter != "" {
+			filterExpr, err = search.FilterData(filter).BuildExpr(filterResolver)
+			if err != nil {
+				return NewBadRequestError("Invalid filter parameters.", err)
+			}
+		}
+
+		idColumn := txDao.DB().QuoteSimpleColumnName(collection.Name) + ".id"
+
+		var lastId string
+
+		for {
+			query := txDao.RecordQuery(collection).
+				AndWhere(dbx.NewExp(idColumn+" > {:bulkDeleteLastId}", dbx.Params{"bulkDeleteLastId": lastId})).
+				OrderBy(idColumn + " ASC").
+				Limit(bulkDeleteBatchSize)
+
+			if err := daos.SoftDeletedFilter(collection, deletedMode)(query); err != nil {
+				return err
+			}
+
+			if filterExpr != nil {
+				query.AndWhere(filterExpr)
+			}
+
+			if err := filterResolver.UpdateQuery(query); err != nil {
+				return err
+			}
+
+			records := []*models.Record{}
+			if err := query.All(&records); err != nil {
+				return err
+			}
+
+			allowed, err := api.bulkDeleteAllowedRecords(txDao, collection, requestInfo, records)
+			if err != nil {
+				return err
+			}
+
+			batchDeleteEvents, batchSoftDeleteEvents, err := api.bulkDeleteRecords(c, txDao, collection, allowed)
+			if err != nil {
+				return err
+			}
+			deleteEvents = append(deleteEvents, batchDeleteEvents...)
+			softDeleteEvents = append(softDeleteEvents, batchSoftDeleteEvents...)
+
+			if len(records) < bulkDeleteBatchSize {
+				return nil
+			}
+
+			lastId = records[len(records)-1].Id
+		}
+	})
+	if txErr != nil {
+		var apiErr *ApiError
+		if errors.As(txErr, &apiErr) {
+			return apiErr
+		}
+		return NewBadRequestError("Failed to delete records. Make sure that the records are not part of a required relation reference.", txErr)
+	}
+
+	// the records are already deleted so the after hooks errors are only logged
+	for _, event := range softDeleteEvents {
+		if err := api.app.OnRecordAfterSoftDeleteRequest().Trigger(event); err != nil {
+			api.app.Logger().Debug("OnRecordAfterSoftDeleteRequest failure", requestLogAttrs(c, "recordId", event.Record.Id, "error", err)...)
+		}
+	}
+
+	for _, event := range deleteEvents {
+		if err := api.app.OnRecordAfterDeleteRequest().Trigger(event); err != nil {
+			api.app.Logger().Debug("OnRecordAfterDeleteRequest failure", requestLogAttrs(c, "recordId", event.Record.Id, "error", err)...)
+		}
+	}
+
+	if c.Response().Committed {
+		return nil
+	}
+
+	return c.JSON(http.StatusOK, bulkDeleteResponse{Deleted: len(deleteEvents) + len(softDeleteEvents)})
+}
+
+// bulkDeleteAllowedRecords returns the subset of the provided bulk delete
+// records that satisfy the collection delete rule.
+//
+// Similar to the single record delete, the rule is resolved with its own
+// resolver in a separate query so that it can reference hidden fields.
+func (api *recordApi) bulkDeleteAllowedRecords(
+	txDao *daos.Dao,
+	collection *models.Collection,
+	requestInfo *models.RequestInfo,
+	records []*models.Record,
+) ([]*models.Record, error) {
+	if len(records) == 0 || requestInfo.Admin != nil || *collection.DeleteRule == "" {
+		return records, nil
+	}
+
+	resolver := resolvers.NewRecordFieldResolver(txDao, collection, requestInfo, true)
+	expr, err := search.FilterData(*collection.DeleteRule).BuildExpr(resolver)
+	if err != nil {
+		return nil, NewBadRequestError("Invalid filter parameters.", err)
+	}
+
+	ids := make([]any, len(records))
+	for i, record := range records {
+		ids[i] = record.Id
+	}
+
+	idColumn := txDao.DB().QuoteSimpleColumnName(collection.Name) + ".id"
+
+	query := txDao.RecordQuery(collection).
+		Select(idColumn).
+		AndWhere(dbx.In(idColumn, ids...)).
+		AndWhere(expr)
+
+	if err := resolver.UpdateQuery(query); err != nil {
+		return nil, err
+	}
+
+	allowedIds := []string{}
+	if err := query.Column(&allowedIds); err != nil {
+		return nil, err
+	}
+
+	allowed := make(map[string]struct{}, len(allowedIds))
+	for _, id := range allowedIds {
+		allowed[id] = struct{}{}
+	}
+
+	result := make([]*models.Record, 0, len(allowedIds))
+	for _, record := range records {
+		if _, ok := allowed[record.Id]; ok {
+			result = append(result, record)
+		}
+	}
+
+	return result, nil
+}
+
+// bulkDeleteRecords deletes (or soft-deletes) a single batch of bulk delete
+// records within the txDao transaction triggering their before request hooks.
+//
+// It returns the events of the deleted and soft-deleted records.
+func (api *recordApi) bulkDeleteRecords(
+	c echo.Context,
+	txDao *daos.Dao,
+	collection *models.Collection,
+	records []*models.Record,
+) ([]*core.RecordDeleteEvent, []*core.RecordSoftDeleteEvent, error) {
+	deleteEvents := []*core.RecordDeleteEvent{}
+	softDeleteEvents := []*core.RecordSoftDeleteEvent{}
+
+	for _, record := range records {
+		if collection.HasSoftDelete() && !record.IsSoftDeleted() {
+			event := new(core.RecordSoftDeleteEvent)
+			event.HttpContext = c
+			event.Collection = collection
+			event.Record = record
+
+			err := api.app.OnRecordBeforeSoftDeleteRequest().Trigger(event, func(e *core.RecordSoftDeleteEvent) error {
+				return txDao.SoftDeleteRecord(e.Record)
+			})
+			if err != nil {
+				return nil, nil, err
+			}
+
+			softDeleteEvents = append(softDeleteEvents, event)
+			continue
+		}
+
+		event := new(core.RecordDeleteEvent)
+		event.HttpContext = c
+		event.Collection = collection
+		event.Record = record
+
+		err := api.app.OnRecordBeforeDeleteRequest().Trigger(event, func(e *core.RecordDeleteEvent) error {
+			return txDao.DeleteRecord(e.Record)
+		})
+		if err != nil {
+			return nil, nil, err
+		}
+
+		deleteEvents = append(deleteEvents, event)
+	}
+
+	return deleteEvents, softDeleteEvents, nil
+}
+
+// softDelete marks the provided record as deleted
+// triggering the soft-delete request hooks.
+func (api *recordApi) softDelete(c echo.Context, collection *models.Collection, record *models.Record) error {
+	event := new(core.RecordSoftDeleteEvent)
+	event.HttpContext = c
+	event.Collection = collection
+	event.Record = record
+
+	return api.app.OnRecordBeforeSoftDeleteRequest().Trigger(event, func(e *core.RecordSoftDeleteEvent) error {
+		if err := api.app.Dao().SoftDeleteRecord(e.Record); err != nil {
+			return NewBadRequestError("Failed to delete record.", err)
+		}
+
+		return api.app.OnRecordAfterSoftDeleteRequest().Trigger(event, func(e *core.RecordSoftDeleteEvent) error {
+			if e.HttpContext.Response().Committed {
+				return nil
+			}
+
+			return e.HttpContext.NoContent(http.StatusNoContent)
+		})
+	})
+}
+
+// restore restores a single soft-deleted collection record (admins only).
+//
+// Note that the restore may fail if a unique value of the record was reused
+// in the meantime (eg. when the collection has a partial unique index
+// that excludes the soft-deleted records).
+func (api *recordApi) restore(c echo.Context) error {
+	collection, _ := c.Get(ContextCollectionKey).(*models.Collection)
+	if collection == nil {
+		return NewNotFoundError("", "Missing collection context.")
+	}
+
+	if !collection.HasSoftDelete() {
+		return NewBadRequestError("The collection doesn't have soft-delete enabled.", nil)
+	}
+
+	recordId := c.PathParam("id")
+	if recordId == "" {
+		return NewNotFoundError("", nil)
+	}
+
+	record, fetchErr := api.app.Dao().FindRecordById(
+		collection.Id,
+		recordId,
+		daos.SoftDeletedFilter(collection, daos.SoftDeletedOnly),
+	)
+	if fetchErr != nil || record == nil {
+		return NewNotFoundError("", fetchErr)
+	}
+
+	event := new(core.RecordRestoreEvent)
+	event.HttpContext = c
+	event.Collection = collection
+	event.Record = record
+
+	return api.app.OnRecordBeforeRestoreRequest().Trigger(event, func(e *core.RecordRestoreEvent) error {
+		if err := api.app.Dao().RestoreRecord(e.Record); err != nil {
+			return NewBadRequestError("Failed to restore record. Make sure that its unique values are not already in use.", err)
+		}
+
+		if err := EnrichRecord(e.HttpContext, api.app.Dao(), e.Record); err != nil {
+			api.app.Logger().Debug("Failed to enrich record", requestLogAttrs(e.HttpContext, "error", err)...)
+		}
+
+		return api.app.OnRecordAfterRestoreRequest().Trigger(event, func(e *core.RecordRestoreEvent) error {
+			if e.HttpContext.Response().Committed {
+				return nil
+			}
+
+			return e.HttpContext.JSON(http.StatusOK, e.Record)
+		})
+	})
+}
+
+// softDeletedFilterMode returns the soft-deleted records filter mode
+// from the "deleted" query param.
+//
+// Only admins are allowed to include the soft-deleted records.
+func softDeletedFilterMode(c echo.Context, requestInfo *models.RequestInfo) (string, error) {
+	mode := c.QueryParam(deletedQueryParam)
+
+	switch mode {
+	case daos.SoftDeletedExclude:
+		return mode, nil
+	case daos.SoftDeletedOnly, daos.SoftDeletedInclude:
+		if requestInfo.Admin == nil {
+			return "", NewForbiddenError("Only admins can access the soft-deleted records.", nil)
+		}
+		return mode, nil
+	default:
+		return "", NewBadRequestError(`Invalid "deleted" query param - expected "only" or "include".`, nil)
+	}
+}
+
 func (api *recordApi) checkForForbiddenQueryFields(c echo.Context) error {
 	admin, _ := c.Get(ContextAdminKey).(*models.Admin)
 	if admin != nil {
@@ -414,3 +1648,83 @@ func (api *recordApi) checkForForbiddenQueryFields(c echo.Context) error {
 
 	return nil
 }
+
+// csvExportColumns returns the CSV export header columns
+// based on the collection fields or the provided `fields` param.
+func csvExportColumns(collection *models.Collection, fieldsParam string) []string {
+	columns := []string{}
+
+	if fieldsParam != "" {
+		for _, field := range strings.Split(fieldsParam, ",") {
+			field = strings.TrimSpace(field)
+			if field != "" && field != "*" {
+				columns = append(columns, field)
+			}
+		}
+		if len(columns) > 0 {
+			return columns
+		}
+	}
+
+	columns = append(columns, schema.FieldNameId)
+
+	if !collection.IsView() {
+		columns = append(columns, schema.FieldNameCreated, schema.FieldNameUpdated)
+	}
+
+	if collection.IsAuth() {
+		columns = append(
+			columns,
+			schema.FieldNameUsername,
+			schema.FieldNameEmail,
+			schema.FieldNameEmailVisibility,
+			schema.FieldNameVerified,
+		)
+	}
+
+	for _, field := range collection.Schema.Fields() {
+		columns = append(columns, field.Name)
+	}
+
+	return columns
+}
+
+// csvValue normalizes a single record field value into a CSV cell
+// (multiple values like relation or file fields are comma joined).
+func csvValue(value any) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case []string:
+		return strings.Join(v, ",")
+	case fmt.Stringer:
+		return v.String()
+	default:
+		return cast.ToString(v)
+	}
+}
+
+// applySearchParam applies the "search" query param full-text
+// search filter (if any) to the provided records query.
+func applySearchParam(
+	c echo.Context,
+	dao *daos.Dao,
+	collection *models.Collection,
+	query *dbx.SelectQuery,
+	orderByRank bool,
+) error {
+	text := c.QueryParam(searchQueryParam)
+	if text == "" {
+		return nil
+	}
+
+	if len(collection.SearchFields()) == 0 {
+		return NewBadRequestError("The collection doesn't have search fields.", nil)
+	}
+
+	if err := dao.RecordsSearchFilter(collection, text, orderByRank)(query); err != nil {
+		return NewBadRequestError("Invalid search parameter.", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,125 @@
+package apis
+
+import (
+	"net/http"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/pocketbase/dbx"
+	"github.com/unkod/space/daos"
+	"github.com/unkod/space/models"
+	"github.com/unkod/space/resolvers"
+	"github.com/unkod/space/tools/search"
+)
+
+// applyFieldReadRules hides from the export the record fields whose
+// [schema.SchemaField.ReadRule] is not satisfied by the current request.
+//
+// Note: Expects all records to be from the same collection!
+func applyFieldReadRules(
+	dao *daos.Dao,
+	records []*models.Record,
+	requestInfo *models.RequestInfo,
+) error {
+	if len(records) == 0 || (requestInfo != nil && requestInfo.Admin != nil) {
+		return nil // nothing to check or admin
+	}
+
+	collection := records[0].Collection()
+
+	mappedRecords := make(map[string]*models.Record, len(records))
+	recordIds := make([]any, len(records))
+	for i, rec := range records {
+		mappedRecords[rec.Id] = rec
+		recordIds[i] = rec.Id
+	}
+
+	for _, field := range collection.Schema.Fields() {
+		if field.ReadRule == "" {
+			continue
+		}
+
+		// fetch the ids of the records that satisfy the field rule
+		allowedIds := []string{}
+
+		query := dao.RecordQuery(collection).
+			Select(dao.DB().QuoteSimpleColumnName(collection.Name) + ".id").
+			AndWhere(dbx.In(dao.DB().QuoteSimpleColumnName(collection.Name)+".id", recordIds...))
+
+		resolver := resolvers.NewRecordFieldResolver(dao, collection, requestInfo, true)
+		expr, err := search.FilterData(field.ReadRule).BuildExpr(resolver)
+		if err != nil {
+			return err
+		}
+		resolver.UpdateQuery(query)
+		query.AndWhere(expr)
+
+		if err := query.Column(&allowedIds); err != nil {
+			return err
+		}
+
+		allowed := make(map[string]struct{}, len(allowedIds))
+		for _, id := range allowedIds {
+			allowed[id] = struct{}{}
+		}
+
+		for id, rec := range mappedRecords {
+			if _, ok := allowed[id]; !ok {
+				rec.HideFields(field.Name)
+			}
+		}
+	}
+
+	return nil
+}
+
+// checkFieldWriteRules returns a validation error for each of the
+// changed record fields whose [schema.SchemaField.WriteRule] is not
+// satisfied by the current request.
+//
+// For existing records the rules are evaluated against the stored
+// record state, otherwise the record is expected to be already persisted
+// (eg. within the create transaction).
+func checkFieldWriteRules(
+	dao *daos.Dao,
+	record *models.Record,
+	requestInfo *models.RequestInfo,
+	changedFields []string,
+) error {
+	if requestInfo != nil && requestInfo.Admin != nil {
+		return nil // admins can change everything
+	}
+
+	collection := record.Collection()
+
+	errs := validation.Errors{}
+
+	for _, name := range changedFields {
+		field := collection.Schema.GetFieldByName(name)
+		if field == nil || field.WriteRule == "" {
+			continue
+		}
+
+		rule := field.WriteRule
+
+		ruleFunc := func(q *dbx.SelectQuery) error {
+			resolver := resolvers.NewRecordFieldResolver(dao, collection, requestInfo, true)
+			expr, err := search.FilterData(rule).BuildExpr(resolver)
+			if err != nil {
+				return err
+			}
+			resolver.UpdateQuery(q)
+			q.AndWhere(expr)
+			return nil
+		}
+
+		if _, err := dao.FindRecordById(collection.Id, record.Id, ruleFunc); err != nil {
+			errs[name] = validation.NewError("validation_field_write_forbidden", "You are not allowed to change this field.")
+		}
+	}
+
+	if len(errs) > 0 {
+		return NewApiError(http.StatusBadRequest, "Failed to save the record.", errs)
+	}
+
+	return nil
+}
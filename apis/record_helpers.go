@@ -1,22 +1,96 @@
 package apis
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
 	"fmt"
+	"io"
 	"log"
 	"net/http"
+	"net/url"
+	"sort"
 	"strings"
+	"time"
 
+	validation "github.com/go-ozzo/ozzo-validation/v4"
 	"github.com/labstack/echo/v5"
 	"github.com/pocketbase/dbx"
 	"github.com/unkod/space/core"
 	"github.com/unkod/space/daos"
 	"github.com/unkod/space/models"
+	"github.com/unkod/space/models/schema"
 	"github.com/unkod/space/resolvers"
 	"github.com/unkod/space/tokens"
 	"github.com/unkod/space/tools/rest"
 	"github.com/unkod/space/tools/search"
+	"github.com/unkod/space/tools/security"
+	"github.com/unkod/space/tools/store"
 )
 
+// authRotationGracePeriod is the duration for which the previous
+// auth record token key remains valid after a token rotation.
+const authRotationGracePeriod = 30 * time.Second
+
+// rotatedTokenKey stores the previous token key of a rotated auth record.
+type rotatedTokenKey struct {
+	Key       string
+	ExpiresAt time.Time
+}
+
+func authRotationCacheKey(collectionId string, recordId string) string {
+	return "@authTokenRotation_" + collectionId + "_" + recordId
+}
+
+// findAuthRecordByRotatedToken returns the auth record associated with
+// a token signed with the record's previous (aka. rotated) token key
+// if it is still within the rotation grace period.
+func findAuthRecordByRotatedToken(app core.App, token string, baseTokenKey string) (*models.Record, error) {
+	claims, err := security.ParseUnverifiedJWT(token)
+	if err != nil {
+		return nil, err
+	}
+
+	id, _ := claims["id"].(string)
+	collectionId, _ := claims["collectionId"].(string)
+	if id == "" || collectionId == "" {
+		return nil, errors.New("missing or invalid token claims")
+	}
+
+	cacheKey := authRotationCacheKey(collectionId, id)
+
+	rotated, _ := app.Cache().Get(cacheKey).(*rotatedTokenKey)
+	if rotated == nil {
+		return nil, errors.New("missing rotated token key")
+	}
+
+	if time.Now().After(rotated.ExpiresAt) {
+		app.Cache().Remove(cacheKey)
+		return nil, errors.New("the rotated token key has expired")
+	}
+
+	verifiedClaims, err := security.ParseJWT(token, rotated.Key+baseTokenKey)
+	if err != nil {
+		return nil, err
+	}
+
+	// similar to the regular auth tokens lookup, accept only auth record tokens
+	if verifiedClaims["type"] != tokens.TypeAuthRecord {
+		return nil, errors.New("invalid token type")
+	}
+
+	record, err := app.Dao().FindRecordById(collectionId, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if !record.Collection().IsAuth() || record.IsSoftDeleted() {
+		return nil, errors.New("invalid auth record")
+	}
+
+	return record, nil
+}
+
 const ContextRequestInfoKey = "requestInfo"
 
 // Deprecated: Use RequestInfo instead.
@@ -25,6 +99,16 @@ func RequestData(c echo.Context) *models.RequestInfo {
 	return RequestInfo(c)
 }
 
+// requestReadDao returns the app read Dao (see [daos.Dao.ReadDao])
+// bound to the request context, aka. its queries are cancelled together
+// with the request (eg. on client disconnect or exceeded request timeout).
+//
+// It must be used only for read-only operations since with a configured
+// read replica all of its queries are executed on the replica.
+func requestReadDao(app core.App, c echo.Context) *daos.Dao {
+	return app.Dao().ReadDao().WithContext(c.Request().Context())
+}
+
 // RequestInfo exports cached common request data fields
 // (query, body, logged auth state, etc.) from the provided context.
 func RequestInfo(c echo.Context) *models.RequestInfo {
@@ -63,6 +147,39 @@ func RequestInfo(c echo.Context) *models.RequestInfo {
 	return result
 }
 
+// recordMFAChallengeResponse writes a 401 response with the MFA challenge id
+// that is required to complete the authentication with the "auth-with-mfa" endpoint.
+func recordMFAChallengeResponse(app core.App, c echo.Context, authRecord *models.Record) error {
+	if err := checkOnlyVerified(authRecord); err != nil {
+		return err
+	}
+
+	mfaId, err := tokens.NewRecordMFAToken(app, authRecord)
+	if err != nil {
+		return NewBadRequestError("Failed to create MFA challenge.", err)
+	}
+
+	return c.JSON(http.StatusUnauthorized, map[string]any{
+		"mfaId": mfaId,
+	})
+}
+
+// checkOnlyVerified returns a 403 error if the auth record collection
+// has the "onlyVerified" option enabled and the record is not verified.
+//
+// The error data contains the "validation_unverified_record" code so that
+// the clients could detect it and prompt for a new verification email.
+func checkOnlyVerified(authRecord *models.Record) error {
+	collection := authRecord.Collection()
+	if collection == nil || !collection.IsAuth() || !collection.AuthOptions().OnlyVerified || authRecord.Verified() {
+		return nil
+	}
+
+	return NewForbiddenError("The auth record must be verified before authenticating.", validation.Errors{
+		schema.FieldNameVerified: validation.NewError("validation_unverified_record", "The auth record is not verified."),
+	})
+}
+
 // RecordAuthResponse writes standardised json record auth response
 // into the specified request context.
 func RecordAuthResponse(
@@ -72,11 +189,28 @@ func RecordAuthResponse(
 	meta any,
 	finalizers ...func(token string) error,
 ) error {
+	if err := checkOnlyVerified(authRecord); err != nil {
+		return err
+	}
+
 	token, tokenErr := tokens.NewRecordAuthToken(app, authRecord)
 	if tokenErr != nil {
 		return NewBadRequestError("Failed to create auth token.", tokenErr)
 	}
 
+	return recordAuthResponseWithToken(app, c, authRecord, token, meta, finalizers...)
+}
+
+// recordAuthResponseWithToken writes standardised json record auth
+// response with an already generated auth token.
+func recordAuthResponseWithToken(
+	app core.App,
+	c echo.Context,
+	authRecord *models.Record,
+	token string,
+	meta any,
+	finalizers ...func(token string) error,
+) error {
 	event := new(core.RecordAuthEvent)
 	event.HttpContext = c
 	event.Collection = authRecord.Collection()
@@ -102,10 +236,10 @@ func RecordAuthResponse(
 			failed := app.Dao().ExpandRecord(
 				e.Record,
 				expands,
-				expandFetch(app.Dao(), &requestInfo),
+				expandFetch(app.Dao(), &requestInfo, requestCache(e.HttpContext, app.Dao())),
 			)
-			if len(failed) > 0 && app.IsDebug() {
-				log.Println("Failed to expand relations: ", failed)
+			if len(failed) > 0 {
+				app.Logger().Debug("Failed to expand relations", requestLogAttrs(e.HttpContext, "error", fmt.Sprint(failed))...)
 			}
 		}
 
@@ -140,13 +274,21 @@ func EnrichRecord(c echo.Context, dao *daos.Dao, record *models.Record, defaultE
 //   - expands relations (if defaultExpands and/or ?expand query param is set)
 //   - ensures that the emails of the auth records and their expanded auth relations
 //     are visibe only for the current logged admin, record owner or record with manage access
+//   - hides the record fields (including of the expanded relations) whose read rule
+//     is not satisfied by the current request
 func EnrichRecords(c echo.Context, dao *daos.Dao, records []*models.Record, defaultExpands ...string) error {
 	requestInfo := RequestInfo(c)
 
-	if err := autoIgnoreAuthRecordsEmailVisibility(dao, records, requestInfo); err != nil {
+	cache := requestCache(c, dao)
+
+	if err := autoIgnoreAuthRecordsEmailVisibility(dao, records, requestInfo, cache); err != nil {
 		return fmt.Errorf("failed to resolve email visibility: %w", err)
 	}
 
+	if err := applyFieldReadRules(dao, records, requestInfo); err != nil {
+		return fmt.Errorf("failed to resolve the fields read rules: %w", err)
+	}
+
 	expands := defaultExpands
 	if param := c.QueryParam(expandQueryParam); param != "" {
 		expands = append(expands, strings.Split(param, ",")...)
@@ -155,7 +297,7 @@ func EnrichRecords(c echo.Context, dao *daos.Dao, records []*models.Record, defa
 		return nil // nothing to expand
 	}
 
-	errs := dao.ExpandRecords(records, expands, expandFetch(dao, requestInfo))
+	errs := dao.ExpandRecords(records, expands, expandFetch(dao, requestInfo, cache))
 	if len(errs) > 0 {
 		return fmt.Errorf("failed to expand: %v", errs)
 	}
@@ -163,13 +305,72 @@ func EnrichRecords(c echo.Context, dao *daos.Dao, records []*models.Record, defa
 	return nil
 }
 
+// checkExpandDepth returns a 400 error if any of the request
+// expand paths exceeds the max allowed dao expand depth.
+func checkExpandDepth(dao *daos.Dao, c echo.Context) error {
+	param := c.QueryParam(expandQueryParam)
+	if param == "" {
+		return nil
+	}
+
+	for _, expand := range strings.Split(param, ",") {
+		if err := dao.CheckExpandDepth(strings.TrimSpace(expand)); err != nil {
+			return NewBadRequestError(err.Error(), nil)
+		}
+	}
+
+	return nil
+}
+
+// triggerRecordsBeforeSend triggers the OnRecordBeforeSendRequest hook
+// for each of the provided (already enriched) response records.
+func triggerRecordsBeforeSend(app core.App, c echo.Context, records []*models.Record) error {
+	for _, record := range records {
+		event := new(core.RecordBeforeSendEvent)
+		event.HttpContext = c
+		event.Collection = record.Collection()
+		event.Record = record
+
+		if err := app.OnRecordBeforeSendRequest().Trigger(event); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
 // expandFetch is the records fetch function that is used to expand related records.
+//
+// The soft-deleted related records are never expanded.
+//
+// If cache is set, the fetched records are memoized and copies of them
+// are returned on each subsequent fetch of the same related records.
 func expandFetch(
 	dao *daos.Dao,
 	requestInfo *models.RequestInfo,
+	cache *store.Store[any],
 ) daos.ExpandFetchFunc {
 	return func(relCollection *models.Collection, relIds []string) ([]*models.Record, error) {
-		records, err := dao.FindRecordsByIds(relCollection.Id, relIds, func(q *dbx.SelectQuery) error {
+		if relCollection.IsApiDisabled() {
+			return nil, fmt.Errorf("the collection %q API is disabled", relCollection.Name)
+		}
+
+		var cacheKey string
+		if cache != nil {
+			cacheKey = requestCacheKey("expand", relCollection, requestInfo, relIds)
+			if cached, ok := cache.Get(cacheKey).([]*models.Record); ok {
+				records := copyRecords(cached)
+				autoIgnoreAuthRecordsEmailVisibility(dao, records, requestInfo, cache)
+				if err := applyFieldReadRules(dao, records, requestInfo); err != nil {
+					return nil, err
+				}
+				return records, nil
+			}
+		}
+
+		softDeletedFilter := daos.SoftDeletedFilter(relCollection, daos.SoftDeletedExclude)
+
+		records, err := dao.FindRecordsByIds(relCollection.Id, relIds, softDeletedFilter, func(q *dbx.SelectQuery) error {
 			if requestInfo.Admin != nil {
 				return nil // admins can access everything
 			}
@@ -191,22 +392,43 @@ func expandFetch(
 			return nil
 		})
 
+		// the expand data is set directly on the returned records
+		// so cache a copy of them to keep the cached records unchanged
+		if err == nil && cache != nil {
+			cache.SetIfLessThanLimit(cacheKey, copyRecords(records), requestCacheLimit)
+		}
+
 		if err == nil && len(records) > 0 {
-			autoIgnoreAuthRecordsEmailVisibility(dao, records, requestInfo)
+			autoIgnoreAuthRecordsEmailVisibility(dao, records, requestInfo, cache)
+			err = applyFieldReadRules(dao, records, requestInfo)
 		}
 
 		return records, err
 	}
 }
 
+// copyRecords returns new clean copies of the provided records.
+func copyRecords(records []*models.Record) []*models.Record {
+	result := make([]*models.Record, len(records))
+
+	for i, record := range records {
+		result[i] = record.CleanCopy()
+	}
+
+	return result
+}
+
 // autoIgnoreAuthRecordsEmailVisibility ignores the email visibility check for
 // the provided record if the current auth model is admin, owner or a "manager".
 //
+// If cache is set, the managed records lookup result is memoized.
+//
 // Note: Expects all records to be from the same auth collection!
 func autoIgnoreAuthRecordsEmailVisibility(
 	dao *daos.Dao,
 	records []*models.Record,
 	requestInfo *models.RequestInfo,
+	cache *store.Store[any],
 ) error {
 	if len(records) == 0 || !records[0].Collection().IsAuth() {
 		return nil // nothing to check
@@ -223,9 +445,11 @@ func autoIgnoreAuthRecordsEmailVisibility(
 
 	mappedRecords := make(map[string]*models.Record, len(records))
 	recordIds := make([]any, len(records))
+	plainIds := make([]string, len(records))
 	for i, rec := range records {
 		mappedRecords[rec.Id] = rec
 		recordIds[i] = rec.Id
+		plainIds[i] = rec.Id
 	}
 
 	if requestInfo != nil && requestInfo.AuthRecord != nil && mappedRecords[requestInfo.AuthRecord.Id] != nil {
@@ -237,6 +461,19 @@ func autoIgnoreAuthRecordsEmailVisibility(
 		return nil // no manage rule to check
 	}
 
+	var cacheKey string
+	if cache != nil {
+		cacheKey = requestCacheKey("manage", collection, requestInfo, plainIds)
+		if cached, ok := cache.Get(cacheKey).([]string); ok {
+			for _, id := range cached {
+				if rec, ok := mappedRecords[id]; ok {
+					rec.IgnoreEmailVisibility(true)
+				}
+			}
+			return nil
+		}
+	}
+
 	// fetch the ids of the managed records
 	// ---
 	managedIds := []string{}
@@ -256,6 +493,10 @@ func autoIgnoreAuthRecordsEmailVisibility(
 	if err := query.Column(&managedIds); err != nil {
 		return err
 	}
+
+	if cache != nil {
+		cache.SetIfLessThanLimit(cacheKey, managedIds, requestCacheLimit)
+	}
 	// ---
 
 	// ignore the email visibility check for the managed records
@@ -305,3 +546,115 @@ func hasAuthManageAccess(
 
 	return findErr == nil
 }
+
+// RecordETag returns a weak ETag of the record response.
+//
+// The ETag is derived from the id and updated timestamp of the record
+// and its expanded relations, the requester identity, the response
+// format and the fields and expand query parameters (so that a projection
+// change or an expanded record update still yields fresh data).
+func RecordETag(c echo.Context, record *models.Record) string {
+	h := sha256.New()
+
+	fmt.Fprintf(h, "%s\n%s\n", c.QueryParam(fieldsQueryParam), c.QueryParam(expandQueryParam))
+
+	if isXML, _ := c.Get(rest.ContextXMLKey).(bool); isXML {
+		fmt.Fprint(h, "xml\n")
+	}
+
+	info := RequestInfo(c)
+	if info.Admin != nil {
+		fmt.Fprintf(h, "admin:%s\n", info.Admin.Id)
+	} else if info.AuthRecord != nil {
+		fmt.Fprintf(h, "auth:%s\n", info.AuthRecord.Id)
+	}
+
+	writeRecordETagState(h, record)
+
+	return `W/"` + hex.EncodeToString(h.Sum(nil))[:32] + `"`
+}
+
+func writeRecordETagState(w io.Writer, record *models.Record) {
+	fmt.Fprintf(w, "%s:%s:%s\n", record.Collection().Id, record.Id, record.Updated.String())
+
+	expand := record.Expand()
+
+	keys := make([]string, 0, len(expand))
+	for k := range expand {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		fmt.Fprintf(w, "expand.%s\n", k)
+
+		switch v := expand[k].(type) {
+		case *models.Record:
+			writeRecordETagState(w, v)
+		case []*models.Record:
+			for _, r := range v {
+				writeRecordETagState(w, r)
+			}
+		}
+	}
+}
+
+// checkIfNoneMatch reports whether the request If-None-Match header
+// matches the provided etag (using the weak comparison).
+func checkIfNoneMatch(c echo.Context, etag string) bool {
+	header := c.Request().Header.Get("If-None-Match")
+	if header == "" {
+		return false
+	}
+
+	normalizedEtag := strings.TrimPrefix(etag, "W/")
+
+	for _, v := range strings.Split(header, ",") {
+		v = strings.TrimSpace(v)
+		if v == "*" || strings.TrimPrefix(v, "W/") == normalizedEtag {
+			return true
+		}
+	}
+
+	return false
+}
+
+// preferMinimalReturn reports whether the request has a
+// "Prefer: return=minimal" header (see RFC 7240).
+func preferMinimalReturn(c echo.Context) bool {
+	for _, header := range c.Request().Header.Values("Prefer") {
+		for _, preference := range strings.Split(header, ",") {
+			// ignore the preference parameters (if any)
+			preference, _, _ = strings.Cut(preference, ";")
+
+			name, value, _ := strings.Cut(preference, "=")
+			if !strings.EqualFold(strings.TrimSpace(name), "return") {
+				continue
+			}
+
+			return strings.EqualFold(strings.Trim(strings.TrimSpace(value), `"`), "minimal")
+		}
+	}
+
+	return false
+}
+
+// recordMinimalResponse writes an empty 204 response for the saved record
+// as requested by the "Prefer: return=minimal" header.
+//
+// If withLocation is set, the Location header with the record view url
+// is also added in order to allow retrieving the id of a created record.
+func recordMinimalResponse(c echo.Context, record *models.Record, withLocation bool) error {
+	header := c.Response().Header()
+	header.Set("Preference-Applied", "return=minimal")
+
+	if withLocation {
+		header.Set("Location", fmt.Sprintf(
+			"/api/collections/%s/records/%s",
+			url.PathEscape(record.Collection().Name),
+			url.PathEscape(record.Id),
+		))
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
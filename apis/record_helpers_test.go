@@ -1,14 +1,19 @@
 package apis_test
 
 import (
+	"context"
+	"database/sql"
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/labstack/echo/v5"
+	"github.com/pocketbase/dbx"
 	"github.com/unkod/space/apis"
+	"github.com/unkod/space/daos"
 	"github.com/unkod/space/models"
 	"github.com/unkod/space/tests"
 )
@@ -218,3 +223,148 @@ func TestEnrichRecords(t *testing.T) {
 		}
 	}
 }
+
+func TestEnrichRecordsRequestCache(t *testing.T) {
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	calledQueries := []string{}
+	app.DB().QueryLogFunc = func(ctx context.Context, t time.Duration, sql string, rows *sql.Rows, err error) {
+		calledQueries = append(calledQueries, sql)
+	}
+	app.Dao().NonconcurrentDB().(*dbx.DB).QueryLogFunc = app.DB().QueryLogFunc
+
+	newContext := func() echo.Context {
+		req := httptest.NewRequest(http.MethodGet, "/?expand=rel_many", nil)
+		c := echo.New().NewContext(req, httptest.NewRecorder())
+
+		authRecord, err := app.Dao().FindAuthRecordByEmail("users", "test@example.com")
+		if err != nil {
+			t.Fatal(err)
+		}
+		c.Set(apis.ContextAuthRecordKey, authRecord)
+
+		return c
+	}
+
+	findRecords := func() []*models.Record {
+		records, err := app.Dao().FindRecordsByIds("demo1", []string{"al1h9ijdeojtsjy", "84nmscqy84lsi1t"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return records
+	}
+
+	enrich := func(c echo.Context, dao *daos.Dao) (string, int) {
+		records := findRecords()
+
+		calledQueries = []string{}
+
+		if err := apis.EnrichRecords(c, dao, records); err != nil {
+			t.Fatal(err)
+		}
+
+		raw, err := json.Marshal(records)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		return string(raw), len(calledQueries)
+	}
+
+	c := newContext()
+
+	firstResult, firstTotal := enrich(c, app.Dao())
+	if firstTotal == 0 {
+		t.Fatal("Expected the first enrich to query the db")
+	}
+
+	if !strings.Contains(firstResult, `"expand":{`) {
+		t.Fatalf("Expected expanded records, got %s", firstResult)
+	}
+
+	// same request
+	secondResult, secondTotal := enrich(c, app.Dao())
+	if secondTotal != 0 {
+		t.Fatalf("Expected the second enrich to be served from the request cache, got queries %v", calledQueries)
+	}
+
+	if secondResult != firstResult {
+		t.Fatalf("Expected the cached enrich result to be the same, got \n%s\nvs\n%s", secondResult, firstResult)
+	}
+
+	// transaction dao
+	app.Dao().RunInTransaction(func(txDao *daos.Dao) error {
+		if _, total := enrich(c, txDao); total == 0 {
+			t.Fatal("Expected the request cache to be disabled for the transactional dao")
+		}
+		return nil
+	})
+
+	// new request
+	if _, total := enrich(newContext(), app.Dao()); total == 0 {
+		t.Fatal("Expected the request cache to be not shared between requests")
+	}
+}
+
+func TestRecordETag(t *testing.T) {
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	findRecord := func() *models.Record {
+		record, err := app.Dao().FindRecordById("demo2", "0yxhwia2amd8gec")
+		if err != nil {
+			t.Fatal(err)
+		}
+		return record
+	}
+
+	record := findRecord()
+
+	newContext := func(url string, authId string) echo.Context {
+		req := httptest.NewRequest(http.MethodGet, url, nil)
+		c := echo.New().NewContext(req, httptest.NewRecorder())
+
+		if authId != "" {
+			authRecord := &models.Record{}
+			authRecord.Id = authId
+			c.Set(apis.ContextAuthRecordKey, authRecord)
+		}
+
+		return c
+	}
+
+	base := apis.RecordETag(newContext("/", ""), record)
+
+	if !strings.HasPrefix(base, `W/"`) || !strings.HasSuffix(base, `"`) {
+		t.Fatalf("Expected weak ETag, got %q", base)
+	}
+
+	if v := apis.RecordETag(newContext("/", ""), record); v != base {
+		t.Fatalf("Expected the same ETag %q for the same request and record, got %q", base, v)
+	}
+
+	changedRecord := findRecord()
+	changedRecord.Set("updated", "2000-01-01 00:00:00.000Z")
+
+	expandedRecord := findRecord()
+	expandedRecord.SetExpand(map[string]any{"rel": record})
+
+	scenarios := []struct {
+		name   string
+		c      echo.Context
+		record *models.Record
+	}{
+		{"different fields", newContext("/?fields=id", ""), record},
+		{"different expand", newContext("/?expand=rel", ""), record},
+		{"different auth", newContext("/", "test"), record},
+		{"different updated", newContext("/", ""), changedRecord},
+		{"different expanded records", newContext("/", ""), expandedRecord},
+	}
+
+	for _, s := range scenarios {
+		if v := apis.RecordETag(s.c, s.record); v == base {
+			t.Errorf("[%s] Expected ETag different from %q", s.name, base)
+		}
+	}
+}
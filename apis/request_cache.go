@@ -0,0 +1,73 @@
+package apis
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/labstack/echo/v5"
+	"github.com/pocketbase/dbx"
+	"github.com/unkod/space/daos"
+	"github.com/unkod/space/models"
+	"github.com/unkod/space/tools/store"
+)
+
+// ContextRequestCacheKey is the echo context key of the request-scoped
+// memoization cache with the repeated access rules lookups
+// (eg. the expanded relations and the auth records manage rule checks).
+//
+// The cache lives only as long as the echo context of the request
+// (echo resets the context store on each new request).
+const ContextRequestCacheKey = "requestCache"
+
+// requestCacheLimit is the max number of entries stored in a single request cache
+// (it is there to prevent the cache growing too big with long streamed responses).
+const requestCacheLimit = 1000
+
+// requestCache returns the request-scoped memoization cache of the provided context.
+//
+// Returns nil (aka. caching is disabled) if there is no request context or if
+// dao is a transactional one, since the tx writes could make the cached lookups stale.
+func requestCache(c echo.Context, dao *daos.Dao) *store.Store[any] {
+	if c == nil || dao == nil {
+		return nil
+	}
+
+	if _, ok := dao.DB().(*dbx.Tx); ok {
+		return nil
+	}
+
+	if cache, ok := c.Get(ContextRequestCacheKey).(*store.Store[any]); ok {
+		return cache
+	}
+
+	cache := store.New[any](nil)
+	c.Set(ContextRequestCacheKey, cache)
+
+	return cache
+}
+
+// requestCacheKey generates a request cache key for the specified
+// lookup type, collection, records and current request auth state
+// (the auth state is part of the key because it could be changed
+// during the request, eg. in the auth response).
+func requestCacheKey(
+	lookup string,
+	collection *models.Collection,
+	requestInfo *models.RequestInfo,
+	ids []string,
+) string {
+	sortedIds := make([]string, len(ids))
+	copy(sortedIds, ids)
+	sort.Strings(sortedIds)
+
+	auth := "guest"
+	if requestInfo != nil {
+		if requestInfo.Admin != nil {
+			auth = "admin_" + requestInfo.Admin.Id
+		} else if requestInfo.AuthRecord != nil {
+			auth = requestInfo.AuthRecord.Collection().Id + "_" + requestInfo.AuthRecord.Id
+		}
+	}
+
+	return lookup + ":" + collection.Id + ":" + auth + ":" + strings.Join(sortedIds, ",")
+}
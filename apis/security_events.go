@@ -0,0 +1,154 @@
+package apis
+
+import (
+	"encoding/json"
+	"sort"
+	"time"
+
+	"github.com/labstack/echo/v5"
+	"github.com/unkod/space/core"
+	"github.com/unkod/space/models"
+	"github.com/unkod/space/models/settings"
+	"github.com/unkod/space/tools/security"
+	"github.com/unkod/space/tools/types"
+	"github.com/unkod/space/tools/webhook"
+)
+
+// SecurityEventsWebhookName is the [core.WebhookHeaderName] header
+// value of the security events deliveries.
+const SecurityEventsWebhookName string = "securityEvents"
+
+// list with the security event outcomes
+const (
+	SecurityEventOutcomeSuccess string = "success"
+	SecurityEventOutcomeFailure string = "failure"
+)
+
+// SecurityEventActor defines the initiator of a security event.
+type SecurityEventActor struct {
+	// Type is the actor auth type ("admin", "auth_record" or "guest").
+	Type string `json:"type"`
+
+	// Id is the admin or auth record id (empty for guests).
+	Id string `json:"id"`
+
+	// CollectionId is the auth record collection id (empty for admins and guests).
+	CollectionId string `json:"collectionId"`
+
+	// Identity is the submitted auth identity (eg. the email of a failed login attempt).
+	Identity string `json:"identity"`
+}
+
+// SecurityEvent defines the json body of a single security event delivery.
+type SecurityEvent struct {
+	Id        string             `json:"id"`
+	Event     string             `json:"event"`
+	Outcome   string             `json:"outcome"`
+	Actor     SecurityEventActor `json:"actor"`
+	Ip        string             `json:"ip"`
+	UserAgent string             `json:"userAgent"`
+	Data      map[string]any     `json:"data"`
+	Created   types.DateTime     `json:"created"`
+}
+
+// newSecurityEvent creates a new security event initialized
+// with the request client and auth state.
+//
+// The client IP is resolved from the proxy headers only for
+// trusted proxies (see [trustedUserIp()]).
+func newSecurityEvent(app core.App, c echo.Context, event string) *SecurityEvent {
+	request := c.Request()
+
+	result := &SecurityEvent{
+		Event:     event,
+		Actor:     SecurityEventActor{Type: models.RequestAuthGuest},
+		Ip:        trustedUserIp(app, request),
+		UserAgent: request.UserAgent(),
+	}
+
+	if admin, _ := c.Get(ContextAdminKey).(*models.Admin); admin != nil {
+		result.setAdmin(admin)
+	} else if record, _ := c.Get(ContextAuthRecordKey).(*models.Record); record != nil {
+		result.setAuthRecord(record)
+	}
+
+	return result
+}
+
+// setAdmin sets the admin as event actor.
+func (e *SecurityEvent) setAdmin(admin *models.Admin) {
+	e.Actor.Type = models.RequestAuthAdmin
+	e.Actor.Id = admin.Id
+	e.Actor.CollectionId = ""
+}
+
+// setAuthRecord sets the auth record as event actor.
+func (e *SecurityEvent) setAuthRecord(record *models.Record) {
+	e.Actor.Type = models.RequestAuthRecord
+	e.Actor.Id = record.Id
+	e.Actor.CollectionId = record.Collection().Id
+}
+
+// send delivers a copy of the event with the specified outcome
+// and data to the configured security events endpoint.
+//
+// It is a no-op if the security events are disabled
+// or the endpoint is not subscribed to the event.
+func (e *SecurityEvent) send(app core.App, outcome string, data map[string]any) {
+	config := app.Settings().SecurityEvents
+	if !config.Matches(e.Event) {
+		return
+	}
+
+	if data == nil {
+		data = map[string]any{}
+	}
+
+	event := *e
+	event.Id = security.PseudorandomString(15)
+	event.Outcome = outcome
+	event.Data = data
+	event.Created = types.NowDateTime()
+
+	body, err := json.Marshal(event)
+	if err != nil {
+		app.Logger().Debug("Security event serialization failed", "event", event.Event, "error", err)
+		return
+	}
+
+	msg := &webhook.Message{
+		Url:        config.Url,
+		Secret:     config.Secret,
+		Body:       body,
+		Timeout:    time.Duration(config.Timeout) * time.Second,
+		MaxRetries: config.MaxRetries,
+		Headers: map[string]string{
+			core.WebhookHeaderName:  SecurityEventsWebhookName,
+			core.WebhookHeaderEvent: event.Event,
+			core.WebhookHeaderId:    event.Id,
+		},
+	}
+
+	if err := app.WebhookDispatcher().Send(msg); err != nil {
+		app.Logger().Warn("Failed to queue security event", "event", event.Event, "error", err)
+	}
+}
+
+// settingsChanges returns the sorted redacted paths
+// of the changed settings (see [settings.Settings.RedactedDiff]).
+func settingsChanges(oldSettings *settings.Settings, newSettings *settings.Settings) []string {
+	result := []string{}
+
+	diff, err := oldSettings.RedactedDiff(newSettings)
+	if err != nil {
+		return result
+	}
+
+	for path := range diff {
+		result = append(result, path)
+	}
+
+	sort.Strings(result)
+
+	return result
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"crypto/tls"
 	"log"
+	"log/slog"
 	"net"
 	"net/http"
 	"path/filepath"
@@ -11,7 +12,6 @@ import (
 	"time"
 
 	"github.com/fatih/color"
-	"github.com/labstack/echo/v5/middleware"
 	"github.com/pocketbase/dbx"
 	"github.com/unkod/space/core"
 	"github.com/unkod/space/migrations"
@@ -21,6 +21,10 @@ import (
 	"golang.org/x/crypto/acme/autocert"
 )
 
+// DefaultShutdownTimeout is the default grace period for the
+// in-flight requests to finish on app termination.
+const DefaultShutdownTimeout = 10 * time.Second
+
 // ServeConfig defines a configuration struct for apis.Serve().
 type ServeConfig struct {
 	// ShowStartBanner indicates whether to show or hide the server start console message.
@@ -34,6 +38,12 @@ type ServeConfig struct {
 
 	// AllowedOrigins is an optional list of CORS origins (default to "*").
 	AllowedOrigins []string
+
+	// ShutdownTimeout is the max duration to wait for the in-flight
+	// requests to finish on app termination (default to [DefaultShutdownTimeout]).
+	//
+	// The connections that are still active after the timeout are closed forcefully.
+	ShutdownTimeout time.Duration
 }
 
 // Serve starts a new app web server.
@@ -52,8 +62,12 @@ func Serve(app core.App, config ServeConfig) (*http.Server, error) {
 		config.AllowedOrigins = []string{"*"}
 	}
 
+	if config.ShutdownTimeout <= 0 {
+		config.ShutdownTimeout = DefaultShutdownTimeout
+	}
+
 	// ensure that the latest migrations are applied before starting the server
-	if err := runMigrations(app); err != nil {
+	if err := RunMigrations(app); err != nil {
 		return nil, err
 	}
 
@@ -72,11 +86,7 @@ func Serve(app core.App, config ServeConfig) (*http.Server, error) {
 	}
 
 	// configure cors
-	router.Use(middleware.CORSWithConfig(middleware.CORSConfig{
-		Skipper:      middleware.DefaultSkipper,
-		AllowOrigins: config.AllowedOrigins,
-		AllowMethods: []string{http.MethodGet, http.MethodHead, http.MethodPut, http.MethodPatch, http.MethodPost, http.MethodDelete},
-	}))
+	router.Use(Cors(app, config.AllowedOrigins))
 
 	// start http server
 	// ---
@@ -101,8 +111,9 @@ func Serve(app core.App, config ServeConfig) (*http.Server, error) {
 		ReadTimeout:       10 * time.Minute,
 		ReadHeaderTimeout: 30 * time.Second,
 		// WriteTimeout: 60 * time.Second, // breaks sse!
-		Handler: router,
-		Addr:    mainAddr,
+		Handler:  router,
+		Addr:     mainAddr,
+		ErrorLog: slog.NewLogLogger(app.Logger().Handler(), slog.LevelError),
 	}
 
 	serveEvent := &core.ServeEvent{
@@ -136,11 +147,42 @@ func Serve(app core.App, config ServeConfig) (*http.Server, error) {
 		regular.Printf("└─ Admin UI: %s\n", color.CyanString("%s://%s/_/", schema, server.Addr))
 	}
 
+	// the long-lived realtime connections are not considered idle
+	// so they need to be closed explicitly for the shutdown to complete
+	server.RegisterOnShutdown(func() {
+		DisconnectRealtimeClients(app, "shutdown")
+	})
+
+	var redirectServer *http.Server
+
 	// try to gracefully shutdown the server on app termination
-	app.OnTerminate().Add(func(e *core.TerminateEvent) error {
-		ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	//
+	// note: registered as first handler to ensure that the in-flight
+	// requests have finished before the db connections are closed
+	app.OnTerminate().PreAdd(func(e *core.TerminateEvent) error {
+		ctx, cancel := context.WithTimeout(context.Background(), config.ShutdownTimeout)
 		defer cancel()
-		server.Shutdown(ctx)
+
+		if redirectServer != nil {
+			redirectServer.Shutdown(ctx)
+		}
+
+		if err := server.Shutdown(ctx); err != nil {
+			app.Logger().Warn("Graceful shutdown timeout - closing the remaining connections", "error", err)
+			server.Close()
+		}
+
+		// wait for the pending request logs writes
+		logsDone := make(chan struct{})
+		go func() {
+			pendingRequestLogs.Wait()
+			close(logsDone)
+		}()
+		select {
+		case <-logsDone:
+		case <-ctx.Done():
+		}
+
 		return nil
 	})
 
@@ -148,7 +190,13 @@ func Serve(app core.App, config ServeConfig) (*http.Server, error) {
 	if config.HttpsAddr != "" {
 		// if httpAddr is set, start an HTTP server to redirect the traffic to the HTTPS version
 		if config.HttpAddr != "" {
-			go http.ListenAndServe(config.HttpAddr, certManager.HTTPHandler(nil))
+			redirectServer = &http.Server{
+				Addr:              config.HttpAddr,
+				Handler:           certManager.HTTPHandler(nil),
+				ReadHeaderTimeout: 30 * time.Second,
+				ErrorLog:          server.ErrorLog,
+			}
+			go redirectServer.ListenAndServe()
 		}
 
 		return server, server.ListenAndServeTLS("", "")
@@ -163,7 +211,8 @@ type migrationsConnection struct {
 	MigrationsList migrate.MigrationsList
 }
 
-func runMigrations(app core.App) error {
+// RunMigrations applies all pending app and logs db migrations.
+func RunMigrations(app core.App) error {
 	connections := []migrationsConnection{
 		{
 			DB:             app.DB(),
@@ -186,5 +235,10 @@ func runMigrations(app core.App) error {
 		}
 	}
 
+	// the migrations operate directly on the db without triggering the model hooks
+	if cache := app.Dao().CollectionsCache; cache != nil {
+		cache.Reset()
+	}
+
 	return nil
 }
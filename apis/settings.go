@@ -4,10 +4,13 @@ import (
 	"net/http"
 
 	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/go-ozzo/ozzo-validation/v4/is"
 	"github.com/labstack/echo/v5"
 	"github.com/unkod/space/core"
 	"github.com/unkod/space/forms"
+	"github.com/unkod/space/models"
 	"github.com/unkod/space/models/settings"
+	"github.com/unkod/space/tools/search"
 )
 
 // bindSettingsApi registers the settings api endpoints.
@@ -17,8 +20,16 @@ func bindSettingsApi(app core.App, rg *echo.Group) {
 	subGroup := rg.Group("/settings", ActivityLogger(app), RequireAdminAuth())
 	subGroup.GET("", api.list)
 	subGroup.PATCH("", api.set)
+	subGroup.POST("/maintenance", api.setMaintenance)
+	subGroup.POST("/maintenance/optimize", api.optimize)
+	subGroup.POST("/login-lockout/clear", api.clearLoginLockout)
+	subGroup.GET("/versions", api.listVersions)
+	subGroup.GET("/versions/:id", api.viewVersion)
+	subGroup.POST("/versions/:id/rollback", api.rollbackVersion)
 	subGroup.POST("/test/s3", api.testS3)
 	subGroup.POST("/test/email", api.testEmail)
+	subGroup.POST("/email-templates/preview", api.previewEmailTemplate)
+	subGroup.POST("/email-templates/test-send", api.testSendEmailTemplate)
 	subGroup.POST("/apple/generate-client-secret", api.generateAppleClientSecret)
 }
 
@@ -47,26 +58,38 @@ func (api *settingsApi) list(c echo.Context) error {
 
 func (api *settingsApi) set(c echo.Context) error {
 	form := forms.NewSettingsUpsert(api.app)
+	form.SetAdmin(api.requestAdmin(c))
 
 	// load request
 	if err := c.Bind(form); err != nil {
 		return NewBadRequestError("An error occurred while loading the submitted data.", err)
 	}
 
+	return form.Submit(api.updateInterceptor(c))
+}
+
+// updateInterceptor returns a settings form interceptor that wraps
+// the settings persistence with the settings update request hooks.
+func (api *settingsApi) updateInterceptor(c echo.Context) forms.InterceptorFunc[*settings.Settings] {
 	event := new(core.SettingsUpdateEvent)
 	event.HttpContext = c
 	event.OldSettings = api.app.Settings()
 
-	// update the settings
-	return form.Submit(func(next forms.InterceptorNextFunc[*settings.Settings]) forms.InterceptorNextFunc[*settings.Settings] {
+	return func(next forms.InterceptorNextFunc[*settings.Settings]) forms.InterceptorNextFunc[*settings.Settings] {
 		return func(s *settings.Settings) error {
 			event.NewSettings = s
 
 			return api.app.OnSettingsBeforeUpdateRequest().Trigger(event, func(e *core.SettingsUpdateEvent) error {
+				securityEvent := newSecurityEvent(api.app, e.HttpContext, settings.SecurityEventSettingsUpdate)
+				changes := settingsChanges(api.app.Settings(), e.NewSettings)
+
 				if err := next(e.NewSettings); err != nil {
+					securityEvent.send(api.app, SecurityEventOutcomeFailure, map[string]any{"changes": changes})
 					return NewBadRequestError("An error occurred while submitting the form.", err)
 				}
 
+				securityEvent.send(api.app, SecurityEventOutcomeSuccess, map[string]any{"changes": changes})
+
 				return api.app.OnSettingsAfterUpdateRequest().Trigger(event, func(e *core.SettingsUpdateEvent) error {
 					if e.HttpContext.Response().Committed {
 						return nil
@@ -81,7 +104,166 @@ func (api *settingsApi) set(c echo.Context) error {
 				})
 			})
 		}
-	})
+	}
+}
+
+func (api *settingsApi) requestAdmin(c echo.Context) *models.Admin {
+	admin, _ := c.Get(ContextAdminKey).(*models.Admin)
+	return admin
+}
+
+var settingsVersionFilterFields = []string{
+	"id", "created", "adminId", "rollbackOf",
+}
+
+func (api *settingsApi) listVersions(c echo.Context) error {
+	fieldResolver := search.NewSimpleFieldResolver(settingsVersionFilterFields...)
+
+	provider := search.NewProvider(fieldResolver).
+		Query(api.app.Dao().WithContext(c.Request().Context()).SettingsVersionQuery())
+
+	// by default list the newest changes first
+	if c.QueryParam(search.SortQueryParam) == "" {
+		provider.AddSort(search.SortField{Name: "created", Direction: search.SortDesc})
+	}
+
+	result, err := provider.ParseAndExec(c.QueryParams().Encode(), &[]*models.SettingsVersion{})
+	if err != nil {
+		return NewBadRequestError("", err)
+	}
+
+	return c.JSON(http.StatusOK, result)
+}
+
+func (api *settingsApi) viewVersion(c echo.Context) error {
+	version, err := api.app.Dao().FindSettingsVersionById(c.PathParam("id"))
+	if err != nil || version == nil {
+		return NewNotFoundError("", err)
+	}
+
+	return c.JSON(http.StatusOK, version)
+}
+
+func (api *settingsApi) rollbackVersion(c echo.Context) error {
+	version, err := api.app.Dao().FindSettingsVersionById(c.PathParam("id"))
+	if err != nil || version == nil {
+		return NewNotFoundError("", err)
+	}
+
+	form := forms.NewSettingsRollback(api.app, version)
+	form.SetAdmin(api.requestAdmin(c))
+
+	submitErr := form.Submit(api.updateInterceptor(c))
+	if submitErr == nil {
+		return nil
+	}
+
+	// the interceptor errors are already api errors
+	if apiErr, ok := submitErr.(*ApiError); ok {
+		return apiErr
+	}
+
+	return NewBadRequestError("Failed to rollback the settings version.", submitErr)
+}
+
+func (api *settingsApi) setMaintenance(c echo.Context) error {
+	form := forms.NewSettingsUpsert(api.app)
+	form.SetAdmin(api.requestAdmin(c))
+
+	// load request
+	if err := c.Bind(&form.Maintenance); err != nil {
+		return NewBadRequestError("An error occurred while loading the submitted data.", err)
+	}
+
+	if err := form.Submit(); err != nil {
+		return NewBadRequestError("Failed to update the maintenance settings.", err)
+	}
+
+	return c.JSON(http.StatusOK, api.app.Settings().Maintenance)
+}
+
+func (api *settingsApi) optimize(c echo.Context) error {
+	if api.app.Cache().Has(core.CacheKeyActiveBackup) {
+		return NewBadRequestError("Try again later - a backup/restore operation is currently running.", nil)
+	}
+
+	if api.app.Cache().Has(core.CacheKeyActiveOptimize) {
+		return NewBadRequestError("Try again later - another optimize operation has already been started.", nil)
+	}
+
+	// all steps are enabled by default
+	data := struct {
+		Analyze    *bool `json:"analyze"`
+		Vacuum     *bool `json:"vacuum"`
+		Checkpoint *bool `json:"checkpoint"`
+	}{}
+	if err := c.Bind(&data); err != nil {
+		return NewBadRequestError("An error occurred while loading the submitted data.", err)
+	}
+
+	options := core.OptimizeOptions{
+		Analyze:    data.Analyze == nil || *data.Analyze,
+		Vacuum:     data.Vacuum == nil || *data.Vacuum,
+		Checkpoint: data.Checkpoint == nil || *data.Checkpoint,
+	}
+
+	result, err := api.app.OptimizeDB(c.Request().Context(), options)
+	if err != nil {
+		return NewBadRequestError("Failed to optimize the database. Raw error: \n"+err.Error(), nil)
+	}
+
+	return c.JSON(http.StatusOK, result)
+}
+
+func (api *settingsApi) clearLoginLockout(c echo.Context) error {
+	data := struct {
+		// Collection is the auth collection id or name of the identity
+		// (leave empty for an admin identity).
+		Collection string `json:"collection"`
+		Identity   string `json:"identity"`
+		Ip         string `json:"ip"`
+	}{}
+	if err := c.Bind(&data); err != nil {
+		return NewBadRequestError("An error occurred while loading the submitted data.", err)
+	}
+
+	err := validation.ValidateStruct(&data,
+		validation.Field(&data.Identity, validation.When(data.Ip == "", validation.Required), validation.Length(0, 255)),
+		validation.Field(&data.Ip, validation.When(data.Identity == "", validation.Required), is.IP),
+		validation.Field(&data.Collection, validation.When(data.Identity == "", validation.Empty)),
+	)
+	if err != nil {
+		return NewBadRequestError("Failed to clear the login lockout.", err)
+	}
+
+	keys := []string{}
+
+	if data.Identity != "" {
+		scope := loginLockoutAdminsScope
+		if data.Collection != "" {
+			collection, err := api.app.Dao().FindCollectionByNameOrId(data.Collection)
+			if err != nil || !collection.IsAuth() {
+				return NewBadRequestError("Failed to clear the login lockout.", validation.Errors{
+					"collection": validation.NewError("validation_invalid_auth_collection", "Missing or invalid auth collection."),
+				})
+			}
+			scope = collection.Id
+		}
+		keys = append(keys, loginLockoutIdentityKey(scope, data.Identity))
+	}
+
+	if data.Ip != "" {
+		keys = append(keys, loginLockoutIpKey(data.Ip))
+	}
+
+	store := loginLockoutStore(api.app)
+	for _, key := range keys {
+		if err := store.Reset(key); err != nil {
+			return NewBadRequestError("Failed to clear the login lockout.", err)
+		}
+	}
+
+	return c.NoContent(http.StatusNoContent)
 }
 
 func (api *settingsApi) testS3(c echo.Context) error {
@@ -128,6 +310,50 @@ func (api *settingsApi) testEmail(c echo.Context) error {
 	return c.NoContent(http.StatusNoContent)
 }
 
+func (api *settingsApi) previewEmailTemplate(c echo.Context) error {
+	form := forms.NewEmailTemplatePreview(api.app)
+
+	// load request
+	if err := c.Bind(form); err != nil {
+		return NewBadRequestError("An error occurred while loading the submitted data.", err)
+	}
+
+	// render
+	result, err := form.Submit()
+	if err != nil {
+		// form error
+		if fErr, ok := err.(validation.Errors); ok {
+			return NewBadRequestError("Invalid email template preview request.", fErr)
+		}
+
+		return NewBadRequestError("Failed to render the email template.", err)
+	}
+
+	return c.JSON(http.StatusOK, result)
+}
+
+func (api *settingsApi) testSendEmailTemplate(c echo.Context) error {
+	form := forms.NewEmailTemplateTestSend(api.app)
+
+	// load request
+	if err := c.Bind(form); err != nil {
+		return NewBadRequestError("An error occurred while loading the submitted data.", err)
+	}
+
+	// send
+	if err := form.Submit(); err != nil {
+		// form error
+		if fErr, ok := err.(validation.Errors); ok {
+			return NewBadRequestError("Failed to send the test email.", fErr)
+		}
+
+		// mailer error
+		return NewBadRequestError("Failed to send the test email. Raw error: \n"+err.Error(), nil)
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
 func (api *settingsApi) generateAppleClientSecret(c echo.Context) error {
 	form := forms.NewAppleClientSecretCreate(api.app)
 
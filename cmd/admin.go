@@ -49,7 +49,7 @@ func adminCreateCommand(app core.App) *cobra.Command {
 
 			admin := &models.Admin{}
 			admin.Email = args[0]
-			admin.SetPassword(args[1])
+			admin.SetPasswordWithOptions(args[1], app.Settings().PasswordHashing.Options())
 
 			if err := app.Dao().SaveAdmin(admin); err != nil {
 				return fmt.Errorf("Failed to create new admin account: %v", err)
@@ -89,7 +89,7 @@ func adminUpdateCommand(app core.App) *cobra.Command {
 				return fmt.Errorf("Admin with email %s doesn't exist.", args[0])
 			}
 
-			admin.SetPassword(args[1])
+			admin.SetPasswordWithOptions(args[1], app.Settings().PasswordHashing.Options())
 
 			if err := app.Dao().SaveAdmin(admin); err != nil {
 				return fmt.Errorf("Failed to change admin %s password: %v", admin.Email, err)
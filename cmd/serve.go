@@ -3,6 +3,7 @@ package cmd
 import (
 	"log"
 	"net/http"
+	"time"
 
 	"github.com/spf13/cobra"
 	"github.com/unkod/space/apis"
@@ -15,6 +16,7 @@ func NewServeCommand(app core.App, showStartBanner bool) *cobra.Command {
 	var allowedOrigins []string
 	var httpAddr string
 	var httpsAddr string
+	var shutdownTimeout time.Duration
 
 	command := &cobra.Command{
 		Use:   "serve",
@@ -25,6 +27,7 @@ func NewServeCommand(app core.App, showStartBanner bool) *cobra.Command {
 				HttpsAddr:       httpsAddr,
 				ShowStartBanner: showStartBanner,
 				AllowedOrigins:  allowedOrigins,
+				ShutdownTimeout: shutdownTimeout,
 			})
 
 			if err != http.ErrServerClosed {
@@ -54,5 +57,12 @@ func NewServeCommand(app core.App, showStartBanner bool) *cobra.Command {
 		"api HTTPS server address (auto TLS via Let's Encrypt)\nthe incoming --http address traffic also will be redirected to this address",
 	)
 
+	command.PersistentFlags().DurationVar(
+		&shutdownTimeout,
+		"shutdownTimeout",
+		apis.DefaultShutdownTimeout,
+		"max duration to wait for the in-flight requests to finish on shutdown\nthe still active connections after that are closed forcefully",
+	)
+
 	return command
 }
@@ -5,15 +5,18 @@ package core
 
 import (
 	"context"
+	"log/slog"
 
 	"github.com/pocketbase/dbx"
 	"github.com/unkod/space/daos"
+	"github.com/unkod/space/models"
 	"github.com/unkod/space/models/settings"
 	"github.com/unkod/space/tools/filesystem"
 	"github.com/unkod/space/tools/hook"
 	"github.com/unkod/space/tools/mailer"
 	"github.com/unkod/space/tools/store"
 	"github.com/unkod/space/tools/subscriptions"
+	"github.com/unkod/space/tools/webhook"
 )
 
 // App defines the main PocketBase app interface.
@@ -33,6 +36,24 @@ type App interface {
 	// trying to access the request logs table will result in error.
 	Dao() *daos.Dao
 
+	// RunInTransaction wraps fn into a transaction and passes to it a
+	// transactional copy of the app, aka. txApp.Dao() and all Dao model
+	// hooks fired from it (eg. OnModelBeforeCreate, OnModelAfterDelete, etc.)
+	// operate on the same transaction.
+	//
+	// The transaction is rolled back if fn returns an error.
+	//
+	// Nested txApp.RunInTransaction calls don't create savepoints -
+	// they reuse the outermost transaction, meaning that an error returned
+	// from the nested fn will rollback the entire transaction
+	// (unless it was explicitly ignored by the parent fn).
+	//
+	// Note that only the txApp Dao is transactional, so inside fn
+	// always use txApp.Dao() (or e.Dao in the model hook handlers)
+	// instead of the outer app.Dao(), otherwise the query will either
+	// run outside of the transaction or deadlock waiting for it to complete.
+	RunInTransaction(fn func(txApp App) error) error
+
 	// Deprecated:
 	// This method may get removed in the near future.
 	// It is recommended to access the logs db instance from app.LogsDao().DB() or
@@ -59,6 +80,12 @@ type App interface {
 	// (showing more detailed error logs, executed sql statements, etc.).
 	IsDebug() bool
 
+	// Logger returns the app structured logger.
+	//
+	// Debug level records are logged only if the app is in
+	// debug mode or an explicit "debug" log level was configured.
+	Logger() *slog.Logger
+
 	// Settings returns the loaded app settings.
 	Settings() *settings.Settings
 
@@ -68,9 +95,19 @@ type App interface {
 	// SubscriptionsBroker returns the app realtime subscriptions broker instance.
 	SubscriptionsBroker() *subscriptions.Broker
 
+	// WebhookDispatcher returns the app webhooks delivery queue instance.
+	WebhookDispatcher() *webhook.Dispatcher
+
 	// NewMailClient creates and returns a configured app mail client.
 	NewMailClient() mailer.Mailer
 
+	// NewCollectionMailClient creates and returns a mail client for the
+	// emails sent to the records of the specified collection.
+	//
+	// The auth collection mailer options (if enabled) take precedence
+	// over the global app mail settings.
+	NewCollectionMailClient(collection *models.Collection) mailer.Mailer
+
 	// NewFilesystem creates and returns a configured filesystem.System instance
 	// for managing regular app files (eg. collection uploads).
 	//
@@ -110,6 +147,13 @@ type App interface {
 	// for details on the backup procedures.
 	CreateBackup(ctx context.Context, name string) error
 
+	// CreateIncrementalBackup creates a new backup with only the pb_data
+	// files that were changed since the most recent backup.
+	//
+	// Please refer to the godoc of the specific core.App implementation
+	// for details on the backup procedures.
+	CreateIncrementalBackup(ctx context.Context, name string) error
+
 	// RestoreBackup restores the backup with the specified name and restarts
 	// the current running application process.
 	//
@@ -122,6 +166,13 @@ type App interface {
 	// NB! This feature is experimental and currently is expected to work only on UNIX based systems.
 	RestoreBackup(ctx context.Context, name string) error
 
+	// OptimizeDB runs the enabled ANALYZE, VACUUM and WAL checkpoint
+	// maintenance steps on the app databases.
+	//
+	// Please refer to the godoc of the specific core.App implementation
+	// for details on the optimize procedures.
+	OptimizeDB(ctx context.Context, options OptimizeOptions) (*OptimizeResult, error)
+
 	// Restart restarts the current running application process.
 	//
 	// Currently it is relying on execve so it is supported only on UNIX based systems.
@@ -160,6 +211,15 @@ type App interface {
 	// ---------------------------------------------------------------
 	// Dao event hooks
 	// ---------------------------------------------------------------
+	//
+	// The Dao event hooks are triggered for all persisted models
+	// (Record, Collection, Admin, ExternalAuth, the settings Param, etc.)
+	// on every Dao Save/Delete call, including the ones from the API handlers.
+	//
+	// For the API requests they are fired in addition to and within the
+	// corresponding Record/Collection/Admin request hooks, eg. a record create
+	// request triggers OnRecordBeforeCreateRequest -> OnModelBeforeCreate ->
+	// (db insert) -> OnModelAfterCreate -> OnRecordAfterCreateRequest.
 
 	// OnModelBeforeCreate hook is triggered before inserting a new
 	// model in the DB, allowing you to modify or validate the stored data.
@@ -538,6 +598,25 @@ type App interface {
 	// triggered and called only if their event data origin matches the tags.
 	OnRecordListExternalAuthsRequest(tags ...string) *hook.TaggedHook[*RecordListExternalAuthsEvent]
 
+	// OnRecordBeforeLinkExternalAuthRequest hook is triggered before each API record
+	// external auth link request (after the OAuth2 user fetch and before the relation create).
+	//
+	// Could be used to additionally validate the request data or implement
+	// completely different link behavior.
+	//
+	// If the optional "tags" list (Collection ids or names) is specified,
+	// then all event handlers registered via the created hook will be
+	// triggered and called only if their event data origin matches the tags.
+	OnRecordBeforeLinkExternalAuthRequest(tags ...string) *hook.TaggedHook[*RecordLinkExternalAuthEvent]
+
+	// OnRecordAfterLinkExternalAuthRequest hook is triggered after each
+	// successful API record external auth link request.
+	//
+	// If the optional "tags" list (Collection ids or names) is specified,
+	// then all event handlers registered via the created hook will be
+	// triggered and called only if their event data origin matches the tags.
+	OnRecordAfterLinkExternalAuthRequest(tags ...string) *hook.TaggedHook[*RecordLinkExternalAuthEvent]
+
 	// OnRecordBeforeUnlinkExternalAuthRequest hook is triggered before each API record
 	// external auth unlink request (after models load and before the actual relation deletion).
 	//
@@ -693,6 +772,20 @@ type App interface {
 	// triggered and called only if their event data origin matches the tags.
 	OnRecordViewRequest(tags ...string) *hook.TaggedHook[*RecordViewEvent]
 
+	// OnRecordBeforeSendRequest hook is triggered for each record of an
+	// API Record list or view response right before its serialization
+	// (after the relations expand and the list/view request hooks).
+	//
+	// Could be used to redact or add computed fields only in the response
+	// without changing the stored data (the record is not persisted).
+	// Unknown computed fields are exported only after calling
+	// e.Record.WithUnknownData(true).
+	//
+	// If the optional "tags" list (Collection ids or names) is specified,
+	// then all event handlers registered via the created hook will be
+	// triggered and called only if their event data origin matches the tags.
+	OnRecordBeforeSendRequest(tags ...string) *hook.TaggedHook[*RecordBeforeSendEvent]
+
 	// OnRecordBeforeCreateRequest hook is triggered before each API Record
 	// create request (after request data load and before model persistence).
 	//
@@ -712,6 +805,24 @@ type App interface {
 	// triggered and called only if their event data origin matches the tags.
 	OnRecordAfterCreateRequest(tags ...string) *hook.TaggedHook[*RecordCreateEvent]
 
+	// OnRecordBeforeBulkCreateRequest hook is triggered before each API Records
+	// bulk create request (after all items data load and before models persistence).
+	//
+	// Could be used to additionally validate or veto the entire batch.
+	//
+	// If the optional "tags" list (Collection ids or names) is specified,
+	// then all event handlers registered via the created hook will be
+	// triggered and called only if their event data origin matches the tags.
+	OnRecordBeforeBulkCreateRequest(tags ...string) *hook.TaggedHook[*RecordsBulkCreateEvent]
+
+	// OnRecordAfterBulkCreateRequest hook is triggered after each
+	// API Records bulk create request (with only the successfully created records).
+	//
+	// If the optional "tags" list (Collection ids or names) is specified,
+	// then all event handlers registered via the created hook will be
+	// triggered and called only if their event data origin matches the tags.
+	OnRecordAfterBulkCreateRequest(tags ...string) *hook.TaggedHook[*RecordsBulkCreateEvent]
+
 	// OnRecordBeforeUpdateRequest hook is triggered before each API Record
 	// update request (after request data load and before model persistence).
 	//
@@ -750,6 +861,42 @@ type App interface {
 	// triggered and called only if their event data origin matches the tags.
 	OnRecordAfterDeleteRequest(tags ...string) *hook.TaggedHook[*RecordDeleteEvent]
 
+	// OnRecordBeforeSoftDeleteRequest hook is triggered before each API Record
+	// delete request of a collection with soft-delete enabled (after model
+	// load and before marking the record as deleted).
+	//
+	// Note that for such collections the OnRecord*DeleteRequest hooks are
+	// triggered only when permanently deleting an already soft-deleted record.
+	//
+	// If the optional "tags" list (Collection ids or names) is specified,
+	// then all event handlers registered via the created hook will be
+	// triggered and called only if their event data origin matches the tags.
+	OnRecordBeforeSoftDeleteRequest(tags ...string) *hook.TaggedHook[*RecordSoftDeleteEvent]
+
+	// OnRecordAfterSoftDeleteRequest hook is triggered after each
+	// successful API Record soft-delete request.
+	//
+	// If the optional "tags" list (Collection ids or names) is specified,
+	// then all event handlers registered via the created hook will be
+	// triggered and called only if their event data origin matches the tags.
+	OnRecordAfterSoftDeleteRequest(tags ...string) *hook.TaggedHook[*RecordSoftDeleteEvent]
+
+	// OnRecordBeforeRestoreRequest hook is triggered before each API Record
+	// restore request (after the soft-deleted model load and before its restore).
+	//
+	// If the optional "tags" list (Collection ids or names) is specified,
+	// then all event handlers registered via the created hook will be
+	// triggered and called only if their event data origin matches the tags.
+	OnRecordBeforeRestoreRequest(tags ...string) *hook.TaggedHook[*RecordRestoreEvent]
+
+	// OnRecordAfterRestoreRequest hook is triggered after each
+	// successful API Record restore request.
+	//
+	// If the optional "tags" list (Collection ids or names) is specified,
+	// then all event handlers registered via the created hook will be
+	// triggered and called only if their event data origin matches the tags.
+	OnRecordAfterRestoreRequest(tags ...string) *hook.TaggedHook[*RecordRestoreEvent]
+
 	// ---------------------------------------------------------------
 	// Collection API event hooks
 	// ---------------------------------------------------------------
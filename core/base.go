@@ -4,10 +4,12 @@ import (
 	"context"
 	"database/sql"
 	"errors"
-	"log"
+	"log/slog"
 	"os"
 	"path/filepath"
 	"runtime"
+	"strings"
+	"sync"
 	"syscall"
 	"time"
 
@@ -18,10 +20,12 @@ import (
 	"github.com/unkod/space/models/settings"
 	"github.com/unkod/space/tools/filesystem"
 	"github.com/unkod/space/tools/hook"
+	"github.com/unkod/space/tools/logger"
 	"github.com/unkod/space/tools/mailer"
 	"github.com/unkod/space/tools/routine"
 	"github.com/unkod/space/tools/store"
 	"github.com/unkod/space/tools/subscriptions"
+	"github.com/unkod/space/tools/webhook"
 )
 
 const (
@@ -40,20 +44,31 @@ var _ App = (*BaseApp)(nil)
 // BaseApp implements core.App and defines the base PocketBase app structure.
 type BaseApp struct {
 	// configurable parameters
-	isDebug          bool
-	dataDir          string
-	encryptionEnv    string
-	dataMaxOpenConns int
-	dataMaxIdleConns int
-	logsMaxOpenConns int
-	logsMaxIdleConns int
+	isDebug             bool
+	dataDir             string
+	encryptionEnv       string
+	dataMaxOpenConns    int
+	dataMaxIdleConns    int
+	dataConnMaxLifetime time.Duration
+	dataReadReplica     string
+	logsMaxOpenConns    int
+	logsMaxIdleConns    int
+	logsConnMaxLifetime time.Duration
+	mailer              mailer.Mailer
 
 	// internals
+	logger              *slog.Logger
 	cache               *store.Store[any]
 	settings            *settings.Settings
 	dao                 *daos.Dao
 	logsDao             *daos.Dao
 	subscriptionsBroker *subscriptions.Broker
+	webhookDispatcher   *webhook.Dispatcher
+
+	// the active backup/restore operation (if any)
+	backupMux    sync.Mutex
+	backupCancel context.CancelFunc
+	backupWg     sync.WaitGroup
 
 	// app event hooks
 	onBeforeBootstrap *hook.Hook[*BootstrapEvent]
@@ -139,18 +154,27 @@ type BaseApp struct {
 	onRecordBeforeConfirmEmailChangeRequest   *hook.Hook[*RecordConfirmEmailChangeEvent]
 	onRecordAfterConfirmEmailChangeRequest    *hook.Hook[*RecordConfirmEmailChangeEvent]
 	onRecordListExternalAuthsRequest          *hook.Hook[*RecordListExternalAuthsEvent]
+	onRecordBeforeLinkExternalAuthRequest     *hook.Hook[*RecordLinkExternalAuthEvent]
+	onRecordAfterLinkExternalAuthRequest      *hook.Hook[*RecordLinkExternalAuthEvent]
 	onRecordBeforeUnlinkExternalAuthRequest   *hook.Hook[*RecordUnlinkExternalAuthEvent]
 	onRecordAfterUnlinkExternalAuthRequest    *hook.Hook[*RecordUnlinkExternalAuthEvent]
 
 	// record crud API event hooks
-	onRecordsListRequest        *hook.Hook[*RecordsListEvent]
-	onRecordViewRequest         *hook.Hook[*RecordViewEvent]
-	onRecordBeforeCreateRequest *hook.Hook[*RecordCreateEvent]
-	onRecordAfterCreateRequest  *hook.Hook[*RecordCreateEvent]
-	onRecordBeforeUpdateRequest *hook.Hook[*RecordUpdateEvent]
-	onRecordAfterUpdateRequest  *hook.Hook[*RecordUpdateEvent]
-	onRecordBeforeDeleteRequest *hook.Hook[*RecordDeleteEvent]
-	onRecordAfterDeleteRequest  *hook.Hook[*RecordDeleteEvent]
+	onRecordsListRequest            *hook.Hook[*RecordsListEvent]
+	onRecordViewRequest             *hook.Hook[*RecordViewEvent]
+	onRecordBeforeSendRequest       *hook.Hook[*RecordBeforeSendEvent]
+	onRecordBeforeCreateRequest     *hook.Hook[*RecordCreateEvent]
+	onRecordAfterCreateRequest      *hook.Hook[*RecordCreateEvent]
+	onRecordBeforeBulkCreateRequest *hook.Hook[*RecordsBulkCreateEvent]
+	onRecordAfterBulkCreateRequest  *hook.Hook[*RecordsBulkCreateEvent]
+	onRecordBeforeUpdateRequest     *hook.Hook[*RecordUpdateEvent]
+	onRecordAfterUpdateRequest      *hook.Hook[*RecordUpdateEvent]
+	onRecordBeforeDeleteRequest     *hook.Hook[*RecordDeleteEvent]
+	onRecordAfterDeleteRequest      *hook.Hook[*RecordDeleteEvent]
+	onRecordBeforeSoftDeleteRequest *hook.Hook[*RecordSoftDeleteEvent]
+	onRecordAfterSoftDeleteRequest  *hook.Hook[*RecordSoftDeleteEvent]
+	onRecordBeforeRestoreRequest    *hook.Hook[*RecordRestoreEvent]
+	onRecordAfterRestoreRequest     *hook.Hook[*RecordRestoreEvent]
 
 	// collection API event hooks
 	onCollectionsListRequest         *hook.Hook[*CollectionsListEvent]
@@ -166,14 +190,46 @@ type BaseApp struct {
 }
 
 // BaseAppConfig defines a BaseApp configuration option
+//
+// The data and logs databases are opened in WAL journal mode, which allows
+// concurrent readers together with a single writer. Because of that the
+// MaxOpenConns and MaxIdleConns options are applied only to the
+// concurrent (aka. read) pool and all write operations always go through
+// a separate single connection pool (see [daos.Dao.NonconcurrentDB()]).
+// Increasing the max open connections increases only the read concurrency
+// and doesn't help with "database is locked" errors caused by long
+// running write transactions.
+//
+// ConnMaxLifetime is applied to both pools and when zero (the default)
+// the connections are not closed due to their age.
+//
+// DataReadReplica is an optional path to a read-only replica of the main
+// data.db (eg. a LiteFS or Litestream replica). When set, the records
+// list, view and count API queries are executed on the replica (see
+// [daos.Dao.ReadDao()]), while all writes and transactions still use
+// the primary db. The replica could lag behind the primary so these
+// reads could be stale (eg. a just created record may not be listed yet).
+//
+// LogFormat and LogLevel configure the app structured logger (see [BaseApp.Logger()]).
+// Unsupported values fallback to their defaults.
 type BaseAppConfig struct {
-	DataDir          string
-	EncryptionEnv    string
-	IsDebug          bool
-	DataMaxOpenConns int // default to 500
-	DataMaxIdleConns int // default 20
-	LogsMaxOpenConns int // default to 100
-	LogsMaxIdleConns int // default to 5
+	DataDir             string
+	EncryptionEnv       string
+	IsDebug             bool
+	LogFormat           string        // "text" or "json"; default to "text"
+	LogLevel            string        // "debug", "info", "warn" or "error"; default to "info" ("debug" in debug mode)
+	DataMaxOpenConns    int           // default to DefaultDataMaxOpenConns
+	DataMaxIdleConns    int           // default to DefaultDataMaxIdleConns
+	DataConnMaxLifetime time.Duration // default to 0 (no limit)
+	DataReadReplica     string        // default to none (all reads go to the primary data.db)
+	LogsMaxOpenConns    int           // default to DefaultLogsMaxOpenConns
+	LogsMaxIdleConns    int           // default to DefaultLogsMaxIdleConns
+	LogsConnMaxLifetime time.Duration // default to 0 (no limit)
+
+	// Mailer is an optional mail client that replaces the default
+	// SMTP/sendmail clients for all app and collection emails
+	// (eg. [mailer.NoopMailer] or [mailer.CaptureMailer] for local development and tests).
+	Mailer mailer.Mailer
 }
 
 // NewBaseApp creates and returns a new BaseApp instance
@@ -187,11 +243,16 @@ func NewBaseApp(config BaseAppConfig) *BaseApp {
 		encryptionEnv:       config.EncryptionEnv,
 		dataMaxOpenConns:    config.DataMaxOpenConns,
 		dataMaxIdleConns:    config.DataMaxIdleConns,
+		dataConnMaxLifetime: config.DataConnMaxLifetime,
+		dataReadReplica:     config.DataReadReplica,
 		logsMaxOpenConns:    config.LogsMaxOpenConns,
 		logsMaxIdleConns:    config.LogsMaxIdleConns,
+		logsConnMaxLifetime: config.LogsConnMaxLifetime,
+		mailer:              config.Mailer,
 		cache:               store.New[any](nil),
 		settings:            settings.New(),
 		subscriptionsBroker: subscriptions.NewBroker(),
+		webhookDispatcher:   webhook.NewDispatcher(),
 
 		// app event hooks
 		onBeforeBootstrap: &hook.Hook[*BootstrapEvent]{},
@@ -277,18 +338,27 @@ func NewBaseApp(config BaseAppConfig) *BaseApp {
 		onRecordBeforeConfirmEmailChangeRequest:   &hook.Hook[*RecordConfirmEmailChangeEvent]{},
 		onRecordAfterConfirmEmailChangeRequest:    &hook.Hook[*RecordConfirmEmailChangeEvent]{},
 		onRecordListExternalAuthsRequest:          &hook.Hook[*RecordListExternalAuthsEvent]{},
+		onRecordBeforeLinkExternalAuthRequest:     &hook.Hook[*RecordLinkExternalAuthEvent]{},
+		onRecordAfterLinkExternalAuthRequest:      &hook.Hook[*RecordLinkExternalAuthEvent]{},
 		onRecordBeforeUnlinkExternalAuthRequest:   &hook.Hook[*RecordUnlinkExternalAuthEvent]{},
 		onRecordAfterUnlinkExternalAuthRequest:    &hook.Hook[*RecordUnlinkExternalAuthEvent]{},
 
 		// record crud API event hooks
-		onRecordsListRequest:        &hook.Hook[*RecordsListEvent]{},
-		onRecordViewRequest:         &hook.Hook[*RecordViewEvent]{},
-		onRecordBeforeCreateRequest: &hook.Hook[*RecordCreateEvent]{},
-		onRecordAfterCreateRequest:  &hook.Hook[*RecordCreateEvent]{},
-		onRecordBeforeUpdateRequest: &hook.Hook[*RecordUpdateEvent]{},
-		onRecordAfterUpdateRequest:  &hook.Hook[*RecordUpdateEvent]{},
-		onRecordBeforeDeleteRequest: &hook.Hook[*RecordDeleteEvent]{},
-		onRecordAfterDeleteRequest:  &hook.Hook[*RecordDeleteEvent]{},
+		onRecordsListRequest:            &hook.Hook[*RecordsListEvent]{},
+		onRecordViewRequest:             &hook.Hook[*RecordViewEvent]{},
+		onRecordBeforeSendRequest:       &hook.Hook[*RecordBeforeSendEvent]{},
+		onRecordBeforeCreateRequest:     &hook.Hook[*RecordCreateEvent]{},
+		onRecordAfterCreateRequest:      &hook.Hook[*RecordCreateEvent]{},
+		onRecordBeforeBulkCreateRequest: &hook.Hook[*RecordsBulkCreateEvent]{},
+		onRecordAfterBulkCreateRequest:  &hook.Hook[*RecordsBulkCreateEvent]{},
+		onRecordBeforeUpdateRequest:     &hook.Hook[*RecordUpdateEvent]{},
+		onRecordAfterUpdateRequest:      &hook.Hook[*RecordUpdateEvent]{},
+		onRecordBeforeDeleteRequest:     &hook.Hook[*RecordDeleteEvent]{},
+		onRecordAfterDeleteRequest:      &hook.Hook[*RecordDeleteEvent]{},
+		onRecordBeforeSoftDeleteRequest: &hook.Hook[*RecordSoftDeleteEvent]{},
+		onRecordAfterSoftDeleteRequest:  &hook.Hook[*RecordSoftDeleteEvent]{},
+		onRecordBeforeRestoreRequest:    &hook.Hook[*RecordRestoreEvent]{},
+		onRecordAfterRestoreRequest:     &hook.Hook[*RecordRestoreEvent]{},
 
 		// collection API event hooks
 		onCollectionsListRequest:         &hook.Hook[*CollectionsListEvent]{},
@@ -303,6 +373,8 @@ func NewBaseApp(config BaseAppConfig) *BaseApp {
 		onCollectionsAfterImportRequest:  &hook.Hook[*CollectionsImportEvent]{},
 	}
 
+	app.initLogger(config.LogFormat, config.LogLevel)
+
 	app.registerDefaultHooks()
 
 	return app
@@ -355,6 +427,9 @@ func (app *BaseApp) Bootstrap() error {
 // ResetBootstrapState takes care for releasing initialized app resources
 // (eg. closing db connections).
 func (app *BaseApp) ResetBootstrapState() error {
+	// discard the pending webhook deliveries
+	app.webhookDispatcher.Stop()
+
 	if app.Dao() != nil {
 		if err := app.Dao().ConcurrentDB().(*dbx.DB).Close(); err != nil {
 			return err
@@ -362,6 +437,11 @@ func (app *BaseApp) ResetBootstrapState() error {
 		if err := app.Dao().NonconcurrentDB().(*dbx.DB).Close(); err != nil {
 			return err
 		}
+		if readDB, ok := app.Dao().ReadDB().(*dbx.DB); ok {
+			if err := readDB.Close(); err != nil {
+				return err
+			}
+		}
 	}
 
 	if app.LogsDao() != nil {
@@ -404,6 +484,37 @@ func (app *BaseApp) Dao() *daos.Dao {
 	return app.dao
 }
 
+// RunInTransaction wraps fn into a transaction and passes to it
+// a transactional copy of the app.
+//
+// See [App.RunInTransaction] for more details about the nesting behavior.
+func (app *BaseApp) RunInTransaction(fn func(txApp App) error) error {
+	return runInTransaction(app, app.Dao(), fn)
+}
+
+// txApp is a transactional App proxy that replaces only the default app Dao.
+type txApp struct {
+	App
+
+	dao *daos.Dao
+}
+
+// Dao returns the transactional Dao instance.
+func (app *txApp) Dao() *daos.Dao {
+	return app.dao
+}
+
+// RunInTransaction reuses the current transaction.
+func (app *txApp) RunInTransaction(fn func(txApp App) error) error {
+	return runInTransaction(app.App, app.dao, fn)
+}
+
+func runInTransaction(app App, dao *daos.Dao, fn func(txApp App) error) error {
+	return dao.RunInTransaction(func(txDao *daos.Dao) error {
+		return fn(&txApp{App: app, dao: txDao})
+	})
+}
+
 // Deprecated:
 // This method may get removed in the near future.
 // It is recommended to access the logs db instance from app.LogsDao().DB() or
@@ -445,6 +556,11 @@ func (app *BaseApp) IsDebug() bool {
 	return app.isDebug
 }
 
+// Logger returns the app structured logger.
+func (app *BaseApp) Logger() *slog.Logger {
+	return app.logger
+}
+
 // Settings returns the loaded app settings.
 func (app *BaseApp) Settings() *settings.Settings {
 	return app.settings
@@ -460,9 +576,20 @@ func (app *BaseApp) SubscriptionsBroker() *subscriptions.Broker {
 	return app.subscriptionsBroker
 }
 
+// WebhookDispatcher returns the app webhooks delivery queue instance.
+func (app *BaseApp) WebhookDispatcher() *webhook.Dispatcher {
+	return app.webhookDispatcher
+}
+
 // NewMailClient creates and returns a new SMTP or Sendmail client
 // based on the current app settings.
+//
+// If [BaseAppConfig.Mailer] is set, it is always returned instead.
 func (app *BaseApp) NewMailClient() mailer.Mailer {
+	if app.mailer != nil {
+		return app.mailer
+	}
+
 	if app.Settings().Smtp.Enabled {
 		return &mailer.SmtpClient{
 			Host:       app.Settings().Smtp.Host,
@@ -478,6 +605,33 @@ func (app *BaseApp) NewMailClient() mailer.Mailer {
 	return &mailer.Sendmail{}
 }
 
+// NewCollectionMailClient creates and returns a new SMTP client based
+// on the mailer options of the specified auth collection.
+//
+// Fallbacks to [BaseApp.NewMailClient] if the collection is not
+// an auth collection, it doesn't have enabled mailer options
+// or [BaseAppConfig.Mailer] is set.
+func (app *BaseApp) NewCollectionMailClient(collection *models.Collection) mailer.Mailer {
+	if app.mailer != nil || collection == nil || !collection.IsAuth() {
+		return app.NewMailClient()
+	}
+
+	options := collection.AuthOptions().Mailer
+	if options == nil || !options.Enabled {
+		return app.NewMailClient()
+	}
+
+	return &mailer.SmtpClient{
+		Host:       options.Host,
+		Port:       options.Port,
+		Username:   options.Username,
+		Password:   options.DecryptedPassword(os.Getenv(app.EncryptionEnv())),
+		Tls:        options.Tls,
+		AuthMethod: options.AuthMethod,
+		LocalName:  options.LocalName,
+	}
+}
+
 // NewFilesystem creates a new local or S3 filesystem instance
 // for managing regular app files (eg. collection uploads)
 // based on the current app settings.
@@ -881,6 +1035,14 @@ func (app *BaseApp) OnRecordListExternalAuthsRequest(tags ...string) *hook.Tagge
 	return hook.NewTaggedHook(app.onRecordListExternalAuthsRequest, tags...)
 }
 
+func (app *BaseApp) OnRecordBeforeLinkExternalAuthRequest(tags ...string) *hook.TaggedHook[*RecordLinkExternalAuthEvent] {
+	return hook.NewTaggedHook(app.onRecordBeforeLinkExternalAuthRequest, tags...)
+}
+
+func (app *BaseApp) OnRecordAfterLinkExternalAuthRequest(tags ...string) *hook.TaggedHook[*RecordLinkExternalAuthEvent] {
+	return hook.NewTaggedHook(app.onRecordAfterLinkExternalAuthRequest, tags...)
+}
+
 func (app *BaseApp) OnRecordBeforeUnlinkExternalAuthRequest(tags ...string) *hook.TaggedHook[*RecordUnlinkExternalAuthEvent] {
 	return hook.NewTaggedHook(app.onRecordBeforeUnlinkExternalAuthRequest, tags...)
 }
@@ -901,6 +1063,10 @@ func (app *BaseApp) OnRecordViewRequest(tags ...string) *hook.TaggedHook[*Record
 	return hook.NewTaggedHook(app.onRecordViewRequest, tags...)
 }
 
+func (app *BaseApp) OnRecordBeforeSendRequest(tags ...string) *hook.TaggedHook[*RecordBeforeSendEvent] {
+	return hook.NewTaggedHook(app.onRecordBeforeSendRequest, tags...)
+}
+
 func (app *BaseApp) OnRecordBeforeCreateRequest(tags ...string) *hook.TaggedHook[*RecordCreateEvent] {
 	return hook.NewTaggedHook(app.onRecordBeforeCreateRequest, tags...)
 }
@@ -909,6 +1075,14 @@ func (app *BaseApp) OnRecordAfterCreateRequest(tags ...string) *hook.TaggedHook[
 	return hook.NewTaggedHook(app.onRecordAfterCreateRequest, tags...)
 }
 
+func (app *BaseApp) OnRecordBeforeBulkCreateRequest(tags ...string) *hook.TaggedHook[*RecordsBulkCreateEvent] {
+	return hook.NewTaggedHook(app.onRecordBeforeBulkCreateRequest, tags...)
+}
+
+func (app *BaseApp) OnRecordAfterBulkCreateRequest(tags ...string) *hook.TaggedHook[*RecordsBulkCreateEvent] {
+	return hook.NewTaggedHook(app.onRecordAfterBulkCreateRequest, tags...)
+}
+
 func (app *BaseApp) OnRecordBeforeUpdateRequest(tags ...string) *hook.TaggedHook[*RecordUpdateEvent] {
 	return hook.NewTaggedHook(app.onRecordBeforeUpdateRequest, tags...)
 }
@@ -925,6 +1099,22 @@ func (app *BaseApp) OnRecordAfterDeleteRequest(tags ...string) *hook.TaggedHook[
 	return hook.NewTaggedHook(app.onRecordAfterDeleteRequest, tags...)
 }
 
+func (app *BaseApp) OnRecordBeforeSoftDeleteRequest(tags ...string) *hook.TaggedHook[*RecordSoftDeleteEvent] {
+	return hook.NewTaggedHook(app.onRecordBeforeSoftDeleteRequest, tags...)
+}
+
+func (app *BaseApp) OnRecordAfterSoftDeleteRequest(tags ...string) *hook.TaggedHook[*RecordSoftDeleteEvent] {
+	return hook.NewTaggedHook(app.onRecordAfterSoftDeleteRequest, tags...)
+}
+
+func (app *BaseApp) OnRecordBeforeRestoreRequest(tags ...string) *hook.TaggedHook[*RecordRestoreEvent] {
+	return hook.NewTaggedHook(app.onRecordBeforeRestoreRequest, tags...)
+}
+
+func (app *BaseApp) OnRecordAfterRestoreRequest(tags ...string) *hook.TaggedHook[*RecordRestoreEvent] {
+	return hook.NewTaggedHook(app.onRecordAfterRestoreRequest, tags...)
+}
+
 // -------------------------------------------------------------------
 // Collection API event hooks
 // -------------------------------------------------------------------
@@ -990,6 +1180,7 @@ func (app *BaseApp) initLogsDB() error {
 	concurrentDB.DB().SetMaxOpenConns(maxOpenConns)
 	concurrentDB.DB().SetMaxIdleConns(maxIdleConns)
 	concurrentDB.DB().SetConnMaxIdleTime(5 * time.Minute)
+	concurrentDB.DB().SetConnMaxLifetime(app.logsConnMaxLifetime)
 
 	nonconcurrentDB, err := connectDB(filepath.Join(app.DataDir(), "logs.db"))
 	if err != nil {
@@ -998,6 +1189,7 @@ func (app *BaseApp) initLogsDB() error {
 	nonconcurrentDB.DB().SetMaxOpenConns(1)
 	nonconcurrentDB.DB().SetMaxIdleConns(1)
 	nonconcurrentDB.DB().SetConnMaxIdleTime(5 * time.Minute)
+	nonconcurrentDB.DB().SetConnMaxLifetime(app.logsConnMaxLifetime)
 
 	app.logsDao = daos.NewMultiDB(concurrentDB, nonconcurrentDB)
 
@@ -1021,6 +1213,7 @@ func (app *BaseApp) initDataDB() error {
 	concurrentDB.DB().SetMaxOpenConns(maxOpenConns)
 	concurrentDB.DB().SetMaxIdleConns(maxIdleConns)
 	concurrentDB.DB().SetConnMaxIdleTime(5 * time.Minute)
+	concurrentDB.DB().SetConnMaxLifetime(app.dataConnMaxLifetime)
 
 	nonconcurrentDB, err := connectDB(filepath.Join(app.DataDir(), "data.db"))
 	if err != nil {
@@ -1029,6 +1222,7 @@ func (app *BaseApp) initDataDB() error {
 	nonconcurrentDB.DB().SetMaxOpenConns(1)
 	nonconcurrentDB.DB().SetMaxIdleConns(1)
 	nonconcurrentDB.DB().SetConnMaxIdleTime(5 * time.Minute)
+	nonconcurrentDB.DB().SetConnMaxLifetime(app.dataConnMaxLifetime)
 
 	if app.IsDebug() {
 		nonconcurrentDB.QueryLogFunc = func(ctx context.Context, t time.Duration, sql string, rows *sql.Rows, err error) {
@@ -1043,10 +1237,54 @@ func (app *BaseApp) initDataDB() error {
 	}
 
 	app.dao = app.createDaoWithHooks(concurrentDB, nonconcurrentDB)
+	app.dao.CollectionsCache = daos.NewCollectionsCache(daos.DefaultCollectionsCacheMaxSize)
+	app.dao.EncryptionKeys = app.recordsEncryptionKeys()
+
+	if app.dataReadReplica != "" {
+		readDB, err := connectReadOnlyDB(app.dataReadReplica)
+		if err != nil {
+			return err
+		}
+		readDB.DB().SetMaxOpenConns(maxOpenConns)
+		readDB.DB().SetMaxIdleConns(maxIdleConns)
+		readDB.DB().SetConnMaxIdleTime(5 * time.Minute)
+		readDB.DB().SetConnMaxLifetime(app.dataConnMaxLifetime)
+		readDB.QueryLogFunc = concurrentDB.QueryLogFunc
+		readDB.ExecLogFunc = concurrentDB.ExecLogFunc
+
+		app.dao = app.dao.WithReadDB(readDB)
+	}
 
 	return nil
 }
 
+// recordsEncryptionKeys returns the keys used for the encrypted record fields.
+//
+// The first key is the app encryption env key, followed by the optional
+// comma separated previous keys from the "<EncryptionEnv>_PREVIOUS" env
+// variable (used only for decrypting the old values during a key rotation).
+func (app *BaseApp) recordsEncryptionKeys() []string {
+	if app.EncryptionEnv() == "" {
+		return nil
+	}
+
+	// the current key is required for encrypting the new values
+	current := os.Getenv(app.EncryptionEnv())
+	if len(current) != 32 {
+		return nil
+	}
+
+	keys := []string{current}
+
+	for _, key := range strings.Split(os.Getenv(app.EncryptionEnv()+"_PREVIOUS"), ",") {
+		if key = strings.TrimSpace(key); len(key) == 32 {
+			keys = append(keys, key)
+		}
+	}
+
+	return keys
+}
+
 func (app *BaseApp) createDaoWithHooks(concurrentDB, nonconcurrentDB dbx.Builder) *daos.Dao {
 	dao := daos.NewMultiDB(concurrentDB, nonconcurrentDB)
 
@@ -1107,6 +1345,39 @@ func (app *BaseApp) createDaoWithHooks(concurrentDB, nonconcurrentDB dbx.Builder
 	return dao
 }
 
+// initLogger initializes the app structured logger writing to stderr.
+//
+// Unsupported format and level values fallback to their defaults
+// (after logging a warning with the new logger).
+func (app *BaseApp) initLogger(format string, level string) {
+	var warnings []string
+
+	logLevel := slog.LevelInfo
+	if app.isDebug {
+		logLevel = slog.LevelDebug
+	}
+
+	if level != "" {
+		if parsed, err := logger.ParseLevel(level); err == nil {
+			logLevel = parsed
+		} else {
+			warnings = append(warnings, err.Error())
+		}
+	}
+
+	l, err := logger.New(os.Stderr, format, logLevel)
+	if err != nil {
+		warnings = append(warnings, err.Error())
+		l, _ = logger.New(os.Stderr, logger.FormatText, logLevel)
+	}
+
+	for _, w := range warnings {
+		l.Warn("Invalid logger config, fallback to the default", "error", w)
+	}
+
+	app.logger = l
+}
+
 func (app *BaseApp) registerDefaultHooks() {
 	deletePrefix := func(prefix string) error {
 		fs, err := app.NewFilesystem()
@@ -1134,10 +1405,10 @@ func (app *BaseApp) registerDefaultHooks() {
 			// @todo consider creating a bg process queue so that the
 			// call could be "retried" in case of a failure.
 			routine.FireAndForget(func() {
-				if err := deletePrefix(prefix); err != nil && app.IsDebug() {
+				if err := deletePrefix(prefix); err != nil {
 					// non critical error - only log for debug
 					// (usually could happen because of S3 api limits)
-					log.Println(err)
+					app.Logger().Debug("Failed to delete storage files", "prefix", prefix, "error", err)
 				}
 			})
 		}
@@ -1145,12 +1416,31 @@ func (app *BaseApp) registerDefaultHooks() {
 		return nil
 	})
 
+	// invalidate the cached collections on change
+	// (the after hooks of the transaction daos are triggered after commit)
+	invalidateCollectionsCache := func(e *ModelEvent) error {
+		if m, ok := e.Model.(*models.Collection); ok && app.Dao() != nil && app.Dao().CollectionsCache != nil {
+			app.Dao().CollectionsCache.Remove(m)
+		}
+
+		return nil
+	}
+	app.OnModelAfterCreate().Add(invalidateCollectionsCache)
+	app.OnModelAfterUpdate().Add(invalidateCollectionsCache)
+	app.OnModelAfterDelete().Add(invalidateCollectionsCache)
+
 	app.OnTerminate().Add(func(e *TerminateEvent) error {
+		// cancel and wait for the active backup/restore (if any)
+		// before closing the db connections
+		app.cancelActiveBackup()
+
 		app.ResetBootstrapState()
 		return nil
 	})
 
-	if err := app.initAutobackupHooks(); err != nil && app.IsDebug() {
-		log.Println(err)
+	if err := app.initAutobackupHooks(); err != nil {
+		app.Logger().Debug("Failed to init the autobackup hooks", "error", err)
 	}
+
+	app.initWebhooksHooks()
 }
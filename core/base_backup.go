@@ -2,14 +2,13 @@ package core
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
-	"io"
-	"log"
 	"os"
 	"path/filepath"
 	"runtime"
-	"sort"
+	"strings"
 	"time"
 
 	"github.com/unkod/space/daos"
@@ -23,6 +22,9 @@ import (
 
 const CacheKeyActiveBackup string = "@activeBackup"
 
+// autobackupPrefix is the name prefix of the cron generated backups.
+const autobackupPrefix = "@auto_pb_backup_"
+
 // CreateBackup creates a new backup of the current app pb_data directory.
 //
 // If name is empty, it will be autogenerated.
@@ -42,21 +44,103 @@ const CacheKeyActiveBackup string = "@activeBackup"
 //
 // Backups can be stored on S3 if it is configured in app.Settings().Backups.
 func (app *BaseApp) CreateBackup(ctx context.Context, name string) error {
-	if app.Cache().Has(CacheKeyActiveBackup) {
-		return errors.New("try again later - another backup/restore operation has already been started")
+	// auto generate backup name
+	if name == "" {
+		name = fmt.Sprintf(
+			"pb_backup_%s.zip",
+			time.Now().UTC().Format("20060102150405"),
+		)
 	}
 
+	return app.createBackup(ctx, name, func(fsys *filesystem.System, tempPath string) error {
+		return archive.Create(app.DataDir(), tempPath, LocalBackupsDirName)
+	})
+}
+
+// CreateIncrementalBackup creates a new backup that contains only the
+// pb_data files that were changed since the most recent backup
+// (aka. its parent backup, full or incremental).
+//
+// If name is empty, it will be autogenerated.
+// If backup with the same name exists, the new backup file will replace it.
+//
+// The changed files are detected by comparing their size and CRC-32 checksum
+// with the parent backup ones. The list with all current pb_data files and
+// the parent backup key are stored in the archive [BackupManifestName] entry
+// so that the backups chain could be restored with [BaseApp.RestoreBackup].
+//
+// Note that deleting a backup from the chain will make
+// all of its subsequent incremental backups unrestorable.
+func (app *BaseApp) CreateIncrementalBackup(ctx context.Context, name string) error {
 	// auto generate backup name
 	if name == "" {
 		name = fmt.Sprintf(
-			"pb_backup_%s.zip",
+			"pb_backup_%s_inc.zip",
 			time.Now().UTC().Format("20060102150405"),
 		)
 	}
 
+	return app.createBackup(ctx, name, func(fsys *filesystem.System, tempPath string) error {
+		parent, err := findLatestBackup(fsys, name)
+		if err != nil {
+			return err
+		}
+
+		parentFiles, err := loadBackupFiles(fsys, parent)
+		if err != nil {
+			return fmt.Errorf("failed to load the parent backup %q: %w", parent, err)
+		}
+
+		currentFiles, err := collectBackupFiles(app.DataDir(), LocalBackupsDirName, LocalTempDirName)
+		if err != nil {
+			return err
+		}
+
+		changed := changedBackupFiles(parentFiles, currentFiles)
+
+		manifest, err := json.Marshal(&BackupManifest{Parent: parent, Files: currentFiles})
+		if err != nil {
+			return err
+		}
+
+		return archive.CreatePartial(
+			app.DataDir(),
+			tempPath,
+			changed,
+			map[string][]byte{BackupManifestName: manifest},
+		)
+	})
+}
+
+// createBackup generates a new backup archive with the provided
+// archiveFunc and persists it in the backups filesystem.
+func (app *BaseApp) createBackup(
+	ctx context.Context,
+	name string,
+	archiveFunc func(fsys *filesystem.System, tempPath string) error,
+) error {
+	if app.Cache().Has(CacheKeyActiveBackup) {
+		return errors.New("try again later - another backup/restore operation has already been started")
+	}
+
+	if app.Cache().Has(CacheKeyActiveOptimize) {
+		return errors.New("try again later - a db optimize operation is currently running")
+	}
+
 	app.Cache().Set(CacheKeyActiveBackup, name)
 	defer app.Cache().Remove(CacheKeyActiveBackup)
 
+	ctx, done := app.trackActiveBackup(ctx)
+	defer done()
+
+	fsys, err := app.NewBackupsFilesystem()
+	if err != nil {
+		return err
+	}
+	defer fsys.Close()
+
+	fsys.SetContext(ctx)
+
 	// make sure that the special temp directory exists
 	// note: it needs to be inside the current pb_data to avoid "cross-device link" errors
 	localTempDir := filepath.Join(app.DataDir(), LocalTempDirName)
@@ -71,7 +155,7 @@ func (app *BaseApp) CreateBackup(ctx context.Context, name string) error {
 	tempPath := filepath.Join(localTempDir, "pb_backup_"+security.PseudorandomString(4))
 	createErr := app.Dao().RunInTransaction(func(txDao *daos.Dao) error {
 		// @todo consider experimenting with temp switching the readonly pragma after the db interface change
-		return archive.Create(app.DataDir(), tempPath, LocalBackupsDirName)
+		return archiveFunc(fsys, tempPath)
 	})
 	if createErr != nil {
 		return createErr
@@ -80,22 +164,8 @@ func (app *BaseApp) CreateBackup(ctx context.Context, name string) error {
 
 	// Persist the backup in the backups filesystem.
 	// ---
-	fsys, err := app.NewBackupsFilesystem()
-	if err != nil {
-		return err
-	}
-	defer fsys.Close()
-
-	fsys.SetContext(ctx)
-
-	file, err := filesystem.NewFileFromPath(tempPath)
-	if err != nil {
-		return err
-	}
-	file.OriginalName = name
-	file.Name = file.OriginalName
-
-	if err := fsys.UploadFile(file, file.Name); err != nil {
+	if err := app.uploadBackup(ctx, fsys, tempPath, name); err != nil {
+		app.Logger().Error("Failed to upload backup", "name", name, "error", err)
 		return err
 	}
 
@@ -113,10 +183,13 @@ func (app *BaseApp) CreateBackup(ctx context.Context, name string) error {
 // The performed steps are:
 //
 //  1. Download the backup with the specified name in a temp location
-//     (this is in case of S3; otherwise it creates a temp copy of the zip)
+//     (this is in case of S3; otherwise it creates a temp copy of the zip).
+//     For incremental backups all parent backups up to the first full one are also downloaded.
 //
 //  2. Extract the backup in a temp directory inside the app "pb_data"
 //     (eg. "pb_data/.pb_temp_to_delete/pb_restore").
+//     For incremental backups the full backup is extracted first
+//     and then each next increment of the chain is applied in order.
 //
 //  3. Move the current app "pb_data" content (excluding the local backups and the special temp dir)
 //     under another temp sub dir that will be deleted on the next app start up
@@ -139,9 +212,16 @@ func (app *BaseApp) RestoreBackup(ctx context.Context, name string) error {
 		return errors.New("try again later - another backup/restore operation has already been started")
 	}
 
+	if app.Cache().Has(CacheKeyActiveOptimize) {
+		return errors.New("try again later - a db optimize operation is currently running")
+	}
+
 	app.Cache().Set(CacheKeyActiveBackup, name)
 	defer app.Cache().Remove(CacheKeyActiveBackup)
 
+	ctx, done := app.trackActiveBackup(ctx)
+	defer done()
+
 	fsys, err := app.NewBackupsFilesystem()
 	if err != nil {
 		return err
@@ -150,13 +230,6 @@ func (app *BaseApp) RestoreBackup(ctx context.Context, name string) error {
 
 	fsys.SetContext(ctx)
 
-	// fetch the backup file in a temp location
-	br, err := fsys.GetFile(name)
-	if err != nil {
-		return err
-	}
-	defer br.Close()
-
 	// make sure that the special temp directory exists
 	// note: it needs to be inside the current pb_data to avoid "cross-device link" errors
 	localTempDir := filepath.Join(app.DataDir(), LocalTempDirName)
@@ -164,20 +237,9 @@ func (app *BaseApp) RestoreBackup(ctx context.Context, name string) error {
 		return fmt.Errorf("failed to create a temp dir: %w", err)
 	}
 
-	// create a temp zip file from the blob.Reader and try to extract it
-	tempZip, err := os.CreateTemp(localTempDir, "pb_restore_zip")
-	if err != nil {
-		return err
-	}
-	defer os.Remove(tempZip.Name())
-
-	if _, err := io.Copy(tempZip, br); err != nil {
-		return err
-	}
-
 	extractedDataDir := filepath.Join(localTempDir, "pb_restore_"+security.PseudorandomString(4))
 	defer os.RemoveAll(extractedDataDir)
-	if err := archive.Extract(tempZip.Name(), extractedDataDir); err != nil {
+	if err := extractBackup(fsys, name, extractedDataDir, localTempDir); err != nil {
 		return err
 	}
 
@@ -187,12 +249,6 @@ func (app *BaseApp) RestoreBackup(ctx context.Context, name string) error {
 		return fmt.Errorf("data.db file is missing or invalid: %w", err)
 	}
 
-	// remove the extracted zip file since we no longer need it
-	// (this is in case the app restarts and the defer calls are not called)
-	if err := os.Remove(tempZip.Name()); err != nil && app.IsDebug() {
-		log.Println(err)
-	}
-
 	// root dir entries to exclude from the backup restore
 	exclude := []string{LocalBackupsDirName, LocalTempDirName}
 
@@ -221,6 +277,15 @@ func (app *BaseApp) RestoreBackup(ctx context.Context, name string) error {
 		return nil
 	}
 
+	// the restore was cancelled (eg. on app termination)
+	if err := ctx.Err(); err != nil {
+		if err := revertDataDirChanges(); err != nil {
+			panic(err)
+		}
+
+		return err
+	}
+
 	// restart the app
 	if err := app.Restart(); err != nil {
 		if err := revertDataDirChanges(); err != nil {
@@ -233,8 +298,44 @@ func (app *BaseApp) RestoreBackup(ctx context.Context, name string) error {
 	return nil
 }
 
+// trackActiveBackup registers the backup/restore operation as active
+// until the returned done func is called.
+//
+// The returned context is cancelled on app termination
+// (see [BaseApp.cancelActiveBackup]).
+func (app *BaseApp) trackActiveBackup(ctx context.Context) (context.Context, func()) {
+	ctx, cancel := context.WithCancel(ctx)
+
+	app.backupWg.Add(1)
+
+	app.backupMux.Lock()
+	app.backupCancel = cancel
+	app.backupMux.Unlock()
+
+	return ctx, func() {
+		app.backupMux.Lock()
+		app.backupCancel = nil
+		app.backupMux.Unlock()
+
+		cancel()
+
+		app.backupWg.Done()
+	}
+}
+
+// cancelActiveBackup cancels the active backup/restore operation (if any)
+// and waits for it to cleanup its temp files.
+func (app *BaseApp) cancelActiveBackup() {
+	app.backupMux.Lock()
+	if app.backupCancel != nil {
+		app.backupCancel()
+	}
+	app.backupMux.Unlock()
+
+	app.backupWg.Wait()
+}
+
 // initAutobackupHooks registers the autobackup app serve hooks.
-// @todo add tests
 func (app *BaseApp) initAutobackupHooks() error {
 	c := cron.New()
 	isServe := false
@@ -247,60 +348,7 @@ func (app *BaseApp) initAutobackupHooks() error {
 			return
 		}
 
-		c.Add("@autobackup", rawSchedule, func() {
-			autoPrefix := "@auto_pb_backup_"
-
-			name := fmt.Sprintf(
-				"%s%s.zip",
-				autoPrefix,
-				time.Now().UTC().Format("20060102150405"),
-			)
-
-			if err := app.CreateBackup(context.Background(), name); err != nil && app.IsDebug() {
-				// @todo replace after logs generalization
-				log.Println(err)
-			}
-
-			maxKeep := app.Settings().Backups.CronMaxKeep
-
-			if maxKeep == 0 {
-				return // no explicit limit
-			}
-
-			fsys, err := app.NewBackupsFilesystem()
-			if err != nil && app.IsDebug() {
-				// @todo replace after logs generalization
-				log.Println(err)
-				return
-			}
-			defer fsys.Close()
-
-			files, err := fsys.List(autoPrefix)
-			if err != nil && app.IsDebug() {
-				// @todo replace after logs generalization
-				log.Println(err)
-				return
-			}
-
-			if maxKeep >= len(files) {
-				return // nothing to remove
-			}
-
-			// sort desc
-			sort.Slice(files, func(i, j int) bool {
-				return files[i].ModTime.After(files[j].ModTime)
-			})
-
-			// keep only the most recent n auto backup files
-			toRemove := files[maxKeep:]
-
-			for _, f := range toRemove {
-				if err := fsys.Delete(f.Key); err != nil && app.IsDebug() {
-					// @todo replace after logs generalization
-					log.Println(err)
-				}
-			}
-		})
+		c.Add("@autobackup", rawSchedule, app.runAutobackup)
 
 		// restart the ticker
 		c.Start()
@@ -333,3 +381,67 @@ func (app *BaseApp) initAutobackupHooks() error {
 
 	return nil
 }
+
+// runAutobackup creates a new cron generated backup and removes the old
+// auto backups that are not retained by the current backups settings.
+//
+// Errors are only logged since the autobackup runs in the background.
+func (app *BaseApp) runAutobackup() {
+	defer func() {
+		if r := recover(); r != nil {
+			app.Logger().Error("Autobackup panic", "error", r)
+		}
+	}()
+
+	name := fmt.Sprintf(
+		"%s%s.zip",
+		autobackupPrefix,
+		time.Now().UTC().Format("20060102150405"),
+	)
+
+	if err := app.CreateBackup(context.Background(), name); err != nil {
+		app.Logger().Error("Autobackup failed", "backup", name, "error", err)
+		return
+	}
+
+	if err := app.pruneAutobackups(); err != nil {
+		app.Logger().Error("Autobackup retention cleanup failed", "error", err)
+	}
+}
+
+// pruneAutobackups removes the cron generated backups
+// that are not retained by the current backups settings.
+//
+// The auto backups that are part of a retained incremental
+// backups chain (aka. the chain parents) are never removed.
+func (app *BaseApp) pruneAutobackups() error {
+	fsys, err := app.NewBackupsFilesystem()
+	if err != nil {
+		return err
+	}
+	defer fsys.Close()
+
+	backups, err := ListBackups(fsys, "")
+	if err != nil {
+		return err
+	}
+
+	autobackups := make([]models.BackupFileInfo, 0, len(backups))
+	for _, b := range backups {
+		if strings.HasPrefix(b.Key, autobackupPrefix) {
+			autobackups = append(autobackups, b)
+		}
+	}
+
+	pruneKeys := withoutChainParents(backupsToPrune(autobackups, app.Settings().Backups), backups)
+
+	var errs []error
+
+	for _, key := range pruneKeys {
+		if err := fsys.Delete(key); err != nil {
+			errs = append(errs, fmt.Errorf("failed to delete %q: %w", key, err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
@@ -0,0 +1,368 @@
+package core
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/unkod/space/tools/archive"
+	"github.com/unkod/space/tools/filesystem"
+)
+
+// list with the supported backup modes
+const (
+	BackupModeFull        string = "full"
+	BackupModeIncremental string = "incremental"
+)
+
+// BackupManifestName is the name of the incremental backup archive
+// entry that holds the serialized [BackupManifest].
+const BackupManifestName string = ".pb_backup_manifest.json"
+
+// maxBackupChainLength is the max allowed number of backups in a single chain
+// (used mainly as a safeguard against circular parent references).
+const maxBackupChainLength = 1000
+
+// BackupManifest defines the metadata of an incremental backup.
+type BackupManifest struct {
+	// Parent is the key of the backup, which the current one is based on.
+	Parent string `json:"parent"`
+
+	// Files is a list with all pb_data files (including the unchanged ones)
+	// at the time of the backup, with their slash separated path as key.
+	Files map[string]BackupManifestFile `json:"files"`
+}
+
+// BackupManifestFile defines a single [BackupManifest] file entry.
+type BackupManifestFile struct {
+	Size     int64  `json:"size"`
+	Checksum uint32 `json:"checksum"`
+}
+
+// ReadBackupManifest loads the manifest of the backup with the specified key.
+//
+// Returns nil manifest and no error for full backups.
+func ReadBackupManifest(fsys *filesystem.System, key string) (*BackupManifest, error) {
+	br, err := fsys.GetFile(key)
+	if err != nil {
+		return nil, err
+	}
+	defer br.Close()
+
+	zr, err := zip.NewReader(&readSeekerAt{r: br}, br.Size())
+	if err != nil {
+		return nil, err
+	}
+
+	return readBackupManifest(zr)
+}
+
+func readBackupManifest(zr *zip.Reader) (*BackupManifest, error) {
+	for _, f := range zr.File {
+		if f.Name != BackupManifestName {
+			continue
+		}
+
+		r, err := f.Open()
+		if err != nil {
+			return nil, err
+		}
+		defer r.Close()
+
+		manifest := &BackupManifest{}
+		if err := json.NewDecoder(r).Decode(manifest); err != nil {
+			return nil, fmt.Errorf("invalid backup manifest: %w", err)
+		}
+
+		return manifest, nil
+	}
+
+	return nil, nil
+}
+
+// findLatestBackup returns the key of the most recently modified backup.
+func findLatestBackup(fsys *filesystem.System, excludeKey string) (string, error) {
+	files, err := fsys.List("")
+	if err != nil {
+		return "", err
+	}
+
+	var latest string
+	var latestIndex int
+
+	for i, f := range files {
+		if f.Key == excludeKey {
+			continue
+		}
+
+		if latest == "" || f.ModTime.After(files[latestIndex].ModTime) {
+			latest = f.Key
+			latestIndex = i
+		}
+	}
+
+	if latest == "" {
+		return "", errors.New("an incremental backup requires at least one existing backup")
+	}
+
+	return latest, nil
+}
+
+// loadBackupFiles returns the pb_data files state at the time
+// of the backup with the specified key.
+func loadBackupFiles(fsys *filesystem.System, key string) (map[string]BackupManifestFile, error) {
+	br, err := fsys.GetFile(key)
+	if err != nil {
+		return nil, err
+	}
+	defer br.Close()
+
+	zr, err := zip.NewReader(&readSeekerAt{r: br}, br.Size())
+	if err != nil {
+		return nil, err
+	}
+
+	manifest, err := readBackupManifest(zr)
+	if err != nil {
+		return nil, err
+	}
+
+	if manifest != nil {
+		return manifest.Files, nil
+	}
+
+	// full backup - use the archive entries
+	result := make(map[string]BackupManifestFile, len(zr.File))
+	for _, f := range zr.File {
+		if f.FileInfo().IsDir() {
+			continue
+		}
+
+		result[f.Name] = BackupManifestFile{
+			Size:     int64(f.UncompressedSize64),
+			Checksum: f.CRC32,
+		}
+	}
+
+	return result, nil
+}
+
+// collectBackupFiles returns the current state of the dir files
+// (excluding the skipped root entries).
+func collectBackupFiles(dir string, skipRootEntries ...string) (map[string]BackupManifestFile, error) {
+	result := map[string]BackupManifestFile{}
+
+	fsys := os.DirFS(dir)
+
+	err := fs.WalkDir(fsys, ".", func(name string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		for _, skip := range skipRootEntries {
+			if name == skip {
+				if d.IsDir() {
+					return fs.SkipDir
+				}
+				return nil
+			}
+		}
+
+		if d.IsDir() {
+			return nil
+		}
+
+		f, err := fsys.Open(name)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+
+		h := crc32.NewIEEE()
+
+		size, err := io.Copy(h, f)
+		if err != nil {
+			return err
+		}
+
+		result[name] = BackupManifestFile{Size: size, Checksum: h.Sum32()}
+
+		return nil
+	})
+
+	return result, err
+}
+
+// changedBackupFiles returns the sorted paths of the new and modified current files.
+func changedBackupFiles(old, current map[string]BackupManifestFile) []string {
+	result := []string{}
+
+	for path, f := range current {
+		if oldFile, ok := old[path]; !ok || oldFile != f {
+			result = append(result, path)
+		}
+	}
+
+	sort.Strings(result)
+
+	return result
+}
+
+// extractBackup extracts the backup with the specified name in the dest dir.
+//
+// If the backup is incremental, its full backup is extracted first and then
+// each next increment of the chain is applied (aka. the changed files
+// are replaced and the ones that are not in the increment manifest
+// are removed).
+//
+// tempDir is used to store the temporary downloaded backup archives.
+func extractBackup(fsys *filesystem.System, name string, dest string, tempDir string) error {
+	// download the backups chain (from the newest to the full backup)
+	chain := []string{}
+	manifests := []*BackupManifest{}
+
+	defer func() {
+		for _, path := range chain {
+			os.Remove(path)
+		}
+	}()
+
+	visited := map[string]struct{}{}
+
+	for key := name; ; {
+		if _, ok := visited[key]; ok || len(visited) >= maxBackupChainLength {
+			return fmt.Errorf("invalid backups chain for %q", name)
+		}
+		visited[key] = struct{}{}
+
+		path, err := downloadBackup(fsys, key, tempDir)
+		if err != nil {
+			return fmt.Errorf("failed to fetch backup %q: %w", key, err)
+		}
+		chain = append(chain, path)
+
+		manifest, err := readLocalBackupManifest(path)
+		if err != nil {
+			return err
+		}
+		manifests = append(manifests, manifest)
+
+		if manifest == nil {
+			break // full backup
+		}
+
+		key = manifest.Parent
+	}
+
+	// apply the chain in reverse order (from the full backup to the newest increment)
+	for i := len(chain) - 1; i >= 0; i-- {
+		if err := archive.Extract(chain[i], dest); err != nil {
+			return err
+		}
+
+		if manifests[i] == nil {
+			continue
+		}
+
+		if err := pruneBackupFiles(dest, manifests[i].Files); err != nil {
+			return err
+		}
+	}
+
+	manifestPath := filepath.Join(dest, BackupManifestName)
+	if err := os.Remove(manifestPath); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+
+	return nil
+}
+
+// downloadBackup creates a temp local copy of the backup with the specified key.
+func downloadBackup(fsys *filesystem.System, key string, tempDir string) (string, error) {
+	br, err := fsys.GetFile(key)
+	if err != nil {
+		return "", err
+	}
+	defer br.Close()
+
+	tempZip, err := os.CreateTemp(tempDir, "pb_restore_zip")
+	if err != nil {
+		return "", err
+	}
+	defer tempZip.Close()
+
+	if _, err := io.Copy(tempZip, br); err != nil {
+		os.Remove(tempZip.Name())
+		return "", err
+	}
+
+	return tempZip.Name(), nil
+}
+
+func readLocalBackupManifest(path string) (*BackupManifest, error) {
+	zr, err := zip.OpenReader(path)
+	if err != nil {
+		return nil, err
+	}
+	defer zr.Close()
+
+	return readBackupManifest(&zr.Reader)
+}
+
+// pruneBackupFiles removes all dir files that are not listed in files.
+func pruneBackupFiles(dir string, files map[string]BackupManifestFile) error {
+	return filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if d.IsDir() {
+			return nil
+		}
+
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+
+		if _, ok := files[rel]; ok || rel == BackupManifestName || strings.HasPrefix(rel, "../") {
+			return nil
+		}
+
+		return os.Remove(path)
+	})
+}
+
+// readSeekerAt implements [io.ReaderAt] for an [io.ReadSeeker]
+// (eg. to read only the central directory of a remote zip archive).
+type readSeekerAt struct {
+	mux sync.Mutex
+	r   io.ReadSeeker
+}
+
+// ReadAt implements the [io.ReaderAt] interface.
+func (ra *readSeekerAt) ReadAt(p []byte, off int64) (int, error) {
+	ra.mux.Lock()
+	defer ra.mux.Unlock()
+
+	if _, err := ra.r.Seek(off, io.SeekStart); err != nil {
+		return 0, err
+	}
+
+	n, err := io.ReadFull(ra.r, p)
+	if errors.Is(err, io.ErrUnexpectedEOF) {
+		err = io.EOF
+	}
+
+	return n, err
+}
@@ -0,0 +1,177 @@
+package core
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/unkod/space/tools/archive"
+	"github.com/unkod/space/tools/filesystem"
+)
+
+func TestExtractBackupChain(t *testing.T) {
+	src := t.TempDir()
+	backupsDir := t.TempDir()
+	tempDir := t.TempDir()
+
+	fsys, err := filesystem.NewLocal(backupsDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer fsys.Close()
+
+	writeTestFile(t, filepath.Join(src, "a.txt"), "a")
+	writeTestFile(t, filepath.Join(src, "b.txt"), "b")
+	writeTestFile(t, filepath.Join(src, "sub/c.txt"), "c")
+
+	if err := archive.Create(src, filepath.Join(backupsDir, "full.zip")); err != nil {
+		t.Fatal(err)
+	}
+
+	// delete, change and add files
+	writeTestFile(t, filepath.Join(src, "b.txt"), "b2")
+	writeTestFile(t, filepath.Join(src, "d.txt"), "d")
+	if err := os.Remove(filepath.Join(src, "sub/c.txt")); err != nil {
+		t.Fatal(err)
+	}
+	createTestIncrementalBackup(t, fsys, src, backupsDir, "inc1.zip", "full.zip", []string{"b.txt", "d.txt"})
+
+	writeTestFile(t, filepath.Join(src, "a.txt"), "a3")
+	createTestIncrementalBackup(t, fsys, src, backupsDir, "inc2.zip", "inc1.zip", []string{"a.txt"})
+
+	scenarios := []struct {
+		name     string
+		expected map[string]string
+	}{
+		{
+			"full.zip",
+			map[string]string{"a.txt": "a", "b.txt": "b", "sub/c.txt": "c"},
+		},
+		{
+			"inc1.zip",
+			map[string]string{"a.txt": "a", "b.txt": "b2", "d.txt": "d"},
+		},
+		{
+			"inc2.zip",
+			map[string]string{"a.txt": "a3", "b.txt": "b2", "d.txt": "d"},
+		},
+	}
+
+	for _, s := range scenarios {
+		dest := filepath.Join(t.TempDir(), "extracted")
+
+		if err := extractBackup(fsys, s.name, dest, tempDir); err != nil {
+			t.Fatalf("[%s] Failed to extract backup: %v", s.name, err)
+		}
+
+		files, err := collectBackupFiles(dest)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if len(files) != len(s.expected) {
+			t.Fatalf("[%s] Expected %d files, got %v", s.name, len(s.expected), files)
+		}
+
+		for path, content := range s.expected {
+			raw, err := os.ReadFile(filepath.Join(dest, path))
+			if err != nil {
+				t.Fatalf("[%s] Missing file %q: %v", s.name, path, err)
+			}
+
+			if string(raw) != content {
+				t.Fatalf("[%s] Expected %q content %q, got %q", s.name, path, content, raw)
+			}
+		}
+	}
+
+	// the downloaded temp zips should be removed
+	if entries, _ := os.ReadDir(tempDir); len(entries) != 0 {
+		t.Fatalf("Expected the temp dir to be empty, got %d entries", len(entries))
+	}
+}
+
+func TestExtractBackupInvalidChain(t *testing.T) {
+	src := t.TempDir()
+	backupsDir := t.TempDir()
+
+	fsys, err := filesystem.NewLocal(backupsDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer fsys.Close()
+
+	writeTestFile(t, filepath.Join(src, "a.txt"), "a")
+
+	scenarios := []struct {
+		name   string
+		parent string
+	}{
+		{"missing_parent.zip", "missing.zip"},
+		{"circular.zip", "circular.zip"},
+	}
+
+	for _, s := range scenarios {
+		manifest, _ := json.Marshal(&BackupManifest{Parent: s.parent})
+
+		err := archive.CreatePartial(src, filepath.Join(backupsDir, s.name), nil, map[string][]byte{
+			BackupManifestName: manifest,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if err := extractBackup(fsys, s.name, t.TempDir(), t.TempDir()); err == nil {
+			t.Fatalf("[%s] Expected error, got nil", s.name)
+		}
+	}
+}
+
+// -------------------------------------------------------------------
+
+func writeTestFile(t *testing.T, path string, content string) {
+	if err := os.MkdirAll(filepath.Dir(path), os.ModePerm); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func createTestIncrementalBackup(
+	t *testing.T,
+	fsys *filesystem.System,
+	src string,
+	backupsDir string,
+	name string,
+	parent string,
+	expectedChanges []string,
+) {
+	parentFiles, err := loadBackupFiles(fsys, parent)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	currentFiles, err := collectBackupFiles(src)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	changed := changedBackupFiles(parentFiles, currentFiles)
+
+	if strings.Join(changed, ",") != strings.Join(expectedChanges, ",") {
+		t.Fatalf("[%s] Expected changes %v, got %v", name, expectedChanges, changed)
+	}
+
+	manifest, _ := json.Marshal(&BackupManifest{Parent: parent, Files: currentFiles})
+
+	err = archive.CreatePartial(src, filepath.Join(backupsDir, name), changed, map[string][]byte{
+		BackupManifestName: manifest,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,117 @@
+package core
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/unkod/space/models"
+	"github.com/unkod/space/models/settings"
+)
+
+// backupsToPrune returns the keys of the backups that are not
+// retained by any of the config retention rules.
+//
+// Returns an empty slice if no retention rule is configured.
+func backupsToPrune(backups []models.BackupFileInfo, config settings.BackupsConfig) []string {
+	result := []string{}
+
+	if config.CronMaxKeep <= 0 && !config.HasCalendarRetention() {
+		return result // no explicit limit
+	}
+
+	// sort desc
+	sorted := make([]models.BackupFileInfo, len(backups))
+	copy(sorted, backups)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Modified.Time().After(sorted[j].Modified.Time())
+	})
+
+	keep := make(map[string]struct{}, len(sorted))
+
+	// keep the most recent n backups
+	for i := 0; i < config.CronMaxKeep && i < len(sorted); i++ {
+		keep[sorted[i].Key] = struct{}{}
+	}
+
+	// keep the most recent backup of each of the last n periods
+	keepPeriods := func(n int, period func(t time.Time) string) {
+		periods := map[string]struct{}{}
+
+		for _, b := range sorted {
+			if len(periods) >= n {
+				break
+			}
+
+			p := period(b.Modified.Time().UTC())
+			if _, ok := periods[p]; ok {
+				continue
+			}
+
+			periods[p] = struct{}{}
+			keep[b.Key] = struct{}{}
+		}
+	}
+
+	keepPeriods(config.CronKeepDaily, func(t time.Time) string {
+		return t.Format("2006-01-02")
+	})
+
+	keepPeriods(config.CronKeepWeekly, func(t time.Time) string {
+		year, week := t.ISOWeek()
+		return fmt.Sprintf("%d-%02d", year, week)
+	})
+
+	keepPeriods(config.CronKeepMonthly, func(t time.Time) string {
+		return t.Format("2006-01")
+	})
+
+	for _, b := range sorted {
+		if _, ok := keep[b.Key]; !ok {
+			result = append(result, b.Key)
+		}
+	}
+
+	return result
+}
+
+// withoutChainParents excludes from the pruneKeys the parents
+// of the retained incremental backups (including the parents
+// of their parents up to the first full backup), so that pruning
+// doesn't break the restore of the retained backups chains.
+func withoutChainParents(pruneKeys []string, backups []models.BackupFileInfo) []string {
+	parents := make(map[string]string, len(backups))
+	for _, b := range backups {
+		parents[b.Key] = b.Parent
+	}
+
+	pruned := make(map[string]struct{}, len(pruneKeys))
+	for _, key := range pruneKeys {
+		pruned[key] = struct{}{}
+	}
+
+	required := map[string]struct{}{}
+
+	for _, b := range backups {
+		if _, ok := pruned[b.Key]; ok {
+			continue
+		}
+
+		// walk the retained backup chain
+		for parent := b.Parent; parent != ""; parent = parents[parent] {
+			if _, ok := required[parent]; ok {
+				break // already walked (or a circular chain)
+			}
+			required[parent] = struct{}{}
+		}
+	}
+
+	result := make([]string, 0, len(pruneKeys))
+	for _, key := range pruneKeys {
+		if _, ok := required[key]; !ok {
+			result = append(result, key)
+		}
+	}
+
+	return result
+}
@@ -0,0 +1,277 @@
+package core
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/unkod/space/models"
+	"github.com/unkod/space/models/settings"
+	"github.com/unkod/space/tools/types"
+)
+
+func TestBackupsToPrune(t *testing.T) {
+	newBackup := func(key string, modified string) models.BackupFileInfo {
+		dt, err := types.ParseDateTime(modified)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		return models.BackupFileInfo{Key: key, Modified: dt}
+	}
+
+	backups := []models.BackupFileInfo{
+		newBackup("b1", "2023-01-15 10:00:00.000Z"),
+		newBackup("b2", "2023-02-20 10:00:00.000Z"),
+		newBackup("b3", "2023-03-01 09:00:00.000Z"), // Wed
+		newBackup("b4", "2023-03-06 09:00:00.000Z"), // Mon
+		newBackup("b5", "2023-03-07 09:00:00.000Z"),
+		newBackup("b6", "2023-03-07 18:00:00.000Z"),
+		newBackup("b7", "2023-03-08 09:00:00.000Z"),
+	}
+
+	scenarios := []struct {
+		name     string
+		config   settings.BackupsConfig
+		expected []string
+	}{
+		{
+			"no retention rules",
+			settings.BackupsConfig{},
+			[]string{},
+		},
+		{
+			"max keep",
+			settings.BackupsConfig{CronMaxKeep: 3},
+			[]string{"b4", "b3", "b2", "b1"},
+		},
+		{
+			"max keep bigger than the total backups",
+			settings.BackupsConfig{CronMaxKeep: 10},
+			[]string{},
+		},
+		{
+			"daily",
+			settings.BackupsConfig{CronKeepDaily: 2},
+			[]string{"b5", "b4", "b3", "b2", "b1"},
+		},
+		{
+			"weekly",
+			settings.BackupsConfig{CronKeepWeekly: 2},
+			[]string{"b6", "b5", "b4", "b2", "b1"},
+		},
+		{
+			"monthly",
+			settings.BackupsConfig{CronKeepMonthly: 2},
+			[]string{"b6", "b5", "b4", "b3", "b1"},
+		},
+		{
+			"combined rules",
+			settings.BackupsConfig{CronMaxKeep: 1, CronKeepDaily: 3, CronKeepMonthly: 3},
+			[]string{"b5", "b3"},
+		},
+	}
+
+	for _, s := range scenarios {
+		result := backupsToPrune(backups, s.config)
+
+		if strings.Join(result, ",") != strings.Join(s.expected, ",") {
+			t.Errorf("[%s] Expected %v, got %v", s.name, s.expected, result)
+		}
+	}
+}
+
+func TestRunAutobackup(t *testing.T) {
+	testDataDir := t.TempDir()
+
+	app := NewBaseApp(BaseAppConfig{DataDir: testDataDir})
+	if err := app.Bootstrap(); err != nil {
+		t.Fatal(err)
+	}
+	defer app.ResetBootstrapState()
+
+	app.Settings().Backups.CronMaxKeep = 2
+
+	fsys, err := app.NewBackupsFilesystem()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer fsys.Close()
+
+	// create old test backups
+	backupsDir := filepath.Join(testDataDir, LocalBackupsDirName)
+	oldBackups := []string{
+		autobackupPrefix + "old1.zip",
+		autobackupPrefix + "old2.zip",
+		autobackupPrefix + "old3.zip",
+		"manual.zip", // not auto generated
+	}
+	for i, key := range oldBackups {
+		if err := fsys.Upload([]byte("test"), key); err != nil {
+			t.Fatal(err)
+		}
+
+		modified := time.Now().Add(time.Duration(i-10) * time.Hour)
+		if err := os.Chtimes(filepath.Join(backupsDir, key), modified, modified); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	app.runAutobackup()
+
+	files, err := fsys.List("")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	keys := make([]string, len(files))
+	for i, f := range files {
+		keys[i] = f.Key
+	}
+
+	if len(keys) != 3 {
+		t.Fatalf("Expected 3 backups, got %v", keys)
+	}
+
+	for _, key := range keys {
+		if key == "manual.zip" || key == autobackupPrefix+"old3.zip" {
+			continue
+		}
+
+		if !strings.HasPrefix(key, autobackupPrefix) || strings.HasPrefix(key, autobackupPrefix+"old") {
+			t.Fatalf("Unexpected backup %q in %v", key, keys)
+		}
+	}
+}
+
+func TestWithoutChainParents(t *testing.T) {
+	backups := []models.BackupFileInfo{
+		{Key: "full1"},
+		{Key: "inc1", Parent: "full1"},
+		{Key: "inc2", Parent: "inc1"},
+		{Key: "full2"},
+		{Key: "inc3", Parent: "full2"},
+		{Key: "inc4", Parent: "missing"},
+		{Key: "circular1", Parent: "circular2"},
+		{Key: "circular2", Parent: "circular1"},
+	}
+
+	scenarios := []struct {
+		name      string
+		pruneKeys []string
+		expected  []string
+	}{
+		{
+			"no keys",
+			[]string{},
+			[]string{},
+		},
+		{
+			"full backups without retained dependents",
+			[]string{"full2", "inc3"},
+			[]string{"full2", "inc3"},
+		},
+		{
+			"parents of a retained incremental backup",
+			[]string{"full1", "inc1", "full2", "inc3"},
+			[]string{"full2", "inc3"},
+		},
+		{
+			"parent of a retained incremental backup",
+			[]string{"full2"},
+			[]string{},
+		},
+		{
+			"missing parent",
+			[]string{"full1", "inc1", "inc2", "full2", "inc3"},
+			[]string{"full1", "inc1", "inc2", "full2", "inc3"},
+		},
+		{
+			"circular chain",
+			[]string{"circular1"},
+			[]string{},
+		},
+	}
+
+	for _, s := range scenarios {
+		result := withoutChainParents(s.pruneKeys, backups)
+
+		if strings.Join(result, ",") != strings.Join(s.expected, ",") {
+			t.Errorf("[%s] Expected %v, got %v", s.name, s.expected, result)
+		}
+	}
+}
+
+func TestRunAutobackupKeepsChainParents(t *testing.T) {
+	testDataDir := t.TempDir()
+
+	app := NewBaseApp(BaseAppConfig{DataDir: testDataDir})
+	if err := app.Bootstrap(); err != nil {
+		t.Fatal(err)
+	}
+	defer app.ResetBootstrapState()
+
+	app.Settings().Backups.CronMaxKeep = 1
+
+	fsys, err := app.NewBackupsFilesystem()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer fsys.Close()
+
+	// old1 <- manual_inc1.zip <- manual_inc2.zip
+	incremental := func(parent string) []byte {
+		buf := new(bytes.Buffer)
+		zw := zip.NewWriter(buf)
+		w, err := zw.Create(BackupManifestName)
+		if err != nil {
+			t.Fatal(err)
+		}
+		raw, _ := json.Marshal(&BackupManifest{Parent: parent})
+		if _, err := w.Write(raw); err != nil {
+			t.Fatal(err)
+		}
+		if err := zw.Close(); err != nil {
+			t.Fatal(err)
+		}
+		return buf.Bytes()
+	}
+
+	backupsDir := filepath.Join(testDataDir, LocalBackupsDirName)
+	oldBackups := []struct {
+		key     string
+		content []byte
+	}{
+		{autobackupPrefix + "old1.zip", []byte("test")},
+		{autobackupPrefix + "old2.zip", []byte("test")},
+		{"manual_inc1.zip", incremental(autobackupPrefix + "old1.zip")},
+		{"manual_inc2.zip", incremental("manual_inc1.zip")},
+	}
+	for i, b := range oldBackups {
+		if err := fsys.Upload(b.content, b.key); err != nil {
+			t.Fatal(err)
+		}
+
+		modified := time.Now().Add(time.Duration(i-10) * time.Hour)
+		if err := os.Chtimes(filepath.Join(backupsDir, b.key), modified, modified); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	app.runAutobackup()
+
+	for _, key := range []string{autobackupPrefix + "old1.zip", "manual_inc1.zip", "manual_inc2.zip"} {
+		if exists, _ := fsys.Exists(key); !exists {
+			t.Fatalf("Expected backup %q to be retained", key)
+		}
+	}
+
+	if exists, _ := fsys.Exists(autobackupPrefix + "old2.zip"); exists {
+		t.Fatalf("Expected backup %q to be pruned", autobackupPrefix+"old2.zip")
+	}
+}
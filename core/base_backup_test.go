@@ -1,6 +1,7 @@
 package core_test
 
 import (
+	"archive/zip"
 	"context"
 	"fmt"
 	"io/fs"
@@ -77,6 +78,104 @@ func TestCreateBackup(t *testing.T) {
 	}
 }
 
+func TestCreateIncrementalBackup(t *testing.T) {
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	// missing parent backup
+	if err := app.CreateIncrementalBackup(context.Background(), "inc.zip"); err == nil {
+		t.Fatal("Expected missing parent backup error, got nil")
+	}
+
+	if err := app.CreateBackup(context.Background(), "full.zip"); err != nil {
+		t.Fatal(err)
+	}
+
+	// add a new storage file
+	newFile := filepath.Join(app.DataDir(), "storage", "incremental_test.txt")
+	if err := os.WriteFile(newFile, []byte("test"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := app.CreateIncrementalBackup(context.Background(), "inc.zip"); err != nil {
+		t.Fatal(err)
+	}
+
+	// auto generated name with the previous incremental backup as parent
+	if err := app.CreateIncrementalBackup(context.Background(), ""); err != nil {
+		t.Fatal(err)
+	}
+
+	fsys, err := app.NewBackupsFilesystem()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer fsys.Close()
+
+	files, err := fsys.List("")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(files) != 3 {
+		t.Fatalf("Expected 3 backup files, got %d", len(files))
+	}
+
+	backupsDir := filepath.Join(app.DataDir(), core.LocalBackupsDirName)
+
+	for _, f := range files {
+		manifest, err := core.ReadBackupManifest(fsys, f.Key)
+		if err != nil {
+			t.Fatalf("[%s] Failed to read manifest: %v", f.Key, err)
+		}
+
+		switch {
+		case f.Key == "full.zip":
+			if manifest != nil {
+				t.Fatalf("[%s] Expected nil manifest, got %v", f.Key, manifest)
+			}
+		case f.Key == "inc.zip":
+			if manifest == nil || manifest.Parent != "full.zip" {
+				t.Fatalf("[%s] Expected full.zip parent, got %v", f.Key, manifest)
+			}
+
+			if _, ok := manifest.Files["storage/incremental_test.txt"]; !ok {
+				t.Fatalf("[%s] Expected the new file to be listed in the manifest", f.Key)
+			}
+
+			if _, ok := manifest.Files["data.db"]; !ok {
+				t.Fatalf("[%s] Expected data.db to be listed in the manifest", f.Key)
+			}
+
+			zr, err := zip.OpenReader(filepath.Join(backupsDir, f.Key))
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer zr.Close()
+
+			names := make([]string, len(zr.File))
+			for i, zf := range zr.File {
+				names[i] = zf.Name
+			}
+
+			if !list.ExistInSlice("storage/incremental_test.txt", names) ||
+				!list.ExistInSlice(core.BackupManifestName, names) {
+				t.Fatalf("[%s] Missing expected archive entries: %v", f.Key, names)
+			}
+
+			if len(names) >= len(manifest.Files) {
+				t.Fatalf("[%s] Expected only the changed files to be archived, got %v", f.Key, names)
+			}
+		case strings.HasPrefix(f.Key, "pb_backup_") && strings.HasSuffix(f.Key, "_inc.zip"):
+			if manifest == nil || manifest.Parent != "inc.zip" {
+				t.Fatalf("[%s] Expected inc.zip parent, got %v", f.Key, manifest)
+			}
+		default:
+			t.Fatalf("Unexpected backup file %q", f.Key)
+		}
+	}
+}
+
 func TestRestoreBackup(t *testing.T) {
 	app, _ := tests.NewTestApp()
 	defer app.Cleanup()
@@ -0,0 +1,233 @@
+package core
+
+import (
+	"bytes"
+	"context"
+	"crypto/md5"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/unkod/space/models"
+	"github.com/unkod/space/tools/filesystem"
+	"github.com/unkod/space/tools/types"
+)
+
+// backup upload metadata keys
+const (
+	backupSizeMetaKey = "backup-size"
+	backupMD5MetaKey  = "backup-md5"
+)
+
+// backup upload retry and chunking settings
+// (vars only to allow changing them in the tests).
+var (
+	backupUploadMaxAttempts = 3
+	backupUploadRetryDelay  = 2 * time.Second // doubled after each failed attempt
+	backupUploadPartSize    = 16 << 20        // 16MB
+)
+
+// BackupUploadError is returned when a backup archive
+// fails to be persisted in the backups filesystem.
+type BackupUploadError struct {
+	Key      string
+	Attempts int
+	Err      error
+}
+
+// Error implements the [error] interface.
+func (e *BackupUploadError) Error() string {
+	return fmt.Sprintf("failed to upload backup %q after %d attempt(s): %v", e.Key, e.Attempts, e.Err)
+}
+
+// Unwrap returns the last upload attempt error.
+func (e *BackupUploadError) Unwrap() error {
+	return e.Err
+}
+
+// ListBackups returns info for all fully uploaded backups in the provided backups filesystem.
+//
+// The backups uploaded by the app with different size than the
+// one recorded at the time of the upload (aka. partial) are skipped.
+func ListBackups(fsys *filesystem.System, prefix string) ([]models.BackupFileInfo, error) {
+	files, err := fsys.List(prefix)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]models.BackupFileInfo, 0, len(files))
+
+	for _, obj := range files {
+		if attrs, err := fsys.Attributes(obj.Key); err == nil {
+			if size, ok := attrs.Metadata[backupSizeMetaKey]; ok && size != strconv.FormatInt(obj.Size, 10) {
+				continue // partial
+			}
+		}
+
+		modified, _ := types.ParseDateTime(obj.ModTime)
+
+		info := models.BackupFileInfo{
+			Key:      obj.Key,
+			Size:     obj.Size,
+			Modified: modified,
+		}
+
+		// the manifest is available only for the incremental backups
+		if manifest, _ := ReadBackupManifest(fsys, obj.Key); manifest != nil {
+			info.Parent = manifest.Parent
+		}
+
+		result = append(result, info)
+	}
+
+	return result, nil
+}
+
+// uploadBackup uploads the local backup archive to the backups filesystem
+// retrying with exponential backoff on failure.
+//
+// The large archives are uploaded in chunks (aka. S3 multipart upload)
+// and each upload is verified for size and checksum mismatch.
+func (app *BaseApp) uploadBackup(ctx context.Context, fsys *filesystem.System, localPath string, key string) error {
+	size, checksum, err := backupFileChecksum(localPath)
+	if err != nil {
+		return err
+	}
+
+	app.abortInterruptedBackupUploads(fsys)
+
+	var lastErr error
+	var attempts int
+
+	delay := backupUploadRetryDelay
+
+	for attempts < backupUploadMaxAttempts {
+		if attempts > 0 {
+			select {
+			case <-ctx.Done():
+				return &BackupUploadError{Key: key, Attempts: attempts, Err: errors.Join(lastErr, ctx.Err())}
+			case <-time.After(delay):
+			}
+			delay *= 2
+		}
+
+		attempts++
+
+		lastErr = app.uploadAndVerifyBackup(fsys, localPath, key, size, checksum)
+		if lastErr == nil {
+			return nil
+		}
+
+		app.Logger().Warn(
+			"Backup upload attempt failed",
+			"key", key,
+			"attempt", attempts,
+			"maxAttempts", backupUploadMaxAttempts,
+			"error", lastErr,
+		)
+
+		if ctx.Err() != nil {
+			break
+		}
+	}
+
+	return &BackupUploadError{Key: key, Attempts: attempts, Err: lastErr}
+}
+
+func (app *BaseApp) uploadAndVerifyBackup(
+	fsys *filesystem.System,
+	localPath string,
+	key string,
+	size int64,
+	checksum []byte,
+) error {
+	file, err := filesystem.NewFileFromPath(localPath)
+	if err != nil {
+		return err
+	}
+	file.OriginalName = key
+	file.Name = key
+
+	err = fsys.UploadFileWithOptions(file, key, filesystem.UploadFileOptions{
+		PartSize: backupUploadPartSize,
+		MD5:      checksum,
+		Metadata: map[string]string{
+			backupSizeMetaKey: strconv.FormatInt(size, 10),
+			backupMD5MetaKey:  hex.EncodeToString(checksum),
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	attrs, err := fsys.Attributes(key)
+	if err != nil {
+		return fmt.Errorf("failed to verify the uploaded backup: %w", err)
+	}
+
+	var verifyErr error
+	if attrs.Size != size {
+		verifyErr = fmt.Errorf("uploaded backup size mismatch (expected %d, got %d)", size, attrs.Size)
+	} else if len(attrs.MD5) > 0 && !bytes.Equal(attrs.MD5, checksum) {
+		// the MD5 is not available for the S3 multipart uploads
+		verifyErr = errors.New("uploaded backup checksum mismatch")
+	}
+
+	if verifyErr != nil {
+		if err := fsys.Delete(key); err != nil {
+			app.Logger().Warn("Failed to delete the invalid uploaded backup", "key", key, "error", err)
+		}
+		return verifyErr
+	}
+
+	return nil
+}
+
+// abortInterruptedBackupUploads aborts the pending S3 multipart uploads
+// of the backups filesystem root archives (eg. from a crashed backup process).
+//
+// It is expected to be called only when there is no other active backup upload.
+func (app *BaseApp) abortInterruptedBackupUploads(fsys *filesystem.System) {
+	uploads, err := fsys.ListMultipartUploads("")
+	if err != nil {
+		app.Logger().Warn("Failed to list the pending backup multipart uploads", "error", err)
+		return
+	}
+
+	for _, upload := range uploads {
+		if strings.Contains(upload.Key, "/") || filepath.Ext(upload.Key) != ".zip" {
+			continue // not a backup
+		}
+
+		if err := fsys.AbortMultipartUpload(upload); err != nil {
+			app.Logger().Warn("Failed to abort an interrupted backup upload", "key", upload.Key, "error", err)
+			continue
+		}
+
+		app.Logger().Info("Aborted an interrupted backup upload", "key", upload.Key, "initiated", upload.Initiated)
+	}
+}
+
+// backupFileChecksum returns the size and the MD5 checksum of the local file.
+func backupFileChecksum(path string) (int64, []byte, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return 0, nil, err
+	}
+	defer f.Close()
+
+	h := md5.New()
+
+	size, err := io.Copy(h, f)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	return size, h.Sum(nil), nil
+}
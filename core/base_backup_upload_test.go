@@ -0,0 +1,279 @@
+package core
+
+import (
+	"context"
+	"crypto/md5"
+	"encoding/hex"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/unkod/space/tools/filesystem"
+)
+
+// mockBackupsS3 is a minimal path-style S3 compatible server
+// with configurable upload failures.
+type mockBackupsS3 struct {
+	mux sync.Mutex
+
+	objects  map[string][]byte
+	metadata map[string]http.Header
+	aborted  []string
+	puts     int
+
+	// number of the next PUT requests to fail
+	failPuts int
+
+	// number of the next HEAD requests to report invalid size
+	corruptHeads int
+}
+
+func (m *mockBackupsS3) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	m.mux.Lock()
+	defer m.mux.Unlock()
+
+	key := strings.TrimPrefix(strings.TrimPrefix(r.URL.Path, "/test_bucket"), "/")
+
+	switch {
+	case r.Method == http.MethodGet && r.URL.Query().Has("uploads"):
+		w.Header().Set("Content-Type", "application/xml")
+		w.Write([]byte(`<?xml version="1.0" encoding="UTF-8"?>
+			<ListMultipartUploadsResult xmlns="http://s3.amazonaws.com/doc/2006-03-01/">
+				<Bucket>test_bucket</Bucket>
+				<IsTruncated>false</IsTruncated>
+				<Upload><Key>pb_backup_old.zip</Key><UploadId>old</UploadId><Initiated>2023-01-01T10:00:00.000Z</Initiated></Upload>
+				<Upload><Key>collection/file.zip</Key><UploadId>nested</UploadId><Initiated>2023-01-01T10:00:00.000Z</Initiated></Upload>
+				<Upload><Key>other.txt</Key><UploadId>other</UploadId><Initiated>2023-01-01T10:00:00.000Z</Initiated></Upload>
+			</ListMultipartUploadsResult>
+		`))
+	case r.Method == http.MethodDelete && r.URL.Query().Has("uploadId"):
+		m.aborted = append(m.aborted, key)
+		w.WriteHeader(http.StatusNoContent)
+	case r.Method == http.MethodPut:
+		m.puts++
+		body, _ := io.ReadAll(r.Body)
+		if m.failPuts > 0 {
+			m.failPuts--
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`<?xml version="1.0" encoding="UTF-8"?><Error><Code>InvalidRequest</Code><Message>test</Message></Error>`))
+			return
+		}
+		m.objects[key] = body
+		m.metadata[key] = r.Header.Clone()
+		w.Header().Set("ETag", `"test"`)
+	case r.Method == http.MethodHead || r.Method == http.MethodGet:
+		body, ok := m.objects[key]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		size := len(body)
+		if m.corruptHeads > 0 {
+			m.corruptHeads--
+			size--
+		}
+		for k, v := range m.metadata[key] {
+			if strings.HasPrefix(strings.ToLower(k), "x-amz-meta-") {
+				w.Header()[k] = v
+			}
+		}
+		sum := md5.Sum(body)
+		w.Header().Set("ETag", `"`+hex.EncodeToString(sum[:])+`"`)
+		w.Header().Set("Content-Length", strconv.Itoa(size))
+		w.Header().Set("Last-Modified", "Mon, 02 Jan 2006 15:04:05 GMT")
+		if r.Method == http.MethodGet {
+			w.Write(body[:size])
+		}
+	case r.Method == http.MethodDelete:
+		delete(m.objects, key)
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+func TestUploadBackup(t *testing.T) {
+	oldDelay := backupUploadRetryDelay
+	backupUploadRetryDelay = time.Millisecond
+	defer func() {
+		backupUploadRetryDelay = oldDelay
+	}()
+
+	tempDir := t.TempDir()
+
+	app := NewBaseApp(BaseAppConfig{DataDir: tempDir})
+
+	localPath := filepath.Join(tempDir, "archive")
+	if err := os.WriteFile(localPath, []byte("test_backup_content"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	scenarios := []struct {
+		name             string
+		failPuts         int
+		corruptHeads     int
+		expectedPuts     int
+		expectedAttempts int // 0 for success
+	}{
+		{"successful upload", 0, 0, 1, 0},
+		{"retry after failed upload", 1, 0, 2, 0},
+		{"retry after failed verification", 0, 1, 2, 0},
+		{"failure after max attempts", 10, 0, 3, 3},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			mock := &mockBackupsS3{
+				objects:      map[string][]byte{},
+				metadata:     map[string]http.Header{},
+				failPuts:     s.failPuts,
+				corruptHeads: s.corruptHeads,
+			}
+
+			server := httptest.NewServer(mock)
+			defer server.Close()
+
+			fsys, err := filesystem.NewS3("test_bucket", "test_region", server.URL, "test_key", "test_secret", true)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer fsys.Close()
+
+			uploadErr := app.uploadBackup(context.Background(), fsys, localPath, "test.zip")
+
+			if mock.puts != s.expectedPuts {
+				t.Fatalf("Expected %d PUT requests, got %d", s.expectedPuts, mock.puts)
+			}
+
+			// only the root backup archives uploads should be aborted
+			if len(mock.aborted) != 1 || mock.aborted[0] != "pb_backup_old.zip" {
+				t.Fatalf("Expected only pb_backup_old.zip upload to be aborted, got %v", mock.aborted)
+			}
+
+			if s.expectedAttempts > 0 {
+				var backupErr *BackupUploadError
+				if !errors.As(uploadErr, &backupErr) {
+					t.Fatalf("Expected BackupUploadError, got %v", uploadErr)
+				}
+				if backupErr.Attempts != s.expectedAttempts || backupErr.Key != "test.zip" {
+					t.Fatalf("Expected %d attempts for test.zip, got %d for %s", s.expectedAttempts, backupErr.Attempts, backupErr.Key)
+				}
+				if _, ok := mock.objects["test.zip"]; ok {
+					t.Fatal("Expected the backup to not be stored")
+				}
+				return
+			}
+
+			if uploadErr != nil {
+				t.Fatalf("Expected successful upload, got %v", uploadErr)
+			}
+
+			if content := string(mock.objects["test.zip"]); content != "test_backup_content" {
+				t.Fatalf("Expected the uploaded content to be stored, got %q", content)
+			}
+
+			if size := mock.metadata["test.zip"].Get("X-Amz-Meta-Backup-Size"); size != "19" {
+				t.Fatalf("Expected backup-size metadata 19, got %q", size)
+			}
+		})
+	}
+}
+
+func TestUploadBackupCancelledContext(t *testing.T) {
+	tempDir := t.TempDir()
+
+	app := NewBaseApp(BaseAppConfig{DataDir: tempDir})
+
+	localPath := filepath.Join(tempDir, "archive")
+	if err := os.WriteFile(localPath, []byte("test"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	mock := &mockBackupsS3{
+		objects:  map[string][]byte{},
+		metadata: map[string]http.Header{},
+		failPuts: 10,
+	}
+
+	server := httptest.NewServer(mock)
+	defer server.Close()
+
+	fsys, err := filesystem.NewS3("test_bucket", "test_region", server.URL, "test_key", "test_secret", true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer fsys.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(50*time.Millisecond, cancel)
+
+	start := time.Now()
+
+	// the default retry delay is larger than the cancellation
+	uploadErr := app.uploadBackup(ctx, fsys, localPath, "test.zip")
+	if !errors.Is(uploadErr, context.Canceled) {
+		t.Fatalf("Expected context.Canceled error, got %v", uploadErr)
+	}
+
+	if time.Since(start) > backupUploadRetryDelay {
+		t.Fatal("Expected the retry to be stopped on context cancellation")
+	}
+}
+
+func TestListBackups(t *testing.T) {
+	fsys, err := filesystem.NewLocal(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer fsys.Close()
+
+	upload := func(key string, metadata map[string]string) {
+		file, err := filesystem.NewFileFromBytes([]byte("test"), key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := fsys.UploadFileWithOptions(file, key, filesystem.UploadFileOptions{Metadata: metadata}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	upload("a.zip", nil)
+	upload("b.zip", map[string]string{backupSizeMetaKey: "4"})
+	upload("c.zip", map[string]string{backupSizeMetaKey: "100"}) // partial
+	upload("prefix_d.zip", nil)
+
+	scenarios := []struct {
+		prefix   string
+		expected []string
+	}{
+		{"", []string{"a.zip", "b.zip", "prefix_d.zip"}},
+		{"prefix_", []string{"prefix_d.zip"}},
+	}
+
+	for _, s := range scenarios {
+		backups, err := ListBackups(fsys, s.prefix)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		keys := make([]string, len(backups))
+		for i, b := range backups {
+			keys[i] = b.Key
+			if b.Size != 4 {
+				t.Errorf("[%s] Expected %s size 4, got %d", s.prefix, b.Key, b.Size)
+			}
+		}
+
+		if strings.Join(keys, ",") != strings.Join(s.expected, ",") {
+			t.Errorf("[%s] Expected keys %v, got %v", s.prefix, s.expected, keys)
+		}
+	}
+}
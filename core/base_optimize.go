@@ -0,0 +1,152 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/unkod/space/daos"
+)
+
+// CacheKeyActiveOptimize is the app cache key that marks a running db optimize operation.
+const CacheKeyActiveOptimize string = "@activeOptimize"
+
+// OptimizeOptions defines the db maintenance steps executed by [BaseApp.OptimizeDB].
+type OptimizeOptions struct {
+	// Analyze enables the ANALYZE step (refresh the query planner statistics).
+	Analyze bool
+
+	// Vacuum enables the VACUUM step (reclaim the unused db disk space).
+	Vacuum bool
+
+	// Checkpoint enables the WAL TRUNCATE checkpoint step
+	// (move the WAL changes into the db file and truncate the WAL file).
+	Checkpoint bool
+}
+
+// OptimizeDBResult defines the optimize stats of a single db.
+type OptimizeDBResult struct {
+	SizeBefore    int64                     `json:"sizeBefore"`
+	SizeAfter     int64                     `json:"sizeAfter"`
+	WalSizeBefore int64                     `json:"walSizeBefore"`
+	WalSizeAfter  int64                     `json:"walSizeAfter"`
+	Checkpoint    *daos.WalCheckpointResult `json:"checkpoint"`
+}
+
+// OptimizeResult defines the result of a [BaseApp.OptimizeDB] call.
+type OptimizeResult struct {
+	Data *OptimizeDBResult `json:"data"`
+	Logs *OptimizeDBResult `json:"logs"`
+
+	// Duration is the total optimize duration in milliseconds.
+	Duration int64 `json:"duration"`
+}
+
+// OptimizeDB runs the enabled maintenance steps (ANALYZE, VACUUM and
+// WAL checkpoint) on both the main and the logs databases.
+//
+// VACUUM holds a write lock for its entire duration so the other
+// write operations will wait for it (up to the configured busy timeout).
+// The api writes are rejected while the optimize is running
+// (similar to the Settings().Maintenance.ReadOnly mode).
+//
+// Only one optimize operation is allowed at a time and it
+// cannot run concurrently with a backup/restore operation.
+func (app *BaseApp) OptimizeDB(ctx context.Context, options OptimizeOptions) (*OptimizeResult, error) {
+	if app.Cache().Has(CacheKeyActiveBackup) {
+		return nil, errors.New("try again later - a backup/restore operation is currently running")
+	}
+
+	if app.Cache().Has(CacheKeyActiveOptimize) {
+		return nil, errors.New("try again later - another optimize operation has already been started")
+	}
+
+	app.Cache().Set(CacheKeyActiveOptimize, true)
+	defer app.Cache().Remove(CacheKeyActiveOptimize)
+
+	start := time.Now()
+
+	dataResult, err := optimizeDB(ctx, app.Dao(), filepath.Join(app.DataDir(), "data.db"), options)
+	if err != nil {
+		return nil, fmt.Errorf("failed to optimize the main db: %w", err)
+	}
+
+	logsResult, err := optimizeDB(ctx, app.LogsDao(), filepath.Join(app.DataDir(), "logs.db"), options)
+	if err != nil {
+		return nil, fmt.Errorf("failed to optimize the logs db: %w", err)
+	}
+
+	result := &OptimizeResult{
+		Data:     dataResult,
+		Logs:     logsResult,
+		Duration: time.Since(start).Milliseconds(),
+	}
+
+	app.Logger().Info(
+		"DB optimize completed",
+		"durationMs", result.Duration,
+		"dataSizeBefore", dataResult.SizeBefore,
+		"dataSizeAfter", dataResult.SizeAfter,
+		"logsSizeBefore", logsResult.SizeBefore,
+		"logsSizeAfter", logsResult.SizeAfter,
+	)
+
+	return result, nil
+}
+
+func optimizeDB(ctx context.Context, dao *daos.Dao, dbPath string, options OptimizeOptions) (*OptimizeDBResult, error) {
+	result := &OptimizeDBResult{
+		SizeBefore:    fileSize(dbPath),
+		WalSizeBefore: fileSize(dbPath + "-wal"),
+	}
+
+	if options.Analyze {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		if err := dao.Analyze(); err != nil {
+			return nil, err
+		}
+	}
+
+	if options.Vacuum {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		if err := dao.Vacuum(); err != nil {
+			return nil, err
+		}
+	}
+
+	if options.Checkpoint {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		checkpoint, err := dao.WalCheckpoint(daos.WalCheckpointTruncate)
+		if err != nil {
+			return nil, err
+		}
+		result.Checkpoint = checkpoint
+	}
+
+	result.SizeAfter = fileSize(dbPath)
+	result.WalSizeAfter = fileSize(dbPath + "-wal")
+
+	return result, nil
+}
+
+// fileSize returns the size of the specified file or 0 if it doesn't exist.
+func fileSize(path string) int64 {
+	info, err := os.Stat(path)
+	if err != nil {
+		return 0
+	}
+
+	return info.Size()
+}
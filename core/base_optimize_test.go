@@ -0,0 +1,62 @@
+package core_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/unkod/space/core"
+	"github.com/unkod/space/tests"
+)
+
+func TestOptimizeDB(t *testing.T) {
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	// with active backup
+	app.Cache().Set(core.CacheKeyActiveBackup, "test.zip")
+	if _, err := app.OptimizeDB(context.Background(), core.OptimizeOptions{Vacuum: true}); err == nil {
+		t.Fatal("Expected error during active backup, got nil")
+	}
+	app.Cache().Remove(core.CacheKeyActiveBackup)
+
+	// with cancelled context
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := app.OptimizeDB(ctx, core.OptimizeOptions{Vacuum: true}); err == nil {
+		t.Fatal("Expected error with cancelled context, got nil")
+	}
+
+	// all steps
+	result, err := app.OptimizeDB(context.Background(), core.OptimizeOptions{
+		Analyze:    true,
+		Vacuum:     true,
+		Checkpoint: true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for name, r := range map[string]*core.OptimizeDBResult{"data": result.Data, "logs": result.Logs} {
+		if r.SizeBefore == 0 || r.SizeAfter == 0 {
+			t.Errorf("[%s] Expected non-zero db sizes, got %d and %d", name, r.SizeBefore, r.SizeAfter)
+		}
+
+		if r.WalSizeAfter != 0 {
+			t.Errorf("[%s] Expected truncated WAL file, got %d", name, r.WalSizeAfter)
+		}
+
+		if r.Checkpoint == nil || r.Checkpoint.Busy {
+			t.Errorf("[%s] Expected non-busy checkpoint result, got %v", name, r.Checkpoint)
+		}
+	}
+
+	if app.Cache().Has(core.CacheKeyActiveOptimize) {
+		t.Fatal("Expected the optimize cache key to be removed")
+	}
+
+	// backup during optimize
+	app.Cache().Set(core.CacheKeyActiveOptimize, true)
+	if err := app.CreateBackup(context.Background(), "test.zip"); err == nil {
+		t.Fatal("Expected backup error during active optimize, got nil")
+	}
+}
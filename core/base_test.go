@@ -1,9 +1,17 @@
 package core
 
 import (
+	"context"
+	"log/slog"
 	"os"
+	"path/filepath"
 	"testing"
+	"time"
 
+	"github.com/pocketbase/dbx"
+	"github.com/unkod/space/daos"
+	"github.com/unkod/space/models"
+	"github.com/unkod/space/models/settings"
 	"github.com/unkod/space/tools/mailer"
 )
 
@@ -42,6 +50,178 @@ func TestNewBaseApp(t *testing.T) {
 	}
 }
 
+func TestBaseAppLogger(t *testing.T) {
+	scenarios := []struct {
+		name          string
+		config        BaseAppConfig
+		expectedLevel slog.Level
+	}{
+		{"default", BaseAppConfig{}, slog.LevelInfo},
+		{"debug mode", BaseAppConfig{IsDebug: true}, slog.LevelDebug},
+		{"explicit level", BaseAppConfig{LogLevel: "warn"}, slog.LevelWarn},
+		{"explicit level in debug mode", BaseAppConfig{IsDebug: true, LogLevel: "error"}, slog.LevelError},
+		{"explicit json format", BaseAppConfig{LogFormat: "json", LogLevel: "warn"}, slog.LevelWarn},
+		{"invalid format and level", BaseAppConfig{LogFormat: "invalid", LogLevel: "invalid"}, slog.LevelInfo},
+	}
+
+	for _, s := range scenarios {
+		app := NewBaseApp(s.config)
+
+		if app.Logger() == nil {
+			t.Fatalf("[%s] Expected logger to be set, got nil", s.name)
+		}
+
+		for _, level := range []slog.Level{slog.LevelDebug, slog.LevelInfo, slog.LevelWarn, slog.LevelError} {
+			expected := level >= s.expectedLevel
+			enabled := app.Logger().Enabled(context.Background(), level)
+			if enabled != expected {
+				t.Errorf("[%s] Expected level %v enabled %v, got %v", s.name, level, expected, enabled)
+			}
+		}
+	}
+}
+
+func TestBaseAppDBPoolConfig(t *testing.T) {
+	const testDataDir = "./pb_base_app_test_data_dir/"
+	defer os.RemoveAll(testDataDir)
+
+	scenarios := []struct {
+		name             string
+		config           BaseAppConfig
+		expectedDataOpen int
+		expectedLogsOpen int
+	}{
+		{
+			"default pool config",
+			BaseAppConfig{DataDir: testDataDir},
+			DefaultDataMaxOpenConns,
+			DefaultLogsMaxOpenConns,
+		},
+		{
+			"custom pool config",
+			BaseAppConfig{
+				DataDir:             testDataDir,
+				DataMaxOpenConns:    5,
+				DataMaxIdleConns:    2,
+				DataConnMaxLifetime: time.Minute,
+				LogsMaxOpenConns:    3,
+				LogsMaxIdleConns:    1,
+				LogsConnMaxLifetime: time.Minute,
+			},
+			5,
+			3,
+		},
+	}
+
+	for _, s := range scenarios {
+		app := NewBaseApp(s.config)
+
+		if err := app.Bootstrap(); err != nil {
+			t.Fatalf("[%s] %v", s.name, err)
+		}
+
+		dataDB, _ := app.Dao().ConcurrentDB().(*dbx.DB)
+		if v := dataDB.DB().Stats().MaxOpenConnections; v != s.expectedDataOpen {
+			t.Errorf("[%s] Expected data max open conns %d, got %d", s.name, s.expectedDataOpen, v)
+		}
+
+		logsDB, _ := app.LogsDao().ConcurrentDB().(*dbx.DB)
+		if v := logsDB.DB().Stats().MaxOpenConnections; v != s.expectedLogsOpen {
+			t.Errorf("[%s] Expected logs max open conns %d, got %d", s.name, s.expectedLogsOpen, v)
+		}
+
+		// the nonconcurrent (aka. write) pools should always have a single connection
+		nonconcurrentDataDB, _ := app.Dao().NonconcurrentDB().(*dbx.DB)
+		if v := nonconcurrentDataDB.DB().Stats().MaxOpenConnections; v != 1 {
+			t.Errorf("[%s] Expected nonconcurrent data max open conns 1, got %d", s.name, v)
+		}
+
+		app.ResetBootstrapState()
+	}
+}
+
+func TestBaseAppDataReadReplica(t *testing.T) {
+	const testDataDir = "./pb_base_app_test_data_dir/"
+	defer os.RemoveAll(testDataDir)
+
+	replicaPath := filepath.Join(testDataDir, "replica.db")
+
+	// missing replica db
+	missingApp := NewBaseApp(BaseAppConfig{DataDir: testDataDir, DataReadReplica: replicaPath})
+	if err := missingApp.Bootstrap(); err == nil {
+		t.Fatal("Expected bootstrap error for missing read replica db")
+	}
+	missingApp.ResetBootstrapState()
+
+	// create the replica as a copy of the primary db
+	primaryApp := NewBaseApp(BaseAppConfig{DataDir: testDataDir})
+	if err := primaryApp.Bootstrap(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := primaryApp.Dao().DB().NewQuery("CREATE TABLE test (id TEXT)").Execute(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := primaryApp.Dao().DB().Insert("test", dbx.Params{"id": "a"}).Execute(); err != nil {
+		t.Fatal(err)
+	}
+	primaryApp.ResetBootstrapState()
+
+	raw, err := os.ReadFile(filepath.Join(testDataDir, "data.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(replicaPath, raw, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	app := NewBaseApp(BaseAppConfig{
+		DataDir:          testDataDir,
+		DataMaxOpenConns: 5,
+		DataReadReplica:  replicaPath,
+	})
+	if err := app.Bootstrap(); err != nil {
+		t.Fatal(err)
+	}
+	defer app.ResetBootstrapState()
+
+	readDB, _ := app.Dao().ReadDB().(*dbx.DB)
+	if readDB == nil {
+		t.Fatal("Expected the read replica db to be initialized")
+	}
+	if v := readDB.DB().Stats().MaxOpenConnections; v != 5 {
+		t.Fatalf("Expected read replica max open conns 5, got %d", v)
+	}
+
+	// insert a new record only in the primary db
+	if _, err := app.Dao().DB().Insert("test", dbx.Params{"id": "b"}).Execute(); err != nil {
+		t.Fatal(err)
+	}
+
+	var primaryTotal, replicaTotal int
+	app.Dao().DB().Select("count(*)").From("test").Row(&primaryTotal)
+	app.Dao().ReadDao().DB().Select("count(*)").From("test").Row(&replicaTotal)
+
+	if primaryTotal != 2 {
+		t.Fatalf("Expected 2 primary db rows, got %d", primaryTotal)
+	}
+	if replicaTotal != 1 {
+		t.Fatalf("Expected 1 read replica db row, got %d", replicaTotal)
+	}
+
+	// the replica should be query only
+	if _, err := app.Dao().ReadDao().NonconcurrentDB().Insert("test", dbx.Params{"id": "c"}).Execute(); err == nil {
+		t.Fatal("Expected the read replica write to fail")
+	}
+
+	// the transactions should always use the primary db
+	app.Dao().RunInTransaction(func(txDao *daos.Dao) error {
+		if txDao.ReadDao() != txDao {
+			t.Fatal("Expected the tx read dao to be the tx dao itself")
+		}
+		return nil
+	})
+}
+
 func TestBaseAppBootstrap(t *testing.T) {
 	const testDataDir = "./pb_base_app_test_data_dir/"
 	defer os.RemoveAll(testDataDir)
@@ -205,6 +385,148 @@ func TestBaseAppNewMailClient(t *testing.T) {
 	if val, ok := client2.(*mailer.SmtpClient); !ok {
 		t.Fatalf("Expected mailer.SmtpClient instance, got %v", val)
 	}
+
+	// custom mailer
+	custom := &mailer.CaptureMailer{}
+
+	app2 := NewBaseApp(BaseAppConfig{
+		DataDir: testDataDir,
+		Mailer:  custom,
+	})
+	app2.Settings().Smtp.Enabled = true
+
+	if client := app2.NewMailClient(); client != custom {
+		t.Fatalf("Expected the custom mailer instance, got %v", client)
+	}
+
+	authCollection := &models.Collection{Type: models.CollectionTypeAuth}
+	authCollection.SetOptions(models.CollectionAuthOptions{
+		Mailer: &models.CollectionMailerOptions{SmtpConfig: settings.SmtpConfig{Enabled: true, Host: "smtp.example.com"}},
+	})
+
+	if client := app2.NewCollectionMailClient(authCollection); client != custom {
+		t.Fatalf("Expected the custom collection mailer instance, got %v", client)
+	}
+}
+
+func TestBaseAppRecordsEncryptionKeys(t *testing.T) {
+	const testDataDir = "./pb_base_app_test_data_dir/"
+	defer os.RemoveAll(testDataDir)
+
+	const key1 = "abcdabcdabcdabcdabcdabcdabcdabcd"
+	const key2 = "12345678901234567890123456789012"
+
+	scenarios := []struct {
+		current  string
+		previous string
+		expected []string
+	}{
+		{"", "", nil},
+		{"invalid", key2, nil},
+		{"", key2, nil},
+		{key1, "", []string{key1}},
+		{key1, key2 + ", invalid,," + key2, []string{key1, key2, key2}},
+	}
+
+	for i, s := range scenarios {
+		t.Setenv("pb_test_env", s.current)
+		t.Setenv("pb_test_env_PREVIOUS", s.previous)
+
+		app := NewBaseApp(BaseAppConfig{
+			DataDir:       testDataDir,
+			EncryptionEnv: "pb_test_env",
+		})
+
+		if err := app.Bootstrap(); err != nil {
+			t.Fatal(err)
+		}
+
+		keys := app.Dao().EncryptionKeys
+
+		app.ResetBootstrapState()
+
+		if len(keys) != len(s.expected) {
+			t.Errorf("(%d) Expected keys %v, got %v", i, s.expected, keys)
+			continue
+		}
+		for j, key := range keys {
+			if key != s.expected[j] {
+				t.Errorf("(%d) Expected keys %v, got %v", i, s.expected, keys)
+				break
+			}
+		}
+	}
+}
+
+func TestBaseAppNewCollectionMailClient(t *testing.T) {
+	const testDataDir = "./pb_base_app_test_data_dir/"
+	defer os.RemoveAll(testDataDir)
+
+	const encryptionKey = "abcdabcdabcdabcdabcdabcdabcdabcd"
+	t.Setenv("pb_test_env", encryptionKey)
+
+	app := NewBaseApp(BaseAppConfig{
+		DataDir:       testDataDir,
+		EncryptionEnv: "pb_test_env",
+		IsDebug:       false,
+	})
+
+	mailerOptions := &models.CollectionMailerOptions{
+		SmtpConfig: settings.SmtpConfig{
+			Enabled:  true,
+			Host:     "smtp.example.com",
+			Port:     587,
+			Password: "123456",
+		},
+	}
+	if err := mailerOptions.EncryptPassword(encryptionKey); err != nil {
+		t.Fatal(err)
+	}
+
+	authCollection := &models.Collection{Type: models.CollectionTypeAuth}
+	authCollection.SetOptions(models.CollectionAuthOptions{Mailer: mailerOptions})
+
+	disabledCollection := &models.Collection{Type: models.CollectionTypeAuth}
+	disabledCollection.SetOptions(models.CollectionAuthOptions{
+		Mailer: &models.CollectionMailerOptions{SmtpConfig: settings.SmtpConfig{Host: "smtp.example.com"}},
+	})
+
+	scenarios := []struct {
+		name         string
+		collection   *models.Collection
+		expectedHost string // empty for Sendmail
+	}{
+		{"nil collection", nil, ""},
+		{"base collection", &models.Collection{Type: models.CollectionTypeBase}, ""},
+		{"auth collection without mailer options", &models.Collection{Type: models.CollectionTypeAuth}, ""},
+		{"auth collection with disabled mailer options", disabledCollection, ""},
+		{"auth collection with enabled mailer options", authCollection, "smtp.example.com"},
+	}
+
+	for _, s := range scenarios {
+		client := app.NewCollectionMailClient(s.collection)
+
+		if s.expectedHost == "" {
+			if val, ok := client.(*mailer.Sendmail); !ok {
+				t.Errorf("[%s] Expected mailer.Sendmail instance, got %v", s.name, val)
+			}
+			continue
+		}
+
+		smtpClient, ok := client.(*mailer.SmtpClient)
+		if !ok {
+			t.Errorf("[%s] Expected mailer.SmtpClient instance, got %v", s.name, client)
+			continue
+		}
+
+		if smtpClient.Host != s.expectedHost {
+			t.Errorf("[%s] Expected host %q, got %q", s.name, s.expectedHost, smtpClient.Host)
+		}
+
+		if smtpClient.Password != "123456" {
+			t.Errorf("[%s] Expected the decrypted password, got %q", s.name, smtpClient.Password)
+		}
+	}
 }
 
 func TestBaseAppNewFilesystem(t *testing.T) {
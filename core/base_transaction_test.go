@@ -0,0 +1,139 @@
+package core_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/unkod/space/core"
+	"github.com/unkod/space/daos"
+	"github.com/unkod/space/models"
+	"github.com/unkod/space/tests"
+)
+
+func TestBaseAppRunInTransactionRollback(t *testing.T) {
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	collection, err := app.Dao().FindCollectionByNameOrId("demo2")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	totalBefore := countRecords(t, app.Dao(), collection)
+
+	var hookDaos []*daos.Dao
+	app.OnModelAfterCreate(collection.Name).Add(func(e *core.ModelEvent) error {
+		hookDaos = append(hookDaos, e.Dao)
+		return nil
+	})
+
+	expectedErr := errors.New("test")
+
+	txErr := app.RunInTransaction(func(txApp core.App) error {
+		if txApp.Dao() == app.Dao() {
+			t.Fatal("Expected txApp.Dao() to be different from the outer app.Dao()")
+		}
+
+		for _, title := range []string{"tx1", "tx2"} {
+			record := models.NewRecord(collection)
+			record.Set("title", title)
+			if err := txApp.Dao().SaveRecord(record); err != nil {
+				return err
+			}
+		}
+
+		// nested calls should reuse the same transaction
+		nestedErr := txApp.RunInTransaction(func(nestedApp core.App) error {
+			record := models.NewRecord(collection)
+			record.Set("title", "tx3")
+			return nestedApp.Dao().SaveRecord(record)
+		})
+		if nestedErr != nil {
+			return nestedErr
+		}
+
+		if total := countRecords(t, txApp.Dao(), collection); total != totalBefore+3 {
+			t.Fatalf("Expected %d records inside the transaction, got %d", totalBefore+3, total)
+		}
+
+		return expectedErr
+	})
+
+	if txErr != expectedErr {
+		t.Fatalf("Expected error %v, got %v", expectedErr, txErr)
+	}
+
+	if total := countRecords(t, app.Dao(), collection); total != totalBefore {
+		t.Fatalf("Expected the created records to be rolled back (%d), got %d", totalBefore, total)
+	}
+
+	// the after hooks are triggered only after a successful commit
+	if len(hookDaos) != 0 {
+		t.Fatalf("Expected no after create hook calls, got %d", len(hookDaos))
+	}
+
+	// successful commit
+	txErr = app.RunInTransaction(func(txApp core.App) error {
+		record := models.NewRecord(collection)
+		record.Set("title", "tx4")
+		return txApp.Dao().SaveRecord(record)
+	})
+	if txErr != nil {
+		t.Fatal(txErr)
+	}
+
+	if total := countRecords(t, app.Dao(), collection); total != totalBefore+1 {
+		t.Fatalf("Expected %d records, got %d", totalBefore+1, total)
+	}
+
+	if len(hookDaos) != 1 {
+		t.Fatalf("Expected 1 after create hook call, got %d", len(hookDaos))
+	}
+}
+
+func TestBaseAppRunInTransactionHookDao(t *testing.T) {
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	collection, err := app.Dao().FindCollectionByNameOrId("demo2")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var hookDao *daos.Dao
+	app.OnModelBeforeCreate(collection.Name).Add(func(e *core.ModelEvent) error {
+		hookDao = e.Dao
+		return nil
+	})
+
+	var txDao *daos.Dao
+	txErr := app.RunInTransaction(func(txApp core.App) error {
+		txDao = txApp.Dao()
+
+		record := models.NewRecord(collection)
+		record.Set("title", "tx")
+		return txApp.Dao().SaveRecord(record)
+	})
+	if txErr != nil {
+		t.Fatal(txErr)
+	}
+
+	if hookDao == nil || hookDao == app.Dao() {
+		t.Fatal("Expected the before create hook to be called with the transactional Dao")
+	}
+
+	if hookDao.DB() != txDao.DB() {
+		t.Fatal("Expected the before create hook Dao to share the txApp transaction")
+	}
+}
+
+func countRecords(t *testing.T, dao *daos.Dao, collection *models.Collection) int {
+	var total int
+
+	err := dao.RecordQuery(collection).Select("count(*)").Row(&total)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return total
+}
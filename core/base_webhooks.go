@@ -0,0 +1,153 @@
+package core
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/unkod/space/models"
+	"github.com/unkod/space/models/schema"
+	"github.com/unkod/space/models/settings"
+	"github.com/unkod/space/tools/list"
+	"github.com/unkod/space/tools/security"
+	"github.com/unkod/space/tools/types"
+	"github.com/unkod/space/tools/webhook"
+)
+
+// list with the webhook request headers (in addition to [webhook.SignatureHeader])
+const (
+	WebhookHeaderName  string = "X-Webhook-Name"
+	WebhookHeaderEvent string = "X-Webhook-Event"
+	WebhookHeaderId    string = "X-Webhook-Id"
+)
+
+// WebhookPayload defines the json body of a single webhook record change delivery.
+type WebhookPayload struct {
+	Id         string         `json:"id"`
+	Event      string         `json:"event"`
+	Collection map[string]any `json:"collection"`
+	Record     map[string]any `json:"record"`
+	Created    types.DateTime `json:"created"`
+}
+
+// NewWebhookPayload creates a new webhook payload for the provided record event
+// with the record fields filtered according to the webhook config.
+func NewWebhookPayload(hook settings.WebhookConfig, event string, record *models.Record) *WebhookPayload {
+	clone := record.CleanCopy()
+	clone.IgnoreEmailVisibility(true)
+
+	data := clone.PublicExport()
+
+	if len(hook.Fields) > 0 {
+		for k := range data {
+			if k != schema.FieldNameId && !list.ExistInSlice(k, hook.Fields) {
+				delete(data, k)
+			}
+		}
+	}
+
+	for _, k := range hook.ExcludeFields {
+		if k != schema.FieldNameId {
+			delete(data, k)
+		}
+	}
+
+	return &WebhookPayload{
+		Id:    security.PseudorandomString(15),
+		Event: event,
+		Collection: map[string]any{
+			"id":   record.Collection().Id,
+			"name": record.Collection().Name,
+		},
+		Record:  data,
+		Created: types.NowDateTime(),
+	}
+}
+
+// initWebhooksHooks registers the settings configured
+// webhooks record after change hooks.
+func (app *BaseApp) initWebhooksHooks() {
+	app.webhookDispatcher.OnFailure = app.logWebhookFailure
+
+	send := func(event string, model models.Model) {
+		record, ok := model.(*models.Record)
+		if !ok || record.Collection() == nil {
+			return
+		}
+
+		config := app.Settings().Webhooks
+		if !config.Enabled {
+			return
+		}
+
+		hooks := config.FindHooks(event, record.Collection().Id, record.Collection().Name)
+
+		for _, hook := range hooks {
+			payload := NewWebhookPayload(hook, event, record)
+
+			body, err := json.Marshal(payload)
+			if err != nil {
+				app.Logger().Debug("Webhook payload serialization failed", "webhook", hook.Name, "error", err)
+				continue
+			}
+
+			msg := &webhook.Message{
+				Url:        hook.Url,
+				Secret:     hook.Secret,
+				Body:       body,
+				Timeout:    time.Duration(hook.Timeout) * time.Second,
+				MaxRetries: hook.MaxRetries,
+				Headers: map[string]string{
+					WebhookHeaderName:  hook.Name,
+					WebhookHeaderEvent: event,
+					WebhookHeaderId:    payload.Id,
+				},
+			}
+
+			if err := app.webhookDispatcher.Send(msg); err != nil {
+				app.logWebhookFailure(msg, 1, 0, err, true)
+			}
+		}
+	}
+
+	app.OnModelAfterCreate().Add(func(e *ModelEvent) error {
+		send(settings.WebhookEventCreate, e.Model)
+		return nil
+	})
+
+	app.OnModelAfterUpdate().Add(func(e *ModelEvent) error {
+		send(settings.WebhookEventUpdate, e.Model)
+		return nil
+	})
+
+	app.OnModelAfterDelete().Add(func(e *ModelEvent) error {
+		send(settings.WebhookEventDelete, e.Model)
+		return nil
+	})
+}
+
+// logWebhookFailure stores the failed webhook delivery attempt in the logs db.
+func (app *BaseApp) logWebhookFailure(msg *webhook.Message, attempt int, status int, err error, final bool) {
+	if app.Settings().Logs.MaxDays == 0 || !app.IsBootstrapped() {
+		return // logs are disabled
+	}
+
+	model := &models.Request{
+		Url:    msg.Url,
+		Method: "POST",
+		Status: status,
+		Auth:   models.RequestAuthGuest,
+		Meta: types.JsonMap{
+			"type":         "webhook",
+			"webhook":      msg.Headers[WebhookHeaderName],
+			"event":        msg.Headers[WebhookHeaderEvent],
+			"deliveryId":   msg.Headers[WebhookHeaderId],
+			"attempt":      attempt,
+			"final":        final,
+			"errorMessage": err.Error(),
+		},
+	}
+
+	if err := app.LogsDao().SaveRequest(model); err != nil {
+		app.Logger().Debug("Webhook failure log save failed", "error", err)
+	}
+}
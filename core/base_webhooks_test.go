@@ -0,0 +1,255 @@
+package core_test
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/pocketbase/dbx"
+	"github.com/unkod/space/core"
+	"github.com/unkod/space/models"
+	"github.com/unkod/space/models/settings"
+	"github.com/unkod/space/tests"
+	"github.com/unkod/space/tools/webhook"
+)
+
+func TestNewWebhookPayload(t *testing.T) {
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	record, err := app.Dao().FindRecordById("users", "4q1xlclmfloku33")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	scenarios := []struct {
+		hook            settings.WebhookConfig
+		expectedFields  []string
+		unexpectedField []string
+	}{
+		{
+			settings.WebhookConfig{},
+			[]string{"id", "email", "username", "name", "created"},
+			[]string{"passwordHash", "tokenKey"},
+		},
+		{
+			settings.WebhookConfig{Fields: []string{"name", "email"}},
+			[]string{"id", "name", "email"},
+			[]string{"username", "created", "passwordHash"},
+		},
+		{
+			settings.WebhookConfig{ExcludeFields: []string{"id", "email", "name"}},
+			[]string{"id", "username", "created"},
+			[]string{"email", "name"},
+		},
+	}
+
+	for i, s := range scenarios {
+		payload := core.NewWebhookPayload(s.hook, settings.WebhookEventUpdate, record)
+
+		if payload.Id == "" {
+			t.Errorf("(%d) Expected the payload id to be set", i)
+		}
+
+		if payload.Event != settings.WebhookEventUpdate {
+			t.Errorf("(%d) Expected event %q, got %q", i, settings.WebhookEventUpdate, payload.Event)
+		}
+
+		if payload.Collection["name"] != "users" {
+			t.Errorf("(%d) Expected the users collection, got %v", i, payload.Collection)
+		}
+
+		for _, f := range s.expectedFields {
+			if _, ok := payload.Record[f]; !ok {
+				t.Errorf("(%d) Expected field %q in %v", i, f, payload.Record)
+			}
+		}
+
+		for _, f := range s.unexpectedField {
+			if _, ok := payload.Record[f]; ok {
+				t.Errorf("(%d) Didn't expect field %q in %v", i, f, payload.Record)
+			}
+		}
+	}
+
+	// the original record shouldn't be modified
+	if record.Get("name") == nil {
+		t.Fatal("Expected the original record to remain unchanged")
+	}
+}
+
+func TestWebhooksDelivery(t *testing.T) {
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	type delivery struct {
+		header http.Header
+		body   []byte
+	}
+
+	deliveries := make(chan delivery, 10)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		deliveries <- delivery{r.Header, body}
+	}))
+	defer server.Close()
+
+	app.Settings().Webhooks = settings.WebhooksConfig{
+		Enabled: true,
+		Hooks: []settings.WebhookConfig{
+			{
+				Name:       "test",
+				Collection: "demo2",
+				Url:        server.URL,
+				Secret:     "1234567890",
+				Events:     []string{settings.WebhookEventCreate, settings.WebhookEventDelete},
+				Fields:     []string{"title"},
+			},
+		},
+	}
+
+	collection, err := app.Dao().FindCollectionByNameOrId("demo2")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	record := models.NewRecord(collection)
+	record.Set("title", "webhook_test")
+	if err := app.Dao().SaveRecord(record); err != nil {
+		t.Fatal(err)
+	}
+
+	// update is not subscribed
+	record.Set("title", "webhook_test2")
+	if err := app.Dao().SaveRecord(record); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := app.Dao().DeleteRecord(record); err != nil {
+		t.Fatal(err)
+	}
+
+	// the deliveries are processed concurrently so their order is not guaranteed
+	expectedTitles := map[string]string{
+		settings.WebhookEventCreate: "webhook_test",
+		settings.WebhookEventDelete: "webhook_test2",
+	}
+
+	for i := 0; i < 2; i++ {
+		select {
+		case d := <-deliveries:
+			if !webhook.VerifySignature(d.body, "1234567890", d.header.Get(webhook.SignatureHeader)) {
+				t.Fatalf("(%d) Invalid signature %q", i, d.header.Get(webhook.SignatureHeader))
+			}
+
+			if v := d.header.Get(core.WebhookHeaderName); v != "test" {
+				t.Fatalf("(%d) Expected webhook name header %q, got %q", i, "test", v)
+			}
+
+			payload := core.WebhookPayload{}
+			if err := json.Unmarshal(d.body, &payload); err != nil {
+				t.Fatal(err)
+			}
+
+			expectedTitle, ok := expectedTitles[payload.Event]
+			if !ok || d.header.Get(core.WebhookHeaderEvent) != payload.Event {
+				t.Fatalf("(%d) Unexpected event %q", i, payload.Event)
+			}
+			delete(expectedTitles, payload.Event)
+
+			if payload.Record["id"] != record.Id || payload.Record["title"] != expectedTitle || len(payload.Record) != 2 {
+				t.Fatalf("(%d) Unexpected payload record %v", i, payload.Record)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("(%d) Missing delivery", i)
+		}
+	}
+
+	select {
+	case d := <-deliveries:
+		t.Fatalf("Unexpected delivery %s", d.body)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
+
+func TestWebhooksFailureLog(t *testing.T) {
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer server.Close()
+
+	app.WebhookDispatcher().BaseBackoff = 10 * time.Millisecond
+
+	// enable logs
+	app.Settings().Logs.MaxDays = 5
+
+	app.Settings().Webhooks = settings.WebhooksConfig{
+		Enabled: true,
+		Hooks: []settings.WebhookConfig{
+			{
+				Name:       "test_fail",
+				Collection: "demo2",
+				Url:        server.URL,
+				Secret:     "1234567890",
+				MaxRetries: 1,
+			},
+		},
+	}
+
+	collection, err := app.Dao().FindCollectionByNameOrId("demo2")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	record := models.NewRecord(collection)
+	record.Set("title", "webhook_test")
+	if err := app.Dao().SaveRecord(record); err != nil {
+		t.Fatal(err)
+	}
+
+	var logs []*models.Request
+
+	// wait for the failed attempt and its retry to be logged
+	for i := 0; i < 50; i++ {
+		logs = nil
+
+		err := app.LogsDao().RequestQuery().
+			AndWhere(dbx.NewExp("json_extract([[meta]], '$.webhook') = 'test_fail'")).
+			OrderBy("rowid ASC").
+			All(&logs)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if len(logs) >= 2 {
+			break
+		}
+
+		time.Sleep(50 * time.Millisecond)
+	}
+
+	if len(logs) != 2 {
+		t.Fatalf("Expected 2 failure logs, got %d", len(logs))
+	}
+
+	for i, l := range logs {
+		if l.Url != server.URL || l.Status != http.StatusBadGateway {
+			t.Errorf("(%d) Unexpected log %v", i, l)
+		}
+
+		if l.Meta["event"] != settings.WebhookEventCreate {
+			t.Errorf("(%d) Expected create event meta, got %v", i, l.Meta)
+		}
+
+		if final, _ := l.Meta["final"].(bool); final != (i == 1) {
+			t.Errorf("(%d) Expected final %v, got %v", i, i == 1, l.Meta["final"])
+		}
+	}
+}
@@ -1,6 +1,9 @@
 package core
 
 import (
+	"fmt"
+	"os"
+
 	"github.com/pocketbase/dbx"
 	_ "modernc.org/sqlite"
 )
@@ -18,3 +21,20 @@ func connectDB(dbPath string) (*dbx.DB, error) {
 
 	return db, nil
 }
+
+// connectReadOnlyDB opens a query only connection to an existing db
+// (eg. a read replica that is synced by an external process).
+func connectReadOnlyDB(dbPath string) (*dbx.DB, error) {
+	if _, err := os.Stat(dbPath); err != nil {
+		return nil, fmt.Errorf("failed to open the read replica db: %w", err)
+	}
+
+	pragmas := "?_pragma=busy_timeout(10000)&_pragma=query_only(ON)&_pragma=foreign_keys(ON)&_pragma=temp_store(MEMORY)&_pragma=cache_size(-16000)"
+
+	db, err := dbx.Open("sqlite", dbPath+pragmas)
+	if err != nil {
+		return nil, err
+	}
+
+	return db, nil
+}
@@ -176,6 +176,13 @@ type RecordViewEvent struct {
 	Record      *models.Record
 }
 
+type RecordBeforeSendEvent struct {
+	BaseCollectionEvent
+
+	HttpContext echo.Context
+	Record      *models.Record
+}
+
 type RecordCreateEvent struct {
 	BaseCollectionEvent
 
@@ -184,6 +191,17 @@ type RecordCreateEvent struct {
 	UploadedFiles map[string][]*filesystem.File
 }
 
+type RecordsBulkCreateEvent struct {
+	BaseCollectionEvent
+
+	HttpContext echo.Context
+	Records     []*models.Record
+
+	// Atomic indicates whether all records are created in a single
+	// transaction (the default) or each record is persisted separately.
+	Atomic bool
+}
+
 type RecordUpdateEvent struct {
 	BaseCollectionEvent
 
@@ -199,6 +217,20 @@ type RecordDeleteEvent struct {
 	Record      *models.Record
 }
 
+type RecordSoftDeleteEvent struct {
+	BaseCollectionEvent
+
+	HttpContext echo.Context
+	Record      *models.Record
+}
+
+type RecordRestoreEvent struct {
+	BaseCollectionEvent
+
+	HttpContext echo.Context
+	Record      *models.Record
+}
+
 // -------------------------------------------------------------------
 // Auth Record API events data
 // -------------------------------------------------------------------
@@ -289,6 +321,16 @@ type RecordListExternalAuthsEvent struct {
 	ExternalAuths []*models.ExternalAuth
 }
 
+type RecordLinkExternalAuthEvent struct {
+	BaseCollectionEvent
+
+	HttpContext    echo.Context
+	Record         *models.Record
+	ExternalAuth   *models.ExternalAuth
+	OAuth2User     *auth.AuthUser
+	ProviderClient auth.Provider
+}
+
 type RecordUnlinkExternalAuthEvent struct {
 	BaseCollectionEvent
 
@@ -0,0 +1,114 @@
+package daos
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/pocketbase/dbx"
+	"github.com/unkod/space/models"
+)
+
+// ErrAuditLogChainBroken is returned by [Dao.VerifyAuditLogs]
+// when the audit logs hash chain doesn't match.
+var ErrAuditLogChainBroken = errors.New("the audit logs hash chain is broken")
+
+// AuditLogQuery returns a new AuditLog select query.
+func (dao *Dao) AuditLogQuery() *dbx.SelectQuery {
+	return dao.ModelQuery(&models.AuditLog{})
+}
+
+// RecordAuditLogQuery returns a new AuditLog select query
+// filtered to the history of a single record.
+func (dao *Dao) RecordAuditLogQuery(collectionId string, recordId string) *dbx.SelectQuery {
+	return dao.AuditLogQuery().AndWhere(dbx.HashExp{
+		"collectionId": collectionId,
+		"recordId":     recordId,
+	})
+}
+
+// FindRecordAuditLogs returns all audit logs of a single record
+// ordered from the oldest to the newest change.
+func (dao *Dao) FindRecordAuditLogs(collectionId string, recordId string) ([]*models.AuditLog, error) {
+	logs := []*models.AuditLog{}
+
+	err := dao.RecordAuditLogQuery(collectionId, recordId).
+		OrderBy("created ASC", "rowid ASC").
+		All(&logs)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return logs, nil
+}
+
+// SaveAuditLog inserts the provided new AuditLog model and
+// chains it to the last stored audit log.
+//
+// The audit logs are append-only and existing models can't be updated.
+func (dao *Dao) SaveAuditLog(log *models.AuditLog) error {
+	if !log.IsNew() {
+		return errors.New("the audit logs can't be modified")
+	}
+
+	return dao.RunInTransaction(func(txDao *Dao) error {
+		var prevHash string
+
+		err := txDao.AuditLogQuery().
+			Select("hash").
+			OrderBy("rowid DESC").
+			Limit(1).
+			Row(&prevHash)
+		if err != nil && !errors.Is(err, sql.ErrNoRows) {
+			return err
+		}
+
+		// the id and created date are part of the hash
+		// so they need to be initialized before the save
+		if !log.HasId() {
+			log.RefreshId()
+		}
+		if log.Created.IsZero() {
+			log.RefreshCreated()
+		}
+
+		log.PrevHash = prevHash
+		log.Hash = log.ComputeHash()
+
+		return txDao.Save(log)
+	})
+}
+
+// VerifyAuditLogs checks the entire audit logs hash chain in
+// insertion order and returns [ErrAuditLogChainBroken] (wrapped with
+// the id of the first mismatched log) if any of the logs was
+// modified, removed or inserted outside of [Dao.SaveAuditLog].
+func (dao *Dao) VerifyAuditLogs() error {
+	batchSize := 1000
+	prevHash := ""
+
+	for offset := 0; ; offset += batchSize {
+		logs := []*models.AuditLog{}
+
+		err := dao.AuditLogQuery().
+			OrderBy("rowid ASC").
+			Limit(int64(batchSize)).
+			Offset(int64(offset)).
+			All(&logs)
+		if err != nil {
+			return err
+		}
+
+		for _, log := range logs {
+			if log.PrevHash != prevHash || log.Hash != log.ComputeHash() {
+				return fmt.Errorf("%w (log %q)", ErrAuditLogChainBroken, log.Id)
+			}
+			prevHash = log.Hash
+		}
+
+		if len(logs) < batchSize {
+			return nil
+		}
+	}
+}
@@ -0,0 +1,190 @@
+package daos_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/pocketbase/dbx"
+	"github.com/unkod/space/daos"
+	"github.com/unkod/space/models"
+	"github.com/unkod/space/tests"
+	"github.com/unkod/space/tools/types"
+)
+
+func TestAuditLogQuery(t *testing.T) {
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	expected := "SELECT {{_audit}}.* FROM `_audit`"
+
+	sql := app.Dao().AuditLogQuery().Build().SQL()
+	if sql != expected {
+		t.Errorf("Expected sql %s, got %s", expected, sql)
+	}
+}
+
+func TestRecordAuditLogQuery(t *testing.T) {
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	expected := "SELECT {{_audit}}.* FROM `_audit` WHERE `collectionId`={:p0} AND `recordId`={:p1}"
+
+	sql := app.Dao().RecordAuditLogQuery("test_collection", "test_record").Build().SQL()
+	if sql != expected {
+		t.Errorf("Expected sql %s, got %s", expected, sql)
+	}
+}
+
+func TestSaveAuditLogAndFindRecordAuditLogs(t *testing.T) {
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	dates := []string{
+		"2023-01-02 10:00:00.000Z",
+		"2023-01-01 10:00:00.000Z",
+		"2023-01-03 10:00:00.000Z",
+	}
+
+	for i, date := range dates {
+		log := &models.AuditLog{
+			CollectionId: "test_collection",
+			RecordId:     "test_record",
+			Action:       models.AuditActionUpdate,
+			ActorType:    models.AuditActorSystem,
+			Changes:      types.JsonMap{"title": models.AuditChange{Old: i, New: i + 1}},
+		}
+		log.Created, _ = types.ParseDateTime(date)
+
+		if err := app.Dao().SaveAuditLog(log); err != nil {
+			t.Fatalf("(%d) Unexpected save error %v", i, err)
+		}
+	}
+
+	// another record log
+	if err := app.Dao().SaveAuditLog(&models.AuditLog{CollectionId: "test_collection", RecordId: "other"}); err != nil {
+		t.Fatal(err)
+	}
+
+	logs, err := app.Dao().FindRecordAuditLogs("test_collection", "test_record")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(logs) != len(dates) {
+		t.Fatalf("Expected %d logs, got %d", len(dates), len(logs))
+	}
+
+	expectedOrder := []string{dates[1], dates[0], dates[2]}
+	for i, log := range logs {
+		if log.Created.String() != expectedOrder[i] {
+			t.Errorf("(%d) Expected created %s, got %s", i, expectedOrder[i], log.Created.String())
+		}
+	}
+
+	// existing audit logs can't be modified
+	logs[0].Action = models.AuditActionDelete
+	if err := app.Dao().SaveAuditLog(logs[0]); err == nil {
+		t.Fatal("Expected the existing audit log update to fail")
+	}
+}
+
+func TestVerifyAuditLogs(t *testing.T) {
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	// empty chain
+	if err := app.Dao().VerifyAuditLogs(); err != nil {
+		t.Fatalf("Expected empty chain to be valid, got %v", err)
+	}
+
+	logs := make([]*models.AuditLog, 3)
+	for i := range logs {
+		logs[i] = &models.AuditLog{
+			CollectionId: "test_collection",
+			RecordId:     "test_record",
+			Action:       models.AuditActionUpdate,
+			ActorType:    models.AuditActorSystem,
+			Changes:      types.JsonMap{"title": models.AuditChange{Old: i, New: i + 1}},
+		}
+		if err := app.Dao().SaveAuditLog(logs[i]); err != nil {
+			t.Fatalf("(%d) Unexpected save error %v", i, err)
+		}
+	}
+
+	if logs[0].PrevHash != "" {
+		t.Fatalf("Expected the first log prevHash to be empty, got %q", logs[0].PrevHash)
+	}
+	for i := 1; i < len(logs); i++ {
+		if logs[i].PrevHash == "" || logs[i].PrevHash != logs[i-1].Hash {
+			t.Fatalf("(%d) Expected prevHash %q, got %q", i, logs[i-1].Hash, logs[i].PrevHash)
+		}
+	}
+
+	if err := app.Dao().VerifyAuditLogs(); err != nil {
+		t.Fatalf("Expected valid chain, got %v", err)
+	}
+
+	// tamper the middle log changes
+	_, err := app.Dao().DB().Update(
+		"_audit",
+		dbx.Params{"changes": `{"title":{"old":0,"new":100}}`},
+		dbx.HashExp{"id": logs[1].Id},
+	).Execute()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = app.Dao().VerifyAuditLogs()
+	if !errors.Is(err, daos.ErrAuditLogChainBroken) {
+		t.Fatalf("Expected ErrAuditLogChainBroken, got %v", err)
+	}
+	if !strings.Contains(err.Error(), logs[1].Id) {
+		t.Fatalf("Expected the error to contain the tampered log id %q, got %v", logs[1].Id, err)
+	}
+
+	// removed log
+	if _, err := app.Dao().DB().Delete("_audit", dbx.HashExp{"id": logs[1].Id}).Execute(); err != nil {
+		t.Fatal(err)
+	}
+
+	err = app.Dao().VerifyAuditLogs()
+	if !errors.Is(err, daos.ErrAuditLogChainBroken) || !strings.Contains(err.Error(), logs[2].Id) {
+		t.Fatalf("Expected ErrAuditLogChainBroken for log %q, got %v", logs[2].Id, err)
+	}
+}
+
+func TestSaveRecordAuditLogFailure(t *testing.T) {
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	tests.UpdateCollection(t, app, "demo2", func(c *models.Collection) { c.Options["audit"] = true })
+
+	// simulate audit log write failure
+	if _, err := app.Dao().DB().DropTable("_audit").Execute(); err != nil {
+		t.Fatal(err)
+	}
+
+	record, err := app.Dao().FindRecordById("demo2", "0yxhwia2amd8gec")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	record.Set("title", "test3_changed")
+	if err := app.Dao().SaveRecord(record); err == nil {
+		t.Fatal("Expected the save to fail")
+	}
+
+	if err := app.Dao().DeleteRecord(record); err == nil {
+		t.Fatal("Expected the delete to fail")
+	}
+
+	// the record changes should have been rolled back
+	record, err = app.Dao().FindRecordById("demo2", "0yxhwia2amd8gec")
+	if err != nil {
+		t.Fatalf("Expected the record to still exist, got %v", err)
+	}
+	if title := record.GetString("title"); title != "test3" {
+		t.Fatalf("Expected the record title to remain test3, got %q", title)
+	}
+}
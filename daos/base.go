@@ -4,6 +4,7 @@
 package daos
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"strings"
@@ -38,6 +39,10 @@ type Dao struct {
 	concurrentDB    dbx.Builder
 	nonconcurrentDB dbx.Builder
 
+	// optional read-only db (eg. a read replica) used by ReadDao()
+	// (it is not inherited by the transaction daos)
+	readDB dbx.Builder
+
 	// MaxLockRetries specifies the default max "database is locked" auto retry attempts.
 	MaxLockRetries int
 
@@ -46,6 +51,27 @@ type Dao struct {
 	// This field has no effect if an explicit query context is already specified.
 	ModelQueryTimeout time.Duration
 
+	// CollectionsCache is an optional cache used by FindCollectionByNameOrId.
+	//
+	// The cache is not shared with the transaction daos and it is
+	// up to the Dao creator to invalidate it on collection changes.
+	CollectionsCache *CollectionsCache
+
+	// EncryptionKeys is an optional list of 32 chars AES keys used for
+	// the encrypted record fields (see [schema.Encryptable]).
+	//
+	// The first key is used for encrypting the new values and the
+	// remaining ones are only tried when decrypting (eg. during a key rotation).
+	EncryptionKeys []string
+
+	// ExpandMaxDepth specifies the max allowed nested expand depth path
+	// (fallbacks to [DefaultMaxExpandDepth] if not set).
+	ExpandMaxDepth int
+
+	// IndirectExpandLimit specifies the max number of the back-relation
+	// records expanded per record (fallbacks to [DefaultIndirectExpandLimit] if not set).
+	IndirectExpandLimit int
+
 	// write hooks
 	BeforeCreateFunc func(eventDao *Dao, m models.Model, action func() error) error
 	AfterCreateFunc  func(eventDao *Dao, m models.Model) error
@@ -78,6 +104,48 @@ func (dao *Dao) NonconcurrentDB() dbx.Builder {
 	return dao.nonconcurrentDB
 }
 
+// ReadDB returns the dao read-only db builder (eg. a read replica)
+// or nil if there is no dedicated one (see [Dao.WithReadDB]).
+func (dao *Dao) ReadDB() dbx.Builder {
+	return dao.readDB
+}
+
+// WithReadDB returns a new Dao with the same configuration options
+// as the current one, but with a dedicated read-only db builder
+// (eg. a read replica) that is used by [Dao.ReadDao].
+func (dao *Dao) WithReadDB(readDB dbx.Builder) *Dao {
+	new := dao.Clone()
+
+	new.readDB = readDB
+
+	return new
+}
+
+// ReadDao returns a Dao for the read-only operations (eg. the records
+// list, view and count queries) that uses the dedicated read db builder
+// for both its concurrent and nonconcurrent queries.
+//
+// If there is no dedicated read db (including in a transaction)
+// the current Dao is returned, aka. the reads go to the primary db.
+//
+// Note that the read replicas are usually updated asynchronously and
+// the ReadDao queries could return stale data (eg. a record that was
+// just created may not be found yet). Use the regular Dao for any
+// read-then-write operations and wrap them in a transaction.
+func (dao *Dao) ReadDao() *Dao {
+	if dao.readDB == nil {
+		return dao
+	}
+
+	new := dao.Clone()
+
+	new.concurrentDB = dao.readDB
+	new.nonconcurrentDB = dao.readDB
+	new.readDB = nil
+
+	return new
+}
+
 // Clone returns a new Dao with the same configuration options as the current one.
 func (dao *Dao) Clone() *Dao {
 	clone := *dao
@@ -100,6 +168,30 @@ func (dao *Dao) WithoutHooks() *Dao {
 	return new
 }
 
+// WithContext returns a new Dao with the same configuration options
+// as the current one, but with db builders associated with ctx
+// (aka. its queries and transactions are cancelled together with ctx).
+//
+// The db builders that are not *dbx.DB (eg. an already started
+// transaction) are left unchanged.
+func (dao *Dao) WithContext(ctx context.Context) *Dao {
+	new := dao.Clone()
+
+	if db, ok := dao.concurrentDB.(*dbx.DB); ok {
+		new.concurrentDB = db.WithContext(ctx)
+	}
+
+	if db, ok := dao.nonconcurrentDB.(*dbx.DB); ok {
+		new.nonconcurrentDB = db.WithContext(ctx)
+	}
+
+	if db, ok := dao.readDB.(*dbx.DB); ok {
+		new.readDB = db.WithContext(ctx)
+	}
+
+	return new
+}
+
 // ModelQuery creates a new preconfigured select query with preset
 // SELECT, FROM and other common fields based on the provided model.
 func (dao *Dao) ModelQuery(m models.Model) *dbx.SelectQuery {
@@ -138,6 +230,9 @@ func (dao *Dao) RunInTransaction(fn func(txDao *Dao) error) error {
 		txDao := New(txOrDB)
 		txDao.MaxLockRetries = dao.MaxLockRetries
 		txDao.ModelQueryTimeout = dao.ModelQueryTimeout
+		txDao.EncryptionKeys = dao.EncryptionKeys
+		txDao.ExpandMaxDepth = dao.ExpandMaxDepth
+		txDao.IndirectExpandLimit = dao.IndirectExpandLimit
 		txDao.BeforeCreateFunc = dao.BeforeCreateFunc
 		txDao.BeforeUpdateFunc = dao.BeforeUpdateFunc
 		txDao.BeforeDeleteFunc = dao.BeforeDeleteFunc
@@ -151,6 +246,9 @@ func (dao *Dao) RunInTransaction(fn func(txDao *Dao) error) error {
 
 		txError := txOrDB.Transactional(func(tx *dbx.Tx) error {
 			txDao := New(tx)
+			txDao.EncryptionKeys = dao.EncryptionKeys
+			txDao.ExpandMaxDepth = dao.ExpandMaxDepth
+			txDao.IndirectExpandLimit = dao.IndirectExpandLimit
 
 			if dao.BeforeCreateFunc != nil {
 				txDao.BeforeCreateFunc = func(eventDao *Dao, m models.Model, action func() error) error {
@@ -285,6 +383,12 @@ func (dao *Dao) update(m models.Model) error {
 		if v, ok := any(m).(models.ColumnValueMapper); ok {
 			dataMap := v.ColumnValueMap()
 
+			if record, ok := m.(*models.Record); ok {
+				if err := dao.encryptRecordColumns(record.Collection(), dataMap); err != nil {
+					return err
+				}
+			}
+
 			_, err := dao.NonconcurrentDB().Update(
 				m.TableName(),
 				dataMap,
@@ -336,6 +440,12 @@ func (dao *Dao) create(m models.Model) error {
 				dataMap["id"] = m.GetId()
 			}
 
+			if record, ok := m.(*models.Record); ok {
+				if err := dao.encryptRecordColumns(record.Collection(), dataMap); err != nil {
+					return err
+				}
+			}
+
 			_, err := dao.NonconcurrentDB().Insert(m.TableName(), dataMap).Execute()
 			if err != nil {
 				return err
@@ -1,10 +1,12 @@
 package daos_test
 
 import (
+	"context"
 	"errors"
 	"testing"
 	"time"
 
+	"github.com/pocketbase/dbx"
 	"github.com/unkod/space/daos"
 	"github.com/unkod/space/models"
 	"github.com/unkod/space/tests"
@@ -245,6 +247,105 @@ func TestDaoModelQueryCancellation(t *testing.T) {
 	}
 }
 
+func TestDaoWithContext(t *testing.T) {
+	testApp, _ := tests.NewTestApp()
+	defer testApp.Cleanup()
+
+	dao := testApp.Dao()
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	ctxDao := dao.WithContext(ctx)
+
+	if ctxDao == dao {
+		t.Fatal("Expected a new Dao instance")
+	}
+
+	if ctxDao.CollectionsCache != dao.CollectionsCache {
+		t.Fatal("Expected the collections cache to be preserved")
+	}
+
+	m := &models.Admin{}
+
+	if err := ctxDao.ModelQuery(m).One(m); err != nil {
+		t.Fatalf("Failed to execute control query: %v", err)
+	}
+
+	cancel()
+
+	if err := ctxDao.ModelQuery(m).One(m); !errors.Is(err, context.Canceled) {
+		t.Fatalf("Expected context.Canceled error, got %v", err)
+	}
+
+	txErr := ctxDao.RunInTransaction(func(txDao *daos.Dao) error {
+		return nil
+	})
+	if !errors.Is(txErr, context.Canceled) {
+		t.Fatalf("Expected context.Canceled transaction error, got %v", txErr)
+	}
+
+	// the original dao should be unaffected
+	if err := dao.ModelQuery(m).One(m); err != nil {
+		t.Fatalf("Expected the original dao query to succeed, got %v", err)
+	}
+}
+
+func TestDaoReadDao(t *testing.T) {
+	testApp, _ := tests.NewTestApp()
+	defer testApp.Cleanup()
+
+	dao := daos.NewMultiDB(testApp.Dao().ConcurrentDB(), testApp.Dao().NonconcurrentDB())
+	dao.MaxLockRetries = 3
+
+	// no read db
+	if dao.ReadDB() != nil {
+		t.Fatal("Expected nil read db")
+	}
+	if dao.ReadDao() != dao {
+		t.Fatal("Expected the same dao instance without read db")
+	}
+
+	readDB := testApp.Dao().ConcurrentDB().(*dbx.DB).WithContext(context.Background())
+
+	withRead := dao.WithReadDB(readDB)
+	if withRead == dao {
+		t.Fatal("Expected a new Dao instance")
+	}
+	if dao.ReadDB() != nil {
+		t.Fatal("Expected the original dao to be unaffected")
+	}
+	if withRead.ReadDB() != readDB {
+		t.Fatal("Expected the read db to be set")
+	}
+	if withRead.DB() != dao.DB() || withRead.NonconcurrentDB() != dao.NonconcurrentDB() {
+		t.Fatal("Expected the primary db builders to remain unchanged")
+	}
+
+	readDao := withRead.ReadDao()
+	if readDao.ConcurrentDB() != readDB || readDao.NonconcurrentDB() != readDB {
+		t.Fatal("Expected both read dao db builders to be the read db")
+	}
+	if readDao.MaxLockRetries != 3 {
+		t.Fatal("Expected the read dao to preserve the dao options")
+	}
+
+	// WithContext should also bind the read db
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	m := &models.Admin{}
+	if err := withRead.WithContext(ctx).ReadDao().ModelQuery(m).One(m); !errors.Is(err, context.Canceled) {
+		t.Fatalf("Expected context.Canceled error, got %v", err)
+	}
+
+	// transactions shouldn't inherit the read db
+	withRead.RunInTransaction(func(txDao *daos.Dao) error {
+		if txDao.ReadDB() != nil || txDao.ReadDao() != txDao {
+			t.Fatal("Expected the tx dao to not have a read db")
+		}
+		return nil
+	})
+}
+
 func TestDaoFindById(t *testing.T) {
 	testApp, _ := tests.NewTestApp()
 	defer testApp.Cleanup()
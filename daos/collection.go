@@ -35,7 +35,17 @@ func (dao *Dao) FindCollectionsByType(collectionType string) ([]*models.Collecti
 }
 
 // FindCollectionByNameOrId finds a single collection by its name (case insensitive) or id.
+//
+// If dao.CollectionsCache is set, the cached collection is returned (if any).
 func (dao *Dao) FindCollectionByNameOrId(nameOrId string) (*models.Collection, error) {
+	var cacheVersion uint64
+	if dao.CollectionsCache != nil {
+		if cached, ok := dao.CollectionsCache.Get(nameOrId); ok {
+			return cached, nil
+		}
+		cacheVersion = dao.CollectionsCache.Version()
+	}
+
 	model := &models.Collection{}
 
 	err := dao.CollectionQuery().
@@ -50,6 +60,10 @@ func (dao *Dao) FindCollectionByNameOrId(nameOrId string) (*models.Collection, e
 		return nil, err
 	}
 
+	if dao.CollectionsCache != nil {
+		dao.CollectionsCache.Set(model, cacheVersion)
+	}
+
 	return model, nil
 }
 
@@ -148,6 +162,10 @@ func (dao *Dao) DeleteCollection(collection *models.Collection) error {
 			if err := txDao.DeleteTable(collection.Name); err != nil {
 				return err
 			}
+
+			if err := txDao.dropRecordsSearchIndex(collection); err != nil {
+				return err
+			}
 		}
 
 		// trigger views resave to check for dependencies
@@ -297,6 +315,10 @@ func (dao *Dao) ImportCollections(
 					if err := txDao.DeleteTable(existing.Name); err != nil {
 						return err
 					}
+
+					if err := txDao.dropRecordsSearchIndex(existing); err != nil {
+						return err
+					}
 				}
 
 				// delete the collection
@@ -0,0 +1,176 @@
+package daos
+
+import (
+	"container/list"
+	"encoding/json"
+	"strings"
+	"sync"
+
+	"github.com/unkod/space/models"
+)
+
+// DefaultCollectionsCacheMaxSize is the default max number of
+// collections that could be stored in a [CollectionsCache].
+const DefaultCollectionsCacheMaxSize = 500
+
+// CollectionsCache is a concurrent safe LRU cache for collection models,
+// indexed by their id and name (case insensitive).
+//
+// The cache stores and returns copies of the collection models so that
+// changing the returned collections doesn't affect the cached state.
+type CollectionsCache struct {
+	mux     sync.Mutex
+	maxSize int
+	version uint64
+	items   *list.List
+	ids     map[string]*list.Element
+	names   map[string]*list.Element
+}
+
+type collectionsCacheItem struct {
+	id   string
+	name string
+	raw  []byte
+}
+
+// NewCollectionsCache creates a new CollectionsCache instance that
+// could store up to maxSize collections (nonpositive values fallback
+// to [DefaultCollectionsCacheMaxSize]).
+func NewCollectionsCache(maxSize int) *CollectionsCache {
+	if maxSize <= 0 {
+		maxSize = DefaultCollectionsCacheMaxSize
+	}
+
+	return &CollectionsCache{
+		maxSize: maxSize,
+		items:   list.New(),
+		ids:     map[string]*list.Element{},
+		names:   map[string]*list.Element{},
+	}
+}
+
+// Version returns the current cache invalidation version.
+//
+// The version is incremented on each [CollectionsCache.Remove] and
+// [CollectionsCache.Reset] call and it is used by [CollectionsCache.Set]
+// to prevent caching collections that were loaded before an invalidation.
+func (c *CollectionsCache) Version() uint64 {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+
+	return c.version
+}
+
+// Get returns a copy of the cached collection with the specified id or name.
+func (c *CollectionsCache) Get(nameOrId string) (*models.Collection, bool) {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+
+	elem, ok := c.ids[nameOrId]
+	if !ok {
+		elem, ok = c.names[strings.ToLower(nameOrId)]
+	}
+	if !ok {
+		return nil, false
+	}
+
+	collection := &models.Collection{}
+	if err := json.Unmarshal(elem.Value.(*collectionsCacheItem).raw, collection); err != nil {
+		c.removeElement(elem)
+		return nil, false
+	}
+	collection.MarkAsNotNew()
+
+	c.items.MoveToFront(elem)
+
+	return collection, true
+}
+
+// Set stores a copy of the provided collection in the cache,
+// evicting the least recently used one if the cache is full.
+//
+// The collection is not stored if the cache was invalidated after
+// the specified version (see [CollectionsCache.Version]).
+func (c *CollectionsCache) Set(collection *models.Collection, version uint64) {
+	raw, err := json.Marshal(collection)
+	if err != nil {
+		return
+	}
+
+	c.mux.Lock()
+	defer c.mux.Unlock()
+
+	if version != c.version {
+		return // stale
+	}
+
+	c.remove(collection)
+
+	item := &collectionsCacheItem{
+		id:   collection.Id,
+		name: strings.ToLower(collection.Name),
+		raw:  raw,
+	}
+
+	elem := c.items.PushFront(item)
+	c.ids[item.id] = elem
+	c.names[item.name] = elem
+
+	for c.items.Len() > c.maxSize {
+		c.removeElement(c.items.Back())
+	}
+}
+
+// Remove removes the cached entries matching the provided collection id or name.
+func (c *CollectionsCache) Remove(collection *models.Collection) {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+
+	c.version++
+
+	c.remove(collection)
+}
+
+// Reset removes all cached collections.
+func (c *CollectionsCache) Reset() {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+
+	c.version++
+
+	c.items.Init()
+	c.ids = map[string]*list.Element{}
+	c.names = map[string]*list.Element{}
+}
+
+// Len returns the number of the cached collections.
+func (c *CollectionsCache) Len() int {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+
+	return c.items.Len()
+}
+
+func (c *CollectionsCache) remove(collection *models.Collection) {
+	if elem, ok := c.ids[collection.Id]; ok {
+		c.removeElement(elem)
+	}
+
+	if elem, ok := c.names[strings.ToLower(collection.Name)]; ok {
+		c.removeElement(elem)
+	}
+}
+
+func (c *CollectionsCache) removeElement(elem *list.Element) {
+	item := elem.Value.(*collectionsCacheItem)
+
+	if c.ids[item.id] == elem {
+		delete(c.ids, item.id)
+	}
+
+	if c.names[item.name] == elem {
+		delete(c.names, item.name)
+	}
+
+	c.items.Remove(elem)
+}
@@ -0,0 +1,131 @@
+package daos_test
+
+import (
+	"testing"
+
+	"github.com/unkod/space/daos"
+	"github.com/unkod/space/models"
+)
+
+func TestCollectionsCacheGetSet(t *testing.T) {
+	cache := daos.NewCollectionsCache(0)
+
+	c := &models.Collection{Name: "Demo"}
+	c.Id = "test_id"
+	c.MarkAsNotNew()
+
+	if _, ok := cache.Get("test_id"); ok {
+		t.Fatal("Expected cache miss for an empty cache")
+	}
+
+	cache.Set(c, cache.Version())
+
+	for _, key := range []string{"test_id", "Demo", "demo", "DEMO"} {
+		cached, ok := cache.Get(key)
+		if !ok {
+			t.Fatalf("[%s] Expected cache hit", key)
+		}
+
+		if cached == c {
+			t.Fatalf("[%s] Expected a copy of the cached collection", key)
+		}
+
+		if cached.Id != c.Id || cached.Name != c.Name || cached.IsNew() {
+			t.Fatalf("[%s] Expected %v, got %v", key, c, cached)
+		}
+
+		// changing the returned copy shouldn't affect the cache
+		cached.Name = "changed"
+	}
+
+	if _, ok := cache.Get("changed"); ok {
+		t.Fatal("Expected cache miss for the changed copy name")
+	}
+}
+
+func TestCollectionsCacheSetStaleVersion(t *testing.T) {
+	cache := daos.NewCollectionsCache(0)
+
+	c := &models.Collection{Name: "demo"}
+	c.Id = "test_id"
+
+	version := cache.Version()
+
+	cache.Remove(c)
+
+	cache.Set(c, version)
+
+	if _, ok := cache.Get("test_id"); ok {
+		t.Fatal("Expected the stale collection to not be cached")
+	}
+}
+
+func TestCollectionsCacheRemove(t *testing.T) {
+	cache := daos.NewCollectionsCache(0)
+
+	c1 := &models.Collection{Name: "demo1"}
+	c1.Id = "id1"
+	cache.Set(c1, cache.Version())
+
+	c2 := &models.Collection{Name: "demo2"}
+	c2.Id = "id2"
+	cache.Set(c2, cache.Version())
+
+	// renamed collection
+	renamed := &models.Collection{Name: "new_name"}
+	renamed.Id = "id1"
+	cache.Remove(renamed)
+
+	for _, key := range []string{"id1", "demo1"} {
+		if _, ok := cache.Get(key); ok {
+			t.Fatalf("[%s] Expected the collection to be removed", key)
+		}
+	}
+
+	if _, ok := cache.Get("demo2"); !ok {
+		t.Fatal("Expected demo2 to remain cached")
+	}
+
+	cache.Reset()
+
+	if total := cache.Len(); total != 0 {
+		t.Fatalf("Expected empty cache after reset, got %d items", total)
+	}
+}
+
+func TestCollectionsCacheEviction(t *testing.T) {
+	cache := daos.NewCollectionsCache(2)
+
+	for _, name := range []string{"c1", "c2"} {
+		c := &models.Collection{Name: name}
+		c.Id = name + "_id"
+		cache.Set(c, cache.Version())
+	}
+
+	// mark c1 as recently used
+	cache.Get("c1")
+
+	c3 := &models.Collection{Name: "c3"}
+	c3.Id = "c3_id"
+	cache.Set(c3, cache.Version())
+
+	if total := cache.Len(); total != 2 {
+		t.Fatalf("Expected 2 cached collections, got %d", total)
+	}
+
+	scenarios := []struct {
+		key      string
+		expected bool
+	}{
+		{"c1", true},
+		{"c2", false},
+		{"c2_id", false},
+		{"c3", true},
+	}
+
+	for _, s := range scenarios {
+		if _, ok := cache.Get(s.key); ok != s.expected {
+			t.Errorf("[%s] Expected cached %v, got %v", s.key, s.expected, ok)
+		}
+	}
+}
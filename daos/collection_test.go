@@ -91,6 +91,53 @@ func TestFindCollectionByNameOrId(t *testing.T) {
 	}
 }
 
+func TestFindCollectionByNameOrIdWithCache(t *testing.T) {
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	cache := app.Dao().CollectionsCache
+	if cache == nil {
+		t.Fatal("Expected the app dao to have a collections cache")
+	}
+	cache.Reset()
+
+	collection, err := app.Dao().FindCollectionByNameOrId("demo1")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, ok := cache.Get(collection.Id); !ok {
+		t.Fatal("Expected the found collection to be cached")
+	}
+
+	// the transaction daos shouldn't use the cache
+	err = app.Dao().RunInTransaction(func(txDao *daos.Dao) error {
+		if txDao.CollectionsCache != nil {
+			t.Fatal("Expected the transaction dao to not have a collections cache")
+		}
+
+		collection.Name = "demo1_renamed"
+		return txDao.SaveCollection(collection)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// should be invalidated after the transaction commit
+	if _, ok := cache.Get("demo1"); ok {
+		t.Fatal("Expected the old collection name to be invalidated")
+	}
+
+	updated, err := app.Dao().FindCollectionByNameOrId(collection.Id)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if updated.Name != "demo1_renamed" {
+		t.Fatalf("Expected the renamed collection, got %q", updated.Name)
+	}
+}
+
 func TestIsCollectionNameUnique(t *testing.T) {
 	app, _ := tests.NewTestApp()
 	defer app.Cleanup()
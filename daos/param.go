@@ -40,18 +40,31 @@ func (dao *Dao) SaveParam(key string, value any, optEncryptionKey ...string) err
 		param = &models.Param{Key: key}
 	}
 
+	encodedValue, err := encodeParamValue(value, optEncryptionKey...)
+	if err != nil {
+		return err
+	}
+
+	param.Value = encodedValue
+
+	return dao.Save(param)
+}
+
+// encodeParamValue encodes the provided value as json
+// and optionally encrypts it with optEncryptionKey.
+func encodeParamValue(value any, optEncryptionKey ...string) (types.JsonRaw, error) {
 	normalizedValue := value
 
 	// encrypt if optEncryptionKey is set
 	if len(optEncryptionKey) > 0 && optEncryptionKey[0] != "" {
 		encoded, encodingErr := json.Marshal(value)
 		if encodingErr != nil {
-			return encodingErr
+			return nil, encodingErr
 		}
 
 		encryptVal, encryptErr := security.Encrypt(encoded, optEncryptionKey[0])
 		if encryptErr != nil {
-			return encryptErr
+			return nil, encryptErr
 		}
 
 		normalizedValue = encryptVal
@@ -59,12 +72,10 @@ func (dao *Dao) SaveParam(key string, value any, optEncryptionKey ...string) err
 
 	encodedValue := types.JsonRaw{}
 	if err := encodedValue.Scan(normalizedValue); err != nil {
-		return err
+		return nil, err
 	}
 
-	param.Value = encodedValue
-
-	return dao.Save(param)
+	return encodedValue, nil
 }
 
 // DeleteParam deletes the provided Param model.
@@ -12,6 +12,7 @@ import (
 	"github.com/unkod/space/models"
 	"github.com/unkod/space/models/schema"
 	"github.com/unkod/space/resolvers"
+	"github.com/unkod/space/tools/dbutils"
 	"github.com/unkod/space/tools/inflector"
 	"github.com/unkod/space/tools/list"
 	"github.com/unkod/space/tools/search"
@@ -76,6 +77,8 @@ func (dao *Dao) RecordQuery(collectionModelOrIdentifier any) *dbx.SelectQuery {
 						return err
 					}
 
+					dao.decryptRecordRow(collection, row)
+
 					record := models.NewRecordFromNullStringMap(collection, row)
 
 					*v = *record
@@ -97,6 +100,10 @@ func (dao *Dao) RecordQuery(collectionModelOrIdentifier any) *dbx.SelectQuery {
 						return err
 					}
 
+					for _, row := range rows {
+						dao.decryptRecordRow(collection, row)
+					}
+
 					records := models.NewRecordsFromNullStringMaps(collection, rows)
 
 					*v = records
@@ -112,6 +119,10 @@ func (dao *Dao) RecordQuery(collectionModelOrIdentifier any) *dbx.SelectQuery {
 						return err
 					}
 
+					for _, row := range rows {
+						dao.decryptRecordRow(collection, row)
+					}
+
 					records := models.NewRecordsFromNullStringMaps(collection, rows)
 
 					nonPointers := make([]models.Record, len(records))
@@ -161,8 +172,16 @@ func (dao *Dao) FindRecordById(
 	return record, nil
 }
 
+// maxRecordIdsPerQuery is the max number of record ids that are bound
+// in a single FindRecordsByIds query, leaving enough room for the
+// optional filters params below the SQLite bound parameters limit.
+const maxRecordIdsPerQuery = 500
+
 // FindRecordsByIds finds all Record models by the provided ids.
 // If no records are found, returns an empty slice.
+//
+// Large ids lists are fetched in chunks of [maxRecordIdsPerQuery] ids
+// (the optional filters are applied to each chunk query).
 func (dao *Dao) FindRecordsByIds(
 	collectionNameOrId string,
 	recordIds []string,
@@ -173,25 +192,38 @@ func (dao *Dao) FindRecordsByIds(
 		return nil, err
 	}
 
-	query := dao.RecordQuery(collection).
-		AndWhere(dbx.In(
-			collection.Name+".id",
-			list.ToInterfaceSlice(recordIds)...,
-		))
+	recordIds = list.ToUniqueStringSlice(recordIds)
 
-	for _, filter := range optFilters {
-		if filter == nil {
-			continue
+	records := make([]*models.Record, 0, len(recordIds))
+
+	for start := 0; start == 0 || start < len(recordIds); start += maxRecordIdsPerQuery {
+		end := start + maxRecordIdsPerQuery
+		if end > len(recordIds) {
+			end = len(recordIds)
 		}
-		if err := filter(query); err != nil {
-			return nil, err
+
+		query := dao.RecordQuery(collection).
+			AndWhere(dbx.In(
+				collection.Name+".id",
+				list.ToInterfaceSlice(recordIds[start:end])...,
+			))
+
+		for _, filter := range optFilters {
+			if filter == nil {
+				continue
+			}
+			if err := filter(query); err != nil {
+				return nil, err
+			}
 		}
-	}
 
-	records := make([]*models.Record, 0, len(recordIds))
+		chunk := make([]*models.Record, 0, end-start)
 
-	if err := query.All(&records); err != nil {
-		return nil, err
+		if err := query.All(&chunk); err != nil {
+			return nil, err
+		}
+
+		records = append(records, chunk...)
 	}
 
 	return records, nil
@@ -356,6 +388,95 @@ func (dao *Dao) FindFirstRecordByFilter(
 	return result[0], nil
 }
 
+// eachRecordBatchSize is the max number of records
+// loaded in memory by a single EachRecord query.
+const eachRecordBatchSize = 500
+
+// EachRecord iterates over all collection records matching the provided
+// string filter and calls fn for each of them (ordered by their id).
+//
+// An empty filter matches all collection records.
+//
+// The records are fetched in batches of [eachRecordBatchSize] using
+// keyset pagination on the record id (aka. "id > lastBatchId" instead of OFFSET),
+// so the memory usage is limited to a single batch of records regardless of
+// the collection size and the batch queries don't slow down for the later pages.
+// Records created or updated while iterating are visited only if
+// their id is greater than the currently processed one.
+//
+// The iteration stops on the first fn error and returns it.
+//
+// NB! Use the last "params" argument to bind untrusted user variables!
+//
+// Example:
+//
+//	err := dao.EachRecord("posts", "status = {:status}", func(record *models.Record) error {
+//		// process record...
+//		return nil
+//	}, dbx.Params{"status": "public"})
+func (dao *Dao) EachRecord(
+	collectionNameOrId string,
+	filter string,
+	fn func(record *models.Record) error,
+	params ...dbx.Params,
+) error {
+	collection, err := dao.FindCollectionByNameOrId(collectionNameOrId)
+	if err != nil {
+		return err
+	}
+
+	resolver := resolvers.NewRecordFieldResolver(
+		dao,
+		collection, // the base collection
+		nil,        // no request data
+		true,       // allow searching hidden/protected fields like "email"
+	)
+
+	var filterExpr dbx.Expression
+	if filter != "" {
+		filterExpr, err = search.FilterData(filter).BuildExpr(resolver, params...)
+		if err != nil {
+			return err
+		}
+	}
+
+	idColumn := fmt.Sprintf("[[%s.%s]]", inflector.Columnify(collection.Name), schema.FieldNameId)
+
+	var lastId string
+
+	for {
+		q := dao.RecordQuery(collection).
+			AndWhere(dbx.NewExp(idColumn+" > {:eachRecordLastId}", dbx.Params{"eachRecordLastId": lastId})).
+			OrderBy(idColumn + " ASC").
+			Limit(eachRecordBatchSize)
+
+		if filterExpr != nil {
+			q.AndWhere(filterExpr)
+		}
+
+		if err := resolver.UpdateQuery(q); err != nil {
+			return err
+		}
+
+		records := []*models.Record{}
+		if err := q.All(&records); err != nil {
+			return err
+		}
+
+		for _, record := range records {
+			if err := fn(record); err != nil {
+				return err
+			}
+		}
+
+		if len(records) < eachRecordBatchSize {
+			return nil
+		}
+
+		lastId = records[len(records)-1].Id
+	}
+}
+
 // IsRecordValueUnique checks if the provided key-value pair is a unique Record value.
 //
 // For correctness, if the collection is "auth" and the key is "username",
@@ -392,6 +513,16 @@ func (dao *Dao) IsRecordValueUnique(
 			normalizedVal = val
 		}
 
+		// compare with the stored cipher text
+		// (the text fields are encrypted deterministically)
+		if field := collection.Schema.GetFieldByName(key); IsEncryptedField(field) {
+			encrypted, err := dao.EncryptFieldValue(field, normalizedVal)
+			if err != nil {
+				return false
+			}
+			normalizedVal = encrypted
+		}
+
 		expr = dbx.HashExp{inflector.Columnify(key): normalizedVal}
 	}
 
@@ -409,9 +540,123 @@ func (dao *Dao) IsRecordValueUnique(
 	return query.Row(&exists) == nil && !exists
 }
 
+// FindUniqueConflictRecords returns the distinct collection records that
+// conflict with the provided data on the record id or on any of the
+// collection unique indexes (including the system auth ones).
+//
+// Only the unique indexes whose columns are all present in data are checked
+// (composite indexes with missing columns and expression indexes are skipped).
+// The WHERE condition of the partial indexes is also applied.
+//
+// For correctness, if the collection is "auth" and the key is "username",
+// the conflict check will be case insensitive.
+func (dao *Dao) FindUniqueConflictRecords(collection *models.Collection, data map[string]any) ([]*models.Record, error) {
+	// normalize the submitted values
+	record := models.NewRecord(collection)
+	record.Load(data)
+	values := record.ColumnValueMap()
+	if err := dao.encryptRecordColumns(collection, values); err != nil {
+		return nil, err
+	}
+
+	indexes := make([]dbutils.Index, 0, len(collection.Indexes)+3)
+
+	if id := cast.ToString(data[schema.FieldNameId]); id != "" {
+		values[schema.FieldNameId] = id
+		indexes = append(indexes, dbutils.Index{
+			Unique:  true,
+			Columns: []dbutils.IndexColumn{{Name: schema.FieldNameId}},
+		})
+	}
+
+	if collection.IsAuth() {
+		indexes = append(
+			indexes,
+			dbutils.Index{
+				Unique:  true,
+				Columns: []dbutils.IndexColumn{{Name: schema.FieldNameUsername}},
+			},
+			dbutils.Index{
+				Unique:  true,
+				Columns: []dbutils.IndexColumn{{Name: schema.FieldNameEmail}},
+				Where:   "[[" + schema.FieldNameEmail + "]] != ''",
+			},
+		)
+	}
+
+	for _, raw := range collection.Indexes {
+		if idx := dbutils.ParseIndex(cast.ToString(raw)); idx.IsValid() && idx.Unique {
+			indexes = append(indexes, idx)
+		}
+	}
+
+	conditions := make([]dbx.Expression, 0, len(indexes))
+
+	for i, idx := range indexes {
+		exprs := make([]dbx.Expression, 0, len(idx.Columns)+1)
+
+		for j, col := range idx.Columns {
+			if _, ok := data[col.Name]; !ok {
+				break // not submitted or an expression
+			}
+
+			value, ok := values[col.Name]
+			if !ok {
+				break // unknown column
+			}
+
+			param := fmt.Sprintf("conflict%d_%d", i, j)
+
+			if collection.IsAuth() && col.Name == schema.FieldNameUsername {
+				exprs = append(exprs, dbx.NewExp(
+					"LOWER([["+col.Name+"]])={:"+param+"}",
+					dbx.Params{param: strings.ToLower(cast.ToString(value))},
+				))
+				continue
+			}
+
+			var collate string
+			if col.Collate != "" {
+				collate = " COLLATE " + col.Collate
+			}
+
+			exprs = append(exprs, dbx.NewExp(
+				"[["+col.Name+"]]={:"+param+"}"+collate,
+				dbx.Params{param: value},
+			))
+		}
+
+		if len(exprs) != len(idx.Columns) {
+			continue // not all index columns are submitted
+		}
+
+		if idx.Where != "" {
+			exprs = append(exprs, dbx.NewExp(idx.Where))
+		}
+
+		conditions = append(conditions, dbx.And(exprs...))
+	}
+
+	records := []*models.Record{}
+
+	if len(conditions) == 0 {
+		return records, nil // nothing to check
+	}
+
+	err := dao.RecordQuery(collection).
+		AndWhere(dbx.Or(conditions...)).
+		All(&records)
+	if err != nil {
+		return nil, err
+	}
+
+	return records, nil
+}
+
 // FindAuthRecordByToken finds the auth record associated with the provided JWT token.
 //
-// Returns an error if the JWT token is invalid, expired or not associated to an auth collection record.
+// Returns an error if the JWT token is invalid, expired or not associated to
+// an auth collection record (or the record is soft-deleted).
 func (dao *Dao) FindAuthRecordByToken(token string, baseTokenKey string) (*models.Record, error) {
 	unverifiedClaims, err := security.ParseUnverifiedJWT(token)
 	if err != nil {
@@ -434,6 +679,10 @@ func (dao *Dao) FindAuthRecordByToken(token string, baseTokenKey string) (*model
 		return nil, errors.New("The token is not associated to an auth collection record.")
 	}
 
+	if record.IsSoftDeleted() {
+		return nil, errors.New("The token is associated to a soft-deleted auth record.")
+	}
+
 	verificationKey := record.TokenKey() + baseTokenKey
 
 	// verify token signature
@@ -446,7 +695,8 @@ func (dao *Dao) FindAuthRecordByToken(token string, baseTokenKey string) (*model
 
 // FindAuthRecordByEmail finds the auth record associated with the provided email.
 //
-// Returns an error if it is not an auth collection or the record is not found.
+// Returns an error if it is not an auth collection or the record is not found
+// (soft-deleted records are treated as not found).
 func (dao *Dao) FindAuthRecordByEmail(collectionNameOrId string, email string) (*models.Record, error) {
 	collection, err := dao.FindCollectionByNameOrId(collectionNameOrId)
 	if err != nil {
@@ -466,12 +716,17 @@ func (dao *Dao) FindAuthRecordByEmail(collectionNameOrId string, email string) (
 		return nil, err
 	}
 
+	if record.IsSoftDeleted() {
+		return nil, sql.ErrNoRows
+	}
+
 	return record, nil
 }
 
 // FindAuthRecordByUsername finds the auth record associated with the provided username (case insensitive).
 //
-// Returns an error if it is not an auth collection or the record is not found.
+// Returns an error if it is not an auth collection or the record is not found
+// (soft-deleted records are treated as not found).
 func (dao *Dao) FindAuthRecordByUsername(collectionNameOrId string, username string) (*models.Record, error) {
 	collection, err := dao.FindCollectionByNameOrId(collectionNameOrId)
 	if err != nil {
@@ -493,6 +748,10 @@ func (dao *Dao) FindAuthRecordByUsername(collectionNameOrId string, username str
 		return nil, err
 	}
 
+	if record.IsSoftDeleted() {
+		return nil, sql.ErrNoRows
+	}
+
 	return record, nil
 }
 
@@ -584,6 +843,9 @@ func (dao *Dao) CanAccessRecord(record *models.Record, requestInfo *models.Reque
 //
 // If record.IsNew() is true, the method will perform a create, otherwise an update.
 // To explicitly mark a record for update you can use record.MarkAsNotNew().
+//
+// The changes of the audited collection records are stored in
+// the audit trail as part of the same transaction.
 func (dao *Dao) SaveRecord(record *models.Record) error {
 	if record.Collection().IsAuth() {
 		if record.Username() == "" {
@@ -607,7 +869,24 @@ func (dao *Dao) SaveRecord(record *models.Record) error {
 		}
 	}
 
-	return dao.Save(record)
+	if !record.Collection().HasAudit() {
+		return dao.Save(record)
+	}
+
+	action := models.AuditActionUpdate
+	oldRecord := record.OriginalCopy()
+	if record.IsNew() {
+		action = models.AuditActionCreate
+		oldRecord = nil
+	}
+
+	return dao.RunInTransaction(func(txDao *Dao) error {
+		if err := txDao.Save(record); err != nil {
+			return err
+		}
+
+		return txDao.saveRecordAuditLog(action, record, oldRecord, record)
+	})
 }
 
 // DeleteRecord deletes the provided Record model.
@@ -617,6 +896,9 @@ func (dao *Dao) SaveRecord(record *models.Record) error {
 //
 // The delete operation may fail if the record is part of a required
 // reference in another record (aka. cannot be deleted or unset).
+//
+// The delete of the audited collection records is stored in
+// the audit trail as part of the same transaction.
 func (dao *Dao) DeleteRecord(record *models.Record) error {
 	// fetch rel references (if any)
 	//
@@ -650,6 +932,12 @@ func (dao *Dao) DeleteRecord(record *models.Record) error {
 			return err
 		}
 
+		if record.Collection().HasAudit() {
+			if err := txDao.saveRecordAuditLog(models.AuditActionDelete, record, record, nil); err != nil {
+				return err
+			}
+		}
+
 		return txDao.cascadeRecordDelete(record, refs)
 	})
 }
@@ -697,6 +985,10 @@ func (dao *Dao) cascadeRecordDelete(mainRecord *models.Record, refs map[*models.
 					break
 				}
 
+				for _, row := range rows {
+					dao.decryptRecordRow(refCollection, row)
+				}
+
 				refRecords := models.NewRecordsFromNullStringMaps(refCollection, rows)
 
 				err := dao.deleteRefRecords(mainRecord, refRecords, field)
@@ -0,0 +1,116 @@
+package daos
+
+import (
+	"encoding/json"
+
+	"github.com/unkod/space/models"
+	"github.com/unkod/space/models/schema"
+	"github.com/unkod/space/tools/list"
+	"github.com/unkod/space/tools/types"
+)
+
+// saveRecordAuditLog stores the audit log of a single record change.
+//
+// oldRecord is nil for newly created records and newRecord is nil for
+// deleted records. Updates without audited field changes are skipped.
+//
+// NB! This method is expected to be called inside a transaction.
+func (dao *Dao) saveRecordAuditLog(action string, record *models.Record, oldRecord *models.Record, newRecord *models.Record) error {
+	changes := recordAuditChanges(record.Collection(), oldRecord, newRecord)
+	if action == models.AuditActionUpdate && len(changes) == 0 {
+		return nil // nothing has changed
+	}
+
+	actorType, actorId := record.AuditActor()
+
+	return dao.SaveAuditLog(&models.AuditLog{
+		CollectionId: record.Collection().Id,
+		RecordId:     record.Id,
+		Action:       action,
+		ActorType:    actorType,
+		ActorId:      actorId,
+		Changes:      changes,
+	})
+}
+
+// recordAuditChanges returns the changed audited fields between the
+// provided records as [models.AuditChange] map items.
+//
+// The values of the encrypted fields are not stored,
+// only the fact that they have changed.
+func recordAuditChanges(collection *models.Collection, oldRecord *models.Record, newRecord *models.Record) types.JsonMap {
+	blank := models.NewRecord(collection)
+
+	changes := types.JsonMap{}
+
+	for _, name := range recordAuditFieldNames(collection) {
+		var oldValue, newValue any
+		if oldRecord != nil {
+			oldValue = oldRecord.Get(name)
+		} else {
+			oldValue = blank.Get(name)
+		}
+		if newRecord != nil {
+			newValue = newRecord.Get(name)
+		} else {
+			newValue = blank.Get(name)
+		}
+
+		oldRaw, _ := json.Marshal(oldValue)
+		newRaw, _ := json.Marshal(newValue)
+		if string(oldRaw) == string(newRaw) || (isEmptyAuditValue(oldRaw) && isEmptyAuditValue(newRaw)) {
+			continue
+		}
+
+		change := models.AuditChange{}
+
+		if !IsEncryptedField(collection.Schema.GetFieldByName(name)) {
+			if oldRecord != nil {
+				change.Old = oldValue
+			}
+			if newRecord != nil {
+				change.New = newValue
+			}
+		}
+
+		changes[name] = change
+	}
+
+	return changes
+}
+
+// recordAuditFieldNames returns the names of the collection record
+// fields tracked in the audit trail.
+//
+// The base model fields, the computed fields and the auth record
+// secrets (password hash, token key, etc.) are never tracked.
+func recordAuditFieldNames(collection *models.Collection) []string {
+	names := []string{}
+
+	if collection.IsAuth() {
+		names = append(
+			names,
+			schema.FieldNameUsername,
+			schema.FieldNameEmail,
+			schema.FieldNameEmailVisibility,
+			schema.FieldNameVerified,
+		)
+	}
+
+	if collection.HasSoftDelete() {
+		names = append(names, schema.FieldNameDeleted)
+	}
+
+	for _, field := range collection.Schema.Fields() {
+		if field.Type == schema.FieldTypeComputed {
+			continue
+		}
+		names = append(names, field.Name)
+	}
+
+	return names
+}
+
+func isEmptyAuditValue(raw []byte) bool {
+	return list.ExistInSlice(string(raw), []string{"null", `""`, "[]", "{}"})
+}
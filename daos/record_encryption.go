@@ -0,0 +1,224 @@
+package daos
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+
+	"github.com/pocketbase/dbx"
+	"github.com/spf13/cast"
+	"github.com/unkod/space/models"
+	"github.com/unkod/space/models/schema"
+	"github.com/unkod/space/tools/security"
+)
+
+// ErrMissingEncryptionKey is returned when trying to store an
+// encrypted record field value without a configured encryption key.
+var ErrMissingEncryptionKey = errors.New("missing records encryption key")
+
+// reencryptBatchSize is the max number of rows that are loaded
+// in memory at once during a records re-encryption.
+const reencryptBatchSize = 500
+
+// IsEncryptedField checks whether the provided schema field values are stored encrypted.
+func IsEncryptedField(field *schema.SchemaField) bool {
+	if field == nil {
+		return false
+	}
+
+	field.InitOptions()
+
+	opt, ok := field.Options.(schema.Encryptable)
+
+	return ok && opt.IsEncrypted()
+}
+
+// EncryptFieldValue encrypts a single record field value with the first dao.EncryptionKeys key.
+//
+// Empty values are returned as they are (this allows checking for empty/required
+// values without having to decrypt the data).
+//
+// The text field values are encrypted deterministically to allow
+// exact-match lookups, while all other values use a random nonce.
+func (dao *Dao) EncryptFieldValue(field *schema.SchemaField, value any) (any, error) {
+	if v, ok := value.(driver.Valuer); ok {
+		var err error
+		if value, err = v.Value(); err != nil {
+			return nil, err
+		}
+	}
+
+	if value == nil {
+		return nil, nil
+	}
+
+	raw := cast.ToString(value)
+	if raw == "" {
+		return raw, nil
+	}
+
+	if len(dao.EncryptionKeys) == 0 {
+		return nil, ErrMissingEncryptionKey
+	}
+
+	if field.Type == schema.FieldTypeText {
+		return security.EncryptDeterministic([]byte(raw), dao.EncryptionKeys[0])
+	}
+
+	return security.Encrypt([]byte(raw), dao.EncryptionKeys[0])
+}
+
+// decryptFieldValue tries to decrypt the provided stored field value
+// with each of the dao.EncryptionKeys.
+//
+// Values that couldn't be decrypted (eg. stored before enabling the field
+// encryption) are returned as they are.
+func (dao *Dao) decryptFieldValue(value string) string {
+	if value == "" {
+		return value
+	}
+
+	for _, key := range dao.EncryptionKeys {
+		if decrypted, err := security.Decrypt(value, key); err == nil {
+			return string(decrypted)
+		}
+	}
+
+	return value
+}
+
+// encryptRecordColumns encrypts in place the encrypted fields values
+// of the provided db column->value map of a record.
+func (dao *Dao) encryptRecordColumns(collection *models.Collection, data map[string]any) error {
+	for _, field := range collection.Schema.Fields() {
+		if !IsEncryptedField(field) {
+			continue
+		}
+
+		value, ok := data[field.Name]
+		if !ok {
+			continue
+		}
+
+		encrypted, err := dao.EncryptFieldValue(field, value)
+		if err != nil {
+			return fmt.Errorf("failed to encrypt field %q: %w", field.Name, err)
+		}
+
+		data[field.Name] = encrypted
+	}
+
+	return nil
+}
+
+// decryptRecordRow decrypts in place the encrypted fields values
+// of the provided raw record db row.
+func (dao *Dao) decryptRecordRow(collection *models.Collection, row dbx.NullStringMap) {
+	if collection == nil {
+		return
+	}
+
+	for _, field := range collection.Schema.Fields() {
+		if !IsEncryptedField(field) {
+			continue
+		}
+
+		if value, ok := row[field.Name]; ok && value.Valid {
+			row[field.Name] = sql.NullString{String: dao.decryptFieldValue(value.String), Valid: true}
+		}
+	}
+}
+
+// ReencryptRecords re-encrypts the encrypted fields values of all
+// collection records with the first dao.EncryptionKeys key.
+//
+// It could be used after an encryption key rotation to avoid having to
+// keep the old keys around (see also [Dao.EncryptionKeys]).
+//
+// Values that are not encrypted yet (eg. stored before enabling the
+// field encryption) are also encrypted.
+func (dao *Dao) ReencryptRecords(collection *models.Collection) error {
+	names := []string{}
+	for _, field := range collection.Schema.Fields() {
+		if IsEncryptedField(field) {
+			names = append(names, field.Name)
+		}
+	}
+
+	return dao.reencryptRecordColumns(collection, names)
+}
+
+// reencryptRecordColumns decrypts and reencrypts (if the field is still
+// marked as encrypted) the specified columns of all collection records.
+//
+// The records are updated directly without triggering the model hooks.
+func (dao *Dao) reencryptRecordColumns(collection *models.Collection, columns []string) error {
+	if len(columns) == 0 || collection.IsView() {
+		return nil
+	}
+
+	if len(dao.EncryptionKeys) == 0 {
+		return ErrMissingEncryptionKey
+	}
+
+	return dao.RunInTransaction(func(txDao *Dao) error {
+		selectCols := append([]string{schema.FieldNameId}, columns...)
+
+		var lastId string
+
+		for {
+			rows := []dbx.NullStringMap{}
+
+			err := txDao.DB().Select(selectCols...).
+				From(collection.Name).
+				AndWhere(dbx.NewExp("[["+schema.FieldNameId+"]] > {:lastId}", dbx.Params{"lastId": lastId})).
+				OrderBy(schema.FieldNameId + " ASC").
+				Limit(reencryptBatchSize).
+				All(&rows)
+			if err != nil {
+				return err
+			}
+
+			for _, row := range rows {
+				lastId = row[schema.FieldNameId].String
+
+				data := dbx.Params{}
+
+				for _, name := range columns {
+					value := row[name]
+					if !value.Valid {
+						continue
+					}
+
+					decrypted := txDao.decryptFieldValue(value.String)
+
+					field := collection.Schema.GetFieldByName(name)
+					if !IsEncryptedField(field) {
+						data[name] = decrypted
+						continue
+					}
+
+					encrypted, err := txDao.EncryptFieldValue(field, decrypted)
+					if err != nil {
+						return fmt.Errorf("failed to encrypt field %q: %w", name, err)
+					}
+					data[name] = encrypted
+				}
+
+				if len(data) == 0 {
+					continue
+				}
+
+				_, err := txDao.DB().Update(collection.Name, data, dbx.HashExp{schema.FieldNameId: lastId}).Execute()
+				if err != nil {
+					return err
+				}
+			}
+
+			if len(rows) < reencryptBatchSize {
+				return nil
+			}
+		}
+	})
+}
@@ -0,0 +1,285 @@
+package daos_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/pocketbase/dbx"
+	"github.com/unkod/space/daos"
+	"github.com/unkod/space/models"
+	"github.com/unkod/space/models/schema"
+	"github.com/unkod/space/tests"
+	"github.com/unkod/space/tools/security"
+)
+
+const (
+	testEncryptionKey1 = "abcdabcdabcdabcdabcdabcdabcdabcd"
+	testEncryptionKey2 = "12345678901234567890123456789012"
+)
+
+func createEncryptedTestCollection(t *testing.T, dao *daos.Dao) *models.Collection {
+	collection := &models.Collection{
+		Name: "encrypted_test",
+		Type: models.CollectionTypeBase,
+		Schema: schema.NewSchema(
+			&schema.SchemaField{Name: "secret", Type: schema.FieldTypeText, Options: &schema.TextOptions{Encrypted: true}},
+			&schema.SchemaField{Name: "data", Type: schema.FieldTypeJson, Options: &schema.JsonOptions{Encrypted: true}},
+			&schema.SchemaField{Name: "plain", Type: schema.FieldTypeText},
+		),
+	}
+
+	if err := dao.SaveCollection(collection); err != nil {
+		t.Fatal(err)
+	}
+
+	return collection
+}
+
+func findRawColumn(t *testing.T, dao *daos.Dao, collection *models.Collection, id string, column string) string {
+	var raw string
+
+	err := dao.DB().Select("COALESCE([[" + column + "]], '')").
+		From(collection.Name).
+		Where(dbx.HashExp{"id": id}).
+		Row(&raw)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return raw
+}
+
+func TestIsEncryptedField(t *testing.T) {
+	scenarios := []struct {
+		field    *schema.SchemaField
+		expected bool
+	}{
+		{nil, false},
+		{&schema.SchemaField{Type: schema.FieldTypeText}, false},
+		{&schema.SchemaField{Type: schema.FieldTypeText, Options: &schema.TextOptions{Encrypted: true}}, true},
+		{&schema.SchemaField{Type: schema.FieldTypeJson, Options: &schema.JsonOptions{Encrypted: true}}, true},
+		{&schema.SchemaField{Type: schema.FieldTypeNumber}, false},
+	}
+
+	for i, s := range scenarios {
+		result := daos.IsEncryptedField(s.field)
+		if result != s.expected {
+			t.Errorf("(%d) Expected %v, got %v", i, s.expected, result)
+		}
+	}
+}
+
+func TestEncryptFieldValue(t *testing.T) {
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	textField := &schema.SchemaField{Type: schema.FieldTypeText}
+	jsonField := &schema.SchemaField{Type: schema.FieldTypeJson}
+
+	// missing key
+	if _, err := app.Dao().EncryptFieldValue(textField, "test"); !errors.Is(err, daos.ErrMissingEncryptionKey) {
+		t.Fatalf("Expected ErrMissingEncryptionKey, got %v", err)
+	}
+
+	app.Dao().EncryptionKeys = []string{testEncryptionKey1}
+
+	// empty values
+	for _, v := range []any{nil, ""} {
+		result, err := app.Dao().EncryptFieldValue(textField, v)
+		if err != nil || result != v {
+			t.Fatalf("Expected %v to be returned as it is, got %v (%v)", v, result, err)
+		}
+	}
+
+	// deterministic text encryption
+	text1, err := app.Dao().EncryptFieldValue(textField, "test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	text2, _ := app.Dao().EncryptFieldValue(textField, "test")
+	if text1 != text2 {
+		t.Fatalf("Expected the same text cipher values, got %v and %v", text1, text2)
+	}
+
+	// randomized json encryption
+	json1, err := app.Dao().EncryptFieldValue(jsonField, `{"a":1}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	json2, _ := app.Dao().EncryptFieldValue(jsonField, `{"a":1}`)
+	if json1 == json2 {
+		t.Fatalf("Expected different json cipher values, got %v", json1)
+	}
+
+	decrypted, err := security.Decrypt(json1.(string), testEncryptionKey1)
+	if err != nil || string(decrypted) != `{"a":1}` {
+		t.Fatalf("Expected the json cipher value to be decryptable, got %q (%v)", decrypted, err)
+	}
+}
+
+func TestEncryptedRecordSaveAndLoad(t *testing.T) {
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	app.Dao().EncryptionKeys = []string{testEncryptionKey1}
+
+	collection := createEncryptedTestCollection(t, app.Dao())
+
+	record := models.NewRecord(collection)
+	record.Set("secret", "top secret")
+	record.Set("data", map[string]any{"a": 1})
+	record.Set("plain", "visible")
+	if err := app.Dao().SaveRecord(record); err != nil {
+		t.Fatal(err)
+	}
+
+	emptyRecord := models.NewRecord(collection)
+	if err := app.Dao().SaveRecord(emptyRecord); err != nil {
+		t.Fatal(err)
+	}
+
+	// check the raw db values
+	if raw := findRawColumn(t, app.Dao(), collection, record.Id, "secret"); raw == "" || raw == "top secret" {
+		t.Fatalf("Expected the secret column to be encrypted, got %q", raw)
+	}
+	if raw := findRawColumn(t, app.Dao(), collection, record.Id, "data"); raw == "" || raw == `{"a":1}` {
+		t.Fatalf("Expected the data column to be encrypted, got %q", raw)
+	}
+	if raw := findRawColumn(t, app.Dao(), collection, record.Id, "plain"); raw != "visible" {
+		t.Fatalf("Expected the plain column to not be encrypted, got %q", raw)
+	}
+	if raw := findRawColumn(t, app.Dao(), collection, emptyRecord.Id, "secret"); raw != "" {
+		t.Fatalf("Expected the empty secret column to be stored as it is, got %q", raw)
+	}
+
+	// check the loaded values
+	loaded, err := app.Dao().FindRecordById(collection.Id, record.Id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v := loaded.GetString("secret"); v != "top secret" {
+		t.Fatalf("Expected the decrypted secret value, got %q", v)
+	}
+	if v := loaded.GetString("data"); v != `{"a":1}` {
+		t.Fatalf("Expected the decrypted data value, got %q", v)
+	}
+
+	loadedAll, err := app.Dao().FindRecordsByIds(collection.Id, []string{record.Id})
+	if err != nil || len(loadedAll) != 1 {
+		t.Fatalf("Expected 1 record, got %v (%v)", loadedAll, err)
+	}
+	if v := loadedAll[0].GetString("secret"); v != "top secret" {
+		t.Fatalf("Expected the decrypted secret value, got %q", v)
+	}
+
+	// unique value checks
+	if app.Dao().IsRecordValueUnique(collection.Id, "secret", "top secret") {
+		t.Fatal("Expected the existing encrypted value to not be unique")
+	}
+	if !app.Dao().IsRecordValueUnique(collection.Id, "secret", "other secret") {
+		t.Fatal("Expected the new encrypted value to be unique")
+	}
+
+	// missing key
+	app.Dao().EncryptionKeys = nil
+	record.Set("secret", "new secret")
+	if err := app.Dao().SaveRecord(record); !errors.Is(err, daos.ErrMissingEncryptionKey) {
+		t.Fatalf("Expected ErrMissingEncryptionKey, got %v", err)
+	}
+}
+
+func TestEncryptedRecordKeyRotation(t *testing.T) {
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	app.Dao().EncryptionKeys = []string{testEncryptionKey1}
+
+	collection := createEncryptedTestCollection(t, app.Dao())
+
+	record1 := models.NewRecord(collection)
+	record1.Set("secret", "secret1")
+	record2 := models.NewRecord(collection)
+	record2.Set("secret", "secret2")
+	for _, r := range []*models.Record{record1, record2} {
+		if err := app.Dao().SaveRecord(r); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// rotate the key
+	app.Dao().EncryptionKeys = []string{testEncryptionKey2, testEncryptionKey1}
+
+	loaded1, err := app.Dao().FindRecordById(collection.Id, record1.Id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v := loaded1.GetString("secret"); v != "secret1" {
+		t.Fatalf("Expected the value to be decrypted with the previous key, got %q", v)
+	}
+
+	// re-saving should re-encrypt with the new key
+	if err := app.Dao().SaveRecord(loaded1); err != nil {
+		t.Fatal(err)
+	}
+	raw1 := findRawColumn(t, app.Dao(), collection, record1.Id, "secret")
+	if decrypted, err := security.Decrypt(raw1, testEncryptionKey2); err != nil || string(decrypted) != "secret1" {
+		t.Fatalf("Expected the re-saved value to be encrypted with the new key, got %q (%v)", decrypted, err)
+	}
+
+	// re-encrypt all records
+	if err := app.Dao().ReencryptRecords(collection); err != nil {
+		t.Fatal(err)
+	}
+	raw2 := findRawColumn(t, app.Dao(), collection, record2.Id, "secret")
+	if decrypted, err := security.Decrypt(raw2, testEncryptionKey2); err != nil || string(decrypted) != "secret2" {
+		t.Fatalf("Expected the re-encrypted value to be encrypted with the new key, got %q (%v)", decrypted, err)
+	}
+
+	// the old key is no longer needed
+	app.Dao().EncryptionKeys = []string{testEncryptionKey2}
+	loaded2, err := app.Dao().FindRecordById(collection.Id, record2.Id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v := loaded2.GetString("secret"); v != "secret2" {
+		t.Fatalf("Expected the value to be decrypted with the new key, got %q", v)
+	}
+}
+
+func TestEncryptedFieldsToggle(t *testing.T) {
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	app.Dao().EncryptionKeys = []string{testEncryptionKey1}
+
+	collection := createEncryptedTestCollection(t, app.Dao())
+
+	record := models.NewRecord(collection)
+	record.Set("secret", "secret")
+	record.Set("plain", "plain")
+	if err := app.Dao().SaveRecord(record); err != nil {
+		t.Fatal(err)
+	}
+
+	// swap the fields encryption
+	collection.Schema.GetFieldByName("secret").Options = &schema.TextOptions{}
+	collection.Schema.GetFieldByName("plain").Options = &schema.TextOptions{Encrypted: true}
+	if err := app.Dao().SaveCollection(collection); err != nil {
+		t.Fatal(err)
+	}
+
+	if raw := findRawColumn(t, app.Dao(), collection, record.Id, "secret"); raw != "secret" {
+		t.Fatalf("Expected the secret column to be decrypted, got %q", raw)
+	}
+	if raw := findRawColumn(t, app.Dao(), collection, record.Id, "plain"); raw == "plain" {
+		t.Fatalf("Expected the plain column to be encrypted, got %q", raw)
+	}
+
+	loaded, err := app.Dao().FindRecordById(collection.Id, record.Id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if loaded.GetString("secret") != "secret" || loaded.GetString("plain") != "plain" {
+		t.Fatalf("Expected the original values, got %v", loaded.SchemaData())
+	}
+}
@@ -1,6 +1,7 @@
 package daos
 
 import (
+	"errors"
 	"fmt"
 	"regexp"
 	"strings"
@@ -15,8 +16,16 @@ import (
 	"github.com/unkod/space/tools/types"
 )
 
-// MaxExpandDepth specifies the max allowed nested expand depth path.
-const MaxExpandDepth = 6
+// DefaultMaxExpandDepth specifies the default max allowed nested expand depth path.
+const DefaultMaxExpandDepth = 6
+
+// DefaultIndirectExpandLimit specifies the default max number
+// of the back-relation records expanded per record.
+const DefaultIndirectExpandLimit = 1000
+
+// ErrMaxExpandDepthExceeded is returned for expand paths
+// with more nested levels than the max allowed expand depth.
+var ErrMaxExpandDepthExceeded = errors.New("max expand depth exceeded")
 
 // ExpandFetchFunc defines the function that is used to fetch the expanded relation records.
 type ExpandFetchFunc func(relCollection *models.Collection, relIds []string) ([]*models.Record, error)
@@ -33,6 +42,16 @@ func (dao *Dao) ExpandRecord(record *models.Record, expands []string, optFetchFu
 
 // ExpandRecords expands the relations of the provided Record models list.
 //
+// The related records of each expand path level are fetched at once for
+// all provided records (aka. a single fetch call per expand path level
+// regardless of the number of records), avoiding the N+1 queries problem.
+//
+// Relations that were already expanded for the same record on the current
+// expand path (eg. with self-referencing records) are not expanded again.
+//
+// Expand paths with more nested levels than [Dao.ExpandMaxDepth] fail
+// with [ErrMaxExpandDepthExceeded] without fetching any relations.
+//
 // If optFetchFunc is not set, then a default function will be used
 // that returns all relation records.
 //
@@ -43,7 +62,12 @@ func (dao *Dao) ExpandRecords(records []*models.Record, expands []string, optFet
 	failed := map[string]error{}
 
 	for _, expand := range normalized {
-		if err := dao.expandRecords(records, expand, optFetchFunc, 1); err != nil {
+		if err := dao.CheckExpandDepth(expand); err != nil {
+			failed[expand] = err
+			continue
+		}
+
+		if err := dao.expandRecords(records, expand, optFetchFunc, 1, nil); err != nil {
 			failed[expand] = err
 		}
 	}
@@ -51,22 +75,66 @@ func (dao *Dao) ExpandRecords(records []*models.Record, expands []string, optFet
 	return failed
 }
 
+// CheckExpandDepth checks whether the nested levels of the provided
+// expand path don't exceed the max allowed dao expand depth.
+func (dao *Dao) CheckExpandDepth(expand string) error {
+	maxDepth := dao.maxExpandDepth()
+
+	if depth := strings.Count(strings.Trim(expand, "."), ".") + 1; depth > maxDepth {
+		return fmt.Errorf("%w: the expand path %q has %d levels (max %d)", ErrMaxExpandDepthExceeded, expand, depth, maxDepth)
+	}
+
+	return nil
+}
+
+func (dao *Dao) maxExpandDepth() int {
+	if dao.ExpandMaxDepth > 0 {
+		return dao.ExpandMaxDepth
+	}
+
+	return DefaultMaxExpandDepth
+}
+
+func (dao *Dao) indirectExpandLimit() int {
+	if dao.IndirectExpandLimit > 0 {
+		return dao.IndirectExpandLimit
+	}
+
+	return DefaultIndirectExpandLimit
+}
+
+// expandRecordKey returns the unique expand path key of the provided record.
+func expandRecordKey(collectionId string, recordId string) string {
+	return collectionId + "/" + recordId
+}
+
 var indirectExpandRegex = regexp.MustCompile(`^(\w+)\((\w+)\)$`)
 
+// backRelationExpandRegex matches the "collection_via_field" indirect expand syntax.
+var backRelationExpandRegex = regexp.MustCompile(`^(\w+)_via_(\w+)$`)
+
 // notes:
-// - if fetchFunc is nil, dao.FindRecordsByIds will be used
+// - if fetchFunc is nil, dao.FindRecordsByIds will be used (excluding the soft-deleted records)
 // - all records are expected to be from the same collection
-// - if MaxExpandDepth is reached, the function returns nil ignoring the remaining expand path
-// - indirect expands are supported only with single relation fields
-func (dao *Dao) expandRecords(records []*models.Record, expandPath string, fetchFunc ExpandFetchFunc, recursionLevel int) error {
+// - if the max expand depth is reached, the function returns nil ignoring the remaining expand path
+// - indirect expands could be defined either as "collection(field)" or "collection_via_field"
+// - indirect expands are limited to [Dao.IndirectExpandLimit] back-relation records per record
+// - ancestors holds the expand path keys of the parents of each record (used for cycles detection)
+func (dao *Dao) expandRecords(
+	records []*models.Record,
+	expandPath string,
+	fetchFunc ExpandFetchFunc,
+	recursionLevel int,
+	ancestors map[string]map[string]struct{},
+) error {
 	if fetchFunc == nil {
 		// load a default fetchFunc
 		fetchFunc = func(relCollection *models.Collection, relIds []string) ([]*models.Record, error) {
-			return dao.FindRecordsByIds(relCollection.Id, relIds)
+			return dao.FindRecordsByIds(relCollection.Id, relIds, SoftDeletedFilter(relCollection, SoftDeletedExclude))
 		}
 	}
 
-	if expandPath == "" || recursionLevel > MaxExpandDepth || len(records) == 0 {
+	if expandPath == "" || recursionLevel > dao.maxExpandDepth() || len(records) == 0 {
 		return nil
 	}
 
@@ -78,6 +146,9 @@ func (dao *Dao) expandRecords(records []*models.Record, expandPath string, fetch
 
 	parts := strings.SplitN(expandPath, ".", 2)
 	matches := indirectExpandRegex.FindStringSubmatch(parts[0])
+	if len(matches) != 3 && mainCollection.Schema.GetFieldByName(parts[0]) == nil {
+		matches = backRelationExpandRegex.FindStringSubmatch(parts[0])
+	}
 
 	if len(matches) == 3 {
 		indirectRel, _ := dao.FindCollectionByNameOrId(matches[1])
@@ -95,33 +166,16 @@ func (dao *Dao) expandRecords(records []*models.Record, expandPath string, fetch
 		if indirectRelFieldOptions == nil || indirectRelFieldOptions.CollectionId != mainCollection.Id {
 			return fmt.Errorf("Invalid indirect relation field path %q.", parts[0])
 		}
-		if indirectRelFieldOptions.IsMultiple() {
-			// for now don't allow multi-relation indirect fields expand
-			// due to eventual poor query performance with large data sets.
-			return fmt.Errorf("Multi-relation fields cannot be indirectly expanded in %q.", parts[0])
-		}
-
-		recordIds := make([]any, len(records))
-		for i, record := range records {
-			recordIds[i] = record.Id
-		}
 
-		// @todo after the index optimizations consider allowing
-		// indirect expand for multi-relation fields
-		indirectRecords, err := dao.FindRecordsByExpr(
-			indirectRel.Id,
-			dbx.In(inflector.Columnify(matches[2]), recordIds...),
+		mappedIndirectRecordIds, err := dao.findIndirectRelationIds(
+			indirectRel,
+			indirectRelField.Name,
+			indirectRelFieldOptions.IsMultiple(),
+			records,
 		)
 		if err != nil {
 			return err
 		}
-		mappedIndirectRecordIds := make(map[string][]string, len(indirectRecords))
-		for _, indirectRecord := range indirectRecords {
-			recId := indirectRecord.GetString(matches[2])
-			if recId != "" {
-				mappedIndirectRecordIds[recId] = append(mappedIndirectRecordIds[recId], indirectRecord.Id)
-			}
-		}
 
 		// add the indirect relation ids as a new relation field value
 		for _, record := range records {
@@ -135,7 +189,7 @@ func (dao *Dao) expandRecords(records []*models.Record, expandPath string, fetch
 			MaxSelect:    nil,
 			CollectionId: indirectRel.Id,
 		}
-		if isRelFieldUnique(indirectRel, indirectRelField.Name) {
+		if !indirectRelFieldOptions.IsMultiple() && isRelFieldUnique(indirectRel, indirectRelField.Name) {
 			relFieldOptions.MaxSelect = types.Pointer(1)
 		}
 		// indirect relation
@@ -166,6 +220,24 @@ func (dao *Dao) expandRecords(records []*models.Record, expandPath string, fetch
 
 	// ---------------------------------------------------------------
 
+	// skip the records whose relation was already expanded on the
+	// current path (aka. a cycle, eg. with self-referencing records)
+	if len(ancestors) > 0 {
+		nonCyclic := make([]*models.Record, 0, len(records))
+		for _, record := range records {
+			recordKey := expandRecordKey(mainCollection.Id, record.Id)
+			if _, isCycle := ancestors[recordKey][recordKey+"."+relField.Name]; !isCycle {
+				nonCyclic = append(nonCyclic, record)
+			}
+		}
+
+		if len(nonCyclic) == 0 {
+			return nil
+		}
+
+		records = nonCyclic
+	}
+
 	// extract the id of the relations to expand
 	relIds := make([]string, 0, len(records))
 	for _, record := range records {
@@ -180,7 +252,28 @@ func (dao *Dao) expandRecords(records []*models.Record, expandPath string, fetch
 
 	// expand nested fields
 	if len(parts) > 1 {
-		err := dao.expandRecords(rels, parts[1], fetchFunc, recursionLevel+1)
+		// collect the already expanded relations on the path of each rel
+		relAncestors := make(map[string]map[string]struct{}, len(rels))
+		for _, record := range records {
+			recordKey := expandRecordKey(mainCollection.Id, record.Id)
+
+			for _, id := range record.GetStringSlice(relField.Name) {
+				relKey := expandRecordKey(relCollection.Id, id)
+
+				path, ok := relAncestors[relKey]
+				if !ok {
+					path = map[string]struct{}{}
+					relAncestors[relKey] = path
+				}
+
+				path[recordKey+"."+relField.Name] = struct{}{}
+				for k := range ancestors[recordKey] {
+					path[k] = struct{}{}
+				}
+			}
+		}
+
+		err := dao.expandRecords(rels, parts[1], fetchFunc, recursionLevel+1, relAncestors)
 		if err != nil {
 			return err
 		}
@@ -244,6 +337,65 @@ func (dao *Dao) expandRecords(records []*models.Record, expandPath string, fetch
 	return nil
 }
 
+// findIndirectRelationIds returns the ids of the indirectRel records
+// that reference the provided records via the relFieldName field,
+// grouped by the referenced record id.
+//
+// Only the ids of the first [Dao.IndirectExpandLimit] back-relation
+// records (in insertion order) are returned for each referenced record.
+func (dao *Dao) findIndirectRelationIds(
+	indirectRel *models.Collection,
+	relFieldName string,
+	isMultiple bool,
+	records []*models.Record,
+) (map[string][]string, error) {
+	recordIds := make([]any, len(records))
+	for i, record := range records {
+		recordIds[i] = record.Id
+	}
+
+	tableName := inflector.Columnify(indirectRel.Name)
+	fieldName := inflector.Columnify(relFieldName)
+
+	query := dao.DB().Select("{{" + tableName + "}}.[[id]] as [[id]]").From(tableName)
+
+	if isMultiple {
+		query.AndSelect("{{__je}}.[[value]] as [[ref]]").
+			InnerJoin(
+				fmt.Sprintf(
+					"json_each(CASE WHEN json_valid([[%s.%s]]) THEN [[%s.%s]] ELSE json_array([[%s.%s]]) END) {{__je}}",
+					tableName, fieldName, tableName, fieldName, tableName, fieldName,
+				),
+				nil,
+			).
+			AndWhere(dbx.In("__je.value", recordIds...))
+	} else {
+		query.AndSelect("{{" + tableName + "}}.[[" + fieldName + "]] as [[ref]]").
+			AndWhere(dbx.In(tableName+"."+fieldName, recordIds...))
+	}
+
+	rows := []struct {
+		Id  string `db:"id"`
+		Ref string `db:"ref"`
+	}{}
+
+	if err := query.OrderBy(tableName + ".rowid ASC").All(&rows); err != nil {
+		return nil, err
+	}
+
+	limit := dao.indirectExpandLimit()
+
+	result := make(map[string][]string, len(records))
+	for _, row := range rows {
+		if row.Ref == "" || len(result[row.Ref]) >= limit {
+			continue
+		}
+		result[row.Ref] = append(result[row.Ref], row.Id)
+	}
+
+	return result, nil
+}
+
 // normalizeExpands normalizes expand strings and merges self containing paths
 // (eg. ["a.b.c", "a.b", "   test  ", "  ", "test"] -> ["a.b.c", "test"]).
 func normalizeExpands(paths []string) []string {
@@ -11,6 +11,7 @@ import (
 	"github.com/unkod/space/models/schema"
 	"github.com/unkod/space/tests"
 	"github.com/unkod/space/tools/list"
+	"github.com/unkod/space/tools/types"
 )
 
 func TestExpandRecords(t *testing.T) {
@@ -118,7 +119,7 @@ func TestExpandRecords(t *testing.T) {
 			func(c *models.Collection, ids []string) ([]*models.Record, error) {
 				return app.Dao().FindRecordsByIds(c.Id, ids, nil)
 			},
-			9,
+			4,
 			0,
 		},
 		{
@@ -150,15 +151,15 @@ func TestExpandRecords(t *testing.T) {
 			0,
 		},
 		{
-			"maxExpandDepth reached",
+			"maxExpandDepth exceeded",
 			"demo4",
 			[]string{"qzaqccwrmva4o1n"},
 			[]string{"self_rel_many.self_rel_many.self_rel_many.self_rel_many.self_rel_many.self_rel_many.self_rel_many.self_rel_many.self_rel_many.self_rel_many.self_rel_many.self_rel_many"},
 			func(c *models.Collection, ids []string) ([]*models.Record, error) {
 				return app.Dao().FindRecordsByIds(c.Id, ids, nil)
 			},
-			6,
 			0,
+			1,
 		},
 		{
 			"simple indirect expand",
@@ -181,7 +182,7 @@ func TestExpandRecords(t *testing.T) {
 			func(c *models.Collection, ids []string) ([]*models.Record, error) {
 				return app.Dao().FindRecordsByIds(c.Id, ids, nil)
 			},
-			5,
+			2,
 			0,
 		},
 		{
@@ -221,6 +222,147 @@ func TestExpandRecords(t *testing.T) {
 	}
 }
 
+func TestExpandRecordsFetchCalls(t *testing.T) {
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	records, err := app.Dao().FindRecordsByExpr("demo1")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var totalCalls int
+	fetchFunc := func(c *models.Collection, ids []string) ([]*models.Record, error) {
+		totalCalls++
+		return app.Dao().FindRecordsByIds(c.Id, ids)
+	}
+
+	failed := app.Dao().ExpandRecords(records, []string{"rel_one", "rel_many"}, fetchFunc)
+	if len(failed) > 0 {
+		t.Fatalf("Expected no expand failures, got %v", failed)
+	}
+
+	// a single fetch per expand path regardless of the number of records
+	// (instead of 1 per record and relation field)
+	if totalCalls != 2 {
+		t.Fatalf("Expected 2 fetch calls for %d records, got %d", len(records), totalCalls)
+	}
+}
+
+func TestExpandRecordsCycles(t *testing.T) {
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	// self-referencing collection
+	collection := &models.Collection{Name: "tree", Type: models.CollectionTypeBase}
+	collection.MarkAsNew()
+	collection.RefreshId()
+	collection.Schema = schema.NewSchema(&schema.SchemaField{
+		Name: "parent",
+		Type: schema.FieldTypeRelation,
+		Options: &schema.RelationOptions{
+			CollectionId: collection.Id,
+			MaxSelect:    types.Pointer(1),
+		},
+	})
+	if err := app.Dao().SaveCollection(collection); err != nil {
+		t.Fatal(err)
+	}
+
+	// a -> b -> a and c -> c
+	a := models.NewRecord(collection)
+	a.Id = "aaaaaaaaaaaaaaa"
+	a.MarkAsNew()
+	a.Set("parent", "bbbbbbbbbbbbbbb")
+	b := models.NewRecord(collection)
+	b.Id = "bbbbbbbbbbbbbbb"
+	b.MarkAsNew()
+	b.Set("parent", "aaaaaaaaaaaaaaa")
+	c := models.NewRecord(collection)
+	c.Id = "ccccccccccccccc"
+	c.MarkAsNew()
+	c.Set("parent", "ccccccccccccccc")
+	for _, r := range []*models.Record{a, b, c} {
+		if err := app.Dao().SaveRecord(r); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var totalCalls int
+	fetchFunc := func(c *models.Collection, ids []string) ([]*models.Record, error) {
+		totalCalls++
+		return app.Dao().FindRecordsByIds(c.Id, ids)
+	}
+
+	records := []*models.Record{a, b, c}
+
+	failed := app.Dao().ExpandRecords(records, []string{"parent.parent.parent.parent.parent.parent"}, fetchFunc)
+	if len(failed) > 0 {
+		t.Fatalf("Expected no expand failures, got %v", failed)
+	}
+
+	if totalCalls != 2 {
+		t.Fatalf("Expected 2 fetch calls, got %d", totalCalls)
+	}
+
+	// a -> b -> a (not expanded further)
+	aParent, _ := a.Expand()["parent"].(*models.Record)
+	if aParent == nil || aParent.Id != b.Id {
+		t.Fatalf("Expected a.parent to be expanded with b, got %v", aParent)
+	}
+	aParentParent, _ := aParent.Expand()["parent"].(*models.Record)
+	if aParentParent == nil || aParentParent.Id != a.Id {
+		t.Fatalf("Expected a.parent.parent to be expanded with a, got %v", aParentParent)
+	}
+	if len(aParentParent.Expand()) != 0 {
+		t.Fatalf("Expected a.parent.parent to not be expanded further, got %v", aParentParent.Expand())
+	}
+
+	// c -> c (not expanded further)
+	cParent, _ := c.Expand()["parent"].(*models.Record)
+	if cParent == nil || cParent.Id != c.Id {
+		t.Fatalf("Expected c.parent to be expanded with c, got %v", cParent)
+	}
+	if len(cParent.Expand()) != 0 {
+		t.Fatalf("Expected c.parent to not be expanded further, got %v", cParent.Expand())
+	}
+}
+
+func TestCheckExpandDepth(t *testing.T) {
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	scenarios := []struct {
+		maxDepth    int
+		expand      string
+		expectError bool
+	}{
+		{0, "", false},
+		{0, "a", false},
+		{0, "a.b.c.d.e.f", false},
+		{0, "a.b.c.d.e.f.g", true},
+		{2, "a.b", false},
+		{2, "a.b.", false},
+		{2, "a.b.c", true},
+	}
+
+	for i, s := range scenarios {
+		app.Dao().ExpandMaxDepth = s.maxDepth
+
+		err := app.Dao().CheckExpandDepth(s.expand)
+
+		hasErr := err != nil
+		if hasErr != s.expectError {
+			t.Errorf("(%d) Expected hasErr %v, got %v (%v)", i, s.expectError, hasErr, err)
+			continue
+		}
+
+		if hasErr && !errors.Is(err, daos.ErrMaxExpandDepthExceeded) {
+			t.Errorf("(%d) Expected ErrMaxExpandDepthExceeded, got %v", i, err)
+		}
+	}
+}
+
 func TestExpandRecord(t *testing.T) {
 	app, _ := tests.NewTestApp()
 	defer app.Cleanup()
@@ -302,7 +444,7 @@ func TestExpandRecord(t *testing.T) {
 			func(c *models.Collection, ids []string) ([]*models.Record, error) {
 				return app.Dao().FindRecordsByIds(c.Id, ids, nil)
 			},
-			8,
+			3,
 			0,
 		},
 		{
@@ -317,15 +459,15 @@ func TestExpandRecord(t *testing.T) {
 			0,
 		},
 		{
-			"maxExpandDepth reached",
+			"maxExpandDepth exceeded",
 			"demo4",
 			"qzaqccwrmva4o1n",
 			[]string{"self_rel_many.self_rel_many.self_rel_many.self_rel_many.self_rel_many.self_rel_many.self_rel_many.self_rel_many.self_rel_many.self_rel_many.self_rel_many.self_rel_many"},
 			func(c *models.Collection, ids []string) ([]*models.Record, error) {
 				return app.Dao().FindRecordsByIds(c.Id, ids, nil)
 			},
-			6,
 			0,
+			1,
 		},
 		{
 			"simple indirect expand",
@@ -348,7 +490,7 @@ func TestExpandRecord(t *testing.T) {
 			func(c *models.Collection, ids []string) ([]*models.Record, error) {
 				return app.Dao().FindRecordsByIds(c.Id, ids, nil)
 			},
-			5,
+			2,
 			0,
 		},
 	}
@@ -371,6 +513,137 @@ func TestExpandRecord(t *testing.T) {
 	}
 }
 
+func TestExpandRecordBackRelation(t *testing.T) {
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	fetchFunc := func(c *models.Collection, ids []string) ([]*models.Record, error) {
+		return app.Dao().FindRecordsByIds(c.Id, ids, nil)
+	}
+
+	scenarios := []struct {
+		name        string
+		collection  string
+		recordId    string
+		expand      string
+		limit       int
+		expectError bool
+		expectIds   []string
+	}{
+		{
+			"missing back-relation collection",
+			"demo3",
+			"lcl9d87w22ml6jy",
+			"missing_via_rel_one_no_cascade_required",
+			0,
+			true,
+			nil,
+		},
+		{
+			"non-relation back-relation field",
+			"demo3",
+			"lcl9d87w22ml6jy",
+			"demo4_via_title",
+			0,
+			true,
+			nil,
+		},
+		{
+			"back-relation field referencing another collection",
+			"demo3",
+			"lcl9d87w22ml6jy",
+			"demo4_via_self_rel_one",
+			0,
+			true,
+			nil,
+		},
+		{
+			"single relation back-relation",
+			"demo3",
+			"lcl9d87w22ml6jy",
+			"demo4_via_rel_one_no_cascade_required",
+			0,
+			false,
+			[]string{"qzaqccwrmva4o1n", "i9naidtvr6qsgb4"},
+		},
+		{
+			"single relation back-relation with limit",
+			"demo3",
+			"lcl9d87w22ml6jy",
+			"demo4_via_rel_one_no_cascade_required",
+			1,
+			false,
+			[]string{"qzaqccwrmva4o1n"},
+		},
+		{
+			"multiple relation back-relation",
+			"demo3",
+			"7nwo8tuiatetxdm",
+			"demo4_via_rel_many_no_cascade_required",
+			0,
+			false,
+			[]string{"i9naidtvr6qsgb4"},
+		},
+		{
+			"self-referencing multiple relation back-relation",
+			"demo4",
+			"qzaqccwrmva4o1n",
+			"demo4_via_self_rel_many",
+			0,
+			false,
+			[]string{"qzaqccwrmva4o1n"},
+		},
+		{
+			"back-relation without referencing records",
+			"demo3",
+			"mk5fmymtx4wsprk",
+			"demo4_via_rel_many_no_cascade_required",
+			0,
+			false,
+			[]string{},
+		},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			app.Dao().IndirectExpandLimit = s.limit
+
+			record, err := app.Dao().FindRecordById(s.collection, s.recordId)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			errs := app.Dao().ExpandRecord(record, []string{s.expand}, fetchFunc)
+
+			hasErr := len(errs) > 0
+			if hasErr != s.expectError {
+				t.Fatalf("Expected hasErr %v, got %v (%v)", s.expectError, hasErr, errs)
+			}
+
+			if hasErr {
+				return
+			}
+
+			rels, _ := record.Expand()[s.expand].([]*models.Record)
+
+			ids := make([]string, len(rels))
+			for i, rel := range rels {
+				ids[i] = rel.Id
+			}
+
+			if len(ids) != len(s.expectIds) {
+				t.Fatalf("Expected ids %v, got %v", s.expectIds, ids)
+			}
+
+			for i, id := range s.expectIds {
+				if ids[i] != id {
+					t.Fatalf("Expected ids %v, got %v", s.expectIds, ids)
+				}
+			}
+		})
+	}
+}
+
 func TestIndirectExpandSingeVsArrayResult(t *testing.T) {
 	app, _ := tests.NewTestApp()
 	defer app.Cleanup()
@@ -0,0 +1,182 @@
+package daos
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/pocketbase/dbx"
+	"github.com/unkod/space/models"
+	"github.com/unkod/space/models/schema"
+)
+
+// SearchTableName returns the name of the FTS5 shadow table
+// storing the full-text search index of the collection records.
+func SearchTableName(collection *models.Collection) string {
+	return "_search_" + collection.Id
+}
+
+// IsFTS5Supported checks whether the current db driver
+// was compiled with the FTS5 extension.
+func (dao *Dao) IsFTS5Supported() bool {
+	var enabled bool
+
+	err := dao.DB().NewQuery("SELECT sqlite_compileoption_used('ENABLE_FTS5')").Row(&enabled)
+
+	return err == nil && enabled
+}
+
+// HasRecordsSearchIndex checks whether the collection records
+// full-text search index table exists.
+func (dao *Dao) HasRecordsSearchIndex(collection *models.Collection) bool {
+	return dao.HasTable(SearchTableName(collection))
+}
+
+// RebuildRecordsSearchIndex (re)creates the collection records full-text
+// search index and populates it with the existing records data.
+//
+// The index is kept up to date with the records table via triggers.
+//
+// The index is only dropped if the collection doesn't have search fields
+// or the FTS5 extension is not supported by the db driver.
+func (dao *Dao) RebuildRecordsSearchIndex(collection *models.Collection) error {
+	return dao.RunInTransaction(func(txDao *Dao) error {
+		if err := txDao.dropRecordsSearchIndex(collection); err != nil {
+			return err
+		}
+
+		fields := collection.SearchFields()
+		if len(fields) == 0 || !txDao.IsFTS5Supported() {
+			return nil
+		}
+
+		tableName := collection.Name
+		searchTableName := SearchTableName(collection)
+
+		columns := make([]string, len(fields))
+		newValues := make([]string, len(fields))
+		for i, name := range fields {
+			columns[i] = "[[" + name + "]]"
+			newValues[i] = "new.[[" + name + "]]"
+		}
+
+		insertColumns := "[[" + schema.FieldNameId + "]], " + strings.Join(columns, ", ")
+		insertValues := "new.[[" + schema.FieldNameId + "]], " + strings.Join(newValues, ", ")
+		deleteOld := fmt.Sprintf("DELETE FROM {{%s}} WHERE [[%s]] = old.[[%s]];", searchTableName, schema.FieldNameId, schema.FieldNameId)
+
+		_, err := txDao.DB().NewQuery(fmt.Sprintf(
+			`
+			CREATE VIRTUAL TABLE {{%[1]s}} USING fts5([[%[3]s]] UNINDEXED, %[4]s);
+
+			INSERT INTO {{%[1]s}} (%[5]s) SELECT %[5]s FROM {{%[2]s}};
+
+			CREATE TRIGGER [[%[1]s_insert]] AFTER INSERT ON {{%[2]s}} BEGIN
+				INSERT INTO {{%[1]s}} (%[5]s) VALUES (%[6]s);
+			END;
+
+			CREATE TRIGGER [[%[1]s_update]] AFTER UPDATE ON {{%[2]s}} BEGIN
+				%[7]s
+				INSERT INTO {{%[1]s}} (%[5]s) VALUES (%[6]s);
+			END;
+
+			CREATE TRIGGER [[%[1]s_delete]] AFTER DELETE ON {{%[2]s}} BEGIN
+				%[7]s
+			END;
+			`,
+			searchTableName,
+			tableName,
+			schema.FieldNameId,
+			strings.Join(columns, ", "),
+			insertColumns,
+			insertValues,
+			deleteOld,
+		)).Execute()
+
+		return err
+	})
+}
+
+// dropRecordsSearchIndex drops the collection records full-text
+// search index table and its sync triggers (if exist).
+func (dao *Dao) dropRecordsSearchIndex(collection *models.Collection) error {
+	searchTableName := SearchTableName(collection)
+
+	_, err := dao.DB().NewQuery(fmt.Sprintf(
+		`
+		DROP TRIGGER IF EXISTS [[%[1]s_insert]];
+		DROP TRIGGER IF EXISTS [[%[1]s_update]];
+		DROP TRIGGER IF EXISTS [[%[1]s_delete]];
+		DROP TABLE IF EXISTS {{%[1]s}};
+		`,
+		searchTableName,
+	)).Execute()
+
+	return err
+}
+
+// RecordsSearchFilter returns a record query filter function that matches
+// only the collection records containing all terms of the search text.
+//
+// If the collection has a full-text search index, the records are
+// ordered by relevance (unless orderByRank is false).
+// Otherwise it fallbacks to a LIKE filter on the collection search fields.
+//
+// The search text is tokenized on whitespace and the FTS5 query
+// syntax (operators, column filters, etc.) is not supported.
+func (dao *Dao) RecordsSearchFilter(collection *models.Collection, text string, orderByRank bool) func(q *dbx.SelectQuery) error {
+	return func(q *dbx.SelectQuery) error {
+		fields := collection.SearchFields()
+		if len(fields) == 0 {
+			return errors.New("the collection doesn't have search fields")
+		}
+
+		terms := strings.Fields(text)
+		if len(terms) == 0 {
+			return nil // nothing to search
+		}
+
+		if !dao.HasRecordsSearchIndex(collection) {
+			// LIKE fallback
+			for i, term := range terms {
+				param := fmt.Sprintf("search%d", i)
+				exprs := make([]dbx.Expression, len(fields))
+				for j, name := range fields {
+					exprs[j] = dbx.NewExp(
+						fmt.Sprintf("[[%s.%s]] LIKE {:%s} ESCAPE '\\'", collection.Name, name, param),
+						dbx.Params{param: "%" + escapeLikeTerm(term) + "%"},
+					)
+				}
+				q.AndWhere(dbx.Or(exprs...))
+			}
+
+			return nil
+		}
+
+		// quote the terms to prevent FTS5 query syntax injection
+		quoted := make([]string, len(terms))
+		for i, term := range terms {
+			quoted[i] = `"` + strings.ReplaceAll(term, `"`, `""`) + `"`
+		}
+
+		searchTableName := SearchTableName(collection)
+
+		q.InnerJoin(
+			fmt.Sprintf("{{%s}}", searchTableName),
+			dbx.NewExp(fmt.Sprintf("[[%s.%s]] = [[%s.%s]]", searchTableName, schema.FieldNameId, collection.Name, schema.FieldNameId)),
+		)
+		q.AndWhere(dbx.NewExp(
+			fmt.Sprintf("[[%s]] MATCH {:searchQuery}", searchTableName),
+			dbx.Params{"searchQuery": strings.Join(quoted, " ")},
+		))
+
+		if orderByRank {
+			q.OrderBy(fmt.Sprintf("[[%s.rank]] ASC", searchTableName))
+		}
+
+		return nil
+	}
+}
+
+func escapeLikeTerm(term string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(term)
+}
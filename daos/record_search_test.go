@@ -0,0 +1,312 @@
+package daos_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/unkod/space/daos"
+	"github.com/unkod/space/models"
+	"github.com/unkod/space/models/schema"
+	"github.com/unkod/space/tests"
+	"github.com/unkod/space/tools/types"
+)
+
+func enableSearchFields(t *testing.T, app *tests.TestApp, collectionNameOrId string, fields ...string) *models.Collection {
+	collection, err := app.Dao().FindCollectionByNameOrId(collectionNameOrId)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	collection.Options["searchFields"] = fields
+
+	if err := app.Dao().SaveCollection(collection); err != nil {
+		t.Fatal(err)
+	}
+
+	return collection
+}
+
+func searchRecordIds(t *testing.T, dao *daos.Dao, collection *models.Collection, text string) []string {
+	records := []*models.Record{}
+
+	query := dao.RecordQuery(collection)
+	if err := dao.RecordsSearchFilter(collection, text, true)(query); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := query.All(&records); err != nil {
+		t.Fatal(err)
+	}
+
+	ids := make([]string, len(records))
+	for i, r := range records {
+		ids[i] = r.Id
+	}
+
+	return ids
+}
+
+func TestIsFTS5Supported(t *testing.T) {
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	if !app.Dao().IsFTS5Supported() {
+		t.Fatal("Expected FTS5 to be supported by the test db driver")
+	}
+}
+
+func TestRebuildRecordsSearchIndex(t *testing.T) {
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	demo1, err := app.Dao().FindCollectionByNameOrId("demo1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if app.Dao().HasRecordsSearchIndex(demo1) {
+		t.Fatal("Expected demo1 to not have search index")
+	}
+
+	// saving the collection with search fields should create and populate the index
+	demo2 := enableSearchFields(t, app, "demo2", "title")
+	if !app.Dao().HasRecordsSearchIndex(demo2) {
+		t.Fatal("Expected demo2 search index to be created")
+	}
+
+	var total int
+	err = app.Dao().DB().Select("count(*)").From(daos.SearchTableName(demo2)).Row(&total)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if total != 3 {
+		t.Fatalf("Expected 3 indexed records, got %d", total)
+	}
+
+	// explicit rebuild
+	if err := app.Dao().RebuildRecordsSearchIndex(demo2); err != nil {
+		t.Fatal(err)
+	}
+	if !app.Dao().HasRecordsSearchIndex(demo2) {
+		t.Fatal("Expected demo2 search index to be recreated")
+	}
+
+	// removing the search fields should drop the index
+	demo2 = enableSearchFields(t, app, "demo2")
+	if app.Dao().HasRecordsSearchIndex(demo2) {
+		t.Fatal("Expected demo2 search index to be dropped")
+	}
+
+	// deleting the collection should drop the index
+	newCollection := &models.Collection{
+		Name: "search_test",
+		Type: models.CollectionTypeBase,
+		Schema: schema.NewSchema(
+			&schema.SchemaField{Name: "title", Type: schema.FieldTypeText},
+		),
+		Options: types.JsonMap{"searchFields": []string{"title"}},
+	}
+	if err := app.Dao().SaveCollection(newCollection); err != nil {
+		t.Fatal(err)
+	}
+	if !app.Dao().HasRecordsSearchIndex(newCollection) {
+		t.Fatal("Expected search_test search index to be created")
+	}
+	if err := app.Dao().DeleteCollection(newCollection); err != nil {
+		t.Fatal(err)
+	}
+	if app.Dao().HasRecordsSearchIndex(newCollection) {
+		t.Fatal("Expected search_test search index to be dropped")
+	}
+}
+
+func TestRecordsSearchIndexSync(t *testing.T) {
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	collection := enableSearchFields(t, app, "demo2", "title")
+
+	// create
+	record := models.NewRecord(collection)
+	record.Set("title", "lorem ipsum")
+	if err := app.Dao().SaveRecord(record); err != nil {
+		t.Fatal(err)
+	}
+	if ids := searchRecordIds(t, app.Dao(), collection, "lorem"); strings.Join(ids, ",") != record.Id {
+		t.Fatalf("Expected only the new record to be found, got %v", ids)
+	}
+
+	// update
+	record.Set("title", "dolor sit")
+	if err := app.Dao().SaveRecord(record); err != nil {
+		t.Fatal(err)
+	}
+	if ids := searchRecordIds(t, app.Dao(), collection, "lorem"); len(ids) != 0 {
+		t.Fatalf("Expected the old value to be removed from the index, got %v", ids)
+	}
+	if ids := searchRecordIds(t, app.Dao(), collection, "dolor"); strings.Join(ids, ",") != record.Id {
+		t.Fatalf("Expected the updated record to be found, got %v", ids)
+	}
+
+	// delete
+	if err := app.Dao().DeleteRecord(record); err != nil {
+		t.Fatal(err)
+	}
+	if ids := searchRecordIds(t, app.Dao(), collection, "dolor"); len(ids) != 0 {
+		t.Fatalf("Expected the deleted record to be removed from the index, got %v", ids)
+	}
+}
+
+func TestRecordsSearchFilter(t *testing.T) {
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	demo1, err := app.Dao().FindCollectionByNameOrId("demo1")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	demo2 := enableSearchFields(t, app, "demo2", "title")
+
+	// collection without search index (eg. FTS5 not supported)
+	demo4 := enableSearchFields(t, app, "demo4", "title")
+	if _, err := app.Dao().DB().NewQuery("DROP TABLE " + daos.SearchTableName(demo4)).Execute(); err != nil {
+		t.Fatal(err)
+	}
+
+	scenarios := []struct {
+		name          string
+		collection    *models.Collection
+		text          string
+		orderByRank   bool
+		expectError   bool
+		expectedSQL   []string
+		unexpectedSQL []string
+	}{
+		{
+			"collection without search fields",
+			demo1,
+			"test",
+			true,
+			true,
+			nil,
+			nil,
+		},
+		{
+			"empty search text",
+			demo2,
+			"   ",
+			true,
+			false,
+			nil,
+			[]string{"MATCH", "LIKE"},
+		},
+		{
+			"search index with rank",
+			demo2,
+			`test "1`,
+			true,
+			false,
+			[]string{
+				"INNER JOIN {{_search_" + demo2.Id + "}} ON [[_search_" + demo2.Id + ".id]] = [[demo2.id]]",
+				"[[_search_" + demo2.Id + "]] MATCH {:searchQuery}",
+				"ORDER BY [[_search_" + demo2.Id + ".rank]] ASC",
+			},
+			nil,
+		},
+		{
+			"search index without rank",
+			demo2,
+			"test",
+			false,
+			false,
+			[]string{"MATCH"},
+			[]string{"ORDER BY"},
+		},
+		{
+			"LIKE fallback",
+			demo4,
+			"test 1",
+			true,
+			false,
+			[]string{
+				"([[demo4.title]] LIKE {:search0} ESCAPE '\\') AND ([[demo4.title]] LIKE {:search1} ESCAPE '\\')",
+			},
+			[]string{"MATCH", "ORDER BY"},
+		},
+	}
+
+	for _, s := range scenarios {
+		query := app.Dao().RecordQuery(s.collection)
+
+		err := app.Dao().RecordsSearchFilter(s.collection, s.text, s.orderByRank)(query)
+
+		hasErr := err != nil
+		if hasErr != s.expectError {
+			t.Errorf("[%s] Expected hasErr %v, got %v (%v)", s.name, s.expectError, hasErr, err)
+			continue
+		}
+
+		rawSQL := query.Build().SQL()
+
+		for _, part := range s.expectedSQL {
+			if !strings.Contains(rawSQL, part) {
+				t.Errorf("[%s] Expected %q in\n%v", s.name, part, rawSQL)
+			}
+		}
+
+		for _, part := range s.unexpectedSQL {
+			if strings.Contains(rawSQL, part) {
+				t.Errorf("[%s] Didn't expect %q in\n%v", s.name, part, rawSQL)
+			}
+		}
+	}
+}
+
+func TestRecordsSearchFilterResults(t *testing.T) {
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	collection := enableSearchFields(t, app, "demo2", "title")
+
+	titles := []string{"apple banana", "apple apple apple", "cherry 100%"}
+	for _, title := range titles {
+		record := models.NewRecord(collection)
+		record.Set("title", title)
+		if err := app.Dao().SaveRecord(record); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	assertTitles := func(name string, text string, expected []string) {
+		records := []*models.Record{}
+		query := app.Dao().RecordQuery(collection)
+		if err := app.Dao().RecordsSearchFilter(collection, text, true)(query); err != nil {
+			t.Fatal(err)
+		}
+		if err := query.All(&records); err != nil {
+			t.Fatalf("[%s] %v", name, err)
+		}
+
+		result := make([]string, len(records))
+		for i, r := range records {
+			result[i] = r.GetString("title")
+		}
+
+		if strings.Join(result, "|") != strings.Join(expected, "|") {
+			t.Errorf("[%s] Expected %v, got %v", name, expected, result)
+		}
+	}
+
+	// FTS5 ranked results
+	assertTitles("fts single term", "apple", []string{"apple apple apple", "apple banana"})
+	assertTitles("fts multiple terms", "banana apple", []string{"apple banana"})
+	assertTitles("fts query syntax", "apple OR", nil)
+
+	// LIKE fallback
+	if _, err := app.Dao().DB().NewQuery("DROP TABLE " + daos.SearchTableName(collection)).Execute(); err != nil {
+		t.Fatal(err)
+	}
+	assertTitles("like single term", "banan", []string{"apple banana"})
+	assertTitles("like escaped term", "0%", []string{"cherry 100%"})
+	assertTitles("like escaped wildcard", "_", nil)
+}
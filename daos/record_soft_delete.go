@@ -0,0 +1,98 @@
+package daos
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/pocketbase/dbx"
+	"github.com/unkod/space/models"
+	"github.com/unkod/space/models/schema"
+	"github.com/unkod/space/tools/types"
+)
+
+// Soft-deleted records filter modes (see [SoftDeletedFilter]).
+const (
+	SoftDeletedExclude = ""
+	SoftDeletedInclude = "include"
+	SoftDeletedOnly    = "only"
+)
+
+// SoftDeletedFilter returns a record query filter function that
+// excludes (SoftDeletedExclude), includes (SoftDeletedInclude) or
+// returns only (SoftDeletedOnly) the soft-deleted collection records.
+//
+// For collections without soft-delete enabled SoftDeletedOnly matches
+// no records and the other modes are no-op.
+//
+// Example:
+//
+//	dao.FindRecordById("posts", "RECORD_ID", daos.SoftDeletedFilter(collection, daos.SoftDeletedOnly))
+func SoftDeletedFilter(collection *models.Collection, mode string) func(q *dbx.SelectQuery) error {
+	return func(q *dbx.SelectQuery) error {
+		var expr dbx.Expression
+
+		column := collection.Name + "." + schema.FieldNameDeleted
+
+		switch mode {
+		case SoftDeletedExclude:
+			if collection.HasSoftDelete() {
+				expr = dbx.HashExp{column: ""}
+			}
+		case SoftDeletedOnly:
+			if collection.HasSoftDelete() {
+				expr = dbx.Not(dbx.HashExp{column: ""})
+			} else {
+				expr = dbx.NewExp("1=0")
+			}
+		case SoftDeletedInclude:
+			// no filter
+		default:
+			return fmt.Errorf("invalid soft-deleted filter mode %q", mode)
+		}
+
+		if expr != nil {
+			q.AndWhere(expr)
+		}
+
+		return nil
+	}
+}
+
+// SoftDeleteRecord marks the provided record as deleted by setting
+// its "deleted" field to the current datetime, without removing
+// the record table row or any of its files.
+//
+// Unlike [Dao.DeleteRecord], the operation is not cascaded and the
+// relation references to the record are left intact so that its restore
+// (see [Dao.RestoreRecord]) is lossless. For the same reason, the
+// soft-deleted record keeps "occupying" its unique field values.
+//
+// Returns an error if the record collection doesn't have soft-delete
+// enabled or the record is already soft-deleted.
+func (dao *Dao) SoftDeleteRecord(record *models.Record) error {
+	if record.IsSoftDeleted() {
+		return errors.New("the record is already soft-deleted")
+	}
+
+	if err := record.SetDeleted(types.NowDateTime()); err != nil {
+		return err
+	}
+
+	return dao.SaveRecord(record)
+}
+
+// RestoreRecord restores the provided soft-deleted record by
+// clearing its "deleted" field.
+//
+// Returns an error if the record is not soft-deleted.
+func (dao *Dao) RestoreRecord(record *models.Record) error {
+	if !record.IsSoftDeleted() {
+		return errors.New("the record is not soft-deleted")
+	}
+
+	if err := record.SetDeleted(types.DateTime{}); err != nil {
+		return err
+	}
+
+	return dao.SaveRecord(record)
+}
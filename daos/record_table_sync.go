@@ -13,6 +13,10 @@ import (
 	"github.com/unkod/space/tools/security"
 )
 
+// softDeleteColDefinition is the record table column definition
+// of the soft-delete "deleted" field.
+const softDeleteColDefinition = "TEXT DEFAULT '' NOT NULL"
+
 // SyncRecordTableSchema compares the two provided collections
 // and applies the necessary related record table changes.
 //
@@ -37,6 +41,14 @@ func (dao *Dao) SyncRecordTableSchema(newCollection *models.Collection, oldColle
 				cols[schema.FieldNamePasswordHash] = "TEXT NOT NULL"
 				cols[schema.FieldNameLastResetSentAt] = "TEXT DEFAULT '' NOT NULL"
 				cols[schema.FieldNameLastVerificationSentAt] = "TEXT DEFAULT '' NOT NULL"
+				cols[schema.FieldNameMFASecret] = "TEXT DEFAULT '' NOT NULL"
+				cols[schema.FieldNameMFAEnabled] = "BOOLEAN DEFAULT FALSE NOT NULL"
+				cols[schema.FieldNameMFABackupCodes] = "JSON DEFAULT '[]' NOT NULL"
+				cols[schema.FieldNameMFALastCounter] = "INTEGER DEFAULT 0 NOT NULL"
+			}
+
+			if newCollection.HasSoftDelete() {
+				cols[schema.FieldNameDeleted] = softDeleteColDefinition
 			}
 
 			// ensure that the new collection has an id
@@ -49,6 +61,9 @@ func (dao *Dao) SyncRecordTableSchema(newCollection *models.Collection, oldColle
 
 			// add schema field definitions
 			for _, field := range newCollection.Schema.Fields() {
+				if field.Type == schema.FieldTypeComputed {
+					continue // evaluated on read
+				}
 				cols[field.Name] = field.ColDefinition()
 			}
 
@@ -74,7 +89,11 @@ func (dao *Dao) SyncRecordTableSchema(newCollection *models.Collection, oldColle
 				}
 			}
 
-			return txDao.createCollectionIndexes(newCollection)
+			if err := txDao.createCollectionIndexes(newCollection); err != nil {
+				return err
+			}
+
+			return txDao.RebuildRecordsSearchIndex(newCollection)
 		}
 
 		// update
@@ -91,6 +110,15 @@ func (dao *Dao) SyncRecordTableSchema(newCollection *models.Collection, oldColle
 			return err
 		}
 
+		// drop the old search index to prevent the sync triggers
+		// from blocking the search fields columns changes
+		rebuildSearchIndex := hasSearchIndexChanges(newCollection, oldCollection)
+		if rebuildSearchIndex {
+			if err := txDao.dropRecordsSearchIndex(oldCollection); err != nil {
+				return err
+			}
+		}
+
 		// check for renamed table
 		if !strings.EqualFold(oldTableName, newTableName) {
 			_, err := txDao.DB().RenameTable("{{"+oldTableName+"}}", "{{"+newTableName+"}}").Execute()
@@ -105,6 +133,10 @@ func (dao *Dao) SyncRecordTableSchema(newCollection *models.Collection, oldColle
 				continue // exist
 			}
 
+			if oldField.Type == schema.FieldTypeComputed {
+				continue // no column
+			}
+
 			_, err := txDao.DB().DropColumn(newTableName, oldField.Name).Execute()
 			if err != nil {
 				return fmt.Errorf("failed to drop column %s - %w", oldField.Name, err)
@@ -116,6 +148,10 @@ func (dao *Dao) SyncRecordTableSchema(newCollection *models.Collection, oldColle
 		// check for new or renamed columns
 		toRename := map[string]string{}
 		for _, field := range newSchema.Fields() {
+			if field.Type == schema.FieldTypeComputed {
+				continue // no column
+			}
+
 			oldField := oldSchema.GetFieldById(field.Id)
 			// Note:
 			// We are using a temporary column name when adding or renaming columns
@@ -154,14 +190,87 @@ func (dao *Dao) SyncRecordTableSchema(newCollection *models.Collection, oldColle
 			}
 		}
 
+		// check for enabled/disabled soft-delete
+		if newCollection.HasSoftDelete() != oldCollection.HasSoftDelete() {
+			var err error
+			if newCollection.HasSoftDelete() {
+				_, err = txDao.DB().AddColumn(newTableName, schema.FieldNameDeleted, softDeleteColDefinition).Execute()
+			} else {
+				_, err = txDao.DB().DropColumn(newTableName, schema.FieldNameDeleted).Execute()
+			}
+			if err != nil {
+				return fmt.Errorf("failed to sync the soft-delete column - %w", err)
+			}
+		}
+
 		if err := txDao.normalizeSingleVsMultipleFieldChanges(newCollection, oldCollection); err != nil {
 			return err
 		}
 
-		return txDao.createCollectionIndexes(newCollection)
+		// encrypt/decrypt the existing values of the fields with changed encryption
+		encryptionChanges := encryptionChangedFieldNames(newCollection, oldCollection)
+		if err := txDao.reencryptRecordColumns(newCollection, encryptionChanges); err != nil {
+			return err
+		}
+
+		if err := txDao.createCollectionIndexes(newCollection); err != nil {
+			return err
+		}
+
+		if rebuildSearchIndex {
+			return txDao.RebuildRecordsSearchIndex(newCollection)
+		}
+
+		return nil
 	})
 }
 
+// hasSearchIndexChanges checks whether the records search index
+// of oldCollection has to be rebuilt after its update to newCollection.
+func hasSearchIndexChanges(newCollection, oldCollection *models.Collection) bool {
+	oldFields := oldCollection.SearchFields()
+	newFields := newCollection.SearchFields()
+
+	if strings.Join(oldFields, ",") != strings.Join(newFields, ",") ||
+		!strings.EqualFold(oldCollection.Name, newCollection.Name) {
+		return true
+	}
+
+	// check for deleted or renamed search fields
+	for _, name := range oldFields {
+		oldField := oldCollection.Schema.GetFieldByName(name)
+		if oldField == nil {
+			continue
+		}
+
+		newField := newCollection.Schema.GetFieldById(oldField.Id)
+		if newField == nil || newField.Name != oldField.Name {
+			return true
+		}
+	}
+
+	return false
+}
+
+// encryptionChangedFieldNames returns the names of the newCollection
+// fields whose encryption was enabled or disabled.
+func encryptionChangedFieldNames(newCollection, oldCollection *models.Collection) []string {
+	result := []string{}
+
+	for _, newField := range newCollection.Schema.Fields() {
+		oldField := oldCollection.Schema.GetFieldById(newField.Id)
+		if oldField == nil {
+			continue // new fields don't have existing values
+		}
+
+		if IsEncryptedField(newField) != IsEncryptedField(oldField) {
+			result = append(result, newField.Name)
+		}
+	}
+
+	return result
+}
+
 func (dao *Dao) normalizeSingleVsMultipleFieldChanges(newCollection, oldCollection *models.Collection) error {
 	if newCollection.IsView() || oldCollection == nil {
 		return nil // view or not an update
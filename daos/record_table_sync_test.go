@@ -82,6 +82,7 @@ func TestSyncRecordTableSchema(t *testing.T) {
 				"id", "created", "updated", "test",
 				"username", "email", "verified", "emailVisibility",
 				"tokenKey", "passwordHash", "lastResetSentAt", "lastVerificationSentAt",
+				"mfaSecret", "mfaEnabled", "mfaBackupCodes", "mfaLastCounter",
 			},
 			4,
 		},
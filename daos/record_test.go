@@ -4,6 +4,7 @@ import (
 	"context"
 	"database/sql"
 	"errors"
+	"fmt"
 	"regexp"
 	"strings"
 	"testing"
@@ -293,6 +294,39 @@ func TestFindRecordsByIds(t *testing.T) {
 	}
 }
 
+func TestFindRecordsByIdsChunks(t *testing.T) {
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	ids := make([]string, 0, 1203)
+	for i := 0; i < 1200; i++ {
+		ids = append(ids, fmt.Sprintf("missing%d", i))
+	}
+	// existing and duplicated ids
+	ids = append(ids, "0yxhwia2amd8gec", "llvuca81nly1qls", "0yxhwia2amd8gec")
+
+	var totalQueries int
+	app.Dao().ConcurrentDB().(*dbx.DB).QueryLogFunc = func(ctx context.Context, t time.Duration, sql string, rows *sql.Rows, err error) {
+		if strings.Contains(sql, "`demo2`.`id` IN") {
+			totalQueries++
+		}
+	}
+
+	records, err := app.Dao().FindRecordsByIds("demo2", ids)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(records) != 2 {
+		t.Fatalf("Expected 2 records, got %d", len(records))
+	}
+
+	// 1202 unique ids in chunks of 500
+	if totalQueries != 3 {
+		t.Fatalf("Expected 3 chunk queries, got %d", totalQueries)
+	}
+}
+
 func TestFindRecordsByExpr(t *testing.T) {
 	app, _ := tests.NewTestApp()
 	defer app.Cleanup()
@@ -530,6 +564,31 @@ func TestFindRecordsByFilter(t *testing.T) {
 				"llvuca81nly1qls",
 			},
 		},
+		{
+			"with placeholder params injection attempt",
+			"demo2",
+			"title = {:title}",
+			"",
+			10,
+			0,
+			[]dbx.Params{{"title": "test1' || title != '"}},
+			false,
+			[]string{},
+		},
+		{
+			"with multiple placeholder params maps",
+			"demo2",
+			"title = {:title} || active = {:active}",
+			"title",
+			10,
+			0,
+			[]dbx.Params{{"title": "test3"}, {"active": false}},
+			false,
+			[]string{
+				"llvuca81nly1qls",
+				"0yxhwia2amd8gec",
+			},
+		},
 	}
 
 	for _, s := range scenarios {
@@ -646,6 +705,177 @@ func TestFindFirstRecordByFilter(t *testing.T) {
 	}
 }
 
+func TestEachRecord(t *testing.T) {
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	scenarios := []struct {
+		name               string
+		collectionIdOrName string
+		filter             string
+		params             []dbx.Params
+		expectError        bool
+		expectRecordIds    []string
+	}{
+		{
+			"missing collection",
+			"missing",
+			"",
+			nil,
+			true,
+			nil,
+		},
+		{
+			"invalid filter",
+			"demo2",
+			"someMissingField > 1",
+			nil,
+			true,
+			nil,
+		},
+		{
+			"empty filter",
+			"demo2",
+			"",
+			nil,
+			false,
+			[]string{"0yxhwia2amd8gec", "achvryl401bhse3", "llvuca81nly1qls"},
+		},
+		{
+			"with placeholder params",
+			"demo2",
+			"active = {:active}",
+			[]dbx.Params{{"active": true}},
+			false,
+			[]string{"0yxhwia2amd8gec", "achvryl401bhse3"},
+		},
+		{
+			"with relation filter",
+			"demo4",
+			"rel_many_no_cascade_required.title ~ 'test'",
+			nil,
+			false,
+			[]string{"i9naidtvr6qsgb4", "qzaqccwrmva4o1n"},
+		},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			ids := []string{}
+
+			err := app.Dao().EachRecord(s.collectionIdOrName, s.filter, func(record *models.Record) error {
+				ids = append(ids, record.Id)
+				return nil
+			}, s.params...)
+
+			hasErr := err != nil
+			if hasErr != s.expectError {
+				t.Fatalf("Expected hasErr to be %v, got %v (%v)", s.expectError, hasErr, err)
+			}
+
+			if hasErr {
+				return
+			}
+
+			if strings.Join(ids, ",") != strings.Join(s.expectRecordIds, ",") {
+				t.Fatalf("Expected records %v, got %v", s.expectRecordIds, ids)
+			}
+		})
+	}
+}
+
+func TestEachRecordBatches(t *testing.T) {
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	collection, err := app.Dao().FindCollectionByNameOrId("demo2")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// seed records
+	totalSeeded := 2500
+	err = app.Dao().RunInTransaction(func(txDao *daos.Dao) error {
+		for i := 0; i < totalSeeded; i++ {
+			record := models.NewRecord(collection)
+			record.Set("title", fmt.Sprintf("seed%d", i))
+			record.Set("active", i%2 == 0)
+			if err := txDao.SaveRecord(record); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var totalQueries, totalOffsetQueries int
+	app.Dao().ConcurrentDB().(*dbx.DB).QueryLogFunc = func(ctx context.Context, t time.Duration, sql string, rows *sql.Rows, err error) {
+		if strings.Contains(sql, "SELECT `demo2`.*") {
+			totalQueries++
+			if strings.Contains(sql, "OFFSET") {
+				totalOffsetQueries++
+			}
+		}
+	}
+
+	t.Run("all active records", func(t *testing.T) {
+		totalQueries = 0
+
+		var lastId string
+		var total int
+		err := app.Dao().EachRecord("demo2", "active = true && title ~ 'seed'", func(record *models.Record) error {
+			if record.Id <= lastId {
+				return fmt.Errorf("expected ascending and unique ids, got %q after %q", record.Id, lastId)
+			}
+			lastId = record.Id
+			total++
+			return nil
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if total != totalSeeded/2 {
+			t.Fatalf("Expected %d records, got %d", totalSeeded/2, total)
+		}
+
+		// 1250 records in batches of 500
+		if totalQueries != 3 {
+			t.Fatalf("Expected 3 batch queries, got %d", totalQueries)
+		}
+
+		if totalOffsetQueries != 0 {
+			t.Fatalf("Expected no OFFSET queries, got %d", totalOffsetQueries)
+		}
+	})
+
+	t.Run("stop on error", func(t *testing.T) {
+		totalQueries = 0
+
+		var calls int
+		err := app.Dao().EachRecord("demo2", "", func(record *models.Record) error {
+			calls++
+			if calls == 700 {
+				return errors.New("test")
+			}
+			return nil
+		})
+		if err == nil || err.Error() != "test" {
+			t.Fatalf("Expected the callback error, got %v", err)
+		}
+
+		if calls != 700 {
+			t.Fatalf("Expected 700 calls, got %d", calls)
+		}
+
+		if totalQueries != 2 {
+			t.Fatalf("Expected 2 batch queries, got %d", totalQueries)
+		}
+	})
+}
+
 func TestCanAccessRecord(t *testing.T) {
 	app, _ := tests.NewTestApp()
 	defer app.Cleanup()
@@ -862,6 +1092,89 @@ func TestIsRecordValueUnique(t *testing.T) {
 	}
 }
 
+func TestFindUniqueConflictRecords(t *testing.T) {
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	// collection with composite and partial unique indexes
+	composite := &models.Collection{}
+	composite.Name = "upsert_test"
+	composite.Schema = schema.NewSchema(
+		&schema.SchemaField{Name: "a", Type: schema.FieldTypeText},
+		&schema.SchemaField{Name: "b", Type: schema.FieldTypeNumber},
+		&schema.SchemaField{Name: "c", Type: schema.FieldTypeText},
+	)
+	composite.Indexes = types.JsonArray[string]{
+		"CREATE UNIQUE INDEX idx_upsert_test_ab ON upsert_test (a, b)",
+		"CREATE UNIQUE INDEX idx_upsert_test_c ON upsert_test (c COLLATE NOCASE) WHERE c != ''",
+		"CREATE INDEX idx_upsert_test_a ON upsert_test (a)",
+	}
+	if err := app.Dao().SaveCollection(composite); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, data := range []map[string]any{
+		{"id": "upsert000000001", "a": "test1", "b": 1, "c": "Unique1"},
+		{"id": "upsert000000002", "a": "test1", "b": 2, "c": ""},
+		{"id": "upsert000000003", "a": "test2", "b": 1, "c": ""},
+	} {
+		record := models.NewRecord(composite)
+		record.Load(data)
+		if err := app.Dao().SaveRecord(record); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	scenarios := []struct {
+		collection  string
+		data        map[string]any
+		expectedIds []string
+	}{
+		// no unique columns
+		{"demo2", map[string]any{"active": true}, nil},
+		{"demo2", map[string]any{"title": "missing"}, nil},
+		{"demo2", map[string]any{"title": "test2"}, []string{"achvryl401bhse3"}},
+		{"demo2", map[string]any{"id": "llvuca81nly1qls"}, []string{"llvuca81nly1qls"}},
+		{"demo2", map[string]any{"id": "llvuca81nly1qls", "title": "test2"}, []string{"llvuca81nly1qls", "achvryl401bhse3"}},
+		// system auth unique fields
+		{"users", map[string]any{"username": "USERS75657"}, []string{"4q1xlclmfloku33"}},
+		{"users", map[string]any{"email": "test2@example.com"}, []string{"oap640cot4yru2s"}},
+		{"users", map[string]any{"email": ""}, nil},
+		// composite index with missing column
+		{"upsert_test", map[string]any{"a": "test1"}, nil},
+		{"upsert_test", map[string]any{"a": "test1", "b": "2"}, []string{"upsert000000002"}},
+		{"upsert_test", map[string]any{"a": "test1", "b": 3}, nil},
+		// collated partial index
+		{"upsert_test", map[string]any{"c": "UNIQUE1"}, []string{"upsert000000001"}},
+		{"upsert_test", map[string]any{"c": ""}, nil},
+		{"upsert_test", map[string]any{"a": "test2", "b": 1, "c": "unique1"}, []string{"upsert000000001", "upsert000000003"}},
+	}
+
+	for i, s := range scenarios {
+		collection, err := app.Dao().FindCollectionByNameOrId(s.collection)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		records, err := app.Dao().FindUniqueConflictRecords(collection, s.data)
+		if err != nil {
+			t.Errorf("(%d) Unexpected error %v", i, err)
+			continue
+		}
+
+		if len(records) != len(s.expectedIds) {
+			t.Errorf("(%d) Expected %d records, got %d", i, len(s.expectedIds), len(records))
+			continue
+		}
+
+		for _, r := range records {
+			if !list.ExistInSlice(r.Id, s.expectedIds) {
+				t.Errorf("(%d) Couldn't find id %s in %v", i, r.Id, s.expectedIds)
+			}
+		}
+	}
+}
+
 func TestFindAuthRecordByToken(t *testing.T) {
 	app, _ := tests.NewTestApp()
 	defer app.Cleanup()
@@ -0,0 +1,175 @@
+package daos
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/pocketbase/dbx"
+	"github.com/unkod/space/models"
+	"github.com/unkod/space/models/schema"
+	"github.com/unkod/space/tools/inflector"
+)
+
+// TruncateCollection deletes all records of the provided collection
+// in a single transaction.
+//
+// If skipHooks is false, the records are deleted one by one with
+// [Dao.DeleteRecord] (aka. the regular delete behavior with the model hooks).
+//
+// If skipHooks is true, the records are deleted with a single "DELETE FROM"
+// query and NO model hooks are triggered for the deleted records and their
+// linked external auths (this also means no realtime events, no audit logs, etc.).
+// The relation references from the other collections are still cascaded (with
+// the regular per record behavior for the referencing records) and the caller
+// is responsible for the deleted records storage files cleanup
+// (eg. by deleting the collection [models.Collection.BaseFilesPath] prefix).
+//
+// Returns the number of deleted records.
+func (dao *Dao) TruncateCollection(collection *models.Collection, skipHooks bool) (int64, error) {
+	if collection.IsView() {
+		return 0, errors.New("view collections cannot be truncated")
+	}
+
+	// note: the select is outside of the transaction to minimize
+	// SQLITE_BUSY errors when mixing read&write in a single transaction
+	refs, err := dao.FindCollectionReferences(collection)
+	if err != nil {
+		return 0, err
+	}
+
+	var total int64
+
+	txErr := dao.RunInTransaction(func(txDao *Dao) error {
+		if !skipHooks {
+			var err error
+			total, err = txDao.truncateRecordsWithHooks(collection)
+			return err
+		}
+
+		for refCollection, fields := range refs {
+			// skip the view collections and the self references
+			// (the records are deleted anyway)
+			if refCollection.IsView() || refCollection.Id == collection.Id {
+				continue
+			}
+
+			for _, field := range fields {
+				if err := txDao.truncateRefRecords(collection, refCollection, field); err != nil {
+					return err
+				}
+			}
+		}
+
+		if collection.IsAuth() {
+			_, err := txDao.DB().Delete((&models.ExternalAuth{}).TableName(), dbx.HashExp{
+				"collectionId": collection.Id,
+			}).Execute()
+			if err != nil {
+				return err
+			}
+		}
+
+		result, err := txDao.DB().Delete(collection.Name, nil).Execute()
+		if err != nil {
+			return err
+		}
+
+		total, _ = result.RowsAffected()
+
+		return nil
+	})
+
+	if txErr != nil {
+		return 0, txErr
+	}
+
+	return total, nil
+}
+
+// truncateRecordsWithHooks deletes all collection records in batches
+// with the regular [Dao.DeleteRecord] behavior.
+//
+// NB! This method is expected to be called inside a transaction.
+func (dao *Dao) truncateRecordsWithHooks(collection *models.Collection) (int64, error) {
+	var total int64
+
+	batchSize := 500
+
+	for {
+		records := []*models.Record{}
+		if err := dao.RecordQuery(collection).Limit(int64(batchSize)).All(&records); err != nil {
+			return total, err
+		}
+
+		for _, record := range records {
+			// the record could have been already deleted as part of a self reference cascade
+			var exists bool
+			err := dao.RecordQuery(collection).
+				Select("(1)").
+				AndWhere(dbx.HashExp{schema.FieldNameId: record.Id}).
+				Limit(1).
+				Row(&exists)
+			if err != nil || !exists {
+				continue
+			}
+
+			if err := dao.DeleteRecord(record); err != nil {
+				return total, err
+			}
+
+			total++
+		}
+
+		if len(records) < batchSize {
+			return total, nil
+		}
+	}
+}
+
+// truncateRefRecords unsets (or cascade deletes) the field references
+// of the refCollection records to the truncated collection.
+//
+// NB! This method is expected to be called inside a transaction.
+func (dao *Dao) truncateRefRecords(collection *models.Collection, refCollection *models.Collection, field *schema.SchemaField) error {
+	options, _ := field.Options.(*schema.RelationOptions)
+	if options == nil {
+		return errors.New("relation field options are not initialized")
+	}
+
+	columnName := inflector.Columnify(field.Name)
+
+	query := dao.RecordQuery(refCollection).
+		AndWhere(dbx.NewExp(fmt.Sprintf("[[%s]] != '' AND [[%s]] != '[]'", columnName, columnName)))
+
+	// each processed batch no longer matches the query
+	// so always fetch the first batchSize items until there is none
+	batchSize := 500
+	for {
+		refRecords := []*models.Record{}
+		if err := query.Limit(int64(batchSize)).All(&refRecords); err != nil {
+			return err
+		}
+
+		if len(refRecords) == 0 {
+			return nil
+		}
+
+		for _, refRecord := range refRecords {
+			if options.CascadeDelete {
+				if err := dao.DeleteRecord(refRecord); err != nil {
+					return err
+				}
+				continue
+			}
+
+			if field.Required {
+				return fmt.Errorf("the collection cannot be truncated because it is part of a required reference in record %s (%s collection)", refRecord.Id, refCollection.Name)
+			}
+
+			refRecord.Set(field.Name, field.PrepareValue(nil))
+			if err := dao.SaveRecord(refRecord); err != nil {
+				return err
+			}
+		}
+	}
+}
@@ -0,0 +1,154 @@
+package daos_test
+
+import (
+	"testing"
+
+	"github.com/unkod/space/models/schema"
+	"github.com/unkod/space/tests"
+)
+
+func TestTruncateCollection(t *testing.T) {
+	scenarios := []struct {
+		name                 string
+		collection           string
+		skipHooks            bool
+		expectError          bool
+		expectTotal          int64
+		expectDeleteEvents   int
+		expectRemainingRefs  map[string]int // ref collection -> remaining records
+		expectEmptyRefFields map[string][]string
+	}{
+		{
+			"view collection",
+			"view1",
+			false,
+			true,
+			0,
+			0,
+			nil,
+			nil,
+		},
+		{
+			"required non-cascade references (with hooks)",
+			"demo3",
+			false,
+			true,
+			0,
+			0,
+			map[string]int{"demo3": 4, "demo4": 2},
+			nil,
+		},
+		{
+			"required non-cascade references (skip hooks)",
+			"demo3",
+			true,
+			true,
+			0,
+			0,
+			map[string]int{"demo3": 4, "demo4": 2},
+			nil,
+		},
+		{
+			"non-cascade references (with hooks)",
+			"demo2",
+			false,
+			false,
+			3,
+			3,
+			map[string]int{"demo2": 0, "users": 3},
+			map[string][]string{"users": {"4q1xlclmfloku33", "bgs820n361vj1qd"}},
+		},
+		{
+			"non-cascade references (skip hooks)",
+			"demo2",
+			true,
+			false,
+			3,
+			0,
+			map[string]int{"demo2": 0, "users": 3},
+			map[string][]string{"users": {"4q1xlclmfloku33", "bgs820n361vj1qd"}},
+		},
+		{
+			"self and non-cascade multiple references (skip hooks)",
+			"demo4",
+			true,
+			false,
+			2,
+			0,
+			map[string]int{"demo4": 0, "demo5": 2},
+			map[string][]string{"demo5": {"qjeql998mtp1azp"}},
+		},
+		{
+			"auth collection with cascade references (with hooks)",
+			"users",
+			false,
+			false,
+			3,
+			7, // 3 users + 2 external auths + 2 demo1 records
+			map[string]int{"users": 0, "demo1": 1, "_externalAuths": 0},
+			nil,
+		},
+		{
+			"auth collection with cascade references (skip hooks)",
+			"users",
+			true,
+			false,
+			3,
+			2, // only the cascade deleted demo1 records
+			map[string]int{"users": 0, "demo1": 1, "_externalAuths": 0},
+			nil,
+		},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			app, _ := tests.NewTestApp()
+			defer app.Cleanup()
+
+			collection, err := app.Dao().FindCollectionByNameOrId(s.collection)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			total, err := app.Dao().TruncateCollection(collection, s.skipHooks)
+
+			hasErr := err != nil
+			if hasErr != s.expectError {
+				t.Fatalf("Expected hasErr %v, got %v (%v)", s.expectError, hasErr, err)
+			}
+
+			if total != s.expectTotal {
+				t.Fatalf("Expected total %d, got %d", s.expectTotal, total)
+			}
+
+			if deleteEvents := app.EventCalls["OnModelAfterDelete"]; deleteEvents != s.expectDeleteEvents {
+				t.Fatalf("Expected %d OnModelAfterDelete calls, got %d", s.expectDeleteEvents, deleteEvents)
+			}
+
+			for table, expected := range s.expectRemainingRefs {
+				var count int
+				if err := app.Dao().DB().Select("count(*)").From(table).Row(&count); err != nil {
+					t.Fatal(err)
+				}
+				if count != expected {
+					t.Fatalf("Expected %d %s records, got %d", expected, table, count)
+				}
+			}
+
+			for refCollection, ids := range s.expectEmptyRefFields {
+				records, err := app.Dao().FindRecordsByIds(refCollection, ids)
+				if err != nil || len(records) != len(ids) {
+					t.Fatalf("Failed to fetch the %s records: %v", refCollection, err)
+				}
+
+				for _, r := range records {
+					for _, field := range r.Collection().Schema.Fields() {
+						if field.Type == schema.FieldTypeRelation && len(r.GetStringSlice(field.Name)) > 0 {
+							t.Fatalf("Expected %s.%s of record %s to be empty, got %v", refCollection, field.Name, r.Id, r.Get(field.Name))
+						}
+					}
+				}
+			}
+		})
+	}
+}
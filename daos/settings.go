@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"errors"
 
+	"github.com/pocketbase/dbx"
 	"github.com/unkod/space/models"
 	"github.com/unkod/space/models/settings"
 	"github.com/unkod/space/tools/security"
@@ -22,10 +23,77 @@ func (dao *Dao) FindSettings(optEncryptionKey ...string) (*settings.Settings, er
 		return nil, err
 	}
 
+	return decodeSettings(param.Value, optEncryptionKey...)
+}
+
+// SaveSettings persists the specified settings configuration.
+//
+// If optEncryptionKey is set, then the stored serialized value will be encrypted with it.
+func (dao *Dao) SaveSettings(newSettings *settings.Settings, optEncryptionKey ...string) error {
+	return dao.SaveParam(models.ParamAppSettings, newSettings, optEncryptionKey...)
+}
+
+// SettingsVersionQuery returns a new SettingsVersion select query.
+func (dao *Dao) SettingsVersionQuery() *dbx.SelectQuery {
+	return dao.ModelQuery(&models.SettingsVersion{})
+}
+
+// FindSettingsVersionById finds a single settings history version by its id.
+func (dao *Dao) FindSettingsVersionById(id string) (*models.SettingsVersion, error) {
+	version := &models.SettingsVersion{}
+
+	err := dao.SettingsVersionQuery().
+		AndWhere(dbx.HashExp{"id": id}).
+		Limit(1).
+		One(version)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return version, nil
+}
+
+// SaveSettingsVersion inserts the provided new SettingsVersion model
+// with the previous settings state serialized as its value.
+//
+// If optEncryptionKey is set, then the stored serialized value will be encrypted with it.
+//
+// The settings versions are append-only and existing models can't be updated.
+func (dao *Dao) SaveSettingsVersion(version *models.SettingsVersion, previous *settings.Settings, optEncryptionKey ...string) error {
+	if !version.IsNew() {
+		return errors.New("the settings versions can't be modified")
+	}
+
+	value, err := encodeParamValue(previous, optEncryptionKey...)
+	if err != nil {
+		return err
+	}
+
+	version.Value = value
+
+	return dao.Save(version)
+}
+
+// DecodeSettingsVersion decodes the serialized previous settings state
+// of the provided settings version (see [Dao.FindSettings] for the
+// optEncryptionKey behavior).
+//
+// The decoded settings are initialized with the current defaults so
+// that the sections added after the version creation are still loadable.
+func (dao *Dao) DecodeSettingsVersion(version *models.SettingsVersion, optEncryptionKey ...string) (*settings.Settings, error) {
+	if len(version.Value) == 0 || string(version.Value) == "null" {
+		return nil, errors.New("the settings version doesn't have a stored value")
+	}
+
+	return decodeSettings(version.Value, optEncryptionKey...)
+}
+
+func decodeSettings(value []byte, optEncryptionKey ...string) (*settings.Settings, error) {
 	result := settings.New()
 
 	// try first without decryption
-	plainDecodeErr := json.Unmarshal(param.Value, result)
+	plainDecodeErr := json.Unmarshal(value, result)
 
 	// failed, try to decrypt
 	if plainDecodeErr != nil {
@@ -40,7 +108,7 @@ func (dao *Dao) FindSettings(optEncryptionKey ...string) (*settings.Settings, er
 		}
 
 		// decrypt
-		decrypted, decryptErr := security.Decrypt(string(param.Value), encryptionKey)
+		decrypted, decryptErr := security.Decrypt(string(value), encryptionKey)
 		if decryptErr != nil {
 			return nil, decryptErr
 		}
@@ -54,10 +122,3 @@ func (dao *Dao) FindSettings(optEncryptionKey ...string) (*settings.Settings, er
 
 	return result, nil
 }
-
-// SaveSettings persists the specified settings configuration.
-//
-// If optEncryptionKey is set, then the stored serialized value will be encrypted with it.
-func (dao *Dao) SaveSettings(newSettings *settings.Settings, optEncryptionKey ...string) error {
-	return dao.SaveParam(models.ParamAppSettings, newSettings, optEncryptionKey...)
-}
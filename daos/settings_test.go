@@ -3,8 +3,11 @@ package daos_test
 import (
 	"testing"
 
+	"github.com/unkod/space/models"
+	"github.com/unkod/space/models/settings"
 	"github.com/unkod/space/tests"
 	"github.com/unkod/space/tools/security"
+	"github.com/unkod/space/tools/types"
 )
 
 func TestSaveAndFindSettings(t *testing.T) {
@@ -48,3 +51,71 @@ func TestSaveAndFindSettings(t *testing.T) {
 		t.Fatalf("Expected settings to be changed with app name %q, got \n%v", "save_encrypted", s3)
 	}
 }
+
+func TestSettingsVersionQuery(t *testing.T) {
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	expected := "SELECT {{_settingsVersions}}.* FROM `_settingsVersions`"
+
+	sql := app.Dao().SettingsVersionQuery().Build().SQL()
+	if sql != expected {
+		t.Errorf("Expected sql %s, got %s", expected, sql)
+	}
+}
+
+func TestSaveAndDecodeSettingsVersion(t *testing.T) {
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	encryptionKey := security.PseudorandomString(32)
+
+	previous, err := app.Settings().Clone()
+	if err != nil {
+		t.Fatal(err)
+	}
+	previous.Meta.AppName = "version_test"
+
+	version := &models.SettingsVersion{
+		AdminId: "test_admin",
+		Changes: types.JsonMap{"meta.appName": settings.Change{Old: "version_test", New: "new"}},
+	}
+	if err := app.Dao().SaveSettingsVersion(version, previous, encryptionKey); err != nil {
+		t.Fatal(err)
+	}
+
+	// existing versions can't be modified
+	if err := app.Dao().SaveSettingsVersion(version, previous); err == nil {
+		t.Fatal("Expected the existing version save to fail")
+	}
+
+	found, err := app.Dao().FindSettingsVersionById(version.Id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if found.AdminId != "test_admin" {
+		t.Fatalf("Expected admin id %q, got %q", "test_admin", found.AdminId)
+	}
+
+	if _, err := app.Dao().FindSettingsVersionById("missing"); err == nil {
+		t.Fatal("Expected error for missing version")
+	}
+
+	// try to decode without encryption key (should fail)
+	if s, err := app.Dao().DecodeSettingsVersion(found); err == nil {
+		t.Fatalf("Expected DecodeSettingsVersion to fail without an encryption key, got \n%v", s)
+	}
+
+	decoded, err := app.Dao().DecodeSettingsVersion(found, encryptionKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if decoded.Meta.AppName != "version_test" {
+		t.Fatalf("Expected decoded app name %q, got %q", "version_test", decoded.Meta.AppName)
+	}
+
+	// version without a value
+	if s, err := app.Dao().DecodeSettingsVersion(&models.SettingsVersion{}); err == nil {
+		t.Fatalf("Expected DecodeSettingsVersion to fail for empty value, got \n%v", s)
+	}
+}
@@ -2,9 +2,12 @@ package daos
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/pocketbase/dbx"
 	"github.com/unkod/space/models"
+	"github.com/unkod/space/models/schema"
+	"github.com/unkod/space/tools/list"
 )
 
 // HasTable checks if a table (or view) with the provided name exists (case insensitive).
@@ -97,6 +100,51 @@ func (dao *Dao) DeleteTable(tableName string) error {
 	return err
 }
 
+// VerifyCollectionsTables checks whether the table (or view) of each
+// stored collection exists and has a column for each of its base
+// and non-computed schema fields (eg. after reverting migrations).
+//
+// It is a no-op if the collections table doesn't exist.
+func (dao *Dao) VerifyCollectionsTables() error {
+	if !dao.HasTable((&models.Collection{}).TableName()) {
+		return nil // nothing to verify
+	}
+
+	collections := []*models.Collection{}
+	if err := dao.CollectionQuery().All(&collections); err != nil {
+		return err
+	}
+
+	for _, collection := range collections {
+		if !dao.HasTable(collection.Name) {
+			return fmt.Errorf("missing collection %q table", collection.Name)
+		}
+
+		columns, err := dao.TableColumns(collection.Name)
+		if err != nil {
+			return err
+		}
+
+		expected := []string{schema.FieldNameId}
+		if !collection.IsView() {
+			expected = append(expected, schema.FieldNameCreated, schema.FieldNameUpdated)
+		}
+		for _, field := range collection.Schema.Fields() {
+			if field.Type != schema.FieldTypeComputed {
+				expected = append(expected, field.Name)
+			}
+		}
+
+		for _, name := range expected {
+			if !list.ExistInSlice(name, columns) {
+				return fmt.Errorf("missing collection %q column %q", collection.Name, name)
+			}
+		}
+	}
+
+	return nil
+}
+
 // Vacuum executes VACUUM on the current dao.DB() instance in order to
 // reclaim unused db disk space.
 func (dao *Dao) Vacuum() error {
@@ -104,3 +152,57 @@ func (dao *Dao) Vacuum() error {
 
 	return err
 }
+
+// Analyze executes ANALYZE on the current dao.DB() instance in order to
+// refresh the tables and indexes statistics used by the query planner.
+func (dao *Dao) Analyze() error {
+	_, err := dao.DB().NewQuery("ANALYZE").Execute()
+
+	return err
+}
+
+// list with the supported WAL checkpoint modes
+const (
+	WalCheckpointPassive  = "PASSIVE"
+	WalCheckpointFull     = "FULL"
+	WalCheckpointRestart  = "RESTART"
+	WalCheckpointTruncate = "TRUNCATE"
+)
+
+// WalCheckpointResult defines the result of a single WAL checkpoint.
+type WalCheckpointResult struct {
+	// Busy indicates whether the checkpoint was blocked from
+	// completing (eg. by another active connection).
+	Busy bool `db:"busy" json:"busy"`
+
+	// Log is the number of modified pages written to the WAL file.
+	Log int `db:"log" json:"log"`
+
+	// Checkpointed is the number of pages in the WAL
+	// file moved back into the database file.
+	Checkpointed int `db:"checkpointed" json:"checkpointed"`
+}
+
+// WalCheckpoint executes a WAL checkpoint with the specified mode
+// (PASSIVE, FULL, RESTART or TRUNCATE) on the current dao.DB() instance
+// in order to transfer the WAL file changes into the database file.
+//
+// The TRUNCATE mode additionally truncates the WAL file to zero bytes.
+func (dao *Dao) WalCheckpoint(mode string) (*WalCheckpointResult, error) {
+	mode = strings.ToUpper(mode)
+
+	switch mode {
+	case WalCheckpointPassive, WalCheckpointFull, WalCheckpointRestart, WalCheckpointTruncate:
+	default:
+		return nil, fmt.Errorf("unsupported WAL checkpoint mode %q", mode)
+	}
+
+	result := &WalCheckpointResult{}
+
+	err := dao.DB().NewQuery(fmt.Sprintf("PRAGMA wal_checkpoint(%s)", mode)).One(result)
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
@@ -75,7 +75,7 @@ func TestTableInfo(t *testing.T) {
 		{"missing", "null"},
 		{
 			"_admins",
-			`[{"PK":1,"Index":0,"Name":"id","Type":"TEXT","NotNull":false,"DefaultValue":null},{"PK":0,"Index":1,"Name":"avatar","Type":"INTEGER","NotNull":true,"DefaultValue":0},{"PK":0,"Index":2,"Name":"email","Type":"TEXT","NotNull":true,"DefaultValue":null},{"PK":0,"Index":3,"Name":"tokenKey","Type":"TEXT","NotNull":true,"DefaultValue":null},{"PK":0,"Index":4,"Name":"passwordHash","Type":"TEXT","NotNull":true,"DefaultValue":null},{"PK":0,"Index":5,"Name":"lastResetSentAt","Type":"TEXT","NotNull":true,"DefaultValue":""},{"PK":0,"Index":6,"Name":"created","Type":"TEXT","NotNull":true,"DefaultValue":""},{"PK":0,"Index":7,"Name":"updated","Type":"TEXT","NotNull":true,"DefaultValue":""}]`,
+			`[{"PK":1,"Index":0,"Name":"id","Type":"TEXT","NotNull":false,"DefaultValue":null},{"PK":0,"Index":1,"Name":"avatar","Type":"INTEGER","NotNull":true,"DefaultValue":0},{"PK":0,"Index":2,"Name":"email","Type":"TEXT","NotNull":true,"DefaultValue":null},{"PK":0,"Index":3,"Name":"tokenKey","Type":"TEXT","NotNull":true,"DefaultValue":null},{"PK":0,"Index":4,"Name":"passwordHash","Type":"TEXT","NotNull":true,"DefaultValue":null},{"PK":0,"Index":5,"Name":"lastResetSentAt","Type":"TEXT","NotNull":true,"DefaultValue":""},{"PK":0,"Index":6,"Name":"created","Type":"TEXT","NotNull":true,"DefaultValue":""},{"PK":0,"Index":7,"Name":"updated","Type":"TEXT","NotNull":true,"DefaultValue":""},{"PK":0,"Index":8,"Name":"mfaSecret","Type":"TEXT","NotNull":true,"DefaultValue":""},{"PK":0,"Index":9,"Name":"mfaEnabled","Type":"BOOLEAN","NotNull":true,"DefaultValue":0},{"PK":0,"Index":10,"Name":"mfaBackupCodes","Type":"JSON","NotNull":true,"DefaultValue":"[]"},{"PK":0,"Index":11,"Name":"mfaLastCounter","Type":"INTEGER","NotNull":true,"DefaultValue":0}]`,
 		},
 	}
 
@@ -115,6 +115,39 @@ func TestDeleteTable(t *testing.T) {
 	}
 }
 
+func TestVerifyCollectionsTables(t *testing.T) {
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	if err := app.Dao().VerifyCollectionsTables(); err != nil {
+		t.Fatalf("Expected the test collections tables to be valid, got %v", err)
+	}
+
+	// missing column
+	if _, err := app.Dao().DB().NewQuery("DROP INDEX idx_demo2_active; ALTER TABLE demo2 DROP COLUMN active").Execute(); err != nil {
+		t.Fatal(err)
+	}
+	if err := app.Dao().VerifyCollectionsTables(); err == nil {
+		t.Fatal("Expected error for the missing demo2 column")
+	}
+
+	// missing table
+	if err := app.Dao().DeleteTable("demo2"); err != nil {
+		t.Fatal(err)
+	}
+	if err := app.Dao().VerifyCollectionsTables(); err == nil {
+		t.Fatal("Expected error for the missing demo2 table")
+	}
+
+	// missing collections table
+	if err := app.Dao().DeleteTable("_collections"); err != nil {
+		t.Fatal(err)
+	}
+	if err := app.Dao().VerifyCollectionsTables(); err != nil {
+		t.Fatalf("Expected nil for missing collections table, got %v", err)
+	}
+}
+
 func TestVacuum(t *testing.T) {
 	app, _ := tests.NewTestApp()
 	defer app.Cleanup()
@@ -140,6 +173,79 @@ func TestVacuum(t *testing.T) {
 	}
 }
 
+func TestAnalyze(t *testing.T) {
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	calledQueries := []string{}
+	app.DB().QueryLogFunc = func(ctx context.Context, t time.Duration, sql string, rows *sql.Rows, err error) {
+		calledQueries = append(calledQueries, sql)
+	}
+	app.DB().ExecLogFunc = func(ctx context.Context, t time.Duration, sql string, result sql.Result, err error) {
+		calledQueries = append(calledQueries, sql)
+	}
+
+	if err := app.Dao().Analyze(); err != nil {
+		t.Fatal(err)
+	}
+
+	if total := len(calledQueries); total != 1 {
+		t.Fatalf("Expected 1 query, got %d", total)
+	}
+
+	if calledQueries[0] != "ANALYZE" {
+		t.Fatalf("Expected ANALYZE query, got %s", calledQueries[0])
+	}
+}
+
+func TestWalCheckpoint(t *testing.T) {
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	scenarios := []struct {
+		mode        string
+		expectQuery string
+		expectError bool
+	}{
+		{"", "", true},
+		{"invalid", "", true},
+		{"passive", "PRAGMA wal_checkpoint(PASSIVE)", false},
+		{"FULL", "PRAGMA wal_checkpoint(FULL)", false},
+		{"RESTART", "PRAGMA wal_checkpoint(RESTART)", false},
+		{"TRUNCATE", "PRAGMA wal_checkpoint(TRUNCATE)", false},
+	}
+
+	for _, s := range scenarios {
+		calledQueries := []string{}
+		app.DB().QueryLogFunc = func(ctx context.Context, t time.Duration, sql string, rows *sql.Rows, err error) {
+			calledQueries = append(calledQueries, sql)
+		}
+
+		result, err := app.Dao().WalCheckpoint(s.mode)
+
+		hasErr := err != nil
+		if hasErr != s.expectError {
+			t.Errorf("[%s] Expected hasErr %v, got %v (%v)", s.mode, s.expectError, hasErr, err)
+			continue
+		}
+
+		if hasErr {
+			if len(calledQueries) != 0 {
+				t.Errorf("[%s] Expected no queries, got %v", s.mode, calledQueries)
+			}
+			continue
+		}
+
+		if len(calledQueries) != 1 || calledQueries[0] != s.expectQuery {
+			t.Errorf("[%s] Expected query %q, got %v", s.mode, s.expectQuery, calledQueries)
+		}
+
+		if result == nil || result.Busy {
+			t.Errorf("[%s] Expected non-busy checkpoint result, got %v", s.mode, result)
+		}
+	}
+}
+
 func TestTableIndexes(t *testing.T) {
 	app, _ := tests.NewTestApp()
 	defer app.Cleanup()
@@ -135,6 +135,7 @@ func TestSaveView(t *testing.T) {
 				"id", "created", "updated",
 				"passwordHash", "tokenKey", "email",
 				"lastResetSentAt", "avatar", "c",
+				"mfaSecret", "mfaEnabled", "mfaBackupCodes", "mfaLastCounter",
 			},
 		},
 		{
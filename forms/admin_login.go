@@ -6,9 +6,11 @@ import (
 
 	validation "github.com/go-ozzo/ozzo-validation/v4"
 	"github.com/go-ozzo/ozzo-validation/v4/is"
+	"github.com/pocketbase/dbx"
 	"github.com/unkod/space/core"
 	"github.com/unkod/space/daos"
 	"github.com/unkod/space/models"
+	"github.com/unkod/space/tools/security"
 )
 
 // AdminLogin is an admin email/pass login form.
@@ -69,6 +71,8 @@ func (form *AdminLogin) Submit(interceptors ...InterceptorFunc[*models.Admin]) (
 			return errors.New("Invalid login credentials.")
 		}
 
+		form.rehashPassword(admin)
+
 		return nil
 	}, interceptors...)
 
@@ -78,3 +82,33 @@ func (form *AdminLogin) Submit(interceptors ...InterceptorFunc[*models.Admin]) (
 
 	return admin, nil
 }
+
+// rehashPassword rehashes the admin password with the current
+// password hashing settings if its hash was generated with a different
+// algorithm or weaker parameters.
+//
+// The hash is updated directly in the db (without triggering the model hooks
+// and without changing the admin tokenKey so that the existing tokens remain valid).
+func (form *AdminLogin) rehashPassword(admin *models.Admin) {
+	opts := form.app.Settings().PasswordHashing.Options()
+
+	if !security.PasswordNeedsRehash(admin.PasswordHash, opts) {
+		return
+	}
+
+	hash, err := security.HashPassword(form.Password, opts)
+	if err == nil {
+		_, err = form.dao.DB().Update(
+			admin.TableName(),
+			dbx.Params{"passwordHash": hash},
+			dbx.HashExp{"id": admin.Id},
+		).Execute()
+	}
+
+	if err != nil {
+		form.app.Logger().Debug("Failed to rehash the admin password", "id", admin.Id, "error", err)
+		return
+	}
+
+	admin.PasswordHash = hash
+}
@@ -2,6 +2,7 @@ package forms_test
 
 import (
 	"errors"
+	"strings"
 	"testing"
 
 	"github.com/unkod/space/forms"
@@ -94,3 +95,41 @@ func TestAdminLoginInterceptors(t *testing.T) {
 		t.Fatalf("Expected Admin model with email %s, got %v", form.Identity, interceptorAdmin)
 	}
 }
+
+func TestAdminLoginRehash(t *testing.T) {
+	testApp, _ := tests.NewTestApp()
+	defer testApp.Cleanup()
+
+	original, err := testApp.Dao().FindAdminByEmail("test@example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// same algorithm but higher cost (the test admin hashes use cost 13)
+	testApp.Settings().PasswordHashing.BcryptCost = 14
+
+	form := forms.NewAdminLogin(testApp)
+	form.Identity = "test@example.com"
+	form.Password = "1234567890"
+
+	if _, err := form.Submit(); err != nil {
+		t.Fatal(err)
+	}
+
+	refreshed, err := testApp.Dao().FindAdminByEmail("test@example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !strings.HasPrefix(refreshed.PasswordHash, "$2a$14$") {
+		t.Fatalf("Expected the stored password to be rehashed, got %q", refreshed.PasswordHash)
+	}
+
+	if refreshed.TokenKey != original.TokenKey {
+		t.Fatalf("Expected the tokenKey to remain unchanged, got %q", refreshed.TokenKey)
+	}
+
+	if !refreshed.ValidatePassword("1234567890") {
+		t.Fatal("Expected the rehashed password to be valid")
+	}
+}
@@ -0,0 +1,94 @@
+package forms
+
+import (
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/unkod/space/core"
+	"github.com/unkod/space/daos"
+	"github.com/unkod/space/models"
+)
+
+// AdminMFAConfirm is an admin TOTP MFA enrollment confirmation form.
+type AdminMFAConfirm struct {
+	app   core.App
+	dao   *daos.Dao
+	admin *models.Admin
+
+	// counter is the time step counter of the validated code
+	counter int
+
+	Code string `form:"code" json:"code"`
+}
+
+// NewAdminMFAConfirm creates a new [AdminMFAConfirm] form initialized with
+// the provided [core.App] instance and the admin to confirm.
+//
+// If you want to submit the form as part of a transaction,
+// you can change the default Dao via [SetDao()].
+func NewAdminMFAConfirm(app core.App, admin *models.Admin) *AdminMFAConfirm {
+	return &AdminMFAConfirm{
+		app:   app,
+		dao:   app.Dao(),
+		admin: admin,
+	}
+}
+
+// SetDao replaces the default form Dao instance with the provided one.
+func (form *AdminMFAConfirm) SetDao(dao *daos.Dao) {
+	form.dao = dao
+}
+
+// Validate makes the form validatable by implementing [validation.Validatable] interface.
+func (form *AdminMFAConfirm) Validate() error {
+	return validation.ValidateStruct(form,
+		validation.Field(&form.Code, validation.Required, validation.By(form.checkCode)),
+	)
+}
+
+func (form *AdminMFAConfirm) checkCode(value any) error {
+	v, _ := value.(string)
+	if v == "" {
+		return nil // nothing to check
+	}
+
+	if form.admin.MFAEnabled {
+		return validation.NewError("validation_mfa_already_enabled", "MFA is already enabled.")
+	}
+
+	key := mfaEncryptionKey(form.app, form.app.Settings().AdminMFAToken.Secret)
+
+	counter, ok := validateMFACode(form.admin.MFASecret, key, v, form.admin.MFALastCounter)
+	if !ok {
+		return validation.NewError("validation_invalid_mfa_code", "Invalid or expired MFA code.")
+	}
+
+	form.counter = counter
+
+	return nil
+}
+
+// Submit validates and submits the form, enabling the admin MFA.
+// On success returns the generated plain backup codes.
+//
+// You can optionally provide a list of InterceptorFunc to
+// further modify the form behavior before persisting it.
+func (form *AdminMFAConfirm) Submit(interceptors ...InterceptorFunc[*models.Admin]) ([]string, error) {
+	if err := form.Validate(); err != nil {
+		return nil, err
+	}
+
+	backupCodes, hashedBackupCodes := newMFABackupCodes()
+
+	form.admin.MFAEnabled = true
+	form.admin.MFABackupCodes = hashedBackupCodes
+	form.admin.MFALastCounter = form.counter
+
+	interceptorsErr := runInterceptors(form.admin, func(m *models.Admin) error {
+		return form.dao.SaveAdmin(m)
+	}, interceptors...)
+
+	if interceptorsErr != nil {
+		return nil, interceptorsErr
+	}
+
+	return backupCodes, nil
+}
@@ -0,0 +1,75 @@
+package forms_test
+
+import (
+	"testing"
+
+	"github.com/unkod/space/forms"
+	"github.com/unkod/space/tests"
+)
+
+func TestAdminMFAConfirmValidateAndSubmit(t *testing.T) {
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	admin, err := app.Dao().FindAdminByEmail("test@example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// missing enrollment
+	form := forms.NewAdminMFAConfirm(app, admin)
+	form.Code = "123456"
+	if _, err := form.Submit(); err == nil {
+		t.Fatal("Expected error for missing enrollment")
+	}
+
+	enrollment, err := forms.NewAdminMFAEnroll(app, admin).Submit()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	scenarios := []struct {
+		code        string
+		expectError bool
+	}{
+		{"", true},
+		{"invalid", true},
+		{"000000", true},
+		{tests.MFACode(t, enrollment.Secret, 0), false},
+		// already enabled
+		{tests.MFACode(t, enrollment.Secret, 1), true},
+	}
+
+	for i, s := range scenarios {
+		form := forms.NewAdminMFAConfirm(app, admin)
+		form.Code = s.code
+
+		backupCodes, err := form.Submit()
+
+		hasErr := err != nil
+		if hasErr != s.expectError {
+			t.Fatalf("[%d] Expected hasErr %v, got %v (%v)", i, s.expectError, hasErr, err)
+		}
+
+		if hasErr {
+			continue
+		}
+
+		if len(backupCodes) != 10 {
+			t.Fatalf("[%d] Expected 10 backup codes, got %v", i, backupCodes)
+		}
+
+		stored, err := app.Dao().FindAdminById(admin.Id)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if !stored.MFAEnabled {
+			t.Fatalf("[%d] Expected MFA to be enabled", i)
+		}
+
+		if len(stored.MFABackupCodes) != len(backupCodes) || stored.MFABackupCodes[0] == backupCodes[0] {
+			t.Fatalf("[%d] Expected %d hashed backup codes, got %v", i, len(backupCodes), stored.MFABackupCodes)
+		}
+	}
+}
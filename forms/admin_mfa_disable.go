@@ -0,0 +1,86 @@
+package forms
+
+import (
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/unkod/space/core"
+	"github.com/unkod/space/daos"
+	"github.com/unkod/space/models"
+	"github.com/unkod/space/tools/types"
+)
+
+// AdminMFADisable is an admin MFA disable form.
+type AdminMFADisable struct {
+	app   core.App
+	dao   *daos.Dao
+	admin *models.Admin
+
+	// Code is a TOTP or backup code.
+	Code string `form:"code" json:"code"`
+}
+
+// NewAdminMFADisable creates a new [AdminMFADisable] form initialized with
+// the provided [core.App] instance and the admin to update.
+//
+// If you want to submit the form as part of a transaction,
+// you can change the default Dao via [SetDao()].
+func NewAdminMFADisable(app core.App, admin *models.Admin) *AdminMFADisable {
+	return &AdminMFADisable{
+		app:   app,
+		dao:   app.Dao(),
+		admin: admin,
+	}
+}
+
+// SetDao replaces the default form Dao instance with the provided one.
+func (form *AdminMFADisable) SetDao(dao *daos.Dao) {
+	form.dao = dao
+}
+
+// Validate makes the form validatable by implementing [validation.Validatable] interface.
+func (form *AdminMFADisable) Validate() error {
+	return validation.ValidateStruct(form,
+		validation.Field(&form.Code, validation.Required, validation.By(form.checkCode)),
+	)
+}
+
+func (form *AdminMFADisable) checkCode(value any) error {
+	v, _ := value.(string)
+	if v == "" {
+		return nil // nothing to check
+	}
+
+	if !form.admin.MFAEnabled {
+		return validation.NewError("validation_mfa_not_enabled", "MFA is not enabled.")
+	}
+
+	key := mfaEncryptionKey(form.app, form.app.Settings().AdminMFAToken.Secret)
+
+	if _, ok := validateMFACode(form.admin.MFASecret, key, v, form.admin.MFALastCounter); ok {
+		return nil
+	}
+
+	if ok, _ := useMFABackupCode(form.admin.MFABackupCodes, v); ok {
+		return nil
+	}
+
+	return validation.NewError("validation_invalid_mfa_code", "Invalid or expired MFA code.")
+}
+
+// Submit validates and submits the form, disabling the admin MFA.
+//
+// You can optionally provide a list of InterceptorFunc to
+// further modify the form behavior before persisting it.
+func (form *AdminMFADisable) Submit(interceptors ...InterceptorFunc[*models.Admin]) error {
+	if err := form.Validate(); err != nil {
+		return err
+	}
+
+	form.admin.MFAEnabled = false
+	form.admin.MFASecret = ""
+	form.admin.MFABackupCodes = types.JsonArray[string]{}
+	form.admin.MFALastCounter = 0
+
+	return runInterceptors(form.admin, func(m *models.Admin) error {
+		return form.dao.SaveAdmin(m)
+	}, interceptors...)
+}
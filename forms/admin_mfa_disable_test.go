@@ -0,0 +1,57 @@
+package forms_test
+
+import (
+	"testing"
+
+	"github.com/unkod/space/forms"
+	"github.com/unkod/space/tests"
+)
+
+func TestAdminMFADisableValidateAndSubmit(t *testing.T) {
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	admin, err := app.Dao().FindAdminByEmail("test@example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// not enabled
+	form := forms.NewAdminMFADisable(app, admin)
+	form.Code = "123456"
+	if err := form.Submit(); err == nil {
+		t.Fatal("Expected error for not enabled MFA")
+	}
+
+	_, backupCodes := tests.EnableAdminMFA(t, app, admin)
+
+	scenarios := []struct {
+		code        string
+		expectError bool
+	}{
+		{"", true},
+		{"invalid", true},
+		{backupCodes[0], false},
+	}
+
+	for i, s := range scenarios {
+		form := forms.NewAdminMFADisable(app, admin)
+		form.Code = s.code
+
+		err := form.Submit()
+
+		hasErr := err != nil
+		if hasErr != s.expectError {
+			t.Fatalf("[%d] Expected hasErr %v, got %v (%v)", i, s.expectError, hasErr, err)
+		}
+	}
+
+	stored, err := app.Dao().FindAdminById(admin.Id)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if stored.MFAEnabled || stored.MFASecret != "" || len(stored.MFABackupCodes) != 0 {
+		t.Fatalf("Expected MFA to be disabled, got %v %q %v", stored.MFAEnabled, stored.MFASecret, stored.MFABackupCodes)
+	}
+}
@@ -0,0 +1,73 @@
+package forms
+
+import (
+	"errors"
+
+	"github.com/unkod/space/core"
+	"github.com/unkod/space/daos"
+	"github.com/unkod/space/models"
+	"github.com/unkod/space/tools/security"
+)
+
+// AdminMFAEnroll is an admin TOTP MFA enrollment form.
+//
+// The enrollment becomes active only after its confirmation with [AdminMFAConfirm].
+type AdminMFAEnroll struct {
+	app   core.App
+	dao   *daos.Dao
+	admin *models.Admin
+}
+
+// NewAdminMFAEnroll creates a new [AdminMFAEnroll] form initialized with
+// the provided [core.App] instance and the admin to enroll.
+//
+// If you want to submit the form as part of a transaction,
+// you can change the default Dao via [SetDao()].
+func NewAdminMFAEnroll(app core.App, admin *models.Admin) *AdminMFAEnroll {
+	return &AdminMFAEnroll{
+		app:   app,
+		dao:   app.Dao(),
+		admin: admin,
+	}
+}
+
+// SetDao replaces the default form Dao instance with the provided one.
+func (form *AdminMFAEnroll) SetDao(dao *daos.Dao) {
+	form.dao = dao
+}
+
+// Submit generates and stores a new pending admin TOTP secret.
+//
+// You can optionally provide a list of InterceptorFunc to
+// further modify the form behavior before persisting it.
+func (form *AdminMFAEnroll) Submit(interceptors ...InterceptorFunc[*models.Admin]) (*MFAEnrollment, error) {
+	if form.admin.MFAEnabled {
+		return nil, errors.New("MFA is already enabled.")
+	}
+
+	secret, err := security.NewTOTPSecret()
+	if err != nil {
+		return nil, err
+	}
+
+	encryptedSecret, err := security.Encrypt(
+		[]byte(secret),
+		mfaEncryptionKey(form.app, form.app.Settings().AdminMFAToken.Secret),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	form.admin.MFASecret = encryptedSecret
+	form.admin.MFALastCounter = 0
+
+	interceptorsErr := runInterceptors(form.admin, func(m *models.Admin) error {
+		return form.dao.SaveAdmin(m)
+	}, interceptors...)
+
+	if interceptorsErr != nil {
+		return nil, interceptorsErr
+	}
+
+	return newMFAEnrollment(form.app, form.admin.Email, secret), nil
+}
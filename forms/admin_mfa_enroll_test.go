@@ -0,0 +1,64 @@
+package forms_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/unkod/space/forms"
+	"github.com/unkod/space/models"
+	"github.com/unkod/space/tests"
+)
+
+func TestAdminMFAEnrollSubmit(t *testing.T) {
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	admin, err := app.Dao().FindAdminByEmail("test@example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// interceptor error
+	interceptorErr := errors.New("test_error")
+	_, err = forms.NewAdminMFAEnroll(app, admin).Submit(func(next forms.InterceptorNextFunc[*models.Admin]) forms.InterceptorNextFunc[*models.Admin] {
+		return func(m *models.Admin) error {
+			return interceptorErr
+		}
+	})
+	if err != interceptorErr {
+		t.Fatalf("Expected interceptor error, got %v", err)
+	}
+
+	enrollment, err := forms.NewAdminMFAEnroll(app, admin).Submit()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(enrollment.Secret) != 32 {
+		t.Fatalf("Expected 32 chars secret, got %q", enrollment.Secret)
+	}
+
+	if !strings.HasPrefix(enrollment.Url, "otpauth://totp/") || !strings.Contains(enrollment.Url, "secret="+enrollment.Secret) {
+		t.Fatalf("Invalid enrollment url %q", enrollment.Url)
+	}
+
+	stored, err := app.Dao().FindAdminById(admin.Id)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if stored.MFASecret == "" || strings.Contains(stored.MFASecret, enrollment.Secret) {
+		t.Fatalf("Expected the stored secret to be encrypted, got %q", stored.MFASecret)
+	}
+
+	if stored.MFAEnabled {
+		t.Fatal("Expected MFA to not be enabled before the enrollment confirmation")
+	}
+
+	// already enabled
+	admin.MFAEnabled = true
+	if _, err := forms.NewAdminMFAEnroll(app, admin).Submit(); err == nil {
+		t.Fatal("Expected error for already enabled MFA")
+	}
+}
@@ -0,0 +1,110 @@
+package forms
+
+import (
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/unkod/space/core"
+	"github.com/unkod/space/daos"
+	"github.com/unkod/space/models"
+)
+
+// AdminMFALogin is an admin MFA login form
+// (aka. the second step of the password authentication).
+type AdminMFALogin struct {
+	app core.App
+	dao *daos.Dao
+
+	// MfaId is the MFA challenge token returned by the first auth step.
+	MfaId string `form:"mfaId" json:"mfaId"`
+
+	// Code is a TOTP or backup code.
+	Code string `form:"code" json:"code"`
+}
+
+// NewAdminMFALogin creates a new [AdminMFALogin] form initialized with
+// the provided [core.App] instance.
+//
+// If you want to submit the form as part of a transaction,
+// you can change the default Dao via [SetDao()].
+func NewAdminMFALogin(app core.App) *AdminMFALogin {
+	return &AdminMFALogin{
+		app: app,
+		dao: app.Dao(),
+	}
+}
+
+// SetDao replaces the default form Dao instance with the provided one.
+func (form *AdminMFALogin) SetDao(dao *daos.Dao) {
+	form.dao = dao
+}
+
+// Validate makes the form validatable by implementing [validation.Validatable] interface.
+func (form *AdminMFALogin) Validate() error {
+	return validation.ValidateStruct(form,
+		validation.Field(&form.MfaId, validation.Required, validation.By(form.checkMfaId)),
+		validation.Field(&form.Code, validation.Required, validation.Length(1, 100)),
+	)
+}
+
+func (form *AdminMFALogin) checkMfaId(value any) error {
+	v, _ := value.(string)
+	if v == "" {
+		return nil // nothing to check
+	}
+
+	admin, err := form.dao.FindAdminByToken(v, form.app.Settings().AdminMFAToken.Secret)
+	if err != nil || admin == nil || !admin.MFAEnabled || isMFAChallengeInvalidated(form.app, v) {
+		return validation.NewError("validation_invalid_mfa_id", "Invalid or expired MFA challenge.")
+	}
+
+	return nil
+}
+
+// Submit validates and submits the form.
+// On success returns the authorized admin model.
+//
+// If a backup code is used, it is removed from the admin backup codes.
+// Otherwise the TOTP code counter is stored to prevent reusing the same code.
+//
+// After too many failed code attempts the MFA challenge is invalidated.
+//
+// You can optionally provide a list of InterceptorFunc to
+// further modify the form behavior before persisting it.
+func (form *AdminMFALogin) Submit(interceptors ...InterceptorFunc[*models.Admin]) (*models.Admin, error) {
+	if err := form.Validate(); err != nil {
+		return nil, err
+	}
+
+	admin, err := form.dao.FindAdminByToken(form.MfaId, form.app.Settings().AdminMFAToken.Secret)
+	if err != nil {
+		return nil, err
+	}
+
+	key := mfaEncryptionKey(form.app, form.app.Settings().AdminMFAToken.Secret)
+
+	if counter, ok := validateMFACode(admin.MFASecret, key, form.Code, admin.MFALastCounter); ok {
+		admin.MFALastCounter = counter
+	} else {
+		ok, remaining := useMFABackupCode(admin.MFABackupCodes, form.Code)
+		if !ok {
+			failMFAChallenge(form.app, form.MfaId, form.app.Settings().AdminMFAToken.Duration)
+			return nil, invalidMFACodeError()
+		}
+
+		admin.MFABackupCodes = remaining
+	}
+
+	if err := form.dao.SaveAdmin(admin); err != nil {
+		return nil, err
+	}
+
+	interceptorsErr := runInterceptors(admin, func(m *models.Admin) error {
+		admin = m
+		return nil
+	}, interceptors...)
+
+	if interceptorsErr != nil {
+		return nil, interceptorsErr
+	}
+
+	return admin, nil
+}
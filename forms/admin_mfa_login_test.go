@@ -0,0 +1,141 @@
+package forms_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/unkod/space/forms"
+	"github.com/unkod/space/models"
+	"github.com/unkod/space/tests"
+	"github.com/unkod/space/tokens"
+)
+
+func TestAdminMFALoginValidateAndSubmit(t *testing.T) {
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	admin, err := app.Dao().FindAdminByEmail("test@example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// MFA challenge for admin without enabled MFA
+	admin2, err := app.Dao().FindAdminByEmail("test2@example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	disabledMfaId, err := tokens.NewAdminMFAToken(app, admin2)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	secret, backupCodes := tests.EnableAdminMFA(t, app, admin)
+
+	mfaId, err := tokens.NewAdminMFAToken(app, admin)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	authToken, err := tokens.NewAdminAuthToken(app, admin)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	scenarios := []struct {
+		name        string
+		mfaId       string
+		code        string
+		expectError bool
+	}{
+		{"empty data", "", "", true},
+		{"invalid mfaId", "invalid", tests.MFACode(t, secret, 0), true},
+		{"auth token as mfaId", authToken, tests.MFACode(t, secret, 0), true},
+		{"disabled MFA", disabledMfaId, tests.MFACode(t, secret, 0), true},
+		{"invalid code", mfaId, "000000", true},
+		{"valid TOTP code", mfaId, tests.MFACode(t, secret, 0), false},
+		{"already used TOTP code", mfaId, tests.MFACode(t, secret, 0), true},
+		{"valid backup code", mfaId, backupCodes[0], false},
+		{"already used backup code", mfaId, backupCodes[0], true},
+	}
+
+	for _, s := range scenarios {
+		form := forms.NewAdminMFALogin(app)
+		form.MfaId = s.mfaId
+		form.Code = s.code
+
+		interceptorCalls := 0
+
+		result, err := form.Submit(func(next forms.InterceptorNextFunc[*models.Admin]) forms.InterceptorNextFunc[*models.Admin] {
+			return func(m *models.Admin) error {
+				interceptorCalls++
+				return next(m)
+			}
+		})
+
+		hasErr := err != nil
+		if hasErr != s.expectError {
+			t.Fatalf("[%s] Expected hasErr %v, got %v (%v)", s.name, s.expectError, hasErr, err)
+		}
+
+		expectedCalls := 1
+		if s.expectError {
+			expectedCalls = 0
+		}
+		if interceptorCalls != expectedCalls {
+			t.Fatalf("[%s] Expected interceptor calls %d, got %d", s.name, expectedCalls, interceptorCalls)
+		}
+
+		if !hasErr && result.Id != admin.Id {
+			t.Fatalf("[%s] Expected admin %q, got %q", s.name, admin.Id, result.Id)
+		}
+	}
+
+	// interceptor error
+	form := forms.NewAdminMFALogin(app)
+	form.MfaId = mfaId
+	form.Code = tests.MFACode(t, secret, 1)
+
+	interceptorErr := errors.New("test_error")
+	_, err = form.Submit(func(next forms.InterceptorNextFunc[*models.Admin]) forms.InterceptorNextFunc[*models.Admin] {
+		return func(m *models.Admin) error {
+			return interceptorErr
+		}
+	})
+	if err != interceptorErr {
+		t.Fatalf("Expected interceptor error, got %v", err)
+	}
+}
+
+func TestAdminMFALoginChallengeInvalidation(t *testing.T) {
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	admin, err := app.Dao().FindAdminByEmail("test@example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	secret, _ := tests.EnableAdminMFA(t, app, admin)
+
+	mfaId, err := tokens.NewAdminMFAToken(app, admin)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 0; i < 5; i++ {
+		form := forms.NewAdminMFALogin(app)
+		form.MfaId = mfaId
+		form.Code = "000000"
+		if _, err := form.Submit(); !forms.IsInvalidMFACodeError(err) {
+			t.Fatalf("[%d] Expected invalid MFA code error, got %v", i, err)
+		}
+	}
+
+	// the challenge should be invalidated even for a valid code
+	form := forms.NewAdminMFALogin(app)
+	form.MfaId = mfaId
+	form.Code = tests.MFACode(t, secret, 0)
+	if _, err := form.Submit(); err == nil || forms.IsInvalidMFACodeError(err) {
+		t.Fatalf("Expected invalid MFA challenge error, got %v", err)
+	}
+}
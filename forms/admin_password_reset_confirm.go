@@ -79,7 +79,7 @@ func (form *AdminPasswordResetConfirm) Submit(interceptors ...InterceptorFunc[*m
 		return nil, err
 	}
 
-	if err := admin.SetPassword(form.Password); err != nil {
+	if err := admin.SetPasswordWithOptions(form.Password, form.app.Settings().PasswordHashing.Options()); err != nil {
 		return nil, err
 	}
 
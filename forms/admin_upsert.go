@@ -114,7 +114,7 @@ func (form *AdminUpsert) Submit(interceptors ...InterceptorFunc[*models.Admin])
 	form.admin.Email = form.Email
 
 	if form.Password != "" {
-		form.admin.SetPassword(form.Password)
+		form.admin.SetPasswordWithOptions(form.Password, form.app.Settings().PasswordHashing.Options())
 	}
 
 	return runInterceptors(form.admin, func(admin *models.Admin) error {
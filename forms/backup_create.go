@@ -16,6 +16,11 @@ type BackupCreate struct {
 	ctx context.Context
 
 	Name string `form:"name" json:"name"`
+
+	// Mode specifies the backup mode ("full" or "incremental").
+	//
+	// Fallbacks to "full" if not set.
+	Mode string `form:"mode" json:"mode"`
 }
 
 // NewBackupCreate creates new BackupCreate request form.
@@ -40,6 +45,10 @@ func (form *BackupCreate) Validate() error {
 			validation.Match(backupNameRegex),
 			validation.By(form.checkUniqueName),
 		),
+		validation.Field(
+			&form.Mode,
+			validation.In(core.BackupModeFull, core.BackupModeIncremental),
+		),
 	)
 }
 
@@ -74,6 +83,10 @@ func (form *BackupCreate) Submit(interceptors ...InterceptorFunc[string]) error
 	}
 
 	return runInterceptors(form.Name, func(name string) error {
+		if form.Mode == core.BackupModeIncremental {
+			return form.app.CreateIncrementalBackup(form.ctx, name)
+		}
+
 		return form.app.CreateBackup(form.ctx, name)
 	}, interceptors...)
 }
@@ -5,6 +5,7 @@ import (
 	"testing"
 
 	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/unkod/space/core"
 	"github.com/unkod/space/forms"
 	"github.com/unkod/space/tests"
 )
@@ -13,26 +14,43 @@ func TestBackupCreateValidateAndSubmit(t *testing.T) {
 	scenarios := []struct {
 		name           string
 		backupName     string
+		mode           string
 		expectedErrors []string
 	}{
 		{
 			"invalid length",
 			strings.Repeat("a", 97) + ".zip",
+			"",
 			[]string{"name"},
 		},
 		{
 			"valid length + invalid format",
 			strings.Repeat("a", 96),
+			"",
 			[]string{"name"},
 		},
 		{
 			"valid length + valid format",
 			strings.Repeat("a", 96) + ".zip",
+			"",
 			[]string{},
 		},
 		{
 			"auto generated name",
 			"",
+			"",
+			[]string{},
+		},
+		{
+			"invalid mode",
+			"",
+			"invalid",
+			[]string{"mode"},
+		},
+		{
+			"full mode",
+			"",
+			core.BackupModeFull,
 			[]string{},
 		},
 	}
@@ -50,6 +68,7 @@ func TestBackupCreateValidateAndSubmit(t *testing.T) {
 
 			form := forms.NewBackupCreate(app)
 			form.Name = s.backupName
+			form.Mode = s.mode
 
 			result := form.Submit()
 
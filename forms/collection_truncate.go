@@ -0,0 +1,126 @@
+package forms
+
+import (
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/unkod/space/core"
+	"github.com/unkod/space/daos"
+	"github.com/unkod/space/models"
+)
+
+// CollectionTruncate is a form that deletes all records of a single collection.
+//
+// NB! When SkipHooks is set, NO model hooks are triggered for the
+// deleted records (see [daos.Dao.TruncateCollection] for more details).
+type CollectionTruncate struct {
+	app        core.App
+	dao        *daos.Dao
+	collection *models.Collection
+
+	// Confirm must match the collection name to ensure that the
+	// truncate is intentional.
+	Confirm string `form:"confirm" json:"confirm"`
+
+	// SkipHooks deletes the records with a single query without
+	// triggering the per record model hooks.
+	SkipHooks bool `form:"skipHooks" json:"skipHooks"`
+}
+
+// NewCollectionTruncate creates a new [CollectionTruncate] form
+// for the provided [models.Collection] instance.
+//
+// If you want to submit the form as part of a transaction,
+// you can change the default Dao via [SetDao()].
+func NewCollectionTruncate(app core.App, collection *models.Collection) *CollectionTruncate {
+	return &CollectionTruncate{
+		app:        app,
+		dao:        app.Dao(),
+		collection: collection,
+	}
+}
+
+// SetDao replaces the default form Dao instance with the provided one.
+func (form *CollectionTruncate) SetDao(dao *daos.Dao) {
+	form.dao = dao
+}
+
+// Validate makes the form validatable by implementing [validation.Validatable] interface.
+func (form *CollectionTruncate) Validate() error {
+	return validation.ValidateStruct(form,
+		validation.Field(
+			&form.Confirm,
+			validation.Required,
+			validation.By(form.checkConfirm),
+		),
+		validation.Field(
+			&form.SkipHooks,
+			validation.By(form.checkCollectionType),
+		),
+	)
+}
+
+func (form *CollectionTruncate) checkConfirm(value any) error {
+	v, _ := value.(string)
+
+	if v != form.collection.Name {
+		return validation.NewError("validation_truncate_confirm_mismatch", "The confirm value must match the collection name.")
+	}
+
+	return nil
+}
+
+func (form *CollectionTruncate) checkCollectionType(value any) error {
+	if form.collection.IsView() {
+		return validation.NewError("validation_truncate_view_collection", "View collections cannot be truncated.")
+	}
+
+	return nil
+}
+
+// Submit validates the form and deletes all records of the form collection.
+//
+// On success the interceptors receive (and the form returns) the number of deleted records.
+//
+// You can optionally provide a list of InterceptorFunc to further
+// modify the form behavior before truncating the collection.
+func (form *CollectionTruncate) Submit(interceptors ...InterceptorFunc[*models.Collection]) (int64, error) {
+	if err := form.Validate(); err != nil {
+		return 0, err
+	}
+
+	var total int64
+
+	submitErr := runInterceptors(form.collection, func(collection *models.Collection) error {
+		var err error
+
+		total, err = form.dao.TruncateCollection(collection, form.SkipHooks)
+		if err != nil {
+			return err
+		}
+
+		// with hooks the files are removed by the regular record delete handlers
+		if form.SkipHooks && total > 0 {
+			form.deleteFiles(collection.BaseFilesPath())
+		}
+
+		return nil
+	}, interceptors...)
+
+	return total, submitErr
+}
+
+// deleteFiles removes "optimistically" the storage files with the specified prefix.
+//
+// The records are already deleted at this point so the
+// failures are non critical and are only logged for debug.
+func (form *CollectionTruncate) deleteFiles(prefix string) {
+	fs, err := form.app.NewFilesystem()
+	if err != nil {
+		form.app.Logger().Debug("Failed to init the truncated collection filesystem", "error", err)
+		return
+	}
+	defer fs.Close()
+
+	if failed := fs.DeletePrefix(prefix + "/"); len(failed) > 0 {
+		form.app.Logger().Debug("Failed to delete the truncated collection files", "prefix", prefix, "errors", failed)
+	}
+}
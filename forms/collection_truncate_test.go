@@ -0,0 +1,131 @@
+package forms_test
+
+import (
+	"errors"
+	"testing"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/unkod/space/forms"
+	"github.com/unkod/space/models"
+	"github.com/unkod/space/tests"
+)
+
+func TestCollectionTruncateValidateAndSubmit(t *testing.T) {
+	scenarios := []struct {
+		name           string
+		collection     string
+		confirm        string
+		skipHooks      bool
+		expectedErrors []string
+		expectedTotal  int64
+	}{
+		{"missing confirm", "demo2", "", false, []string{"confirm"}, 0},
+		{"confirm with collection id", "demo2", "sz5l5z67tg7gku0", false, []string{"confirm"}, 0},
+		{"view collection", "view1", "view1", false, []string{"skipHooks"}, 0},
+		{"valid confirm (with hooks)", "demo2", "demo2", false, []string{}, 3},
+		{"valid confirm (skip hooks)", "users", "users", true, []string{}, 3},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			app, _ := tests.NewTestApp()
+			defer app.Cleanup()
+
+			collection, err := app.Dao().FindCollectionByNameOrId(s.collection)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			form := forms.NewCollectionTruncate(app, collection)
+			form.Confirm = s.confirm
+			form.SkipHooks = s.skipHooks
+
+			interceptorCalls := 0
+			interceptor := func(next forms.InterceptorNextFunc[*models.Collection]) forms.InterceptorNextFunc[*models.Collection] {
+				return func(c *models.Collection) error {
+					interceptorCalls++
+					return next(c)
+				}
+			}
+
+			total, result := form.Submit(interceptor)
+
+			// parse errors
+			errs, ok := result.(validation.Errors)
+			if !ok && result != nil {
+				t.Fatalf("Failed to parse errors %v", result)
+			}
+
+			// check errors
+			if len(errs) > len(s.expectedErrors) {
+				t.Fatalf("Expected error keys %v, got %v", s.expectedErrors, errs)
+			}
+			for _, k := range s.expectedErrors {
+				if _, ok := errs[k]; !ok {
+					t.Fatalf("Missing expected error key %q in %v", k, errs)
+				}
+			}
+
+			if total != s.expectedTotal {
+				t.Fatalf("Expected total %d, got %d", s.expectedTotal, total)
+			}
+
+			expectInterceptorCalls := 1
+			if len(s.expectedErrors) > 0 {
+				expectInterceptorCalls = 0
+			}
+			if interceptorCalls != expectInterceptorCalls {
+				t.Fatalf("Expected interceptor to be called %d, got %d", expectInterceptorCalls, interceptorCalls)
+			}
+
+			if result != nil || !s.skipHooks {
+				return
+			}
+
+			// the storage files should be deleted synchronously in skip hooks mode
+			fs, err := app.NewFilesystem()
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer fs.Close()
+
+			files, err := fs.List(collection.BaseFilesPath() + "/")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(files) > 0 {
+				t.Fatalf("Expected the collection files to be deleted, found %d", len(files))
+			}
+		})
+	}
+}
+
+func TestCollectionTruncateInterceptors(t *testing.T) {
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	collection, err := app.Dao().FindCollectionByNameOrId("demo2")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	form := forms.NewCollectionTruncate(app, collection)
+	form.Confirm = collection.Name
+
+	testErr := errors.New("test_error")
+
+	interceptor := func(next forms.InterceptorNextFunc[*models.Collection]) forms.InterceptorNextFunc[*models.Collection] {
+		return func(c *models.Collection) error {
+			return testErr
+		}
+	}
+
+	if _, err := form.Submit(interceptor); err != testErr {
+		t.Fatalf("Expected test_error, got %v", err)
+	}
+
+	records, err := app.Dao().FindRecordsByExpr(collection.Id)
+	if err != nil || len(records) != 3 {
+		t.Fatalf("Expected the collection records to remain untouched, got %d (%v)", len(records), err)
+	}
+}
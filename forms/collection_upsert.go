@@ -3,6 +3,7 @@ package forms
 import (
 	"encoding/json"
 	"fmt"
+	"os"
 	"regexp"
 	"strconv"
 
@@ -14,6 +15,7 @@ import (
 	"github.com/unkod/space/models/schema"
 	"github.com/unkod/space/resolvers"
 	"github.com/unkod/space/tools/dbutils"
+	"github.com/unkod/space/tools/expr"
 	"github.com/unkod/space/tools/list"
 	"github.com/unkod/space/tools/search"
 	"github.com/unkod/space/tools/types"
@@ -21,6 +23,9 @@ import (
 
 var collectionNameRegex = regexp.MustCompile(`^[a-zA-Z0-9][a-zA-Z0-9_]*$`)
 
+// indexColumnIdentifierRegex matches a single (optionally quoted) index column identifier.
+var indexColumnIdentifierRegex = regexp.MustCompile("^[\\[`\"']*(\\w+)[\\]`\"']*$")
+
 // CollectionUpsert is a [models.Collection] upsert (create/update) form.
 type CollectionUpsert struct {
 	app        core.App
@@ -139,7 +144,11 @@ func (form *CollectionUpsert) Validate() error {
 			validation.By(form.ensureNoSystemFieldsChange),
 			validation.By(form.ensureNoFieldsTypeChange),
 			validation.By(form.checkRelationFields),
+			validation.By(form.checkEncryptedFields),
+			validation.By(form.checkComputedFields),
+			validation.By(form.checkFieldRules),
 			validation.When(isAuth, validation.By(form.ensureNoAuthFieldName)),
+			validation.When(form.hasSoftDelete(), validation.By(form.ensureNoSoftDeleteFieldName)),
 		),
 		validation.Field(&form.ListRule, validation.By(form.checkRule)),
 		validation.Field(&form.ViewRule, validation.By(form.checkRule)),
@@ -291,6 +300,100 @@ func (form *CollectionUpsert) checkRelationFields(value any) error {
 	return nil
 }
 
+// checkEncryptedFields checks that the records encryption key is
+// configured when there are encrypted fields or their encryption changes.
+func (form *CollectionUpsert) checkEncryptedFields(value any) error {
+	if len(form.dao.EncryptionKeys) > 0 {
+		return nil // nothing to check
+	}
+
+	v, _ := value.(schema.Schema)
+
+	for i, field := range v.Fields() {
+		oldField := form.collection.Schema.GetFieldById(field.Id)
+
+		if daos.IsEncryptedField(field) || daos.IsEncryptedField(oldField) {
+			return validation.Errors{fmt.Sprint(i): validation.Errors{
+				"options": validation.Errors{
+					"encrypted": validation.NewError(
+						"validation_missing_encryption_key",
+						"The app encryption key must be set in order to use encrypted fields.",
+					),
+				},
+			}}
+		}
+	}
+
+	return nil
+}
+
+// checkFieldRules checks that the schema fields read and write
+// rules are valid filter expressions for the collection.
+func (form *CollectionUpsert) checkFieldRules(value any) error {
+	v, _ := value.(schema.Schema)
+
+	for i, field := range v.Fields() {
+		errs := validation.Errors{}
+
+		if err := form.checkRule(&field.ReadRule); err != nil {
+			errs["readRule"] = err
+		}
+
+		if err := form.checkRule(&field.WriteRule); err != nil {
+			errs["writeRule"] = err
+		}
+
+		if len(errs) > 0 {
+			return validation.Errors{fmt.Sprint(i): errs}
+		}
+	}
+
+	return nil
+}
+
+// checkComputedFields checks that the computed fields expressions
+// reference only base model fields and other non-computed schema fields.
+func (form *CollectionUpsert) checkComputedFields(value any) error {
+	v, _ := value.(schema.Schema)
+
+	for i, field := range v.Fields() {
+		if field.Type != schema.FieldTypeComputed {
+			continue
+		}
+
+		options, _ := field.Options.(*schema.ComputedOptions)
+		if options == nil {
+			continue
+		}
+
+		e, err := expr.Parse(options.Expression)
+		if err != nil {
+			continue // already reported by the field options validator
+		}
+
+		for _, name := range e.Identifiers() {
+			if list.ExistInSlice(name, schema.BaseModelFieldNames()) {
+				continue
+			}
+
+			if f := v.GetFieldByName(name); f != nil && f.Type != schema.FieldTypeComputed {
+				continue
+			}
+
+			return validation.Errors{fmt.Sprint(i): validation.Errors{
+				"options": validation.Errors{
+					"expression": validation.NewError(
+						"validation_invalid_computed_identifier",
+						fmt.Sprintf("The expression references %q which is not a base field or a non-computed schema field.", name),
+					),
+				},
+			}}
+		}
+	}
+
+	return nil
+}
+
 func (form *CollectionUpsert) ensureNoAuthFieldName(value any) error {
 	v, _ := value.(schema.Schema)
 
@@ -321,6 +424,134 @@ func (form *CollectionUpsert) ensureNoAuthFieldName(value any) error {
 	return nil
 }
 
+func (form *CollectionUpsert) ensureNoSoftDeleteFieldName(value any) error {
+	v, _ := value.(schema.Schema)
+
+	errs := validation.Errors{}
+	for i, field := range v.Fields() {
+		if field.Name == schema.FieldNameDeleted {
+			errs[fmt.Sprint(i)] = validation.Errors{
+				"name": validation.NewError(
+					"validation_reserved_soft_delete_field_name",
+					"The field name is reserved for collections with soft-delete enabled.",
+				),
+			}
+		}
+	}
+
+	if len(errs) > 0 {
+		return errs
+	}
+
+	return nil
+}
+
+// hasSoftDelete checks whether the form options enable soft-delete.
+func (form *CollectionUpsert) hasSoftDelete() bool {
+	return (&models.Collection{Type: form.Type, Options: form.Options}).HasSoftDelete()
+}
+
+// ensureNoSoftDeletedRecordsOnDisable checks that there are no
+// soft-deleted records left when disabling the collection soft-delete
+// (otherwise they will become "alive" again).
+func (form *CollectionUpsert) ensureNoSoftDeletedRecordsOnDisable() error {
+	if form.collection.IsNew() || !form.collection.HasSoftDelete() || form.hasSoftDelete() {
+		return nil
+	}
+
+	var exists bool
+
+	query := form.dao.RecordQuery(form.collection).
+		Select("(1)").
+		Limit(1)
+
+	if err := daos.SoftDeletedFilter(form.collection, daos.SoftDeletedOnly)(query); err != nil {
+		return err
+	}
+
+	if err := query.Row(&exists); err == nil && exists {
+		return validation.Errors{"softDelete": validation.NewError(
+			"validation_soft_deleted_records_exist",
+			"Restore or permanently delete the soft-deleted records before disabling soft-delete.",
+		)}
+	}
+
+	return nil
+}
+
+// checkSearchFields checks that all search fields are existing text schema fields.
+func (form *CollectionUpsert) checkSearchFields(names []string) error {
+	allowedTypes := []string{
+		schema.FieldTypeText,
+		schema.FieldTypeEditor,
+		schema.FieldTypeEmail,
+		schema.FieldTypeUrl,
+	}
+
+	for i, name := range names {
+		field := form.Schema.GetFieldByName(name)
+		if field == nil || !list.ExistInSlice(field.Type, allowedTypes) {
+			return validation.Errors{"searchFields": validation.Errors{
+				strconv.Itoa(i): validation.NewError(
+					"validation_invalid_search_field",
+					fmt.Sprintf("%q is not a text, editor, email or url schema field.", name),
+				),
+			}}
+		}
+
+		if daos.IsEncryptedField(field) {
+			return validation.Errors{"searchFields": validation.Errors{
+				strconv.Itoa(i): validation.NewError(
+					"validation_encrypted_search_field",
+					fmt.Sprintf("%q is encrypted and can't be indexed.", name),
+				),
+			}}
+		}
+	}
+
+	return nil
+}
+
+// checkTokenClaims checks that all custom token claims are mapped
+// to existing non-secret auth record fields.
+func (form *CollectionUpsert) checkTokenClaims(claims map[string]string) error {
+	systemFields := []string{
+		schema.FieldNameCreated,
+		schema.FieldNameUpdated,
+		schema.FieldNameUsername,
+		schema.FieldNameEmail,
+		schema.FieldNameEmailVisibility,
+		schema.FieldNameVerified,
+	}
+
+	for name, fieldName := range claims {
+		if list.ExistInSlice(fieldName, systemFields) {
+			continue
+		}
+
+		field := form.Schema.GetFieldByName(fieldName)
+		if field == nil {
+			return validation.Errors{"tokenClaims": validation.Errors{
+				name: validation.NewError(
+					"validation_invalid_token_claim_field",
+					fmt.Sprintf("%q is not an existing auth record field.", fieldName),
+				),
+			}}
+		}
+
+		if daos.IsEncryptedField(field) {
+			return validation.Errors{"tokenClaims": validation.Errors{
+				name: validation.NewError(
+					"validation_encrypted_token_claim_field",
+					fmt.Sprintf("%q is encrypted and can't be used as token claim.", fieldName),
+				),
+			}}
+		}
+	}
+
+	return nil
+}
+
 func (form *CollectionUpsert) checkMinSchemaFields(value any) error {
 	v, _ := value.(schema.Schema)
 
@@ -398,6 +629,10 @@ func (form *CollectionUpsert) checkIndexes(value any) error {
 			}
 		}
 
+		if err := form.checkIndexColumns(parsed); err != nil {
+			return validation.Errors{strconv.Itoa(i): err}
+		}
+
 		// note: we don't check the index table because it is always
 		// overwritten by the daos.SyncRecordTableSchema to allow
 		// easier partial modifications (eg. changing only the collection name).
@@ -414,10 +649,79 @@ func (form *CollectionUpsert) checkIndexes(value any) error {
 	return nil
 }
 
+// checkIndexColumns checks whether the identifier columns of the
+// provided parsed index exist in the collection table.
+//
+// Expression columns (eg. "lower(name)") are not checked and any
+// SQL error related to them is reported on index creation.
+func (form *CollectionUpsert) checkIndexColumns(index dbutils.Index) error {
+	allowed := schema.BaseModelFieldNames()
+
+	if form.Type == models.CollectionTypeAuth {
+		allowed = append(allowed, schema.AuthFieldNames()...)
+	}
+
+	if softDelete, _ := form.Options["softDelete"].(bool); softDelete {
+		allowed = append(allowed, schema.FieldNameDeleted)
+	}
+
+	for _, column := range index.Columns {
+		match := indexColumnIdentifierRegex.FindStringSubmatch(column.Name)
+		if len(match) < 2 {
+			continue // expression
+		}
+
+		name := match[1]
+
+		if f := form.Schema.GetFieldByName(name); f != nil && f.Type == schema.FieldTypeComputed {
+			return validation.NewError(
+				"validation_computed_index_column",
+				fmt.Sprintf("The index %s references the computed field %q which doesn't have a db column.", index.IndexName, name),
+			)
+		}
+
+		if list.ExistInSlice(name, allowed) || form.Schema.GetFieldByName(name) != nil {
+			continue
+		}
+
+		if form.collection.Schema.GetFieldByName(name) != nil {
+			return validation.NewError(
+				"validation_index_removed_field",
+				fmt.Sprintf("The index %s references the removed or renamed field %q and must be updated or deleted first.", index.IndexName, name),
+			)
+		}
+
+		return validation.NewError(
+			"validation_invalid_index_column",
+			fmt.Sprintf("The index %s references the missing collection field %q.", index.IndexName, name),
+		)
+	}
+
+	return nil
+}
+
 func (form *CollectionUpsert) checkOptions(value any) error {
 	v, _ := value.(types.JsonMap)
 
 	switch form.Type {
+	case models.CollectionTypeBase:
+		options := models.CollectionBaseOptions{}
+		if err := decodeOptions(v, &options); err != nil {
+			return err
+		}
+
+		// check the generic validations
+		if err := options.Validate(); err != nil {
+			return err
+		}
+
+		// additional form specific validations
+		if err := form.ensureNoSoftDeletedRecordsOnDisable(); err != nil {
+			return err
+		}
+		if err := form.checkSearchFields(options.SearchFields); err != nil {
+			return err
+		}
 	case models.CollectionTypeAuth:
 		options := models.CollectionAuthOptions{}
 		if err := decodeOptions(v, &options); err != nil {
@@ -433,6 +737,15 @@ func (form *CollectionUpsert) checkOptions(value any) error {
 		if err := form.checkRule(options.ManageRule); err != nil {
 			return validation.Errors{"manageRule": err}
 		}
+		if err := form.ensureNoSoftDeletedRecordsOnDisable(); err != nil {
+			return err
+		}
+		if err := form.checkSearchFields(options.SearchFields); err != nil {
+			return err
+		}
+		if err := form.checkTokenClaims(options.TokenClaims); err != nil {
+			return err
+		}
 	case models.CollectionTypeView:
 		options := models.CollectionViewOptions{}
 		if err := decodeOptions(v, &options); err != nil {
@@ -471,6 +784,31 @@ func decodeOptions(options types.JsonMap, result any) error {
 	return nil
 }
 
+// encryptMailerPassword encrypts the plain auth collection mailer
+// password (if any) with the app encryption env key.
+func (form *CollectionUpsert) encryptMailerPassword() error {
+	encryptionKey := os.Getenv(form.app.EncryptionEnv())
+	if encryptionKey == "" {
+		return nil
+	}
+
+	options := form.collection.AuthOptions()
+	if options.Mailer == nil || options.Mailer.Password == "" {
+		return nil
+	}
+
+	// already encrypted
+	if options.Mailer.DecryptedPassword(encryptionKey) != options.Mailer.Password {
+		return nil
+	}
+
+	if err := options.Mailer.EncryptPassword(encryptionKey); err != nil {
+		return err
+	}
+
+	return form.collection.SetOptions(options)
+}
+
 // Submit validates the form and upserts the form's Collection model.
 //
 // On success the related record table schema will be auto updated.
@@ -519,6 +857,12 @@ func (form *CollectionUpsert) Submit(interceptors ...InterceptorFunc[*models.Col
 	form.collection.DeleteRule = form.DeleteRule
 	form.collection.SetOptions(form.Options)
 
+	if form.collection.IsAuth() {
+		if err := form.encryptMailerPassword(); err != nil {
+			return err
+		}
+	}
+
 	return runInterceptors(form.collection, func(collection *models.Collection) error {
 		return form.dao.SaveCollection(collection)
 	}, interceptors...)
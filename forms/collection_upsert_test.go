@@ -7,11 +7,13 @@ import (
 
 	validation "github.com/go-ozzo/ozzo-validation/v4"
 	"github.com/spf13/cast"
+	"github.com/unkod/space/daos"
 	"github.com/unkod/space/forms"
 	"github.com/unkod/space/models"
 	"github.com/unkod/space/models/schema"
 	"github.com/unkod/space/tests"
 	"github.com/unkod/space/tools/dbutils"
+	"github.com/unkod/space/tools/list"
 	"github.com/unkod/space/tools/security"
 )
 
@@ -184,6 +186,22 @@ func TestCollectionUpsertValidateAndSubmit(t *testing.T) {
 			}`,
 			[]string{"options"},
 		},
+		{
+			"create failure - index with missing field column",
+			"",
+			`{
+				"name": "test_new",
+				"type": "auth",
+				"schema": [
+					{"name":"test","type":"text"}
+				],
+				"indexes": [
+					"create index idx_test_new1 on test_new (id, [[email]], lower(test))",
+					"create index idx_test_new2 on test_new (test, missing)"
+				]
+			}`,
+			[]string{"indexes"},
+		},
 		{
 			"create failure - check view options validators",
 			"",
@@ -347,7 +365,7 @@ func TestCollectionUpsertValidateAndSubmit(t *testing.T) {
 					{"id":"_2hlxbmp","name":"test_renamed","type":"text"}
 				]
 			}`,
-			[]string{"listRule", "viewRule", "createRule", "updateRule", "deleteRule"},
+			[]string{"listRule", "viewRule", "createRule", "updateRule", "deleteRule", "indexes"},
 		},
 		// (cleared filter references)
 		{
@@ -521,6 +539,111 @@ func TestCollectionUpsertValidateAndSubmit(t *testing.T) {
 			}`,
 			[]string{},
 		},
+		{
+			"soft-delete create failure - reserved field name",
+			"",
+			`{
+				"name": "soft_delete_test",
+				"options": {"softDelete": true},
+				"schema": [
+					{"name":"deleted","type":"text"}
+				]
+			}`,
+			[]string{"schema"},
+		},
+		{
+			"soft-delete create success",
+			"",
+			`{
+				"name": "soft_delete_test",
+				"options": {"softDelete": true},
+				"schema": [
+					{"name":"title","type":"text"}
+				]
+			}`,
+			[]string{},
+		},
+		{
+			"deleted field name without soft-delete",
+			"",
+			`{
+				"name": "soft_delete_test2",
+				"schema": [
+					{"name":"deleted","type":"text"}
+				]
+			}`,
+			[]string{},
+		},
+		{
+			"search fields create failure - missing and non-text fields",
+			"",
+			`{
+				"name": "search_test",
+				"options": {"searchFields": ["title", "missing", "active"]},
+				"schema": [
+					{"name":"title","type":"text"},
+					{"name":"active","type":"bool"}
+				]
+			}`,
+			[]string{"options"},
+		},
+		{
+			"search fields create failure - duplicated fields",
+			"",
+			`{
+				"name": "search_test",
+				"options": {"searchFields": ["title", "title"]},
+				"schema": [
+					{"name":"title","type":"text"}
+				]
+			}`,
+			[]string{"options"},
+		},
+		{
+			"search fields create success",
+			"",
+			`{
+				"name": "search_test",
+				"options": {"searchFields": ["title", "content"]},
+				"schema": [
+					{"name":"title","type":"text"},
+					{"name":"content","type":"editor"}
+				]
+			}`,
+			[]string{},
+		},
+		{
+			"auth search fields update success",
+			"users",
+			`{
+				"options": {"searchFields": ["name"]}
+			}`,
+			[]string{},
+		},
+		{
+			"auth token claims update failure - missing field",
+			"users",
+			`{
+				"options": {"tokenClaims": {"role": "missing"}}
+			}`,
+			[]string{"options"},
+		},
+		{
+			"auth token claims update failure - reserved claim",
+			"users",
+			`{
+				"options": {"tokenClaims": {"collectionId": "name"}}
+			}`,
+			[]string{"options"},
+		},
+		{
+			"auth token claims update success",
+			"users",
+			`{
+				"options": {"tokenClaims": {"name": "name", "isVerified": "verified"}}
+			}`,
+			[]string{},
+		},
 	}
 
 	for _, s := range scenarios {
@@ -653,6 +776,133 @@ func TestCollectionUpsertValidateAndSubmit(t *testing.T) {
 	}
 }
 
+func TestCollectionUpsertDisableSoftDelete(t *testing.T) {
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	collection, err := app.Dao().FindCollectionByNameOrId("demo2")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	collection.Options["softDelete"] = true
+	if err := app.Dao().SaveCollection(collection); err != nil {
+		t.Fatal(err)
+	}
+
+	record, err := app.Dao().FindRecordById(collection.Id, "0yxhwia2amd8gec")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := app.Dao().SoftDeleteRecord(record); err != nil {
+		t.Fatal(err)
+	}
+
+	disable := func() error {
+		form := forms.NewCollectionUpsert(app, collection)
+		form.Options = map[string]any{"softDelete": false}
+		return form.Submit()
+	}
+
+	// with soft-deleted records
+	errs, ok := disable().(validation.Errors)
+	if !ok {
+		t.Fatalf("Expected validation.Errors, got %v", errs)
+	}
+	if err, ok := errs["options"].(validation.Errors); !ok || err["softDelete"] == nil {
+		t.Fatalf("Expected options.softDelete error, got %v", errs)
+	}
+
+	// without soft-deleted records
+	if err := app.Dao().RestoreRecord(record); err != nil {
+		t.Fatal(err)
+	}
+	if err := disable(); err != nil {
+		t.Fatalf("Expected nil, got %v", err)
+	}
+
+	collection, _ = app.Dao().FindCollectionByNameOrId("demo2")
+	if collection.HasSoftDelete() {
+		t.Fatal("Expected soft-delete to be disabled")
+	}
+}
+
+func TestCollectionUpsertMailerOptions(t *testing.T) {
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	const encryptionKey = "abcdabcdabcdabcdabcdabcdabcdabcd"
+	t.Setenv(app.EncryptionEnv(), encryptionKey)
+
+	collection, err := app.Dao().FindCollectionByNameOrId("users")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	submit := func(mailer map[string]any) error {
+		form := forms.NewCollectionUpsert(app, collection)
+		options := collection.AuthOptions()
+		form.Options = map[string]any{}
+		raw, _ := json.Marshal(options)
+		json.Unmarshal(raw, &form.Options)
+		form.Options["mailer"] = mailer
+		return form.Submit()
+	}
+
+	// invalid SMTP config
+	errs, ok := submit(map[string]any{"enabled": true}).(validation.Errors)
+	if !ok {
+		t.Fatalf("Expected validation.Errors, got %v", errs)
+	}
+	if err, ok := errs["options"].(validation.Errors); !ok || err["mailer"] == nil {
+		t.Fatalf("Expected options.mailer error, got %v", errs)
+	}
+
+	// valid SMTP config
+	err = submit(map[string]any{
+		"enabled":       true,
+		"host":          "smtp.example.com",
+		"port":          587,
+		"password":      "123456",
+		"senderAddress": "no-reply@example.com",
+	})
+	if err != nil {
+		t.Fatalf("Expected nil, got %v", err)
+	}
+
+	collection, _ = app.Dao().FindCollectionByNameOrId("users")
+	mailerOptions := collection.AuthOptions().Mailer
+	if mailerOptions == nil {
+		t.Fatal("Expected the mailer options to be saved")
+	}
+	encryptedPassword := mailerOptions.Password
+	if encryptedPassword == "123456" {
+		t.Fatal("Expected the password to be stored encrypted")
+	}
+	if v := mailerOptions.DecryptedPassword(encryptionKey); v != "123456" {
+		t.Fatalf("Expected the decrypted password %q, got %q", "123456", v)
+	}
+
+	// resubmit the already encrypted password
+	if err := submit(map[string]any{
+		"enabled":  true,
+		"host":     "smtp2.example.com",
+		"port":     587,
+		"password": encryptedPassword,
+	}); err != nil {
+		t.Fatalf("Expected nil, got %v", err)
+	}
+
+	collection, _ = app.Dao().FindCollectionByNameOrId("users")
+	mailerOptions = collection.AuthOptions().Mailer
+	if mailerOptions.Password != encryptedPassword {
+		t.Fatalf("Expected the encrypted password to remain unchanged, got %q", mailerOptions.Password)
+	}
+	if mailerOptions.Host != "smtp2.example.com" {
+		t.Fatalf("Expected host %q, got %q", "smtp2.example.com", mailerOptions.Host)
+	}
+}
+
 func TestCollectionUpsertSubmitInterceptors(t *testing.T) {
 	app, _ := tests.NewTestApp()
 	defer app.Cleanup()
@@ -806,3 +1056,292 @@ func TestCollectionUpsertWithCustomId(t *testing.T) {
 		}
 	}
 }
+
+func TestCollectionUpsertEncryptedFields(t *testing.T) {
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	submit := func(data string) validation.Errors {
+		form := forms.NewCollectionUpsert(app, &models.Collection{})
+		if err := json.Unmarshal([]byte(data), form); err != nil {
+			t.Fatal(err)
+		}
+
+		err := form.Submit()
+		if err == nil {
+			return nil
+		}
+
+		errs, ok := err.(validation.Errors)
+		if !ok {
+			t.Fatalf("Expected validation.Errors, got %v", err)
+		}
+
+		return errs
+	}
+
+	data := `{
+		"name": "encrypted_test",
+		"schema": [
+			{"name":"title","type":"text"},
+			{"name":"secret","type":"text","options":{"encrypted":true}}
+		]
+	}`
+
+	// missing encryption key
+	errs := submit(data)
+	if errs["schema"] == nil {
+		t.Fatalf("Expected schema error, got %v", errs)
+	}
+
+	app.Dao().EncryptionKeys = []string{"abcdabcdabcdabcdabcdabcdabcdabcd"}
+
+	// encrypted search field
+	errs = submit(`{
+		"name": "encrypted_test",
+		"options": {"searchFields": ["title", "secret"]},
+		"schema": [
+			{"name":"title","type":"text"},
+			{"name":"secret","type":"text","options":{"encrypted":true}}
+		]
+	}`)
+	if errs["options"] == nil {
+		t.Fatalf("Expected options error, got %v", errs)
+	}
+
+	// with encryption key
+	if errs := submit(data); errs != nil {
+		t.Fatalf("Expected nil, got %v", errs)
+	}
+
+	collection, err := app.Dao().FindCollectionByNameOrId("encrypted_test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if field := collection.Schema.GetFieldByName("secret"); !daos.IsEncryptedField(field) {
+		t.Fatalf("Expected the secret field to be encrypted, got %v", field)
+	}
+}
+
+func TestCollectionUpsertComputedFields(t *testing.T) {
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	scenarios := []struct {
+		name           string
+		jsonData       string
+		expectedErrors []string
+	}{
+		{
+			"invalid expression",
+			`{
+				"name": "computed_test",
+				"schema": [
+					{"name":"price","type":"number"},
+					{"name":"total","type":"computed","options":{"expression":"price *"}}
+				]
+			}`,
+			[]string{"schema"},
+		},
+		{
+			"missing field reference",
+			`{
+				"name": "computed_test",
+				"schema": [
+					{"name":"price","type":"number"},
+					{"name":"total","type":"computed","options":{"expression":"price * missing"}}
+				]
+			}`,
+			[]string{"schema"},
+		},
+		{
+			"computed field reference",
+			`{
+				"name": "computed_test",
+				"schema": [
+					{"name":"price","type":"number"},
+					{"name":"total","type":"computed","options":{"expression":"price * 2"}},
+					{"name":"total2","type":"computed","options":{"expression":"total + 1"}}
+				]
+			}`,
+			[]string{"schema"},
+		},
+		{
+			"auth field reference",
+			`{
+				"name": "computed_test",
+				"type": "auth",
+				"schema": [
+					{"name":"info","type":"computed","options":{"expression":"'hash: ' + passwordHash"}}
+				]
+			}`,
+			[]string{"schema"},
+		},
+		{
+			"indexed computed field",
+			`{
+				"name": "computed_test",
+				"schema": [
+					{"name":"price","type":"number"},
+					{"name":"total","type":"computed","options":{"expression":"price * 2"}}
+				],
+				"indexes": ["create index idx_computed_test on computed_test (total)"]
+			}`,
+			[]string{"indexes"},
+		},
+		{
+			"valid computed fields",
+			`{
+				"name": "computed_test",
+				"schema": [
+					{"name":"title","type":"text"},
+					{"name":"price","type":"number"},
+					{"name":"total","type":"computed","options":{"expression":"price * 2"}},
+					{"name":"label","type":"computed","options":{"expression":"id + ': ' + title"}}
+				]
+			}`,
+			[]string{},
+		},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			form := forms.NewCollectionUpsert(app, &models.Collection{})
+			if err := json.Unmarshal([]byte(s.jsonData), form); err != nil {
+				t.Fatal(err)
+			}
+
+			result := form.Submit()
+
+			errs, ok := result.(validation.Errors)
+			if !ok && result != nil {
+				t.Fatalf("Failed to parse errors %v", result)
+			}
+
+			if len(errs) > len(s.expectedErrors) {
+				t.Fatalf("Expected error keys %v, got %v", s.expectedErrors, errs)
+			}
+			for _, k := range s.expectedErrors {
+				if _, ok := errs[k]; !ok {
+					t.Fatalf("Missing expected error key %q in %v", k, errs)
+				}
+			}
+
+			if len(s.expectedErrors) > 0 {
+				return
+			}
+
+			columns, err := app.Dao().TableColumns("computed_test")
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			for _, name := range []string{"total", "label"} {
+				if list.ExistInSlice(name, columns) {
+					t.Fatalf("Didn't expect column %q in %v", name, columns)
+				}
+			}
+		})
+	}
+}
+
+func TestCollectionUpsertFieldRules(t *testing.T) {
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	scenarios := []struct {
+		name           string
+		jsonData       string
+		expectedErrors map[string][]string
+	}{
+		{
+			"invalid read rule",
+			`{
+				"name": "field_rules_test",
+				"schema": [
+					{"name":"salary","type":"number","readRule":"missing = 1"}
+				]
+			}`,
+			map[string][]string{"0": {"readRule"}},
+		},
+		{
+			"invalid write rule",
+			`{
+				"name": "field_rules_test",
+				"schema": [
+					{"name":"title","type":"text"},
+					{"name":"salary","type":"number","writeRule":"@request.auth.id ="}
+				]
+			}`,
+			map[string][]string{"1": {"writeRule"}},
+		},
+		{
+			"write rule on computed field",
+			`{
+				"name": "field_rules_test",
+				"schema": [
+					{"name":"salary","type":"number"},
+					{"name":"total","type":"computed","writeRule":"@request.auth.id != ''","options":{"expression":"salary * 12"}}
+				]
+			}`,
+			map[string][]string{"1": {"writeRule"}},
+		},
+		{
+			"valid rules",
+			`{
+				"name": "field_rules_test",
+				"schema": [
+					{"name":"title","type":"text"},
+					{
+						"name":"salary",
+						"type":"number",
+						"readRule":"@request.auth.id != '' && title != ''",
+						"writeRule":"@request.auth.verified = true"
+					}
+				]
+			}`,
+			nil,
+		},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			form := forms.NewCollectionUpsert(app, &models.Collection{})
+			if err := json.Unmarshal([]byte(s.jsonData), form); err != nil {
+				t.Fatal(err)
+			}
+
+			result := form.Submit()
+
+			if len(s.expectedErrors) == 0 {
+				if result != nil {
+					t.Fatalf("Expected nil, got %v", result)
+				}
+				return
+			}
+
+			errs, ok := result.(validation.Errors)
+			if !ok {
+				t.Fatalf("Failed to parse errors %v", result)
+			}
+
+			schemaErrs, ok := errs["schema"].(validation.Errors)
+			if !ok {
+				t.Fatalf("Expected schema errors, got %v", errs)
+			}
+
+			for index, keys := range s.expectedErrors {
+				fieldErrs, ok := schemaErrs[index].(validation.Errors)
+				if !ok {
+					t.Fatalf("Expected field %s errors, got %v", index, schemaErrs)
+				}
+
+				for _, k := range keys {
+					if _, ok := fieldErrs[k]; !ok {
+						t.Fatalf("Missing expected field %s error key %q in %v", index, k, fieldErrs)
+					}
+				}
+			}
+		})
+	}
+}
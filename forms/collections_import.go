@@ -3,7 +3,6 @@ package forms
 import (
 	"encoding/json"
 	"fmt"
-	"log"
 
 	validation "github.com/go-ozzo/ozzo-validation/v4"
 	"github.com/unkod/space/core"
@@ -78,9 +77,7 @@ func (form *CollectionsImport) Submit(interceptors ...InterceptorFunc[[]*models.
 			}
 
 			// generic/db failure
-			if form.app.IsDebug() {
-				log.Println("Internal import failure:", importErr)
-			}
+			form.app.Logger().Debug("Internal import failure", "error", importErr)
 			return validation.Errors{"collections": validation.NewError(
 				"collections_import_failure",
 				"Failed to import the collections configuration.",
@@ -120,6 +117,7 @@ func (form *CollectionsImport) afterSync(txDao *daos.Dao, mappedNew, mappedOld m
 		upsertForm.DeleteRule = collection.DeleteRule
 		upsertForm.Schema = collection.Schema
 		upsertForm.Options = collection.Options
+		upsertForm.Indexes = collection.Indexes
 
 		if err := upsertForm.Validate(); err != nil {
 			// serialize the validation error(s)
@@ -0,0 +1,200 @@
+package forms
+
+import (
+	"errors"
+	"net/mail"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/go-ozzo/ozzo-validation/v4/is"
+	"github.com/unkod/space/core"
+	"github.com/unkod/space/mails"
+	"github.com/unkod/space/models/settings"
+	"github.com/unkod/space/tokens"
+	"github.com/unkod/space/tools/mailer"
+)
+
+// testEmailTemplateAddress is the email address of the fake auth record
+// used to generate the default preview token.
+const testEmailTemplateAddress = "test@example.com"
+
+// EmailTemplatePreview is an email template preview request form.
+//
+// By default the currently stored app email template is rendered,
+// but it could be replaced with a custom (eg. not saved yet) one
+// by setting the EmailTemplate field.
+type EmailTemplatePreview struct {
+	app core.App
+
+	Template string `form:"template" json:"template"`
+
+	// EmailTemplate is an optional email template to render
+	// instead of the currently stored one.
+	EmailTemplate *settings.EmailTemplate `form:"emailTemplate" json:"emailTemplate"`
+
+	// Token is an optional token value to use for the {TOKEN} placeholder.
+	//
+	// If not set, a token for a fake test auth record is generated.
+	Token string `form:"token" json:"token"`
+}
+
+// EmailTemplatePreviewResult defines the result of an email template preview.
+type EmailTemplatePreviewResult struct {
+	Subject   string `json:"subject"`
+	Html      string `json:"html"`
+	ActionUrl string `json:"actionUrl"`
+
+	// UnknownPlaceholders lists the template placeholders
+	// that were left unresolved (eg. "{MISSING}").
+	UnknownPlaceholders []string `json:"unknownPlaceholders"`
+}
+
+// NewEmailTemplatePreview creates and initializes new EmailTemplatePreview form.
+func NewEmailTemplatePreview(app core.App) *EmailTemplatePreview {
+	return &EmailTemplatePreview{app: app}
+}
+
+// Validate makes the form validatable by implementing [validation.Validatable] interface.
+func (form *EmailTemplatePreview) Validate() error {
+	return validation.ValidateStruct(form,
+		validation.Field(
+			&form.Template,
+			validation.Required,
+			validation.In(templateVerification, templatePasswordReset, templateEmailChange),
+		),
+		validation.Field(&form.EmailTemplate),
+		validation.Field(&form.Token, validation.Length(0, 1000)),
+	)
+}
+
+// Submit validates the form and renders the email template.
+//
+// No records are created or modified during the rendering.
+func (form *EmailTemplatePreview) Submit() (*EmailTemplatePreviewResult, error) {
+	if err := form.Validate(); err != nil {
+		return nil, err
+	}
+
+	emailTemplate, err := form.emailTemplate()
+	if err != nil {
+		return nil, err
+	}
+
+	token := form.Token
+	if token == "" {
+		token, err = form.testToken()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	subject, html, err := mails.ResolveEmailTemplate(form.app, token, emailTemplate)
+	if err != nil {
+		return nil, err
+	}
+
+	_, _, actionUrl := emailTemplate.Resolve(
+		form.app.Settings().Meta.AppName,
+		form.app.Settings().Meta.AppUrl,
+		token,
+	)
+
+	return &EmailTemplatePreviewResult{
+		Subject:             subject,
+		Html:                html,
+		ActionUrl:           actionUrl,
+		UnknownPlaceholders: emailTemplate.UnknownPlaceholders(),
+	}, nil
+}
+
+func (form *EmailTemplatePreview) emailTemplate() (settings.EmailTemplate, error) {
+	if form.EmailTemplate != nil {
+		return *form.EmailTemplate, nil
+	}
+
+	meta := form.app.Settings().Meta
+
+	switch form.Template {
+	case templateVerification:
+		return meta.VerificationTemplate, nil
+	case templatePasswordReset:
+		return meta.ResetPasswordTemplate, nil
+	case templateEmailChange:
+		return meta.ConfirmEmailChangeTemplate, nil
+	}
+
+	return settings.EmailTemplate{}, errors.New("unknown email template")
+}
+
+func (form *EmailTemplatePreview) testToken() (string, error) {
+	record := newTestAuthRecord(testEmailTemplateAddress)
+
+	switch form.Template {
+	case templateVerification:
+		return tokens.NewRecordVerifyToken(form.app, record)
+	case templatePasswordReset:
+		return tokens.NewRecordResetPasswordToken(form.app, record)
+	case templateEmailChange:
+		return tokens.NewRecordChangeEmailToken(form.app, record, testEmailTemplateAddress)
+	}
+
+	return "", errors.New("unknown email template")
+}
+
+// -------------------------------------------------------------------
+
+// EmailTemplateTestSend is a form that renders an email template
+// (see [EmailTemplatePreview]) and sends it to the specified address.
+type EmailTemplateTestSend struct {
+	EmailTemplatePreview
+
+	Email string `form:"email" json:"email"`
+}
+
+// NewEmailTemplateTestSend creates and initializes new EmailTemplateTestSend form.
+func NewEmailTemplateTestSend(app core.App) *EmailTemplateTestSend {
+	return &EmailTemplateTestSend{EmailTemplatePreview: EmailTemplatePreview{app: app}}
+}
+
+// Validate makes the form validatable by implementing [validation.Validatable] interface.
+func (form *EmailTemplateTestSend) Validate() error {
+	return validation.ValidateStruct(form,
+		validation.Field(
+			&form.Template,
+			validation.Required,
+			validation.In(templateVerification, templatePasswordReset, templateEmailChange),
+		),
+		validation.Field(&form.EmailTemplate),
+		validation.Field(&form.Token, validation.Length(0, 1000)),
+		validation.Field(
+			&form.Email,
+			validation.Required,
+			validation.Length(1, 255),
+			is.EmailFormat,
+		),
+	)
+}
+
+// Submit validates the form, renders the email template and
+// sends it with the app mail client to the form.Email address.
+//
+// Note that the mailer record hooks are not triggered.
+func (form *EmailTemplateTestSend) Submit() error {
+	if err := form.Validate(); err != nil {
+		return err
+	}
+
+	result, err := form.EmailTemplatePreview.Submit()
+	if err != nil {
+		return err
+	}
+
+	return form.app.NewMailClient().Send(&mailer.Message{
+		From: mail.Address{
+			Name:    form.app.Settings().Meta.SenderName,
+			Address: form.app.Settings().Meta.SenderAddress,
+		},
+		To:      []mail.Address{{Address: form.Email}},
+		Subject: result.Subject,
+		HTML:    result.Html,
+	})
+}
@@ -0,0 +1,236 @@
+package forms_test
+
+import (
+	"strings"
+	"testing"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/unkod/space/forms"
+	"github.com/unkod/space/models/settings"
+	"github.com/unkod/space/tests"
+)
+
+func TestEmailTemplatePreviewValidateAndSubmit(t *testing.T) {
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	scenarios := []struct {
+		name                 string
+		template             string
+		emailTemplate        *settings.EmailTemplate
+		token                string
+		expectedErrors       []string
+		expectedContent      []string
+		expectedUnknown      []string
+		expectedActionSuffix string
+	}{
+		{
+			"empty form",
+			"",
+			nil,
+			"",
+			[]string{"template"},
+			nil,
+			nil,
+			"",
+		},
+		{
+			"invalid template",
+			"invalid",
+			nil,
+			"",
+			[]string{"template"},
+			nil,
+			nil,
+			"",
+		},
+		{
+			"invalid custom email template",
+			"verification",
+			&settings.EmailTemplate{Subject: "test", Body: "test", ActionUrl: "test"},
+			"",
+			[]string{"emailTemplate"},
+			nil,
+			nil,
+			"",
+		},
+		{
+			"stored verification template",
+			"verification",
+			nil,
+			"",
+			nil,
+			[]string{"Verify"},
+			[]string{},
+			"",
+		},
+		{
+			"stored password reset template with custom token",
+			"password-reset",
+			nil,
+			"test_token",
+			nil,
+			[]string{"Reset password", "test_token"},
+			[]string{},
+			"test_token",
+		},
+		{
+			"stored email change template",
+			"email-change",
+			nil,
+			"",
+			nil,
+			[]string{"Confirm new email"},
+			[]string{},
+			"",
+		},
+		{
+			"custom email template with unknown placeholders",
+			"verification",
+			&settings.EmailTemplate{
+				Subject:   "Hello {USER_NAME}",
+				Body:      "<p>{ACTION_URL}</p><p>{MISSING}</p>",
+				ActionUrl: "{APP_URL}/verify/{TOKEN}",
+			},
+			"test_token",
+			nil,
+			[]string{"{USER_NAME}", "{MISSING}", "/verify/test_token"},
+			[]string{"{USER_NAME}", "{MISSING}"},
+			"test_token",
+		},
+	}
+
+	for _, s := range scenarios {
+		form := forms.NewEmailTemplatePreview(app)
+		form.Template = s.template
+		form.EmailTemplate = s.emailTemplate
+		form.Token = s.token
+
+		result, err := form.Submit()
+
+		// parse errors
+		errs, ok := err.(validation.Errors)
+		if !ok && err != nil {
+			t.Errorf("[%s] Failed to parse errors %v", s.name, err)
+			continue
+		}
+
+		// check errors
+		if len(errs) > len(s.expectedErrors) {
+			t.Errorf("[%s] Expected error keys %v, got %v", s.name, s.expectedErrors, errs)
+			continue
+		}
+		for _, k := range s.expectedErrors {
+			if _, ok := errs[k]; !ok {
+				t.Errorf("[%s] Missing expected error key %q in %v", s.name, k, errs)
+			}
+		}
+
+		if len(s.expectedErrors) > 0 {
+			if result != nil {
+				t.Errorf("[%s] Expected nil result, got %v", s.name, result)
+			}
+			continue
+		}
+
+		content := result.Subject + result.Html
+		for _, c := range s.expectedContent {
+			if !strings.Contains(content, c) {
+				t.Errorf("[%s] Expected the rendered template to contain %q, got \n%v", s.name, c, content)
+			}
+		}
+
+		if strings.Join(result.UnknownPlaceholders, ",") != strings.Join(s.expectedUnknown, ",") {
+			t.Errorf("[%s] Expected unknown placeholders %v, got %v", s.name, s.expectedUnknown, result.UnknownPlaceholders)
+		}
+
+		if result.ActionUrl == "" || !strings.HasSuffix(result.ActionUrl, s.expectedActionSuffix) {
+			t.Errorf("[%s] Expected the action url to end with %q, got %q", s.name, s.expectedActionSuffix, result.ActionUrl)
+		}
+	}
+
+	if app.TestMailer.TotalSend != 0 {
+		t.Fatalf("Expected no sent emails, got %d", app.TestMailer.TotalSend)
+	}
+}
+
+func TestEmailTemplateTestSendValidateAndSubmit(t *testing.T) {
+	scenarios := []struct {
+		template        string
+		email           string
+		emailTemplate   *settings.EmailTemplate
+		expectedErrors  []string
+		expectedContent string
+	}{
+		{"", "", nil, []string{"template", "email"}, ""},
+		{"invalid", "test@example.com", nil, []string{"template"}, ""},
+		{"verification", "invalid", nil, []string{"email"}, ""},
+		{"verification", "test@example.com", nil, nil, "Verify"},
+		{"password-reset", "test@example.com", nil, nil, "Reset password"},
+		{
+			"email-change",
+			"test@example.com",
+			&settings.EmailTemplate{
+				Subject:   "test_subject",
+				Body:      "test_body {ACTION_URL}",
+				ActionUrl: "{APP_URL}/{TOKEN}",
+			},
+			nil,
+			"test_body",
+		},
+	}
+
+	for i, s := range scenarios {
+		func() {
+			app, _ := tests.NewTestApp()
+			defer app.Cleanup()
+
+			form := forms.NewEmailTemplateTestSend(app)
+			form.Template = s.template
+			form.Email = s.email
+			form.EmailTemplate = s.emailTemplate
+
+			result := form.Submit()
+
+			// parse errors
+			errs, ok := result.(validation.Errors)
+			if !ok && result != nil {
+				t.Errorf("(%d) Failed to parse errors %v", i, result)
+				return
+			}
+
+			// check errors
+			if len(errs) > len(s.expectedErrors) {
+				t.Errorf("(%d) Expected error keys %v, got %v", i, s.expectedErrors, errs)
+				return
+			}
+			for _, k := range s.expectedErrors {
+				if _, ok := errs[k]; !ok {
+					t.Errorf("(%d) Missing expected error key %q in %v", i, k, errs)
+					return
+				}
+			}
+
+			expectedEmails := 1
+			if len(s.expectedErrors) > 0 {
+				expectedEmails = 0
+			}
+
+			if app.TestMailer.TotalSend != expectedEmails {
+				t.Errorf("(%d) Expected %d email(s) to be sent, got %d", i, expectedEmails, app.TestMailer.TotalSend)
+			}
+
+			if len(s.expectedErrors) > 0 {
+				return
+			}
+
+			if app.TestMailer.LastMessage.To[0].Address != s.email {
+				t.Errorf("(%d) Expected the email to be sent to %s, got %s", i, s.email, app.TestMailer.LastMessage.To[0].Address)
+			}
+
+			if !strings.Contains(app.TestMailer.LastMessage.HTML, s.expectedContent) {
+				t.Errorf("(%d) Expected the email to contains %s, got \n%v", i, s.expectedContent, app.TestMailer.LastMessage.HTML)
+			}
+		}()
+	}
+}
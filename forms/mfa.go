@@ -0,0 +1,191 @@
+package forms
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/unkod/space/core"
+	"github.com/unkod/space/tools/lockout"
+	"github.com/unkod/space/tools/security"
+)
+
+// mfaBackupCodesCount is the number of the generated MFA backup codes.
+const mfaBackupCodesCount = 10
+
+// mfaMaxCodeAttempts is the max number of failed code attempts
+// after which the MFA challenge (aka. mfaId) is invalidated.
+const mfaMaxCodeAttempts = 5
+
+// mfaChallengesStoreCacheKey is the app cache key of the in-memory
+// store with the failed code attempts of the MFA challenges.
+const mfaChallengesStoreCacheKey = "@mfaChallengesStore"
+
+const mfaBackupCodeAlphabet = "abcdefghijkmnpqrstuvwxyz23456789"
+
+// MFAEnrollment defines the result of a TOTP MFA enrollment request.
+type MFAEnrollment struct {
+	// Secret is the base32 encoded TOTP secret.
+	Secret string `json:"secret"`
+
+	// Url is the "otpauth://" key uri of the secret (usually used to render a QR code).
+	Url string `json:"url"`
+}
+
+func newMFAEnrollment(app core.App, account string, secret string) *MFAEnrollment {
+	return &MFAEnrollment{
+		Secret: secret,
+		Url:    security.TOTPAuthURL(app.Settings().Meta.AppName, account, secret),
+	}
+}
+
+// mfaEncryptionKey returns the key used to encrypt the stored TOTP secrets.
+//
+// It is the app encryption env key (if set) or otherwise a key derived from
+// the provided MFA token secret, meaning that in the latter case changing
+// the token secret will invalidate the existing enrollments.
+func mfaEncryptionKey(app core.App, tokenSecret string) string {
+	if key := os.Getenv(app.EncryptionEnv()); len(key) == 32 {
+		return key
+	}
+
+	hash := sha256.Sum256([]byte(tokenSecret))
+
+	return hex.EncodeToString(hash[:])[:32]
+}
+
+// newMFABackupCodes generates a new set of plain MFA backup codes
+// together with their hashes (that are safe to store).
+func newMFABackupCodes() (plain []string, hashed []string) {
+	plain = make([]string, mfaBackupCodesCount)
+	hashed = make([]string, mfaBackupCodesCount)
+
+	for i := range plain {
+		plain[i] = security.RandomStringWithAlphabet(5, mfaBackupCodeAlphabet) +
+			"-" +
+			security.RandomStringWithAlphabet(5, mfaBackupCodeAlphabet)
+		hashed[i] = hashMFABackupCode(plain[i])
+	}
+
+	return plain, hashed
+}
+
+func hashMFABackupCode(code string) string {
+	hash := sha256.Sum256([]byte(strings.ToLower(strings.TrimSpace(code))))
+
+	return hex.EncodeToString(hash[:])
+}
+
+// validateMFACode checks whether code is a valid TOTP code of the encrypted secret
+// and returns its time step counter.
+//
+// To prevent replays, the codes with counter less or equal
+// to lastCounter (aka. the last accepted code counter) are rejected.
+func validateMFACode(encryptedSecret string, encryptionKey string, code string, lastCounter int) (int, bool) {
+	if encryptedSecret == "" {
+		return 0, false
+	}
+
+	secret, err := security.Decrypt(encryptedSecret, encryptionKey)
+	if err != nil {
+		return 0, false
+	}
+
+	counter, ok := security.MatchTOTPCode(string(secret), code, time.Now())
+	if !ok || int(counter) <= lastCounter {
+		return 0, false
+	}
+
+	return int(counter), true
+}
+
+// useMFABackupCode checks whether code matches one of the hashed backup codes
+// and returns the remaining ones (aka. without the matched code).
+func useMFABackupCode(hashedCodes []string, code string) (bool, []string) {
+	hash := hashMFABackupCode(code)
+
+	for i, h := range hashedCodes {
+		if subtle.ConstantTimeCompare([]byte(h), []byte(hash)) == 1 {
+			remaining := make([]string, 0, len(hashedCodes)-1)
+			remaining = append(remaining, hashedCodes[:i]...)
+			remaining = append(remaining, hashedCodes[i+1:]...)
+
+			return true, remaining
+		}
+	}
+
+	return false, hashedCodes
+}
+
+// IsInvalidMFACodeError reports whether err is the MFA login forms
+// error returned for a valid MFA challenge but invalid TOTP or backup code.
+func IsInvalidMFACodeError(err error) bool {
+	errs, ok := err.(validation.Errors)
+	if !ok {
+		return false
+	}
+
+	codeErr, ok := errs["code"].(validation.Error)
+
+	return ok && codeErr.Code() == "validation_invalid_mfa_code"
+}
+
+var mfaChallengesStoreMux sync.Mutex
+
+// mfaChallengesStore returns the app MFA challenges failures store
+// (lazily creating a new in-memory one if missing).
+func mfaChallengesStore(app core.App) lockout.Store {
+	if store, ok := app.Cache().Get(mfaChallengesStoreCacheKey).(lockout.Store); ok {
+		return store
+	}
+
+	mfaChallengesStoreMux.Lock()
+	defer mfaChallengesStoreMux.Unlock()
+
+	// check again in case it was created while waiting for the lock
+	if store, ok := app.Cache().Get(mfaChallengesStoreCacheKey).(lockout.Store); ok {
+		return store
+	}
+
+	store := lockout.NewMemoryStore()
+	app.Cache().Set(mfaChallengesStoreCacheKey, store)
+
+	return store
+}
+
+func mfaChallengeKey(mfaId string) string {
+	hash := sha256.Sum256([]byte(mfaId))
+
+	return "mfa:" + hex.EncodeToString(hash[:])
+}
+
+// isMFAChallengeInvalidated reports whether the mfaId challenge
+// was invalidated because of too many failed code attempts.
+func isMFAChallengeInvalidated(app core.App, mfaId string) bool {
+	lockedFor, err := mfaChallengesStore(app).Check(mfaChallengeKey(mfaId))
+
+	return err == nil && lockedFor > 0
+}
+
+// failMFAChallenge registers a failed code attempt for the mfaId challenge.
+//
+// After mfaMaxCodeAttempts failures the challenge is invalidated
+// for the rest of its lifetime (aka. the MFA token duration in seconds).
+func failMFAChallenge(app core.App, mfaId string, tokenDuration int64) {
+	duration := time.Duration(tokenDuration) * time.Second
+
+	if _, err := mfaChallengesStore(app).Fail(mfaChallengeKey(mfaId), mfaMaxCodeAttempts, duration, duration); err != nil {
+		app.Logger().Debug("Failed to register the MFA challenge failure", "error", err)
+	}
+}
+
+func invalidMFACodeError() error {
+	return validation.Errors{
+		"code": validation.NewError("validation_invalid_mfa_code", "Invalid or expired MFA code."),
+	}
+}
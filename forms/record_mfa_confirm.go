@@ -0,0 +1,102 @@
+package forms
+
+import (
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/unkod/space/core"
+	"github.com/unkod/space/daos"
+	"github.com/unkod/space/models"
+)
+
+// RecordMFAConfirm is an auth record TOTP MFA enrollment confirmation form.
+type RecordMFAConfirm struct {
+	app    core.App
+	dao    *daos.Dao
+	record *models.Record
+
+	// counter is the time step counter of the validated code
+	counter int
+
+	Code string `form:"code" json:"code"`
+}
+
+// NewRecordMFAConfirm creates a new [RecordMFAConfirm] form initialized with
+// the provided [core.App] instance and the auth record to confirm.
+//
+// If you want to submit the form as part of a transaction,
+// you can change the default Dao via [SetDao()].
+func NewRecordMFAConfirm(app core.App, record *models.Record) *RecordMFAConfirm {
+	return &RecordMFAConfirm{
+		app:    app,
+		dao:    app.Dao(),
+		record: record,
+	}
+}
+
+// SetDao replaces the default form Dao instance with the provided one.
+func (form *RecordMFAConfirm) SetDao(dao *daos.Dao) {
+	form.dao = dao
+}
+
+// Validate makes the form validatable by implementing [validation.Validatable] interface.
+func (form *RecordMFAConfirm) Validate() error {
+	return validation.ValidateStruct(form,
+		validation.Field(&form.Code, validation.Required, validation.By(form.checkCode)),
+	)
+}
+
+func (form *RecordMFAConfirm) checkCode(value any) error {
+	v, _ := value.(string)
+	if v == "" {
+		return nil // nothing to check
+	}
+
+	if form.record.MFAEnabled() {
+		return validation.NewError("validation_mfa_already_enabled", "MFA is already enabled.")
+	}
+
+	key := mfaEncryptionKey(form.app, form.app.Settings().RecordMFAToken.Secret)
+
+	counter, ok := validateMFACode(form.record.MFASecret(), key, v, form.record.MFALastCounter())
+	if !ok {
+		return validation.NewError("validation_invalid_mfa_code", "Invalid or expired MFA code.")
+	}
+
+	form.counter = counter
+
+	return nil
+}
+
+// Submit validates and submits the form, enabling the auth record MFA.
+// On success returns the generated plain backup codes.
+//
+// You can optionally provide a list of InterceptorFunc to
+// further modify the form behavior before persisting it.
+func (form *RecordMFAConfirm) Submit(interceptors ...InterceptorFunc[*models.Record]) ([]string, error) {
+	if err := form.Validate(); err != nil {
+		return nil, err
+	}
+
+	backupCodes, hashedBackupCodes := newMFABackupCodes()
+
+	if err := form.record.SetMFAEnabled(true); err != nil {
+		return nil, err
+	}
+
+	if err := form.record.SetMFABackupCodes(hashedBackupCodes); err != nil {
+		return nil, err
+	}
+
+	if err := form.record.SetMFALastCounter(form.counter); err != nil {
+		return nil, err
+	}
+
+	interceptorsErr := runInterceptors(form.record, func(m *models.Record) error {
+		return form.dao.SaveRecord(m)
+	}, interceptors...)
+
+	if interceptorsErr != nil {
+		return nil, interceptorsErr
+	}
+
+	return backupCodes, nil
+}
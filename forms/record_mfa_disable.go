@@ -0,0 +1,85 @@
+package forms
+
+import (
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/unkod/space/core"
+	"github.com/unkod/space/daos"
+	"github.com/unkod/space/models"
+)
+
+// RecordMFADisable is an auth record MFA disable form.
+type RecordMFADisable struct {
+	app    core.App
+	dao    *daos.Dao
+	record *models.Record
+
+	// Code is a TOTP or backup code.
+	Code string `form:"code" json:"code"`
+}
+
+// NewRecordMFADisable creates a new [RecordMFADisable] form initialized with
+// the provided [core.App] instance and the auth record to update.
+//
+// If you want to submit the form as part of a transaction,
+// you can change the default Dao via [SetDao()].
+func NewRecordMFADisable(app core.App, record *models.Record) *RecordMFADisable {
+	return &RecordMFADisable{
+		app:    app,
+		dao:    app.Dao(),
+		record: record,
+	}
+}
+
+// SetDao replaces the default form Dao instance with the provided one.
+func (form *RecordMFADisable) SetDao(dao *daos.Dao) {
+	form.dao = dao
+}
+
+// Validate makes the form validatable by implementing [validation.Validatable] interface.
+func (form *RecordMFADisable) Validate() error {
+	return validation.ValidateStruct(form,
+		validation.Field(&form.Code, validation.Required, validation.By(form.checkCode)),
+	)
+}
+
+func (form *RecordMFADisable) checkCode(value any) error {
+	v, _ := value.(string)
+	if v == "" {
+		return nil // nothing to check
+	}
+
+	if !form.record.MFAEnabled() {
+		return validation.NewError("validation_mfa_not_enabled", "MFA is not enabled.")
+	}
+
+	key := mfaEncryptionKey(form.app, form.app.Settings().RecordMFAToken.Secret)
+
+	if _, ok := validateMFACode(form.record.MFASecret(), key, v, form.record.MFALastCounter()); ok {
+		return nil
+	}
+
+	if ok, _ := useMFABackupCode(form.record.MFABackupCodes(), v); ok {
+		return nil
+	}
+
+	return validation.NewError("validation_invalid_mfa_code", "Invalid or expired MFA code.")
+}
+
+// Submit validates and submits the form, disabling the auth record MFA.
+//
+// You can optionally provide a list of InterceptorFunc to
+// further modify the form behavior before persisting it.
+func (form *RecordMFADisable) Submit(interceptors ...InterceptorFunc[*models.Record]) error {
+	if err := form.Validate(); err != nil {
+		return err
+	}
+
+	form.record.SetMFAEnabled(false)
+	form.record.SetMFASecret("")
+	form.record.SetMFABackupCodes(nil)
+	form.record.SetMFALastCounter(0)
+
+	return runInterceptors(form.record, func(m *models.Record) error {
+		return form.dao.SaveRecord(m)
+	}, interceptors...)
+}
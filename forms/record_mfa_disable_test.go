@@ -0,0 +1,59 @@
+package forms_test
+
+import (
+	"testing"
+
+	"github.com/unkod/space/forms"
+	"github.com/unkod/space/tests"
+)
+
+func TestRecordMFADisableValidateAndSubmit(t *testing.T) {
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	record, err := app.Dao().FindAuthRecordByEmail("users", "test@example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// not enabled
+	form := forms.NewRecordMFADisable(app, record)
+	form.Code = "123456"
+	if err := form.Submit(); err == nil {
+		t.Fatal("Expected error for not enabled MFA")
+	}
+
+	secret, _ := tests.EnableRecordMFA(t, app, record)
+
+	scenarios := []struct {
+		code        string
+		expectError bool
+	}{
+		{"", true},
+		{"invalid", true},
+		// already used code
+		{tests.MFACode(t, secret, -1), true},
+		{tests.MFACode(t, secret, 0), false},
+	}
+
+	for i, s := range scenarios {
+		form := forms.NewRecordMFADisable(app, record)
+		form.Code = s.code
+
+		err := form.Submit()
+
+		hasErr := err != nil
+		if hasErr != s.expectError {
+			t.Fatalf("[%d] Expected hasErr %v, got %v (%v)", i, s.expectError, hasErr, err)
+		}
+	}
+
+	stored, err := app.Dao().FindRecordById(record.Collection().Id, record.Id)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if stored.MFAEnabled() || stored.MFASecret() != "" || len(stored.MFABackupCodes()) != 0 {
+		t.Fatalf("Expected MFA to be disabled, got %v %q %v", stored.MFAEnabled(), stored.MFASecret(), stored.MFABackupCodes())
+	}
+}
@@ -0,0 +1,83 @@
+package forms
+
+import (
+	"errors"
+
+	"github.com/unkod/space/core"
+	"github.com/unkod/space/daos"
+	"github.com/unkod/space/models"
+	"github.com/unkod/space/tools/security"
+)
+
+// RecordMFAEnroll is an auth record TOTP MFA enrollment form.
+//
+// The enrollment becomes active only after its confirmation with [RecordMFAConfirm].
+type RecordMFAEnroll struct {
+	app    core.App
+	dao    *daos.Dao
+	record *models.Record
+}
+
+// NewRecordMFAEnroll creates a new [RecordMFAEnroll] form initialized with
+// the provided [core.App] instance and the auth record to enroll.
+//
+// If you want to submit the form as part of a transaction,
+// you can change the default Dao via [SetDao()].
+func NewRecordMFAEnroll(app core.App, record *models.Record) *RecordMFAEnroll {
+	return &RecordMFAEnroll{
+		app:    app,
+		dao:    app.Dao(),
+		record: record,
+	}
+}
+
+// SetDao replaces the default form Dao instance with the provided one.
+func (form *RecordMFAEnroll) SetDao(dao *daos.Dao) {
+	form.dao = dao
+}
+
+// Submit generates and stores a new pending auth record TOTP secret.
+//
+// You can optionally provide a list of InterceptorFunc to
+// further modify the form behavior before persisting it.
+func (form *RecordMFAEnroll) Submit(interceptors ...InterceptorFunc[*models.Record]) (*MFAEnrollment, error) {
+	if form.record.MFAEnabled() {
+		return nil, errors.New("MFA is already enabled.")
+	}
+
+	secret, err := security.NewTOTPSecret()
+	if err != nil {
+		return nil, err
+	}
+
+	encryptedSecret, err := security.Encrypt(
+		[]byte(secret),
+		mfaEncryptionKey(form.app, form.app.Settings().RecordMFAToken.Secret),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := form.record.SetMFASecret(encryptedSecret); err != nil {
+		return nil, err
+	}
+
+	if err := form.record.SetMFALastCounter(0); err != nil {
+		return nil, err
+	}
+
+	interceptorsErr := runInterceptors(form.record, func(m *models.Record) error {
+		return form.dao.SaveRecord(m)
+	}, interceptors...)
+
+	if interceptorsErr != nil {
+		return nil, interceptorsErr
+	}
+
+	account := form.record.Email()
+	if account == "" {
+		account = form.record.Username()
+	}
+
+	return newMFAEnrollment(form.app, account, secret), nil
+}
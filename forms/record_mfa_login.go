@@ -0,0 +1,113 @@
+package forms
+
+import (
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/unkod/space/core"
+	"github.com/unkod/space/daos"
+	"github.com/unkod/space/models"
+)
+
+// RecordMFALogin is an auth record MFA login form
+// (aka. the second step of the password or OAuth2 authentication).
+type RecordMFALogin struct {
+	app        core.App
+	dao        *daos.Dao
+	collection *models.Collection
+
+	// MfaId is the MFA challenge token returned by the first auth step.
+	MfaId string `form:"mfaId" json:"mfaId"`
+
+	// Code is a TOTP or backup code.
+	Code string `form:"code" json:"code"`
+}
+
+// NewRecordMFALogin creates a new [RecordMFALogin] form initialized
+// with from the provided [core.App] and [models.Collection] instance.
+//
+// If you want to submit the form as part of a transaction,
+// you can change the default Dao via [SetDao()].
+func NewRecordMFALogin(app core.App, collection *models.Collection) *RecordMFALogin {
+	return &RecordMFALogin{
+		app:        app,
+		dao:        app.Dao(),
+		collection: collection,
+	}
+}
+
+// SetDao replaces the default form Dao instance with the provided one.
+func (form *RecordMFALogin) SetDao(dao *daos.Dao) {
+	form.dao = dao
+}
+
+// Validate makes the form validatable by implementing [validation.Validatable] interface.
+func (form *RecordMFALogin) Validate() error {
+	return validation.ValidateStruct(form,
+		validation.Field(&form.MfaId, validation.Required, validation.By(form.checkMfaId)),
+		validation.Field(&form.Code, validation.Required, validation.Length(1, 100)),
+	)
+}
+
+func (form *RecordMFALogin) checkMfaId(value any) error {
+	v, _ := value.(string)
+	if v == "" {
+		return nil // nothing to check
+	}
+
+	record, err := form.dao.FindAuthRecordByToken(v, form.app.Settings().RecordMFAToken.Secret)
+	if err != nil || record == nil || record.Collection().Id != form.collection.Id || !record.MFAEnabled() ||
+		isMFAChallengeInvalidated(form.app, v) {
+		return validation.NewError("validation_invalid_mfa_id", "Invalid or expired MFA challenge.")
+	}
+
+	return nil
+}
+
+// Submit validates and submits the form.
+// On success returns the authorized record model.
+//
+// If a backup code is used, it is removed from the record backup codes.
+// Otherwise the TOTP code counter is stored to prevent reusing the same code.
+//
+// After too many failed code attempts the MFA challenge is invalidated.
+//
+// You can optionally provide a list of InterceptorFunc to
+// further modify the form behavior before persisting it.
+func (form *RecordMFALogin) Submit(interceptors ...InterceptorFunc[*models.Record]) (*models.Record, error) {
+	if err := form.Validate(); err != nil {
+		return nil, err
+	}
+
+	record, err := form.dao.FindAuthRecordByToken(form.MfaId, form.app.Settings().RecordMFAToken.Secret)
+	if err != nil {
+		return nil, err
+	}
+
+	key := mfaEncryptionKey(form.app, form.app.Settings().RecordMFAToken.Secret)
+
+	if counter, ok := validateMFACode(record.MFASecret(), key, form.Code, record.MFALastCounter()); ok {
+		record.SetMFALastCounter(counter)
+	} else {
+		ok, remaining := useMFABackupCode(record.MFABackupCodes(), form.Code)
+		if !ok {
+			failMFAChallenge(form.app, form.MfaId, form.app.Settings().RecordMFAToken.Duration)
+			return nil, invalidMFACodeError()
+		}
+
+		record.SetMFABackupCodes(remaining)
+	}
+
+	if err := form.dao.SaveRecord(record); err != nil {
+		return nil, err
+	}
+
+	interceptorsErr := runInterceptors(record, func(m *models.Record) error {
+		record = m
+		return nil
+	}, interceptors...)
+
+	if interceptorsErr != nil {
+		return nil, interceptorsErr
+	}
+
+	return record, nil
+}
@@ -0,0 +1,164 @@
+package forms
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/go-ozzo/ozzo-validation/v4/is"
+	"github.com/unkod/space/core"
+	"github.com/unkod/space/daos"
+	"github.com/unkod/space/models"
+	"github.com/unkod/space/tools/auth"
+)
+
+// RecordOAuth2LinkData defines the RecordOAuth2Link form submit interceptor data.
+type RecordOAuth2LinkData struct {
+	ExternalAuth   *models.ExternalAuth
+	Record         *models.Record
+	OAuth2User     *auth.AuthUser
+	ProviderClient auth.Provider
+}
+
+// RecordOAuth2Link is a form that links an additional
+// OAuth2 provider to an existing auth record.
+type RecordOAuth2Link struct {
+	app    core.App
+	dao    *daos.Dao
+	record *models.Record
+
+	// The name of the OAuth2 client provider (eg. "google")
+	Provider string `form:"provider" json:"provider"`
+
+	// The authorization code returned from the initial request.
+	Code string `form:"code" json:"code"`
+
+	// The code verifier sent with the initial request as part of the code_challenge.
+	//
+	// It is optional only for the confidential-client flow (without PKCE).
+	CodeVerifier string `form:"codeVerifier" json:"codeVerifier"`
+
+	// The state returned from the initial request.
+	//
+	// It is required when a code verifier is set (aka. the PKCE flow) and
+	// it must be the one generated with [NewOAuth2State] for the same
+	// provider and code verifier no more than [OAuth2StateMaxAge] ago.
+	State string `form:"state" json:"state"`
+
+	// The redirect url sent with the initial request.
+	RedirectUrl string `form:"redirectUrl" json:"redirectUrl"`
+}
+
+// NewRecordOAuth2Link creates a new [RecordOAuth2Link] form
+// initialized with the provided [core.App] instance and auth record.
+//
+// If you want to submit the form as part of a transaction,
+// you can change the default Dao via [SetDao()].
+func NewRecordOAuth2Link(app core.App, record *models.Record) *RecordOAuth2Link {
+	return &RecordOAuth2Link{
+		app:    app,
+		dao:    app.Dao(),
+		record: record,
+	}
+}
+
+// SetDao replaces the default form Dao instance with the provided one.
+func (form *RecordOAuth2Link) SetDao(dao *daos.Dao) {
+	form.dao = dao
+}
+
+// Validate makes the form validatable by implementing [validation.Validatable] interface.
+func (form *RecordOAuth2Link) Validate() error {
+	return validation.ValidateStruct(form,
+		validation.Field(&form.Provider, validation.Required, validation.By(form.checkProviderName)),
+		validation.Field(&form.Code, validation.Required),
+		validation.Field(&form.CodeVerifier, validation.When(form.State != "", validation.Required)),
+		validation.Field(
+			&form.State,
+			validation.When(form.CodeVerifier != "", validation.Required),
+			validation.By(form.checkState),
+		),
+		validation.Field(&form.RedirectUrl, validation.Required, is.URL),
+	)
+}
+
+func (form *RecordOAuth2Link) checkState(value any) error {
+	state, _ := value.(string)
+
+	return checkOAuth2State(form.app, state, form.Provider, form.CodeVerifier)
+}
+
+func (form *RecordOAuth2Link) checkProviderName(value any) error {
+	name, _ := value.(string)
+
+	if err := checkOAuth2ProviderName(form.app, name); err != nil {
+		return err
+	}
+
+	if rel, _ := form.dao.FindExternalAuthByRecordAndProvider(form.record, name); rel != nil {
+		return validation.NewError("validation_provider_already_linked", "The provider is already linked to the auth record.")
+	}
+
+	return nil
+}
+
+// Submit validates the form, fetches the OAuth2 user and links it
+// to the form auth record by creating a new [models.ExternalAuth].
+//
+// Returns an error if the OAuth2 user is already linked to another auth record.
+//
+// You can optionally provide a list of InterceptorFunc to further
+// modify the form behavior before persisting it.
+//
+// On success returns the created external auth relation and the fetched provider's data.
+func (form *RecordOAuth2Link) Submit(
+	interceptors ...InterceptorFunc[*RecordOAuth2LinkData],
+) (*models.ExternalAuth, *auth.AuthUser, error) {
+	if form.record == nil || !form.record.Collection().IsAuth() {
+		return nil, nil, errors.New("missing or invalid auth record")
+	}
+
+	if !form.record.Collection().AuthOptions().AllowOAuth2Auth {
+		return nil, nil, errors.New("OAuth2 authentication is not allowed for the auth collection.")
+	}
+
+	if err := form.Validate(); err != nil {
+		return nil, nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	provider, authUser, err := fetchOAuth2User(ctx, form.app, form.Provider, form.Code, form.CodeVerifier, form.RedirectUrl)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// the OAuth2 identity shouldn't be linked to another record
+	if rel, _ := form.dao.FindExternalAuthByProvider(form.Provider, authUser.Id); rel != nil {
+		return nil, authUser, errors.New("the OAuth2 account is already linked to another auth record")
+	}
+
+	interceptorData := &RecordOAuth2LinkData{
+		ExternalAuth: &models.ExternalAuth{
+			CollectionId: form.record.Collection().Id,
+			RecordId:     form.record.Id,
+			Provider:     form.Provider,
+			ProviderId:   authUser.Id,
+		},
+		Record:         form.record,
+		OAuth2User:     authUser,
+		ProviderClient: provider,
+	}
+
+	interceptorsErr := runInterceptors(interceptorData, func(data *RecordOAuth2LinkData) error {
+		return form.dao.SaveExternalAuth(data.ExternalAuth)
+	}, interceptors...)
+
+	if interceptorsErr != nil {
+		return nil, interceptorData.OAuth2User, interceptorsErr
+	}
+
+	return interceptorData.ExternalAuth, interceptorData.OAuth2User, nil
+}
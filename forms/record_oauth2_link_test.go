@@ -0,0 +1,103 @@
+package forms_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+	"time"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/unkod/space/forms"
+	"github.com/unkod/space/tests"
+)
+
+func TestRecordOAuth2LinkValidate(t *testing.T) {
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	state := forms.NewOAuth2State(app, "gitlab", "test_verifier")
+	expiredState := signTestOAuth2State(app, "gitlab", "test_verifier", time.Now().Add(-forms.OAuth2StateMaxAge-time.Minute))
+
+	scenarios := []struct {
+		testName       string
+		recordId       string
+		jsonData       string
+		expectedErrors []string
+	}{
+		{
+			"empty payload",
+			"oap640cot4yru2s",
+			"{}",
+			[]string{"provider", "code", "redirectUrl"},
+		},
+		{
+			"disabled provider",
+			"oap640cot4yru2s",
+			`{"provider":"github","code":"123","redirectUrl":"https://example.com"}`,
+			[]string{"provider"},
+		},
+		{
+			"provider already linked to the record",
+			"4q1xlclmfloku33",
+			`{"provider":"gitlab","code":"123","redirectUrl":"https://example.com"}`,
+			[]string{"provider"},
+		},
+		{
+			"code verifier without state",
+			"oap640cot4yru2s",
+			`{"provider":"gitlab","code":"123","codeVerifier":"test_verifier","redirectUrl":"https://example.com"}`,
+			[]string{"state"},
+		},
+		{
+			"expired state",
+			"oap640cot4yru2s",
+			fmt.Sprintf(`{"provider":"gitlab","code":"123","codeVerifier":"test_verifier","state":%q,"redirectUrl":"https://example.com"}`, expiredState),
+			[]string{"state"},
+		},
+		{
+			"state with mismatched code verifier",
+			"oap640cot4yru2s",
+			fmt.Sprintf(`{"provider":"gitlab","code":"123","codeVerifier":"other_verifier","state":%q,"redirectUrl":"https://example.com"}`, state),
+			[]string{"state"},
+		},
+		{
+			"valid data",
+			"oap640cot4yru2s",
+			fmt.Sprintf(`{"provider":"gitlab","code":"123","codeVerifier":"test_verifier","state":%q,"redirectUrl":"https://example.com"}`, state),
+			[]string{},
+		},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.testName, func(t *testing.T) {
+			record, err := app.Dao().FindRecordById("users", s.recordId)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			form := forms.NewRecordOAuth2Link(app, record)
+
+			if err := json.Unmarshal([]byte(s.jsonData), form); err != nil {
+				t.Fatalf("Failed to load form data: %v", err)
+			}
+
+			err = form.Validate()
+
+			// parse errors
+			errs, ok := err.(validation.Errors)
+			if !ok && err != nil {
+				t.Fatalf("Failed to parse errors %v", err)
+			}
+
+			// check errors
+			if len(errs) > len(s.expectedErrors) {
+				t.Fatalf("Expected error keys %v, got %v", s.expectedErrors, errs)
+			}
+			for _, k := range s.expectedErrors {
+				if _, ok := errs[k]; !ok {
+					t.Fatalf("Missing expected error key %q in %v", k, errs)
+				}
+			}
+		})
+	}
+}
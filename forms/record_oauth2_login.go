@@ -2,8 +2,13 @@ package forms
 
 import (
 	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
 	"errors"
 	"fmt"
+	"strconv"
+	"strings"
 	"time"
 
 	validation "github.com/go-ozzo/ozzo-validation/v4"
@@ -47,8 +52,17 @@ type RecordOAuth2Login struct {
 	Code string `form:"code" json:"code"`
 
 	// The code verifier sent with the initial request as part of the code_challenge.
+	//
+	// It is optional only for the confidential-client flow (without PKCE).
 	CodeVerifier string `form:"codeVerifier" json:"codeVerifier"`
 
+	// The state returned from the initial request.
+	//
+	// It is required when a code verifier is set (aka. the PKCE flow) and
+	// it must be the one generated with [NewOAuth2State] for the same
+	// provider and code verifier no more than [OAuth2StateMaxAge] ago.
+	State string `form:"state" json:"state"`
+
 	// The redirect url sent with the initial request.
 	RedirectUrl string `form:"redirectUrl" json:"redirectUrl"`
 
@@ -88,15 +102,89 @@ func (form *RecordOAuth2Login) Validate() error {
 	return validation.ValidateStruct(form,
 		validation.Field(&form.Provider, validation.Required, validation.By(form.checkProviderName)),
 		validation.Field(&form.Code, validation.Required),
-		validation.Field(&form.CodeVerifier, validation.Required),
+		validation.Field(&form.CodeVerifier, validation.When(form.State != "", validation.Required)),
+		validation.Field(
+			&form.State,
+			validation.When(form.CodeVerifier != "", validation.Required),
+			validation.By(form.checkState),
+		),
 		validation.Field(&form.RedirectUrl, validation.Required, is.URL),
 	)
 }
 
+func (form *RecordOAuth2Login) checkState(value any) error {
+	state, _ := value.(string)
+
+	return checkOAuth2State(form.app, state, form.Provider, form.CodeVerifier)
+}
+
+// OAuth2StateMaxAge is the max allowed time between the
+// [NewOAuth2State] generation and the OAuth2 login/link submit.
+const OAuth2StateMaxAge = 10 * time.Minute
+
+// oauth2StateMaxClockSkew is the max allowed state issued-at time in the future.
+const oauth2StateMaxClockSkew = 1 * time.Minute
+
+func checkOAuth2State(app core.App, state string, provider string, codeVerifier string) error {
+	if state == "" {
+		return nil
+	}
+
+	invalidErr := validation.NewError("validation_invalid_state", "Invalid or mismatched OAuth2 state.")
+
+	parts := strings.Split(state, ".")
+	if len(parts) != 3 || parts[0] == "" {
+		return invalidErr
+	}
+
+	nonce, rawIssuedAt, signature := parts[0], parts[1], parts[2]
+
+	expected := oauth2StateSignature(app, nonce, rawIssuedAt, provider, codeVerifier)
+	if !hmac.Equal([]byte(signature), []byte(expected)) {
+		return invalidErr
+	}
+
+	issuedAt, err := strconv.ParseInt(rawIssuedAt, 10, 64)
+	if err != nil {
+		return invalidErr
+	}
+
+	age := time.Since(time.Unix(issuedAt, 0))
+	if age > OAuth2StateMaxAge || age < -oauth2StateMaxClockSkew {
+		return validation.NewError("validation_expired_state", "The OAuth2 state has expired.")
+	}
+
+	return nil
+}
+
+// NewOAuth2State generates a new random OAuth2 auth request state
+// that is bound to the specified provider and PKCE code verifier.
+//
+// The state has the format "nonce.issuedAt.signature" and could be later
+// verified by submitting it together with the code verifier as part
+// of the login form (no more than [OAuth2StateMaxAge] after its generation).
+func NewOAuth2State(app core.App, provider string, codeVerifier string) string {
+	nonce := security.RandomString(30)
+	rawIssuedAt := strconv.FormatInt(time.Now().Unix(), 10)
+
+	return nonce + "." + rawIssuedAt + "." + oauth2StateSignature(app, nonce, rawIssuedAt, provider, codeVerifier)
+}
+
+func oauth2StateSignature(app core.App, nonce string, issuedAt string, provider string, codeVerifier string) string {
+	mac := hmac.New(sha256.New, []byte(app.Settings().RecordAuthToken.Secret))
+	mac.Write([]byte(nonce + "." + issuedAt + "." + provider + "." + codeVerifier))
+
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
 func (form *RecordOAuth2Login) checkProviderName(value any) error {
 	name, _ := value.(string)
 
-	config, ok := form.app.Settings().NamedAuthProviderConfigs()[name]
+	return checkOAuth2ProviderName(form.app, name)
+}
+
+func checkOAuth2ProviderName(app core.App, name string) error {
+	config, ok := app.Settings().NamedAuthProviderConfigs()[name]
 	if !ok || !config.Enabled {
 		return validation.NewError("validation_invalid_provider", fmt.Sprintf("%q is missing or is not enabled.", name))
 	}
@@ -125,35 +213,10 @@ func (form *RecordOAuth2Login) Submit(
 		return nil, nil, errors.New("OAuth2 authentication is not allowed for the auth collection.")
 	}
 
-	provider, err := auth.NewProviderByName(form.Provider)
-	if err != nil {
-		return nil, nil, err
-	}
-
 	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
 	defer cancel()
 
-	provider.SetContext(ctx)
-
-	// load provider configuration
-	providerConfig := form.app.Settings().NamedAuthProviderConfigs()[form.Provider]
-	if err := providerConfig.SetupProvider(provider); err != nil {
-		return nil, nil, err
-	}
-
-	provider.SetRedirectUrl(form.RedirectUrl)
-
-	// fetch token
-	token, err := provider.FetchToken(
-		form.Code,
-		oauth2.SetAuthURLParam("code_verifier", form.CodeVerifier),
-	)
-	if err != nil {
-		return nil, nil, err
-	}
-
-	// fetch external auth user
-	authUser, err := provider.FetchAuthUser(token)
+	provider, authUser, err := fetchOAuth2User(ctx, form.app, form.Provider, form.Code, form.CodeVerifier, form.RedirectUrl)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -164,7 +227,11 @@ func (form *RecordOAuth2Login) Submit(
 	rel, _ := form.dao.FindExternalAuthByProvider(form.Provider, authUser.Id)
 	switch {
 	case rel != nil:
-		authRecord, err = form.dao.FindRecordById(form.collection.Id, rel.RecordId)
+		authRecord, err = form.dao.FindRecordById(
+			form.collection.Id,
+			rel.RecordId,
+			daos.SoftDeletedFilter(form.collection, daos.SoftDeletedExclude),
+		)
 		if err != nil {
 			return nil, authUser, err
 		}
@@ -277,3 +344,49 @@ func (form *RecordOAuth2Login) submit(data *RecordOAuth2LoginData) error {
 		return nil
 	})
 }
+
+// fetchOAuth2User exchanges the authorization code for a token
+// and fetches the OAuth2 user of the specified provider.
+//
+// The provided ctx is also set as the returned provider client context.
+func fetchOAuth2User(
+	ctx context.Context,
+	app core.App,
+	providerName string,
+	code string,
+	codeVerifier string,
+	redirectUrl string,
+) (auth.Provider, *auth.AuthUser, error) {
+	provider, err := auth.NewProviderByName(providerName)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	provider.SetContext(ctx)
+
+	// load provider configuration
+	providerConfig := app.Settings().NamedAuthProviderConfigs()[providerName]
+	if err := providerConfig.SetupProvider(provider); err != nil {
+		return nil, nil, err
+	}
+
+	provider.SetRedirectUrl(redirectUrl)
+
+	// fetch token
+	tokenOpts := []oauth2.AuthCodeOption{}
+	if codeVerifier != "" {
+		tokenOpts = append(tokenOpts, oauth2.SetAuthURLParam("code_verifier", codeVerifier))
+	}
+	token, err := provider.FetchToken(code, tokenOpts...)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// fetch external auth user
+	authUser, err := provider.FetchAuthUser(token)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return provider, authUser, nil
+}
@@ -1,18 +1,41 @@
 package forms_test
 
 import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
 	"encoding/json"
+	"fmt"
+	"strconv"
 	"testing"
+	"time"
 
 	validation "github.com/go-ozzo/ozzo-validation/v4"
 	"github.com/unkod/space/forms"
 	"github.com/unkod/space/tests"
 )
 
+// signTestOAuth2State creates a [forms.NewOAuth2State] formatted state
+// with custom issued-at time.
+func signTestOAuth2State(app *tests.TestApp, provider string, codeVerifier string, issuedAt time.Time) string {
+	nonce := "test_nonce"
+	rawIssuedAt := strconv.FormatInt(issuedAt.Unix(), 10)
+
+	mac := hmac.New(sha256.New, []byte(app.Settings().RecordAuthToken.Secret))
+	mac.Write([]byte(nonce + "." + rawIssuedAt + "." + provider + "." + codeVerifier))
+
+	return nonce + "." + rawIssuedAt + "." + base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
 func TestUserOauth2LoginValidate(t *testing.T) {
 	app, _ := tests.NewTestApp()
 	defer app.Cleanup()
 
+	state := forms.NewOAuth2State(app, "gitlab", "test_verifier")
+	recentState := signTestOAuth2State(app, "gitlab", "test_verifier", time.Now().Add(-forms.OAuth2StateMaxAge+time.Minute))
+	expiredState := signTestOAuth2State(app, "gitlab", "test_verifier", time.Now().Add(-forms.OAuth2StateMaxAge-time.Minute))
+	futureState := signTestOAuth2State(app, "gitlab", "test_verifier", time.Now().Add(5*time.Minute))
+
 	scenarios := []struct {
 		testName       string
 		collectionName string
@@ -23,30 +46,96 @@ func TestUserOauth2LoginValidate(t *testing.T) {
 			"empty payload",
 			"users",
 			"{}",
-			[]string{"provider", "code", "codeVerifier", "redirectUrl"},
+			[]string{"provider", "code", "redirectUrl"},
 		},
 		{
 			"empty data",
 			"users",
 			`{"provider":"","code":"","codeVerifier":"","redirectUrl":""}`,
-			[]string{"provider", "code", "codeVerifier", "redirectUrl"},
+			[]string{"provider", "code", "redirectUrl"},
 		},
 		{
 			"missing provider",
 			"users",
-			`{"provider":"missing","code":"123","codeVerifier":"123","redirectUrl":"https://example.com"}`,
+			`{"provider":"missing","code":"123","redirectUrl":"https://example.com"}`,
 			[]string{"provider"},
 		},
 		{
 			"disabled provider",
 			"users",
-			`{"provider":"github","code":"123","codeVerifier":"123","redirectUrl":"https://example.com"}`,
+			`{"provider":"github","code":"123","redirectUrl":"https://example.com"}`,
 			[]string{"provider"},
 		},
 		{
-			"enabled provider",
+			"enabled provider with code verifier and without state",
 			"users",
 			`{"provider":"gitlab","code":"123","codeVerifier":"123","redirectUrl":"https://example.com"}`,
+			[]string{"state"},
+		},
+		{
+			"enabled provider without code verifier (confidential client)",
+			"users",
+			`{"provider":"gitlab","code":"123","redirectUrl":"https://example.com"}`,
+			[]string{},
+		},
+		{
+			"state without code verifier",
+			"users",
+			fmt.Sprintf(`{"provider":"gitlab","code":"123","state":%q,"redirectUrl":"https://example.com"}`, state),
+			[]string{"codeVerifier", "state"},
+		},
+		{
+			"invalid state format",
+			"users",
+			`{"provider":"gitlab","code":"123","codeVerifier":"test_verifier","state":"abc","redirectUrl":"https://example.com"}`,
+			[]string{"state"},
+		},
+		{
+			"state with mismatched code verifier",
+			"users",
+			fmt.Sprintf(`{"provider":"gitlab","code":"123","codeVerifier":"other_verifier","state":%q,"redirectUrl":"https://example.com"}`, state),
+			[]string{"state"},
+		},
+		{
+			"state with mismatched provider",
+			"users",
+			fmt.Sprintf(`{"provider":"google","code":"123","codeVerifier":"test_verifier","state":%q,"redirectUrl":"https://example.com"}`, state),
+			[]string{"provider", "state"},
+		},
+		{
+			"state without issued-at",
+			"users",
+			`{"provider":"gitlab","code":"123","codeVerifier":"test_verifier","state":"test_nonce.test_signature","redirectUrl":"https://example.com"}`,
+			[]string{"state"},
+		},
+		{
+			"expired state",
+			"users",
+			fmt.Sprintf(`{"provider":"gitlab","code":"123","codeVerifier":"test_verifier","state":%q,"redirectUrl":"https://example.com"}`, expiredState),
+			[]string{"state"},
+		},
+		{
+			"state issued in the future",
+			"users",
+			fmt.Sprintf(`{"provider":"gitlab","code":"123","codeVerifier":"test_verifier","state":%q,"redirectUrl":"https://example.com"}`, futureState),
+			[]string{"state"},
+		},
+		{
+			"state with modified issued-at",
+			"users",
+			fmt.Sprintf(`{"provider":"gitlab","code":"123","codeVerifier":"test_verifier","state":%q,"redirectUrl":"https://example.com"}`, "test_nonce."+strconv.FormatInt(time.Now().Unix(), 10)+expiredState[len("test_nonce.")+10:]),
+			[]string{"state"},
+		},
+		{
+			"valid state within the max age",
+			"users",
+			fmt.Sprintf(`{"provider":"gitlab","code":"123","codeVerifier":"test_verifier","state":%q,"redirectUrl":"https://example.com"}`, recentState),
+			[]string{},
+		},
+		{
+			"valid state and code verifier",
+			"users",
+			fmt.Sprintf(`{"provider":"gitlab","code":"123","codeVerifier":"test_verifier","state":%q,"redirectUrl":"https://example.com"}`, state),
 			[]string{},
 		},
 	}
@@ -6,9 +6,12 @@ import (
 
 	validation "github.com/go-ozzo/ozzo-validation/v4"
 	"github.com/go-ozzo/ozzo-validation/v4/is"
+	"github.com/pocketbase/dbx"
 	"github.com/unkod/space/core"
 	"github.com/unkod/space/daos"
 	"github.com/unkod/space/models"
+	"github.com/unkod/space/models/schema"
+	"github.com/unkod/space/tools/security"
 )
 
 // RecordPasswordLogin is record username/email + password login form.
@@ -84,6 +87,8 @@ func (form *RecordPasswordLogin) Submit(interceptors ...InterceptorFunc[*models.
 			return errors.New("Invalid login credentials.")
 		}
 
+		form.rehashPassword(authRecord)
+
 		return nil
 	}, interceptors...)
 
@@ -93,3 +98,33 @@ func (form *RecordPasswordLogin) Submit(interceptors ...InterceptorFunc[*models.
 
 	return authRecord, nil
 }
+
+// rehashPassword rehashes the auth record password with the current
+// password hashing settings if its hash was generated with a different
+// algorithm or weaker parameters.
+//
+// The hash is updated directly in the db (without triggering the model hooks
+// and without changing the record tokenKey so that the existing tokens remain valid).
+func (form *RecordPasswordLogin) rehashPassword(authRecord *models.Record) {
+	opts := form.app.Settings().PasswordHashing.Options()
+
+	if !security.PasswordNeedsRehash(authRecord.PasswordHash(), opts) {
+		return
+	}
+
+	hash, err := security.HashPassword(form.Password, opts)
+	if err == nil {
+		_, err = form.dao.DB().Update(
+			form.collection.Name,
+			dbx.Params{schema.FieldNamePasswordHash: hash},
+			dbx.HashExp{schema.FieldNameId: authRecord.Id},
+		).Execute()
+	}
+
+	if err != nil {
+		form.app.Logger().Debug("Failed to rehash the auth record password", "id", authRecord.Id, "error", err)
+		return
+	}
+
+	authRecord.Set(schema.FieldNamePasswordHash, hash)
+}
@@ -2,6 +2,7 @@ package forms_test
 
 import (
 	"errors"
+	"strings"
 	"testing"
 
 	"github.com/unkod/space/forms"
@@ -180,3 +181,56 @@ func TestRecordPasswordLoginInterceptors(t *testing.T) {
 		t.Fatalf("Expected auth Record model with email %s, got %v", form.Identity, interceptorRecord)
 	}
 }
+
+func TestRecordPasswordLoginRehash(t *testing.T) {
+	testApp, _ := tests.NewTestApp()
+	defer testApp.Cleanup()
+
+	authCollection, err := testApp.Dao().FindCollectionByNameOrId("users")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	original, err := testApp.Dao().FindAuthRecordByEmail(authCollection.Id, "test@example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// switch to argon2id (with low cost params to speed up the test)
+	testApp.Settings().PasswordHashing.Algorithm = "argon2id"
+	testApp.Settings().PasswordHashing.Argon2Memory = 1024
+	testApp.Settings().PasswordHashing.Argon2Iterations = 1
+	testApp.Settings().PasswordHashing.Argon2Parallelism = 1
+
+	for i := 0; i < 2; i++ {
+		form := forms.NewRecordPasswordLogin(testApp, authCollection)
+		form.Identity = "test@example.com"
+		form.Password = "1234567890"
+
+		record, err := form.Submit()
+		if err != nil {
+			t.Fatalf("(%d) Expected the old and new format hashes to be valid, got %v", i, err)
+		}
+
+		if !strings.HasPrefix(record.PasswordHash(), "$argon2id$") {
+			t.Fatalf("(%d) Expected the returned record password to be rehashed, got %q", i, record.PasswordHash())
+		}
+	}
+
+	refreshed, err := testApp.Dao().FindRecordById(authCollection.Id, original.Id)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !strings.HasPrefix(refreshed.PasswordHash(), "$argon2id$v=19$m=1024,t=1,p=1$") {
+		t.Fatalf("Expected the stored password to be rehashed, got %q", refreshed.PasswordHash())
+	}
+
+	if refreshed.TokenKey() != original.TokenKey() {
+		t.Fatalf("Expected the tokenKey to remain unchanged, got %q", refreshed.TokenKey())
+	}
+
+	if refreshed.Updated.String() != original.Updated.String() {
+		t.Fatalf("Expected the updated date to remain unchanged, got %v", refreshed.Updated)
+	}
+}
@@ -39,11 +39,28 @@ func (form *RecordPasswordResetConfirm) SetDao(dao *daos.Dao) {
 
 // Validate makes the form validatable by implementing [validation.Validatable] interface.
 func (form *RecordPasswordResetConfirm) Validate() error {
-	minPasswordLength := form.collection.AuthOptions().MinPasswordLength
+	authOptions := form.collection.AuthOptions()
+
+	// the token record identities for the password policy check
+	var identities []string
+	if authOptions.PasswordPolicy != nil && form.Token != "" {
+		record, _ := form.dao.FindAuthRecordByToken(
+			form.Token,
+			form.app.Settings().RecordPasswordResetToken.Secret,
+		)
+		if record != nil {
+			identities = append(identities, record.Username(), record.Email())
+		}
+	}
 
 	return validation.ValidateStruct(form,
 		validation.Field(&form.Token, validation.Required, validation.By(form.checkToken)),
-		validation.Field(&form.Password, validation.Required, validation.Length(minPasswordLength, 100)),
+		validation.Field(
+			&form.Password,
+			validation.Required,
+			validation.Length(authOptions.MinPasswordLength, 100),
+			validation.By(validators.PasswordPolicy(authOptions.PasswordPolicy, identities...)),
+		),
 		validation.Field(&form.PasswordConfirm, validation.Required, validation.By(validators.Compare(form.Password))),
 	)
 }
@@ -87,7 +104,7 @@ func (form *RecordPasswordResetConfirm) Submit(interceptors ...InterceptorFunc[*
 		return nil, err
 	}
 
-	if err := authRecord.SetPassword(form.Password); err != nil {
+	if err := authRecord.SetPasswordWithOptions(form.Password, form.app.Settings().PasswordHashing.Options()); err != nil {
 		return nil, err
 	}
 
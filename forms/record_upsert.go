@@ -4,8 +4,8 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
-	"log"
 	"net/http"
+	"reflect"
 	"regexp"
 	"strings"
 
@@ -19,14 +19,25 @@ import (
 	"github.com/unkod/space/models"
 	"github.com/unkod/space/models/schema"
 	"github.com/unkod/space/tools/filesystem"
+	"github.com/unkod/space/tools/inflector"
+	"github.com/unkod/space/tools/jsonschema"
 	"github.com/unkod/space/tools/list"
 	"github.com/unkod/space/tools/rest"
 	"github.com/unkod/space/tools/security"
+	"github.com/unkod/space/tools/types"
 )
 
 // username value regex pattern
 var usernameRegex = regexp.MustCompile(`^[\w][\w\.]*$`)
 
+// RecordVersionKey is the name of the optional request data field
+// with the expected record version (see [RecordUpsert.SetVersion]).
+const RecordVersionKey = "@version"
+
+// ErrRecordVersionConflict is returned by [RecordUpsert.Submit] when
+// the expected record version doesn't match the stored one.
+var ErrRecordVersionConflict = errors.New("the record was modified by another request")
+
 // RecordUpsert is a [models.Record] upsert (create/update) form.
 type RecordUpsert struct {
 	app          core.App
@@ -36,6 +47,11 @@ type RecordUpsert struct {
 
 	filesToUpload map[string][]*filesystem.File
 	filesToDelete []string // names list
+	version       string
+	dryRun        bool
+	mergePatch    bool
+	requestInfo   *models.RequestInfo
+	defaulted     []string // names of the fields loaded with their default value
 
 	// base model fields
 	Id string `json:"id"`
@@ -79,6 +95,27 @@ func (form *RecordUpsert) Data() map[string]any {
 	return form.data
 }
 
+// ChangedFields returns the names of the schema fields whose loaded
+// values differ from the current form record ones.
+//
+// The fields loaded with their default value are not considered changed.
+func (form *RecordUpsert) ChangedFields() []string {
+	result := []string{}
+
+	for _, field := range form.record.Collection().Schema.Fields() {
+		value, ok := form.data[field.Name]
+		if !ok || list.ExistInSlice(field.Name, form.defaulted) {
+			continue
+		}
+
+		if !reflect.DeepEqual(value, field.PrepareValue(form.record.Get(field.Name))) {
+			result = append(result, field.Name)
+		}
+	}
+
+	return result
+}
+
 // SetFullManageAccess sets the manageAccess bool flag of the current
 // form to enable/disable directly changing some system record fields
 // (often used with auth collection records).
@@ -91,6 +128,59 @@ func (form *RecordUpsert) SetDao(dao *daos.Dao) {
 	form.dao = dao
 }
 
+// SetVersion enables the optimistic concurrency control for the
+// update of an existing record.
+//
+// The version is the expected `updated` datetime of the stored record
+// and if it doesn't match at the time of the save (the check is
+// performed in the same transaction), [RecordUpsert.Submit] fails with
+// [ErrRecordVersionConflict].
+//
+// A version that is not a valid datetime results in a validation error.
+//
+// An empty version disables the check (default).
+func (form *RecordUpsert) SetVersion(version string) {
+	form.version = version
+}
+
+// SetDryRun enables/disables the dry run mode of [RecordUpsert.Submit].
+//
+// In dry run mode the form is validated and the record is saved
+// (triggering the before model hooks) within a transaction that is
+// always rolled back. The files to upload are validated but discarded,
+// the old record files are not deleted and the after model hooks
+// are never called.
+func (form *RecordUpsert) SetDryRun(dryRun bool) {
+	form.dryRun = dryRun
+}
+
+// SetMergePatch enables/disables the JSON Merge Patch (RFC 7386)
+// semantic for the json fields of the loaded data.
+//
+// When enabled, an object value submitted for a json field is merged
+// recursively with the existing field value (where a `null` key value
+// removes the existing key) instead of replacing it entirely.
+//
+// Regardless of the mode, an explicit `null` (or empty) value clears
+// the field and an omitted key leaves the field unchanged.
+//
+// The mode is enabled automatically by [RecordUpsert.LoadRequest]
+// for requests with "application/merge-patch+json" content-type.
+func (form *RecordUpsert) SetMergePatch(mergePatch bool) {
+	form.mergePatch = mergePatch
+}
+
+// SetRequestInfo sets the request info used to resolve the "@request.*"
+// identifiers of the field default value expressions.
+//
+// The "@request.data.*" identifiers are always resolved from the loaded form data.
+func (form *RecordUpsert) SetRequestInfo(requestInfo *models.RequestInfo) {
+	form.requestInfo = requestInfo
+}
+
+// errDryRunRollback is used to rollback the dry run transaction.
+var errDryRunRollback = errors.New("dry run rollback")
+
 func (form *RecordUpsert) loadFormDefaults() {
 	form.Id = form.record.Id
 
@@ -103,6 +193,9 @@ func (form *RecordUpsert) loadFormDefaults() {
 
 	form.data = map[string]any{}
 	for _, field := range form.record.Collection().Schema.Fields() {
+		if field.Type == schema.FieldTypeComputed {
+			continue // read-only
+		}
 		form.data[field.Name] = form.record.Get(field.Name)
 	}
 }
@@ -124,6 +217,9 @@ func (form *RecordUpsert) extractRequestInfo(
 	switch form.getContentType(r) {
 	case "application/json":
 		return form.extractJsonData(r, keyPrefix)
+	case rest.MIMEApplicationMergePatchJSON:
+		form.SetMergePatch(true)
+		return form.extractJsonData(r, keyPrefix)
 	case "multipart/form-data":
 		return form.extractMultipartFormData(r, keyPrefix)
 	default:
@@ -198,24 +294,27 @@ func (form *RecordUpsert) extractMultipartFormData(
 			fullKey = keyPrefix + "." + key
 		}
 
-		files, err := rest.FindUploadedFiles(r, fullKey)
-		if err != nil || len(files) == 0 {
-			if err != nil && err != http.ErrMissingFile && form.app.IsDebug() {
-				log.Printf("%q uploaded file error: %v\n", fullKey, err)
+		// load both the plain and the "field+" (append modifier) uploaded files
+		for _, uploadKey := range []string{fullKey, fullKey + "+"} {
+			files, err := rest.FindUploadedFiles(r, uploadKey)
+			if err != nil || len(files) == 0 {
+				if err != nil && err != http.ErrMissingFile {
+					form.app.Logger().Debug("Uploaded file error", "key", uploadKey, "error", err)
+				}
+
+				// skip invalid or missing file(s)
+				continue
 			}
 
-			// skip invalid or missing file(s)
-			continue
+			filesToUpload[key] = append(filesToUpload[key], files...)
 		}
-
-		filesToUpload[key] = append(filesToUpload[key], files...)
 	}
 
 	return data, filesToUpload, nil
 }
 
-// LoadRequest extracts the json or multipart/form-data request data
-// and lods it into the form.
+// LoadRequest extracts the json, json merge patch or multipart/form-data
+// request data and lods it into the form.
 //
 // File upload is supported only via multipart/form-data.
 func (form *RecordUpsert) LoadRequest(r *http.Request, keyPrefix string) error {
@@ -355,6 +454,10 @@ func (form *RecordUpsert) LoadData(requestInfo map[string]any) error {
 		form.Id = cast.ToString(v)
 	}
 
+	if v, ok := requestInfo[RecordVersionKey]; ok {
+		form.version = cast.ToString(v)
+	}
+
 	// load auth system fields
 	if form.record.Collection().IsAuth() {
 		if v, ok := requestInfo[schema.FieldNameUsername]; ok {
@@ -383,6 +486,18 @@ func (form *RecordUpsert) LoadData(requestInfo map[string]any) error {
 	// replace modifiers (if any)
 	requestInfo = form.record.ReplaceModifers(requestInfo)
 
+	if form.mergePatch {
+		requestInfo = form.mergePatchJsonFields(requestInfo)
+	}
+
+	if form.record.IsNew() {
+		withDefaults, err := form.applyFieldDefaults(requestInfo)
+		if err != nil {
+			return err
+		}
+		requestInfo = withDefaults
+	}
+
 	// create a shallow copy of form.data
 	var extendedData = make(map[string]any, len(form.data))
 	for k, v := range form.data {
@@ -399,6 +514,10 @@ func (form *RecordUpsert) LoadData(requestInfo map[string]any) error {
 	}
 
 	for _, field := range form.record.Collection().Schema.Fields() {
+		if field.Type == schema.FieldTypeComputed {
+			continue // read-only
+		}
+
 		key := field.Name
 		value := field.PrepareValue(extendedData[key])
 
@@ -454,17 +573,141 @@ func (form *RecordUpsert) LoadData(requestInfo map[string]any) error {
 	return nil
 }
 
+// applyFieldDefaults returns a shallow copy of the request data
+// extended with the evaluated default values of the omitted fields.
+func (form *RecordUpsert) applyFieldDefaults(requestInfo map[string]any) (map[string]any, error) {
+	result := make(map[string]any, len(requestInfo))
+	for k, v := range requestInfo {
+		result[k] = v
+	}
+
+	for _, field := range form.record.Collection().Schema.Fields() {
+		if field.Default == "" {
+			continue
+		}
+
+		if _, ok := requestInfo[field.Name]; ok {
+			continue // explicitly submitted
+		}
+
+		e, err := field.ParseDefault()
+		if err != nil {
+			return nil, validation.Errors{
+				field.Name: validation.NewError("validation_invalid_default", err.Error()),
+			}
+		}
+
+		vars := make(map[string]any, len(e.Identifiers()))
+		for _, identifier := range e.Identifiers() {
+			vars[identifier] = form.resolveDefaultIdentifier(identifier, requestInfo)
+		}
+
+		value, err := e.Eval(vars)
+		if err != nil {
+			return nil, validation.Errors{
+				field.Name: validation.NewError("validation_invalid_default", err.Error()),
+			}
+		}
+
+		result[field.Name] = value
+		form.defaulted = append(form.defaulted, field.Name)
+	}
+
+	return result, nil
+}
+
+// resolveDefaultIdentifier resolves a single
+// [schema.DefaultRequestIdentifiers] default expression identifier.
+func (form *RecordUpsert) resolveDefaultIdentifier(identifier string, data map[string]any) any {
+	if identifier == "@now" {
+		return types.NowDateTime()
+	}
+
+	if name, ok := strings.CutPrefix(identifier, "@request.data."); ok {
+		return data[name]
+	}
+
+	info := form.requestInfo
+	if info == nil {
+		return nil
+	}
+
+	if identifier == "@request.method" {
+		return info.Method
+	}
+
+	if name, ok := strings.CutPrefix(identifier, "@request.query."); ok {
+		return info.Query[name]
+	}
+
+	if name, ok := strings.CutPrefix(identifier, "@request.headers."); ok {
+		return info.Headers[name]
+	}
+
+	if name, ok := strings.CutPrefix(identifier, "@request.auth."); ok && info.AuthRecord != nil {
+		switch name {
+		case schema.FieldNameCollectionId:
+			return info.AuthRecord.Collection().Id
+		case schema.FieldNameCollectionName:
+			return info.AuthRecord.Collection().Name
+		}
+
+		// ParseDefault already excludes the auth secret fields
+		if list.ExistInSlice(name, schema.BaseModelFieldNames()) ||
+			list.ExistInSlice(name, schema.AuthFieldNames()) ||
+			info.AuthRecord.Collection().Schema.GetFieldByName(name) != nil {
+			return info.AuthRecord.Get(name)
+		}
+	}
+
+	return nil
+}
+
+// mergePatchJsonFields returns a shallow copy of the request data with
+// the submitted json field objects merged with the current field values.
+func (form *RecordUpsert) mergePatchJsonFields(requestInfo map[string]any) map[string]any {
+	result := make(map[string]any, len(requestInfo))
+	for k, v := range requestInfo {
+		result[k] = v
+	}
+
+	for _, field := range form.record.Collection().Schema.Fields() {
+		if field.Type != schema.FieldTypeJson {
+			continue
+		}
+
+		patch, ok := result[field.Name].(map[string]any)
+		if !ok {
+			continue // null, missing or non-object values replace the field as usual
+		}
+
+		var current any
+		if raw, _ := form.data[field.Name].(types.JsonRaw); len(raw) > 0 {
+			if err := json.Unmarshal(raw, &current); err != nil {
+				current = nil
+			}
+		}
+
+		result[field.Name] = types.JsonMergePatch(current, patch)
+	}
+
+	return result
+}
+
 // Validate makes the form validatable by implementing [validation.Validatable] interface.
 func (form *RecordUpsert) Validate() error {
+	// collection JSON Schema validator (if any)
+	if err := form.validateJsonSchema(); err != nil {
+		return err
+	}
+
 	// base form fields validator
 	baseFieldsRules := []*validation.FieldRules{
 		validation.Field(
 			&form.Id,
 			validation.When(
 				form.record.IsNew(),
-				validation.Length(models.DefaultIdLength, models.DefaultIdLength),
-				validation.Match(idRegex),
-				validation.By(validators.UniqueId(form.dao, form.record.TableName())),
+				form.newIdRules()...,
 			).Else(validation.In(form.record.Id)),
 		),
 	}
@@ -513,6 +756,13 @@ func (form *RecordUpsert) Validate() error {
 					validation.Required,
 				),
 				validation.Length(form.record.Collection().AuthOptions().MinPasswordLength, 72),
+				validation.By(validators.PasswordPolicy(
+					form.record.Collection().AuthOptions().PasswordPolicy,
+					form.Username,
+					form.Email,
+					form.record.Username(),
+					form.record.Email(),
+				)),
 			),
 			validation.Field(
 				&form.PasswordConfirm,
@@ -548,6 +798,144 @@ func (form *RecordUpsert) Validate() error {
 	).Validate(form.data)
 }
 
+// newIdRules returns the validation rules of a new record id
+// based on the "idPattern" and "disableAutoId" collection options.
+func (form *RecordUpsert) newIdRules() []validation.Rule {
+	collection := form.record.Collection()
+
+	rules := []validation.Rule{
+		validation.When(!collection.HasAutoId(), validation.Required),
+	}
+
+	if pattern := collection.IdPattern(); pattern != "" {
+		rules = append(rules,
+			validation.Length(1, models.MaxCustomIdLength),
+			validation.By(checkIdPattern(pattern)),
+		)
+	} else {
+		rules = append(rules, validation.Length(models.DefaultIdLength, models.DefaultIdLength))
+	}
+
+	return append(rules,
+		validation.Match(idRegex),
+		validation.By(validators.UniqueId(form.dao, form.record.TableName())),
+	)
+}
+
+func checkIdPattern(pattern string) validation.RuleFunc {
+	return func(value any) error {
+		v, _ := value.(string)
+		if v == "" {
+			return nil // nothing to check
+		}
+
+		if ok, _ := regexp.MatchString(pattern, v); !ok {
+			return validation.NewError("validation_invalid_id", "The id doesn't match the collection id pattern.")
+		}
+
+		return nil
+	}
+}
+
+// JsonSchemaRootErrorKey is the form validation errors key of
+// the collection JSON Schema errors that are not specific to a field.
+const JsonSchemaRootErrorKey = "jsonSchema"
+
+// validateJsonSchema checks the form data against the
+// collection "jsonSchema" option (if any).
+//
+// The validated document contains the record id, the auth fields
+// (except the password ones) and the schema fields data. The blank
+// (null, empty string or array) values are omitted so that the
+// JSON Schema "required" keyword behaves like the fields required option.
+func (form *RecordUpsert) validateJsonSchema() error {
+	document := form.record.Collection().JsonSchema()
+	if document == nil {
+		return nil
+	}
+
+	jsonSchema, err := jsonschema.Compile(document)
+	if err != nil {
+		return err
+	}
+
+	data := make(map[string]any, len(form.data)+5)
+	for k, v := range form.data {
+		data[k] = v
+	}
+	data[schema.FieldNameId] = form.Id
+	if form.record.Collection().IsAuth() {
+		data[schema.FieldNameUsername] = form.Username
+		data[schema.FieldNameEmail] = form.Email
+		data[schema.FieldNameEmailVisibility] = form.EmailVisibility
+		data[schema.FieldNameVerified] = form.Verified
+	}
+
+	// normalize the values to their JSON representation
+	raw, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+	normalized := map[string]any{}
+	if err := json.Unmarshal(raw, &normalized); err != nil {
+		return err
+	}
+	for k, v := range normalized {
+		if items, ok := v.([]any); (ok && len(items) == 0) || v == nil || v == "" {
+			delete(normalized, k)
+		}
+	}
+
+	err = jsonSchema.Validate(normalized)
+
+	var schemaErrs jsonschema.Errors
+	if !errors.As(err, &schemaErrs) {
+		return err
+	}
+
+	return jsonSchemaValidationErrors(schemaErrs)
+}
+
+// jsonSchemaValidationErrors converts the provided JSON Schema errors into
+// nested validation errors (eg. "/address/city" -> {"address": {"city": ...}}).
+//
+// Only the first error of each path is kept.
+func jsonSchemaValidationErrors(schemaErrs jsonschema.Errors) validation.Errors {
+	result := validation.Errors{}
+
+	for _, schemaErr := range schemaErrs {
+		segments := schemaErr.PathSegments()
+		if len(segments) == 0 {
+			segments = []string{JsonSchemaRootErrorKey}
+		}
+
+		current := result
+		for i, segment := range segments {
+			if i == len(segments)-1 {
+				if _, ok := current[segment]; !ok {
+					current[segment] = validation.NewError(
+						"validation_json_schema_"+inflector.Snakecase(schemaErr.Keyword),
+						schemaErr.Message,
+					)
+				}
+				break
+			}
+
+			next, ok := current[segment].(validation.Errors)
+			if !ok {
+				if _, exists := current[segment]; exists {
+					break // there is already a more generic error
+				}
+				next = validation.Errors{}
+				current[segment] = next
+			}
+			current = next
+		}
+	}
+
+	return result
+}
+
 func (form *RecordUpsert) checkUniqueUsername(value any) error {
 	v, _ := value.(string)
 	if v == "" {
@@ -666,7 +1054,7 @@ func (form *RecordUpsert) ValidateAndFill() error {
 		}
 
 		if form.Password != "" && form.Password == form.PasswordConfirm {
-			if err := form.record.SetPassword(form.Password); err != nil {
+			if err := form.record.SetPasswordWithOptions(form.Password, form.app.Settings().PasswordHashing.Options()); err != nil {
 				return err
 			}
 		}
@@ -698,6 +1086,9 @@ func (form *RecordUpsert) DrySubmit(callback func(txDao *daos.Dao) error) error
 		// otherwise use the form noncurrent dao db pool
 		dryDao = daos.New(form.dao.NonconcurrentDB())
 	}
+	dryDao.EncryptionKeys = form.dao.EncryptionKeys
+	dryDao.ExpandMaxDepth = form.dao.ExpandMaxDepth
+	dryDao.IndirectExpandLimit = form.dao.IndirectExpandLimit
 
 	return dryDao.RunInTransaction(func(txDao *daos.Dao) error {
 		tx, ok := txDao.DB().(*dbx.Tx)
@@ -740,6 +1131,10 @@ func (form *RecordUpsert) Submit(interceptors ...InterceptorFunc[*models.Record]
 			form.record.MarkAsNew()
 		}
 
+		if form.dryRun {
+			return form.dryRunSave()
+		}
+
 		dao := form.dao.Clone()
 
 		// upload new files (if any)
@@ -786,7 +1181,10 @@ func (form *RecordUpsert) Submit(interceptors ...InterceptorFunc[*models.Record]
 		// ---
 
 		// persist the record model
-		if err := dao.SaveRecord(form.record); err != nil {
+		if err := form.saveRecord(dao); err != nil {
+			if errors.Is(err, ErrRecordVersionConflict) {
+				return err
+			}
 			return form.prepareError(err)
 		}
 
@@ -794,14 +1192,88 @@ func (form *RecordUpsert) Submit(interceptors ...InterceptorFunc[*models.Record]
 		//
 		// for now fail silently to avoid reupload when `form.Submit()`
 		// is called manually (aka. not from an api request)...
-		if err := form.processFilesToDelete(); err != nil && form.app.IsDebug() {
-			log.Println(err)
+		if err := form.processFilesToDelete(); err != nil {
+			form.app.Logger().Debug("Failed to delete the old record files", "error", err)
 		}
 
 		return nil
 	}, interceptors...)
 }
 
+// dryRunSave saves the form record within a rolled back transaction.
+func (form *RecordUpsert) dryRunSave() error {
+	isNew := form.record.IsNew()
+
+	dryDao := form.dao.Clone()
+	if form.dao.ConcurrentDB() == form.dao.NonconcurrentDB() {
+		// it is already in a transaction and therefore use the app concurrent db pool
+		// to prevent rolling back the parent transaction (see also DrySubmit)
+		dryDao = daos.New(form.app.Dao().ConcurrentDB())
+		dryDao.EncryptionKeys = form.dao.EncryptionKeys
+		dryDao.ExpandMaxDepth = form.dao.ExpandMaxDepth
+		dryDao.IndirectExpandLimit = form.dao.IndirectExpandLimit
+		dryDao.BeforeCreateFunc = form.dao.BeforeCreateFunc
+		dryDao.BeforeUpdateFunc = form.dao.BeforeUpdateFunc
+	}
+
+	// note: the after model hooks are invoked only on transaction commit
+	err := dryDao.RunInTransaction(func(txDao *daos.Dao) error {
+		if err := form.saveRecord(txDao); err != nil {
+			return err
+		}
+
+		return errDryRunRollback
+	})
+
+	// restore record isNew state
+	if isNew {
+		form.record.MarkAsNew()
+	}
+
+	switch {
+	case errors.Is(err, errDryRunRollback):
+		return nil
+	case errors.Is(err, ErrRecordVersionConflict):
+		return err
+	default:
+		return form.prepareError(err)
+	}
+}
+
+// saveRecord persists the form record, checking the expected
+// record version (if any) in the same transaction as the save.
+func (form *RecordUpsert) saveRecord(dao *daos.Dao) error {
+	if form.version == "" || form.record.IsNew() {
+		return dao.SaveRecord(form.record)
+	}
+
+	expected, err := types.ParseDateTime(form.version)
+	if err != nil || expected.IsZero() {
+		return validation.Errors{
+			RecordVersionKey: validation.NewError("validation_invalid_record_version", "Invalid record version."),
+		}
+	}
+
+	return dao.RunInTransaction(func(txDao *daos.Dao) error {
+		var current types.DateTime
+
+		err := txDao.RecordQuery(form.record.Collection()).
+			Select(form.record.TableName() + "." + schema.FieldNameUpdated).
+			AndWhere(dbx.HashExp{form.record.TableName() + ".id": form.record.Id}).
+			Limit(1).
+			Row(&current)
+		if err != nil {
+			return err
+		}
+
+		if !current.Time().Equal(expected.Time()) {
+			return ErrRecordVersionConflict
+		}
+
+		return txDao.SaveRecord(form.record)
+	})
+}
+
 func (form *RecordUpsert) processFilesToUpload() error {
 	if len(form.filesToUpload) == 0 {
 		return nil // no parsed file fields
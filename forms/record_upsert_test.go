@@ -10,7 +10,9 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+	"sync"
 	"testing"
+	"time"
 
 	validation "github.com/go-ozzo/ozzo-validation/v4"
 	"github.com/labstack/echo/v5"
@@ -22,6 +24,7 @@ import (
 	"github.com/unkod/space/tests"
 	"github.com/unkod/space/tools/filesystem"
 	"github.com/unkod/space/tools/list"
+	"github.com/unkod/space/tools/rest"
 	"github.com/unkod/space/tools/types"
 )
 
@@ -203,6 +206,80 @@ func TestRecordUpsertLoadRequestMultipart(t *testing.T) {
 	}
 }
 
+func TestRecordUpsertLoadRequestMergePatch(t *testing.T) {
+	scenarios := []struct {
+		name         string
+		contentType  string
+		expectedJson string
+	}{
+		{
+			"json (replace)",
+			echo.MIMEApplicationJSON,
+			`{"b":{"c":null,"e":4},"f":5}`,
+		},
+		{
+			"json merge patch",
+			rest.MIMEApplicationMergePatchJSON,
+			`{"a":1,"b":{"d":3,"e":4},"f":5}`,
+		},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			app, _ := tests.NewTestApp()
+			defer app.Cleanup()
+
+			record, err := app.Dao().FindRecordById("demo1", "84nmscqy84lsi1t")
+			if err != nil {
+				t.Fatal(err)
+			}
+			record.Set("json", map[string]any{"a": 1, "b": map[string]any{"c": 2, "d": 3}})
+
+			form := forms.NewRecordUpsert(app, record)
+
+			body := `{
+				"text": null,
+				"rel_many": null,
+				"file_one": null,
+				"json": {"b": {"c": null, "e": 4}, "f": 5}
+			}`
+			req := httptest.NewRequest(http.MethodPatch, "/", strings.NewReader(body))
+			req.Header.Set(echo.HeaderContentType, s.contentType)
+			if err := form.LoadRequest(req, ""); err != nil {
+				t.Fatal(err)
+			}
+
+			data := form.Data()
+
+			// explicit null values
+			if v := data["text"]; v != "" {
+				t.Fatalf("Expected text to be cleared, got %v", v)
+			}
+			if v := list.ToUniqueStringSlice(data["rel_many"]); len(v) != 0 {
+				t.Fatalf("Expected rel_many to be cleared, got %v", v)
+			}
+			if v := data["file_one"]; v != "" {
+				t.Fatalf("Expected file_one to be cleared, got %v", v)
+			}
+			if v := form.FilesToDelete(); len(v) != 1 || v[0] != "test_d61b33QdDU.txt" {
+				t.Fatalf("Expected the old file_one file to be marked for deletion, got %v", v)
+			}
+
+			// omitted values
+			if v := data["number"]; v != record.Get("number") {
+				t.Fatalf("Expected number to remain unchanged, got %v", v)
+			}
+			if v := list.ToUniqueStringSlice(data["file_many"]); len(v) != 5 {
+				t.Fatalf("Expected file_many to remain unchanged, got %v", v)
+			}
+
+			if v := fmt.Sprint(data["json"]); v != s.expectedJson {
+				t.Fatalf("Expected json %s, got %s", s.expectedJson, v)
+			}
+		})
+	}
+}
+
 func TestRecordUpsertLoadData(t *testing.T) {
 	app, _ := tests.NewTestApp()
 	defer app.Cleanup()
@@ -231,6 +308,59 @@ func TestRecordUpsertLoadData(t *testing.T) {
 	}
 }
 
+func TestRecordUpsertChangedFields(t *testing.T) {
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	record, err := app.Dao().FindRecordById("demo2", "llvuca81nly1qls")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	form := forms.NewRecordUpsert(app, record)
+
+	if changed := form.ChangedFields(); len(changed) != 0 {
+		t.Fatalf("Expected no changed fields, got %v", changed)
+	}
+
+	loadErr := form.LoadData(map[string]any{
+		"title":  record.GetString("title"),
+		"active": !record.GetBool("active"),
+	})
+	if loadErr != nil {
+		t.Fatal(loadErr)
+	}
+
+	changed := form.ChangedFields()
+	if len(changed) != 1 || changed[0] != "active" {
+		t.Fatalf("Expected only the active field to be changed, got %v", changed)
+	}
+
+	// the default values of a new record shouldn't be considered changed
+	collection := &models.Collection{
+		Name: "changed_defaults",
+		Type: models.CollectionTypeBase,
+		Schema: schema.NewSchema(
+			&schema.SchemaField{Name: "title", Type: schema.FieldTypeText},
+			&schema.SchemaField{Name: "code", Type: schema.FieldTypeText, Default: "'abc'"},
+			&schema.SchemaField{Name: "total", Type: schema.FieldTypeNumber},
+		),
+	}
+	if err := app.Dao().SaveCollection(collection); err != nil {
+		t.Fatal(err)
+	}
+
+	newForm := forms.NewRecordUpsert(app, models.NewRecord(collection))
+	if err := newForm.LoadData(map[string]any{"title": "test", "total": 0}); err != nil {
+		t.Fatal(err)
+	}
+
+	changed = newForm.ChangedFields()
+	if len(changed) != 1 || changed[0] != "title" {
+		t.Fatalf("Expected only the title field to be changed, got %v", changed)
+	}
+}
+
 func TestRecordUpsertDrySubmitFailure(t *testing.T) {
 	app, _ := tests.NewTestApp()
 	defer app.Cleanup()
@@ -553,6 +683,291 @@ func TestRecordUpsertSubmitSuccess(t *testing.T) {
 	}
 }
 
+func TestRecordUpsertSubmitDryRun(t *testing.T) {
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	collection, _ := app.Dao().FindCollectionByNameOrId("demo1")
+	recordBefore, err := app.Dao().FindRecordById(collection.Id, "84nmscqy84lsi1t")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// update
+	// ---
+	formData, mp, err := tests.MockMultipartData(map[string]string{
+		"text":     "test_dry_run",
+		"file_one": "",
+	}, "file_many")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	form := forms.NewRecordUpsert(app, recordBefore)
+	form.SetDryRun(true)
+	req := httptest.NewRequest(http.MethodGet, "/", formData)
+	req.Header.Set(echo.HeaderContentType, mp.FormDataContentType())
+	form.LoadRequest(req, "")
+
+	app.ResetEventCalls()
+
+	if err := form.Submit(); err != nil {
+		t.Fatalf("Expected nil, got error %v", err)
+	}
+
+	if v := recordBefore.GetString("text"); v != "test_dry_run" {
+		t.Fatalf("Expected the would-be record.text to be %q, got %q", "test_dry_run", v)
+	}
+
+	if app.EventCalls["OnModelBeforeUpdate"] != 1 {
+		t.Fatalf("Expected OnModelBeforeUpdate to be called once, got %d", app.EventCalls["OnModelBeforeUpdate"])
+	}
+
+	if app.EventCalls["OnModelAfterUpdate"] != 0 {
+		t.Fatalf("Expected OnModelAfterUpdate to not be called, got %d", app.EventCalls["OnModelAfterUpdate"])
+	}
+
+	recordAfter, err := app.Dao().FindRecordById(collection.Id, recordBefore.Id)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if v := recordAfter.GetString("text"); v == "test_dry_run" {
+		t.Fatal("Expected the record.text change to not be persisted")
+	}
+
+	if !hasRecordFile(app, recordAfter, recordAfter.GetString("file_one")) {
+		t.Fatal("Expected record.file_one to not be deleted")
+	}
+
+	for _, f := range recordBefore.GetStringSlice("file_many") {
+		if !list.ExistInSlice(f, recordAfter.GetStringSlice("file_many")) && hasRecordFile(app, recordAfter, f) {
+			t.Fatalf("Expected the new file %q to not be uploaded", f)
+		}
+	}
+
+	// create (with validation error)
+	// ---
+	failForm := forms.NewRecordUpsert(app, models.NewRecord(collection))
+	failForm.SetDryRun(true)
+	failForm.LoadData(map[string]any{"email": "invalid"})
+	if err := failForm.Submit(); err == nil {
+		t.Fatal("Expected validation error, got nil")
+	}
+
+	// create
+	// ---
+	totalBefore := countCollectionRecords(t, app, collection)
+
+	newRecord := models.NewRecord(collection)
+	createForm := forms.NewRecordUpsert(app, newRecord)
+	createForm.SetDryRun(true)
+	createForm.LoadData(map[string]any{"text": "test_dry_run"})
+	if err := createForm.Submit(); err != nil {
+		t.Fatalf("Expected nil, got error %v", err)
+	}
+
+	if !newRecord.IsNew() {
+		t.Fatal("Expected the dry run record to be still marked as new")
+	}
+
+	if newRecord.Id == "" {
+		t.Fatal("Expected the dry run record to have a would-be id")
+	}
+
+	if totalAfter := countCollectionRecords(t, app, collection); totalAfter != totalBefore {
+		t.Fatalf("Expected %d records, got %d", totalBefore, totalAfter)
+	}
+}
+
+func countCollectionRecords(t *testing.T, app *tests.TestApp, collection *models.Collection) int {
+	var total int
+
+	err := app.Dao().RecordQuery(collection).Select("count(*)").Row(&total)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return total
+}
+
+func TestRecordUpsertSubmitVersion(t *testing.T) {
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	// 2022-10-14 10:52:46.726Z
+	const recordId = "achvryl401bhse3"
+
+	scenarios := []struct {
+		name           string
+		data           map[string]any
+		version        string
+		expectConflict bool
+		expectError    bool
+	}{
+		{"no version", map[string]any{"title": "test_no_version"}, "", false, false},
+		{"invalid version", map[string]any{"title": "test_invalid"}, "invalid", false, true},
+		{"stale version", map[string]any{"title": "test_stale"}, "2022-10-14 10:52:46.725Z", true, true},
+		{"stale @version data field", map[string]any{"title": "test_stale", forms.RecordVersionKey: "2022-10-14 10:52:46.725Z"}, "", true, true},
+		{"current version", map[string]any{"title": "test_current"}, "current", false, false},
+		{"current @version data field", map[string]any{"title": "test_current", forms.RecordVersionKey: "current"}, "", false, false},
+	}
+
+	for _, s := range scenarios {
+		record, err := app.Dao().FindRecordById("demo2", recordId)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if v, ok := s.data[forms.RecordVersionKey]; ok && v == "current" {
+			s.data[forms.RecordVersionKey] = record.Updated.String()
+		}
+
+		form := forms.NewRecordUpsert(app, record)
+		if err := form.LoadData(s.data); err != nil {
+			t.Fatal(err)
+		}
+		if s.version == "current" {
+			form.SetVersion(record.Updated.String())
+		} else if s.version != "" {
+			form.SetVersion(s.version)
+		}
+
+		err = form.Submit()
+
+		isConflict := errors.Is(err, forms.ErrRecordVersionConflict)
+		if isConflict != s.expectConflict {
+			t.Fatalf("[%s] Expected conflict %v, got %v (%v)", s.name, s.expectConflict, isConflict, err)
+		}
+
+		hasErr := err != nil
+		if hasErr != s.expectError {
+			t.Fatalf("[%s] Expected hasErr %v, got %v (%v)", s.name, s.expectError, hasErr, err)
+		}
+
+		stored, err := app.Dao().FindRecordById("demo2", recordId)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if s.expectError && stored.GetString("title") == s.data["title"] {
+			t.Fatalf("[%s] Expected the record to not be updated", s.name)
+		}
+
+		if !s.expectError && stored.GetString("title") != s.data["title"] {
+			t.Fatalf("[%s] Expected title %q, got %q", s.name, s.data["title"], stored.GetString("title"))
+		}
+	}
+}
+
+func TestRecordUpsertSubmitConcurrentVersion(t *testing.T) {
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	// simulate 2 clients that have loaded the same record version
+	upserts := []*forms.RecordUpsert{}
+	for _, title := range []string{"client1", "client2"} {
+		record, err := app.Dao().FindRecordById("demo2", "achvryl401bhse3")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		form := forms.NewRecordUpsert(app, record)
+		form.SetVersion(record.Updated.String())
+		if err := form.LoadData(map[string]any{"title": title}); err != nil {
+			t.Fatal(err)
+		}
+
+		upserts = append(upserts, form)
+	}
+
+	errs := make([]error, len(upserts))
+
+	var wg sync.WaitGroup
+	for i, form := range upserts {
+		wg.Add(1)
+		go func(i int, form *forms.RecordUpsert) {
+			defer wg.Done()
+			errs[i] = form.Submit()
+		}(i, form)
+	}
+	wg.Wait()
+
+	var succeeded, conflicts int
+	for _, err := range errs {
+		switch {
+		case err == nil:
+			succeeded++
+		case errors.Is(err, forms.ErrRecordVersionConflict):
+			conflicts++
+		default:
+			t.Fatalf("Unexpected error %v", err)
+		}
+	}
+
+	if succeeded != 1 || conflicts != 1 {
+		t.Fatalf("Expected 1 successful update and 1 conflict, got %d and %d (%v)", succeeded, conflicts, errs)
+	}
+}
+
+func TestRecordUpsertSubmitFileModifiers(t *testing.T) {
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	collection, _ := app.Dao().FindCollectionByNameOrId("demo1")
+	recordBefore, err := app.Dao().FindRecordById(collection.Id, "84nmscqy84lsi1t")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	removed := "test_MaWC6mWyrP.txt"
+	untouched := list.SubtractSlice(recordBefore.GetStringSlice("file_many"), []string{removed})
+
+	formData, mp, err := tests.MockMultipartData(map[string]string{
+		"file_many-": removed,
+	}, "file_many+", "file_many+")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	form := forms.NewRecordUpsert(app, recordBefore)
+	req := httptest.NewRequest(http.MethodPatch, "/", formData)
+	req.Header.Set(echo.HeaderContentType, mp.FormDataContentType())
+	if err := form.LoadRequest(req, ""); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := form.Submit(); err != nil {
+		t.Fatalf("Expected nil, got error %v", err)
+	}
+
+	recordAfter, err := app.Dao().FindRecordById(collection.Id, recordBefore.Id)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fileMany := recordAfter.GetStringSlice("file_many")
+	if len(fileMany) != len(untouched)+2 {
+		t.Fatalf("Expected %d record.file_many, got %d (%v)", len(untouched)+2, len(fileMany), fileMany)
+	}
+
+	if list.ExistInSlice(removed, fileMany) || hasRecordFile(app, recordAfter, removed) {
+		t.Fatalf("Expected file %q to be deleted", removed)
+	}
+
+	for _, f := range untouched {
+		if !list.ExistInSlice(f, fileMany) {
+			t.Fatalf("Expected file %q to remain in %v", f, fileMany)
+		}
+	}
+
+	for _, f := range fileMany {
+		if !hasRecordFile(app, recordAfter, f) {
+			t.Fatalf("Expected file %q to exist", f)
+		}
+	}
+}
+
 func TestRecordUpsertSubmitInterceptors(t *testing.T) {
 	app, _ := tests.NewTestApp()
 	defer app.Cleanup()
@@ -714,6 +1129,338 @@ func TestRecordUpsertWithCustomId(t *testing.T) {
 	}
 }
 
+func TestRecordUpsertWithCustomIdOptions(t *testing.T) {
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	collection := &models.Collection{
+		Name:   "custom_ids",
+		Type:   models.CollectionTypeBase,
+		Schema: schema.NewSchema(&schema.SchemaField{Name: "title", Type: schema.FieldTypeText}),
+	}
+	collection.SetOptions(models.CollectionBaseOptions{
+		IdPattern:     `^[a-z0-9_-]+$`,
+		DisableAutoId: true,
+	})
+	if err := app.Dao().SaveCollection(collection); err != nil {
+		t.Fatal(err)
+	}
+
+	relCollection := &models.Collection{
+		Name: "custom_ids_rel",
+		Type: models.CollectionTypeBase,
+		Schema: schema.NewSchema(&schema.SchemaField{
+			Name:    "rel",
+			Type:    schema.FieldTypeRelation,
+			Options: &schema.RelationOptions{CollectionId: collection.Id, MaxSelect: types.Pointer(1)},
+		}),
+	}
+	if err := app.Dao().SaveCollection(relCollection); err != nil {
+		t.Fatal(err)
+	}
+
+	scenarios := []struct {
+		name        string
+		data        map[string]any
+		expectError bool
+	}{
+		{"missing id", map[string]any{"title": "test"}, true},
+		{"id not matching the pattern", map[string]any{"id": "ABC"}, true},
+		{"id matching the pattern but with invalid chars", map[string]any{"id": "a b"}, true},
+		{"too long id", map[string]any{"id": strings.Repeat("a", models.MaxCustomIdLength+1)}, true},
+		{"short id matching the pattern", map[string]any{"id": "sku-1"}, false},
+		{"duplicated id", map[string]any{"id": "sku-1"}, true},
+		{"max length id", map[string]any{"id": strings.Repeat("a", models.MaxCustomIdLength)}, false},
+	}
+
+	for _, s := range scenarios {
+		record := models.NewRecord(collection)
+
+		form := forms.NewRecordUpsert(app, record)
+		form.LoadData(s.data)
+
+		err := form.Submit()
+		hasErr := err != nil
+
+		if hasErr != s.expectError {
+			t.Errorf("[%s] Expected hasErr to be %v, got %v (%v)", s.name, s.expectError, hasErr, err)
+			continue
+		}
+
+		if !hasErr {
+			if _, err := app.Dao().FindRecordById(collection.Id, s.data["id"].(string)); err != nil {
+				t.Errorf("[%s] Expected to find record with id %s, got %v", s.name, s.data["id"], err)
+			}
+		}
+	}
+
+	// relation to a custom id record
+	relRecord := models.NewRecord(relCollection)
+	relForm := forms.NewRecordUpsert(app, relRecord)
+	relForm.LoadData(map[string]any{"rel": "sku-1"})
+	if err := relForm.Submit(); err != nil {
+		t.Fatalf("Expected the relation to the custom id record to be valid, got %v", err)
+	}
+
+	relForm = forms.NewRecordUpsert(app, models.NewRecord(relCollection))
+	relForm.LoadData(map[string]any{"rel": "sku-missing"})
+	if err := relForm.Submit(); err == nil {
+		t.Fatal("Expected the relation to a missing custom id record to fail")
+	}
+}
+
+func TestRecordUpsertFieldDefaults(t *testing.T) {
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	collection := &models.Collection{
+		Name: "defaults",
+		Type: models.CollectionTypeBase,
+		Schema: schema.NewSchema(
+			&schema.SchemaField{Name: "title", Type: schema.FieldTypeText},
+			&schema.SchemaField{
+				Name:    "author",
+				Type:    schema.FieldTypeRelation,
+				Options: &schema.RelationOptions{CollectionId: "_pb_users_auth_", MaxSelect: types.Pointer(1)},
+				Default: "@request.auth.id",
+			},
+			&schema.SchemaField{Name: "author_email", Type: schema.FieldTypeText, Default: "@request.auth.email"},
+			&schema.SchemaField{Name: "published", Type: schema.FieldTypeDate, Default: "now()"},
+			&schema.SchemaField{Name: "code", Type: schema.FieldTypeText, Default: "'c_' + uuid()"},
+			&schema.SchemaField{Name: "label", Type: schema.FieldTypeText, Default: "@request.data.title + ' (' + @request.method + ')'"},
+			&schema.SchemaField{Name: "total", Type: schema.FieldTypeNumber, Default: "10 * 2"},
+		),
+	}
+	if err := app.Dao().SaveCollection(collection); err != nil {
+		t.Fatal(err)
+	}
+
+	user, err := app.Dao().FindAuthRecordByEmail("users", "test@example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	requestInfo := &models.RequestInfo{Method: "POST", AuthRecord: user}
+
+	// create with omitted fields
+	// ---
+	before := types.NowDateTime()
+
+	record := models.NewRecord(collection)
+	form := forms.NewRecordUpsert(app, record)
+	form.SetRequestInfo(requestInfo)
+	if err := form.LoadData(map[string]any{"title": "test"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := form.Submit(); err != nil {
+		t.Fatal(err)
+	}
+
+	if v := record.GetString("author"); v != user.Id {
+		t.Fatalf("Expected author %q, got %q", user.Id, v)
+	}
+
+	if v := record.GetString("author_email"); v != user.Email() {
+		t.Fatalf("Expected author_email %q, got %q", user.Email(), v)
+	}
+
+	// the stored datetime has only ms precision
+	if v := record.GetDateTime("published"); v.Time().Before(before.Time().Truncate(time.Millisecond)) {
+		t.Fatalf("Expected published to be the create time, got %v", v)
+	}
+
+	if v := record.GetString("code"); !strings.HasPrefix(v, "c_") || len(v) != 38 {
+		t.Fatalf("Expected code to be prefixed uuid, got %q", v)
+	}
+
+	if v := record.GetString("label"); v != "test (POST)" {
+		t.Fatalf("Expected label %q, got %q", "test (POST)", v)
+	}
+
+	if v := record.GetInt("total"); v != 20 {
+		t.Fatalf("Expected total 20, got %d", v)
+	}
+
+	// create with explicitly submitted fields and without request info
+	// ---
+	record2 := models.NewRecord(collection)
+	form2 := forms.NewRecordUpsert(app, record2)
+	if err := form2.LoadData(map[string]any{"title": "test2", "code": "", "total": 1}); err != nil {
+		t.Fatal(err)
+	}
+	if err := form2.Submit(); err != nil {
+		t.Fatal(err)
+	}
+
+	if v := record2.GetString("author"); v != "" {
+		t.Fatalf("Expected empty author, got %q", v)
+	}
+
+	if v := record2.GetString("code"); v != "" {
+		t.Fatalf("Expected the submitted empty code, got %q", v)
+	}
+
+	if v := record2.GetInt("total"); v != 1 {
+		t.Fatalf("Expected the submitted total 1, got %d", v)
+	}
+
+	if v := record2.GetDateTime("published"); v.IsZero() {
+		t.Fatal("Expected published to be set")
+	}
+
+	// update doesn't apply the defaults
+	// ---
+	record2.Set("published", "")
+	if err := app.Dao().SaveRecord(record2); err != nil {
+		t.Fatal(err)
+	}
+
+	form3 := forms.NewRecordUpsert(app, record2)
+	form3.SetRequestInfo(requestInfo)
+	if err := form3.LoadData(map[string]any{"title": "test3"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := form3.Submit(); err != nil {
+		t.Fatal(err)
+	}
+
+	if v := record2.GetString("author"); v != "" {
+		t.Fatalf("Expected the author to remain empty on update, got %q", v)
+	}
+
+	if v := record2.GetDateTime("published"); !v.IsZero() {
+		t.Fatalf("Expected published to remain empty on update, got %v", v)
+	}
+}
+
+func TestRecordUpsertWithJsonSchema(t *testing.T) {
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	collection := &models.Collection{
+		Name: "json_schema",
+		Type: models.CollectionTypeBase,
+		Schema: schema.NewSchema(
+			&schema.SchemaField{Name: "type", Type: schema.FieldTypeText},
+			&schema.SchemaField{Name: "code", Type: schema.FieldTypeText},
+			&schema.SchemaField{Name: "website", Type: schema.FieldTypeText},
+			&schema.SchemaField{Name: "vat", Type: schema.FieldTypeText},
+			&schema.SchemaField{Name: "meta", Type: schema.FieldTypeJson, Options: &schema.JsonOptions{}},
+		),
+	}
+	collection.SetOptions(models.CollectionBaseOptions{
+		JsonSchema: types.JsonRaw(`{
+			"properties": {
+				"code": {"pattern": "^[A-Z]{3}-[0-9]+$"},
+				"website": {"format": "uri"},
+				"meta": {
+					"type": "object",
+					"properties": {"contact": {"format": "email"}}
+				}
+			},
+			"if": {"properties": {"type": {"const": "company"}}, "required": ["type"]},
+			"then": {"required": ["vat"]}
+		}`),
+	})
+	if err := app.Dao().SaveCollection(collection); err != nil {
+		t.Fatal(err)
+	}
+
+	scenarios := []struct {
+		name           string
+		data           map[string]any
+		expectedErrors string
+	}{
+		{
+			"empty data",
+			map[string]any{},
+			``,
+		},
+		{
+			"invalid pattern and formats",
+			map[string]any{
+				"code":    "abc-1",
+				"website": "example.com",
+				"meta":    map[string]any{"contact": "invalid"},
+			},
+			`{"code":"Must match the required pattern.","meta":{"contact":"Must be a valid email."},"website":"Must be a valid uri."}`,
+		},
+		{
+			"invalid nested type",
+			map[string]any{"meta": []int{1, 2}},
+			`{"meta":"Must be of type object."}`,
+		},
+		{
+			"conditional required (blank value)",
+			map[string]any{"type": "company", "vat": ""},
+			`{"vat":"Missing required value."}`,
+		},
+		{
+			"valid data",
+			map[string]any{
+				"type":    "company",
+				"vat":     "123",
+				"code":    "ABC-1",
+				"website": "https://example.com",
+				"meta":    map[string]any{"contact": "test@example.com"},
+			},
+			``,
+		},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			form := forms.NewRecordUpsert(app, models.NewRecord(collection))
+			form.LoadData(s.data)
+
+			err := form.Validate()
+
+			if s.expectedErrors == "" {
+				if err != nil {
+					t.Fatalf("Expected no errors, got %v", err)
+				}
+				return
+			}
+
+			errs, ok := err.(validation.Errors)
+			if !ok {
+				t.Fatalf("Expected validation.Errors, got %v", err)
+			}
+
+			raw, _ := json.Marshal(errs)
+			if string(raw) != s.expectedErrors {
+				t.Fatalf("Expected errors\n%s\ngot\n%s", s.expectedErrors, raw)
+			}
+
+			for name, fieldErr := range errs {
+				if vErr, ok := fieldErr.(validation.Error); ok && !strings.HasPrefix(vErr.Code(), "validation_json_schema_") {
+					t.Fatalf("Expected %q error code with validation_json_schema_ prefix, got %q", name, vErr.Code())
+				}
+			}
+		})
+	}
+
+	// update of an existing record
+	record := models.NewRecord(collection)
+	record.Set("type", "company")
+	record.Set("vat", "123")
+	if err := app.Dao().SaveRecord(record); err != nil {
+		t.Fatal(err)
+	}
+
+	form := forms.NewRecordUpsert(app, record)
+	form.LoadData(map[string]any{"vat": nil})
+	if err := form.Submit(); err == nil {
+		t.Fatal("Expected the removal of the conditionally required vat to fail")
+	}
+
+	form = forms.NewRecordUpsert(app, record)
+	form.LoadData(map[string]any{"type": "person", "vat": nil})
+	if err := form.Submit(); err != nil {
+		t.Fatalf("Expected the record update to succeed, got %v", err)
+	}
+}
+
 func TestRecordUpsertAuthRecord(t *testing.T) {
 	app, _ := tests.NewTestApp()
 	defer app.Cleanup()
@@ -948,6 +1695,133 @@ func TestRecordUpsertAuthRecord(t *testing.T) {
 	}
 }
 
+func TestRecordUpsertAuthRecordPasswordPolicy(t *testing.T) {
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	collection, err := app.Dao().FindCollectionByNameOrId("users")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	options := collection.AuthOptions()
+	options.PasswordPolicy = &models.CollectionPasswordPolicy{
+		RequireSymbol:    true,
+		DisallowIdentity: true,
+		DisallowCommon:   true,
+	}
+	collection.SetOptions(options)
+	if err := app.Dao().SaveCollection(collection); err != nil {
+		t.Fatal(err)
+	}
+
+	scenarios := []struct {
+		name          string
+		existingId    string
+		data          map[string]any
+		expectedError string
+	}{
+		{
+			"create with missing symbol",
+			"",
+			map[string]any{
+				"password":        "abcdefgh1",
+				"passwordConfirm": "abcdefgh1",
+			},
+			"validation_password_missing_symbol",
+		},
+		{
+			"create with common password",
+			"",
+			map[string]any{
+				"password":        "p@ssw0rd",
+				"passwordConfirm": "p@ssw0rd",
+			},
+			"validation_password_common",
+		},
+		{
+			"create with password matching the new username",
+			"",
+			map[string]any{
+				"username":        "test_new1",
+				"password":        "test_new1",
+				"passwordConfirm": "test_new1",
+			},
+			"validation_password_identity",
+		},
+		{
+			"create with valid password",
+			"",
+			map[string]any{
+				"username":        "test_new1",
+				"password":        "abc123!@#",
+				"passwordConfirm": "abc123!@#",
+			},
+			"",
+		},
+		{
+			"update with password containing the existing email",
+			"4q1xlclmfloku33",
+			map[string]any{
+				"password":        "test@example.com1",
+				"passwordConfirm": "test@example.com1",
+			},
+			"",
+		},
+		{
+			"update with password matching the existing email",
+			"4q1xlclmfloku33",
+			map[string]any{
+				"password":        "Test@example.com",
+				"passwordConfirm": "Test@example.com",
+			},
+			"validation_password_identity",
+		},
+		{
+			"update without password change",
+			"4q1xlclmfloku33",
+			map[string]any{
+				"emailVisibility": true,
+			},
+			"",
+		},
+	}
+
+	for _, s := range scenarios {
+		record := models.NewRecord(collection)
+		if s.existingId != "" {
+			var err error
+			record, err = app.Dao().FindRecordById(collection.Id, s.existingId)
+			if err != nil {
+				t.Errorf("[%s] Failed to fetch auth record with id %s", s.name, s.existingId)
+				continue
+			}
+		}
+
+		form := forms.NewRecordUpsert(app, record)
+		form.SetFullManageAccess(true)
+		if err := form.LoadData(s.data); err != nil {
+			t.Errorf("[%s] Failed to load form data", s.name)
+			continue
+		}
+
+		submitErr := form.Validate()
+
+		if s.expectedError == "" {
+			if submitErr != nil {
+				t.Errorf("[%s] Expected no error, got %v", s.name, submitErr)
+			}
+			continue
+		}
+
+		errs, _ := submitErr.(validation.Errors)
+		passwordErr, _ := errs["password"].(validation.Error)
+		if passwordErr == nil || passwordErr.Code() != s.expectedError {
+			t.Errorf("[%s] Expected password error %q, got %v", s.name, s.expectedError, submitErr)
+		}
+	}
+}
+
 func TestRecordUpsertUniqueValidator(t *testing.T) {
 	app, _ := tests.NewTestApp()
 	defer app.Cleanup()
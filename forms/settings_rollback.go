@@ -0,0 +1,70 @@
+package forms
+
+import (
+	"errors"
+	"os"
+
+	"github.com/unkod/space/core"
+	"github.com/unkod/space/daos"
+	"github.com/unkod/space/models"
+	"github.com/unkod/space/models/settings"
+)
+
+// SettingsRollback is a form that restores the app settings
+// to their state before the specified settings version change.
+type SettingsRollback struct {
+	app     core.App
+	dao     *daos.Dao
+	admin   *models.Admin
+	version *models.SettingsVersion
+}
+
+// NewSettingsRollback creates a new [SettingsRollback] form initialized with
+// the provided [core.App] instance and the settings version to revert.
+//
+// If you want to submit the form as part of a transaction,
+// you can change the default Dao via [SetDao()].
+func NewSettingsRollback(app core.App, version *models.SettingsVersion) *SettingsRollback {
+	return &SettingsRollback{
+		app:     app,
+		dao:     app.Dao(),
+		version: version,
+	}
+}
+
+// SetDao replaces the default form Dao instance with the provided one.
+func (form *SettingsRollback) SetDao(dao *daos.Dao) {
+	form.dao = dao
+}
+
+// SetAdmin sets the admin that is stored as author
+// of the rollback settings history version.
+func (form *SettingsRollback) SetAdmin(admin *models.Admin) {
+	form.admin = admin
+}
+
+// Submit decodes and validates the settings version snapshot
+// and upserts it as the new app settings.
+//
+// The rollback is stored as a new settings history version.
+//
+// You can optionally provide a list of InterceptorFunc to further
+// modify the form behavior before persisting it.
+func (form *SettingsRollback) Submit(interceptors ...InterceptorFunc[*settings.Settings]) error {
+	if form.version == nil || form.version.IsNew() {
+		return errors.New("missing settings version to rollback")
+	}
+
+	restored, err := form.dao.DecodeSettingsVersion(form.version, os.Getenv(form.app.EncryptionEnv()))
+	if err != nil {
+		return err
+	}
+
+	upsert := NewSettingsUpsert(form.app)
+	upsert.SetDao(form.dao)
+	upsert.SetAdmin(form.admin)
+	upsert.Settings = restored
+	upsert.rollbackOf = form.version.Id
+
+	return upsert.Submit(interceptors...)
+}
@@ -0,0 +1,85 @@
+package forms_test
+
+import (
+	"os"
+	"testing"
+
+	"github.com/unkod/space/forms"
+	"github.com/unkod/space/models"
+	"github.com/unkod/space/tests"
+	"github.com/unkod/space/tools/security"
+)
+
+func TestSettingsRollbackSubmit(t *testing.T) {
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	os.Setenv(app.EncryptionEnv(), security.RandomString(32))
+	defer os.Unsetenv(app.EncryptionEnv())
+
+	admin, err := app.Dao().FindAdminByEmail("test@example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	originalName := app.Settings().Meta.AppName
+
+	// change the settings to create a version
+	upsert := forms.NewSettingsUpsert(app)
+	upsert.SetAdmin(admin)
+	upsert.Meta.AppName = "rollback_test"
+	upsert.Smtp.Password = "secret_test"
+	if err := upsert.Submit(); err != nil {
+		t.Fatal(err)
+	}
+
+	versions := []*models.SettingsVersion{}
+	if err := app.Dao().SettingsVersionQuery().OrderBy("created ASC").All(&versions); err != nil {
+		t.Fatal(err)
+	}
+	if len(versions) != 1 {
+		t.Fatalf("Expected 1 version, got %d", len(versions))
+	}
+	if versions[0].AdminId != admin.Id {
+		t.Fatalf("Expected version admin %q, got %q", admin.Id, versions[0].AdminId)
+	}
+	if versions[0].Changes.Get("smtp.password") == nil {
+		t.Fatalf("Expected smtp.password change, got %v", versions[0].Changes)
+	}
+
+	// resubmitting the same settings shouldn't create a new version
+	if err := forms.NewSettingsUpsert(app).Submit(); err != nil {
+		t.Fatal(err)
+	}
+
+	// missing version
+	if err := forms.NewSettingsRollback(app, &models.SettingsVersion{}).Submit(); err == nil {
+		t.Fatal("Expected missing version error")
+	}
+
+	rollback := forms.NewSettingsRollback(app, versions[0])
+	if err := rollback.Submit(); err != nil {
+		t.Fatal(err)
+	}
+
+	if app.Settings().Meta.AppName != originalName {
+		t.Fatalf("Expected app name %q, got %q", originalName, app.Settings().Meta.AppName)
+	}
+	if app.Settings().Smtp.Password != "" {
+		t.Fatalf("Expected empty smtp password, got %q", app.Settings().Smtp.Password)
+	}
+
+	versions = []*models.SettingsVersion{}
+	if err := app.Dao().SettingsVersionQuery().OrderBy("created ASC").All(&versions); err != nil {
+		t.Fatal(err)
+	}
+	if len(versions) != 2 {
+		t.Fatalf("Expected 2 versions, got %d", len(versions))
+	}
+	if versions[1].RollbackOf != versions[0].Id {
+		t.Fatalf("Expected rollbackOf %q, got %q", versions[0].Id, versions[1].RollbackOf)
+	}
+	if versions[1].AdminId != "" {
+		t.Fatalf("Expected empty admin id, got %q", versions[1].AdminId)
+	}
+}
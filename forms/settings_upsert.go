@@ -6,15 +6,19 @@ import (
 
 	"github.com/unkod/space/core"
 	"github.com/unkod/space/daos"
+	"github.com/unkod/space/models"
 	"github.com/unkod/space/models/settings"
+	"github.com/unkod/space/tools/types"
 )
 
 // SettingsUpsert is a [settings.Settings] upsert (create/update) form.
 type SettingsUpsert struct {
 	*settings.Settings
 
-	app core.App
-	dao *daos.Dao
+	app        core.App
+	dao        *daos.Dao
+	admin      *models.Admin
+	rollbackOf string
 }
 
 // NewSettingsUpsert creates a new [SettingsUpsert] form with initializer
@@ -39,6 +43,12 @@ func (form *SettingsUpsert) SetDao(dao *daos.Dao) {
 	form.dao = dao
 }
 
+// SetAdmin sets the admin that is stored as author
+// of the submitted settings history version.
+func (form *SettingsUpsert) SetAdmin(admin *models.Admin) {
+	form.admin = admin
+}
+
 // Validate makes the form validatable by implementing [validation.Validatable] interface.
 func (form *SettingsUpsert) Validate() error {
 	return form.Settings.Validate()
@@ -51,6 +61,9 @@ func (form *SettingsUpsert) Validate() error {
 // You can optionally provide a list of InterceptorFunc to further
 // modify the form behavior before persisting it.
 func (form *SettingsUpsert) Submit(interceptors ...InterceptorFunc[*settings.Settings]) error {
+	// keep the existing secrets of the submitted redacted webhooks
+	form.Settings.Webhooks.RestoreSecrets(form.app.Settings().Webhooks)
+
 	if err := form.Validate(); err != nil {
 		return err
 	}
@@ -68,13 +81,40 @@ func (form *SettingsUpsert) Submit(interceptors ...InterceptorFunc[*settings.Set
 			return err
 		}
 
-		// persists settings change
+		changes, err := oldSettings.RedactedDiff(form.Settings)
+		if err != nil {
+			return err
+		}
+
+		// persists settings change (and its history version)
 		encryptionKey := os.Getenv(form.app.EncryptionEnv())
-		if err := form.dao.SaveSettings(form.Settings, encryptionKey); err != nil {
+		saveErr := form.dao.RunInTransaction(func(txDao *daos.Dao) error {
+			if err := txDao.SaveSettings(form.Settings, encryptionKey); err != nil {
+				return err
+			}
+
+			if len(changes) == 0 {
+				return nil // nothing has changed
+			}
+
+			version := &models.SettingsVersion{
+				RollbackOf: form.rollbackOf,
+				Changes:    make(types.JsonMap, len(changes)),
+			}
+			if form.admin != nil {
+				version.AdminId = form.admin.Id
+			}
+			for path, change := range changes {
+				version.Changes[path] = change
+			}
+
+			return txDao.SaveSettingsVersion(version, oldSettings, encryptionKey)
+		})
+		if saveErr != nil {
 			// try to revert app settings
 			form.app.Settings().Merge(oldSettings)
 
-			return err
+			return saveErr
 		}
 
 		// explicitly trigger old logs deletion
@@ -51,18 +51,7 @@ func (form *TestEmailSend) Submit() error {
 		return err
 	}
 
-	// create a test auth record
-	collection := &models.Collection{
-		BaseModel: models.BaseModel{Id: "__pb_test_collection_id__"},
-		Name:      "__pb_test_collection_name__",
-		Type:      models.CollectionTypeAuth,
-	}
-
-	record := models.NewRecord(collection)
-	record.Id = "__pb_test_id__"
-	record.Set(schema.FieldNameUsername, "pb_test")
-	record.Set(schema.FieldNameEmail, form.Email)
-	record.RefreshTokenKey()
+	record := newTestAuthRecord(form.Email)
 
 	switch form.Template {
 	case templateVerification:
@@ -75,3 +64,21 @@ func (form *TestEmailSend) Submit() error {
 
 	return nil
 }
+
+// newTestAuthRecord creates a new fake (nonpersisted) auth record
+// with the specified email that is used for the email template tests.
+func newTestAuthRecord(email string) *models.Record {
+	collection := &models.Collection{
+		BaseModel: models.BaseModel{Id: "__pb_test_collection_id__"},
+		Name:      "__pb_test_collection_name__",
+		Type:      models.CollectionTypeAuth,
+	}
+
+	record := models.NewRecord(collection)
+	record.Id = "__pb_test_id__"
+	record.Set(schema.FieldNameUsername, "pb_test")
+	record.Set(schema.FieldNameEmail, email)
+	record.RefreshTokenKey()
+
+	return record
+}
@@ -0,0 +1,103 @@
+package validators
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"time"
+	"unicode"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/unkod/space/models"
+	"github.com/unkod/space/tools/security"
+)
+
+// pwnedPasswordsTimeout is the max duration of a single breached passwords range API check.
+const pwnedPasswordsTimeout = 5 * time.Second
+
+var pwnedPasswordsClient = &http.Client{Timeout: pwnedPasswordsTimeout}
+
+// PasswordPolicy checks whether the validated password satisfies the provided policy.
+//
+// identities are the related record username and email values that the
+// password must not match when the policy DisallowIdentity option is set.
+//
+// The breached passwords check is skipped if the range API is unreachable
+// (eg. in offline environments).
+//
+// Example:
+//
+//	validation.Field(&form.Password, validation.By(validators.PasswordPolicy(policy, form.Email, form.Username)))
+func PasswordPolicy(policy *models.CollectionPasswordPolicy, identities ...string) validation.RuleFunc {
+	return func(value any) error {
+		v, _ := value.(string)
+		if v == "" || policy == nil {
+			return nil // nothing to check
+		}
+
+		var hasLower, hasUpper, hasDigit, hasSymbol bool
+		for _, r := range v {
+			switch {
+			case unicode.IsLower(r):
+				hasLower = true
+			case unicode.IsUpper(r):
+				hasUpper = true
+			case unicode.IsDigit(r):
+				hasDigit = true
+			case !unicode.IsLetter(r):
+				hasSymbol = true
+			}
+		}
+
+		if policy.RequireLowercase && !hasLower {
+			return validation.NewError("validation_password_missing_lowercase", "The password must contain at least one lowercase letter.")
+		}
+
+		if policy.RequireUppercase && !hasUpper {
+			return validation.NewError("validation_password_missing_uppercase", "The password must contain at least one uppercase letter.")
+		}
+
+		if policy.RequireDigit && !hasDigit {
+			return validation.NewError("validation_password_missing_digit", "The password must contain at least one digit.")
+		}
+
+		if policy.RequireSymbol && !hasSymbol {
+			return validation.NewError("validation_password_missing_symbol", "The password must contain at least one symbol.")
+		}
+
+		if policy.DisallowIdentity {
+			for _, identity := range identities {
+				if identity == "" {
+					continue
+				}
+
+				local, _, _ := strings.Cut(identity, "@")
+				if strings.EqualFold(v, identity) || strings.EqualFold(v, local) {
+					return validation.NewError("validation_password_identity", "The password must not match the username or email.")
+				}
+			}
+		}
+
+		if policy.DisallowCommon && security.IsCommonPassword(v) {
+			return validation.NewError("validation_password_common", "The password is too common.")
+		}
+
+		for _, p := range policy.DisallowedPasswords {
+			if strings.EqualFold(v, p) {
+				return validation.NewError("validation_password_common", "The password is too common.")
+			}
+		}
+
+		if policy.CheckBreached {
+			ctx, cancel := context.WithTimeout(context.Background(), pwnedPasswordsTimeout)
+			defer cancel()
+
+			pwned, err := security.IsPwnedPassword(ctx, pwnedPasswordsClient, policy.BreachedApiUrl, v)
+			if err == nil && pwned {
+				return validation.NewError("validation_password_breached", "The password was found in a known data breach.")
+			}
+		}
+
+		return nil
+	}
+}
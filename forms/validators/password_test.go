@@ -0,0 +1,141 @@
+package validators_test
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/unkod/space/forms/validators"
+	"github.com/unkod/space/models"
+)
+
+func TestPasswordPolicy(t *testing.T) {
+	// mock the pwned passwords range API
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		sum := sha1.Sum([]byte("Breached1!"))
+		hash := strings.ToUpper(hex.EncodeToString(sum[:]))
+
+		if strings.HasSuffix(r.URL.Path, "/"+hash[:5]) {
+			w.Write([]byte(hash[5:] + ":10"))
+		}
+	}))
+	defer server.Close()
+
+	classesPolicy := &models.CollectionPasswordPolicy{
+		RequireLowercase: true,
+		RequireUppercase: true,
+		RequireDigit:     true,
+		RequireSymbol:    true,
+	}
+
+	scenarios := []struct {
+		name          string
+		policy        *models.CollectionPasswordPolicy
+		identities    []string
+		value         any
+		expectedError string
+	}{
+		{"nil policy", nil, nil, "123", ""},
+		{"empty value", classesPolicy, nil, "", ""},
+		{"missing lowercase", classesPolicy, nil, "ABC123!@", "validation_password_missing_lowercase"},
+		{"missing uppercase", classesPolicy, nil, "abc123!@", "validation_password_missing_uppercase"},
+		{"missing digit", classesPolicy, nil, "abcABC!@", "validation_password_missing_digit"},
+		{"missing symbol", classesPolicy, nil, "abcABC12", "validation_password_missing_symbol"},
+		{"all classes", classesPolicy, nil, "abcABC1 ", ""},
+		{
+			"matching username with disabled DisallowIdentity",
+			&models.CollectionPasswordPolicy{},
+			[]string{"test@example.com", "test_username"},
+			"test_username",
+			"",
+		},
+		{
+			"matching username",
+			&models.CollectionPasswordPolicy{DisallowIdentity: true},
+			[]string{"test@example.com", "test_username"},
+			"TEST_username",
+			"validation_password_identity",
+		},
+		{
+			"matching email",
+			&models.CollectionPasswordPolicy{DisallowIdentity: true},
+			[]string{"test@example.com", "test_username"},
+			"test@example.com",
+			"validation_password_identity",
+		},
+		{
+			"matching email local part",
+			&models.CollectionPasswordPolicy{DisallowIdentity: true},
+			[]string{"test@example.com", "test_username"},
+			"test",
+			"validation_password_identity",
+		},
+		{
+			"non matching identities",
+			&models.CollectionPasswordPolicy{DisallowIdentity: true},
+			[]string{"", "test@example.com", "test_username"},
+			"test@example",
+			"",
+		},
+		{
+			"common password",
+			&models.CollectionPasswordPolicy{DisallowCommon: true},
+			nil,
+			"Password123",
+			"validation_password_common",
+		},
+		{
+			"custom disallowed password",
+			&models.CollectionPasswordPolicy{DisallowedPasswords: []string{"acme2023"}},
+			nil,
+			"ACME2023",
+			"validation_password_common",
+		},
+		{
+			"breached password",
+			&models.CollectionPasswordPolicy{CheckBreached: true, BreachedApiUrl: server.URL},
+			nil,
+			"Breached1!",
+			"validation_password_breached",
+		},
+		{
+			"non breached password",
+			&models.CollectionPasswordPolicy{CheckBreached: true, BreachedApiUrl: server.URL},
+			nil,
+			"NotBreached1!",
+			"",
+		},
+		{
+			"unreachable breached passwords api",
+			&models.CollectionPasswordPolicy{CheckBreached: true, BreachedApiUrl: "http://127.0.0.1:0"},
+			nil,
+			"Breached1!",
+			"",
+		},
+	}
+
+	for _, s := range scenarios {
+		err := validators.PasswordPolicy(s.policy, s.identities...)(s.value)
+
+		if s.expectedError == "" {
+			if err != nil {
+				t.Errorf("[%s] Expected no error, got %v", s.name, err)
+			}
+			continue
+		}
+
+		verr, ok := err.(validation.Error)
+		if !ok {
+			t.Errorf("[%s] Expected validation.Error %q, got %v", s.name, s.expectedError, err)
+			continue
+		}
+
+		if verr.Code() != s.expectedError {
+			t.Errorf("[%s] Expected error code %q, got %q", s.name, s.expectedError, verr.Code())
+		}
+	}
+}
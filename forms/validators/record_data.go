@@ -71,6 +71,10 @@ func (validator *RecordDataValidator) Validate(data map[string]any) error {
 	}
 
 	for key, field := range keyedSchema {
+		if field.Type == schema.FieldTypeComputed {
+			continue // read-only
+		}
+
 		// normalize value to emulate the same behavior
 		// when fetching or persisting the record model
 		value := field.PrepareValue(data[key])
@@ -119,6 +123,8 @@ func (validator *RecordDataValidator) checkFieldValue(field *schema.SchemaField,
 		return validator.checkFileValue(field, value)
 	case schema.FieldTypeRelation:
 		return validator.checkRelationValue(field, value)
+	case schema.FieldTypeGeoPoint:
+		return validator.checkGeoPointValue(field, value)
 	}
 
 	return nil
@@ -303,6 +309,27 @@ func (validator *RecordDataValidator) checkJsonValue(field *schema.SchemaField,
 	return nil
 }
 
+func (validator *RecordDataValidator) checkGeoPointValue(field *schema.SchemaField, value any) error {
+	val, _ := value.(types.GeoPoint)
+
+	if val.IsZero() {
+		if field.Required {
+			return requiredErr
+		}
+		return nil // nothing to check
+	}
+
+	if val.Lat < -90 || val.Lat > 90 {
+		return validation.NewError("validation_invalid_latitude", "Latitude must be between -90 and 90 degrees")
+	}
+
+	if val.Lon < -180 || val.Lon > 180 {
+		return validation.NewError("validation_invalid_longitude", "Longitude must be between -180 and 180 degrees")
+	}
+
+	return nil
+}
+
 func (validator *RecordDataValidator) checkFileValue(field *schema.SchemaField, value any) error {
 	names := list.ToUniqueStringSlice(value)
 	if len(names) == 0 && field.Required {
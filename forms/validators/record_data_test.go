@@ -1230,6 +1230,87 @@ func TestRecordDataValidatorValidateRelation(t *testing.T) {
 	checkValidatorErrors(t, app.Dao(), models.NewRecord(collection), scenarios)
 }
 
+func TestRecordDataValidatorValidateGeoPoint(t *testing.T) {
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	// create new test collection
+	collection := &models.Collection{}
+	collection.Name = "validate_test"
+	collection.Schema = schema.NewSchema(
+		&schema.SchemaField{
+			Name: "field1",
+			Type: schema.FieldTypeGeoPoint,
+		},
+		&schema.SchemaField{
+			Name:     "field2",
+			Required: true,
+			Type:     schema.FieldTypeGeoPoint,
+		},
+	)
+	if err := app.Dao().SaveCollection(collection); err != nil {
+		t.Fatal(err)
+	}
+
+	scenarios := []testDataFieldScenario{
+		{
+			"(geoPoint) check required constraint - nil",
+			map[string]any{
+				"field1": nil,
+				"field2": nil,
+			},
+			nil,
+			[]string{"field2"},
+		},
+		{
+			"(geoPoint) check required constraint - zero coordinates",
+			map[string]any{
+				"field1": `{"lon":0,"lat":0}`,
+				"field2": types.GeoPoint{},
+			},
+			nil,
+			[]string{"field2"},
+		},
+		{
+			"(geoPoint) check latitude range",
+			map[string]any{
+				"field1": `{"lon":10,"lat":-90.1}`,
+				"field2": map[string]any{"lon": 10, "lat": 90.1},
+			},
+			nil,
+			[]string{"field1", "field2"},
+		},
+		{
+			"(geoPoint) check longitude range",
+			map[string]any{
+				"field1": `{"lon":-180.1,"lat":10}`,
+				"field2": map[string]any{"lon": 180.1, "lat": 10},
+			},
+			nil,
+			[]string{"field1", "field2"},
+		},
+		{
+			"(geoPoint) valid data - only required fields",
+			map[string]any{
+				"field2": `{"lon":-122.4,"lat":37.7}`,
+			},
+			nil,
+			[]string{},
+		},
+		{
+			"(geoPoint) valid data - edge coordinates",
+			map[string]any{
+				"field1": types.GeoPoint{Lon: -180, Lat: -90},
+				"field2": map[string]any{"lon": 180, "lat": 90},
+			},
+			nil,
+			[]string{},
+		},
+	}
+
+	checkValidatorErrors(t, app.Dao(), models.NewRecord(collection), scenarios)
+}
+
 func checkValidatorErrors(t *testing.T, dao *daos.Dao, record *models.Record, scenarios []testDataFieldScenario) {
 	for i, s := range scenarios {
 		validator := validators.NewRecordDataValidator(dao, record, s.files)
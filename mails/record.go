@@ -19,18 +19,15 @@ func SendRecordPasswordReset(app core.App, authRecord *models.Record) error {
 		return tokenErr
 	}
 
-	mailClient := app.NewMailClient()
+	mailClient := app.NewCollectionMailClient(authRecord.Collection())
 
-	subject, body, err := resolveEmailTemplate(app, token, app.Settings().Meta.ResetPasswordTemplate)
+	subject, body, err := ResolveEmailTemplate(app, token, app.Settings().Meta.ResetPasswordTemplate)
 	if err != nil {
 		return err
 	}
 
 	message := &mailer.Message{
-		From: mail.Address{
-			Name:    app.Settings().Meta.SenderName,
-			Address: app.Settings().Meta.SenderAddress,
-		},
+		From:    recordSender(app, authRecord.Collection()),
 		To:      []mail.Address{{Address: authRecord.Email()}},
 		Subject: subject,
 		HTML:    body,
@@ -59,18 +56,15 @@ func SendRecordVerification(app core.App, authRecord *models.Record) error {
 		return tokenErr
 	}
 
-	mailClient := app.NewMailClient()
+	mailClient := app.NewCollectionMailClient(authRecord.Collection())
 
-	subject, body, err := resolveEmailTemplate(app, token, app.Settings().Meta.VerificationTemplate)
+	subject, body, err := ResolveEmailTemplate(app, token, app.Settings().Meta.VerificationTemplate)
 	if err != nil {
 		return err
 	}
 
 	message := &mailer.Message{
-		From: mail.Address{
-			Name:    app.Settings().Meta.SenderName,
-			Address: app.Settings().Meta.SenderAddress,
-		},
+		From:    recordSender(app, authRecord.Collection()),
 		To:      []mail.Address{{Address: authRecord.Email()}},
 		Subject: subject,
 		HTML:    body,
@@ -99,18 +93,15 @@ func SendRecordChangeEmail(app core.App, record *models.Record, newEmail string)
 		return tokenErr
 	}
 
-	mailClient := app.NewMailClient()
+	mailClient := app.NewCollectionMailClient(record.Collection())
 
-	subject, body, err := resolveEmailTemplate(app, token, app.Settings().Meta.ConfirmEmailChangeTemplate)
+	subject, body, err := ResolveEmailTemplate(app, token, app.Settings().Meta.ConfirmEmailChangeTemplate)
 	if err != nil {
 		return err
 	}
 
 	message := &mailer.Message{
-		From: mail.Address{
-			Name:    app.Settings().Meta.SenderName,
-			Address: app.Settings().Meta.SenderAddress,
-		},
+		From:    recordSender(app, record.Collection()),
 		To:      []mail.Address{{Address: newEmail}},
 		Subject: subject,
 		HTML:    body,
@@ -135,7 +126,36 @@ func SendRecordChangeEmail(app core.App, record *models.Record, newEmail string)
 	})
 }
 
-func resolveEmailTemplate(
+// recordSender returns the sender of the emails sent to the records
+// of the specified collection.
+//
+// The auth collection mailer sender options take precedence
+// over the global app settings ones.
+func recordSender(app core.App, collection *models.Collection) mail.Address {
+	sender := mail.Address{
+		Name:    app.Settings().Meta.SenderName,
+		Address: app.Settings().Meta.SenderAddress,
+	}
+
+	if collection == nil || !collection.IsAuth() {
+		return sender
+	}
+
+	if options := collection.AuthOptions().Mailer; options != nil {
+		if options.SenderName != "" {
+			sender.Name = options.SenderName
+		}
+		if options.SenderAddress != "" {
+			sender.Address = options.SenderAddress
+		}
+	}
+
+	return sender
+}
+
+// ResolveEmailTemplate resolves the placeholders of the provided email
+// template and wraps its body in the default mail html layout.
+func ResolveEmailTemplate(
 	app core.App,
 	token string,
 	emailTemplate settings.EmailTemplate,
@@ -5,6 +5,7 @@ import (
 	"testing"
 
 	"github.com/unkod/space/mails"
+	"github.com/unkod/space/models"
 	"github.com/unkod/space/tests"
 )
 
@@ -85,3 +86,33 @@ func TestSendRecordChangeEmail(t *testing.T) {
 		}
 	}
 }
+
+func TestSendRecordEmailCollectionSender(t *testing.T) {
+	testApp, _ := tests.NewTestApp()
+	defer testApp.Cleanup()
+
+	testApp.Settings().Meta.SenderName = "global_name"
+	testApp.Settings().Meta.SenderAddress = "global@example.com"
+
+	user, _ := testApp.Dao().FindFirstRecordByData("users", "email", "test@example.com")
+
+	// global sender
+	if err := mails.SendRecordVerification(testApp, user); err != nil {
+		t.Fatal(err)
+	}
+	if from := testApp.TestMailer.LastMessage.From; from.Name != "global_name" || from.Address != "global@example.com" {
+		t.Fatalf("Expected the global sender, got %v", from)
+	}
+
+	// collection sender (with name fallback)
+	options := user.Collection().AuthOptions()
+	options.Mailer = &models.CollectionMailerOptions{SenderAddress: "collection@example.com"}
+	user.Collection().SetOptions(options)
+
+	if err := mails.SendRecordVerification(testApp, user); err != nil {
+		t.Fatal(err)
+	}
+	if from := testApp.TestMailer.LastMessage.From; from.Name != "global_name" || from.Address != "collection@example.com" {
+		t.Fatalf("Expected the collection sender, got %v", from)
+	}
+}
@@ -0,0 +1,91 @@
+package migrations
+
+import (
+	"github.com/pocketbase/dbx"
+	"github.com/unkod/space/daos"
+	"github.com/unkod/space/models"
+	"github.com/unkod/space/models/schema"
+	"github.com/unkod/space/tools/list"
+)
+
+// mfaColumns lists the MFA columns of the _admins and the auth collection tables.
+var mfaColumns = []struct {
+	name       string
+	definition string
+}{
+	{schema.FieldNameMFASecret, `TEXT DEFAULT "" NOT NULL`},
+	{schema.FieldNameMFAEnabled, `BOOLEAN DEFAULT 0 NOT NULL`},
+	{schema.FieldNameMFABackupCodes, `JSON DEFAULT "[]" NOT NULL`},
+	{schema.FieldNameMFALastCounter, `INTEGER DEFAULT 0 NOT NULL`},
+}
+
+// Adds the MFA columns to the _admins and the auth collection tables (if not already).
+func init() {
+	AppMigrations.Register(func(db dbx.Builder) error {
+		dao := daos.New(db)
+
+		tables, err := mfaTables(dao)
+		if err != nil {
+			return err
+		}
+
+		for _, table := range tables {
+			cols, err := dao.TableColumns(table)
+			if err != nil {
+				return err
+			}
+
+			for _, col := range mfaColumns {
+				if list.ExistInSlice(col.name, cols) {
+					continue
+				}
+
+				if _, err := db.AddColumn(table, col.name, col.definition).Execute(); err != nil {
+					return err
+				}
+			}
+		}
+
+		return nil
+	}, func(db dbx.Builder) error {
+		dao := daos.New(db)
+
+		tables, err := mfaTables(dao)
+		if err != nil {
+			return err
+		}
+
+		for _, table := range tables {
+			cols, err := dao.TableColumns(table)
+			if err != nil {
+				return err
+			}
+
+			for _, col := range mfaColumns {
+				if !list.ExistInSlice(col.name, cols) {
+					continue
+				}
+
+				if _, err := db.DropColumn(table, col.name).Execute(); err != nil {
+					return err
+				}
+			}
+		}
+
+		return nil
+	})
+}
+
+func mfaTables(dao *daos.Dao) ([]string, error) {
+	collections, err := dao.FindCollectionsByType(models.CollectionTypeAuth)
+	if err != nil {
+		return nil, err
+	}
+
+	tables := []string{"_admins"}
+	for _, c := range collections {
+		tables = append(tables, c.Name)
+	}
+
+	return tables, nil
+}
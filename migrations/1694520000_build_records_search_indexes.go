@@ -0,0 +1,35 @@
+package migrations
+
+import (
+	"github.com/pocketbase/dbx"
+	"github.com/unkod/space/daos"
+	"github.com/unkod/space/models"
+)
+
+// Builds the full-text search indexes of the collections with
+// search fields and populates them with the existing records data.
+func init() {
+	AppMigrations.Register(func(db dbx.Builder) error {
+		dao := daos.New(db)
+
+		collections := []*models.Collection{}
+		err := dao.CollectionQuery().
+			AndWhere(dbx.HashExp{"type": []any{models.CollectionTypeBase, models.CollectionTypeAuth}}).
+			All(&collections)
+		if err != nil {
+			return err
+		}
+
+		for _, collection := range collections {
+			if len(collection.SearchFields()) == 0 {
+				continue
+			}
+
+			if err := dao.RebuildRecordsSearchIndex(collection); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}, nil)
+}
@@ -0,0 +1,36 @@
+package migrations
+
+import (
+	"github.com/pocketbase/dbx"
+)
+
+// Creates the _audit table with the audited records changes.
+//
+// Note that there is intentionally no foreign key to the _collections
+// table so that the audit trail is preserved even after a collection delete.
+func init() {
+	AppMigrations.Register(func(db dbx.Builder) error {
+		_, err := db.NewQuery(`
+			CREATE TABLE IF NOT EXISTS {{_audit}} (
+				[[id]]           TEXT PRIMARY KEY NOT NULL,
+				[[collectionId]] TEXT NOT NULL,
+				[[recordId]]     TEXT NOT NULL,
+				[[action]]       TEXT NOT NULL,
+				[[actorType]]    TEXT DEFAULT "" NOT NULL,
+				[[actorId]]      TEXT DEFAULT "" NOT NULL,
+				[[changes]]      JSON DEFAULT "{}" NOT NULL,
+				[[created]]      TEXT DEFAULT (strftime('%Y-%m-%d %H:%M:%fZ')) NOT NULL,
+				[[updated]]      TEXT DEFAULT (strftime('%Y-%m-%d %H:%M:%fZ')) NOT NULL
+			);
+
+			CREATE INDEX IF NOT EXISTS _audit_record_idx on {{_audit}} ([[collectionId]], [[recordId]]);
+			CREATE INDEX IF NOT EXISTS _audit_created_idx on {{_audit}} ([[created]]);
+		`).Execute()
+
+		return err
+	}, func(db dbx.Builder) error {
+		_, err := db.DropTable("_audit").Execute()
+
+		return err
+	})
+}
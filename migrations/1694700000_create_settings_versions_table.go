@@ -0,0 +1,30 @@
+package migrations
+
+import (
+	"github.com/pocketbase/dbx"
+)
+
+// Creates the _settingsVersions table with the app settings changes history.
+func init() {
+	AppMigrations.Register(func(db dbx.Builder) error {
+		_, err := db.NewQuery(`
+			CREATE TABLE IF NOT EXISTS {{_settingsVersions}} (
+				[[id]]         TEXT PRIMARY KEY NOT NULL,
+				[[adminId]]    TEXT DEFAULT "" NOT NULL,
+				[[rollbackOf]] TEXT DEFAULT "" NOT NULL,
+				[[changes]]    JSON DEFAULT "{}" NOT NULL,
+				[[value]]      JSON DEFAULT NULL,
+				[[created]]    TEXT DEFAULT (strftime('%Y-%m-%d %H:%M:%fZ')) NOT NULL,
+				[[updated]]    TEXT DEFAULT (strftime('%Y-%m-%d %H:%M:%fZ')) NOT NULL
+			);
+
+			CREATE INDEX IF NOT EXISTS _settingsVersions_created_idx on {{_settingsVersions}} ([[created]]);
+		`).Execute()
+
+		return err
+	}, func(db dbx.Builder) error {
+		_, err := db.DropTable("_settingsVersions").Execute()
+
+		return err
+	})
+}
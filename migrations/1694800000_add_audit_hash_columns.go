@@ -0,0 +1,74 @@
+package migrations
+
+import (
+	"github.com/pocketbase/dbx"
+	"github.com/unkod/space/daos"
+	"github.com/unkod/space/models"
+	"github.com/unkod/space/tools/list"
+)
+
+// Adds the prevHash and hash columns to the _audit table (if not already)
+// and chains the existing audit logs in their insertion order.
+func init() {
+	AppMigrations.Register(func(db dbx.Builder) error {
+		dao := daos.New(db)
+
+		cols, err := dao.TableColumns("_audit")
+		if err != nil {
+			return err
+		}
+
+		for _, name := range []string{"prevHash", "hash"} {
+			if list.ExistInSlice(name, cols) {
+				continue
+			}
+
+			if _, err := db.AddColumn("_audit", name, `TEXT DEFAULT "" NOT NULL`).Execute(); err != nil {
+				return err
+			}
+		}
+
+		logs := []*models.AuditLog{}
+		if err := dao.AuditLogQuery().OrderBy("rowid ASC").All(&logs); err != nil {
+			return err
+		}
+
+		prevHash := ""
+		for _, log := range logs {
+			log.PrevHash = prevHash
+			log.Hash = log.ComputeHash()
+
+			_, err := db.Update(
+				"_audit",
+				dbx.Params{"prevHash": log.PrevHash, "hash": log.Hash},
+				dbx.HashExp{"id": log.Id},
+			).Execute()
+			if err != nil {
+				return err
+			}
+
+			prevHash = log.Hash
+		}
+
+		return nil
+	}, func(db dbx.Builder) error {
+		dao := daos.New(db)
+
+		cols, err := dao.TableColumns("_audit")
+		if err != nil {
+			return err
+		}
+
+		for _, name := range []string{"prevHash", "hash"} {
+			if !list.ExistInSlice(name, cols) {
+				continue
+			}
+
+			if _, err := db.DropColumn("_audit", name).Execute(); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
@@ -5,7 +5,6 @@ import (
 
 	"github.com/unkod/space/tools/security"
 	"github.com/unkod/space/tools/types"
-	"golang.org/x/crypto/bcrypt"
 )
 
 var (
@@ -20,6 +19,18 @@ type Admin struct {
 	TokenKey        string         `db:"tokenKey" json:"-"`
 	PasswordHash    string         `db:"passwordHash" json:"-"`
 	LastResetSentAt types.DateTime `db:"lastResetSentAt" json:"-"`
+
+	// MFASecret is the encrypted TOTP secret.
+	MFASecret string `db:"mfaSecret" json:"-"`
+
+	// MFAEnabled indicates whether the MFASecret enrollment was confirmed.
+	MFAEnabled bool `db:"mfaEnabled" json:"-"`
+
+	// MFABackupCodes is a list with the hashed MFA backup codes.
+	MFABackupCodes types.JsonArray[string] `db:"mfaBackupCodes" json:"-"`
+
+	// MFALastCounter is the time step counter of the last accepted TOTP code.
+	MFALastCounter int `db:"mfaLastCounter" json:"-"`
 }
 
 // TableName returns the Admin model SQL table name.
@@ -29,31 +40,33 @@ func (m *Admin) TableName() string {
 
 // ValidatePassword validates a plain password against the model's password.
 func (m *Admin) ValidatePassword(password string) bool {
-	bytePassword := []byte(password)
-	bytePasswordHash := []byte(m.PasswordHash)
-
-	// comparing the password with the hash
-	err := bcrypt.CompareHashAndPassword(bytePasswordHash, bytePassword)
-
-	// nil means it is a match
-	return err == nil
+	return security.ComparePassword(m.PasswordHash, password)
 }
 
 // SetPassword sets cryptographically secure string to `model.Password`.
 //
 // Additionally this method also resets the LastResetSentAt and the TokenKey fields.
+//
+// The password is hashed with the default [security.PasswordHashOptions]
+// (use [Admin.SetPasswordWithOptions] to specify different ones).
 func (m *Admin) SetPassword(password string) error {
+	return m.SetPasswordWithOptions(password, security.DefaultPasswordHashOptions())
+}
+
+// SetPasswordWithOptions is similar to [Admin.SetPassword] but hashes
+// the password with the provided hashing options.
+func (m *Admin) SetPasswordWithOptions(password string, opts security.PasswordHashOptions) error {
 	if password == "" {
 		return errors.New("The provided plain password is empty")
 	}
 
 	// hash the password
-	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(password), 12)
+	hashedPassword, err := security.HashPassword(password, opts)
 	if err != nil {
 		return err
 	}
 
-	m.PasswordHash = string(hashedPassword)
+	m.PasswordHash = hashedPassword
 	m.LastResetSentAt = types.DateTime{} // reset
 
 	// invalidate previously issued tokens
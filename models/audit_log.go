@@ -0,0 +1,88 @@
+package models
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"strconv"
+
+	"github.com/unkod/space/tools/types"
+)
+
+var _ Model = (*AuditLog)(nil)
+
+// list with the supported audit log actions
+const (
+	AuditActionCreate = "create"
+	AuditActionUpdate = "update"
+	AuditActionDelete = "delete"
+)
+
+// list with the supported audit log actor types
+const (
+	AuditActorSystem = "system"
+	AuditActorGuest  = "guest"
+	AuditActorAdmin  = "admin"
+	AuditActorRecord = "auth_record"
+)
+
+// AuditChange defines a single changed record field entry of an audit log.
+type AuditChange struct {
+	Old any `json:"old"`
+	New any `json:"new"`
+}
+
+// AuditLog defines a single record change stored in the audit trail.
+//
+// The audit logs form a hash chain - each log stores the hash of the
+// previous log (PrevHash) and the hash of its own content (Hash),
+// which allows detecting modified, removed or inserted entries.
+type AuditLog struct {
+	BaseModel
+
+	CollectionId string        `db:"collectionId" json:"collectionId"`
+	RecordId     string        `db:"recordId" json:"recordId"`
+	Action       string        `db:"action" json:"action"`
+	ActorType    string        `db:"actorType" json:"actorType"`
+	ActorId      string        `db:"actorId" json:"actorId"`
+	Changes      types.JsonMap `db:"changes" json:"changes"`
+	PrevHash     string        `db:"prevHash" json:"prevHash"`
+	Hash         string        `db:"hash" json:"hash"`
+}
+
+func (m *AuditLog) TableName() string {
+	return "_audit"
+}
+
+// ComputeHash returns the sha256 hex hash of the log content
+// chained with the log PrevHash.
+func (m *AuditLog) ComputeHash() string {
+	// normalize the changes so that the hash stays
+	// the same before and after the db roundtrip
+	var changes any = map[string]any{}
+	if len(m.Changes) > 0 {
+		raw, _ := json.Marshal(m.Changes)
+		json.Unmarshal(raw, &changes)
+	}
+	rawChanges, _ := json.Marshal(changes)
+
+	parts := []string{
+		m.PrevHash,
+		m.Id,
+		m.CollectionId,
+		m.RecordId,
+		m.Action,
+		m.ActorType,
+		m.ActorId,
+		string(rawChanges),
+		m.Created.String(),
+	}
+
+	h := sha256.New()
+	for _, part := range parts {
+		// length prefix each part to prevent ambiguous concatenations
+		h.Write([]byte(strconv.Itoa(len(part)) + ":" + part))
+	}
+
+	return hex.EncodeToString(h.Sum(nil))
+}
@@ -0,0 +1,53 @@
+package models_test
+
+import (
+	"testing"
+
+	"github.com/unkod/space/models"
+	"github.com/unkod/space/tools/types"
+)
+
+func TestAuditLogTableName(t *testing.T) {
+	m := models.AuditLog{}
+	if m.TableName() != "_audit" {
+		t.Fatalf("Unexpected table name, got %q", m.TableName())
+	}
+}
+
+func TestAuditLogComputeHash(t *testing.T) {
+	m := models.AuditLog{
+		CollectionId: "test_collection",
+		RecordId:     "test_record",
+		Action:       models.AuditActionUpdate,
+		ActorType:    models.AuditActorAdmin,
+		ActorId:      "test_admin",
+		Changes:      types.JsonMap{"title": models.AuditChange{Old: "a", New: "b"}},
+	}
+	m.Id = "test_id"
+	m.Created, _ = types.ParseDateTime("2023-01-01 10:00:00.000Z")
+
+	hash := m.ComputeHash()
+	if len(hash) != 64 {
+		t.Fatalf("Expected sha256 hex hash, got %q", hash)
+	}
+
+	// same content with the unmarshalled changes
+	m.Changes = types.JsonMap{"title": map[string]any{"new": "b", "old": "a"}}
+	if h := m.ComputeHash(); h != hash {
+		t.Fatalf("Expected the same hash %q, got %q", hash, h)
+	}
+
+	// nil and empty changes
+	m.Changes = nil
+	nilHash := m.ComputeHash()
+	m.Changes = types.JsonMap{}
+	if h := m.ComputeHash(); h != nilHash {
+		t.Fatalf("Expected the nil and empty changes hashes to match, got %q and %q", nilHash, h)
+	}
+
+	// changed prevHash
+	m.PrevHash = "test"
+	if h := m.ComputeHash(); h == nilHash {
+		t.Fatal("Expected the prevHash to change the hash")
+	}
+}
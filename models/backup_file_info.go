@@ -6,4 +6,8 @@ type BackupFileInfo struct {
 	Key      string         `json:"key"`
 	Size     int64          `json:"size"`
 	Modified types.DateTime `json:"modified"`
+
+	// Parent is the key of the parent backup
+	// (it is empty for the full backups).
+	Parent string `json:"parent"`
 }
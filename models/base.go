@@ -12,6 +12,10 @@ const (
 
 	// DefaultIdAlphabet is the default characters set used for generating the model id.
 	DefaultIdAlphabet = "abcdefghijklmnopqrstuvwxyz0123456789"
+
+	// MaxCustomIdLength is the max length of a custom record id
+	// (see the collection "idPattern" option).
+	MaxCustomIdLength = 100
 )
 
 // ColumnValueMapper defines an interface for custom db model data serialization.
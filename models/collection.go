@@ -2,10 +2,15 @@ package models
 
 import (
 	"encoding/json"
+	"regexp"
 
 	validation "github.com/go-ozzo/ozzo-validation/v4"
 	"github.com/go-ozzo/ozzo-validation/v4/is"
 	"github.com/unkod/space/models/schema"
+	"github.com/unkod/space/models/settings"
+	"github.com/unkod/space/tools/jsonschema"
+	"github.com/unkod/space/tools/list"
+	"github.com/unkod/space/tools/security"
 	"github.com/unkod/space/tools/types"
 )
 
@@ -64,6 +69,101 @@ func (m *Collection) IsView() bool {
 	return m.Type == CollectionTypeView
 }
 
+// HasSoftDelete checks if the current collection has the soft-delete
+// option enabled (aka. its records are only marked as "deleted" on delete).
+//
+// View collections never have soft-delete enabled.
+func (m *Collection) HasSoftDelete() bool {
+	if m.IsView() {
+		return false
+	}
+
+	enabled, _ := m.Options["softDelete"].(bool)
+
+	return enabled
+}
+
+// SearchFields returns the names of the collection text fields
+// indexed for full-text search (see the "searchFields" option).
+//
+// View collections never have search fields.
+func (m *Collection) SearchFields() []string {
+	if m.IsView() {
+		return nil
+	}
+
+	return list.ToUniqueStringSlice(m.Options["searchFields"])
+}
+
+// IdPattern returns the regex pattern that the custom (user provided)
+// record ids must match (see the "idPattern" option).
+//
+// An empty pattern means that only ids with the default
+// [DefaultIdLength] length are allowed.
+//
+// View collections never have an id pattern.
+func (m *Collection) IdPattern() string {
+	if m.IsView() {
+		return ""
+	}
+
+	pattern, _ := m.Options["idPattern"].(string)
+
+	return pattern
+}
+
+// HasAutoId checks whether a random id is generated for the new
+// collection records without an explicit id (see the "disableAutoId" option).
+//
+// View collections always have auto id.
+func (m *Collection) HasAutoId() bool {
+	if m.IsView() {
+		return true
+	}
+
+	disabled, _ := m.Options["disableAutoId"].(bool)
+
+	return !disabled
+}
+
+// HasAudit checks if the changes of the current collection records
+// are stored in the audit trail (see the "audit" option).
+//
+// View collections are never audited.
+func (m *Collection) HasAudit() bool {
+	if m.IsView() {
+		return false
+	}
+
+	enabled, _ := m.Options["audit"].(bool)
+
+	return enabled
+}
+
+// IsApiDisabled checks whether the collection records are excluded
+// from the public records APIs (see the "apiDisabled" option).
+//
+// The records of such collections are still accessible via the Dao.
+func (m *Collection) IsApiDisabled() bool {
+	disabled, _ := m.Options["apiDisabled"].(bool)
+
+	return disabled
+}
+
+// JsonSchema returns the JSON Schema document that the collection
+// record data must satisfy on create and update (see the "jsonSchema" option).
+//
+// Returns nil if the collection doesn't have a JSON Schema.
+//
+// View collections never have a JSON Schema.
+func (m *Collection) JsonSchema() any {
+	if m.IsView() {
+		return nil
+	}
+
+	return m.Options["jsonSchema"]
+}
+
 // MarshalJSON implements the [json.Marshaler] interface.
 func (m Collection) MarshalJSON() ([]byte, error) {
 	type alias Collection // prevent recursion
@@ -162,25 +262,77 @@ func (m *Collection) SetOptions(typedOptions any) error {
 
 // CollectionBaseOptions defines the "base" Collection.Options fields.
 type CollectionBaseOptions struct {
+	SoftDelete    bool          `form:"softDelete" json:"softDelete"`
+	SearchFields  []string      `form:"searchFields" json:"searchFields"`
+	IdPattern     string        `form:"idPattern" json:"idPattern"`
+	DisableAutoId bool          `form:"disableAutoId" json:"disableAutoId"`
+	Audit         bool          `form:"audit" json:"audit"`
+	JsonSchema    types.JsonRaw `form:"jsonSchema" json:"jsonSchema"`
+	ApiDisabled   bool          `form:"apiDisabled" json:"apiDisabled"`
 }
 
 // Validate implements [validation.Validatable] interface.
 func (o CollectionBaseOptions) Validate() error {
-	return nil
+	return validation.ValidateStruct(&o,
+		validation.Field(&o.SearchFields, validation.By(checkUniqueSearchFields)),
+		validation.Field(&o.IdPattern, validation.By(checkIdPattern)),
+		validation.Field(&o.JsonSchema, validation.By(checkJsonSchema)),
+	)
 }
 
 // -------------------------------------------------------------------
 
 // CollectionAuthOptions defines the "auth" Collection.Options fields.
 type CollectionAuthOptions struct {
-	ManageRule         *string  `form:"manageRule" json:"manageRule"`
-	AllowOAuth2Auth    bool     `form:"allowOAuth2Auth" json:"allowOAuth2Auth"`
-	AllowUsernameAuth  bool     `form:"allowUsernameAuth" json:"allowUsernameAuth"`
-	AllowEmailAuth     bool     `form:"allowEmailAuth" json:"allowEmailAuth"`
-	RequireEmail       bool     `form:"requireEmail" json:"requireEmail"`
-	ExceptEmailDomains []string `form:"exceptEmailDomains" json:"exceptEmailDomains"`
-	OnlyEmailDomains   []string `form:"onlyEmailDomains" json:"onlyEmailDomains"`
-	MinPasswordLength  int      `form:"minPasswordLength" json:"minPasswordLength"`
+	ManageRule         *string       `form:"manageRule" json:"manageRule"`
+	AllowOAuth2Auth    bool          `form:"allowOAuth2Auth" json:"allowOAuth2Auth"`
+	AllowUsernameAuth  bool          `form:"allowUsernameAuth" json:"allowUsernameAuth"`
+	AllowEmailAuth     bool          `form:"allowEmailAuth" json:"allowEmailAuth"`
+	RequireEmail       bool          `form:"requireEmail" json:"requireEmail"`
+	ExceptEmailDomains []string      `form:"exceptEmailDomains" json:"exceptEmailDomains"`
+	OnlyEmailDomains   []string      `form:"onlyEmailDomains" json:"onlyEmailDomains"`
+	MinPasswordLength  int           `form:"minPasswordLength" json:"minPasswordLength"`
+	OnlyVerified       bool          `form:"onlyVerified" json:"onlyVerified"`
+	SoftDelete         bool          `form:"softDelete" json:"softDelete"`
+	SearchFields       []string      `form:"searchFields" json:"searchFields"`
+	IdPattern          string        `form:"idPattern" json:"idPattern"`
+	DisableAutoId      bool          `form:"disableAutoId" json:"disableAutoId"`
+	Audit              bool          `form:"audit" json:"audit"`
+	JsonSchema         types.JsonRaw `form:"jsonSchema" json:"jsonSchema"`
+	ApiDisabled        bool          `form:"apiDisabled" json:"apiDisabled"`
+
+	// RotateAuthToken enables the auth token rotation on auth refresh,
+	// aka. the refresh regenerates the record token key which invalidates
+	// all previously issued record tokens (after a short grace period).
+	//
+	// Note that the token key is shared by all sessions of the auth record,
+	// so the refresh from one device logs out the other devices of the same
+	// record (they will have to authenticate again after the grace period).
+	RotateAuthToken bool `form:"rotateAuthToken" json:"rotateAuthToken"`
+
+	// optional collection specific token durations (in seconds)
+	// that take precedence over the global app settings ones
+	// (zero value means "use the global setting")
+	AuthTokenDuration          int64 `form:"authTokenDuration" json:"authTokenDuration"`
+	PasswordResetTokenDuration int64 `form:"passwordResetTokenDuration" json:"passwordResetTokenDuration"`
+	VerificationTokenDuration  int64 `form:"verificationTokenDuration" json:"verificationTokenDuration"`
+	EmailChangeTokenDuration   int64 `form:"emailChangeTokenDuration" json:"emailChangeTokenDuration"`
+
+	// optional collection specific mailer settings
+	// that take precedence over the global app settings
+	Mailer *CollectionMailerOptions `form:"mailer" json:"mailer,omitempty"`
+
+	// optional password policy applied in addition to MinPasswordLength
+	// when setting a new record password
+	PasswordPolicy *CollectionPasswordPolicy `form:"passwordPolicy" json:"passwordPolicy,omitempty"`
+
+	// optional custom claims added to the issued record auth tokens
+	// in the format "claimName": "recordFieldName" (eg. "role": "role")
+	//
+	// Note that every claim increases the token size and the auth tokens
+	// are usually sent with each request header (and often stored in cookies
+	// which are limited to ~4KB), so keep the claims values short.
+	TokenClaims map[string]string `form:"tokenClaims" json:"tokenClaims,omitempty"`
 }
 
 // Validate implements [validation.Validatable] interface.
@@ -201,14 +353,201 @@ func (o CollectionAuthOptions) Validate() error {
 			validation.Min(5),
 			validation.Max(72),
 		),
+		validation.Field(&o.AuthTokenDuration, validation.Min(int64(5)), validation.Max(int64(63072000))),
+		validation.Field(&o.PasswordResetTokenDuration, validation.Min(int64(5)), validation.Max(int64(63072000))),
+		validation.Field(&o.VerificationTokenDuration, validation.Min(int64(5)), validation.Max(int64(63072000))),
+		validation.Field(&o.EmailChangeTokenDuration, validation.Min(int64(5)), validation.Max(int64(63072000))),
+		validation.Field(&o.SearchFields, validation.By(checkUniqueSearchFields)),
+		validation.Field(&o.IdPattern, validation.By(checkIdPattern)),
+		validation.Field(&o.JsonSchema, validation.By(checkJsonSchema)),
+		validation.Field(&o.Mailer),
+		validation.Field(&o.PasswordPolicy),
+		validation.Field(&o.TokenClaims, validation.Length(0, maxTokenClaims), validation.By(checkTokenClaims)),
+	)
+}
+
+// CollectionPasswordPolicy defines the auth collection password
+// strength requirements.
+type CollectionPasswordPolicy struct {
+	// required character classes
+	RequireLowercase bool `form:"requireLowercase" json:"requireLowercase"`
+	RequireUppercase bool `form:"requireUppercase" json:"requireUppercase"`
+	RequireDigit     bool `form:"requireDigit" json:"requireDigit"`
+	RequireSymbol    bool `form:"requireSymbol" json:"requireSymbol"`
+
+	// DisallowIdentity forbids passwords matching (case-insensitive)
+	// the record username, email or email local part.
+	DisallowIdentity bool `form:"disallowIdentity" json:"disallowIdentity"`
+
+	// DisallowCommon forbids the builtin list of commonly used passwords.
+	DisallowCommon bool `form:"disallowCommon" json:"disallowCommon"`
+
+	// DisallowedPasswords is an optional list with additional
+	// forbidden passwords (case-insensitive).
+	DisallowedPasswords []string `form:"disallowedPasswords" json:"disallowedPasswords"`
+
+	// CheckBreached enables the "Have I Been Pwned" k-anonymity
+	// range API check for passwords exposed in known data breaches.
+	//
+	// The check is skipped if the range API is unavailable.
+	CheckBreached bool `form:"checkBreached" json:"checkBreached"`
+
+	// BreachedApiUrl is an optional custom passwords range API url
+	// (eg. a self-hosted mirror for offline environments).
+	BreachedApiUrl string `form:"breachedApiUrl" json:"breachedApiUrl"`
+}
+
+// Validate implements [validation.Validatable] interface.
+func (o CollectionPasswordPolicy) Validate() error {
+	return validation.ValidateStruct(&o,
+		validation.Field(&o.DisallowedPasswords, validation.Each(validation.Required, validation.Length(1, 72))),
+		validation.Field(&o.BreachedApiUrl, is.URL),
+	)
+}
+
+// CollectionMailerOptions defines the auth collection specific mailer
+// settings used for the emails sent to the collection records.
+//
+// The SMTP settings are used only when Enabled is set, while the
+// empty sender fields fallback to the global app settings ones.
+type CollectionMailerOptions struct {
+	settings.SmtpConfig
+
+	SenderName    string `form:"senderName" json:"senderName"`
+	SenderAddress string `form:"senderAddress" json:"senderAddress"`
+}
+
+// Validate implements [validation.Validatable] interface.
+func (o CollectionMailerOptions) Validate() error {
+	if err := o.SmtpConfig.Validate(); err != nil {
+		return err
+	}
+
+	return validation.ValidateStruct(&o,
+		validation.Field(&o.SenderName, validation.Length(0, 255)),
+		validation.Field(&o.SenderAddress, is.EmailFormat),
 	)
 }
 
+// EncryptPassword encrypts the current SMTP password with encryptionKey.
+//
+// If encryptionKey or the password is empty, the password is left unchanged.
+func (o *CollectionMailerOptions) EncryptPassword(encryptionKey string) error {
+	if encryptionKey == "" || o.Password == "" {
+		return nil
+	}
+
+	encrypted, err := security.Encrypt([]byte(o.Password), encryptionKey)
+	if err != nil {
+		return err
+	}
+
+	o.Password = encrypted
+
+	return nil
+}
+
+// DecryptedPassword returns the plain SMTP password.
+//
+// The stored password is returned as it is if it is not
+// encrypted or it cannot be decrypted with encryptionKey
+// (eg. it was saved before setting the app encryption env key).
+func (o CollectionMailerOptions) DecryptedPassword(encryptionKey string) string {
+	if encryptionKey == "" || o.Password == "" {
+		return o.Password
+	}
+
+	decrypted, err := security.Decrypt(o.Password, encryptionKey)
+	if err != nil {
+		return o.Password
+	}
+
+	return string(decrypted)
+}
+
+func checkUniqueSearchFields(value any) error {
+	names, _ := value.([]string)
+
+	if len(list.ToUniqueStringSlice(names)) != len(names) {
+		return validation.NewError("validation_duplicated_search_fields", "The search fields must be unique.")
+	}
+
+	return nil
+}
+
+// maxTokenClaims is the max number of the custom auth token claims.
+const maxTokenClaims = 20
+
+var tokenClaimNameRegex = regexp.MustCompile(`^[a-zA-Z_]\w*$`)
+
+// ReservedTokenClaims returns the names of the auth token claims
+// that couldn't be overwritten by the custom TokenClaims.
+func ReservedTokenClaims() []string {
+	return []string{
+		// app claims
+		"id", "type", "collectionId", "email", "newEmail", "impersonator",
+		// registered JWT claims
+		"exp", "iat", "nbf", "iss", "sub", "aud", "jti",
+	}
+}
+
+func checkTokenClaims(value any) error {
+	claims, _ := value.(map[string]string)
+
+	reserved := ReservedTokenClaims()
+
+	errs := validation.Errors{}
+
+	for name, field := range claims {
+		switch {
+		case !tokenClaimNameRegex.MatchString(name):
+			errs[name] = validation.NewError("validation_invalid_token_claim", "Invalid claim name.")
+		case list.ExistInSlice(name, reserved):
+			errs[name] = validation.NewError("validation_reserved_token_claim", "The claim name is reserved.")
+		case field == "":
+			errs[name] = validation.ErrRequired
+		}
+	}
+
+	if len(errs) > 0 {
+		return errs
+	}
+
+	return nil
+}
+
+func checkIdPattern(value any) error {
+	v, _ := value.(string)
+	if v == "" {
+		return nil // nothing to check
+	}
+
+	if _, err := regexp.Compile(v); err != nil {
+		return validation.NewError("validation_invalid_regex", err.Error())
+	}
+
+	return nil
+}
+
+func checkJsonSchema(value any) error {
+	v, _ := value.(types.JsonRaw)
+	if len(v) == 0 || v.String() == "null" {
+		return nil // nothing to check
+	}
+
+	if _, err := jsonschema.Compile([]byte(v)); err != nil {
+		return validation.NewError("validation_invalid_json_schema", err.Error())
+	}
+
+	return nil
+}
+
 // -------------------------------------------------------------------
 
 // CollectionViewOptions defines the "view" Collection.Options fields.
 type CollectionViewOptions struct {
-	Query string `form:"query" json:"query"`
+	Query       string `form:"query" json:"query"`
+	ApiDisabled bool   `form:"apiDisabled" json:"apiDisabled"`
 }
 
 // Validate implements [validation.Validatable] interface.
@@ -2,11 +2,15 @@ package models_test
 
 import (
 	"encoding/json"
+	"reflect"
+	"strings"
 	"testing"
 
 	validation "github.com/go-ozzo/ozzo-validation/v4"
 	"github.com/unkod/space/models"
+	"github.com/unkod/space/models/settings"
 	"github.com/unkod/space/tools/list"
+	"github.com/unkod/space/tools/mailer"
 	"github.com/unkod/space/tools/types"
 )
 
@@ -66,6 +70,154 @@ func TestCollectionIsAuth(t *testing.T) {
 	}
 }
 
+func TestCollectionHasSoftDelete(t *testing.T) {
+	scenarios := []struct {
+		collection models.Collection
+		expected   bool
+	}{
+		{models.Collection{}, false},
+		{models.Collection{Type: models.CollectionTypeBase}, false},
+		{models.Collection{Type: models.CollectionTypeBase, Options: types.JsonMap{"softDelete": false}}, false},
+		{models.Collection{Type: models.CollectionTypeBase, Options: types.JsonMap{"softDelete": true}}, true},
+		{models.Collection{Type: models.CollectionTypeAuth, Options: types.JsonMap{"softDelete": true}}, true},
+		{models.Collection{Type: models.CollectionTypeView, Options: types.JsonMap{"softDelete": true}}, false},
+	}
+
+	for i, s := range scenarios {
+		result := s.collection.HasSoftDelete()
+		if result != s.expected {
+			t.Errorf("(%d) Expected %v, got %v", i, s.expected, result)
+		}
+	}
+}
+
+func TestCollectionSearchFields(t *testing.T) {
+	scenarios := []struct {
+		collection models.Collection
+		expected   []string
+	}{
+		{models.Collection{}, nil},
+		{models.Collection{Type: models.CollectionTypeBase}, nil},
+		{models.Collection{Type: models.CollectionTypeBase, Options: types.JsonMap{"searchFields": []any{}}}, nil},
+		{models.Collection{Type: models.CollectionTypeBase, Options: types.JsonMap{"searchFields": []any{"a", "", "b", "a"}}}, []string{"a", "b"}},
+		{models.Collection{Type: models.CollectionTypeAuth, Options: types.JsonMap{"searchFields": []string{"a"}}}, []string{"a"}},
+		{models.Collection{Type: models.CollectionTypeView, Options: types.JsonMap{"searchFields": []any{"a"}}}, nil},
+	}
+
+	for i, s := range scenarios {
+		result := s.collection.SearchFields()
+		if strings.Join(result, ",") != strings.Join(s.expected, ",") {
+			t.Errorf("(%d) Expected %v, got %v", i, s.expected, result)
+		}
+	}
+}
+
+func TestCollectionIdPattern(t *testing.T) {
+	scenarios := []struct {
+		collection models.Collection
+		expected   string
+	}{
+		{models.Collection{}, ""},
+		{models.Collection{Type: models.CollectionTypeBase}, ""},
+		{models.Collection{Type: models.CollectionTypeBase, Options: types.JsonMap{"idPattern": "^a$"}}, "^a$"},
+		{models.Collection{Type: models.CollectionTypeAuth, Options: types.JsonMap{"idPattern": "^a$"}}, "^a$"},
+		{models.Collection{Type: models.CollectionTypeView, Options: types.JsonMap{"idPattern": "^a$"}}, ""},
+	}
+
+	for i, s := range scenarios {
+		result := s.collection.IdPattern()
+		if result != s.expected {
+			t.Errorf("(%d) Expected %q, got %q", i, s.expected, result)
+		}
+	}
+}
+
+func TestCollectionHasAutoId(t *testing.T) {
+	scenarios := []struct {
+		collection models.Collection
+		expected   bool
+	}{
+		{models.Collection{}, true},
+		{models.Collection{Type: models.CollectionTypeBase}, true},
+		{models.Collection{Type: models.CollectionTypeBase, Options: types.JsonMap{"disableAutoId": false}}, true},
+		{models.Collection{Type: models.CollectionTypeBase, Options: types.JsonMap{"disableAutoId": true}}, false},
+		{models.Collection{Type: models.CollectionTypeAuth, Options: types.JsonMap{"disableAutoId": true}}, false},
+		{models.Collection{Type: models.CollectionTypeView, Options: types.JsonMap{"disableAutoId": true}}, true},
+	}
+
+	for i, s := range scenarios {
+		result := s.collection.HasAutoId()
+		if result != s.expected {
+			t.Errorf("(%d) Expected %v, got %v", i, s.expected, result)
+		}
+	}
+}
+
+func TestCollectionHasAudit(t *testing.T) {
+	scenarios := []struct {
+		collection models.Collection
+		expected   bool
+	}{
+		{models.Collection{}, false},
+		{models.Collection{Type: models.CollectionTypeBase}, false},
+		{models.Collection{Type: models.CollectionTypeBase, Options: types.JsonMap{"audit": false}}, false},
+		{models.Collection{Type: models.CollectionTypeBase, Options: types.JsonMap{"audit": true}}, true},
+		{models.Collection{Type: models.CollectionTypeAuth, Options: types.JsonMap{"audit": true}}, true},
+		{models.Collection{Type: models.CollectionTypeView, Options: types.JsonMap{"audit": true}}, false},
+	}
+
+	for i, s := range scenarios {
+		result := s.collection.HasAudit()
+		if result != s.expected {
+			t.Errorf("(%d) Expected %v, got %v", i, s.expected, result)
+		}
+	}
+}
+
+func TestCollectionIsApiDisabled(t *testing.T) {
+	scenarios := []struct {
+		collection models.Collection
+		expected   bool
+	}{
+		{models.Collection{}, false},
+		{models.Collection{Type: models.CollectionTypeBase}, false},
+		{models.Collection{Type: models.CollectionTypeBase, Options: types.JsonMap{"apiDisabled": false}}, false},
+		{models.Collection{Type: models.CollectionTypeBase, Options: types.JsonMap{"apiDisabled": true}}, true},
+		{models.Collection{Type: models.CollectionTypeAuth, Options: types.JsonMap{"apiDisabled": true}}, true},
+		{models.Collection{Type: models.CollectionTypeView, Options: types.JsonMap{"apiDisabled": true}}, true},
+	}
+
+	for i, s := range scenarios {
+		result := s.collection.IsApiDisabled()
+		if result != s.expected {
+			t.Errorf("(%d) Expected %v, got %v", i, s.expected, result)
+		}
+	}
+}
+
+func TestCollectionJsonSchema(t *testing.T) {
+	jsonSchema := map[string]any{"required": []any{"title"}}
+
+	scenarios := []struct {
+		collection models.Collection
+		expected   any
+	}{
+		{models.Collection{}, nil},
+		{models.Collection{Type: models.CollectionTypeBase}, nil},
+		{models.Collection{Type: models.CollectionTypeBase, Options: types.JsonMap{"jsonSchema": nil}}, nil},
+		{models.Collection{Type: models.CollectionTypeBase, Options: types.JsonMap{"jsonSchema": jsonSchema}}, jsonSchema},
+		{models.Collection{Type: models.CollectionTypeAuth, Options: types.JsonMap{"jsonSchema": jsonSchema}}, jsonSchema},
+		{models.Collection{Type: models.CollectionTypeView, Options: types.JsonMap{"jsonSchema": jsonSchema}}, nil},
+	}
+
+	for i, s := range scenarios {
+		result := s.collection.JsonSchema()
+		if !reflect.DeepEqual(result, s.expected) {
+			t.Errorf("(%d) Expected %v, got %v", i, s.expected, result)
+		}
+	}
+}
+
 func TestCollectionMarshalJSON(t *testing.T) {
 	scenarios := []struct {
 		name       string
@@ -75,22 +227,22 @@ func TestCollectionMarshalJSON(t *testing.T) {
 		{
 			"no type",
 			models.Collection{Name: "test"},
-			`{"id":"","created":"","updated":"","name":"test","type":"","system":false,"schema":[],"indexes":[],"listRule":null,"viewRule":null,"createRule":null,"updateRule":null,"deleteRule":null,"options":{}}`,
+			`{"id":"","created":"","updated":"","name":"test","type":"","system":false,"schema":[],"indexes":[],"listRule":null,"viewRule":null,"createRule":null,"updateRule":null,"deleteRule":null,"options":{"apiDisabled":false,"audit":false,"disableAutoId":false,"idPattern":"","jsonSchema":null,"searchFields":null,"softDelete":false}}`,
 		},
 		{
 			"unknown type + non empty options",
 			models.Collection{Name: "test", Type: "unknown", ListRule: types.Pointer("test_list"), Options: types.JsonMap{"test": 123}, Indexes: types.JsonArray[string]{"idx_test"}},
-			`{"id":"","created":"","updated":"","name":"test","type":"unknown","system":false,"schema":[],"indexes":["idx_test"],"listRule":"test_list","viewRule":null,"createRule":null,"updateRule":null,"deleteRule":null,"options":{}}`,
+			`{"id":"","created":"","updated":"","name":"test","type":"unknown","system":false,"schema":[],"indexes":["idx_test"],"listRule":"test_list","viewRule":null,"createRule":null,"updateRule":null,"deleteRule":null,"options":{"apiDisabled":false,"audit":false,"disableAutoId":false,"idPattern":"","jsonSchema":null,"searchFields":null,"softDelete":false}}`,
 		},
 		{
 			"base type + non empty options",
 			models.Collection{Name: "test", Type: models.CollectionTypeBase, ListRule: types.Pointer("test_list"), Options: types.JsonMap{"test": 123}},
-			`{"id":"","created":"","updated":"","name":"test","type":"base","system":false,"schema":[],"indexes":[],"listRule":"test_list","viewRule":null,"createRule":null,"updateRule":null,"deleteRule":null,"options":{}}`,
+			`{"id":"","created":"","updated":"","name":"test","type":"base","system":false,"schema":[],"indexes":[],"listRule":"test_list","viewRule":null,"createRule":null,"updateRule":null,"deleteRule":null,"options":{"apiDisabled":false,"audit":false,"disableAutoId":false,"idPattern":"","jsonSchema":null,"searchFields":null,"softDelete":false}}`,
 		},
 		{
 			"auth type + non empty options",
 			models.Collection{BaseModel: models.BaseModel{Id: "test"}, Type: models.CollectionTypeAuth, Options: types.JsonMap{"test": 123, "allowOAuth2Auth": true, "minPasswordLength": 4}},
-			`{"id":"test","created":"","updated":"","name":"","type":"auth","system":false,"schema":[],"indexes":[],"listRule":null,"viewRule":null,"createRule":null,"updateRule":null,"deleteRule":null,"options":{"allowEmailAuth":false,"allowOAuth2Auth":true,"allowUsernameAuth":false,"exceptEmailDomains":null,"manageRule":null,"minPasswordLength":4,"onlyEmailDomains":null,"requireEmail":false}}`,
+			`{"id":"test","created":"","updated":"","name":"","type":"auth","system":false,"schema":[],"indexes":[],"listRule":null,"viewRule":null,"createRule":null,"updateRule":null,"deleteRule":null,"options":{"allowEmailAuth":false,"allowOAuth2Auth":true,"allowUsernameAuth":false,"apiDisabled":false,"audit":false,"authTokenDuration":0,"disableAutoId":false,"emailChangeTokenDuration":0,"exceptEmailDomains":null,"idPattern":"","jsonSchema":null,"manageRule":null,"minPasswordLength":4,"onlyEmailDomains":null,"onlyVerified":false,"passwordResetTokenDuration":0,"requireEmail":false,"rotateAuthToken":false,"searchFields":null,"softDelete":false,"verificationTokenDuration":0}}`,
 		},
 	}
 
@@ -115,22 +267,27 @@ func TestCollectionBaseOptions(t *testing.T) {
 		{
 			"no type",
 			models.Collection{Options: types.JsonMap{"test": 123}},
-			"{}",
+			`{"softDelete":false,"searchFields":null,"idPattern":"","disableAutoId":false,"audit":false,"jsonSchema":null,"apiDisabled":false}`,
 		},
 		{
 			"unknown type",
 			models.Collection{Type: "anything", Options: types.JsonMap{"test": 123}},
-			"{}",
+			`{"softDelete":false,"searchFields":null,"idPattern":"","disableAutoId":false,"audit":false,"jsonSchema":null,"apiDisabled":false}`,
 		},
 		{
 			"different type",
 			models.Collection{Type: models.CollectionTypeAuth, Options: types.JsonMap{"test": 123, "minPasswordLength": 4}},
-			"{}",
+			`{"softDelete":false,"searchFields":null,"idPattern":"","disableAutoId":false,"audit":false,"jsonSchema":null,"apiDisabled":false}`,
 		},
 		{
 			"base type",
 			models.Collection{Type: models.CollectionTypeBase, Options: types.JsonMap{"test": 123}},
-			"{}",
+			`{"softDelete":false,"searchFields":null,"idPattern":"","disableAutoId":false,"audit":false,"jsonSchema":null,"apiDisabled":false}`,
+		},
+		{
+			"base type + soft-delete",
+			models.Collection{Type: models.CollectionTypeBase, Options: types.JsonMap{"test": 123, "softDelete": true}},
+			`{"softDelete":true,"searchFields":null,"idPattern":"","disableAutoId":false,"audit":false,"jsonSchema":null,"apiDisabled":false}`,
 		},
 	}
 
@@ -150,7 +307,7 @@ func TestCollectionBaseOptions(t *testing.T) {
 
 func TestCollectionAuthOptions(t *testing.T) {
 	options := types.JsonMap{"test": 123, "minPasswordLength": 4}
-	expectedSerialization := `{"manageRule":null,"allowOAuth2Auth":false,"allowUsernameAuth":false,"allowEmailAuth":false,"requireEmail":false,"exceptEmailDomains":null,"onlyEmailDomains":null,"minPasswordLength":4}`
+	expectedSerialization := `{"manageRule":null,"allowOAuth2Auth":false,"allowUsernameAuth":false,"allowEmailAuth":false,"requireEmail":false,"exceptEmailDomains":null,"onlyEmailDomains":null,"minPasswordLength":4,"onlyVerified":false,"softDelete":false,"searchFields":null,"idPattern":"","disableAutoId":false,"audit":false,"jsonSchema":null,"apiDisabled":false,"rotateAuthToken":false,"authTokenDuration":0,"passwordResetTokenDuration":0,"verificationTokenDuration":0,"emailChangeTokenDuration":0}`
 
 	scenarios := []struct {
 		name       string
@@ -195,7 +352,7 @@ func TestCollectionAuthOptions(t *testing.T) {
 
 func TestCollectionViewOptions(t *testing.T) {
 	options := types.JsonMap{"query": "select id from demo1", "minPasswordLength": 4}
-	expectedSerialization := `{"query":"select id from demo1"}`
+	expectedSerialization := `{"query":"select id from demo1","apiDisabled":false}`
 
 	scenarios := []struct {
 		name       string
@@ -247,17 +404,17 @@ func TestNormalizeOptions(t *testing.T) {
 		{
 			"unknown type",
 			models.Collection{Type: "unknown", Options: types.JsonMap{"test": 123, "minPasswordLength": 4}},
-			"{}",
+			`{"apiDisabled":false,"audit":false,"disableAutoId":false,"idPattern":"","jsonSchema":null,"searchFields":null,"softDelete":false}`,
 		},
 		{
 			"base type",
 			models.Collection{Type: models.CollectionTypeBase, Options: types.JsonMap{"test": 123, "minPasswordLength": 4}},
-			"{}",
+			`{"apiDisabled":false,"audit":false,"disableAutoId":false,"idPattern":"","jsonSchema":null,"searchFields":null,"softDelete":false}`,
 		},
 		{
 			"auth type",
 			models.Collection{Type: models.CollectionTypeAuth, Options: types.JsonMap{"test": 123, "minPasswordLength": 4}},
-			`{"allowEmailAuth":false,"allowOAuth2Auth":false,"allowUsernameAuth":false,"exceptEmailDomains":null,"manageRule":null,"minPasswordLength":4,"onlyEmailDomains":null,"requireEmail":false}`,
+			`{"allowEmailAuth":false,"allowOAuth2Auth":false,"allowUsernameAuth":false,"apiDisabled":false,"audit":false,"authTokenDuration":0,"disableAutoId":false,"emailChangeTokenDuration":0,"exceptEmailDomains":null,"idPattern":"","jsonSchema":null,"manageRule":null,"minPasswordLength":4,"onlyEmailDomains":null,"onlyVerified":false,"passwordResetTokenDuration":0,"requireEmail":false,"rotateAuthToken":false,"searchFields":null,"softDelete":false,"verificationTokenDuration":0}`,
 		},
 	}
 
@@ -307,25 +464,25 @@ func TestSetOptions(t *testing.T) {
 			"no type",
 			models.Collection{},
 			map[string]any{},
-			"{}",
+			`{"apiDisabled":false,"audit":false,"disableAutoId":false,"idPattern":"","jsonSchema":null,"searchFields":null,"softDelete":false}`,
 		},
 		{
 			"unknown type + non empty options",
 			models.Collection{Type: "unknown", Options: types.JsonMap{"test": 123}},
 			map[string]any{"test": 456, "minPasswordLength": 4},
-			"{}",
+			`{"apiDisabled":false,"audit":false,"disableAutoId":false,"idPattern":"","jsonSchema":null,"searchFields":null,"softDelete":false}`,
 		},
 		{
 			"base type",
 			models.Collection{Type: models.CollectionTypeBase, Options: types.JsonMap{"test": 123}},
 			map[string]any{"test": 456, "minPasswordLength": 4},
-			"{}",
+			`{"apiDisabled":false,"audit":false,"disableAutoId":false,"idPattern":"","jsonSchema":null,"searchFields":null,"softDelete":false}`,
 		},
 		{
 			"auth type",
 			models.Collection{Type: models.CollectionTypeAuth, Options: types.JsonMap{"test": 123}},
 			map[string]any{"test": 456, "minPasswordLength": 4},
-			`{"allowEmailAuth":false,"allowOAuth2Auth":false,"allowUsernameAuth":false,"exceptEmailDomains":null,"manageRule":null,"minPasswordLength":4,"onlyEmailDomains":null,"requireEmail":false}`,
+			`{"allowEmailAuth":false,"allowOAuth2Auth":false,"allowUsernameAuth":false,"apiDisabled":false,"audit":false,"authTokenDuration":0,"disableAutoId":false,"emailChangeTokenDuration":0,"exceptEmailDomains":null,"idPattern":"","jsonSchema":null,"manageRule":null,"minPasswordLength":4,"onlyEmailDomains":null,"onlyVerified":false,"passwordResetTokenDuration":0,"requireEmail":false,"rotateAuthToken":false,"searchFields":null,"softDelete":false,"verificationTokenDuration":0}`,
 		},
 	}
 
@@ -351,6 +508,38 @@ func TestCollectionBaseOptionsValidate(t *testing.T) {
 	if err := opt.Validate(); err != nil {
 		t.Fatal(err)
 	}
+
+	opt.SearchFields = []string{"a", "b"}
+	if err := opt.Validate(); err != nil {
+		t.Fatal(err)
+	}
+
+	opt.SearchFields = []string{"a", "b", "a"}
+	if err := opt.Validate(); err == nil {
+		t.Fatal("Expected duplicated search fields error, got nil")
+	}
+
+	opt.SearchFields = nil
+	opt.IdPattern = "^[a-z]+$"
+	if err := opt.Validate(); err != nil {
+		t.Fatal(err)
+	}
+
+	opt.IdPattern = "(invalid"
+	if err := opt.Validate(); err == nil {
+		t.Fatal("Expected invalid id pattern error, got nil")
+	}
+
+	opt.IdPattern = ""
+	opt.JsonSchema = types.JsonRaw(`{"properties":{"title":{"pattern":"^[a-z]+$"}}}`)
+	if err := opt.Validate(); err != nil {
+		t.Fatal(err)
+	}
+
+	opt.JsonSchema = types.JsonRaw(`{"properties":{"title":{"pattern":"(invalid"}}}`)
+	if err := opt.Validate(); err == nil {
+		t.Fatal("Expected invalid json schema error, got nil")
+	}
 }
 
 func TestCollectionAuthOptionsValidate(t *testing.T) {
@@ -379,6 +568,41 @@ func TestCollectionAuthOptionsValidate(t *testing.T) {
 			models.CollectionAuthOptions{MinPasswordLength: 73},
 			[]string{"minPasswordLength"},
 		},
+		{
+			"duplicated SearchFields",
+			models.CollectionAuthOptions{MinPasswordLength: 5, SearchFields: []string{"a", "a"}},
+			[]string{"searchFields"},
+		},
+		{
+			"invalid IdPattern",
+			models.CollectionAuthOptions{MinPasswordLength: 5, IdPattern: "(invalid"},
+			[]string{"idPattern"},
+		},
+		{
+			"invalid JsonSchema",
+			models.CollectionAuthOptions{MinPasswordLength: 5, JsonSchema: types.JsonRaw(`{"type":"unknown"}`)},
+			[]string{"jsonSchema"},
+		},
+		{
+			"token durations < 5",
+			models.CollectionAuthOptions{
+				AuthTokenDuration:          4,
+				PasswordResetTokenDuration: -1,
+				VerificationTokenDuration:  4,
+				EmailChangeTokenDuration:   -10,
+			},
+			[]string{"authTokenDuration", "passwordResetTokenDuration", "verificationTokenDuration", "emailChangeTokenDuration"},
+		},
+		{
+			"token durations > 63072000",
+			models.CollectionAuthOptions{
+				AuthTokenDuration:          63072001,
+				PasswordResetTokenDuration: 63072001,
+				VerificationTokenDuration:  63072001,
+				EmailChangeTokenDuration:   63072001,
+			},
+			[]string{"authTokenDuration", "passwordResetTokenDuration", "verificationTokenDuration", "emailChangeTokenDuration"},
+		},
 		{
 			"both OnlyDomains and ExceptDomains set",
 			models.CollectionAuthOptions{
@@ -401,6 +625,27 @@ func TestCollectionAuthOptionsValidate(t *testing.T) {
 			},
 			[]string{},
 		},
+		{
+			"invalid enabled mailer",
+			models.CollectionAuthOptions{
+				Mailer: &models.CollectionMailerOptions{
+					SmtpConfig: settings.SmtpConfig{Enabled: true},
+				},
+			},
+			[]string{"mailer"},
+		},
+		{
+			"invalid TokenClaims",
+			models.CollectionAuthOptions{
+				TokenClaims: map[string]string{
+					"valid_claim":  "name",
+					"invalid-name": "name",
+					"exp":          "name",
+					"empty":        "",
+				},
+			},
+			[]string{"tokenClaims"},
+		},
 		{
 			"all fields with valid data",
 			models.CollectionAuthOptions{
@@ -412,6 +657,23 @@ func TestCollectionAuthOptionsValidate(t *testing.T) {
 				ExceptEmailDomains: []string{"example.com", "test.com"},
 				OnlyEmailDomains:   nil,
 				MinPasswordLength:  5,
+
+				AuthTokenDuration:          5,
+				PasswordResetTokenDuration: 100,
+				VerificationTokenDuration:  63072000,
+				EmailChangeTokenDuration:   1800,
+
+				Mailer: &models.CollectionMailerOptions{
+					SmtpConfig: settings.SmtpConfig{
+						Enabled: true,
+						Host:    "smtp.example.com",
+						Port:    587,
+					},
+					SenderName:    "Acme",
+					SenderAddress: "no-reply@example.com",
+				},
+
+				TokenClaims: map[string]string{"role": "role", "tenant_id": "tenant"},
 			},
 			[]string{},
 		},
@@ -440,6 +702,189 @@ func TestCollectionAuthOptionsValidate(t *testing.T) {
 	}
 }
 
+func TestCollectionMailerOptionsValidate(t *testing.T) {
+	scenarios := []struct {
+		name           string
+		options        models.CollectionMailerOptions
+		expectedErrors []string
+	}{
+		{
+			"empty",
+			models.CollectionMailerOptions{},
+			[]string{},
+		},
+		{
+			"sender only",
+			models.CollectionMailerOptions{
+				SenderName:    "Acme",
+				SenderAddress: "no-reply@example.com",
+			},
+			[]string{},
+		},
+		{
+			"invalid sender address",
+			models.CollectionMailerOptions{
+				SenderAddress: "invalid",
+			},
+			[]string{"senderAddress"},
+		},
+		{
+			"enabled SMTP with missing required fields",
+			models.CollectionMailerOptions{
+				SmtpConfig: settings.SmtpConfig{Enabled: true},
+			},
+			[]string{"host", "port"},
+		},
+		{
+			"enabled SMTP with invalid auth method",
+			models.CollectionMailerOptions{
+				SmtpConfig: settings.SmtpConfig{
+					Enabled:    true,
+					Host:       "smtp.example.com",
+					Port:       587,
+					AuthMethod: "invalid",
+				},
+			},
+			[]string{"authMethod"},
+		},
+		{
+			"enabled SMTP with valid data",
+			models.CollectionMailerOptions{
+				SmtpConfig: settings.SmtpConfig{
+					Enabled:    true,
+					Host:       "smtp.example.com",
+					Port:       587,
+					Username:   "test",
+					Password:   "123456",
+					AuthMethod: mailer.SmtpAuthLogin,
+					Tls:        true,
+				},
+				SenderName:    "Acme",
+				SenderAddress: "no-reply@example.com",
+			},
+			[]string{},
+		},
+	}
+
+	for _, s := range scenarios {
+		result := s.options.Validate()
+
+		// parse errors
+		errs, ok := result.(validation.Errors)
+		if !ok && result != nil {
+			t.Errorf("[%s] Failed to parse errors %v", s.name, result)
+			continue
+		}
+
+		if len(errs) != len(s.expectedErrors) {
+			t.Errorf("[%s] Expected error keys %v, got errors \n%v", s.name, s.expectedErrors, result)
+			continue
+		}
+
+		for key := range errs {
+			if !list.ExistInSlice(key, s.expectedErrors) {
+				t.Errorf("[%s] Unexpected error key %q in \n%v", s.name, key, errs)
+			}
+		}
+	}
+}
+
+func TestCollectionPasswordPolicyValidate(t *testing.T) {
+	scenarios := []struct {
+		name           string
+		policy         models.CollectionPasswordPolicy
+		expectedErrors []string
+	}{
+		{
+			"empty",
+			models.CollectionPasswordPolicy{},
+			[]string{},
+		},
+		{
+			"invalid data",
+			models.CollectionPasswordPolicy{
+				DisallowedPasswords: []string{"test", ""},
+				BreachedApiUrl:      "invalid",
+			},
+			[]string{"disallowedPasswords", "breachedApiUrl"},
+		},
+		{
+			"valid data",
+			models.CollectionPasswordPolicy{
+				RequireLowercase:    true,
+				RequireUppercase:    true,
+				RequireDigit:        true,
+				RequireSymbol:       true,
+				DisallowIdentity:    true,
+				DisallowCommon:      true,
+				DisallowedPasswords: []string{"test"},
+				CheckBreached:       true,
+				BreachedApiUrl:      "https://example.com/range/",
+			},
+			[]string{},
+		},
+	}
+
+	for _, s := range scenarios {
+		result := s.policy.Validate()
+
+		// parse errors
+		errs, ok := result.(validation.Errors)
+		if !ok && result != nil {
+			t.Errorf("[%s] Failed to parse errors %v", s.name, result)
+			continue
+		}
+
+		if len(errs) != len(s.expectedErrors) {
+			t.Errorf("[%s] Expected error keys %v, got errors \n%v", s.name, s.expectedErrors, result)
+			continue
+		}
+
+		for key := range errs {
+			if !list.ExistInSlice(key, s.expectedErrors) {
+				t.Errorf("[%s] Unexpected error key %q in \n%v", s.name, key, errs)
+			}
+		}
+	}
+}
+
+func TestCollectionMailerOptionsPassword(t *testing.T) {
+	const key = "abcdabcdabcdabcdabcdabcdabcdabcd"
+
+	options := models.CollectionMailerOptions{
+		SmtpConfig: settings.SmtpConfig{Password: "123456"},
+	}
+
+	// missing encryption key
+	if err := options.EncryptPassword(""); err != nil {
+		t.Fatal(err)
+	}
+	if options.Password != "123456" {
+		t.Fatalf("Expected the password to be unchanged, got %q", options.Password)
+	}
+
+	// plain password
+	if v := options.DecryptedPassword(key); v != "123456" {
+		t.Fatalf("Expected the plain password to be returned, got %q", v)
+	}
+
+	if err := options.EncryptPassword(key); err != nil {
+		t.Fatal(err)
+	}
+	if options.Password == "" || options.Password == "123456" {
+		t.Fatalf("Expected the password to be encrypted, got %q", options.Password)
+	}
+
+	if v := options.DecryptedPassword(key); v != "123456" {
+		t.Fatalf("Expected the decrypted password %q, got %q", "123456", v)
+	}
+
+	// invalid encryption key
+	if v := options.DecryptedPassword("1234abcdabcdabcdabcdabcdabcdabcd"); v != options.Password {
+		t.Fatalf("Expected the stored password to be returned, got %q", v)
+	}
+}
+
 func TestCollectionViewOptionsValidate(t *testing.T) {
 	scenarios := []struct {
 		name           string
@@ -11,11 +11,11 @@ import (
 	"github.com/pocketbase/dbx"
 	"github.com/spf13/cast"
 	"github.com/unkod/space/models/schema"
+	"github.com/unkod/space/tools/expr"
 	"github.com/unkod/space/tools/list"
 	"github.com/unkod/space/tools/security"
 	"github.com/unkod/space/tools/store"
 	"github.com/unkod/space/tools/types"
-	"golang.org/x/crypto/bcrypt"
 )
 
 var (
@@ -29,12 +29,15 @@ type Record struct {
 
 	collection *Collection
 
-	exportUnknown         bool // whether to export unknown fields
-	ignoreEmailVisibility bool // whether to ignore the emailVisibility flag for auth collections
+	exportUnknown         bool   // whether to export unknown fields
+	ignoreEmailVisibility bool   // whether to ignore the emailVisibility flag for auth collections
+	auditActorType        string // the actor type stored in the record audit logs
+	auditActorId          string // the actor id stored in the record audit logs
 	loaded                bool
-	originalData          map[string]any    // the original (aka. first loaded) model data
-	expand                *store.Store[any] // expanded relations
-	data                  *store.Store[any] // any custom data in addition to the base model fields
+	hiddenFields          map[string]struct{} // schema fields excluded from the export
+	originalData          map[string]any      // the original (aka. first loaded) model data
+	expand                *store.Store[any]   // expanded relations
+	data                  *store.Store[any]   // any custom data in addition to the base model fields
 }
 
 // NewRecord initializes a new empty Record model.
@@ -67,6 +70,9 @@ func NewRecordFromNullStringMap(collection *Collection, data dbx.NullStringMap)
 
 	// load schema fields
 	for _, field := range collection.Schema.Fields() {
+		if field.Type == schema.FieldTypeComputed {
+			continue // no db column
+		}
 		resultMap[field.Name] = nullStringMapValue(data, field.Name)
 	}
 
@@ -82,6 +88,11 @@ func NewRecordFromNullStringMap(collection *Collection, data dbx.NullStringMap)
 		}
 	}
 
+	// load the soft-delete field
+	if collection.HasSoftDelete() {
+		resultMap[schema.FieldNameDeleted] = nullStringMapValue(data, schema.FieldNameDeleted)
+	}
+
 	record := NewRecord(collection)
 
 	record.Load(resultMap)
@@ -279,6 +290,42 @@ func (m *Record) IgnoreEmailVisibility(state bool) {
 	m.ignoreEmailVisibility = state
 }
 
+// SetAuditActor sets the actor that will be stored in the audit logs
+// of the next record changes (applicable only for audited collections).
+func (m *Record) SetAuditActor(actorType string, actorId string) {
+	m.auditActorType = actorType
+	m.auditActorId = actorId
+}
+
+// AuditActor returns the audit logs actor of the current record changes.
+//
+// Defaults to [AuditActorSystem] if no actor was set.
+func (m *Record) AuditActor() (actorType string, actorId string) {
+	if m.auditActorType == "" {
+		return AuditActorSystem, ""
+	}
+
+	return m.auditActorType, m.auditActorId
+}
+
+// HideFields excludes the specified schema fields from the record
+// export/serialization (the field values are still accessible with Get).
+func (m *Record) HideFields(names ...string) {
+	if m.hiddenFields == nil {
+		m.hiddenFields = make(map[string]struct{}, len(names))
+	}
+
+	for _, name := range names {
+		m.hiddenFields[name] = struct{}{}
+	}
+}
+
+// IsFieldHidden reports whether the schema field is excluded from the record export.
+func (m *Record) IsFieldHidden(name string) bool {
+	_, ok := m.hiddenFields[name]
+	return ok
+}
+
 // WithUnknownData toggles the export/serialization of unknown data fields
 // (false by default).
 func (m *Record) WithUnknownData(state bool) {
@@ -289,6 +336,8 @@ func (m *Record) WithUnknownData(state bool) {
 //
 // If the record collection has field with name matching the provided "key",
 // the value will be further normalized according to the field rules.
+//
+// Values for computed fields are ignored.
 func (m *Record) Set(key string, value any) {
 	switch key {
 	case schema.FieldNameId:
@@ -303,16 +352,25 @@ func (m *Record) Set(key string, value any) {
 		var v = value
 
 		if field := m.Collection().Schema.GetFieldByName(key); field != nil {
+			if field.Type == schema.FieldTypeComputed {
+				return // read-only
+			}
 			v = field.PrepareValue(value)
+		} else if key == schema.FieldNameDeleted && m.collection.HasSoftDelete() {
+			v, _ = types.ParseDateTime(value)
 		} else if m.collection.IsAuth() {
 			// normalize auth fields
 			switch key {
-			case schema.FieldNameEmailVisibility, schema.FieldNameVerified:
+			case schema.FieldNameEmailVisibility, schema.FieldNameVerified, schema.FieldNameMFAEnabled:
 				v = cast.ToBool(value)
 			case schema.FieldNameLastResetSentAt, schema.FieldNameLastVerificationSentAt:
 				v, _ = types.ParseDateTime(value)
-			case schema.FieldNameUsername, schema.FieldNameEmail, schema.FieldNameTokenKey, schema.FieldNamePasswordHash:
+			case schema.FieldNameUsername, schema.FieldNameEmail, schema.FieldNameTokenKey, schema.FieldNamePasswordHash, schema.FieldNameMFASecret:
 				v = cast.ToString(value)
+			case schema.FieldNameMFABackupCodes:
+				v = list.ToUniqueStringSlice(value)
+			case schema.FieldNameMFALastCounter:
+				v = cast.ToInt(value)
 			}
 		}
 
@@ -325,6 +383,8 @@ func (m *Record) Set(key string, value any) {
 }
 
 // Get returns a normalized single record model data value for "key".
+//
+// Computed field values are evaluated on each call.
 func (m *Record) Get(key string) any {
 	switch key {
 	case schema.FieldNameId:
@@ -342,15 +402,24 @@ func (m *Record) Get(key string) any {
 		// normalize the field value in case it is missing or an incorrect type was set
 		// to ensure that the DB will always have normalized columns value.
 		if field := m.Collection().Schema.GetFieldByName(key); field != nil {
+			if field.Type == schema.FieldTypeComputed {
+				return m.evalComputedField(field)
+			}
 			v = field.PrepareValue(v)
+		} else if key == schema.FieldNameDeleted && m.collection.HasSoftDelete() {
+			v, _ = types.ParseDateTime(v)
 		} else if m.collection.IsAuth() {
 			switch key {
-			case schema.FieldNameEmailVisibility, schema.FieldNameVerified:
+			case schema.FieldNameEmailVisibility, schema.FieldNameVerified, schema.FieldNameMFAEnabled:
 				v = cast.ToBool(v)
 			case schema.FieldNameLastResetSentAt, schema.FieldNameLastVerificationSentAt:
 				v, _ = types.ParseDateTime(v)
-			case schema.FieldNameUsername, schema.FieldNameEmail, schema.FieldNameTokenKey, schema.FieldNamePasswordHash:
+			case schema.FieldNameUsername, schema.FieldNameEmail, schema.FieldNameTokenKey, schema.FieldNamePasswordHash, schema.FieldNameMFASecret:
 				v = cast.ToString(v)
+			case schema.FieldNameMFABackupCodes:
+				v = list.ToUniqueStringSlice(v)
+			case schema.FieldNameMFALastCounter:
+				v = cast.ToInt(v)
 			}
 		}
 
@@ -389,6 +458,13 @@ func (m *Record) GetDateTime(key string) types.DateTime {
 	return d
 }
 
+// GetGeoPoint returns the data value for "key" as a GeoPoint instance.
+func (m *Record) GetGeoPoint(key string) types.GeoPoint {
+	p := types.GeoPoint{}
+	p.Scan(m.Get(key))
+	return p
+}
+
 // GetStringSlice returns the data value for "key" as a slice of unique strings.
 func (m *Record) GetStringSlice(key string) []string {
 	return list.ToUniqueStringSlice(m.Get(key))
@@ -493,6 +569,9 @@ func (m *Record) ColumnValueMap() map[string]any {
 
 	// export schema field values
 	for _, field := range m.collection.Schema.Fields() {
+		if field.Type == schema.FieldTypeComputed {
+			continue // no db column
+		}
 		result[field.Name] = m.getNormalizeDataValueForDB(field.Name)
 	}
 
@@ -503,6 +582,11 @@ func (m *Record) ColumnValueMap() map[string]any {
 		}
 	}
 
+	// export the soft-delete field
+	if m.collection.HasSoftDelete() {
+		result[schema.FieldNameDeleted] = m.getNormalizeDataValueForDB(schema.FieldNameDeleted)
+	}
+
 	// export base model fields
 	result[schema.FieldNameId] = m.getNormalizeDataValueForDB(schema.FieldNameId)
 	result[schema.FieldNameCreated] = m.getNormalizeDataValueForDB(schema.FieldNameCreated)
@@ -527,6 +611,10 @@ func (m *Record) PublicExport() map[string]any {
 
 	// export schema field values
 	for _, field := range m.collection.Schema.Fields() {
+		if m.IsFieldHidden(field.Name) {
+			continue
+		}
+
 		result[field.Name] = m.Get(field.Name)
 	}
 
@@ -540,6 +628,11 @@ func (m *Record) PublicExport() map[string]any {
 		}
 	}
 
+	// export the soft-delete field
+	if m.collection.HasSoftDelete() {
+		result[schema.FieldNameDeleted] = m.Deleted()
+	}
+
 	// export base model fields
 	result[schema.FieldNameId] = m.GetId()
 	if created := m.GetCreated(); !m.Collection().IsView() || !created.IsZero() {
@@ -683,12 +776,16 @@ func (m *Record) getNormalizeDataValueForDB(key string) any {
 	// normalize auth fields
 	if m.collection.IsAuth() {
 		switch key {
-		case schema.FieldNameEmailVisibility, schema.FieldNameVerified:
+		case schema.FieldNameEmailVisibility, schema.FieldNameVerified, schema.FieldNameMFAEnabled:
 			return m.GetBool(key)
 		case schema.FieldNameLastResetSentAt, schema.FieldNameLastVerificationSentAt:
 			return m.GetDateTime(key)
-		case schema.FieldNameUsername, schema.FieldNameEmail, schema.FieldNameTokenKey, schema.FieldNamePasswordHash:
+		case schema.FieldNameUsername, schema.FieldNameEmail, schema.FieldNameTokenKey, schema.FieldNamePasswordHash, schema.FieldNameMFASecret:
 			return m.GetString(key)
+		case schema.FieldNameMFABackupCodes:
+			return append(types.JsonArray[string]{}, m.GetStringSlice(key)...)
+		case schema.FieldNameMFALastCounter:
+			return m.GetInt(key)
 		}
 	}
 
@@ -713,6 +810,42 @@ func (m *Record) getNormalizeDataValueForDB(key string) any {
 	}
 }
 
+// evalComputedField evaluates the expression of the provided computed field.
+//
+// Only the base model and the regular schema fields are available
+// as expression variables.
+//
+// Returns nil on evaluation error (eg. division by zero).
+func (m *Record) evalComputedField(field *schema.SchemaField) any {
+	field.InitOptions()
+
+	options, _ := field.Options.(*schema.ComputedOptions)
+	if options == nil {
+		return nil
+	}
+
+	e, err := expr.Parse(options.Expression)
+	if err != nil {
+		return nil
+	}
+
+	vars := map[string]any{}
+	for _, name := range e.Identifiers() {
+		if list.ExistInSlice(name, schema.BaseModelFieldNames()) {
+			vars[name] = m.Get(name)
+		} else if f := m.collection.Schema.GetFieldByName(name); f != nil && f.Type != schema.FieldTypeComputed {
+			vars[name] = m.Get(name)
+		}
+	}
+
+	result, err := e.Eval(vars)
+	if err != nil {
+		return nil
+	}
+
+	return result
+}
+
 // shallowCopy shallow copy data into a new map.
 func shallowCopy(data map[string]any) map[string]any {
 	result := make(map[string]any, len(data))
@@ -744,6 +877,10 @@ func (m *Record) extractUnknownData(data map[string]any) map[string]any {
 		}
 	}
 
+	if m.collection.HasSoftDelete() {
+		knownFields[schema.FieldNameDeleted] = struct{}{}
+	}
+
 	result := map[string]any{}
 
 	for k, v := range data {
@@ -755,6 +892,36 @@ func (m *Record) extractUnknownData(data map[string]any) map[string]any {
 	return result
 }
 
+// -------------------------------------------------------------------
+// Soft-delete helpers
+// -------------------------------------------------------------------
+
+var notSoftDeleteRecordErr = errors.New("Not a soft-delete collection record.")
+
+// Deleted returns the "deleted" soft-delete record data value
+// (the zero DateTime for not soft-deleted records).
+func (m *Record) Deleted() types.DateTime {
+	return m.GetDateTime(schema.FieldNameDeleted)
+}
+
+// SetDeleted sets the "deleted" soft-delete record data value.
+//
+// Returns an error if the record collection doesn't have soft-delete enabled.
+func (m *Record) SetDeleted(dateTime types.DateTime) error {
+	if !m.collection.HasSoftDelete() {
+		return notSoftDeleteRecordErr
+	}
+
+	m.Set(schema.FieldNameDeleted, dateTime)
+
+	return nil
+}
+
+// IsSoftDeleted checks whether the record is marked as soft-deleted.
+func (m *Record) IsSoftDeleted() bool {
+	return m.collection.HasSoftDelete() && !m.Deleted().IsZero()
+}
+
 // -------------------------------------------------------------------
 // Auth helpers
 // -------------------------------------------------------------------
@@ -898,6 +1065,81 @@ func (m *Record) SetLastVerificationSentAt(dateTime types.DateTime) error {
 	return nil
 }
 
+// MFASecret returns the "mfaSecret" auth record data value
+// (aka. the encrypted TOTP secret).
+func (m *Record) MFASecret() string {
+	return m.GetString(schema.FieldNameMFASecret)
+}
+
+// SetMFASecret sets the "mfaSecret" auth record data value.
+//
+// Returns an error if the record is not from an auth collection.
+func (m *Record) SetMFASecret(secret string) error {
+	if !m.collection.IsAuth() {
+		return notAuthRecordErr
+	}
+
+	m.Set(schema.FieldNameMFASecret, secret)
+
+	return nil
+}
+
+// MFAEnabled returns the "mfaEnabled" auth record data value.
+func (m *Record) MFAEnabled() bool {
+	return m.GetBool(schema.FieldNameMFAEnabled)
+}
+
+// SetMFAEnabled sets the "mfaEnabled" auth record data value.
+//
+// Returns an error if the record is not from an auth collection.
+func (m *Record) SetMFAEnabled(enabled bool) error {
+	if !m.collection.IsAuth() {
+		return notAuthRecordErr
+	}
+
+	m.Set(schema.FieldNameMFAEnabled, enabled)
+
+	return nil
+}
+
+// MFABackupCodes returns the "mfaBackupCodes" auth record data value
+// (aka. the hashed MFA backup codes).
+func (m *Record) MFABackupCodes() []string {
+	return m.GetStringSlice(schema.FieldNameMFABackupCodes)
+}
+
+// SetMFABackupCodes sets the "mfaBackupCodes" auth record data value.
+//
+// Returns an error if the record is not from an auth collection.
+func (m *Record) SetMFABackupCodes(hashedCodes []string) error {
+	if !m.collection.IsAuth() {
+		return notAuthRecordErr
+	}
+
+	m.Set(schema.FieldNameMFABackupCodes, hashedCodes)
+
+	return nil
+}
+
+// MFALastCounter returns the "mfaLastCounter" auth record data value
+// (aka. the time step counter of the last accepted TOTP code).
+func (m *Record) MFALastCounter() int {
+	return m.GetInt(schema.FieldNameMFALastCounter)
+}
+
+// SetMFALastCounter sets the "mfaLastCounter" auth record data value.
+//
+// Returns an error if the record is not from an auth collection.
+func (m *Record) SetMFALastCounter(counter int) error {
+	if !m.collection.IsAuth() {
+		return notAuthRecordErr
+	}
+
+	m.Set(schema.FieldNameMFALastCounter, counter)
+
+	return nil
+}
+
 // PasswordHash returns the "passwordHash" auth record data value.
 func (m *Record) PasswordHash() string {
 	return m.GetString(schema.FieldNamePasswordHash)
@@ -911,17 +1153,24 @@ func (m *Record) ValidatePassword(password string) bool {
 		return false
 	}
 
-	err := bcrypt.CompareHashAndPassword([]byte(m.PasswordHash()), []byte(password))
-
-	return err == nil
+	return security.ComparePassword(m.PasswordHash(), password)
 }
 
 // SetPassword sets cryptographically secure string to the auth record "password" field.
 // This method also resets the "lastResetSentAt" and the "tokenKey" fields.
 //
+// The password is hashed with the default [security.PasswordHashOptions]
+// (use [Record.SetPasswordWithOptions] to specify different ones).
+//
 // Returns an error if the record is not from an auth collection or
 // an empty password is provided.
 func (m *Record) SetPassword(password string) error {
+	return m.SetPasswordWithOptions(password, security.DefaultPasswordHashOptions())
+}
+
+// SetPasswordWithOptions is similar to [Record.SetPassword] but hashes
+// the password with the provided hashing options.
+func (m *Record) SetPasswordWithOptions(password string, opts security.PasswordHashOptions) error {
 	if !m.collection.IsAuth() {
 		return notAuthRecordErr
 	}
@@ -931,12 +1180,12 @@ func (m *Record) SetPassword(password string) error {
 	}
 
 	// hash the password
-	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(password), 12)
+	hashedPassword, err := security.HashPassword(password, opts)
 	if err != nil {
 		return err
 	}
 
-	m.Set(schema.FieldNamePasswordHash, string(hashedPassword))
+	m.Set(schema.FieldNamePasswordHash, hashedPassword)
 	m.Set(schema.FieldNameLastResetSentAt, types.DateTime{})
 
 	// invalidate previously issued tokens
@@ -989,6 +989,107 @@ func TestRecordGetDateTime(t *testing.T) {
 	}
 }
 
+func TestRecordGetGeoPoint(t *testing.T) {
+	scenarios := []struct {
+		value    any
+		expected types.GeoPoint
+	}{
+		{nil, types.GeoPoint{}},
+		{"", types.GeoPoint{}},
+		{"test", types.GeoPoint{}},
+		{123, types.GeoPoint{}},
+		{`{"lon":1.5,"lat":-2}`, types.GeoPoint{Lon: 1.5, Lat: -2}},
+		{map[string]any{"lat": 3}, types.GeoPoint{Lat: 3}},
+		{types.GeoPoint{Lon: 4, Lat: 5}, types.GeoPoint{Lon: 4, Lat: 5}},
+	}
+
+	collection := &models.Collection{}
+
+	for i, s := range scenarios {
+		m := models.NewRecord(collection)
+		m.Set("test", s.value)
+
+		result := m.GetGeoPoint("test")
+		if result != s.expected {
+			t.Errorf("(%d) Expected %v, got %v", i, s.expected, result)
+		}
+	}
+}
+
+func TestRecordComputedField(t *testing.T) {
+	collection := &models.Collection{
+		Schema: schema.NewSchema(
+			&schema.SchemaField{Name: "title", Type: schema.FieldTypeText},
+			&schema.SchemaField{Name: "price", Type: schema.FieldTypeNumber},
+			&schema.SchemaField{Name: "qty", Type: schema.FieldTypeNumber},
+			&schema.SchemaField{
+				Name:    "total",
+				Type:    schema.FieldTypeComputed,
+				Options: &schema.ComputedOptions{Expression: "price * qty"},
+			},
+			&schema.SchemaField{
+				Name:    "label",
+				Type:    schema.FieldTypeComputed,
+				Options: &schema.ComputedOptions{Expression: "id + ': ' + title + ' x' + qty"},
+			},
+			&schema.SchemaField{
+				Name:    "ratio",
+				Type:    schema.FieldTypeComputed,
+				Options: &schema.ComputedOptions{Expression: "price / qty"},
+			},
+			&schema.SchemaField{
+				Name:    "nested",
+				Type:    schema.FieldTypeComputed,
+				Options: &schema.ComputedOptions{Expression: "total + 1"},
+			},
+		),
+	}
+
+	m := models.NewRecord(collection)
+	m.Id = "test_id"
+	m.Set("title", "Box")
+	m.Set("price", 2.5)
+	m.Set("qty", 4)
+	m.Set("total", 123) // should be ignored
+
+	if v := m.Get("total"); v != 10.0 {
+		t.Fatalf("Expected total 10, got %v", v)
+	}
+
+	if v := m.Get("label"); v != "test_id: Box x4" {
+		t.Fatalf("Expected label %q, got %v", "test_id: Box x4", v)
+	}
+
+	// computed fields can't reference other computed fields
+	if v := m.Get("nested"); v != 1.0 {
+		t.Fatalf("Expected nested 1, got %v", v)
+	}
+
+	// evaluation errors result in nil value
+	m.Set("qty", 0)
+	if v := m.Get("ratio"); v != nil {
+		t.Fatalf("Expected nil ratio, got %v", v)
+	}
+
+	// no db columns
+	columns := m.ColumnValueMap()
+	for _, name := range []string{"total", "label", "ratio", "nested"} {
+		if _, ok := columns[name]; ok {
+			t.Fatalf("Didn't expect column %q in %v", name, columns)
+		}
+	}
+
+	// exported
+	raw, err := json.Marshal(m)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := `{"collectionId":"","collectionName":"","created":"","id":"test_id","label":"test_id: Box x0","nested":1,"price":2.5,"qty":0,"ratio":null,"title":"Box","total":0,"updated":""}`
+	if string(raw) != expected {
+		t.Fatalf("Expected \n%s, \ngot \n%s", expected, raw)
+	}
+}
+
 func TestRecordGetStringSlice(t *testing.T) {
 	nowTime := time.Now()
 
@@ -1274,7 +1375,7 @@ func TestRecordColumnValueMap(t *testing.T) {
 		},
 		{
 			models.CollectionTypeAuth,
-			`{"created":"2022-01-01 10:00:30.123Z","email":"test_email","emailVisibility":true,"field1":"test","field2":"test.png","field3":["test1","test2"],"field4":["test11","test12"],"id":"test_id","lastResetSentAt":"2022-01-02 10:00:30.123Z","lastVerificationSentAt":"","passwordHash":"test_passwordHash","tokenKey":"test_tokenKey","updated":"","username":"test_username","verified":false}`,
+			`{"created":"2022-01-01 10:00:30.123Z","email":"test_email","emailVisibility":true,"field1":"test","field2":"test.png","field3":["test1","test2"],"field4":["test11","test12"],"id":"test_id","lastResetSentAt":"2022-01-02 10:00:30.123Z","lastVerificationSentAt":"","mfaBackupCodes":[],"mfaEnabled":false,"mfaLastCounter":0,"mfaSecret":"","passwordHash":"test_passwordHash","tokenKey":"test_tokenKey","updated":"","username":"test_username","verified":false}`,
 		},
 	}
 
@@ -1903,6 +2004,53 @@ func TestRecordRefreshTokenKey(t *testing.T) {
 	}
 }
 
+func TestRecordMFA(t *testing.T) {
+	scenarios := []struct {
+		collectionType string
+		expectError    bool
+	}{
+		{models.CollectionTypeBase, true},
+		{models.CollectionTypeAuth, false},
+	}
+
+	for i, s := range scenarios {
+		collection := &models.Collection{Type: s.collectionType}
+		m := models.NewRecord(collection)
+
+		secretErr := m.SetMFASecret("test")
+		enabledErr := m.SetMFAEnabled(true)
+		codesErr := m.SetMFABackupCodes([]string{"a", "b", "a"})
+
+		if s.expectError {
+			if secretErr == nil || enabledErr == nil || codesErr == nil {
+				t.Fatalf("(%d) Expected errors, got %v, %v, %v", i, secretErr, enabledErr, codesErr)
+			}
+			if m.MFASecret() != "" || m.MFAEnabled() || len(m.MFABackupCodes()) != 0 {
+				t.Fatalf("(%d) Expected empty MFA fields, got %q, %v, %v", i, m.MFASecret(), m.MFAEnabled(), m.MFABackupCodes())
+			}
+			// verify that nothing is stored in the record data slice
+			for _, name := range []string{schema.FieldNameMFASecret, schema.FieldNameMFAEnabled, schema.FieldNameMFABackupCodes} {
+				if v := m.Get(name); v != nil {
+					t.Fatalf("(%d) Didn't expect data field %q: %v", i, name, v)
+				}
+			}
+		} else {
+			if secretErr != nil || enabledErr != nil || codesErr != nil {
+				t.Fatalf("(%d) Expected nil errors, got %v, %v, %v", i, secretErr, enabledErr, codesErr)
+			}
+			if v := m.MFASecret(); v != "test" {
+				t.Fatalf("(%d) Expected secret %q, got %q", i, "test", v)
+			}
+			if !m.MFAEnabled() {
+				t.Fatalf("(%d) Expected MFA to be enabled", i)
+			}
+			if v := m.MFABackupCodes(); len(v) != 2 || v[0] != "a" || v[1] != "b" {
+				t.Fatalf("(%d) Expected unique backup codes [a b], got %v", i, v)
+			}
+		}
+	}
+}
+
 func TestRecordLastResetSentAt(t *testing.T) {
 	scenarios := []struct {
 		collectionType string
@@ -2069,3 +2217,104 @@ func TestRecordSetPassword(t *testing.T) {
 		}
 	}
 }
+
+func TestRecordSoftDelete(t *testing.T) {
+	scenarios := []struct {
+		collection  *models.Collection
+		expectError bool
+	}{
+		{&models.Collection{Type: models.CollectionTypeBase}, true},
+		{&models.Collection{Type: models.CollectionTypeBase, Options: types.JsonMap{"softDelete": true}}, false},
+		{&models.Collection{Type: models.CollectionTypeAuth, Options: types.JsonMap{"softDelete": true}}, false},
+	}
+
+	for i, s := range scenarios {
+		m := models.NewRecord(s.collection)
+
+		if m.IsSoftDeleted() {
+			t.Fatalf("(%d) Expected the new record to not be soft-deleted", i)
+		}
+
+		now := types.NowDateTime()
+
+		err := m.SetDeleted(now)
+
+		if s.expectError {
+			if err == nil {
+				t.Fatalf("(%d) Expected error, got nil", i)
+			}
+			if m.IsSoftDeleted() {
+				t.Fatalf("(%d) Expected the record to not be soft-deleted", i)
+			}
+			if _, ok := m.PublicExport()[schema.FieldNameDeleted]; ok {
+				t.Fatalf("(%d) Didn't expect the deleted field to be exported", i)
+			}
+			continue
+		}
+
+		if err != nil {
+			t.Fatalf("(%d) Expected nil, got %v", i, err)
+		}
+		if !m.IsSoftDeleted() || m.Deleted().String() != now.String() {
+			t.Fatalf("(%d) Expected deleted %q, got %q", i, now.String(), m.Deleted().String())
+		}
+		if v := m.ColumnValueMap()[schema.FieldNameDeleted]; v != now {
+			t.Fatalf("(%d) Expected the deleted column value %v, got %v", i, now, v)
+		}
+		if v := m.PublicExport()[schema.FieldNameDeleted]; v != now {
+			t.Fatalf("(%d) Expected the exported deleted value %v, got %v", i, now, v)
+		}
+
+		if err := m.SetDeleted(types.DateTime{}); err != nil || m.IsSoftDeleted() {
+			t.Fatalf("(%d) Expected the record to be restored, got %v", i, err)
+		}
+	}
+}
+
+func TestRecordHideFields(t *testing.T) {
+	collection := &models.Collection{
+		Name: "c_name",
+		Schema: schema.NewSchema(
+			&schema.SchemaField{Name: "field1", Type: schema.FieldTypeText},
+			&schema.SchemaField{Name: "field2", Type: schema.FieldTypeText},
+			&schema.SchemaField{Name: "field3", Type: schema.FieldTypeText},
+		),
+	}
+	collection.Id = "c_id"
+
+	m := models.NewRecord(collection)
+	m.Id = "test_id"
+	m.Set("field1", "a")
+	m.Set("field2", "b")
+	m.Set("field3", "c")
+
+	if m.IsFieldHidden("field1") {
+		t.Fatal("Expected field1 to not be hidden by default")
+	}
+
+	m.HideFields("field1", "field3", "missing")
+
+	if !m.IsFieldHidden("field1") || m.IsFieldHidden("field2") || !m.IsFieldHidden("field3") {
+		t.Fatal("Expected only field1 and field3 to be hidden")
+	}
+
+	raw, err := json.Marshal(m)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `{"collectionId":"c_id","collectionName":"c_name","created":"","field2":"b","id":"test_id","updated":""}`
+	if string(raw) != expected {
+		t.Fatalf("Expected %s, got %s", expected, raw)
+	}
+
+	// the hidden fields values should be still accessible
+	if v := m.GetString("field1"); v != "a" {
+		t.Fatalf("Expected field1 value %q, got %q", "a", v)
+	}
+
+	// clean copies should have no hidden fields
+	if m.CleanCopy().IsFieldHidden("field1") {
+		t.Fatal("Expected the clean copy fields to not be hidden")
+	}
+}
@@ -3,12 +3,16 @@ package schema
 import (
 	"encoding/json"
 	"errors"
+	"fmt"
 	"regexp"
 	"strconv"
+	"strings"
 
 	validation "github.com/go-ozzo/ozzo-validation/v4"
 	"github.com/go-ozzo/ozzo-validation/v4/is"
+	"github.com/google/uuid"
 	"github.com/spf13/cast"
+	"github.com/unkod/space/tools/expr"
 	"github.com/unkod/space/tools/filesystem"
 	"github.com/unkod/space/tools/list"
 	"github.com/unkod/space/tools/types"
@@ -46,6 +50,11 @@ const (
 	FieldNamePasswordHash           string = "passwordHash"
 	FieldNameLastResetSentAt        string = "lastResetSentAt"
 	FieldNameLastVerificationSentAt string = "lastVerificationSentAt"
+	FieldNameMFASecret              string = "mfaSecret"
+	FieldNameMFAEnabled             string = "mfaEnabled"
+	FieldNameMFABackupCodes         string = "mfaBackupCodes"
+	FieldNameMFALastCounter         string = "mfaLastCounter"
+	FieldNameDeleted                string = "deleted"
 )
 
 // BaseModelFieldNames returns the field names that all models have (id, created, updated).
@@ -77,6 +86,10 @@ func AuthFieldNames() []string {
 		FieldNamePasswordHash,
 		FieldNameLastResetSentAt,
 		FieldNameLastVerificationSentAt,
+		FieldNameMFASecret,
+		FieldNameMFAEnabled,
+		FieldNameMFABackupCodes,
+		FieldNameMFALastCounter,
 	}
 }
 
@@ -93,6 +106,8 @@ const (
 	FieldTypeJson     string = "json"
 	FieldTypeFile     string = "file"
 	FieldTypeRelation string = "relation"
+	FieldTypeGeoPoint string = "geoPoint"
+	FieldTypeComputed string = "computed"
 
 	// Deprecated: Will be removed in v0.9+
 	FieldTypeUser string = "user"
@@ -112,6 +127,8 @@ func FieldTypes() []string {
 		FieldTypeJson,
 		FieldTypeFile,
 		FieldTypeRelation,
+		FieldTypeGeoPoint,
+		FieldTypeComputed,
 	}
 }
 
@@ -140,6 +157,22 @@ type SchemaField struct {
 	// Please use the collection.Indexes field to define a unique constraint.
 	Unique bool `form:"unique" json:"unique"`
 
+	// Default is an optional expression evaluated on record create
+	// when the field value is not submitted (see [SchemaField.ParseDefault]).
+	Default string `form:"default" json:"default,omitempty"`
+
+	// ReadRule is an optional filter expression that the request must
+	// satisfy for the field to be included in the record API responses.
+	//
+	// WriteRule is an optional filter expression that the request must
+	// satisfy to change the field value with the record API create/update.
+	//
+	// Both rules are evaluated against the record and the request info
+	// in the same way as the collection API rules (admins always pass them).
+	// An empty rule means that the field is not restricted.
+	ReadRule  string `form:"readRule" json:"readRule,omitempty"`
+	WriteRule string `form:"writeRule" json:"writeRule,omitempty"`
+
 	Options any `form:"options" json:"options"`
 }
 
@@ -152,6 +185,8 @@ func (f *SchemaField) ColDefinition() string {
 		return "BOOLEAN DEFAULT FALSE NOT NULL"
 	case FieldTypeJson:
 		return "JSON DEFAULT NULL"
+	case FieldTypeGeoPoint:
+		return `JSON DEFAULT '{"lon":0,"lat":0}' NOT NULL`
 	default:
 		if opt, ok := f.Options.(MultiValuer); ok && opt.IsMultiple() {
 			return "JSON DEFAULT '[]' NOT NULL"
@@ -216,9 +251,112 @@ func (f SchemaField) Validate() error {
 		// currently file fields cannot be unique because a proper
 		// hash/content check could cause performance issues
 		validation.Field(&f.Unique, validation.When(f.Type == FieldTypeFile, validation.Empty)),
+		validation.Field(
+			&f.Default,
+			validation.When(f.Type == FieldTypeFile || f.Type == FieldTypeComputed, validation.Empty),
+			validation.By(f.checkDefault),
+		),
+		validation.Field(&f.ReadRule, validation.Length(0, 1000)),
+		validation.Field(&f.WriteRule, validation.Length(0, 1000), validation.When(f.Type == FieldTypeComputed, validation.Empty)),
 	)
 }
 
+func (f *SchemaField) checkDefault(value any) error {
+	v, _ := value.(string)
+	if v == "" {
+		return nil // nothing to check
+	}
+
+	if _, err := f.ParseDefault(); err != nil {
+		return validation.NewError("validation_invalid_default", err.Error())
+	}
+
+	return nil
+}
+
+// DefaultRequestIdentifiers returns the special request identifiers
+// (or identifier prefixes when ending with ".") that could be used
+// in the field default value expressions.
+func DefaultRequestIdentifiers() []string {
+	return []string{
+		"@now",
+		"@request.method",
+		"@request.auth.",
+		"@request.query.",
+		"@request.headers.",
+		"@request.data.",
+	}
+}
+
+// defaultFunctions lists the functions available in the field default value expressions.
+var defaultFunctions = map[string]expr.Func{
+	"now": func() (any, error) {
+		return types.NowDateTime(), nil
+	},
+	"uuid": func() (any, error) {
+		return uuid.NewString(), nil
+	},
+}
+
+// ParseDefault parses the field default value expression.
+//
+// In addition to the [expr] package syntax, the expression
+// could use the [DefaultRequestIdentifiers] and the "now()"
+// and "uuid()" functions, eg.:
+//
+//	@request.auth.id
+//	'draft_' + uuid()
+//	now()
+//
+// The "@request.auth.*" identifiers are limited to the auth record
+// direct fields with the exception of the auth secrets (eg. tokenKey).
+func (f *SchemaField) ParseDefault() (*expr.Expr, error) {
+	e, err := expr.ParseWithFunctions(f.Default, defaultFunctions)
+	if err != nil {
+		return nil, err
+	}
+
+	secrets := []string{
+		FieldNameTokenKey,
+		FieldNamePasswordHash,
+		FieldNameMFASecret,
+		FieldNameMFABackupCodes,
+		FieldNameMFALastCounter,
+		FieldNameLastResetSentAt,
+		FieldNameLastVerificationSentAt,
+	}
+
+	for _, identifier := range e.Identifiers() {
+		if !isDefaultRequestIdentifier(identifier) {
+			return nil, fmt.Errorf("unknown identifier %q", identifier)
+		}
+
+		if name, ok := strings.CutPrefix(identifier, "@request.auth."); ok && list.ExistInSlice(name, secrets) {
+			return nil, fmt.Errorf("%q is not allowed in default expressions", identifier)
+		}
+	}
+
+	return e, nil
+}
+
+func isDefaultRequestIdentifier(identifier string) bool {
+	for _, allowed := range DefaultRequestIdentifiers() {
+		if !strings.HasSuffix(allowed, ".") {
+			if identifier == allowed {
+				return true
+			}
+			continue
+		}
+
+		// only direct keys (eg. "@request.auth.id" but not "@request.auth.rel.id")
+		if name, ok := strings.CutPrefix(identifier, allowed); ok && schemaFieldNameRegex.MatchString(name) {
+			return true
+		}
+	}
+
+	return false
+}
+
 func (f *SchemaField) checkOptions(value any) error {
 	v, ok := value.(FieldOptions)
 	if !ok {
@@ -265,6 +403,10 @@ func (f *SchemaField) InitOptions() error {
 		options = &FileOptions{}
 	case FieldTypeRelation:
 		options = &RelationOptions{}
+	case FieldTypeGeoPoint:
+		options = &GeoPointOptions{}
+	case FieldTypeComputed:
+		options = &ComputedOptions{}
 
 	// Deprecated: Will be removed in v0.9+
 	case FieldTypeUser:
@@ -365,6 +507,10 @@ func (f *SchemaField) PrepareValue(value any) any {
 		}
 
 		return ids
+	case FieldTypeGeoPoint:
+		val := types.GeoPoint{}
+		val.Scan(value)
+		return val
 	default:
 		return value // unmodified
 	}
@@ -416,15 +562,31 @@ type MultiValuer interface {
 	IsMultiple() bool
 }
 
+// Encryptable defines common interface methods that every field option
+// struct with optional encryption at rest support has.
+type Encryptable interface {
+	IsEncrypted() bool
+}
+
 // FieldOptions defines common interface methods that every field option struct has.
 type FieldOptions interface {
 	Validate() error
 }
 
+var _ Encryptable = (*TextOptions)(nil)
+
 type TextOptions struct {
 	Min     *int   `form:"min" json:"min"`
 	Max     *int   `form:"max" json:"max"`
 	Pattern string `form:"pattern" json:"pattern"`
+
+	// Encrypted indicates whether the field values should be stored
+	// encrypted with the app encryption key.
+	//
+	// The text values are encrypted deterministically to allow
+	// exact-match filtering (aka. the same value always results in
+	// the same cipher text and could be compared for equality).
+	Encrypted bool `form:"encrypted" json:"encrypted"`
 }
 
 func (o TextOptions) Validate() error {
@@ -453,6 +615,12 @@ func (o *TextOptions) checkRegex(value any) error {
 	return nil
 }
 
+// IsEncrypted implements Encryptable interface and checks whether the
+// field values should be stored encrypted.
+func (o TextOptions) IsEncrypted() bool {
+	return o.Encrypted
+}
+
 // -------------------------------------------------------------------
 
 type NumberOptions struct {
@@ -595,13 +763,66 @@ func (o SelectOptions) IsMultiple() bool {
 
 // -------------------------------------------------------------------
 
+var _ Encryptable = (*JsonOptions)(nil)
+
 type JsonOptions struct {
+	// Encrypted indicates whether the field values should be stored
+	// encrypted with the app encryption key.
+	//
+	// Encrypted json fields can't be used in filters or sort expressions.
+	Encrypted bool `form:"encrypted" json:"encrypted"`
 }
 
 func (o JsonOptions) Validate() error {
 	return nil
 }
 
+// IsEncrypted implements Encryptable interface and checks whether the
+// field values should be stored encrypted.
+func (o JsonOptions) IsEncrypted() bool {
+	return o.Encrypted
+}
+
+// -------------------------------------------------------------------
+
+type GeoPointOptions struct {
+}
+
+func (o GeoPointOptions) Validate() error {
+	return nil
+}
+
+// -------------------------------------------------------------------
+
+// ComputedOptions defines the options of a read-only field whose value
+// is evaluated on read from the other record fields
+// (see the [expr] package for the supported expression syntax).
+//
+// Computed fields don't have a db column and can't be
+// used in filter or sort expressions.
+type ComputedOptions struct {
+	Expression string `form:"expression" json:"expression"`
+}
+
+func (o ComputedOptions) Validate() error {
+	return validation.ValidateStruct(&o,
+		validation.Field(&o.Expression, validation.Required, validation.By(o.checkExpression)),
+	)
+}
+
+func (o *ComputedOptions) checkExpression(value any) error {
+	v, _ := value.(string)
+	if v == "" {
+		return nil // nothing to check
+	}
+
+	if _, err := expr.Parse(v); err != nil {
+		return validation.NewError("validation_invalid_expression", err.Error())
+	}
+
+	return nil
+}
+
 // -------------------------------------------------------------------
 
 var _ MultiValuer = (*FileOptions)(nil)
@@ -31,7 +31,7 @@ func TestSystemFieldNames(t *testing.T) {
 
 func TestAuthFieldNames(t *testing.T) {
 	result := schema.AuthFieldNames()
-	expected := 8
+	expected := 12
 
 	if len(result) != expected {
 		t.Fatalf("Expected %d auth field names, got %d (%v)", expected, len(result), result)
@@ -40,7 +40,7 @@ func TestAuthFieldNames(t *testing.T) {
 
 func TestFieldTypes(t *testing.T) {
 	result := schema.FieldTypes()
-	expected := 11
+	expected := 13
 
 	if len(result) != expected {
 		t.Fatalf("Expected %d types, got %d (%v)", expected, len(result), result)
@@ -117,6 +117,10 @@ func TestSchemaFieldColDefinition(t *testing.T) {
 			schema.SchemaField{Type: schema.FieldTypeRelation, Name: "test_multiple", Options: &schema.RelationOptions{MaxSelect: nil}},
 			"JSON DEFAULT '[]' NOT NULL",
 		},
+		{
+			schema.SchemaField{Type: schema.FieldTypeGeoPoint, Name: "test"},
+			`JSON DEFAULT '{"lon":0,"lat":0}' NOT NULL`,
+		},
 	}
 
 	for i, s := range scenarios {
@@ -141,7 +145,7 @@ func TestSchemaFieldString(t *testing.T) {
 	}
 
 	result := f.String()
-	expected := `{"system":true,"id":"abc","name":"test","type":"text","required":true,"presentable":true,"unique":false,"options":{"min":null,"max":null,"pattern":"test"}}`
+	expected := `{"system":true,"id":"abc","name":"test","type":"text","required":true,"presentable":true,"unique":false,"options":{"min":null,"max":null,"pattern":"test","encrypted":false}}`
 
 	if result != expected {
 		t.Errorf("Expected \n%v, got \n%v", expected, result)
@@ -168,7 +172,7 @@ func TestSchemaFieldMarshalJSON(t *testing.T) {
 				Presentable: true,
 				System:      true,
 			},
-			`{"system":true,"id":"abc","name":"test","type":"text","required":true,"presentable":true,"unique":false,"options":{"min":null,"max":null,"pattern":""}}`,
+			`{"system":true,"id":"abc","name":"test","type":"text","required":true,"presentable":true,"unique":false,"options":{"min":null,"max":null,"pattern":"","encrypted":false}}`,
 		},
 		// with defined options
 		{
@@ -182,7 +186,7 @@ func TestSchemaFieldMarshalJSON(t *testing.T) {
 					Pattern: "test",
 				},
 			},
-			`{"system":true,"id":"","name":"test","type":"text","required":true,"presentable":false,"unique":false,"options":{"min":null,"max":null,"pattern":"test"}}`,
+			`{"system":true,"id":"","name":"test","type":"text","required":true,"presentable":false,"unique":false,"options":{"min":null,"max":null,"pattern":"test","encrypted":false}}`,
 		},
 	}
 
@@ -227,12 +231,12 @@ func TestSchemaFieldUnmarshalJSON(t *testing.T) {
 		{
 			[]byte(`{"type":"text","system":true}`),
 			false,
-			`{"system":true,"id":"","name":"","type":"text","required":false,"presentable":false,"unique":false,"options":{"min":null,"max":null,"pattern":""}}`,
+			`{"system":true,"id":"","name":"","type":"text","required":false,"presentable":false,"unique":false,"options":{"min":null,"max":null,"pattern":"","encrypted":false}}`,
 		},
 		{
-			[]byte(`{"type":"text","options":{"pattern":"test"}}`),
+			[]byte(`{"type":"text","options":{"pattern":"test","encrypted":false}}`),
 			false,
-			`{"system":false,"id":"","name":"","type":"text","required":false,"presentable":false,"unique":false,"options":{"min":null,"max":null,"pattern":"test"}}`,
+			`{"system":false,"id":"","name":"","type":"text","required":false,"presentable":false,"unique":false,"options":{"min":null,"max":null,"pattern":"test","encrypted":false}}`,
 		},
 	}
 
@@ -437,6 +441,37 @@ func TestSchemaFieldValidate(t *testing.T) {
 			},
 			[]string{},
 		},
+		{
+			"default for file field",
+			schema.SchemaField{
+				Type:    schema.FieldTypeFile,
+				Id:      "1234567890",
+				Name:    "test",
+				Options: &schema.FileOptions{MaxSelect: 1, MaxSize: 1},
+				Default: "'abc'",
+			},
+			[]string{"default"},
+		},
+		{
+			"invalid default expression",
+			schema.SchemaField{
+				Type:    schema.FieldTypeText,
+				Id:      "1234567890",
+				Name:    "test",
+				Default: "@request.auth.tokenKey",
+			},
+			[]string{"default"},
+		},
+		{
+			"valid default expression",
+			schema.SchemaField{
+				Type:    schema.FieldTypeText,
+				Id:      "1234567890",
+				Name:    "test",
+				Default: "@request.auth.id + '_' + uuid()",
+			},
+			[]string{},
+		},
 	}
 
 	for _, s := range scenarios {
@@ -461,6 +496,49 @@ func TestSchemaFieldValidate(t *testing.T) {
 	}
 }
 
+func TestSchemaFieldParseDefault(t *testing.T) {
+	scenarios := []struct {
+		expression  string
+		expectError bool
+	}{
+		{"", true},
+		{"missing", true},
+		{"@missing", true},
+		{"@request", true},
+		{"@request.auth", true},
+		{"@request.auth.rel.id", true},
+		{"@request.auth.tokenKey", true},
+		{"@request.auth.passwordHash", true},
+		{"@request.auth.mfaSecret", true},
+		{"@request.body.title", true},
+		{"fn()", true},
+		{"uuid(1)", true},
+		{"123", false},
+		{"'abc'", false},
+		{"now()", false},
+		{"uuid()", false},
+		{"@now", false},
+		{"@request.method", false},
+		{"@request.auth.id", false},
+		{"@request.auth.email", false},
+		{"@request.auth.custom_field", false},
+		{"@request.query.title", false},
+		{"@request.headers.x_token", false},
+		{"@request.data.title + ' ' + uuid()", false},
+	}
+
+	for i, s := range scenarios {
+		field := schema.SchemaField{Default: s.expression}
+
+		_, err := field.ParseDefault()
+
+		hasErr := err != nil
+		if hasErr != s.expectError {
+			t.Errorf("(%d) Expected hasErr %v, got %v (%v)", i, s.expectError, hasErr, err)
+		}
+	}
+}
+
 func TestSchemaFieldInitOptions(t *testing.T) {
 	scenarios := []struct {
 		field       schema.SchemaField
@@ -480,7 +558,7 @@ func TestSchemaFieldInitOptions(t *testing.T) {
 		{
 			schema.SchemaField{Type: schema.FieldTypeText},
 			false,
-			`{"system":false,"id":"","name":"","type":"text","required":false,"presentable":false,"unique":false,"options":{"min":null,"max":null,"pattern":""}}`,
+			`{"system":false,"id":"","name":"","type":"text","required":false,"presentable":false,"unique":false,"options":{"min":null,"max":null,"pattern":"","encrypted":false}}`,
 		},
 		{
 			schema.SchemaField{Type: schema.FieldTypeNumber},
@@ -520,7 +598,7 @@ func TestSchemaFieldInitOptions(t *testing.T) {
 		{
 			schema.SchemaField{Type: schema.FieldTypeJson},
 			false,
-			`{"system":false,"id":"","name":"","type":"json","required":false,"presentable":false,"unique":false,"options":{}}`,
+			`{"system":false,"id":"","name":"","type":"json","required":false,"presentable":false,"unique":false,"options":{"encrypted":false}}`,
 		},
 		{
 			schema.SchemaField{Type: schema.FieldTypeFile},
@@ -532,6 +610,16 @@ func TestSchemaFieldInitOptions(t *testing.T) {
 			false,
 			`{"system":false,"id":"","name":"","type":"relation","required":false,"presentable":false,"unique":false,"options":{"collectionId":"","cascadeDelete":false,"minSelect":null,"maxSelect":null,"displayFields":null}}`,
 		},
+		{
+			schema.SchemaField{Type: schema.FieldTypeGeoPoint},
+			false,
+			`{"system":false,"id":"","name":"","type":"geoPoint","required":false,"presentable":false,"unique":false,"options":{}}`,
+		},
+		{
+			schema.SchemaField{Type: schema.FieldTypeComputed},
+			false,
+			`{"system":false,"id":"","name":"","type":"computed","required":false,"presentable":false,"unique":false,"options":{"expression":""}}`,
+		},
 		{
 			schema.SchemaField{Type: schema.FieldTypeUser},
 			false,
@@ -543,7 +631,7 @@ func TestSchemaFieldInitOptions(t *testing.T) {
 				Options: &schema.TextOptions{Pattern: "test"},
 			},
 			false,
-			`{"system":false,"id":"","name":"","type":"text","required":false,"presentable":false,"unique":false,"options":{"min":null,"max":null,"pattern":"test"}}`,
+			`{"system":false,"id":"","name":"","type":"text","required":false,"presentable":false,"unique":false,"options":{"min":null,"max":null,"pattern":"test","encrypted":false}}`,
 		},
 	}
 
@@ -902,6 +990,14 @@ func TestSchemaFieldPrepareValue(t *testing.T) {
 			[]string{"1ba88b4f-e9da-42f0-9764-9a55c953e724", "2ba88b4f-e9da-42f0-9764-9a55c953e724", "1ba88b4f-e9da-42f0-9764-9a55c953e724"},
 			`["1ba88b4f-e9da-42f0-9764-9a55c953e724","2ba88b4f-e9da-42f0-9764-9a55c953e724"]`,
 		},
+
+		// geoPoint
+		{schema.SchemaField{Type: schema.FieldTypeGeoPoint}, nil, `{"lon":0,"lat":0}`},
+		{schema.SchemaField{Type: schema.FieldTypeGeoPoint}, "", `{"lon":0,"lat":0}`},
+		{schema.SchemaField{Type: schema.FieldTypeGeoPoint}, "invalid", `{"lon":0,"lat":0}`},
+		{schema.SchemaField{Type: schema.FieldTypeGeoPoint}, `{"lon":-122.4,"lat":37.7}`, `{"lon":-122.4,"lat":37.7}`},
+		{schema.SchemaField{Type: schema.FieldTypeGeoPoint}, map[string]any{"lat": 1.5}, `{"lon":0,"lat":1.5}`},
+		{schema.SchemaField{Type: schema.FieldTypeGeoPoint}, types.GeoPoint{Lon: 1, Lat: 2}, `{"lon":1,"lat":2}`},
 	}
 
 	for i, s := range scenarios {
@@ -2038,6 +2134,40 @@ func TestJsonOptionsValidate(t *testing.T) {
 	checkFieldOptionsScenarios(t, scenarios)
 }
 
+func TestGeoPointOptionsValidate(t *testing.T) {
+	scenarios := []fieldOptionsScenario{
+		{
+			"empty",
+			schema.GeoPointOptions{},
+			[]string{},
+		},
+	}
+
+	checkFieldOptionsScenarios(t, scenarios)
+}
+
+func TestComputedOptionsValidate(t *testing.T) {
+	scenarios := []fieldOptionsScenario{
+		{
+			"empty",
+			schema.ComputedOptions{},
+			[]string{"expression"},
+		},
+		{
+			"invalid expression",
+			schema.ComputedOptions{Expression: "price *"},
+			[]string{"expression"},
+		},
+		{
+			"valid expression",
+			schema.ComputedOptions{Expression: "price * qty + ' total'"},
+			[]string{},
+		},
+	}
+
+	checkFieldOptionsScenarios(t, scenarios)
+}
+
 func TestFileOptionsValidate(t *testing.T) {
 	scenarios := []fieldOptionsScenario{
 		{
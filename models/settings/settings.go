@@ -4,6 +4,10 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"regexp"
 	"strings"
 	"sync"
 
@@ -11,9 +15,12 @@ import (
 	"github.com/go-ozzo/ozzo-validation/v4/is"
 	"github.com/unkod/space/tools/auth"
 	"github.com/unkod/space/tools/cron"
+	"github.com/unkod/space/tools/filesystem"
+	"github.com/unkod/space/tools/list"
 	"github.com/unkod/space/tools/mailer"
 	"github.com/unkod/space/tools/rest"
 	"github.com/unkod/space/tools/security"
+	"golang.org/x/crypto/bcrypt"
 )
 
 // SecretMask is the default settings secrets replacement value
@@ -24,20 +31,51 @@ const SecretMask string = "******"
 type Settings struct {
 	mux sync.RWMutex
 
-	Meta    MetaConfig    `form:"meta" json:"meta"`
-	Logs    LogsConfig    `form:"logs" json:"logs"`
-	Smtp    SmtpConfig    `form:"smtp" json:"smtp"`
-	S3      S3Config      `form:"s3" json:"s3"`
-	Backups BackupsConfig `form:"backups" json:"backups"`
+	Meta       MetaConfig       `form:"meta" json:"meta"`
+	Logs       LogsConfig       `form:"logs" json:"logs"`
+	Smtp       SmtpConfig       `form:"smtp" json:"smtp"`
+	S3         S3Config         `form:"s3" json:"s3"`
+	Backups    BackupsConfig    `form:"backups" json:"backups"`
+	RateLimits RateLimitsConfig `form:"rateLimits" json:"rateLimits"`
+	BodyLimits BodyLimitsConfig `form:"bodyLimits" json:"bodyLimits"`
+	Timeouts   TimeoutsConfig   `form:"timeouts" json:"timeouts"`
+	Webhooks   WebhooksConfig   `form:"webhooks" json:"webhooks"`
+
+	LoginLockout LoginLockoutConfig `form:"loginLockout" json:"loginLockout"`
+
+	SecurityEvents SecurityEventsConfig `form:"securityEvents" json:"securityEvents"`
+
+	Compression CompressionConfig `form:"compression" json:"compression"`
+
+	Maintenance MaintenanceConfig `form:"maintenance" json:"maintenance"`
+
+	PasswordHashing PasswordHashingConfig `form:"passwordHashing" json:"passwordHashing"`
+
+	AdminIpFilter IpFilterConfig `form:"adminIpFilter" json:"adminIpFilter"`
+
+	TrustedProxy TrustedProxyConfig `form:"trustedProxy" json:"trustedProxy"`
+
+	Cors CorsConfig `form:"cors" json:"cors"`
+
+	Routes RoutesConfig `form:"routes" json:"routes"`
+
+	Impersonation ImpersonationConfig `form:"impersonation" json:"impersonation"`
+
+	Thumbs ThumbsConfig `form:"thumbs" json:"thumbs"`
+
+	Metrics MetricsConfig `form:"metrics" json:"metrics"`
 
 	AdminAuthToken           TokenConfig `form:"adminAuthToken" json:"adminAuthToken"`
 	AdminPasswordResetToken  TokenConfig `form:"adminPasswordResetToken" json:"adminPasswordResetToken"`
 	AdminFileToken           TokenConfig `form:"adminFileToken" json:"adminFileToken"`
+	AdminMFAToken            TokenConfig `form:"adminMFAToken" json:"adminMFAToken"`
 	RecordAuthToken          TokenConfig `form:"recordAuthToken" json:"recordAuthToken"`
 	RecordPasswordResetToken TokenConfig `form:"recordPasswordResetToken" json:"recordPasswordResetToken"`
 	RecordEmailChangeToken   TokenConfig `form:"recordEmailChangeToken" json:"recordEmailChangeToken"`
 	RecordVerificationToken  TokenConfig `form:"recordVerificationToken" json:"recordVerificationToken"`
 	RecordFileToken          TokenConfig `form:"recordFileToken" json:"recordFileToken"`
+	RecordMFAToken           TokenConfig `form:"recordMFAToken" json:"recordMFAToken"`
+	FileSignedUrlToken       TokenConfig `form:"fileSignedUrlToken" json:"fileSignedUrlToken"`
 
 	// Deprecated: Will be removed in v0.9+
 	EmailAuth EmailAuthConfig `form:"emailAuth" json:"emailAuth"`
@@ -92,6 +130,86 @@ func New() *Settings {
 		Backups: BackupsConfig{
 			CronMaxKeep: 3,
 		},
+		RateLimits: RateLimitsConfig{
+			Enabled: false,
+			Rules: []RateLimitRule{
+				{Label: "auth", MaxRequests: 10, Duration: 60},
+				{Label: RateLimitDefaultLabel, MaxRequests: 300, Duration: 10},
+			},
+		},
+		BodyLimits: BodyLimitsConfig{
+			MaxSize:       32 << 20, // 32MB
+			MaxUploadSize: 0,        // no limit
+		},
+		LoginLockout: LoginLockoutConfig{
+			Enabled:     false,
+			MaxAttempts: 5,
+			Window:      300, // 5 minutes
+			Duration:    900, // 15 minutes
+		},
+		Timeouts: TimeoutsConfig{
+			Request:           0,  // no limit
+			RealtimeKeepAlive: 30, // 30s
+		},
+		Webhooks: WebhooksConfig{
+			Enabled: false,
+			Hooks:   []WebhookConfig{},
+		},
+		SecurityEvents: SecurityEventsConfig{
+			Enabled: false,
+			Events:  []string{},
+		},
+		Compression: CompressionConfig{
+			Enabled:   false,
+			Level:     0,    // default gzip level
+			MinLength: 1024, // 1KB
+		},
+		Maintenance: MaintenanceConfig{
+			ReadOnly: false,
+		},
+		PasswordHashing: PasswordHashingConfig{
+			Algorithm:         security.PasswordAlgorithmBcrypt,
+			BcryptCost:        12,
+			Argon2Memory:      64 * 1024, // 64MB
+			Argon2Iterations:  3,
+			Argon2Parallelism: 2,
+		},
+		AdminIpFilter: IpFilterConfig{
+			Allow: []string{},
+			Deny:  []string{},
+		},
+		TrustedProxy: TrustedProxyConfig{
+			Proxies: []string{},
+		},
+		Cors: CorsConfig{
+			Enabled: false, // fallback to the serve command origins
+			Default: CorsRule{
+				AllowOrigins: []string{"*"},
+				AllowMethods: []string{},
+				AllowHeaders: []string{},
+			},
+			Realtime: CorsRule{
+				AllowOrigins: []string{},
+				AllowMethods: []string{},
+				AllowHeaders: []string{},
+			},
+			Files: CorsRule{
+				AllowOrigins: []string{},
+				AllowMethods: []string{},
+				AllowHeaders: []string{},
+			},
+		},
+		Impersonation: ImpersonationConfig{
+			Enabled:  false,
+			Duration: 0, // fallback to the auth token duration
+		},
+		Thumbs: ThumbsConfig{
+			Sizes: []string{},
+		},
+		Metrics: MetricsConfig{
+			Enabled:     false,
+			RequireAuth: true,
+		},
 		AdminAuthToken: TokenConfig{
 			Secret:   security.RandomString(50),
 			Duration: 1209600, // 14 days
@@ -104,6 +222,10 @@ func New() *Settings {
 			Secret:   security.RandomString(50),
 			Duration: 120, // 2 minutes
 		},
+		AdminMFAToken: TokenConfig{
+			Secret:   security.RandomString(50),
+			Duration: 300, // 5 minutes
+		},
 		RecordAuthToken: TokenConfig{
 			Secret:   security.RandomString(50),
 			Duration: 1209600, // 14 days
@@ -124,6 +246,14 @@ func New() *Settings {
 			Secret:   security.RandomString(50),
 			Duration: 1800, // 30 minutes
 		},
+		RecordMFAToken: TokenConfig{
+			Secret:   security.RandomString(50),
+			Duration: 300, // 5 minutes
+		},
+		FileSignedUrlToken: TokenConfig{
+			Secret:   security.RandomString(50),
+			Duration: 3600, // 1 hour
+		},
 		GoogleAuth: AuthProviderConfig{
 			Enabled: false,
 		},
@@ -201,14 +331,33 @@ func (s *Settings) Validate() error {
 		validation.Field(&s.AdminAuthToken),
 		validation.Field(&s.AdminPasswordResetToken),
 		validation.Field(&s.AdminFileToken),
+		validation.Field(&s.AdminMFAToken),
 		validation.Field(&s.RecordAuthToken),
 		validation.Field(&s.RecordPasswordResetToken),
 		validation.Field(&s.RecordEmailChangeToken),
 		validation.Field(&s.RecordVerificationToken),
 		validation.Field(&s.RecordFileToken),
+		validation.Field(&s.RecordMFAToken),
+		validation.Field(&s.FileSignedUrlToken),
 		validation.Field(&s.Smtp),
 		validation.Field(&s.S3),
 		validation.Field(&s.Backups),
+		validation.Field(&s.RateLimits),
+		validation.Field(&s.BodyLimits),
+		validation.Field(&s.LoginLockout),
+		validation.Field(&s.Timeouts),
+		validation.Field(&s.Impersonation),
+		validation.Field(&s.Thumbs),
+		validation.Field(&s.Metrics),
+		validation.Field(&s.Webhooks),
+		validation.Field(&s.SecurityEvents),
+		validation.Field(&s.Compression),
+		validation.Field(&s.Maintenance),
+		validation.Field(&s.PasswordHashing),
+		validation.Field(&s.AdminIpFilter),
+		validation.Field(&s.TrustedProxy),
+		validation.Field(&s.Cors),
+		validation.Field(&s.Routes),
 		validation.Field(&s.GoogleAuth),
 		validation.Field(&s.FacebookAuth),
 		validation.Field(&s.GithubAuth),
@@ -270,11 +419,16 @@ func (s *Settings) RedactClone() (*Settings, error) {
 		&clone.AdminAuthToken.Secret,
 		&clone.AdminPasswordResetToken.Secret,
 		&clone.AdminFileToken.Secret,
+		&clone.AdminMFAToken.Secret,
 		&clone.RecordAuthToken.Secret,
 		&clone.RecordPasswordResetToken.Secret,
 		&clone.RecordEmailChangeToken.Secret,
 		&clone.RecordVerificationToken.Secret,
 		&clone.RecordFileToken.Secret,
+		&clone.RecordMFAToken.Secret,
+		&clone.FileSignedUrlToken.Secret,
+		&clone.SecurityEvents.Secret,
+		&clone.Metrics.Token,
 		&clone.GoogleAuth.ClientSecret,
 		&clone.FacebookAuth.ClientSecret,
 		&clone.GithubAuth.ClientSecret,
@@ -298,6 +452,10 @@ func (s *Settings) RedactClone() (*Settings, error) {
 		&clone.YandexAuth.ClientSecret,
 	}
 
+	for i := range clone.Webhooks.Hooks {
+		sensitiveFields = append(sensitiveFields, &clone.Webhooks.Hooks[i].Secret)
+	}
+
 	// mask all sensitive fields
 	for _, v := range sensitiveFields {
 		if v != nil && *v != "" {
@@ -308,6 +466,110 @@ func (s *Settings) RedactClone() (*Settings, error) {
 	return clone, nil
 }
 
+// Change defines a single changed settings value.
+type Change struct {
+	Old any `json:"old"`
+	New any `json:"new"`
+}
+
+// RedactedDiff returns the changed values between the current and the
+// other settings indexed by their dotted settings path (eg. "smtp.host").
+//
+// The objects are compared per key, while the arrays are compared as a whole.
+// The secret values are masked (a changed secret is still reported).
+func (s *Settings) RedactedDiff(other *Settings) (map[string]Change, error) {
+	oldValues, err := flattenSettings(s, false)
+	if err != nil {
+		return nil, err
+	}
+
+	newValues, err := flattenSettings(other, false)
+	if err != nil {
+		return nil, err
+	}
+
+	oldRedactedValues, err := flattenSettings(s, true)
+	if err != nil {
+		return nil, err
+	}
+
+	newRedactedValues, err := flattenSettings(other, true)
+	if err != nil {
+		return nil, err
+	}
+
+	paths := make(map[string]struct{}, len(oldValues))
+	for path := range oldValues {
+		paths[path] = struct{}{}
+	}
+	for path := range newValues {
+		paths[path] = struct{}{}
+	}
+
+	result := map[string]Change{}
+
+	for path := range paths {
+		oldRaw, _ := json.Marshal(oldValues[path])
+		newRaw, _ := json.Marshal(newValues[path])
+		if string(oldRaw) == string(newRaw) {
+			continue
+		}
+
+		result[path] = Change{
+			Old: oldRedactedValues[path],
+			New: newRedactedValues[path],
+		}
+	}
+
+	return result, nil
+}
+
+// flattenSettings returns the (optionally redacted) settings
+// json values indexed by their dotted path.
+func flattenSettings(s *Settings, redact bool) (map[string]any, error) {
+	var err error
+
+	if redact {
+		s, err = s.RedactClone()
+	} else {
+		s, err = s.Clone()
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	raw, err := json.Marshal(s)
+	if err != nil {
+		return nil, err
+	}
+
+	data := map[string]any{}
+	if err := json.Unmarshal(raw, &data); err != nil {
+		return nil, err
+	}
+
+	result := map[string]any{}
+
+	var flatten func(prefix string, value any)
+	flatten = func(prefix string, value any) {
+		obj, ok := value.(map[string]any)
+		if !ok || len(obj) == 0 {
+			result[prefix] = value
+			return
+		}
+
+		for k, v := range obj {
+			flatten(prefix+"."+k, v)
+		}
+	}
+
+	for k, v := range data {
+		flatten(k, v)
+	}
+
+	return result, nil
+}
+
 // NamedAuthProviderConfigs returns a map with all registered OAuth2
 // provider configurations (indexed by their name identifier).
 func (s *Settings) NamedAuthProviderConfigs() map[string]AuthProviderConfig {
@@ -418,7 +680,8 @@ type S3Config struct {
 // Validate makes S3Config validatable by implementing [validation.Validatable] interface.
 func (c S3Config) Validate() error {
 	return validation.ValidateStruct(&c,
-		validation.Field(&c.Endpoint, is.URL, validation.When(c.Enabled, validation.Required)),
+		// the endpoint is optional and defaults to the AWS S3 region endpoint
+		validation.Field(&c.Endpoint, is.URL),
 		validation.Field(&c.Bucket, validation.When(c.Enabled, validation.Required)),
 		validation.Field(&c.Region, validation.When(c.Enabled, validation.Required)),
 		validation.Field(&c.AccessKey, validation.When(c.Enabled, validation.Required)),
@@ -440,6 +703,17 @@ type BackupsConfig struct {
 	// This field works only when the cron config has valid cron expression.
 	CronMaxKeep int `form:"cronMaxKeep" json:"cronMaxKeep"`
 
+	// CronKeepDaily, CronKeepWeekly and CronKeepMonthly are optional
+	// calendar based retention rules that keep the most recent cron
+	// generated backup for each of the last N days, weeks and months
+	// (only the periods with at least one backup are counted).
+	//
+	// They are combined with CronMaxKeep, meaning that a backup is removed
+	// only if it is not retained by any of the configured rules.
+	CronKeepDaily   int `form:"cronKeepDaily" json:"cronKeepDaily"`
+	CronKeepWeekly  int `form:"cronKeepWeekly" json:"cronKeepWeekly"`
+	CronKeepMonthly int `form:"cronKeepMonthly" json:"cronKeepMonthly"`
+
 	// S3 is an optional S3 storage config specifying where to store the app backups.
 	S3 S3Config `form:"s3" json:"s3"`
 }
@@ -451,12 +725,21 @@ func (c BackupsConfig) Validate() error {
 		validation.Field(&c.Cron, validation.By(checkCronExpression)),
 		validation.Field(
 			&c.CronMaxKeep,
-			validation.When(c.Cron != "", validation.Required),
+			validation.When(c.Cron != "" && !c.HasCalendarRetention(), validation.Required),
 			validation.Min(1),
 		),
+		validation.Field(&c.CronKeepDaily, validation.Min(0)),
+		validation.Field(&c.CronKeepWeekly, validation.Min(0)),
+		validation.Field(&c.CronKeepMonthly, validation.Min(0)),
 	)
 }
 
+// HasCalendarRetention reports whether at least one of the
+// daily, weekly or monthly retention rules is configured.
+func (c BackupsConfig) HasCalendarRetention() bool {
+	return c.CronKeepDaily > 0 || c.CronKeepWeekly > 0 || c.CronKeepMonthly > 0
+}
+
 func checkCronExpression(value any) error {
 	v, _ := value.(string)
 	if v == "" {
@@ -581,6 +864,37 @@ func (t EmailTemplate) Resolve(
 	return subject, body, actionUrl
 }
 
+var emailPlaceholderRegex = regexp.MustCompile(`\{[A-Z][A-Z0-9_]*\}`)
+
+// UnknownPlaceholders returns the unique placeholder-like params
+// (eg. "{MISSING}") of the current email template that will not be
+// replaced by [EmailTemplate.Resolve].
+//
+// Note that the supported placeholders differ for each template part
+// (eg. {TOKEN} is not resolved in the subject).
+func (t EmailTemplate) UnknownPlaceholders() []string {
+	parts := []struct {
+		value string
+		known []string
+	}{
+		{t.Subject, []string{EmailPlaceholderAppName, EmailPlaceholderAppUrl}},
+		{t.ActionUrl, []string{EmailPlaceholderAppName, EmailPlaceholderAppUrl, EmailPlaceholderToken}},
+		{t.Body, []string{EmailPlaceholderAppName, EmailPlaceholderAppUrl, EmailPlaceholderToken, EmailPlaceholderActionUrl}},
+	}
+
+	result := []string{}
+
+	for _, part := range parts {
+		for _, placeholder := range emailPlaceholderRegex.FindAllString(part.value, -1) {
+			if !list.ExistInSlice(placeholder, part.known) && !list.ExistInSlice(placeholder, result) {
+				result = append(result, placeholder)
+			}
+		}
+	}
+
+	return result
+}
+
 // -------------------------------------------------------------------
 
 type LogsConfig struct {
@@ -596,6 +910,797 @@ func (c LogsConfig) Validate() error {
 
 // -------------------------------------------------------------------
 
+type BodyLimitsConfig struct {
+	// MaxSize is the default max allowed request body size in bytes
+	// (0 means no limit).
+	MaxSize int64 `form:"maxSize" json:"maxSize"`
+
+	// MaxUploadSize is the max allowed request body size in bytes
+	// of the multipart/form-data (aka. file upload) requests
+	// (0 means no limit).
+	//
+	// Note that the individual file fields max size options are still applied.
+	MaxUploadSize int64 `form:"maxUploadSize" json:"maxUploadSize"`
+}
+
+// Validate makes BodyLimitsConfig validatable by implementing [validation.Validatable] interface.
+func (c BodyLimitsConfig) Validate() error {
+	return validation.ValidateStruct(&c,
+		validation.Field(&c.MaxSize, validation.Min(0)),
+		validation.Field(&c.MaxUploadSize, validation.Min(0)),
+	)
+}
+
+// -------------------------------------------------------------------
+
+type TimeoutsConfig struct {
+	// Request is the default max duration in seconds of an API request
+	// handling (0 means no limit).
+	//
+	// Note that the timeout doesn't interrupt the already
+	// committed (eg. streamed) responses.
+	Request int `form:"request" json:"request"`
+
+	// RealtimeKeepAlive is the interval in seconds of the keep-alive
+	// pings sent to the idle realtime SSE connections (0 disables the pings).
+	//
+	// It should be lower than the idle timeout of the proxies and
+	// load balancers in front of the app (usually ~60s).
+	RealtimeKeepAlive int `form:"realtimeKeepAlive" json:"realtimeKeepAlive"`
+}
+
+// Validate makes TimeoutsConfig validatable by implementing [validation.Validatable] interface.
+func (c TimeoutsConfig) Validate() error {
+	return validation.ValidateStruct(&c,
+		validation.Field(&c.Request, validation.Min(0)),
+		validation.Field(&c.RealtimeKeepAlive, validation.Min(0)),
+	)
+}
+
+// -------------------------------------------------------------------
+
+type CompressionConfig struct {
+	// Enabled enables the gzip compression of the API responses
+	// for the clients that support it (via the Accept-Encoding header).
+	Enabled bool `form:"enabled" json:"enabled"`
+
+	// Level is the gzip compression level from 1 (best speed)
+	// to 9 (best compression), or 0 for the default gzip level.
+	Level int `form:"level" json:"level"`
+
+	// MinLength is the min response body size in bytes
+	// before the compression is applied.
+	MinLength int `form:"minLength" json:"minLength"`
+}
+
+// Validate makes CompressionConfig validatable by implementing [validation.Validatable] interface.
+func (c CompressionConfig) Validate() error {
+	return validation.ValidateStruct(&c,
+		validation.Field(&c.Level, validation.Min(0), validation.Max(9)),
+		validation.Field(&c.MinLength, validation.Min(0)),
+	)
+}
+
+// -------------------------------------------------------------------
+
+type MaintenanceConfig struct {
+	// ReadOnly rejects all API mutations (create, update, delete, etc.)
+	// with 503 error, while still allowing the read requests.
+	//
+	// The internal app writes (eg. migrations) are not affected.
+	ReadOnly bool `form:"readOnly" json:"readOnly"`
+
+	// Message is an optional custom read-only error message.
+	Message string `form:"message" json:"message"`
+}
+
+// Validate makes MaintenanceConfig validatable by implementing [validation.Validatable] interface.
+func (c MaintenanceConfig) Validate() error {
+	return validation.ValidateStruct(&c,
+		validation.Field(&c.Message, validation.Length(0, 255)),
+	)
+}
+
+// -------------------------------------------------------------------
+
+type PasswordHashingConfig struct {
+	// Algorithm is the password hashing algorithm of the new
+	// admin and auth record passwords ("bcrypt" or "argon2id").
+	//
+	// The existing passwords are rehashed on successful login if they
+	// were hashed with a different algorithm or weaker parameters.
+	Algorithm string `form:"algorithm" json:"algorithm"`
+
+	// BcryptCost is the bcrypt hashing cost (4-31).
+	BcryptCost int `form:"bcryptCost" json:"bcryptCost"`
+
+	// Argon2Memory is the argon2id memory usage in KiB.
+	Argon2Memory int `form:"argon2Memory" json:"argon2Memory"`
+
+	// Argon2Iterations is the number of argon2id passes over the memory.
+	Argon2Iterations int `form:"argon2Iterations" json:"argon2Iterations"`
+
+	// Argon2Parallelism is the number of argon2id threads (1-255).
+	Argon2Parallelism int `form:"argon2Parallelism" json:"argon2Parallelism"`
+}
+
+// Validate makes PasswordHashingConfig validatable by implementing [validation.Validatable] interface.
+func (c PasswordHashingConfig) Validate() error {
+	isBcrypt := c.Algorithm == security.PasswordAlgorithmBcrypt
+	isArgon2 := c.Algorithm == security.PasswordAlgorithmArgon2id
+
+	return validation.ValidateStruct(&c,
+		validation.Field(
+			&c.Algorithm,
+			validation.Required,
+			validation.In(security.PasswordAlgorithmBcrypt, security.PasswordAlgorithmArgon2id),
+		),
+		validation.Field(&c.BcryptCost, validation.When(isBcrypt, validation.Min(bcrypt.MinCost), validation.Max(bcrypt.MaxCost))),
+		validation.Field(&c.Argon2Memory, validation.When(isArgon2, validation.Min(8*1024), validation.Max(4*1024*1024))),
+		validation.Field(&c.Argon2Iterations, validation.When(isArgon2, validation.Min(1), validation.Max(100))),
+		validation.Field(&c.Argon2Parallelism, validation.When(isArgon2, validation.Min(1), validation.Max(255))),
+	)
+}
+
+// Options returns the config as [security.PasswordHashOptions].
+func (c PasswordHashingConfig) Options() security.PasswordHashOptions {
+	return security.PasswordHashOptions{
+		Algorithm:         c.Algorithm,
+		BcryptCost:        c.BcryptCost,
+		Argon2Memory:      uint32(c.Argon2Memory),
+		Argon2Iterations:  uint32(c.Argon2Iterations),
+		Argon2Parallelism: uint8(c.Argon2Parallelism),
+	}
+}
+
+// -------------------------------------------------------------------
+
+type IpFilterConfig struct {
+	// Allow is a list of IPv4/IPv6 CIDRs (or single IPs) that are allowed
+	// to access the filtered routes (empty list means "allow all").
+	Allow []string `form:"allow" json:"allow"`
+
+	// Deny is a list of IPv4/IPv6 CIDRs (or single IPs) that are
+	// never allowed to access the filtered routes (takes precedence over Allow).
+	Deny []string `form:"deny" json:"deny"`
+}
+
+// Validate makes IpFilterConfig validatable by implementing [validation.Validatable] interface.
+func (c IpFilterConfig) Validate() error {
+	return validation.ValidateStruct(&c,
+		validation.Field(&c.Allow, validation.Each(validation.By(checkIpOrCIDR))),
+		validation.Field(&c.Deny, validation.Each(validation.By(checkIpOrCIDR))),
+	)
+}
+
+// IsAllowed checks whether the provided ip is allowed by the filter lists.
+//
+// Invalid ips are never allowed.
+func (c IpFilterConfig) IsAllowed(ip string) bool {
+	parsed := net.ParseIP(ip)
+	if parsed == nil {
+		return false
+	}
+
+	if ipInList(parsed, c.Deny) {
+		return false
+	}
+
+	return len(c.Allow) == 0 || ipInList(parsed, c.Allow)
+}
+
+// -------------------------------------------------------------------
+
+type TrustedProxyConfig struct {
+	// Proxies is a list of IPv4/IPv6 CIDRs (or single IPs) of the
+	// reverse proxies whose client IP headers (eg. X-Forwarded-For)
+	// are trusted when resolving the request user IP.
+	//
+	// Empty list means that the proxy headers are never trusted
+	// and the user IP is always the request remote address.
+	Proxies []string `form:"proxies" json:"proxies"`
+}
+
+// Validate makes TrustedProxyConfig validatable by implementing [validation.Validatable] interface.
+func (c TrustedProxyConfig) Validate() error {
+	return validation.ValidateStruct(&c,
+		validation.Field(&c.Proxies, validation.Each(validation.By(checkIpOrCIDR))),
+	)
+}
+
+// IsTrusted checks whether the provided ip is one of the trusted proxies.
+//
+// Invalid ips are never trusted.
+func (c TrustedProxyConfig) IsTrusted(ip string) bool {
+	parsed := net.ParseIP(ip)
+	if parsed == nil {
+		return false
+	}
+
+	return ipInList(parsed, c.Proxies)
+}
+
+func ipInList(ip net.IP, list []string) bool {
+	for _, item := range list {
+		if _, network, err := net.ParseCIDR(item); err == nil {
+			if network.Contains(ip) {
+				return true
+			}
+		} else if other := net.ParseIP(item); other != nil && other.Equal(ip) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func checkIpOrCIDR(value any) error {
+	v, _ := value.(string)
+
+	if _, _, err := net.ParseCIDR(v); err == nil {
+		return nil
+	}
+
+	if net.ParseIP(v) != nil {
+		return nil
+	}
+
+	return validation.NewError("validation_invalid_ip_or_cidr", "Must be a valid IP address or CIDR.")
+}
+
+// -------------------------------------------------------------------
+
+type CorsConfig struct {
+	// Enabled replaces the serve command allowed origins
+	// with the CORS rules below.
+	Enabled bool `form:"enabled" json:"enabled"`
+
+	// Default is the CORS rule applied to all routes without an override.
+	Default CorsRule `form:"default" json:"default"`
+
+	// Realtime is an optional "/api/realtime" routes rule override
+	// (applied only if it has at least one allowed origin).
+	Realtime CorsRule `form:"realtime" json:"realtime"`
+
+	// Files is an optional "/api/files" routes rule override
+	// (applied only if it has at least one allowed origin).
+	Files CorsRule `form:"files" json:"files"`
+}
+
+// Validate makes CorsConfig validatable by implementing [validation.Validatable] interface.
+func (c CorsConfig) Validate() error {
+	return validation.ValidateStruct(&c,
+		validation.Field(&c.Default),
+		validation.Field(&c.Realtime),
+		validation.Field(&c.Files),
+	)
+}
+
+// RuleFor returns the CORS rule for the provided request path.
+func (c CorsConfig) RuleFor(path string) CorsRule {
+	if len(c.Realtime.AllowOrigins) > 0 && (path == "/api/realtime" || strings.HasPrefix(path, "/api/realtime/")) {
+		return c.Realtime
+	}
+
+	if len(c.Files.AllowOrigins) > 0 && strings.HasPrefix(path, "/api/files/") {
+		return c.Files
+	}
+
+	return c.Default
+}
+
+// -------------------------------------------------------------------
+
+const (
+	TrailingSlashLeave  string = ""
+	TrailingSlashStrip  string = "strip"
+	TrailingSlashAppend string = "append"
+)
+
+// RoutesConfig defines the request path matching behavior of the
+// custom routes (aka. all routes except the "/api/*" ones).
+//
+// The "/api/*" routes trailing slash is always removed (see the
+// RemoveTrailingSlash middleware in apis.InitApi) and their
+// matching is always case-sensitive.
+type RoutesConfig struct {
+	// TrailingSlash specifies how the custom routes trailing slash is handled:
+	//   - TrailingSlashLeave ("") - the request path is not changed (default)
+	//   - TrailingSlashStrip ("strip") - the trailing slash is removed (the same as for the /api/ routes)
+	//   - TrailingSlashAppend ("append") - a trailing slash is added
+	//
+	// The path is rewritten before the routing, aka. a custom route
+	// must be registered with the normalized path form (eg. "/hello/"
+	// in "append" mode). The static directory handler is not affected
+	// since it always cleans the requested file path.
+	TrailingSlash string `form:"trailingSlash" json:"trailingSlash"`
+
+	// CaseInsensitive enables the case-insensitive matching of the
+	// custom routes static path segments (eg. "/HELLO/:name" is routed to
+	// "/hello/:name"). The path parameters values are left as they are.
+	//
+	// Note that the files of the static directory handler are still
+	// served case-sensitively (or as the underlying filesystem does).
+	CaseInsensitive bool `form:"caseInsensitive" json:"caseInsensitive"`
+}
+
+// Validate makes RoutesConfig validatable by implementing [validation.Validatable] interface.
+func (c RoutesConfig) Validate() error {
+	return validation.ValidateStruct(&c,
+		validation.Field(
+			&c.TrailingSlash,
+			validation.In(TrailingSlashLeave, TrailingSlashStrip, TrailingSlashAppend),
+		),
+	)
+}
+
+// -------------------------------------------------------------------
+
+// CorsRule defines a single CORS rule.
+type CorsRule struct {
+	// AllowOrigins is a list with the allowed request origins.
+	//
+	// Each origin could be:
+	//   - "*" to allow any origin
+	//   - an exact origin (eg. "https://example.com")
+	//   - an origin with "*" wildcards (eg. "https://*.example.com")
+	//   - a regular expression prefixed with "^" (eg. "^https://(a|b)\.example\.com$")
+	AllowOrigins []string `form:"allowOrigins" json:"allowOrigins"`
+
+	// AllowMethods is a list with the allowed preflight request methods
+	// (empty list means the default GET, HEAD, PUT, PATCH, POST and DELETE).
+	AllowMethods []string `form:"allowMethods" json:"allowMethods"`
+
+	// AllowHeaders is a list with the allowed preflight request headers
+	// (empty list means allow the requested headers).
+	AllowHeaders []string `form:"allowHeaders" json:"allowHeaders"`
+
+	// AllowCredentials indicates whether the responses can be
+	// exposed to requests made with credentials (eg. cookies).
+	AllowCredentials bool `form:"allowCredentials" json:"allowCredentials"`
+
+	// MaxAge is the number of seconds the preflight requests
+	// results could be cached (0 means no caching header).
+	MaxAge int `form:"maxAge" json:"maxAge"`
+}
+
+// Validate makes CorsRule validatable by implementing [validation.Validatable] interface.
+func (r CorsRule) Validate() error {
+	return validation.ValidateStruct(&r,
+		validation.Field(
+			&r.AllowOrigins,
+			validation.Each(validation.By(checkOriginPattern)),
+			validation.When(r.AllowCredentials, validation.By(checkNoWildcardOrigin)),
+		),
+		validation.Field(
+			&r.AllowMethods,
+			validation.Each(validation.In(
+				http.MethodGet,
+				http.MethodHead,
+				http.MethodPost,
+				http.MethodPut,
+				http.MethodPatch,
+				http.MethodDelete,
+				http.MethodOptions,
+			)),
+		),
+		validation.Field(&r.AllowHeaders, validation.Each(validation.Match(corsHeaderRegex))),
+		validation.Field(&r.MaxAge, validation.Min(0), validation.Max(86400)),
+	)
+}
+
+// OriginMatcher returns a function that checks
+// whether an origin is allowed by the rule.
+//
+// Invalid origin patterns are ignored.
+func (r CorsRule) OriginMatcher() func(origin string) bool {
+	patterns := make([]*regexp.Regexp, 0, len(r.AllowOrigins))
+
+	for _, o := range r.AllowOrigins {
+		if o == "*" {
+			return func(origin string) bool { return true }
+		}
+
+		if re, err := compileOriginPattern(o); err == nil {
+			patterns = append(patterns, re)
+		}
+	}
+
+	return func(origin string) bool {
+		for _, re := range patterns {
+			if re.MatchString(origin) {
+				return true
+			}
+		}
+
+		return false
+	}
+}
+
+var corsHeaderRegex = regexp.MustCompile(`^(\*|[\w-]+)$`)
+
+// compileOriginPattern compiles a single CorsRule.AllowOrigins
+// pattern into a regular expression.
+func compileOriginPattern(pattern string) (*regexp.Regexp, error) {
+	if strings.HasPrefix(pattern, "^") {
+		return regexp.Compile(pattern)
+	}
+
+	// replace the wildcards with a placeholder valid both as host label and port
+	u, err := url.Parse(strings.ReplaceAll(pattern, "*", "0"))
+	if err != nil || u.Scheme == "" || u.Host == "" || (u.Path != "" && u.Path != "/") || u.RawQuery != "" || u.Fragment != "" || u.User != nil {
+		return nil, errors.New("invalid origin")
+	}
+
+	escaped := regexp.QuoteMeta(strings.TrimSuffix(pattern, "/"))
+
+	return regexp.Compile("^" + strings.ReplaceAll(escaped, "\\*", "[^/]*") + "$")
+}
+
+func checkOriginPattern(value any) error {
+	v, _ := value.(string)
+
+	if v == "*" {
+		return nil
+	}
+
+	if _, err := compileOriginPattern(v); err != nil {
+		return validation.NewError("validation_invalid_origin", "Must be *, a valid origin (optionally with * wildcards) or a regular expression starting with ^.")
+	}
+
+	return nil
+}
+
+func checkNoWildcardOrigin(value any) error {
+	v, _ := value.([]string)
+
+	if list.ExistInSlice("*", v) {
+		return validation.NewError("validation_wildcard_origin_with_credentials", "The * origin can't be used together with allowCredentials.")
+	}
+
+	return nil
+}
+
+// -------------------------------------------------------------------
+
+type ImpersonationConfig struct {
+	// Enabled allows admins to generate auth tokens for the auth records
+	// (disabling it invalidates the already generated impersonation tokens).
+	Enabled bool `form:"enabled" json:"enabled"`
+
+	// Duration is the impersonation tokens duration in seconds
+	// (0 means fallback to the regular auth record token duration).
+	Duration int64 `form:"duration" json:"duration"`
+}
+
+// Validate makes ImpersonationConfig validatable by implementing [validation.Validatable] interface.
+func (c ImpersonationConfig) Validate() error {
+	return validation.ValidateStruct(&c,
+		validation.Field(&c.Duration, validation.Min(0), validation.Max(63072000)),
+	)
+}
+
+// -------------------------------------------------------------------
+
+type LoginLockoutConfig struct {
+	// Enabled enables the temporary lockout of the auth identities and
+	// client IPs after too many failed auth-with-password attempts.
+	Enabled bool `form:"enabled" json:"enabled"`
+
+	// MaxAttempts is the number of failed attempts within Window
+	// after which the identity (or client IP) is locked.
+	MaxAttempts int `form:"maxAttempts" json:"maxAttempts"`
+
+	// Window is the failed attempts counting window in seconds.
+	Window int64 `form:"window" json:"window"`
+
+	// Duration is the lockout duration in seconds.
+	Duration int64 `form:"duration" json:"duration"`
+}
+
+// Validate makes LoginLockoutConfig validatable by implementing [validation.Validatable] interface.
+func (c LoginLockoutConfig) Validate() error {
+	return validation.ValidateStruct(&c,
+		validation.Field(&c.MaxAttempts, validation.When(c.Enabled, validation.Required), validation.Min(0)),
+		validation.Field(&c.Window, validation.When(c.Enabled, validation.Required), validation.Min(int64(0)), validation.Max(int64(86400))),
+		validation.Field(&c.Duration, validation.When(c.Enabled, validation.Required), validation.Min(int64(0)), validation.Max(int64(86400))),
+	)
+}
+
+// -------------------------------------------------------------------
+
+type ThumbsConfig struct {
+	// Sizes is a list of thumb sizes (in the same format as the file
+	// field thumbs option) that are allowed to be generated on demand
+	// for every file field, in addition to the field specific ones.
+	Sizes []string `form:"sizes" json:"sizes"`
+}
+
+// Validate makes ThumbsConfig validatable by implementing [validation.Validatable] interface.
+func (c ThumbsConfig) Validate() error {
+	return validation.ValidateStruct(&c,
+		validation.Field(&c.Sizes, validation.Each(
+			validation.NotIn("0x0", "0x0t", "0x0b", "0x0f"),
+			validation.Match(filesystem.ThumbSizeRegex),
+		)),
+	)
+}
+
+// -------------------------------------------------------------------
+
+// RateLimitDefaultLabel is the label of the rate limit rule that is used
+// as a fallback for the route groups without an explicit rule.
+const RateLimitDefaultLabel string = "*"
+
+type RateLimitsConfig struct {
+	Enabled bool            `form:"enabled" json:"enabled"`
+	Rules   []RateLimitRule `form:"rules" json:"rules"`
+}
+
+// Validate makes RateLimitsConfig validatable by implementing [validation.Validatable] interface.
+func (c RateLimitsConfig) Validate() error {
+	return validation.ValidateStruct(&c,
+		validation.Field(&c.Rules, validation.By(checkUniqueRateLimitLabels)),
+	)
+}
+
+// FindRule returns the rule with the specified label or the
+// RateLimitDefaultLabel rule if there is no explicit one.
+//
+// Returns nil if neither of the rules exist.
+func (c RateLimitsConfig) FindRule(label string) *RateLimitRule {
+	var fallback *RateLimitRule
+
+	for i, rule := range c.Rules {
+		if rule.Label == label {
+			return &c.Rules[i]
+		}
+		if rule.Label == RateLimitDefaultLabel {
+			fallback = &c.Rules[i]
+		}
+	}
+
+	return fallback
+}
+
+func checkUniqueRateLimitLabels(value any) error {
+	rules, _ := value.([]RateLimitRule)
+
+	labels := make(map[string]struct{}, len(rules))
+
+	for _, rule := range rules {
+		if _, ok := labels[rule.Label]; ok {
+			return validation.NewError("validation_duplicated_rate_limit_label", fmt.Sprintf("Duplicated rule label %q.", rule.Label))
+		}
+		labels[rule.Label] = struct{}{}
+	}
+
+	return nil
+}
+
+type RateLimitRule struct {
+	// Label is the identifier of the route group the rule applies to (eg. "auth").
+	Label string `form:"label" json:"label"`
+
+	// MaxRequests is the max allowed requests per Duration for a single client.
+	MaxRequests int `form:"maxRequests" json:"maxRequests"`
+
+	// Duration is the rate limit window in seconds.
+	Duration int64 `form:"duration" json:"duration"`
+}
+
+// Validate makes RateLimitRule validatable by implementing [validation.Validatable] interface.
+func (c RateLimitRule) Validate() error {
+	return validation.ValidateStruct(&c,
+		validation.Field(&c.Label, validation.Required, validation.Length(1, 100)),
+		validation.Field(&c.MaxRequests, validation.Required, validation.Min(1)),
+		validation.Field(&c.Duration, validation.Required, validation.Min(int64(1))),
+	)
+}
+
+// -------------------------------------------------------------------
+
+// list with the supported webhook record events
+const (
+	WebhookEventCreate string = "create"
+	WebhookEventUpdate string = "update"
+	WebhookEventDelete string = "delete"
+)
+
+type WebhooksConfig struct {
+	Enabled bool            `form:"enabled" json:"enabled"`
+	Hooks   []WebhookConfig `form:"hooks" json:"hooks"`
+}
+
+// Validate makes WebhooksConfig validatable by implementing [validation.Validatable] interface.
+func (c WebhooksConfig) Validate() error {
+	return validation.ValidateStruct(&c,
+		validation.Field(&c.Hooks, validation.By(checkUniqueWebhookNames)),
+	)
+}
+
+// FindHooks returns all webhooks that are subscribed to the
+// specified record event and collection (matched by any of its name or id).
+func (c WebhooksConfig) FindHooks(event string, collectionNameOrIds ...string) []WebhookConfig {
+	result := []WebhookConfig{}
+
+	for _, hook := range c.Hooks {
+		if hook.Matches(event, collectionNameOrIds...) {
+			result = append(result, hook)
+		}
+	}
+
+	return result
+}
+
+// RestoreSecrets replaces the masked (see [SecretMask]) webhook secrets
+// with the ones of the same named webhooks from the old config.
+func (c *WebhooksConfig) RestoreSecrets(old WebhooksConfig) {
+	for i, hook := range c.Hooks {
+		if hook.Secret != SecretMask {
+			continue
+		}
+
+		for _, oldHook := range old.Hooks {
+			if oldHook.Name == hook.Name {
+				c.Hooks[i].Secret = oldHook.Secret
+				break
+			}
+		}
+	}
+}
+
+func checkUniqueWebhookNames(value any) error {
+	hooks, _ := value.([]WebhookConfig)
+
+	names := make(map[string]struct{}, len(hooks))
+
+	for _, hook := range hooks {
+		if _, ok := names[hook.Name]; ok {
+			return validation.NewError("validation_duplicated_webhook_name", fmt.Sprintf("Duplicated webhook name %q.", hook.Name))
+		}
+		names[hook.Name] = struct{}{}
+	}
+
+	return nil
+}
+
+type WebhookConfig struct {
+	// Name is the unique identifier of the webhook.
+	Name string `form:"name" json:"name"`
+
+	// Collection is the name or id of the collection whose record changes are delivered.
+	Collection string `form:"collection" json:"collection"`
+
+	// Url is the endpoint that will receive the record change POST requests.
+	Url string `form:"url" json:"url"`
+
+	// Secret is the shared secret used to sign the request body (HMAC-SHA256).
+	Secret string `form:"secret" json:"secret"`
+
+	// Events is a list with the record events to deliver
+	// ("create", "update", "delete").
+	//
+	// Leave empty to deliver all record events.
+	Events []string `form:"events" json:"events"`
+
+	// Fields is an optional list with the only record fields
+	// to include in the payload (the record id is always included).
+	Fields []string `form:"fields" json:"fields"`
+
+	// ExcludeFields is an optional list with record fields
+	// to exclude from the payload.
+	ExcludeFields []string `form:"excludeFields" json:"excludeFields"`
+
+	// Timeout is the max duration in seconds of a single delivery attempt.
+	Timeout int64 `form:"timeout" json:"timeout"`
+
+	// MaxRetries is the max number of retries (with exponential backoff)
+	// after a failed delivery attempt.
+	MaxRetries int `form:"maxRetries" json:"maxRetries"`
+}
+
+// Validate makes WebhookConfig validatable by implementing [validation.Validatable] interface.
+func (c WebhookConfig) Validate() error {
+	return validation.ValidateStruct(&c,
+		validation.Field(&c.Name, validation.Required, validation.Length(1, 100)),
+		validation.Field(&c.Collection, validation.Required),
+		validation.Field(&c.Url, validation.Required, is.URL),
+		validation.Field(&c.Secret, validation.Required, validation.Length(10, 255)),
+		validation.Field(
+			&c.Events,
+			validation.Each(validation.In(WebhookEventCreate, WebhookEventUpdate, WebhookEventDelete)),
+		),
+		validation.Field(&c.Timeout, validation.Min(int64(0)), validation.Max(int64(300))),
+		validation.Field(&c.MaxRetries, validation.Min(0), validation.Max(10)),
+	)
+}
+
+// Matches reports whether the webhook is subscribed to the specified
+// record event and collection (matched by any of its name or id).
+func (c WebhookConfig) Matches(event string, collectionNameOrIds ...string) bool {
+	if len(c.Events) > 0 && !list.ExistInSlice(event, c.Events) {
+		return false
+	}
+
+	for _, v := range collectionNameOrIds {
+		if strings.EqualFold(c.Collection, v) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// -------------------------------------------------------------------
+
+// list with the supported security events
+const (
+	SecurityEventAdminAuth         string = "admin.auth"
+	SecurityEventRecordAuth        string = "record.auth"
+	SecurityEventRecordImpersonate string = "record.impersonate"
+	SecurityEventSettingsUpdate    string = "settings.update"
+	SecurityEventBackupCreate      string = "backup.create"
+	SecurityEventBackupRestore     string = "backup.restore"
+	SecurityEventBackupDelete      string = "backup.delete"
+)
+
+// SecurityEvents returns a list with all supported security events.
+func SecurityEvents() []string {
+	return []string{
+		SecurityEventAdminAuth,
+		SecurityEventRecordAuth,
+		SecurityEventRecordImpersonate,
+		SecurityEventSettingsUpdate,
+		SecurityEventBackupCreate,
+		SecurityEventBackupRestore,
+		SecurityEventBackupDelete,
+	}
+}
+
+// SecurityEventsConfig defines the security events stream, delivered
+// to a single endpoint separately from the record webhooks and the
+// activity logs (eg. to forward them to a SIEM).
+type SecurityEventsConfig struct {
+	Enabled bool `form:"enabled" json:"enabled"`
+
+	// Url is the endpoint that will receive the security events POST requests.
+	Url string `form:"url" json:"url"`
+
+	// Secret is the shared secret used to sign the request body (HMAC-SHA256).
+	Secret string `form:"secret" json:"secret"`
+
+	// Events is a list with the security events to deliver (see [SecurityEvents]).
+	//
+	// Leave empty to deliver all security events.
+	Events []string `form:"events" json:"events"`
+
+	// Timeout is the max duration in seconds of a single delivery attempt.
+	Timeout int64 `form:"timeout" json:"timeout"`
+
+	// MaxRetries is the max number of retries (with exponential backoff)
+	// after a failed delivery attempt.
+	MaxRetries int `form:"maxRetries" json:"maxRetries"`
+}
+
+// Validate makes SecurityEventsConfig validatable by implementing [validation.Validatable] interface.
+func (c SecurityEventsConfig) Validate() error {
+	return validation.ValidateStruct(&c,
+		validation.Field(&c.Url, validation.When(c.Enabled, validation.Required), is.URL),
+		validation.Field(&c.Secret, validation.When(c.Enabled, validation.Required, validation.Length(10, 255))),
+		validation.Field(&c.Events, validation.Each(validation.In(list.ToInterfaceSlice(SecurityEvents())...))),
+		validation.Field(&c.Timeout, validation.Min(int64(0)), validation.Max(int64(300))),
+		validation.Field(&c.MaxRetries, validation.Min(0), validation.Max(10)),
+	)
+}
+
+// Matches reports whether the specified security event should be delivered.
+func (c SecurityEventsConfig) Matches(event string) bool {
+	return c.Enabled && (len(c.Events) == 0 || list.ExistInSlice(event, c.Events))
+}
+
+// -------------------------------------------------------------------
+
 type AuthProviderConfig struct {
 	Enabled      bool   `form:"enabled" json:"enabled"`
 	ClientId     string `form:"clientId" json:"clientId"`
@@ -603,6 +1708,13 @@ type AuthProviderConfig struct {
 	AuthUrl      string `form:"authUrl" json:"authUrl"`
 	TokenUrl     string `form:"tokenUrl" json:"tokenUrl"`
 	UserApiUrl   string `form:"userApiUrl" json:"userApiUrl"`
+
+	// IssuerUrl is the OpenID Connect issuer used to discover the
+	// provider endpoints (supported only by the OIDC providers).
+	//
+	// The explicitly set auth, token and user api urls take precedence
+	// over the discovered ones.
+	IssuerUrl string `form:"issuerUrl" json:"issuerUrl"`
 }
 
 // Validate makes `ProviderConfig` validatable by implementing [validation.Validatable] interface.
@@ -613,6 +1725,7 @@ func (c AuthProviderConfig) Validate() error {
 		validation.Field(&c.AuthUrl, is.URL),
 		validation.Field(&c.TokenUrl, is.URL),
 		validation.Field(&c.UserApiUrl, is.URL),
+		validation.Field(&c.IssuerUrl, is.URL),
 	)
 }
 
@@ -630,6 +1743,19 @@ func (c AuthProviderConfig) SetupProvider(provider auth.Provider) error {
 		provider.SetClientSecret(c.ClientSecret)
 	}
 
+	if c.IssuerUrl != "" {
+		oidc, ok := provider.(*auth.OIDC)
+		if !ok {
+			return errors.New("The provider doesn't support OpenID Connect discovery.")
+		}
+
+		oidc.SetIssuerUrl(c.IssuerUrl)
+
+		if err := oidc.Discover(); err != nil {
+			return err
+		}
+	}
+
 	if c.AuthUrl != "" {
 		provider.SetAuthUrl(c.AuthUrl)
 	}
@@ -659,3 +1785,26 @@ type EmailAuthConfig struct {
 func (c EmailAuthConfig) Validate() error {
 	return nil
 }
+
+// -------------------------------------------------------------------
+
+type MetricsConfig struct {
+	// Enabled exposes the Prometheus metrics endpoint (/api/metrics)
+	// and starts recording the HTTP requests metrics.
+	Enabled bool `form:"enabled" json:"enabled"`
+
+	// RequireAuth restricts the metrics endpoint access only to
+	// admins or to requests with a valid "Authorization: Bearer TOKEN" header.
+	RequireAuth bool `form:"requireAuth" json:"requireAuth"`
+
+	// Token is an optional static bearer token for the metrics scrapers
+	// (applicable only when RequireAuth is set).
+	Token string `form:"token" json:"token"`
+}
+
+// Validate makes MetricsConfig validatable by implementing [validation.Validatable] interface.
+func (c MetricsConfig) Validate() error {
+	return validation.ValidateStruct(&c,
+		validation.Field(&c.Token, validation.Length(30, 300)),
+	)
+}
@@ -19,6 +19,14 @@ func TestSettingsValidate(t *testing.T) {
 	// set invalid settings data
 	s.Meta.AppName = ""
 	s.Logs.MaxDays = -10
+	s.RateLimits.Rules = []settings.RateLimitRule{{Label: ""}}
+	s.LoginLockout.Enabled = true
+	s.LoginLockout.MaxAttempts = 0
+	s.SecurityEvents.Enabled = true
+	s.SecurityEvents.Url = ""
+	s.Webhooks.Hooks = []settings.WebhookConfig{{Name: ""}}
+	s.Routes.TrailingSlash = "invalid"
+	s.TrustedProxy.Proxies = []string{"invalid"}
 	s.Smtp.Enabled = true
 	s.Smtp.Host = ""
 	s.S3.Enabled = true
@@ -26,11 +34,14 @@ func TestSettingsValidate(t *testing.T) {
 	s.AdminAuthToken.Duration = -10
 	s.AdminPasswordResetToken.Duration = -10
 	s.AdminFileToken.Duration = -10
+	s.AdminMFAToken.Duration = -10
 	s.RecordAuthToken.Duration = -10
 	s.RecordPasswordResetToken.Duration = -10
 	s.RecordEmailChangeToken.Duration = -10
 	s.RecordVerificationToken.Duration = -10
 	s.RecordFileToken.Duration = -10
+	s.RecordMFAToken.Duration = -10
+	s.FileSignedUrlToken.Duration = -10
 	s.GoogleAuth.Enabled = true
 	s.GoogleAuth.ClientId = ""
 	s.FacebookAuth.Enabled = true
@@ -83,16 +94,25 @@ func TestSettingsValidate(t *testing.T) {
 	expectations := []string{
 		`"meta":{`,
 		`"logs":{`,
+		`"rateLimits":{`,
+		`"loginLockout":{`,
+		`"securityEvents":{`,
+		`"webhooks":{`,
+		`"routes":{`,
+		`"trustedProxy":{`,
 		`"smtp":{`,
 		`"s3":{`,
 		`"adminAuthToken":{`,
 		`"adminPasswordResetToken":{`,
 		`"adminFileToken":{`,
+		`"adminMFAToken":{`,
 		`"recordAuthToken":{`,
 		`"recordPasswordResetToken":{`,
 		`"recordEmailChangeToken":{`,
 		`"recordVerificationToken":{`,
 		`"recordFileToken":{`,
+		`"recordMFAToken":{`,
+		`"fileSignedUrlToken":{`,
 		`"googleAuth":{`,
 		`"facebookAuth":{`,
 		`"githubAuth":{`,
@@ -145,6 +165,9 @@ func TestSettingsMerge(t *testing.T) {
 	s2.RecordEmailChangeToken.Duration = 5
 	s2.RecordVerificationToken.Duration = 6
 	s2.RecordFileToken.Duration = 7
+	s2.AdminMFAToken.Duration = 8
+	s2.RecordMFAToken.Duration = 9
+	s2.FileSignedUrlToken.Duration = 10
 	s2.GoogleAuth.Enabled = true
 	s2.GoogleAuth.ClientId = "google_test"
 	s2.FacebookAuth.Enabled = true
@@ -251,11 +274,15 @@ func TestSettingsRedactClone(t *testing.T) {
 	s1.AdminAuthToken.Secret = testSecret
 	s1.AdminPasswordResetToken.Secret = testSecret
 	s1.AdminFileToken.Secret = testSecret
+	s1.AdminMFAToken.Secret = testSecret
 	s1.RecordAuthToken.Secret = testSecret
 	s1.RecordPasswordResetToken.Secret = testSecret
 	s1.RecordEmailChangeToken.Secret = testSecret
 	s1.RecordVerificationToken.Secret = testSecret
 	s1.RecordFileToken.Secret = testSecret
+	s1.RecordMFAToken.Secret = testSecret
+	s1.FileSignedUrlToken.Secret = testSecret
+	s1.Metrics.Token = testSecret
 	s1.GoogleAuth.ClientSecret = testSecret
 	s1.FacebookAuth.ClientSecret = testSecret
 	s1.GithubAuth.ClientSecret = testSecret
@@ -277,6 +304,8 @@ func TestSettingsRedactClone(t *testing.T) {
 	s1.InstagramAuth.ClientSecret = testSecret
 	s1.VKAuth.ClientSecret = testSecret
 	s1.YandexAuth.ClientSecret = testSecret
+	s1.Webhooks.Hooks = []settings.WebhookConfig{{Name: "test", Secret: testSecret}}
+	s1.SecurityEvents.Secret = testSecret
 
 	s1Bytes, err := json.Marshal(s1)
 	if err != nil {
@@ -310,6 +339,51 @@ func TestSettingsRedactClone(t *testing.T) {
 	}
 }
 
+func TestSettingsRedactedDiff(t *testing.T) {
+	s1 := settings.New()
+	s1.Meta.AppName = "old"
+	s1.Smtp.Password = "old_secret"
+	s1.S3.Secret = "same_secret"
+
+	s2, err := s1.Clone()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// no changes
+	diff, err := s1.RedactedDiff(s2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(diff) != 0 {
+		t.Fatalf("Expected no changes, got %v", diff)
+	}
+
+	s2.Meta.AppName = "new"
+	s2.Smtp.Password = "new_secret"
+	s2.Cors.Default.AllowOrigins = []string{"https://example.com"}
+
+	diff, err = s1.RedactedDiff(s2)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	raw, err := json.Marshal(diff)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `{` +
+		`"cors.default.allowOrigins":{"old":["*"],"new":["https://example.com"]},` +
+		`"meta.appName":{"old":"old","new":"new"},` +
+		`"smtp.password":{"old":"******","new":"******"}` +
+		`}`
+
+	if string(raw) != expected {
+		t.Fatalf("Expected\n%s\ngot\n%s", expected, raw)
+	}
+}
+
 func TestNamedAuthProviderConfigs(t *testing.T) {
 	s := settings.New()
 
@@ -559,6 +633,18 @@ func TestS3ConfigValidate(t *testing.T) {
 			},
 			false,
 		},
+		// valid data (default AWS endpoint)
+		{
+			settings.S3Config{
+				Enabled:        true,
+				Bucket:         "test",
+				Region:         "test",
+				AccessKey:      "test",
+				Secret:         "test",
+				ForcePathStyle: true,
+			},
+			false,
+		},
 	}
 
 	for i, scenario := range scenarios {
@@ -700,6 +786,25 @@ func TestBackupsConfigValidate(t *testing.T) {
 			},
 			[]string{},
 		},
+		{
+			"negative calendar retention",
+			settings.BackupsConfig{
+				Cron:            "*/10 * * * *",
+				CronMaxKeep:     1,
+				CronKeepDaily:   -1,
+				CronKeepWeekly:  -1,
+				CronKeepMonthly: -1,
+			},
+			[]string{"cronKeepDaily", "cronKeepWeekly", "cronKeepMonthly"},
+		},
+		{
+			"calendar retention without max keep",
+			settings.BackupsConfig{
+				Cron:          "*/10 * * * *",
+				CronKeepDaily: 7,
+			},
+			[]string{},
+		},
 	}
 
 	for _, s := range scenarios {
@@ -848,6 +953,42 @@ func TestEmailTemplateResolve(t *testing.T) {
 	}
 }
 
+func TestEmailTemplateUnknownPlaceholders(t *testing.T) {
+	scenarios := []struct {
+		emailTemplate settings.EmailTemplate
+		expected      []string
+	}{
+		{
+			settings.EmailTemplate{},
+			[]string{},
+		},
+		{
+			settings.EmailTemplate{
+				Subject:   "{APP_NAME} {APP_URL}",
+				ActionUrl: "{APP_URL}/{TOKEN}",
+				Body:      "{APP_NAME} {ACTION_URL} {TOKEN} {lower} {}",
+			},
+			[]string{},
+		},
+		{
+			settings.EmailTemplate{
+				Subject:   "{TOKEN} {MISSING}",
+				ActionUrl: "{APP_URL}/{ACTION_URL}",
+				Body:      "{MISSING} {USER_NAME} {ACTION_URL}",
+			},
+			[]string{"{TOKEN}", "{MISSING}", "{ACTION_URL}", "{USER_NAME}"},
+		},
+	}
+
+	for i, s := range scenarios {
+		result := s.emailTemplate.UnknownPlaceholders()
+
+		if strings.Join(result, ",") != strings.Join(s.expected, ",") {
+			t.Errorf("(%d) Expected %v, got %v", i, s.expected, result)
+		}
+	}
+}
+
 func TestLogsConfigValidate(t *testing.T) {
 	scenarios := []struct {
 		config      settings.LogsConfig
@@ -883,52 +1024,74 @@ func TestLogsConfigValidate(t *testing.T) {
 	}
 }
 
-func TestAuthProviderConfigValidate(t *testing.T) {
+func TestTimeoutsConfigValidate(t *testing.T) {
 	scenarios := []struct {
-		config      settings.AuthProviderConfig
+		config      settings.TimeoutsConfig
+		expectError bool
+	}{
+		// zero values
+		{
+			settings.TimeoutsConfig{},
+			false,
+		},
+		// negative request timeout
+		{
+			settings.TimeoutsConfig{Request: -1},
+			true,
+		},
+		// negative realtime keep-alive interval
+		{
+			settings.TimeoutsConfig{RealtimeKeepAlive: -1},
+			true,
+		},
+		// valid data
+		{
+			settings.TimeoutsConfig{Request: 30, RealtimeKeepAlive: 30},
+			false,
+		},
+	}
+
+	for i, scenario := range scenarios {
+		result := scenario.config.Validate()
+
+		if result != nil && !scenario.expectError {
+			t.Errorf("(%d) Didn't expect error, got %v", i, result)
+		}
+
+		if result == nil && scenario.expectError {
+			t.Errorf("(%d) Expected error, got nil", i)
+		}
+	}
+}
+
+func TestLoginLockoutConfigValidate(t *testing.T) {
+	scenarios := []struct {
+		config      settings.LoginLockoutConfig
 		expectError bool
 	}{
 		// zero values (disabled)
 		{
-			settings.AuthProviderConfig{},
+			settings.LoginLockoutConfig{},
 			false,
 		},
 		// zero values (enabled)
 		{
-			settings.AuthProviderConfig{Enabled: true},
+			settings.LoginLockoutConfig{Enabled: true},
 			true,
 		},
-		// invalid data
+		// negative values
 		{
-			settings.AuthProviderConfig{
-				Enabled:      true,
-				ClientId:     "",
-				ClientSecret: "",
-				AuthUrl:      "test",
-				TokenUrl:     "test",
-				UserApiUrl:   "test",
-			},
+			settings.LoginLockoutConfig{MaxAttempts: -1, Window: -1, Duration: -1},
 			true,
 		},
-		// valid data (only the required)
+		// too long window and duration
 		{
-			settings.AuthProviderConfig{
-				Enabled:      true,
-				ClientId:     "test",
-				ClientSecret: "test",
-			},
-			false,
+			settings.LoginLockoutConfig{Enabled: true, MaxAttempts: 5, Window: 86401, Duration: 86401},
+			true,
 		},
-		// valid data (fill all fields)
+		// valid data
 		{
-			settings.AuthProviderConfig{
-				Enabled:      true,
-				ClientId:     "test",
-				ClientSecret: "test",
-				AuthUrl:      "https://example.com",
-				TokenUrl:     "https://example.com",
-				UserApiUrl:   "https://example.com",
-			},
+			settings.LoginLockoutConfig{Enabled: true, MaxAttempts: 5, Window: 300, Duration: 900},
 			false,
 		},
 	}
@@ -946,44 +1109,1189 @@ func TestAuthProviderConfigValidate(t *testing.T) {
 	}
 }
 
-func TestAuthProviderConfigSetupProvider(t *testing.T) {
-	provider := auth.NewGithubProvider()
+func TestSecurityEventsConfigValidate(t *testing.T) {
+	scenarios := []struct {
+		config      settings.SecurityEventsConfig
+		expectError bool
+	}{
+		// zero values (disabled)
+		{
+			settings.SecurityEventsConfig{},
+			false,
+		},
+		// zero values (enabled)
+		{
+			settings.SecurityEventsConfig{Enabled: true},
+			true,
+		},
+		// invalid data
+		{
+			settings.SecurityEventsConfig{
+				Enabled:    true,
+				Url:        "invalid",
+				Secret:     "short",
+				Events:     []string{settings.SecurityEventAdminAuth, "invalid"},
+				Timeout:    301,
+				MaxRetries: 11,
+			},
+			true,
+		},
+		// valid data
+		{
+			settings.SecurityEventsConfig{
+				Enabled:    true,
+				Url:        "https://example.com/siem",
+				Secret:     "1234567890",
+				Events:     []string{settings.SecurityEventAdminAuth, settings.SecurityEventBackupRestore},
+				Timeout:    10,
+				MaxRetries: 3,
+			},
+			false,
+		},
+	}
 
-	// disabled config
-	c1 := settings.AuthProviderConfig{Enabled: false}
-	if err := c1.SetupProvider(provider); err == nil {
-		t.Errorf("Expected error, got nil")
+	for i, scenario := range scenarios {
+		result := scenario.config.Validate()
+
+		if result != nil && !scenario.expectError {
+			t.Errorf("(%d) Didn't expect error, got %v", i, result)
+		}
+
+		if result == nil && scenario.expectError {
+			t.Errorf("(%d) Expected error, got nil", i)
+		}
 	}
+}
 
-	c2 := settings.AuthProviderConfig{
-		Enabled:      true,
-		ClientId:     "test_ClientId",
-		ClientSecret: "test_ClientSecret",
-		AuthUrl:      "test_AuthUrl",
-		UserApiUrl:   "test_UserApiUrl",
-		TokenUrl:     "test_TokenUrl",
+func TestSecurityEventsConfigMatches(t *testing.T) {
+	scenarios := []struct {
+		config   settings.SecurityEventsConfig
+		event    string
+		expected bool
+	}{
+		{settings.SecurityEventsConfig{}, settings.SecurityEventAdminAuth, false},
+		{settings.SecurityEventsConfig{Enabled: true}, settings.SecurityEventAdminAuth, true},
+		{settings.SecurityEventsConfig{Enabled: true, Events: []string{settings.SecurityEventRecordAuth}}, settings.SecurityEventAdminAuth, false},
+		{settings.SecurityEventsConfig{Enabled: true, Events: []string{settings.SecurityEventAdminAuth}}, settings.SecurityEventAdminAuth, true},
 	}
-	if err := c2.SetupProvider(provider); err != nil {
-		t.Error(err)
+
+	for i, s := range scenarios {
+		if result := s.config.Matches(s.event); result != s.expected {
+			t.Errorf("(%d) Expected %v, got %v", i, s.expected, result)
+		}
 	}
+}
 
-	if provider.ClientId() != c2.ClientId {
-		t.Fatalf("Expected ClientId %s, got %s", c2.ClientId, provider.ClientId())
+func TestImpersonationConfigValidate(t *testing.T) {
+	scenarios := []struct {
+		config      settings.ImpersonationConfig
+		expectError bool
+	}{
+		// zero values
+		{
+			settings.ImpersonationConfig{},
+			false,
+		},
+		// negative duration
+		{
+			settings.ImpersonationConfig{Enabled: true, Duration: -1},
+			true,
+		},
+		// too long duration
+		{
+			settings.ImpersonationConfig{Enabled: true, Duration: 63072001},
+			true,
+		},
+		// valid data
+		{
+			settings.ImpersonationConfig{Enabled: true, Duration: 600},
+			false,
+		},
 	}
 
-	if provider.ClientSecret() != c2.ClientSecret {
-		t.Fatalf("Expected ClientSecret %s, got %s", c2.ClientSecret, provider.ClientSecret())
+	for i, scenario := range scenarios {
+		result := scenario.config.Validate()
+
+		if result != nil && !scenario.expectError {
+			t.Errorf("(%d) Didn't expect error, got %v", i, result)
+		}
+
+		if result == nil && scenario.expectError {
+			t.Errorf("(%d) Expected error, got nil", i)
+		}
 	}
+}
 
-	if provider.AuthUrl() != c2.AuthUrl {
-		t.Fatalf("Expected AuthUrl %s, got %s", c2.AuthUrl, provider.AuthUrl())
+func TestThumbsConfigValidate(t *testing.T) {
+	scenarios := []struct {
+		config      settings.ThumbsConfig
+		expectError bool
+	}{
+		// zero values
+		{
+			settings.ThumbsConfig{},
+			false,
+		},
+		// invalid size format
+		{
+			settings.ThumbsConfig{Sizes: []string{"100x100", "invalid"}},
+			true,
+		},
+		// zero width and height
+		{
+			settings.ThumbsConfig{Sizes: []string{"0x0f"}},
+			true,
+		},
+		// valid data
+		{
+			settings.ThumbsConfig{Sizes: []string{"300x0", "0x300", "200x200f", "50x50t"}},
+			false,
+		},
 	}
 
-	if provider.UserApiUrl() != c2.UserApiUrl {
-		t.Fatalf("Expected UserApiUrl %s, got %s", c2.UserApiUrl, provider.UserApiUrl())
+	for i, scenario := range scenarios {
+		result := scenario.config.Validate()
+
+		if result != nil && !scenario.expectError {
+			t.Errorf("(%d) Didn't expect error, got %v", i, result)
+		}
+
+		if result == nil && scenario.expectError {
+			t.Errorf("(%d) Expected error, got nil", i)
+		}
 	}
+}
 
-	if provider.TokenUrl() != c2.TokenUrl {
-		t.Fatalf("Expected TokenUrl %s, got %s", c2.TokenUrl, provider.TokenUrl())
+func TestMetricsConfigValidate(t *testing.T) {
+	scenarios := []struct {
+		config      settings.MetricsConfig
+		expectError bool
+	}{
+		// zero values
+		{
+			settings.MetricsConfig{},
+			false,
+		},
+		// too short token
+		{
+			settings.MetricsConfig{Enabled: true, RequireAuth: true, Token: strings.Repeat("a", 29)},
+			true,
+		},
+		// valid data
+		{
+			settings.MetricsConfig{Enabled: true, RequireAuth: true, Token: strings.Repeat("a", 30)},
+			false,
+		},
+	}
+
+	for i, scenario := range scenarios {
+		result := scenario.config.Validate()
+
+		if result != nil && !scenario.expectError {
+			t.Errorf("(%d) Didn't expect error, got %v", i, result)
+		}
+
+		if result == nil && scenario.expectError {
+			t.Errorf("(%d) Expected error, got nil", i)
+		}
+	}
+}
+
+func TestCompressionConfigValidate(t *testing.T) {
+	scenarios := []struct {
+		config      settings.CompressionConfig
+		expectError bool
+	}{
+		// zero values
+		{
+			settings.CompressionConfig{},
+			false,
+		},
+		// negative level
+		{
+			settings.CompressionConfig{Level: -1},
+			true,
+		},
+		// too high level
+		{
+			settings.CompressionConfig{Level: 10},
+			true,
+		},
+		// negative min length
+		{
+			settings.CompressionConfig{MinLength: -1},
+			true,
+		},
+		// valid data
+		{
+			settings.CompressionConfig{Enabled: true, Level: 9, MinLength: 1024},
+			false,
+		},
+	}
+
+	for i, scenario := range scenarios {
+		result := scenario.config.Validate()
+
+		if result != nil && !scenario.expectError {
+			t.Errorf("(%d) Didn't expect error, got %v", i, result)
+		}
+
+		if result == nil && scenario.expectError {
+			t.Errorf("(%d) Expected error, got nil", i)
+		}
+	}
+}
+
+func TestPasswordHashingConfigValidate(t *testing.T) {
+	scenarios := []struct {
+		config      settings.PasswordHashingConfig
+		expectError bool
+	}{
+		// zero values
+		{
+			settings.PasswordHashingConfig{},
+			true,
+		},
+		// unsupported algorithm
+		{
+			settings.PasswordHashingConfig{Algorithm: "md5"},
+			true,
+		},
+		// bcrypt with invalid cost
+		{
+			settings.PasswordHashingConfig{Algorithm: "bcrypt", BcryptCost: 3},
+			true,
+		},
+		// bcrypt with valid cost (and invalid argon2 params)
+		{
+			settings.PasswordHashingConfig{Algorithm: "bcrypt", BcryptCost: 10},
+			false,
+		},
+		// argon2id with invalid params
+		{
+			settings.PasswordHashingConfig{Algorithm: "argon2id", Argon2Memory: 1024, Argon2Iterations: 0, Argon2Parallelism: 0},
+			true,
+		},
+		// argon2id with valid params (and invalid bcrypt cost)
+		{
+			settings.PasswordHashingConfig{Algorithm: "argon2id", Argon2Memory: 64 * 1024, Argon2Iterations: 3, Argon2Parallelism: 2},
+			false,
+		},
+	}
+
+	for i, scenario := range scenarios {
+		result := scenario.config.Validate()
+
+		if result != nil && !scenario.expectError {
+			t.Errorf("(%d) Didn't expect error, got %v", i, result)
+		}
+
+		if result == nil && scenario.expectError {
+			t.Errorf("(%d) Expected error, got nil", i)
+		}
+	}
+}
+
+func TestPasswordHashingConfigOptions(t *testing.T) {
+	config := settings.PasswordHashingConfig{
+		Algorithm:         "argon2id",
+		BcryptCost:        10,
+		Argon2Memory:      1024,
+		Argon2Iterations:  2,
+		Argon2Parallelism: 3,
+	}
+
+	opts := config.Options()
+
+	if opts.Algorithm != "argon2id" ||
+		opts.BcryptCost != 10 ||
+		opts.Argon2Memory != 1024 ||
+		opts.Argon2Iterations != 2 ||
+		opts.Argon2Parallelism != 3 {
+		t.Fatalf("Unexpected options %v", opts)
+	}
+}
+
+func TestIpFilterConfigValidate(t *testing.T) {
+	scenarios := []struct {
+		config      settings.IpFilterConfig
+		expectError bool
+	}{
+		// zero values
+		{
+			settings.IpFilterConfig{},
+			false,
+		},
+		// invalid allow item
+		{
+			settings.IpFilterConfig{Allow: []string{"127.0.0.1", "invalid"}},
+			true,
+		},
+		// invalid deny item
+		{
+			settings.IpFilterConfig{Deny: []string{"10.0.0.0/33"}},
+			true,
+		},
+		// valid data
+		{
+			settings.IpFilterConfig{
+				Allow: []string{"127.0.0.1", "10.0.0.0/8", "2001:db8::/32"},
+				Deny:  []string{"10.1.0.0/16", "::1"},
+			},
+			false,
+		},
+	}
+
+	for i, scenario := range scenarios {
+		result := scenario.config.Validate()
+
+		if result != nil && !scenario.expectError {
+			t.Errorf("(%d) Didn't expect error, got %v", i, result)
+		}
+
+		if result == nil && scenario.expectError {
+			t.Errorf("(%d) Expected error, got nil", i)
+		}
+	}
+}
+
+func TestIpFilterConfigIsAllowed(t *testing.T) {
+	config := settings.IpFilterConfig{
+		Allow: []string{"127.0.0.1", "10.0.0.0/8", "2001:db8::/32"},
+		Deny:  []string{"10.1.0.0/16", "2001:db8::1"},
+	}
+
+	scenarios := []struct {
+		config   settings.IpFilterConfig
+		ip       string
+		expected bool
+	}{
+		{settings.IpFilterConfig{}, "", false},
+		{settings.IpFilterConfig{}, "invalid", false},
+		{settings.IpFilterConfig{}, "1.2.3.4", true},
+		{settings.IpFilterConfig{Deny: []string{"1.2.3.0/24"}}, "1.2.3.4", false},
+		{config, "127.0.0.1", true},
+		{config, "127.0.0.2", false},
+		{config, "10.2.3.4", true},
+		{config, "10.1.3.4", false},
+		{config, "2001:db8::2", true},
+		{config, "2001:db8::1", false},
+		{config, "2001:db9::1", false},
+	}
+
+	for i, s := range scenarios {
+		result := s.config.IsAllowed(s.ip)
+		if result != s.expected {
+			t.Errorf("(%d) Expected %v for ip %q, got %v", i, s.expected, s.ip, result)
+		}
+	}
+}
+
+func TestTrustedProxyConfigValidate(t *testing.T) {
+	scenarios := []struct {
+		config      settings.TrustedProxyConfig
+		expectError bool
+	}{
+		// zero values
+		{
+			settings.TrustedProxyConfig{},
+			false,
+		},
+		// invalid proxy item
+		{
+			settings.TrustedProxyConfig{Proxies: []string{"127.0.0.1", "invalid"}},
+			true,
+		},
+		// valid data
+		{
+			settings.TrustedProxyConfig{Proxies: []string{"127.0.0.1", "10.0.0.0/8", "::1"}},
+			false,
+		},
+	}
+
+	for i, scenario := range scenarios {
+		result := scenario.config.Validate()
+
+		if result != nil && !scenario.expectError {
+			t.Errorf("(%d) Didn't expect error, got %v", i, result)
+		}
+
+		if result == nil && scenario.expectError {
+			t.Errorf("(%d) Expected error, got nil", i)
+		}
+	}
+}
+
+func TestTrustedProxyConfigIsTrusted(t *testing.T) {
+	config := settings.TrustedProxyConfig{
+		Proxies: []string{"127.0.0.1", "10.0.0.0/8", "2001:db8::/32"},
+	}
+
+	scenarios := []struct {
+		config   settings.TrustedProxyConfig
+		ip       string
+		expected bool
+	}{
+		{settings.TrustedProxyConfig{}, "", false},
+		{settings.TrustedProxyConfig{}, "1.2.3.4", false},
+		{config, "invalid", false},
+		{config, "127.0.0.1", true},
+		{config, "127.0.0.2", false},
+		{config, "10.2.3.4", true},
+		{config, "2001:db8::2", true},
+		{config, "2001:db9::1", false},
+	}
+
+	for i, s := range scenarios {
+		result := s.config.IsTrusted(s.ip)
+		if result != s.expected {
+			t.Errorf("(%d) Expected %v for ip %q, got %v", i, s.expected, s.ip, result)
+		}
+	}
+}
+
+func TestRoutesConfigValidate(t *testing.T) {
+	scenarios := []struct {
+		config      settings.RoutesConfig
+		expectError bool
+	}{
+		// zero values
+		{
+			settings.RoutesConfig{},
+			false,
+		},
+		// invalid trailing slash mode
+		{
+			settings.RoutesConfig{TrailingSlash: "invalid"},
+			true,
+		},
+		// valid data
+		{
+			settings.RoutesConfig{TrailingSlash: settings.TrailingSlashStrip, CaseInsensitive: true},
+			false,
+		},
+		{
+			settings.RoutesConfig{TrailingSlash: settings.TrailingSlashAppend},
+			false,
+		},
+	}
+
+	for i, scenario := range scenarios {
+		result := scenario.config.Validate()
+
+		if result != nil && !scenario.expectError {
+			t.Errorf("(%d) Didn't expect error, got %v", i, result)
+		}
+
+		if result == nil && scenario.expectError {
+			t.Errorf("(%d) Expected error, got nil", i)
+		}
+	}
+}
+
+func TestCorsConfigValidate(t *testing.T) {
+	scenarios := []struct {
+		config      settings.CorsConfig
+		expectError bool
+	}{
+		// zero values
+		{
+			settings.CorsConfig{},
+			false,
+		},
+		// invalid default rule
+		{
+			settings.CorsConfig{Default: settings.CorsRule{AllowOrigins: []string{"invalid"}}},
+			true,
+		},
+		// invalid realtime rule
+		{
+			settings.CorsConfig{Realtime: settings.CorsRule{MaxAge: -1}},
+			true,
+		},
+		// invalid files rule
+		{
+			settings.CorsConfig{Files: settings.CorsRule{AllowMethods: []string{"invalid"}}},
+			true,
+		},
+		// valid data
+		{
+			settings.CorsConfig{
+				Enabled:  true,
+				Default:  settings.CorsRule{AllowOrigins: []string{"https://example.com"}},
+				Realtime: settings.CorsRule{AllowOrigins: []string{"*"}},
+				Files:    settings.CorsRule{AllowOrigins: []string{"https://*.example.com"}},
+			},
+			false,
+		},
+	}
+
+	for i, scenario := range scenarios {
+		result := scenario.config.Validate()
+
+		if result != nil && !scenario.expectError {
+			t.Errorf("(%d) Didn't expect error, got %v", i, result)
+		}
+
+		if result == nil && scenario.expectError {
+			t.Errorf("(%d) Expected error, got nil", i)
+		}
+	}
+}
+
+func TestCorsConfigRuleFor(t *testing.T) {
+	config := settings.CorsConfig{
+		Default:  settings.CorsRule{AllowOrigins: []string{"https://default.com"}},
+		Realtime: settings.CorsRule{AllowOrigins: []string{"https://realtime.com"}},
+		Files:    settings.CorsRule{AllowOrigins: []string{"https://files.com"}},
+	}
+
+	scenarios := []struct {
+		config   settings.CorsConfig
+		path     string
+		expected string
+	}{
+		{config, "/", "https://default.com"},
+		{config, "/api/collections/demo1/records", "https://default.com"},
+		{config, "/api/realtime", "https://realtime.com"},
+		{config, "/api/realtime/test", "https://realtime.com"},
+		{config, "/api/realtimetest", "https://default.com"},
+		{config, "/api/files/demo1/abc/test.png", "https://files.com"},
+		{config, "/api/files", "https://default.com"},
+		// no overrides
+		{settings.CorsConfig{Default: config.Default}, "/api/realtime", "https://default.com"},
+		{settings.CorsConfig{Default: config.Default}, "/api/files/demo1/abc/test.png", "https://default.com"},
+	}
+
+	for i, s := range scenarios {
+		rule := s.config.RuleFor(s.path)
+		if len(rule.AllowOrigins) != 1 || rule.AllowOrigins[0] != s.expected {
+			t.Errorf("(%d) Expected %q rule for path %q, got %v", i, s.expected, s.path, rule.AllowOrigins)
+		}
+	}
+}
+
+func TestCorsRuleValidate(t *testing.T) {
+	scenarios := []struct {
+		rule        settings.CorsRule
+		expectError bool
+	}{
+		// zero values
+		{
+			settings.CorsRule{},
+			false,
+		},
+		// invalid origins
+		{
+			settings.CorsRule{AllowOrigins: []string{"example.com"}},
+			true,
+		},
+		{
+			settings.CorsRule{AllowOrigins: []string{"https://example.com/path"}},
+			true,
+		},
+		{
+			settings.CorsRule{AllowOrigins: []string{"https://example.com?a=1"}},
+			true,
+		},
+		{
+			settings.CorsRule{AllowOrigins: []string{"^https://(example.com$"}},
+			true,
+		},
+		// invalid methods
+		{
+			settings.CorsRule{AllowMethods: []string{"GET", "get"}},
+			true,
+		},
+		// invalid headers
+		{
+			settings.CorsRule{AllowHeaders: []string{"Content-Type", "invalid header"}},
+			true,
+		},
+		// invalid max age
+		{
+			settings.CorsRule{MaxAge: -1},
+			true,
+		},
+		{
+			settings.CorsRule{MaxAge: 86401},
+			true,
+		},
+		// wildcard origin with credentials
+		{
+			settings.CorsRule{AllowOrigins: []string{"https://example.com", "*"}, AllowCredentials: true},
+			true,
+		},
+		// valid data
+		{
+			settings.CorsRule{
+				AllowOrigins:     []string{"https://example.com", "http://localhost:3000", "https://*.example.com", `^https://(a|b)\.test\.com$`},
+				AllowMethods:     []string{"GET", "POST", "OPTIONS"},
+				AllowHeaders:     []string{"Authorization", "Content-Type", "X_Custom"},
+				AllowCredentials: true,
+				MaxAge:           3600,
+			},
+			false,
+		},
+		{
+			settings.CorsRule{AllowOrigins: []string{"*"}, AllowHeaders: []string{"*"}},
+			false,
+		},
+	}
+
+	for i, scenario := range scenarios {
+		result := scenario.rule.Validate()
+
+		if result != nil && !scenario.expectError {
+			t.Errorf("(%d) Didn't expect error, got %v", i, result)
+		}
+
+		if result == nil && scenario.expectError {
+			t.Errorf("(%d) Expected error, got nil", i)
+		}
+	}
+}
+
+func TestCorsRuleOriginMatcher(t *testing.T) {
+	scenarios := []struct {
+		origins  []string
+		origin   string
+		expected bool
+	}{
+		{nil, "https://example.com", false},
+		{[]string{"*"}, "https://example.com", true},
+		{[]string{"invalid", "*"}, "https://example.com", true},
+		{[]string{"https://example.com"}, "https://example.com", true},
+		{[]string{"https://example.com/"}, "https://example.com", true},
+		{[]string{"https://example.com"}, "http://example.com", false},
+		{[]string{"https://example.com"}, "https://example.com:8080", false},
+		{[]string{"https://example.com"}, "https://examplexcom", false},
+		{[]string{"https://*.example.com"}, "https://a.example.com", true},
+		{[]string{"https://*.example.com"}, "https://a.b.example.com", true},
+		{[]string{"https://*.example.com"}, "https://example.com", false},
+		{[]string{"https://*.example.com"}, "https://a.example.com.evil.com", false},
+		{[]string{"http://localhost:*"}, "http://localhost:3000", true},
+		{[]string{`^https://(a|b)\.test\.com$`}, "https://a.test.com", true},
+		{[]string{`^https://(a|b)\.test\.com$`}, "https://c.test.com", false},
+	}
+
+	for i, s := range scenarios {
+		rule := settings.CorsRule{AllowOrigins: s.origins}
+
+		result := rule.OriginMatcher()(s.origin)
+		if result != s.expected {
+			t.Errorf("(%d) Expected %v for origin %q, got %v", i, s.expected, s.origin, result)
+		}
+	}
+}
+
+func TestMaintenanceConfigValidate(t *testing.T) {
+	scenarios := []struct {
+		config      settings.MaintenanceConfig
+		expectError bool
+	}{
+		// zero values
+		{
+			settings.MaintenanceConfig{},
+			false,
+		},
+		// too long message
+		{
+			settings.MaintenanceConfig{ReadOnly: true, Message: strings.Repeat("a", 256)},
+			true,
+		},
+		// valid data
+		{
+			settings.MaintenanceConfig{ReadOnly: true, Message: "test"},
+			false,
+		},
+	}
+
+	for i, scenario := range scenarios {
+		result := scenario.config.Validate()
+
+		if result != nil && !scenario.expectError {
+			t.Errorf("(%d) Didn't expect error, got %v", i, result)
+		}
+
+		if result == nil && scenario.expectError {
+			t.Errorf("(%d) Expected error, got nil", i)
+		}
+	}
+}
+
+func TestBodyLimitsConfigValidate(t *testing.T) {
+	scenarios := []struct {
+		config      settings.BodyLimitsConfig
+		expectError bool
+	}{
+		// zero values
+		{
+			settings.BodyLimitsConfig{},
+			false,
+		},
+		// negative max size
+		{
+			settings.BodyLimitsConfig{MaxSize: -1},
+			true,
+		},
+		// negative max upload size
+		{
+			settings.BodyLimitsConfig{MaxUploadSize: -1},
+			true,
+		},
+		// valid data
+		{
+			settings.BodyLimitsConfig{MaxSize: 100, MaxUploadSize: 1000},
+			false,
+		},
+	}
+
+	for i, scenario := range scenarios {
+		result := scenario.config.Validate()
+
+		if result != nil && !scenario.expectError {
+			t.Errorf("(%d) Didn't expect error, got %v", i, result)
+		}
+
+		if result == nil && scenario.expectError {
+			t.Errorf("(%d) Expected error, got nil", i)
+		}
+	}
+}
+
+func TestRateLimitsConfigValidate(t *testing.T) {
+	scenarios := []struct {
+		config      settings.RateLimitsConfig
+		expectError bool
+	}{
+		// zero values
+		{
+			settings.RateLimitsConfig{},
+			false,
+		},
+		// invalid rule
+		{
+			settings.RateLimitsConfig{
+				Rules: []settings.RateLimitRule{
+					{Label: "test", MaxRequests: 0, Duration: 10},
+				},
+			},
+			true,
+		},
+		// duplicated labels
+		{
+			settings.RateLimitsConfig{
+				Rules: []settings.RateLimitRule{
+					{Label: "test", MaxRequests: 1, Duration: 10},
+					{Label: "test", MaxRequests: 2, Duration: 20},
+				},
+			},
+			true,
+		},
+		// valid data
+		{
+			settings.RateLimitsConfig{
+				Enabled: true,
+				Rules: []settings.RateLimitRule{
+					{Label: "test", MaxRequests: 1, Duration: 10},
+					{Label: settings.RateLimitDefaultLabel, MaxRequests: 2, Duration: 20},
+				},
+			},
+			false,
+		},
+	}
+
+	for i, scenario := range scenarios {
+		result := scenario.config.Validate()
+
+		if result != nil && !scenario.expectError {
+			t.Errorf("(%d) Didn't expect error, got %v", i, result)
+		}
+
+		if result == nil && scenario.expectError {
+			t.Errorf("(%d) Expected error, got nil", i)
+		}
+	}
+}
+
+func TestRateLimitsConfigFindRule(t *testing.T) {
+	withDefault := settings.RateLimitsConfig{
+		Rules: []settings.RateLimitRule{
+			{Label: settings.RateLimitDefaultLabel, MaxRequests: 1, Duration: 1},
+			{Label: "auth", MaxRequests: 2, Duration: 2},
+		},
+	}
+
+	withoutDefault := settings.RateLimitsConfig{
+		Rules: []settings.RateLimitRule{
+			{Label: "auth", MaxRequests: 2, Duration: 2},
+		},
+	}
+
+	scenarios := []struct {
+		config   settings.RateLimitsConfig
+		label    string
+		expected int // MaxRequests of the found rule (0 for nil)
+	}{
+		{settings.RateLimitsConfig{}, "auth", 0},
+		{withDefault, "auth", 2},
+		{withDefault, "missing", 1},
+		{withoutDefault, "auth", 2},
+		{withoutDefault, "missing", 0},
+	}
+
+	for i, s := range scenarios {
+		rule := s.config.FindRule(s.label)
+
+		var maxRequests int
+		if rule != nil {
+			maxRequests = rule.MaxRequests
+		}
+
+		if maxRequests != s.expected {
+			t.Errorf("(%d) Expected rule with %d MaxRequests, got %d", i, s.expected, maxRequests)
+		}
+	}
+}
+
+func TestRateLimitRuleValidate(t *testing.T) {
+	scenarios := []struct {
+		config      settings.RateLimitRule
+		expectError bool
+	}{
+		// zero values
+		{
+			settings.RateLimitRule{},
+			true,
+		},
+		// invalid data
+		{
+			settings.RateLimitRule{Label: "test", MaxRequests: -1, Duration: -1},
+			true,
+		},
+		// valid data
+		{
+			settings.RateLimitRule{Label: "test", MaxRequests: 1, Duration: 1},
+			false,
+		},
+	}
+
+	for i, scenario := range scenarios {
+		result := scenario.config.Validate()
+
+		if result != nil && !scenario.expectError {
+			t.Errorf("(%d) Didn't expect error, got %v", i, result)
+		}
+
+		if result == nil && scenario.expectError {
+			t.Errorf("(%d) Expected error, got nil", i)
+		}
+	}
+}
+
+func TestWebhooksConfigValidate(t *testing.T) {
+	validHook := settings.WebhookConfig{
+		Name:       "test",
+		Collection: "demo",
+		Url:        "https://example.com",
+		Secret:     "1234567890",
+	}
+
+	scenarios := []struct {
+		config      settings.WebhooksConfig
+		expectError bool
+	}{
+		// zero values
+		{
+			settings.WebhooksConfig{},
+			false,
+		},
+		// invalid hook
+		{
+			settings.WebhooksConfig{
+				Hooks: []settings.WebhookConfig{{Name: "test"}},
+			},
+			true,
+		},
+		// duplicated names
+		{
+			settings.WebhooksConfig{
+				Hooks: []settings.WebhookConfig{validHook, validHook},
+			},
+			true,
+		},
+		// valid data
+		{
+			settings.WebhooksConfig{
+				Enabled: true,
+				Hooks:   []settings.WebhookConfig{validHook},
+			},
+			false,
+		},
+	}
+
+	for i, scenario := range scenarios {
+		result := scenario.config.Validate()
+
+		if result != nil && !scenario.expectError {
+			t.Errorf("(%d) Didn't expect error, got %v", i, result)
+		}
+
+		if result == nil && scenario.expectError {
+			t.Errorf("(%d) Expected error, got nil", i)
+		}
+	}
+}
+
+func TestWebhooksConfigFindHooks(t *testing.T) {
+	config := settings.WebhooksConfig{
+		Hooks: []settings.WebhookConfig{
+			{Name: "a", Collection: "demo1"},
+			{Name: "b", Collection: "demo1", Events: []string{settings.WebhookEventDelete}},
+			{Name: "c", Collection: "id2", Events: []string{settings.WebhookEventCreate, settings.WebhookEventUpdate}},
+		},
+	}
+
+	scenarios := []struct {
+		event       string
+		collections []string
+		expected    []string
+	}{
+		{settings.WebhookEventCreate, nil, []string{}},
+		{settings.WebhookEventCreate, []string{"missing"}, []string{}},
+		{settings.WebhookEventCreate, []string{"id1", "DEMO1"}, []string{"a"}},
+		{settings.WebhookEventDelete, []string{"id1", "demo1"}, []string{"a", "b"}},
+		{settings.WebhookEventUpdate, []string{"id2", "demo2"}, []string{"c"}},
+		{settings.WebhookEventDelete, []string{"id2", "demo2"}, []string{}},
+	}
+
+	for i, s := range scenarios {
+		hooks := config.FindHooks(s.event, s.collections...)
+
+		names := make([]string, len(hooks))
+		for j, hook := range hooks {
+			names[j] = hook.Name
+		}
+
+		if strings.Join(names, ",") != strings.Join(s.expected, ",") {
+			t.Errorf("(%d) Expected hooks %v, got %v", i, s.expected, names)
+		}
+	}
+}
+
+func TestWebhooksConfigRestoreSecrets(t *testing.T) {
+	old := settings.WebhooksConfig{
+		Hooks: []settings.WebhookConfig{
+			{Name: "a", Secret: "secret_a"},
+			{Name: "b", Secret: "secret_b"},
+		},
+	}
+
+	config := settings.WebhooksConfig{
+		Hooks: []settings.WebhookConfig{
+			{Name: "b", Secret: settings.SecretMask},
+			{Name: "a", Secret: "new_secret"},
+			{Name: "c", Secret: settings.SecretMask},
+		},
+	}
+
+	config.RestoreSecrets(old)
+
+	expected := []string{"secret_b", "new_secret", settings.SecretMask}
+
+	for i, hook := range config.Hooks {
+		if hook.Secret != expected[i] {
+			t.Errorf("(%d) Expected secret %q, got %q", i, expected[i], hook.Secret)
+		}
+	}
+}
+
+func TestWebhookConfigValidate(t *testing.T) {
+	scenarios := []struct {
+		config      settings.WebhookConfig
+		expectError bool
+	}{
+		// zero values
+		{
+			settings.WebhookConfig{},
+			true,
+		},
+		// invalid data
+		{
+			settings.WebhookConfig{
+				Name:       "test",
+				Collection: "demo",
+				Url:        "invalid",
+				Secret:     "short",
+				Events:     []string{"invalid"},
+				Timeout:    -1,
+				MaxRetries: 100,
+			},
+			true,
+		},
+		// invalid event
+		{
+			settings.WebhookConfig{
+				Name:       "test",
+				Collection: "demo",
+				Url:        "https://example.com",
+				Secret:     "1234567890",
+				Events:     []string{settings.WebhookEventCreate, "invalid"},
+			},
+			true,
+		},
+		// valid data
+		{
+			settings.WebhookConfig{
+				Name:          "test",
+				Collection:    "demo",
+				Url:           "https://example.com",
+				Secret:        "1234567890",
+				Events:        []string{settings.WebhookEventCreate, settings.WebhookEventDelete},
+				Fields:        []string{"title"},
+				ExcludeFields: []string{"secret"},
+				Timeout:       10,
+				MaxRetries:    3,
+			},
+			false,
+		},
+	}
+
+	for i, scenario := range scenarios {
+		result := scenario.config.Validate()
+
+		if result != nil && !scenario.expectError {
+			t.Errorf("(%d) Didn't expect error, got %v", i, result)
+		}
+
+		if result == nil && scenario.expectError {
+			t.Errorf("(%d) Expected error, got nil", i)
+		}
+	}
+}
+
+func TestAuthProviderConfigValidate(t *testing.T) {
+	scenarios := []struct {
+		config      settings.AuthProviderConfig
+		expectError bool
+	}{
+		// zero values (disabled)
+		{
+			settings.AuthProviderConfig{},
+			false,
+		},
+		// zero values (enabled)
+		{
+			settings.AuthProviderConfig{Enabled: true},
+			true,
+		},
+		// invalid data
+		{
+			settings.AuthProviderConfig{
+				Enabled:      true,
+				ClientId:     "",
+				ClientSecret: "",
+				AuthUrl:      "test",
+				TokenUrl:     "test",
+				UserApiUrl:   "test",
+			},
+			true,
+		},
+		// invalid issuer url
+		{
+			settings.AuthProviderConfig{
+				Enabled:      true,
+				ClientId:     "test",
+				ClientSecret: "test",
+				IssuerUrl:    "test",
+			},
+			true,
+		},
+		// valid data (only the required)
+		{
+			settings.AuthProviderConfig{
+				Enabled:      true,
+				ClientId:     "test",
+				ClientSecret: "test",
+			},
+			false,
+		},
+		// valid data (fill all fields)
+		{
+			settings.AuthProviderConfig{
+				Enabled:      true,
+				ClientId:     "test",
+				ClientSecret: "test",
+				AuthUrl:      "https://example.com",
+				TokenUrl:     "https://example.com",
+				UserApiUrl:   "https://example.com",
+				IssuerUrl:    "https://example.com",
+			},
+			false,
+		},
+	}
+
+	for i, scenario := range scenarios {
+		result := scenario.config.Validate()
+
+		if result != nil && !scenario.expectError {
+			t.Errorf("(%d) Didn't expect error, got %v", i, result)
+		}
+
+		if result == nil && scenario.expectError {
+			t.Errorf("(%d) Expected error, got nil", i)
+		}
+	}
+}
+
+func TestAuthProviderConfigSetupProvider(t *testing.T) {
+	provider := auth.NewGithubProvider()
+
+	// disabled config
+	c1 := settings.AuthProviderConfig{Enabled: false}
+	if err := c1.SetupProvider(provider); err == nil {
+		t.Errorf("Expected error, got nil")
+	}
+
+	c2 := settings.AuthProviderConfig{
+		Enabled:      true,
+		ClientId:     "test_ClientId",
+		ClientSecret: "test_ClientSecret",
+		AuthUrl:      "test_AuthUrl",
+		UserApiUrl:   "test_UserApiUrl",
+		TokenUrl:     "test_TokenUrl",
+	}
+	if err := c2.SetupProvider(provider); err != nil {
+		t.Error(err)
+	}
+
+	if provider.ClientId() != c2.ClientId {
+		t.Fatalf("Expected ClientId %s, got %s", c2.ClientId, provider.ClientId())
+	}
+
+	if provider.ClientSecret() != c2.ClientSecret {
+		t.Fatalf("Expected ClientSecret %s, got %s", c2.ClientSecret, provider.ClientSecret())
+	}
+
+	if provider.AuthUrl() != c2.AuthUrl {
+		t.Fatalf("Expected AuthUrl %s, got %s", c2.AuthUrl, provider.AuthUrl())
+	}
+
+	if provider.UserApiUrl() != c2.UserApiUrl {
+		t.Fatalf("Expected UserApiUrl %s, got %s", c2.UserApiUrl, provider.UserApiUrl())
+	}
+
+	if provider.TokenUrl() != c2.TokenUrl {
+		t.Fatalf("Expected TokenUrl %s, got %s", c2.TokenUrl, provider.TokenUrl())
+	}
+
+	// issuer url for a provider without discovery support
+	c3 := settings.AuthProviderConfig{
+		Enabled:   true,
+		IssuerUrl: "https://example.com",
+	}
+	if err := c3.SetupProvider(provider); err == nil {
+		t.Errorf("Expected error, got nil")
 	}
 }
@@ -0,0 +1,30 @@
+package models
+
+import "github.com/unkod/space/tools/types"
+
+var _ Model = (*SettingsVersion)(nil)
+
+// SettingsVersion defines a single app settings change stored in the settings history.
+type SettingsVersion struct {
+	BaseModel
+
+	// AdminId is the id of the admin that made the change
+	// (empty for the changes that are not made via the api).
+	AdminId string `db:"adminId" json:"adminId"`
+
+	// RollbackOf is the id of the reverted version
+	// (empty for the regular settings changes).
+	RollbackOf string `db:"rollbackOf" json:"rollbackOf"`
+
+	// Changes holds the [settings.Change] entries indexed by their
+	// settings path (the secret values are masked).
+	Changes types.JsonMap `db:"changes" json:"changes"`
+
+	// Value is the serialized (and optionally encrypted) snapshot
+	// of the settings state before the change.
+	Value types.JsonRaw `db:"value" json:"-"`
+}
+
+func (m *SettingsVersion) TableName() string {
+	return "_settingsVersions"
+}
@@ -0,0 +1,34 @@
+package models_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/unkod/space/models"
+	"github.com/unkod/space/tools/types"
+)
+
+func TestSettingsVersionTableName(t *testing.T) {
+	m := models.SettingsVersion{}
+	if m.TableName() != "_settingsVersions" {
+		t.Fatalf("Unexpected table name, got %q", m.TableName())
+	}
+}
+
+func TestSettingsVersionMarshalJSON(t *testing.T) {
+	m := models.SettingsVersion{
+		AdminId: "test",
+		Value:   types.JsonRaw(`{"secret":"test"}`),
+	}
+
+	raw, err := json.Marshal(m)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `{"id":"","created":"","updated":"","adminId":"test","rollbackOf":"","changes":{}}`
+
+	if string(raw) != expected {
+		t.Fatalf("Expected\n%s\ngot\n%s", expected, raw)
+	}
+}
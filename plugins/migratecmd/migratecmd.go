@@ -23,8 +23,10 @@ import (
 	"time"
 
 	"github.com/AlecAivazis/survey/v2"
+	"github.com/pocketbase/dbx"
 	"github.com/spf13/cobra"
 	"github.com/unkod/space/core"
+	"github.com/unkod/space/daos"
 	"github.com/unkod/space/migrations"
 	"github.com/unkod/space/models"
 	"github.com/unkod/space/tools/inflector"
@@ -111,7 +113,7 @@ type plugin struct {
 func (p *plugin) createCommand() *cobra.Command {
 	const cmdDesc = `Supported arguments are:
 - up            - runs all available migrations
-- down [number] - reverts the last [number] applied migrations
+- down [number] - reverts the last [number] applied migrations (all of them must have a down function)
 - create name   - creates new blank migration template file
 - collections   - creates new migration file with snapshot of the local collections configuration
 - history-sync  - ensures that the _migrations history table doesn't have references to deleted migration files
@@ -146,9 +148,18 @@ func (p *plugin) createCommand() *cobra.Command {
 					return err
 				}
 
+				// ensure that the collections tables are still in sync after a revert
+				runner.SetVerifier(func(db dbx.Builder) error {
+					return daos.New(db).VerifyCollectionsTables()
+				})
+
 				if err := runner.Run(args...); err != nil {
 					return err
 				}
+
+				if cache := p.app.Dao().CollectionsCache; cache != nil {
+					cache.Reset()
+				}
 			}
 
 			return nil
@@ -44,11 +44,24 @@ migrate((db) => {
       "allowEmailAuth": false,
       "allowOAuth2Auth": false,
       "allowUsernameAuth": false,
+      "apiDisabled": false,
+      "audit": false,
+      "authTokenDuration": 0,
+      "disableAutoId": false,
+      "emailChangeTokenDuration": 0,
       "exceptEmailDomains": null,
+      "idPattern": "",
+      "jsonSchema": null,
       "manageRule": "created > 0",
       "minPasswordLength": 20,
       "onlyEmailDomains": null,
-      "requireEmail": false
+      "onlyVerified": false,
+      "passwordResetTokenDuration": 0,
+      "requireEmail": false,
+      "rotateAuthToken": false,
+      "searchFields": null,
+      "softDelete": false,
+      "verificationTokenDuration": 0
     }
   });
 
@@ -97,11 +110,24 @@ func init() {
 				"allowEmailAuth": false,
 				"allowOAuth2Auth": false,
 				"allowUsernameAuth": false,
+				"apiDisabled": false,
+				"audit": false,
+				"authTokenDuration": 0,
+				"disableAutoId": false,
+				"emailChangeTokenDuration": 0,
 				"exceptEmailDomains": null,
+				"idPattern": "",
+				"jsonSchema": null,
 				"manageRule": "created > 0",
 				"minPasswordLength": 20,
 				"onlyEmailDomains": null,
-				"requireEmail": false
+				"onlyVerified": false,
+				"passwordResetTokenDuration": 0,
+				"requireEmail": false,
+				"rotateAuthToken": false,
+				"searchFields": null,
+				"softDelete": false,
+				"verificationTokenDuration": 0
 			}
 		}` + "`" + `
 
@@ -222,11 +248,24 @@ migrate((db) => {
       "allowEmailAuth": false,
       "allowOAuth2Auth": false,
       "allowUsernameAuth": false,
+      "apiDisabled": false,
+      "audit": false,
+      "authTokenDuration": 0,
+      "disableAutoId": false,
+      "emailChangeTokenDuration": 0,
       "exceptEmailDomains": null,
+      "idPattern": "",
+      "jsonSchema": null,
       "manageRule": "created > 0",
       "minPasswordLength": 20,
       "onlyEmailDomains": null,
-      "requireEmail": false
+      "onlyVerified": false,
+      "passwordResetTokenDuration": 0,
+      "requireEmail": false,
+      "rotateAuthToken": false,
+      "searchFields": null,
+      "softDelete": false,
+      "verificationTokenDuration": 0
     }
   });
 
@@ -279,11 +318,24 @@ func init() {
 				"allowEmailAuth": false,
 				"allowOAuth2Auth": false,
 				"allowUsernameAuth": false,
+				"apiDisabled": false,
+				"audit": false,
+				"authTokenDuration": 0,
+				"disableAutoId": false,
+				"emailChangeTokenDuration": 0,
 				"exceptEmailDomains": null,
+				"idPattern": "",
+				"jsonSchema": null,
 				"manageRule": "created > 0",
 				"minPasswordLength": 20,
 				"onlyEmailDomains": null,
-				"requireEmail": false
+				"onlyVerified": false,
+				"passwordResetTokenDuration": 0,
+				"requireEmail": false,
+				"rotateAuthToken": false,
+				"searchFields": null,
+				"softDelete": false,
+				"verificationTokenDuration": 0
 			}
 		}` + "`" + `
 
@@ -383,7 +435,15 @@ migrate((db) => {
   collection.type = "base"
   collection.listRule = null
   collection.deleteRule = "updated > 0 && @request.auth.id != ''"
-  collection.options = {}
+  collection.options = {
+    "apiDisabled": false,
+    "audit": false,
+    "disableAutoId": false,
+    "idPattern": "",
+    "jsonSchema": null,
+    "searchFields": null,
+    "softDelete": false
+  }
   collection.indexes = [
     "create index test1 on test456_update (f1_name)"
   ]
@@ -403,7 +463,8 @@ migrate((db) => {
     "options": {
       "min": null,
       "max": null,
-      "pattern": "` + "`" + `test backtick` + "`" + `123"
+      "pattern": "` + "`" + `test backtick` + "`" + `123",
+      "encrypted": false
     }
   }))
 
@@ -435,11 +496,24 @@ migrate((db) => {
     "allowEmailAuth": false,
     "allowOAuth2Auth": false,
     "allowUsernameAuth": false,
+    "apiDisabled": false,
+    "audit": false,
+    "authTokenDuration": 0,
+    "disableAutoId": false,
+    "emailChangeTokenDuration": 0,
     "exceptEmailDomains": null,
+    "idPattern": "",
+    "jsonSchema": null,
     "manageRule": "created > 0",
     "minPasswordLength": 20,
     "onlyEmailDomains": null,
-    "requireEmail": false
+    "onlyVerified": false,
+    "passwordResetTokenDuration": 0,
+    "requireEmail": false,
+    "rotateAuthToken": false,
+    "searchFields": null,
+    "softDelete": false,
+    "verificationTokenDuration": 0
   }
   collection.indexes = [
     "create index test1 on test456 (f1_name)"
@@ -512,7 +586,15 @@ func init() {
 		collection.DeleteRule = types.Pointer("updated > 0 && @request.auth.id != ''")
 
 		options := map[string]any{}
-		json.Unmarshal([]byte(` + "`" + `{}` + "`" + `), &options)
+		json.Unmarshal([]byte(` + "`" + `{
+			"apiDisabled": false,
+			"audit": false,
+			"disableAutoId": false,
+			"idPattern": "",
+			"jsonSchema": null,
+			"searchFields": null,
+			"softDelete": false
+		}` + "`" + `), &options)
 		collection.SetOptions(options)
 
 		json.Unmarshal([]byte(` + "`" + `[
@@ -535,7 +617,8 @@ func init() {
 			"options": {
 				"min": null,
 				"max": null,
-				"pattern": ` + "\"` + \"`\" + `test backtick` + \"`\" + `123\"" + `
+				"pattern": ` + "\"` + \"`\" + `test backtick` + \"`\" + `123\"" + `,
+				"encrypted": false
 			}
 		}` + "`" + `), new_f4_name)
 		collection.Schema.AddField(new_f4_name)
@@ -579,11 +662,24 @@ func init() {
 			"allowEmailAuth": false,
 			"allowOAuth2Auth": false,
 			"allowUsernameAuth": false,
+			"apiDisabled": false,
+			"audit": false,
+			"authTokenDuration": 0,
+			"disableAutoId": false,
+			"emailChangeTokenDuration": 0,
 			"exceptEmailDomains": null,
+			"idPattern": "",
+			"jsonSchema": null,
 			"manageRule": "created > 0",
 			"minPasswordLength": 20,
 			"onlyEmailDomains": null,
-			"requireEmail": false
+			"onlyVerified": false,
+			"passwordResetTokenDuration": 0,
+			"requireEmail": false,
+			"rotateAuthToken": false,
+			"searchFields": null,
+			"softDelete": false,
+			"verificationTokenDuration": 0
 		}` + "`" + `), &options)
 		collection.SetOptions(options)
 
@@ -3,6 +3,7 @@ package resolvers
 import (
 	"encoding/json"
 	"fmt"
+	"regexp"
 	"strconv"
 	"strings"
 
@@ -13,8 +14,12 @@ import (
 	"github.com/unkod/space/tools/list"
 	"github.com/unkod/space/tools/search"
 	"github.com/unkod/space/tools/security"
+	"github.com/unkod/space/tools/types"
 )
 
+// jsonPathSegmentRegex matches a single json field path key or array index.
+var jsonPathSegmentRegex = regexp.MustCompile(`^\w+$`)
+
 // parseAndRun starts a new one-off RecordFieldResolver.Resolve execution.
 func parseAndRun(fieldName string, resolver *RecordFieldResolver) (*search.ResolverResult, error) {
 	r := &runner{
@@ -364,6 +369,36 @@ func (r *runner) processActiveProps() (*search.ResolverResult, error) {
 				return result, nil
 			}
 
+			// geoPoint field with ":distance(lat, lon)" modifier
+			// -------------------------------------------------------
+			distanceName, origin, err := splitDistanceModifier(prop)
+			if err != nil {
+				return nil, err
+			}
+			if distanceName != "" {
+				field := collection.Schema.GetFieldByName(distanceName)
+				if field == nil || field.Type != schema.FieldTypeGeoPoint {
+					return nil, fmt.Errorf("field %q is not a valid geoPoint", distanceName)
+				}
+
+				if err := r.checkFieldReadRule(field); err != nil {
+					return nil, err
+				}
+
+				cleanFieldName := inflector.Columnify(field.Name)
+
+				result := &search.ResolverResult{
+					Identifier: geoDistance(r.activeTableAlias+"."+cleanFieldName, origin),
+				}
+
+				if r.withMultiMatch {
+					r.multiMatch.valueIdentifier = geoDistance(r.multiMatchActiveTableAlias+"."+cleanFieldName, origin)
+					result.MultiMatchSubQuery = r.multiMatch
+				}
+
+				return result, nil
+			}
+
 			name, modifier, err := splitModifier(prop)
 			if err != nil {
 				return nil, err
@@ -377,8 +412,44 @@ func (r *runner) processActiveProps() (*search.ResolverResult, error) {
 				return nil, fmt.Errorf("unknown field %q", name)
 			}
 
+			if field.Type == schema.FieldTypeComputed {
+				return nil, fmt.Errorf("computed field %q can't be used for filtering or sorting", name)
+			}
+
+			if err := r.checkFieldReadRule(field); err != nil {
+				return nil, err
+			}
+
 			cleanFieldName := inflector.Columnify(field.Name)
 
+			// encrypted fields (only exact-match is supported)
+			// -------------------------------------------------------
+			field.InitOptions()
+			if opt, ok := field.Options.(schema.Encryptable); ok && opt.IsEncrypted() {
+				if modifier != "" || field.Type != schema.FieldTypeText {
+					return nil, fmt.Errorf("encrypted field %q can be used only for exact-match filtering", name)
+				}
+
+				encrypter, ok := r.resolver.dao.(FieldValueEncrypter)
+				if !ok {
+					return nil, fmt.Errorf("failed to initialize the encrypted field %q resolver", name)
+				}
+
+				result := &search.ResolverResult{
+					Identifier: fmt.Sprintf("[[%s.%s]]", r.activeTableAlias, cleanFieldName),
+					EncryptValue: func(value any) (any, error) {
+						return encrypter.EncryptFieldValue(field, value)
+					},
+				}
+
+				if r.withMultiMatch {
+					r.multiMatch.valueIdentifier = fmt.Sprintf("[[%s.%s]]", r.multiMatchActiveTableAlias, cleanFieldName)
+					result.MultiMatchSubQuery = r.multiMatch
+				}
+
+				return result, nil
+			}
+
 			// arrayble fields ":length" modifier
 			// -------------------------------------------------------
 			if modifier == lengthModifier && list.ExistInSlice(field.Type, schema.ArraybleFieldTypes()) {
@@ -456,11 +527,28 @@ func (r *runner) processActiveProps() (*search.ResolverResult, error) {
 			return nil, fmt.Errorf("unknown field %q", prop)
 		}
 
+		if field.Type == schema.FieldTypeComputed {
+			return nil, fmt.Errorf("computed field %q can't be used for filtering or sorting", prop)
+		}
+
+		if err := r.checkFieldReadRule(field); err != nil {
+			return nil, err
+		}
+
 		// check if it is a json field
 		if field.Type == schema.FieldTypeJson {
+			field.InitOptions()
+			if opt, ok := field.Options.(schema.Encryptable); ok && opt.IsEncrypted() {
+				return nil, fmt.Errorf("encrypted field %q can't be used for filtering", prop)
+			}
+
 			var jsonPath strings.Builder
 			jsonPath.WriteString("$")
 			for _, p := range r.activeProps[i+1:] {
+				if !jsonPathSegmentRegex.MatchString(p) {
+					return nil, fmt.Errorf("invalid json path segment %q for field %q", p, prop)
+				}
+
 				if _, err := strconv.Atoi(p); err == nil {
 					jsonPath.WriteString("[")
 					jsonPath.WriteString(inflector.Columnify(p))
@@ -585,6 +673,27 @@ func jsonArrayLength(tableColumnPair string) string {
 	)
 }
 
+// geoDistance returns an SQL expression that calculates the haversine
+// distance in meters between the geoPoint column and the origin point.
+//
+// The origin coordinates are inlined (they are always parsed floats)
+// so that the expression could be used also as a sort identifier.
+func geoDistance(tableColumnPair string, origin types.GeoPoint) string {
+	lat := strconv.FormatFloat(origin.Lat, 'f', -1, 64)
+	lon := strconv.FormatFloat(origin.Lon, 'f', -1, 64)
+	colLat := fmt.Sprintf("JSON_EXTRACT([[%s]], '$.lat')", tableColumnPair)
+	colLon := fmt.Sprintf("JSON_EXTRACT([[%s]], '$.lon')", tableColumnPair)
+
+	return fmt.Sprintf(
+		// note: min() is used to guard against floating point errors outside of the asin domain
+		`(%s * ASIN(MIN(1, SQRT(POW(SIN((RADIANS(%s) - RADIANS(%s)) / 2), 2) + COS(RADIANS(%s)) * COS(RADIANS(%s)) * POW(SIN((RADIANS(%s) - RADIANS(%s)) / 2), 2)))))`,
+		strconv.FormatFloat(2*types.EarthRadius, 'f', -1, 64),
+		colLat, lat,
+		lat, colLat,
+		colLon, lon,
+	)
+}
+
 func jsonEach(tableColumnPair string) string {
 	return fmt.Sprintf(
 		// note: the case is used to normalize value access for single and multiple relations.
@@ -608,3 +717,15 @@ func resolvableSystemFieldNames(collection *models.Collection) []string {
 
 	return result
 }
+
+// checkFieldReadRule returns an error if the field has a read rule
+// and the resolver is not allowed to access hidden fields
+// (otherwise the restricted field values could be guessed with
+// a carefully crafted filter or sort expression).
+func (r *runner) checkFieldReadRule(field *schema.SchemaField) error {
+	if field.ReadRule != "" && !r.allowHiddenFields {
+		return fmt.Errorf("field %q can't be used for filtering or sorting", field.Name)
+	}
+
+	return nil
+}
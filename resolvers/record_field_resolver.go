@@ -12,13 +12,15 @@ import (
 	"github.com/unkod/space/models/schema"
 	"github.com/unkod/space/tools/search"
 	"github.com/unkod/space/tools/security"
+	"github.com/unkod/space/tools/types"
 )
 
 // filter modifiers
 const (
-	eachModifier   string = "each"
-	issetModifier  string = "isset"
-	lengthModifier string = "length"
+	eachModifier     string = "each"
+	issetModifier    string = "isset"
+	lengthModifier   string = "length"
+	distanceModifier string = "distance"
 )
 
 // list of auth filter fields that don't require join with the auth
@@ -47,9 +49,26 @@ type CollectionsFinder interface {
 	FindCollectionByNameOrId(collectionNameOrId string) (*models.Collection, error)
 }
 
+// FieldValueEncrypter defines an optional CollectionsFinder interface
+// for encrypting the filter values compared with encrypted record fields.
+type FieldValueEncrypter interface {
+	EncryptFieldValue(field *schema.SchemaField, value any) (any, error)
+}
+
 // RecordFieldResolver defines a custom search resolver struct for
 // managing Record model search fields.
 //
+// Relation fields could be filtered by the related records fields using
+// dot notation (eg. "author.verified = true" or "author.team.name = 'a'").
+// Single relations are resolved with LEFT JOIN and multiple relations with
+// json_each LEFT JOIN, where the default operators must match all related
+// records and the "?"-prefixed ones (eg. "?=") at least one of them.
+//
+// Note that the API rules of the related collections are NOT applied
+// when resolving the relation fields, aka. any record that passes the base
+// collection rule could be filtered by the related records fields
+// (the hidden fields restriction still applies, see allowHiddenFields).
+//
 // Usually used together with `search.Provider`.
 // Example:
 //
@@ -148,6 +167,7 @@ func (r *RecordFieldResolver) UpdateQuery(query *dbx.SelectQuery) error {
 //	@request.data.someField
 //	@request.data.someSelect:each
 //	@request.data.someField:isset
+//	someGeoPoint:distance(37.7, -122.4)
 //	@collection.product.name
 func (r *RecordFieldResolver) Resolve(fieldName string) (*search.ResolverResult, error) {
 	return parseAndRun(fieldName, r)
@@ -294,3 +314,35 @@ func splitModifier(combined string) (string, string, error) {
 
 	return "", "", fmt.Errorf("unknown modifier in %q", combined)
 }
+
+// splitDistanceModifier splits the normalized "field:distance:lat:lon" prop
+// (see [search.ParseModifierArg]) into its field name and origin point.
+//
+// Returns an empty name if the prop doesn't have a distance modifier.
+func splitDistanceModifier(combined string) (string, types.GeoPoint, error) {
+	parts := strings.Split(combined, ":")
+
+	if len(parts) < 2 || parts[1] != distanceModifier {
+		return "", types.GeoPoint{}, nil
+	}
+
+	if len(parts) != 4 {
+		return "", types.GeoPoint{}, fmt.Errorf("the distance modifier in %q expects exactly 2 arguments (lat, lon)", combined)
+	}
+
+	lat, err := search.ParseModifierArg(parts[2])
+	if err != nil {
+		return "", types.GeoPoint{}, err
+	}
+
+	lon, err := search.ParseModifierArg(parts[3])
+	if err != nil {
+		return "", types.GeoPoint{}, err
+	}
+
+	if lat < -90 || lat > 90 || lon < -180 || lon > 180 {
+		return "", types.GeoPoint{}, fmt.Errorf("invalid distance coordinates in %q", combined)
+	}
+
+	return parts[0], types.GeoPoint{Lon: lon, Lat: lat}, nil
+}
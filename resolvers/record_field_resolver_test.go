@@ -2,15 +2,19 @@ package resolvers_test
 
 import (
 	"encoding/json"
+	"math"
 	"regexp"
 	"strings"
 	"testing"
 
+	"github.com/pocketbase/dbx"
 	"github.com/unkod/space/models"
+	"github.com/unkod/space/models/schema"
 	"github.com/unkod/space/resolvers"
 	"github.com/unkod/space/tests"
 	"github.com/unkod/space/tools/list"
 	"github.com/unkod/space/tools/search"
+	"github.com/unkod/space/tools/types"
 )
 
 func TestRecordFieldResolverUpdateQuery(t *testing.T) {
@@ -381,6 +385,10 @@ func TestRecordFieldResolverResolveSchemaFields(t *testing.T) {
 		// json_extract
 		{"json_array.0", false, "JSON_EXTRACT([[demo4.json_array]], '$[0]')"},
 		{"json_object.a.b.c", false, "JSON_EXTRACT([[demo4.json_object]], '$.a.b.c')"},
+		{"json_object.a.0.b_1", false, "JSON_EXTRACT([[demo4.json_object]], '$.a[0].b_1')"},
+		{"json_object.", true, ""},
+		{"json_object.a..b", true, ""},
+		{"json_object.a.b-c", true, ""},
 		// @request.auth relation join:
 		{"@request.auth.rel", false, "[[__auth_users.rel]]"},
 		{"@request.auth.rel.title", false, "[[__auth_users_rel.title]]"},
@@ -535,3 +543,247 @@ func TestRecordFieldResolverResolveStaticRequestInfoFields(t *testing.T) {
 		}
 	}
 }
+
+func TestRecordFieldResolverResolveGeoDistance(t *testing.T) {
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	collection := &models.Collection{}
+	collection.Name = "geo_test"
+	collection.Schema = schema.NewSchema(
+		&schema.SchemaField{Name: "title", Type: schema.FieldTypeText},
+		&schema.SchemaField{Name: "location", Type: schema.FieldTypeGeoPoint},
+	)
+	if err := app.Dao().SaveCollection(collection); err != nil {
+		t.Fatal(err)
+	}
+
+	points := map[string]types.GeoPoint{
+		"san_francisco": {Lon: -122.4194, Lat: 37.7749},
+		"oakland":       {Lon: -122.2712, Lat: 37.8044},
+		"san_jose":      {Lon: -121.8863, Lat: 37.3382},
+		"los_angeles":   {Lon: -118.2437, Lat: 34.0522},
+	}
+	for title, point := range points {
+		record := models.NewRecord(collection)
+		record.Set("title", title)
+		record.Set("location", point)
+		if err := app.Dao().SaveRecord(record); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	t.Run("resolve", func(t *testing.T) {
+		scenarios := []struct {
+			fieldName   string
+			expectError bool
+		}{
+			{"location:distance", true},
+			{"location:distance:37_7", true},
+			{"location:distance:abc:n122_4", true},
+			{"location:distance:90_1:0", true},
+			{"location:distance:0:180_1", true},
+			{"title:distance:37_7:n122_4", true},
+			{"missing:distance:37_7:n122_4", true},
+			{"location:distance:37_7:n122_4", false},
+		}
+
+		r := resolvers.NewRecordFieldResolver(app.Dao(), collection, nil, true)
+
+		for _, s := range scenarios {
+			result, err := r.Resolve(s.fieldName)
+
+			hasErr := err != nil
+			if hasErr != s.expectError {
+				t.Errorf("(%q) Expected hasErr %v, got %v (%v)", s.fieldName, s.expectError, hasErr, err)
+				continue
+			}
+
+			if hasErr {
+				continue
+			}
+
+			// the origin coordinates should be inlined to allow sorting
+			if len(result.Params) != 0 {
+				t.Errorf("(%q) Expected 0 r.Params, got %v", s.fieldName, result.Params)
+			}
+		}
+	})
+
+	t.Run("filter and sort", func(t *testing.T) {
+		origin := points["san_francisco"]
+
+		records := []*models.Record{}
+		resolver := resolvers.NewRecordFieldResolver(app.Dao(), collection, nil, true)
+		_, err := search.NewProvider(resolver).
+			Query(app.Dao().RecordQuery(collection)).
+			ParseAndExec("filter=location:distance(37.7749, -122.4194) < 100000&sort=-location:distance(37.7749, -122.4194)", &records)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		expected := []string{"san_jose", "oakland", "san_francisco"}
+		if len(records) != len(expected) {
+			t.Fatalf("Expected %d records, got %d", len(expected), len(records))
+		}
+		for i, title := range expected {
+			if v := records[i].GetString("title"); v != title {
+				t.Fatalf("Expected record %d to be %q, got %q", i, title, v)
+			}
+		}
+
+		// compare the sql calculated distance with the go implementation
+		distanceExpr, err := resolver.Resolve("location:distance:37_7749:n122_4194")
+		if err != nil {
+			t.Fatal(err)
+		}
+		for title, point := range points {
+			var distance float64
+			err := app.Dao().RecordQuery(collection).
+				Select(distanceExpr.Identifier).
+				AndWhere(dbx.HashExp{"title": title}).
+				Row(&distance)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if expected := origin.DistanceTo(point); math.Abs(distance-expected) > 0.001 {
+				t.Errorf("(%s) Expected distance %f, got %f", title, expected, distance)
+			}
+		}
+	})
+}
+
+func TestRecordFieldResolverResolveEncryptedFields(t *testing.T) {
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	app.Dao().EncryptionKeys = []string{"abcdabcdabcdabcdabcdabcdabcdabcd"}
+
+	collection := &models.Collection{
+		Name: "encrypted_test",
+		Type: models.CollectionTypeBase,
+		Schema: schema.NewSchema(
+			&schema.SchemaField{Name: "secret", Type: schema.FieldTypeText, Options: &schema.TextOptions{Encrypted: true}},
+			&schema.SchemaField{Name: "data", Type: schema.FieldTypeJson, Options: &schema.JsonOptions{Encrypted: true}},
+			&schema.SchemaField{Name: "plain", Type: schema.FieldTypeText},
+		),
+	}
+	if err := app.Dao().SaveCollection(collection); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, secret := range []string{"secret1", "secret2", ""} {
+		record := models.NewRecord(collection)
+		record.Set("secret", secret)
+		record.Set("data", map[string]any{"a": secret})
+		record.Set("plain", secret)
+		if err := app.Dao().SaveRecord(record); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	scenarios := []struct {
+		filter         string
+		expectError    bool
+		expectedSecret []string
+	}{
+		{"secret = 'secret1'", false, []string{"secret1"}},
+		{"secret != 'secret1'", false, []string{"secret2", ""}},
+		{"secret = ''", false, []string{""}},
+		{"secret = plain", true, nil},
+		{"secret ~ 'secret'", true, nil},
+		{"secret:length > 0", true, nil},
+		{"data = ''", true, nil},
+		{"data.a = 'secret1'", true, nil},
+		{"plain ~ 'secret'", false, []string{"secret1", "secret2"}},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.filter, func(t *testing.T) {
+			r := resolvers.NewRecordFieldResolver(app.Dao(), collection, nil, true)
+
+			expr, err := search.FilterData(s.filter).BuildExpr(r)
+
+			hasErr := err != nil
+			if hasErr != s.expectError {
+				t.Fatalf("Expected hasErr %v, got %v (%v)", s.expectError, hasErr, err)
+			}
+
+			if hasErr {
+				return
+			}
+
+			records := []*models.Record{}
+			query := app.Dao().RecordQuery(collection).AndWhere(expr).OrderBy("rowid ASC")
+			if err := r.UpdateQuery(query); err != nil {
+				t.Fatal(err)
+			}
+			if err := query.All(&records); err != nil {
+				t.Fatal(err)
+			}
+
+			secrets := make([]string, len(records))
+			for i, record := range records {
+				secrets[i] = record.GetString("secret")
+			}
+
+			if strings.Join(secrets, ",") != strings.Join(s.expectedSecret, ",") {
+				t.Fatalf("Expected secrets %v, got %v", s.expectedSecret, secrets)
+			}
+		})
+	}
+}
+
+func TestRecordFieldResolverResolveComputedFields(t *testing.T) {
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	products := &models.Collection{
+		Name: "computed_products",
+		Type: models.CollectionTypeBase,
+		Schema: schema.NewSchema(
+			&schema.SchemaField{Name: "price", Type: schema.FieldTypeNumber},
+			&schema.SchemaField{Name: "total", Type: schema.FieldTypeComputed, Options: &schema.ComputedOptions{Expression: "price * 2"}},
+		),
+	}
+	if err := app.Dao().SaveCollection(products); err != nil {
+		t.Fatal(err)
+	}
+
+	orders := &models.Collection{
+		Name: "computed_orders",
+		Type: models.CollectionTypeBase,
+		Schema: schema.NewSchema(
+			&schema.SchemaField{Name: "product", Type: schema.FieldTypeRelation, Options: &schema.RelationOptions{CollectionId: products.Id, MaxSelect: types.Pointer(1)}},
+		),
+	}
+	if err := app.Dao().SaveCollection(orders); err != nil {
+		t.Fatal(err)
+	}
+
+	scenarios := []struct {
+		collection  *models.Collection
+		fieldName   string
+		expectError bool
+	}{
+		{products, "price", false},
+		{products, "total", true},
+		{products, "total:length", true},
+		{orders, "product.price", false},
+		{orders, "product.total", true},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.collection.Name+"_"+s.fieldName, func(t *testing.T) {
+			r := resolvers.NewRecordFieldResolver(app.Dao(), s.collection, nil, true)
+
+			_, err := r.Resolve(s.fieldName)
+
+			hasErr := err != nil
+			if hasErr != s.expectError {
+				t.Fatalf("Expected hasErr %v, got %v (%v)", s.expectError, hasErr, err)
+			}
+		})
+	}
+}
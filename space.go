@@ -6,12 +6,15 @@ import (
 	"path/filepath"
 	"strings"
 	"syscall"
+	"time"
 
 	"github.com/fatih/color"
 	"github.com/spf13/cobra"
+	"github.com/unkod/space/apis"
 	"github.com/unkod/space/cmd"
 	"github.com/unkod/space/core"
 	"github.com/unkod/space/tools/list"
+	"github.com/unkod/space/tools/mailer"
 )
 
 var _ core.App = (*Space)(nil)
@@ -34,6 +37,8 @@ type Space struct {
 	debugFlag         bool
 	dataDirFlag       string
 	encryptionEnvFlag string
+	logFormatFlag     string
+	logLevelFlag      string
 	hideStartBanner   bool
 
 	// RootCmd is the main console command
@@ -46,15 +51,25 @@ type Config struct {
 	DefaultDebug         bool
 	DefaultDataDir       string // if not set, it will fallback to "./pb_data"
 	DefaultEncryptionEnv string
+	DefaultLogFormat     string // "text" (default) or "json"
+	DefaultLogLevel      string // if not set, it will fallback to "info" ("debug" in debug mode)
 
 	// hide the default console server info on app startup
 	HideStartBanner bool
 
 	// optional DB configurations
-	DataMaxOpenConns int // default to core.DefaultDataMaxOpenConns
-	DataMaxIdleConns int // default to core.DefaultDataMaxIdleConns
-	LogsMaxOpenConns int // default to core.DefaultLogsMaxOpenConns
-	LogsMaxIdleConns int // default to core.DefaultLogsMaxIdleConns
+	// (see core.BaseAppConfig for more details how they interact with the WAL journal mode)
+	DataMaxOpenConns    int           // default to core.DefaultDataMaxOpenConns
+	DataMaxIdleConns    int           // default to core.DefaultDataMaxIdleConns
+	DataConnMaxLifetime time.Duration // default to 0 (no limit)
+	DataReadReplica     string        // optional read-only replica of the main data.db
+	LogsMaxOpenConns    int           // default to core.DefaultLogsMaxOpenConns
+	LogsMaxIdleConns    int           // default to core.DefaultLogsMaxIdleConns
+	LogsConnMaxLifetime time.Duration // default to 0 (no limit)
+
+	// optional custom mail client that replaces the default SMTP/sendmail clients
+	// (eg. mailer.NoopMailer or mailer.CaptureMailer during local development)
+	Mailer mailer.Mailer
 }
 
 // New creates a new Space instance with the default configuration.
@@ -103,6 +118,8 @@ func NewWithConfig(config Config) *Space {
 		debugFlag:         config.DefaultDebug,
 		dataDirFlag:       config.DefaultDataDir,
 		encryptionEnvFlag: config.DefaultEncryptionEnv,
+		logFormatFlag:     config.DefaultLogFormat,
+		logLevelFlag:      config.DefaultLogLevel,
 		hideStartBanner:   config.HideStartBanner,
 	}
 
@@ -112,13 +129,19 @@ func NewWithConfig(config Config) *Space {
 
 	// initialize the app instance
 	pb.appWrapper = &appWrapper{core.NewBaseApp(core.BaseAppConfig{
-		DataDir:          pb.dataDirFlag,
-		EncryptionEnv:    pb.encryptionEnvFlag,
-		IsDebug:          pb.debugFlag,
-		DataMaxOpenConns: config.DataMaxOpenConns,
-		DataMaxIdleConns: config.DataMaxIdleConns,
-		LogsMaxOpenConns: config.LogsMaxOpenConns,
-		LogsMaxIdleConns: config.LogsMaxIdleConns,
+		DataDir:             pb.dataDirFlag,
+		EncryptionEnv:       pb.encryptionEnvFlag,
+		IsDebug:             pb.debugFlag,
+		LogFormat:           pb.logFormatFlag,
+		LogLevel:            pb.logLevelFlag,
+		DataMaxOpenConns:    config.DataMaxOpenConns,
+		DataMaxIdleConns:    config.DataMaxIdleConns,
+		DataConnMaxLifetime: config.DataConnMaxLifetime,
+		DataReadReplica:     config.DataReadReplica,
+		LogsMaxOpenConns:    config.LogsMaxOpenConns,
+		LogsMaxIdleConns:    config.LogsMaxIdleConns,
+		LogsConnMaxLifetime: config.LogsConnMaxLifetime,
+		Mailer:              config.Mailer,
 	})}
 
 	// hide the default help command (allow only `--help` flag)
@@ -137,6 +160,61 @@ func (pb *Space) Start() error {
 	return pb.Execute()
 }
 
+// RegisterCommand registers a custom console command to the pb.RootCmd.
+//
+// The command (and its subcommands) runs in the fully initialized app
+// context, aka. after the app bootstrap and after applying the pending
+// db migrations, and shares the same global flags (--dir, --debug, etc.).
+//
+// Note that due to the cobra hooks inheritance, the app initialization
+// is registered as the command PersistentPreRunE hook (wrapping the existing one)
+// and it will be skipped if a subcommand defines its own persistent pre-run hook.
+//
+// Example:
+//
+//	app.RegisterCommand(&cobra.Command{
+//		Use: "seed",
+//		RunE: func(cmd *cobra.Command, args []string) error {
+//			record := models.NewRecord(collection)
+//			// ...
+//			return app.Dao().SaveRecord(record)
+//		},
+//	})
+func (pb *Space) RegisterCommand(command *cobra.Command) {
+	preRunE := command.PersistentPreRunE
+	preRun := command.PersistentPreRun
+
+	command.PersistentPreRun = nil
+	command.PersistentPreRunE = func(c *cobra.Command, args []string) error {
+		if err := pb.initCommandApp(); err != nil {
+			return err
+		}
+
+		if preRunE != nil {
+			return preRunE(c, args)
+		}
+
+		if preRun != nil {
+			preRun(c, args)
+		}
+
+		return nil
+	}
+
+	pb.RootCmd.AddCommand(command)
+}
+
+// initCommandApp bootstraps the app (if not already) and applies the pending db migrations.
+func (pb *Space) initCommandApp() error {
+	if !pb.IsBootstrapped() {
+		if err := pb.Bootstrap(); err != nil {
+			return err
+		}
+	}
+
+	return apis.RunMigrations(pb)
+}
+
 // Execute initializes the application (if not already) and executes
 // the pb.RootCmd with graceful shutdown support.
 //
@@ -203,6 +281,20 @@ func (pb *Space) eagerParseFlags(config *Config) error {
 		"enable debug mode, aka. showing more detailed logs",
 	)
 
+	pb.RootCmd.PersistentFlags().StringVar(
+		&pb.logFormatFlag,
+		"logFormat",
+		config.DefaultLogFormat,
+		"the app logs output format - text or json (default text)",
+	)
+
+	pb.RootCmd.PersistentFlags().StringVar(
+		&pb.logLevelFlag,
+		"logLevel",
+		config.DefaultLogLevel,
+		"the min level of the logged messages - debug, info, warn or error \n(default info or debug in debug mode)",
+	)
+
 	return pb.RootCmd.ParseFlags(os.Args[1:])
 }
 
@@ -1,11 +1,14 @@
 package space
 
 import (
+	"context"
+	"log/slog"
 	"os"
 	"path/filepath"
 	"testing"
 
 	"github.com/spf13/cobra"
+	"github.com/unkod/space/models"
 )
 
 func TestNew(t *testing.T) {
@@ -58,6 +61,7 @@ func TestNewWithConfig(t *testing.T) {
 		DefaultDebug:         true,
 		DefaultDataDir:       "test_dir",
 		DefaultEncryptionEnv: "test_encryption_env",
+		DefaultLogLevel:      "warn",
 		HideStartBanner:      true,
 	})
 
@@ -88,6 +92,14 @@ func TestNewWithConfig(t *testing.T) {
 	if app.IsDebug() != true {
 		t.Fatal("Expected app.IsDebug() true, got false")
 	}
+
+	if app.Logger().Enabled(context.Background(), slog.LevelInfo) {
+		t.Fatal("Expected app.Logger() info level to be disabled")
+	}
+
+	if !app.Logger().Enabled(context.Background(), slog.LevelWarn) {
+		t.Fatal("Expected app.Logger() warn level to be enabled")
+	}
 }
 
 func TestNewWithConfigAndFlags(t *testing.T) {
@@ -106,12 +118,14 @@ func TestNewWithConfigAndFlags(t *testing.T) {
 		"--dir=test_dir_flag",
 		"--encryptionEnv=test_encryption_env_flag",
 		"--debug=false",
+		"--logLevel=error",
 	)
 
 	app := NewWithConfig(Config{
 		DefaultDebug:         true,
 		DefaultDataDir:       "test_dir",
 		DefaultEncryptionEnv: "test_encryption_env",
+		DefaultLogLevel:      "warn",
 		HideStartBanner:      true,
 	})
 
@@ -142,6 +156,73 @@ func TestNewWithConfigAndFlags(t *testing.T) {
 	if app.IsDebug() != false {
 		t.Fatal("Expected app.IsDebug() false, got true")
 	}
+
+	if app.Logger().Enabled(context.Background(), slog.LevelWarn) {
+		t.Fatal("Expected app.Logger() warn level to be disabled")
+	}
+
+	if !app.Logger().Enabled(context.Background(), slog.LevelError) {
+		t.Fatal("Expected app.Logger() error level to be enabled")
+	}
+}
+
+func TestRegisterCommand(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "pb_register_command")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	app := NewWithConfig(Config{DefaultDataDir: tempDir})
+	defer app.ResetBootstrapState()
+
+	var preRunCalled bool
+	var seeded int
+
+	// example custom command that seeds admin accounts
+	app.RegisterCommand(&cobra.Command{
+		Use: "seed",
+		PersistentPreRun: func(cmd *cobra.Command, args []string) {
+			preRunCalled = true
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			for _, email := range args {
+				admin := &models.Admin{Email: email}
+				admin.SetPassword("1234567890")
+				if err := app.Dao().SaveAdmin(admin); err != nil {
+					return err
+				}
+				seeded++
+			}
+
+			return nil
+		},
+	})
+
+	app.RootCmd.SetArgs([]string{"seed", "--dir=" + tempDir, "test1@example.com", "test2@example.com"})
+	if err := app.RootCmd.Execute(); err != nil {
+		t.Fatal(err)
+	}
+
+	if !app.IsBootstrapped() {
+		t.Fatal("Expected the app to be bootstrapped")
+	}
+
+	if !preRunCalled {
+		t.Fatal("Expected the original command PersistentPreRun to be called")
+	}
+
+	if seeded != 2 {
+		t.Fatalf("Expected 2 seeded admins, got %d", seeded)
+	}
+
+	total, err := app.Dao().TotalAdmins()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if total != 2 {
+		t.Fatalf("Expected 2 admins in the db, got %d", total)
+	}
 }
 
 func TestSkipBootstrap(t *testing.T) {
@@ -10,6 +10,7 @@ import (
 	"sync"
 
 	"github.com/unkod/space/core"
+	"github.com/unkod/space/models"
 	"github.com/unkod/space/tools/mailer"
 )
 
@@ -54,6 +55,12 @@ func (t *TestApp) NewMailClient() mailer.Mailer {
 	return t.TestMailer
 }
 
+// NewCollectionMailClient initializes test app mail client
+// (the collection mailer options are ignored).
+func (t *TestApp) NewCollectionMailClient(collection *models.Collection) mailer.Mailer {
+	return t.NewMailClient()
+}
+
 // ResetEventCalls resets the EventCalls counter.
 func (t *TestApp) ResetEventCalls() {
 	t.mux.Lock()
@@ -163,6 +170,10 @@ func NewTestApp(optTestDataDir ...string) (*TestApp, error) {
 		return t.registerEventCall("OnRecordViewRequest")
 	})
 
+	t.OnRecordBeforeSendRequest().Add(func(e *core.RecordBeforeSendEvent) error {
+		return t.registerEventCall("OnRecordBeforeSendRequest")
+	})
+
 	t.OnRecordBeforeCreateRequest().Add(func(e *core.RecordCreateEvent) error {
 		return t.registerEventCall("OnRecordBeforeCreateRequest")
 	})
@@ -171,6 +182,14 @@ func NewTestApp(optTestDataDir ...string) (*TestApp, error) {
 		return t.registerEventCall("OnRecordAfterCreateRequest")
 	})
 
+	t.OnRecordBeforeBulkCreateRequest().Add(func(e *core.RecordsBulkCreateEvent) error {
+		return t.registerEventCall("OnRecordBeforeBulkCreateRequest")
+	})
+
+	t.OnRecordAfterBulkCreateRequest().Add(func(e *core.RecordsBulkCreateEvent) error {
+		return t.registerEventCall("OnRecordAfterBulkCreateRequest")
+	})
+
 	t.OnRecordBeforeUpdateRequest().Add(func(e *core.RecordUpdateEvent) error {
 		return t.registerEventCall("OnRecordBeforeUpdateRequest")
 	})
@@ -187,6 +206,22 @@ func NewTestApp(optTestDataDir ...string) (*TestApp, error) {
 		return t.registerEventCall("OnRecordAfterDeleteRequest")
 	})
 
+	t.OnRecordBeforeSoftDeleteRequest().Add(func(e *core.RecordSoftDeleteEvent) error {
+		return t.registerEventCall("OnRecordBeforeSoftDeleteRequest")
+	})
+
+	t.OnRecordAfterSoftDeleteRequest().Add(func(e *core.RecordSoftDeleteEvent) error {
+		return t.registerEventCall("OnRecordAfterSoftDeleteRequest")
+	})
+
+	t.OnRecordBeforeRestoreRequest().Add(func(e *core.RecordRestoreEvent) error {
+		return t.registerEventCall("OnRecordBeforeRestoreRequest")
+	})
+
+	t.OnRecordAfterRestoreRequest().Add(func(e *core.RecordRestoreEvent) error {
+		return t.registerEventCall("OnRecordAfterRestoreRequest")
+	})
+
 	t.OnRecordAuthRequest().Add(func(e *core.RecordAuthEvent) error {
 		return t.registerEventCall("OnRecordAuthRequest")
 	})
@@ -267,6 +302,14 @@ func NewTestApp(optTestDataDir ...string) (*TestApp, error) {
 		return t.registerEventCall("OnRecordListExternalAuthsRequest")
 	})
 
+	t.OnRecordBeforeLinkExternalAuthRequest().Add(func(e *core.RecordLinkExternalAuthEvent) error {
+		return t.registerEventCall("OnRecordBeforeLinkExternalAuthRequest")
+	})
+
+	t.OnRecordAfterLinkExternalAuthRequest().Add(func(e *core.RecordLinkExternalAuthEvent) error {
+		return t.registerEventCall("OnRecordAfterLinkExternalAuthRequest")
+	})
+
 	t.OnRecordBeforeUnlinkExternalAuthRequest().Add(func(e *core.RecordUnlinkExternalAuthEvent) error {
 		return t.registerEventCall("OnRecordBeforeUnlinkExternalAuthRequest")
 	})
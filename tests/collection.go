@@ -0,0 +1,43 @@
+package tests
+
+import (
+	"testing"
+
+	"github.com/unkod/space/models"
+)
+
+// UpdateCollection finds the collection with the specified name or id,
+// applies the update func to it and saves it.
+//
+// The optional afterSave funcs are called with the saved collection
+// (eg. to seed records for its new fields or options) and at the end
+// the app event calls are reset so that the setup doesn't affect
+// the scenario expected events.
+func UpdateCollection(
+	t testing.TB,
+	app *TestApp,
+	nameOrId string,
+	update func(collection *models.Collection),
+	afterSave ...func(collection *models.Collection) error,
+) *models.Collection {
+	collection, err := app.Dao().FindCollectionByNameOrId(nameOrId)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	update(collection)
+
+	if err := app.Dao().SaveCollection(collection); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, fn := range afterSave {
+		if err := fn(collection); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	app.ResetEventCalls()
+
+	return collection
+}
@@ -0,0 +1,75 @@
+package tests
+
+import (
+	"testing"
+	"time"
+
+	"github.com/unkod/space/forms"
+	"github.com/unkod/space/models"
+	"github.com/unkod/space/tools/security"
+)
+
+// EnableAdminMFA enrolls and confirms the MFA of the provided admin
+// and returns the plain TOTP secret and backup codes.
+//
+// The enrollment is confirmed with the previous time step code so that
+// the current and the next step codes (see [MFACode]) could be used
+// for the subsequent MFA checks.
+//
+// The triggered event calls are reset after the confirmation.
+func EnableAdminMFA(t testing.TB, app *TestApp, admin *models.Admin) (string, []string) {
+	enrollment, err := forms.NewAdminMFAEnroll(app, admin).Submit()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	confirm := forms.NewAdminMFAConfirm(app, admin)
+	confirm.Code = MFACode(t, enrollment.Secret, -1)
+
+	backupCodes, err := confirm.Submit()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	app.ResetEventCalls()
+
+	return enrollment.Secret, backupCodes
+}
+
+// EnableRecordMFA enrolls and confirms the MFA of the provided auth record
+// and returns the plain TOTP secret and backup codes.
+//
+// Similar to [EnableAdminMFA], the enrollment is confirmed with the
+// previous time step code and the triggered event calls are reset.
+func EnableRecordMFA(t testing.TB, app *TestApp, record *models.Record) (string, []string) {
+	enrollment, err := forms.NewRecordMFAEnroll(app, record).Submit()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	confirm := forms.NewRecordMFAConfirm(app, record)
+	confirm.Code = MFACode(t, enrollment.Secret, -1)
+
+	backupCodes, err := confirm.Submit()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	app.ResetEventCalls()
+
+	return enrollment.Secret, backupCodes
+}
+
+// MFACode returns the TOTP code of the plain secret for the time step
+// at the specified offset from the current one (eg. -1 for the previous step).
+//
+// Note that a TOTP code is accepted only once, so each subsequent
+// MFA check must use a code with a greater offset.
+func MFACode(t testing.TB, secret string, offset int) string {
+	code, err := security.TOTPCode(secret, time.Now().Add(time.Duration(offset)*security.TOTPPeriod))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return code
+}
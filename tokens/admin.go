@@ -33,3 +33,13 @@ func NewAdminFileToken(app core.App, admin *models.Admin) (string, error) {
 		app.Settings().AdminFileToken.Duration,
 	)
 }
+
+// NewAdminMFAToken generates and returns a new admin MFA challenge token
+// (aka. the "mfaId" of the second auth step).
+func NewAdminMFAToken(app core.App, admin *models.Admin) (string, error) {
+	return security.NewJWT(
+		jwt.MapClaims{"id": admin.Id, "type": TypeAdmin},
+		(admin.TokenKey + app.Settings().AdminMFAToken.Secret),
+		app.Settings().AdminMFAToken.Duration,
+	)
+}
@@ -75,3 +75,35 @@ func TestNewAdminFileToken(t *testing.T) {
 		t.Fatalf("Expected admin %v, got %v", admin, tokenAdmin)
 	}
 }
+
+func TestNewAdminMFAToken(t *testing.T) {
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	admin, err := app.Dao().FindAdminByEmail("test@example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	token, err := tokens.NewAdminMFAToken(app, admin)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tokenAdmin, _ := app.Dao().FindAdminByToken(
+		token,
+		app.Settings().AdminMFAToken.Secret,
+	)
+	if tokenAdmin == nil || tokenAdmin.Id != admin.Id {
+		t.Fatalf("Expected admin %v, got %v", admin, tokenAdmin)
+	}
+
+	// shouldn't be usable as auth token
+	authAdmin, _ := app.Dao().FindAdminByToken(
+		token,
+		app.Settings().AdminAuthToken.Secret,
+	)
+	if authAdmin != nil {
+		t.Fatalf("Expected the MFA token to not be valid auth token, got %v", authAdmin)
+	}
+}
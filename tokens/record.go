@@ -6,6 +6,7 @@ import (
 	"github.com/golang-jwt/jwt/v4"
 	"github.com/unkod/space/core"
 	"github.com/unkod/space/models"
+	"github.com/unkod/space/tools/list"
 	"github.com/unkod/space/tools/security"
 )
 
@@ -15,14 +16,63 @@ func NewRecordAuthToken(app core.App, record *models.Record) (string, error) {
 		return "", errors.New("The record is not from an auth collection.")
 	}
 
+	claims := recordCustomClaims(record)
+	claims["id"] = record.Id
+	claims["type"] = TypeAuthRecord
+	claims["collectionId"] = record.Collection().Id
+
 	return security.NewJWT(
-		jwt.MapClaims{
-			"id":           record.Id,
-			"type":         TypeAuthRecord,
-			"collectionId": record.Collection().Id,
-		},
+		claims,
+		(record.TokenKey() + app.Settings().RecordAuthToken.Secret),
+		tokenDuration(record.Collection().AuthOptions().AuthTokenDuration, app.Settings().RecordAuthToken.Duration),
+	)
+}
+
+// recordCustomClaims returns the auth record collection custom token claims
+// with their values loaded from the record fields.
+//
+// The reserved claims are always skipped.
+func recordCustomClaims(record *models.Record) jwt.MapClaims {
+	claims := jwt.MapClaims{}
+
+	reserved := models.ReservedTokenClaims()
+
+	for name, field := range record.Collection().AuthOptions().TokenClaims {
+		if list.ExistInSlice(name, reserved) {
+			continue
+		}
+
+		claims[name] = record.Get(field)
+	}
+
+	return claims
+}
+
+// NewRecordImpersonateToken generates and returns a new auth record
+// authentication token flagged as impersonated by the provided admin.
+//
+// The token duration is the impersonation settings one (if set),
+// otherwise the same as the regular auth record token.
+func NewRecordImpersonateToken(app core.App, record *models.Record, admin *models.Admin) (string, error) {
+	if !record.Collection().IsAuth() {
+		return "", errors.New("The record is not from an auth collection.")
+	}
+
+	duration := app.Settings().Impersonation.Duration
+	if duration <= 0 {
+		duration = tokenDuration(record.Collection().AuthOptions().AuthTokenDuration, app.Settings().RecordAuthToken.Duration)
+	}
+
+	claims := recordCustomClaims(record)
+	claims["id"] = record.Id
+	claims["type"] = TypeAuthRecord
+	claims["collectionId"] = record.Collection().Id
+	claims[ImpersonatorClaim] = admin.Id
+
+	return security.NewJWT(
+		claims,
 		(record.TokenKey() + app.Settings().RecordAuthToken.Secret),
-		app.Settings().RecordAuthToken.Duration,
+		duration,
 	)
 }
 
@@ -40,7 +90,7 @@ func NewRecordVerifyToken(app core.App, record *models.Record) (string, error) {
 			"email":        record.Email(),
 		},
 		(record.TokenKey() + app.Settings().RecordVerificationToken.Secret),
-		app.Settings().RecordVerificationToken.Duration,
+		tokenDuration(record.Collection().AuthOptions().VerificationTokenDuration, app.Settings().RecordVerificationToken.Duration),
 	)
 }
 
@@ -58,7 +108,7 @@ func NewRecordResetPasswordToken(app core.App, record *models.Record) (string, e
 			"email":        record.Email(),
 		},
 		(record.TokenKey() + app.Settings().RecordPasswordResetToken.Secret),
-		app.Settings().RecordPasswordResetToken.Duration,
+		tokenDuration(record.Collection().AuthOptions().PasswordResetTokenDuration, app.Settings().RecordPasswordResetToken.Duration),
 	)
 }
 
@@ -73,7 +123,7 @@ func NewRecordChangeEmailToken(app core.App, record *models.Record, newEmail str
 			"newEmail":     newEmail,
 		},
 		(record.TokenKey() + app.Settings().RecordEmailChangeToken.Secret),
-		app.Settings().RecordEmailChangeToken.Duration,
+		tokenDuration(record.Collection().AuthOptions().EmailChangeTokenDuration, app.Settings().RecordEmailChangeToken.Duration),
 	)
 }
 
@@ -93,3 +143,31 @@ func NewRecordFileToken(app core.App, record *models.Record) (string, error) {
 		app.Settings().RecordFileToken.Duration,
 	)
 }
+
+// NewRecordMFAToken generates and returns a new auth record MFA challenge token
+// (aka. the "mfaId" of the second auth step).
+func NewRecordMFAToken(app core.App, record *models.Record) (string, error) {
+	if !record.Collection().IsAuth() {
+		return "", errors.New("The record is not from an auth collection.")
+	}
+
+	return security.NewJWT(
+		jwt.MapClaims{
+			"id":           record.Id,
+			"type":         TypeAuthRecord,
+			"collectionId": record.Collection().Id,
+		},
+		(record.TokenKey() + app.Settings().RecordMFAToken.Secret),
+		app.Settings().RecordMFAToken.Duration,
+	)
+}
+
+// tokenDuration returns the collection specific token duration
+// or the global default one if the former is not set.
+func tokenDuration(collectionDuration int64, defaultDuration int64) int64 {
+	if collectionDuration > 0 {
+		return collectionDuration
+	}
+
+	return defaultDuration
+}
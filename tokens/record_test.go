@@ -2,9 +2,12 @@ package tokens_test
 
 import (
 	"testing"
+	"time"
 
+	"github.com/spf13/cast"
 	"github.com/unkod/space/tests"
 	"github.com/unkod/space/tokens"
+	"github.com/unkod/space/tools/security"
 )
 
 func TestNewRecordAuthToken(t *testing.T) {
@@ -30,6 +33,135 @@ func TestNewRecordAuthToken(t *testing.T) {
 	}
 }
 
+func TestNewRecordAuthTokenWithCustomClaims(t *testing.T) {
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	user, err := app.Dao().FindAuthRecordByEmail("users", "test@example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	options := user.Collection().AuthOptions()
+	options.TokenClaims = map[string]string{
+		"userName":     "username",
+		"isVerified":   "verified",
+		"collectionId": "username", // reserved
+		"exp":          "username", // reserved
+	}
+	user.Collection().SetOptions(options)
+
+	token, err := tokens.NewRecordAuthToken(app, user)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	claims, _ := security.ParseUnverifiedJWT(token)
+
+	if v := cast.ToString(claims["userName"]); v != user.Username() {
+		t.Fatalf("Expected userName claim %q, got %q", user.Username(), v)
+	}
+
+	if v, ok := claims["isVerified"].(bool); !ok || v != user.Verified() {
+		t.Fatalf("Expected isVerified claim %v, got %v", user.Verified(), claims["isVerified"])
+	}
+
+	if v := cast.ToString(claims["collectionId"]); v != user.Collection().Id {
+		t.Fatalf("Expected the reserved collectionId claim to not be overwritten, got %q", v)
+	}
+
+	if _, ok := claims["exp"].(float64); !ok {
+		t.Fatalf("Expected the reserved exp claim to not be overwritten, got %v", claims["exp"])
+	}
+
+	tokenRecord, _ := app.Dao().FindAuthRecordByToken(
+		token,
+		app.Settings().RecordAuthToken.Secret,
+	)
+	if tokenRecord == nil || tokenRecord.Id != user.Id {
+		t.Fatalf("Expected auth record %v, got %v", user, tokenRecord)
+	}
+}
+
+func TestNewRecordAuthTokenWithCollectionDuration(t *testing.T) {
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	user, err := app.Dao().FindAuthRecordByEmail("users", "test@example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	options := user.Collection().AuthOptions()
+	options.AuthTokenDuration = 100
+	user.Collection().SetOptions(options)
+
+	token, err := tokens.NewRecordAuthToken(app, user)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	claims, _ := security.ParseUnverifiedJWT(token)
+	exp := cast.ToInt64(claims["exp"])
+	expected := time.Now().Unix() + 100
+	if exp < expected-5 || exp > expected+5 {
+		t.Fatalf("Expected token exp around %d, got %d", expected, exp)
+	}
+}
+
+func TestNewRecordImpersonateToken(t *testing.T) {
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	user, err := app.Dao().FindAuthRecordByEmail("users", "test@example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	admin, err := app.Dao().FindAdminByEmail("test@example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	scenarios := []struct {
+		name             string
+		duration         int64
+		expectedDuration int64
+	}{
+		{"fallback to the auth token duration", 0, app.Settings().RecordAuthToken.Duration},
+		{"impersonation duration", 100, 100},
+	}
+
+	for _, s := range scenarios {
+		app.Settings().Impersonation.Duration = s.duration
+
+		token, err := tokens.NewRecordImpersonateToken(app, user, admin)
+		if err != nil {
+			t.Fatalf("[%s] %v", s.name, err)
+		}
+
+		tokenRecord, _ := app.Dao().FindAuthRecordByToken(
+			token,
+			app.Settings().RecordAuthToken.Secret,
+		)
+		if tokenRecord == nil || tokenRecord.Id != user.Id {
+			t.Fatalf("[%s] Expected auth record %v, got %v", s.name, user, tokenRecord)
+		}
+
+		claims, _ := security.ParseUnverifiedJWT(token)
+
+		if v := cast.ToString(claims[tokens.ImpersonatorClaim]); v != admin.Id {
+			t.Fatalf("[%s] Expected impersonator claim %q, got %q", s.name, admin.Id, v)
+		}
+
+		exp := cast.ToInt64(claims["exp"])
+		expected := time.Now().Unix() + s.expectedDuration
+		if exp < expected-5 || exp > expected+5 {
+			t.Fatalf("[%s] Expected token exp around %d, got %d", s.name, expected, exp)
+		}
+	}
+}
+
 func TestNewRecordVerifyToken(t *testing.T) {
 	app, _ := tests.NewTestApp()
 	defer app.Cleanup()
@@ -121,3 +253,35 @@ func TestNewRecordFileToken(t *testing.T) {
 		t.Fatalf("Expected auth record %v, got %v", user, tokenRecord)
 	}
 }
+
+func TestNewRecordMFAToken(t *testing.T) {
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	user, err := app.Dao().FindAuthRecordByEmail("users", "test@example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	token, err := tokens.NewRecordMFAToken(app, user)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tokenRecord, _ := app.Dao().FindAuthRecordByToken(
+		token,
+		app.Settings().RecordMFAToken.Secret,
+	)
+	if tokenRecord == nil || tokenRecord.Id != user.Id {
+		t.Fatalf("Expected auth record %v, got %v", user, tokenRecord)
+	}
+
+	// shouldn't be usable as auth token
+	authRecord, _ := app.Dao().FindAuthRecordByToken(
+		token,
+		app.Settings().RecordAuthToken.Secret,
+	)
+	if authRecord != nil {
+		t.Fatalf("Expected the MFA token to not be valid auth token, got %v", authRecord)
+	}
+}
@@ -5,3 +5,7 @@ const (
 	TypeAdmin      = "admin"
 	TypeAuthRecord = "authRecord"
 )
+
+// ImpersonatorClaim is the impersonation token claim
+// with the id of the impersonating admin.
+const ImpersonatorClaim = "impersonator"
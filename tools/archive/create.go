@@ -7,6 +7,7 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+	"time"
 )
 
 // Create creates a new zip archive from src dir content and saves it in dest path.
@@ -37,6 +38,59 @@ func Create(src string, dest string, skipPaths ...string) error {
 	return nil
 }
 
+// CreatePartial creates a new zip archive in dest path only with the specified
+// src dir files (relative to src) and the optional extra in-memory entries.
+func CreatePartial(src string, dest string, files []string, extra map[string][]byte) error {
+	if err := os.MkdirAll(filepath.Dir(dest), os.ModePerm); err != nil {
+		return err
+	}
+
+	zf, err := os.Create(dest)
+	if err != nil {
+		return err
+	}
+	defer zf.Close()
+
+	zw := zip.NewWriter(zf)
+	defer zw.Close()
+
+	addErr := func() error {
+		fsys := os.DirFS(src)
+
+		for _, name := range files {
+			if err := zipAddFile(zw, fsys, name); err != nil {
+				return err
+			}
+		}
+
+		for name, content := range extra {
+			fw, err := zw.CreateHeader(&zip.FileHeader{
+				Name:     name,
+				Method:   zip.Deflate,
+				Modified: time.Now(),
+			})
+			if err != nil {
+				return err
+			}
+
+			if _, err := fw.Write(content); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}()
+
+	if addErr != nil {
+		// try to cleanup at least the created zip file
+		os.Remove(dest)
+
+		return addErr
+	}
+
+	return nil
+}
+
 // note remove after similar method is added in the std lib (https://github.com/golang/go/issues/54898)
 func zipAddFS(w *zip.Writer, fsys fs.FS, skipPaths ...string) error {
 	return fs.WalkDir(fsys, ".", func(name string, d fs.DirEntry, err error) error {
@@ -56,32 +110,37 @@ func zipAddFS(w *zip.Writer, fsys fs.FS, skipPaths ...string) error {
 			}
 		}
 
-		info, err := d.Info()
-		if err != nil {
-			return err
-		}
-
-		h, err := zip.FileInfoHeader(info)
-		if err != nil {
-			return err
-		}
+		return zipAddFile(w, fsys, name)
+	})
+}
 
-		h.Name = name
-		h.Method = zip.Deflate
+// zipAddFile adds a single fsys file to the zip archive.
+func zipAddFile(w *zip.Writer, fsys fs.FS, name string) error {
+	f, err := fsys.Open(name)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
 
-		fw, err := w.CreateHeader(h)
-		if err != nil {
-			return err
-		}
+	info, err := f.Stat()
+	if err != nil {
+		return err
+	}
 
-		f, err := fsys.Open(name)
-		if err != nil {
-			return err
-		}
-		defer f.Close()
+	h, err := zip.FileInfoHeader(info)
+	if err != nil {
+		return err
+	}
 
-		_, err = io.Copy(fw, f)
+	h.Name = name
+	h.Method = zip.Deflate
 
+	fw, err := w.CreateHeader(h)
+	if err != nil {
 		return err
-	})
+	}
+
+	_, err = io.Copy(fw, f)
+
+	return err
 }
@@ -1,6 +1,8 @@
 package archive_test
 
 import (
+	"archive/zip"
+	"io"
 	"os"
 	"path/filepath"
 	"testing"
@@ -54,6 +56,58 @@ func TestCreateSuccess(t *testing.T) {
 	}
 }
 
+func TestCreatePartial(t *testing.T) {
+	testDir := createTestDir(t)
+	defer os.RemoveAll(testDir)
+
+	zipPath := filepath.Join(os.TempDir(), "pb_partial_test.zip")
+	defer os.RemoveAll(zipPath)
+
+	// missing file
+	if err := archive.CreatePartial(testDir, zipPath, []string{"missing"}, nil); err == nil {
+		t.Fatal("Expected to fail due to missing file")
+	}
+	if _, err := os.Stat(zipPath); err == nil {
+		t.Fatalf("Expected the zip file not to be created")
+	}
+
+	files := []string{"test", "a/b/c/sub2"}
+	extra := map[string][]byte{"extra.json": []byte(`{"test":123}`)}
+
+	if err := archive.CreatePartial(testDir, zipPath, files, extra); err != nil {
+		t.Fatalf("Failed to create archive: %v", err)
+	}
+
+	zr, err := zip.OpenReader(zipPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer zr.Close()
+
+	expectedNames := []string{"test", "a/b/c/sub2", "extra.json"}
+
+	if len(zr.File) != len(expectedNames) {
+		t.Fatalf("Expected %d archive entries, got %d", len(expectedNames), len(zr.File))
+	}
+
+	for i, f := range zr.File {
+		if f.Name != expectedNames[i] {
+			t.Fatalf("(%d) Expected entry %q, got %q", i, expectedNames[i], f.Name)
+		}
+	}
+
+	r, err := zr.File[2].Open()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+
+	content, _ := io.ReadAll(r)
+	if string(content) != `{"test":123}` {
+		t.Fatalf("Unexpected extra entry content %q", content)
+	}
+}
+
 // -------------------------------------------------------------------
 
 // note: make sure to call os.RemoveAll(dir) after you are done
@@ -0,0 +1,190 @@
+package auth
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"strings"
+
+	"github.com/golang-jwt/jwt/v4"
+)
+
+// jwk defines a single JSON Web Key (RFC 7517) from a JWKS document.
+type jwk struct {
+	Kty string
+	Kid string
+	Use string
+	Alg string
+
+	// RSA key params
+	N string
+	E string
+
+	// EC key params
+	Crv string
+	X   string
+	Y   string
+}
+
+// publicKey constructs a crypto public key from the current key params
+// per RFC 7518 (https://tools.ietf.org/html/rfc7518#section-6).
+func (k *jwk) publicKey() (any, error) {
+	switch k.Kty {
+	case "RSA":
+		exponent, err := decodeJWKParam(k.E)
+		if err != nil {
+			return nil, err
+		}
+
+		modulus, err := decodeJWKParam(k.N)
+		if err != nil {
+			return nil, err
+		}
+
+		return &rsa.PublicKey{
+			// https://tools.ietf.org/html/rfc7517#appendix-A.1
+			E: int(big.NewInt(0).SetBytes(exponent).Uint64()),
+			N: big.NewInt(0).SetBytes(modulus),
+		}, nil
+	case "EC":
+		var curve elliptic.Curve
+		switch k.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, fmt.Errorf("unsupported jwk curve %q", k.Crv)
+		}
+
+		x, err := decodeJWKParam(k.X)
+		if err != nil {
+			return nil, err
+		}
+
+		y, err := decodeJWKParam(k.Y)
+		if err != nil {
+			return nil, err
+		}
+
+		return &ecdsa.PublicKey{
+			Curve: curve,
+			X:     big.NewInt(0).SetBytes(x),
+			Y:     big.NewInt(0).SetBytes(y),
+		}, nil
+	default:
+		return nil, fmt.Errorf("unsupported jwk key type %q", k.Kty)
+	}
+}
+
+func decodeJWKParam(param string) ([]byte, error) {
+	return base64.RawURLEncoding.DecodeString(strings.TrimRight(param, "="))
+}
+
+// fetchJWK loads the JWKS document from the provided url
+// and returns the key matching the specified kid.
+func fetchJWK(ctx context.Context, jwksUrl string, kid string) (*jwk, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", jwksUrl, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	rawBody, err := io.ReadAll(res.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	// http.Client.Get doesn't treat non 2xx responses as error
+	if res.StatusCode >= 400 {
+		return nil, fmt.Errorf(
+			"failed to verify the provided id_token (%d):\n%s",
+			res.StatusCode,
+			string(rawBody),
+		)
+	}
+
+	jwks := struct {
+		Keys []*jwk
+	}{}
+	if err := json.Unmarshal(rawBody, &jwks); err != nil {
+		return nil, err
+	}
+
+	for _, key := range jwks.Keys {
+		if key.Kid == kid {
+			return key, nil
+		}
+	}
+
+	return nil, fmt.Errorf("jwk with kid %q was not found", kid)
+}
+
+// verifyIdTokenSignature verifies the id_token signature with
+// the matching JWKS key and returns its (time validated) claims.
+//
+// Note that the issuer and the audience claims are
+// expected to be checked by the caller.
+func verifyIdTokenSignature(ctx context.Context, jwksUrl string, idToken string) (jwt.MapClaims, error) {
+	if idToken == "" {
+		return nil, errors.New("empty id_token")
+	}
+
+	// extract the token header params
+	t, _, err := jwt.NewParser().ParseUnverified(idToken, jwt.MapClaims{})
+	if err != nil {
+		return nil, err
+	}
+
+	kid, _ := t.Header["kid"].(string)
+	if kid == "" {
+		return nil, errors.New("missing kid header value")
+	}
+
+	key, err := fetchJWK(ctx, jwksUrl, kid)
+	if err != nil {
+		return nil, err
+	}
+
+	publicKey, err := key.publicKey()
+	if err != nil {
+		return nil, err
+	}
+
+	// the key alg is optional so fallback to the token header one
+	// (it is safe since the key type restricts the allowed methods)
+	alg := key.Alg
+	if alg == "" {
+		alg, _ = t.Header["alg"].(string)
+	}
+
+	parser := jwt.NewParser(jwt.WithValidMethods([]string{alg}))
+
+	parsedToken, err := parser.Parse(idToken, func(t *jwt.Token) (any, error) {
+		return publicKey, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if claims, ok := parsedToken.Claims.(jwt.MapClaims); ok && parsedToken.Valid {
+		return claims, nil
+	}
+
+	return nil, errors.New("the parsed id_token is invalid")
+}
@@ -3,7 +3,15 @@ package auth
 import (
 	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
 
+	"github.com/golang-jwt/jwt/v4"
 	"golang.org/x/oauth2"
 )
 
@@ -12,14 +20,28 @@ var _ Provider = (*OIDC)(nil)
 // NameOIDC is the unique name of the OpenID Connect (OIDC) provider.
 const NameOIDC string = "oidc"
 
+// OIDCDiscoveryPath is the issuer relative path of the provider configuration document.
+const OIDCDiscoveryPath string = "/.well-known/openid-configuration"
+
+// OIDCDiscoveryCacheDuration specifies how long the fetched
+// provider configuration documents are reused.
+var OIDCDiscoveryCacheDuration = 1 * time.Hour
+
 // OIDC allows authentication via OpenID Connect (OIDC) OAuth2 provider.
+//
+// The provider endpoints could be either set manually or discovered
+// from the issuer configuration document (see [OIDC.Discover]).
 type OIDC struct {
 	*baseProvider
+
+	issuerUrl string
+	issuer    string
+	jwksUrl   string
 }
 
 // NewOIDCProvider creates new OpenID Connect (OIDC) provider instance with some defaults.
 func NewOIDCProvider() *OIDC {
-	return &OIDC{&baseProvider{
+	return &OIDC{baseProvider: &baseProvider{
 		ctx: context.Background(),
 		scopes: []string{
 			"openid", // minimal requirement to return the id
@@ -29,6 +51,44 @@ func NewOIDCProvider() *OIDC {
 	}}
 }
 
+// IssuerUrl returns the provider's OpenID Connect issuer url.
+func (p *OIDC) IssuerUrl() string {
+	return p.issuerUrl
+}
+
+// SetIssuerUrl sets the provider's OpenID Connect issuer url.
+//
+// Call [OIDC.Discover] to load the provider endpoints from the issuer.
+func (p *OIDC) SetIssuerUrl(url string) {
+	p.issuerUrl = strings.TrimSuffix(url, "/")
+}
+
+// Discover fetches the issuer configuration document and loads from it
+// the provider auth, token, user info and JWKS endpoints.
+//
+// Once discovered, the provider requires and validates the "id_token"
+// returned with the exchanged token.
+//
+// The fetched configuration documents are cached for [OIDCDiscoveryCacheDuration].
+func (p *OIDC) Discover() error {
+	if p.issuerUrl == "" {
+		return errors.New("missing OIDC issuer url")
+	}
+
+	config, err := fetchOIDCConfig(p.ctx, p.issuerUrl)
+	if err != nil {
+		return err
+	}
+
+	p.issuer = config.Issuer
+	p.jwksUrl = config.JwksUri
+	p.authUrl = config.AuthorizationEndpoint
+	p.tokenUrl = config.TokenEndpoint
+	p.userApiUrl = config.UserinfoEndpoint
+
+	return nil
+}
+
 // FetchAuthUser returns an AuthUser instance based the provider's user api.
 //
 // API reference: https://openid.net/specs/openid-connect-core-1_0.html#StandardClaims
@@ -71,3 +131,148 @@ func (p *OIDC) FetchAuthUser(token *oauth2.Token) (*AuthUser, error) {
 
 	return user, nil
 }
+
+// FetchRawUserData implements Provider.FetchRawUserData interface.
+//
+// For discovered providers the returned data is the verified "id_token"
+// claims merged with the user info endpoint response (if available).
+func (p *OIDC) FetchRawUserData(token *oauth2.Token) ([]byte, error) {
+	if p.jwksUrl == "" {
+		return p.baseProvider.FetchRawUserData(token)
+	}
+
+	idToken, _ := token.Extra("id_token").(string)
+
+	claims, err := p.parseAndVerifyIdToken(idToken)
+	if err != nil {
+		return nil, err
+	}
+
+	if p.userApiUrl != "" {
+		data, err := p.baseProvider.FetchRawUserData(token)
+		if err != nil {
+			return nil, err
+		}
+
+		userInfo := map[string]any{}
+		if err := json.Unmarshal(data, &userInfo); err != nil {
+			return nil, err
+		}
+
+		// https://openid.net/specs/openid-connect-core-1_0.html#UserInfoResponse
+		if userInfo["sub"] != claims["sub"] {
+			return nil, errors.New("the user info sub doesn't match with the id_token one")
+		}
+
+		for k, v := range userInfo {
+			claims[k] = v
+		}
+	}
+
+	return json.Marshal(claims)
+}
+
+// -------------------------------------------------------------------
+
+// parseAndVerifyIdToken validates the id_token
+// per https://openid.net/specs/openid-connect-core-1_0.html#IDTokenValidation.
+func (p *OIDC) parseAndVerifyIdToken(idToken string) (jwt.MapClaims, error) {
+	claims, err := verifyIdTokenSignature(p.ctx, p.jwksUrl, idToken)
+	if err != nil {
+		return nil, err
+	}
+
+	if !claims.VerifyIssuer(p.issuer, true) {
+		return nil, fmt.Errorf("iss must be %s", p.issuer)
+	}
+
+	if !claims.VerifyAudience(p.clientId, true) {
+		return nil, errors.New("aud must contain the provider client_id")
+	}
+
+	if azp, ok := claims["azp"]; ok && azp != p.clientId {
+		return nil, errors.New("azp must be the provider client_id")
+	}
+
+	if sub, _ := claims["sub"].(string); sub == "" {
+		return nil, errors.New("missing id_token sub claim")
+	}
+
+	return claims, nil
+}
+
+// oidcConfig defines the used fields of an OpenID Connect provider configuration document.
+//
+// https://openid.net/specs/openid-connect-discovery-1_0.html#ProviderMetadata
+type oidcConfig struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	UserinfoEndpoint      string `json:"userinfo_endpoint"`
+	JwksUri               string `json:"jwks_uri"`
+
+	fetched time.Time
+}
+
+var (
+	oidcConfigsMux sync.Mutex
+	oidcConfigs    = map[string]*oidcConfig{}
+)
+
+func fetchOIDCConfig(ctx context.Context, issuerUrl string) (*oidcConfig, error) {
+	oidcConfigsMux.Lock()
+	cached := oidcConfigs[issuerUrl]
+	oidcConfigsMux.Unlock()
+
+	if cached != nil && time.Since(cached.fetched) < OIDCDiscoveryCacheDuration {
+		return cached, nil
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "GET", issuerUrl+OIDCDiscoveryPath, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	rawBody, err := io.ReadAll(res.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	// http.Client.Get doesn't treat non 2xx responses as error
+	if res.StatusCode >= 400 {
+		return nil, fmt.Errorf(
+			"failed to fetch the OIDC provider configuration via %s (%d):\n%s",
+			req.URL,
+			res.StatusCode,
+			string(rawBody),
+		)
+	}
+
+	config := &oidcConfig{}
+	if err := json.Unmarshal(rawBody, config); err != nil {
+		return nil, err
+	}
+
+	// https://openid.net/specs/openid-connect-discovery-1_0.html#ProviderConfigurationValidation
+	if strings.TrimSuffix(config.Issuer, "/") != issuerUrl {
+		return nil, fmt.Errorf("the discovered issuer %q doesn't match with %q", config.Issuer, issuerUrl)
+	}
+
+	if config.AuthorizationEndpoint == "" || config.TokenEndpoint == "" || config.JwksUri == "" {
+		return nil, errors.New("the OIDC provider configuration is missing required endpoints")
+	}
+
+	config.fetched = time.Now()
+
+	oidcConfigsMux.Lock()
+	oidcConfigs[issuerUrl] = config
+	oidcConfigsMux.Unlock()
+
+	return config, nil
+}
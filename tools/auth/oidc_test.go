@@ -0,0 +1,264 @@
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+	"golang.org/x/oauth2"
+)
+
+type testOIDCServer struct {
+	*httptest.Server
+
+	key      *rsa.PrivateKey
+	issuer   string
+	userInfo map[string]any
+
+	discoveryCalls int
+}
+
+func newTestOIDCServer(t *testing.T) *testOIDCServer {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s := &testOIDCServer{key: key}
+
+	mux := http.NewServeMux()
+
+	mux.HandleFunc(OIDCDiscoveryPath, func(w http.ResponseWriter, r *http.Request) {
+		s.discoveryCalls++
+		json.NewEncoder(w).Encode(map[string]any{
+			"issuer":                 s.issuer,
+			"authorization_endpoint": s.URL + "/auth",
+			"token_endpoint":         s.URL + "/token",
+			"userinfo_endpoint":      s.URL + "/userinfo",
+			"jwks_uri":               s.URL + "/jwks",
+		})
+	})
+
+	mux.HandleFunc("/jwks", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]any{
+			"keys": []map[string]any{{
+				"kty": "RSA",
+				"kid": "test_kid",
+				"use": "sig",
+				"alg": "RS256",
+				"n":   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+				"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+			}},
+		})
+	})
+
+	mux.HandleFunc("/userinfo", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(s.userInfo)
+	})
+
+	s.Server = httptest.NewServer(mux)
+	s.issuer = s.URL
+
+	t.Cleanup(s.Close)
+
+	return s
+}
+
+func (s *testOIDCServer) idToken(t *testing.T, claims jwt.MapClaims) string {
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	token.Header["kid"] = "test_kid"
+
+	signed, err := token.SignedString(s.key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return signed
+}
+
+func TestOIDCDiscover(t *testing.T) {
+	server := newTestOIDCServer(t)
+
+	p := NewOIDCProvider()
+
+	if err := p.Discover(); err == nil {
+		t.Fatal("Expected error for missing issuer url")
+	}
+
+	p.SetIssuerUrl(server.URL + "/")
+
+	if p.IssuerUrl() != server.URL {
+		t.Fatalf("Expected issuer url %q, got %q", server.URL, p.IssuerUrl())
+	}
+
+	if err := p.Discover(); err != nil {
+		t.Fatal(err)
+	}
+
+	if p.AuthUrl() != server.URL+"/auth" {
+		t.Fatalf("Expected auth url %q, got %q", server.URL+"/auth", p.AuthUrl())
+	}
+
+	if p.TokenUrl() != server.URL+"/token" {
+		t.Fatalf("Expected token url %q, got %q", server.URL+"/token", p.TokenUrl())
+	}
+
+	if p.UserApiUrl() != server.URL+"/userinfo" {
+		t.Fatalf("Expected user api url %q, got %q", server.URL+"/userinfo", p.UserApiUrl())
+	}
+
+	// the configuration document should be cached
+	p2 := NewOIDCProvider()
+	p2.SetIssuerUrl(server.URL)
+	if err := p2.Discover(); err != nil {
+		t.Fatal(err)
+	}
+	if server.discoveryCalls != 1 {
+		t.Fatalf("Expected 1 discovery call, got %d", server.discoveryCalls)
+	}
+}
+
+func TestOIDCDiscoverIssuerMismatch(t *testing.T) {
+	server := newTestOIDCServer(t)
+	server.issuer = "https://example.com"
+
+	p := NewOIDCProvider()
+	p.SetIssuerUrl(server.URL)
+
+	if err := p.Discover(); err == nil {
+		t.Fatal("Expected issuer mismatch error")
+	}
+}
+
+func TestOIDCFetchAuthUser(t *testing.T) {
+	server := newTestOIDCServer(t)
+	server.userInfo = map[string]any{
+		"sub":            "test_sub",
+		"name":           "test_name",
+		"picture":        "https://example.com/avatar.png",
+		"email":          "test@example.com",
+		"email_verified": true,
+	}
+
+	validClaims := func() jwt.MapClaims {
+		return jwt.MapClaims{
+			"iss":                server.issuer,
+			"aud":                "test_client",
+			"sub":                "test_sub",
+			"exp":                time.Now().Add(1 * time.Minute).Unix(),
+			"preferred_username": "test_username",
+		}
+	}
+
+	scenarios := []struct {
+		name        string
+		claims      func() jwt.MapClaims
+		expectError bool
+	}{
+		{
+			"valid id_token",
+			validClaims,
+			false,
+		},
+		{
+			"expired id_token",
+			func() jwt.MapClaims {
+				c := validClaims()
+				c["exp"] = time.Now().Add(-1 * time.Minute).Unix()
+				return c
+			},
+			true,
+		},
+		{
+			"invalid issuer",
+			func() jwt.MapClaims {
+				c := validClaims()
+				c["iss"] = "https://example.com"
+				return c
+			},
+			true,
+		},
+		{
+			"invalid audience",
+			func() jwt.MapClaims {
+				c := validClaims()
+				c["aud"] = []string{"other_client"}
+				return c
+			},
+			true,
+		},
+		{
+			"invalid authorized party",
+			func() jwt.MapClaims {
+				c := validClaims()
+				c["aud"] = []string{"test_client", "other_client"}
+				c["azp"] = "other_client"
+				return c
+			},
+			true,
+		},
+		{
+			"user info sub mismatch",
+			func() jwt.MapClaims {
+				c := validClaims()
+				c["sub"] = "other_sub"
+				return c
+			},
+			true,
+		},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			p := NewOIDCProvider()
+			p.SetClientId("test_client")
+			p.SetIssuerUrl(server.URL)
+			if err := p.Discover(); err != nil {
+				t.Fatal(err)
+			}
+
+			token := (&oauth2.Token{AccessToken: "test_access"}).WithExtra(map[string]any{
+				"id_token": server.idToken(t, s.claims()),
+			})
+
+			user, err := p.FetchAuthUser(token)
+
+			hasErr := err != nil
+			if hasErr != s.expectError {
+				t.Fatalf("Expected hasErr %v, got %v (%v)", s.expectError, hasErr, err)
+			}
+
+			if hasErr {
+				return
+			}
+
+			if user.Id != "test_sub" ||
+				user.Name != "test_name" ||
+				user.Username != "test_username" ||
+				user.Email != "test@example.com" ||
+				user.AvatarUrl != "https://example.com/avatar.png" ||
+				user.AccessToken != "test_access" {
+				t.Fatalf("Unexpected auth user %v", user)
+			}
+		})
+	}
+
+	// missing id_token
+	p := NewOIDCProvider()
+	p.SetClientId("test_client")
+	p.SetIssuerUrl(server.URL)
+	if err := p.Discover(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := p.FetchAuthUser(&oauth2.Token{AccessToken: "test_access"}); err == nil || !strings.Contains(err.Error(), "id_token") {
+		t.Fatalf("Expected missing id_token error, got %v", err)
+	}
+}
@@ -0,0 +1,488 @@
+// Package expr implements a small sandboxed expression evaluator
+// with support for arithmetic operations and string concatenation.
+//
+// The expressions have no access to anything other than the explicitly
+// provided variables and functions and they cannot loop.
+//
+// Example
+//
+//	e, _ := expr.Parse("price * qty + 1")
+//	result, _ := e.Eval(map[string]any{"price": 2.5, "qty": 2}) // 6
+//
+//	e, _ = expr.Parse("title + ' (' + qty + ')'")
+//	result, _ = e.Eval(map[string]any{"title": "Box", "qty": 2}) // "Box (2)"
+//
+//	e, _ = expr.ParseWithFunctions("@request.id + '_' + rand()", map[string]expr.Func{"rand": rand})
+//	result, _ = e.Eval(map[string]any{"@request.id": "abc"}) // "abc_..."
+package expr
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/spf13/cast"
+)
+
+// MaxLength is the max allowed expression length (in bytes).
+const MaxLength = 1000
+
+// MaxDepth is the max allowed nesting depth of the expression
+// parenthesis and unary operators.
+const MaxDepth = 50
+
+// ErrDivisionByZero is returned when evaluating a division or modulo by zero.
+var ErrDivisionByZero = errors.New("division by zero")
+
+// Func is a function that could be called (without arguments) from an expression.
+type Func func() (any, error)
+
+// Expr is a single parsed expression.
+type Expr struct {
+	root        node
+	identifiers []string
+}
+
+// Parse parses the provided expression source.
+//
+// The supported syntax is:
+//   - number literals (eg. 1, 2.5)
+//   - single or double quoted string literals (eg. 'abc', "abc")
+//   - identifiers of the evaluation variables (eg. price, qty_2, @request.auth.id)
+//   - the binary operators +, -, *, / and % (+ concatenates if any of its operands is a string)
+//   - the unary minus operator
+//   - parenthesis for grouping
+func Parse(src string) (*Expr, error) {
+	return ParseWithFunctions(src, nil)
+}
+
+// ParseWithFunctions parses the provided expression source similar to [Parse]
+// but additionally allows calling the provided functions, eg. "now()".
+func ParseWithFunctions(src string, funcs map[string]Func) (*Expr, error) {
+	if strings.TrimSpace(src) == "" {
+		return nil, errors.New("empty expression")
+	}
+
+	if len(src) > MaxLength {
+		return nil, fmt.Errorf("the expression must be less than %d characters", MaxLength)
+	}
+
+	tokens, err := lex(src)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &parser{tokens: tokens, identifiers: map[string]struct{}{}, funcs: funcs}
+
+	root, err := p.parseExpr(0)
+	if err != nil {
+		return nil, err
+	}
+
+	if t := p.peek(); t.kind != tokenEOF {
+		return nil, fmt.Errorf("unexpected token %q at position %d", t.value, t.pos)
+	}
+
+	result := &Expr{root: root}
+	for _, t := range tokens {
+		if t.kind != tokenIdentifier {
+			continue
+		}
+		if _, ok := p.identifiers[t.value]; ok {
+			result.identifiers = append(result.identifiers, t.value)
+			delete(p.identifiers, t.value)
+		}
+	}
+
+	return result, nil
+}
+
+// Identifiers returns the unique variable names used in the expression
+// in the order of their first occurrence.
+func (e *Expr) Identifiers() []string {
+	return append([]string{}, e.identifiers...)
+}
+
+// Eval evaluates the expression with the provided variables.
+//
+// The result is either a float64 or a string.
+// Missing variables are treated as nil (aka. 0 or empty string).
+func (e *Expr) Eval(vars map[string]any) (any, error) {
+	return e.root.eval(vars)
+}
+
+// -------------------------------------------------------------------
+// lexer
+// -------------------------------------------------------------------
+
+const (
+	tokenEOF = iota
+	tokenNumber
+	tokenString
+	tokenIdentifier
+	tokenOperator
+	tokenOpenParen
+	tokenCloseParen
+)
+
+type token struct {
+	kind  int
+	value string
+	pos   int
+}
+
+func lex(src string) ([]token, error) {
+	tokens := []token{}
+	runes := []rune(src)
+
+	for i := 0; i < len(runes); {
+		r := runes[i]
+
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case r == '(':
+			tokens = append(tokens, token{kind: tokenOpenParen, value: "(", pos: i})
+			i++
+		case r == ')':
+			tokens = append(tokens, token{kind: tokenCloseParen, value: ")", pos: i})
+			i++
+		case strings.ContainsRune("+-*/%", r):
+			tokens = append(tokens, token{kind: tokenOperator, value: string(r), pos: i})
+			i++
+		case r == '\'' || r == '"':
+			start := i
+			var value strings.Builder
+			i++
+			for ; i < len(runes) && runes[i] != r; i++ {
+				if runes[i] == '\\' && i+1 < len(runes) {
+					i++
+				}
+				value.WriteRune(runes[i])
+			}
+			if i >= len(runes) {
+				return nil, fmt.Errorf("unterminated string literal at position %d", start)
+			}
+			i++ // closing quote
+			tokens = append(tokens, token{kind: tokenString, value: value.String(), pos: start})
+		case unicode.IsDigit(r) || (r == '.' && i+1 < len(runes) && unicode.IsDigit(runes[i+1])):
+			start := i
+			for i < len(runes) && (unicode.IsDigit(runes[i]) || runes[i] == '.') {
+				i++
+			}
+			tokens = append(tokens, token{kind: tokenNumber, value: string(runes[start:i]), pos: start})
+		case r == '@':
+			// special dot-separated identifier (eg. @request.auth.id)
+			start := i
+			i++
+			for i < len(runes) && (runes[i] == '_' || runes[i] == '.' || unicode.IsLetter(runes[i]) || unicode.IsDigit(runes[i])) {
+				i++
+			}
+			value := string(runes[start:i])
+			if len(value) == 1 || strings.HasSuffix(value, ".") || strings.Contains(value, "..") || value[1] == '.' {
+				return nil, fmt.Errorf("invalid identifier %q at position %d", value, start)
+			}
+			tokens = append(tokens, token{kind: tokenIdentifier, value: value, pos: start})
+		case r == '_' || unicode.IsLetter(r):
+			start := i
+			for i < len(runes) && (runes[i] == '_' || unicode.IsLetter(runes[i]) || unicode.IsDigit(runes[i])) {
+				i++
+			}
+			tokens = append(tokens, token{kind: tokenIdentifier, value: string(runes[start:i]), pos: start})
+		default:
+			return nil, fmt.Errorf("invalid character %q at position %d", r, i)
+		}
+	}
+
+	return append(tokens, token{kind: tokenEOF, pos: len(runes)}), nil
+}
+
+// -------------------------------------------------------------------
+// parser
+// -------------------------------------------------------------------
+
+type parser struct {
+	tokens      []token
+	pos         int
+	identifiers map[string]struct{}
+	funcs       map[string]Func
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *parser) next() token {
+	t := p.tokens[p.pos]
+	if t.kind != tokenEOF {
+		p.pos++
+	}
+	return t
+}
+
+// parseExpr parses an additive expression (aka. terms separated by + or -).
+func (p *parser) parseExpr(depth int) (node, error) {
+	if depth > MaxDepth {
+		return nil, fmt.Errorf("the expression must have less than %d nesting levels", MaxDepth)
+	}
+
+	left, err := p.parseTerm(depth)
+	if err != nil {
+		return nil, err
+	}
+
+	for {
+		t := p.peek()
+		if t.kind != tokenOperator || (t.value != "+" && t.value != "-") {
+			return left, nil
+		}
+		p.next()
+
+		right, err := p.parseTerm(depth)
+		if err != nil {
+			return nil, err
+		}
+
+		left = &binaryNode{op: t.value, left: left, right: right}
+	}
+}
+
+// parseTerm parses a multiplicative expression (aka. factors separated by *, / or %).
+func (p *parser) parseTerm(depth int) (node, error) {
+	left, err := p.parseUnary(depth)
+	if err != nil {
+		return nil, err
+	}
+
+	for {
+		t := p.peek()
+		if t.kind != tokenOperator || (t.value != "*" && t.value != "/" && t.value != "%") {
+			return left, nil
+		}
+		p.next()
+
+		right, err := p.parseUnary(depth)
+		if err != nil {
+			return nil, err
+		}
+
+		left = &binaryNode{op: t.value, left: left, right: right}
+	}
+}
+
+func (p *parser) parseUnary(depth int) (node, error) {
+	if depth > MaxDepth {
+		return nil, fmt.Errorf("the expression must have less than %d nesting levels", MaxDepth)
+	}
+
+	if t := p.peek(); t.kind == tokenOperator && t.value == "-" {
+		p.next()
+
+		operand, err := p.parseUnary(depth + 1)
+		if err != nil {
+			return nil, err
+		}
+
+		return &negateNode{operand: operand}, nil
+	}
+
+	return p.parsePrimary(depth)
+}
+
+func (p *parser) parsePrimary(depth int) (node, error) {
+	t := p.next()
+
+	switch t.kind {
+	case tokenNumber:
+		v, err := strconv.ParseFloat(t.value, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid number %q at position %d", t.value, t.pos)
+		}
+		return &literalNode{value: v}, nil
+	case tokenString:
+		return &literalNode{value: t.value}, nil
+	case tokenIdentifier:
+		if p.peek().kind == tokenOpenParen {
+			fn, ok := p.funcs[t.value]
+			if !ok {
+				return nil, fmt.Errorf("unknown function %q at position %d", t.value, t.pos)
+			}
+			p.next()
+			if closing := p.next(); closing.kind != tokenCloseParen {
+				return nil, fmt.Errorf("function %q doesn't accept arguments (position %d)", t.value, closing.pos)
+			}
+			return &callNode{fn: fn}, nil
+		}
+		p.identifiers[t.value] = struct{}{}
+		return &identifierNode{name: t.value}, nil
+	case tokenOpenParen:
+		inner, err := p.parseExpr(depth + 1)
+		if err != nil {
+			return nil, err
+		}
+		if closing := p.next(); closing.kind != tokenCloseParen {
+			return nil, fmt.Errorf("missing closing parenthesis at position %d", closing.pos)
+		}
+		return inner, nil
+	case tokenEOF:
+		return nil, errors.New("unexpected end of the expression")
+	default:
+		return nil, fmt.Errorf("unexpected token %q at position %d", t.value, t.pos)
+	}
+}
+
+// -------------------------------------------------------------------
+// nodes
+// -------------------------------------------------------------------
+
+type node interface {
+	eval(vars map[string]any) (any, error)
+}
+
+type literalNode struct {
+	value any
+}
+
+func (n *literalNode) eval(vars map[string]any) (any, error) {
+	return n.value, nil
+}
+
+type identifierNode struct {
+	name string
+}
+
+func (n *identifierNode) eval(vars map[string]any) (any, error) {
+	return vars[n.name], nil
+}
+
+type callNode struct {
+	fn Func
+}
+
+func (n *callNode) eval(vars map[string]any) (any, error) {
+	return n.fn()
+}
+
+type negateNode struct {
+	operand node
+}
+
+func (n *negateNode) eval(vars map[string]any) (any, error) {
+	v, err := n.operand.eval(vars)
+	if err != nil {
+		return nil, err
+	}
+
+	num, err := toNumber(v)
+	if err != nil {
+		return nil, err
+	}
+
+	return -num, nil
+}
+
+type binaryNode struct {
+	op    string
+	left  node
+	right node
+}
+
+func (n *binaryNode) eval(vars map[string]any) (any, error) {
+	left, err := n.left.eval(vars)
+	if err != nil {
+		return nil, err
+	}
+
+	right, err := n.right.eval(vars)
+	if err != nil {
+		return nil, err
+	}
+
+	if n.op == "+" && (isString(left) || isString(right)) {
+		l, err := toString(left)
+		if err != nil {
+			return nil, err
+		}
+
+		r, err := toString(right)
+		if err != nil {
+			return nil, err
+		}
+
+		if len(l)+len(r) > MaxLength*10 {
+			return nil, errors.New("the concatenated string is too long")
+		}
+
+		return l + r, nil
+	}
+
+	l, err := toNumber(left)
+	if err != nil {
+		return nil, err
+	}
+
+	r, err := toNumber(right)
+	if err != nil {
+		return nil, err
+	}
+
+	switch n.op {
+	case "+":
+		return l + r, nil
+	case "-":
+		return l - r, nil
+	case "*":
+		return l * r, nil
+	case "/":
+		if r == 0 {
+			return nil, ErrDivisionByZero
+		}
+		return l / r, nil
+	case "%":
+		if r == 0 {
+			return nil, ErrDivisionByZero
+		}
+		return math.Mod(l, r), nil
+	}
+
+	return nil, fmt.Errorf("unsupported operator %q", n.op)
+}
+
+func isString(v any) bool {
+	switch v.(type) {
+	case string, fmt.Stringer:
+		return true
+	}
+
+	return false
+}
+
+func toString(v any) (string, error) {
+	if s, ok := v.(fmt.Stringer); ok {
+		return s.String(), nil
+	}
+
+	result, err := cast.ToStringE(v)
+	if err != nil {
+		return "", fmt.Errorf("%T value can't be used as string", v)
+	}
+
+	return result, nil
+}
+
+func toNumber(v any) (float64, error) {
+	switch v.(type) {
+	case string, fmt.Stringer:
+		return 0, fmt.Errorf("%T value can't be used as number", v)
+	}
+
+	result, err := cast.ToFloat64E(v)
+	if err != nil {
+		return 0, fmt.Errorf("%T value can't be used as number", v)
+	}
+
+	return result, nil
+}
@@ -0,0 +1,251 @@
+package expr_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/unkod/space/tools/expr"
+	"github.com/unkod/space/tools/types"
+)
+
+func TestParse(t *testing.T) {
+	scenarios := []struct {
+		src                 string
+		expectError         bool
+		expectedIdentifiers []string
+	}{
+		{"", true, nil},
+		{"   ", true, nil},
+		{strings.Repeat("1+", expr.MaxLength) + "1", true, nil},
+		{strings.Repeat("(", expr.MaxDepth+1) + "1" + strings.Repeat(")", expr.MaxDepth+1), true, nil},
+		{strings.Repeat("-", expr.MaxDepth+2) + "1", true, nil},
+		{"1 +", true, nil},
+		{"* 2", true, nil},
+		{"(1 + 2", true, nil},
+		{"1 + 2)", true, nil},
+		{"1 2", true, nil},
+		{"'abc", true, nil},
+		{"a.b", true, nil},
+		{"a == b", true, nil},
+		{"fn(1)", true, nil},
+		{"1.2.3", true, nil},
+		{"@", true, nil},
+		{"@.a", true, nil},
+		{"@a.", true, nil},
+		{"@a..b", true, nil},
+		{"1", false, []string{}},
+		{"'abc' + \"def\"", false, []string{}},
+		{"price * qty", false, []string{"price", "qty"}},
+		{"(a + b) * a - _c / (-d_2 % b)", false, []string{"a", "b", "_c", "d_2"}},
+		{"@now + @request.auth.id + a", false, []string{"@now", "@request.auth.id", "a"}},
+	}
+
+	for i, s := range scenarios {
+		e, err := expr.Parse(s.src)
+
+		hasErr := err != nil
+		if hasErr != s.expectError {
+			t.Errorf("(%d) Expected hasErr %v, got %v (%v)", i, s.expectError, hasErr, err)
+			continue
+		}
+
+		if hasErr {
+			continue
+		}
+
+		identifiers := e.Identifiers()
+		if strings.Join(identifiers, ",") != strings.Join(s.expectedIdentifiers, ",") {
+			t.Errorf("(%d) Expected identifiers %v, got %v", i, s.expectedIdentifiers, identifiers)
+		}
+	}
+}
+
+func TestParseWithFunctions(t *testing.T) {
+	funcs := map[string]expr.Func{
+		"one": func() (any, error) {
+			return 1, nil
+		},
+		"fail": func() (any, error) {
+			return nil, errors.New("test")
+		},
+	}
+
+	scenarios := []struct {
+		src         string
+		expectError bool
+		expected    any
+	}{
+		{"missing()", true, nil},
+		{"one(1)", true, nil},
+		{"one(", true, nil},
+		{"one", false, nil}, // identifier
+		{"one()", false, 1},
+		{"one() + one() * 2", false, 3.0},
+		{"'a' + one()", false, "a1"},
+	}
+
+	for i, s := range scenarios {
+		e, err := expr.ParseWithFunctions(s.src, funcs)
+
+		hasErr := err != nil
+		if hasErr != s.expectError {
+			t.Errorf("(%d) Expected hasErr %v, got %v (%v)", i, s.expectError, hasErr, err)
+			continue
+		}
+
+		if hasErr {
+			continue
+		}
+
+		result, err := e.Eval(nil)
+		if err != nil {
+			t.Errorf("(%d) Failed to evaluate: %v", i, err)
+			continue
+		}
+
+		if result != s.expected {
+			t.Errorf("(%d) Expected %v (%T), got %v (%T)", i, s.expected, s.expected, result, result)
+		}
+	}
+
+	// function error
+	e, err := expr.ParseWithFunctions("fail() + 1", funcs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := e.Eval(nil); err == nil {
+		t.Fatal("Expected the function error to be returned")
+	}
+}
+
+func TestEvalArithmetic(t *testing.T) {
+	vars := map[string]any{
+		"price": 2.5,
+		"qty":   4,
+		"zero":  0,
+		"flag":  true,
+	}
+
+	scenarios := []struct {
+		src      string
+		expected float64
+	}{
+		{"1", 1},
+		{".5", 0.5},
+		{"price * qty", 10},
+		{"price * qty + 1", 11},
+		{"1 + price * qty", 11},
+		{"(1 + price) * qty", 14},
+		{"qty - 1 - 1", 2},
+		{"qty / 2 / 2", 1},
+		{"qty % 3", 1},
+		{"-qty", -4},
+		{"--qty", 4},
+		{"qty * -price", -10},
+		{"qty * flag", 4},
+		{"missing + 1", 1},
+		{"zero * price", 0},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.src, func(t *testing.T) {
+			e, err := expr.Parse(s.src)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			result, err := e.Eval(vars)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if v, ok := result.(float64); !ok || v != s.expected {
+				t.Fatalf("Expected %v, got %v (%T)", s.expected, result, result)
+			}
+		})
+	}
+}
+
+func TestEvalConcatenation(t *testing.T) {
+	date, _ := types.ParseDateTime("2023-01-01 10:00:00.000Z")
+
+	vars := map[string]any{
+		"title": "Box",
+		"qty":   2,
+		"price": 2.5,
+		"date":  date,
+	}
+
+	scenarios := []struct {
+		src      string
+		expected string
+	}{
+		{"'abc'", "abc"},
+		{`"a\"b" + 'c\'d'`, `a"bc'd`},
+		{"title + ' (' + qty + ')'", "Box (2)"},
+		{"title + qty * price", "Box5"},
+		{"title + qty + price", "Box22.5"},
+		{"qty + price + title", "4.5Box"},
+		{"'' + missing", ""},
+		{"'created: ' + date", "created: 2023-01-01 10:00:00.000Z"},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.src, func(t *testing.T) {
+			e, err := expr.Parse(s.src)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			result, err := e.Eval(vars)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if v, ok := result.(string); !ok || v != s.expected {
+				t.Fatalf("Expected %q, got %v (%T)", s.expected, result, result)
+			}
+		})
+	}
+}
+
+func TestEvalErrors(t *testing.T) {
+	vars := map[string]any{
+		"title": "Box",
+		"qty":   2,
+		"zero":  0,
+		"list":  []string{"a", "b"},
+	}
+
+	scenarios := []struct {
+		src         string
+		expectedErr error
+	}{
+		{"qty / zero", expr.ErrDivisionByZero},
+		{"qty % 0", expr.ErrDivisionByZero},
+		{"title * qty", nil},
+		{"-title", nil},
+		{"title - 'a'", nil},
+		{"list * 2", nil},
+		{"'a' + list", nil},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.src, func(t *testing.T) {
+			e, err := expr.Parse(s.src)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			result, err := e.Eval(vars)
+			if err == nil {
+				t.Fatalf("Expected error, got %v", result)
+			}
+
+			if s.expectedErr != nil && !errors.Is(err, s.expectedErr) {
+				t.Fatalf("Expected error %v, got %v", s.expectedErr, err)
+			}
+		})
+	}
+}
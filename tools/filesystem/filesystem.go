@@ -2,9 +2,11 @@ package filesystem
 
 import (
 	"context"
+	"encoding/hex"
 	"errors"
 	"image"
 	"io"
+	"mime"
 	"mime/multipart"
 	"net/http"
 	"os"
@@ -13,10 +15,12 @@ import (
 	"sort"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/aws/credentials"
 	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/s3"
 	"github.com/disintegration/imaging"
 	"github.com/gabriel-vasile/mimetype"
 	"github.com/unkod/space/tools/list"
@@ -28,10 +32,17 @@ import (
 type System struct {
 	ctx    context.Context
 	bucket *blob.Bucket
+
+	// s3BucketName is the name of the S3 bucket (empty for the local filesystem)
+	s3BucketName string
 }
 
 // NewS3 initializes an S3 filesystem instance.
 //
+// The endpoint is optional and when empty the default AWS S3 region
+// endpoint is used. For S3 compatible services that doesn't support
+// virtual-hosted style requests (eg. MinIO) set s3ForcePathStyle to true.
+//
 // NB! Make sure to call `Close()` after you are done working with it.
 func NewS3(
 	bucketName string,
@@ -45,12 +56,18 @@ func NewS3(
 
 	cred := credentials.NewStaticCredentials(accessKey, secretKey, "")
 
-	sess, err := session.NewSession(&aws.Config{
+	config := &aws.Config{
 		Region:           aws.String(region),
-		Endpoint:         aws.String(endpoint),
 		Credentials:      cred,
 		S3ForcePathStyle: aws.Bool(s3ForcePathStyle),
-	})
+	}
+
+	// fallback to the default AWS endpoint resolver
+	if endpoint != "" {
+		config.Endpoint = aws.String(endpoint)
+	}
+
+	sess, err := session.NewSession(config)
 	if err != nil {
 		return nil, err
 	}
@@ -60,7 +77,7 @@ func NewS3(
 		return nil, err
 	}
 
-	return &System{ctx: ctx, bucket: bucket}, nil
+	return &System{ctx: ctx, bucket: bucket, s3BucketName: bucketName}, nil
 }
 
 // NewLocal initializes a new local filesystem instance.
@@ -155,8 +172,34 @@ func (s *System) Upload(content []byte, fileKey string) error {
 	return w.Close()
 }
 
+// UploadFileOptions defines the optional [System.UploadFileWithOptions] settings.
+type UploadFileOptions struct {
+	// PartSize is the size in bytes of a single upload chunk
+	// (for S3 this is the multipart upload part size and the files
+	// larger than it are uploaded with a multipart upload).
+	//
+	// Zero value fallbacks to the driver default (5MB for S3).
+	PartSize int
+
+	// MD5 is the expected MD5 checksum of the file content.
+	//
+	// If set, the upload fails (without completing the write)
+	// when the uploaded bytes doesn't match the checksum.
+	MD5 []byte
+
+	// Metadata is an optional additional file metadata
+	// (the keys are lowercased by the storage drivers).
+	Metadata map[string]string
+}
+
 // UploadFile uploads the provided multipart file to the fileKey location.
 func (s *System) UploadFile(file *File, fileKey string) error {
+	return s.UploadFileWithOptions(file, fileKey, UploadFileOptions{})
+}
+
+// UploadFileWithOptions uploads the provided file to the fileKey location
+// similar to [System.UploadFile] but with additional upload options.
+func (s *System) UploadFileWithOptions(file *File, fileKey string, options UploadFileOptions) error {
 	f, err := file.Reader.Open()
 	if err != nil {
 		return err
@@ -182,6 +225,11 @@ func (s *System) UploadFile(file *File, fileKey string) error {
 		Metadata: map[string]string{
 			"original-filename": originalName,
 		},
+		BufferSize: options.PartSize,
+		ContentMD5: options.MD5,
+	}
+	for k, v := range options.Metadata {
+		opts.Metadata[k] = v
 	}
 
 	w, err := s.bucket.NewWriter(s.ctx, fileKey, opts)
@@ -239,6 +287,71 @@ func (s *System) UploadMultipart(fh *multipart.FileHeader, fileKey string) error
 	return w.Close()
 }
 
+// MultipartUpload defines a single pending (aka. not completed or aborted)
+// S3 multipart upload, eg. from an interrupted large file upload.
+type MultipartUpload struct {
+	Key       string
+	UploadId  string
+	Initiated time.Time
+}
+
+// ListMultipartUploads returns the pending S3 multipart uploads
+// of the files starting with the specified prefix.
+//
+// The parts of the pending uploads are stored (and billed) by the S3
+// provider until the upload is completed or aborted with [System.AbortMultipartUpload].
+//
+// For the local filesystem it always returns an empty slice since
+// the local uploads doesn't leave any partial files behind.
+func (s *System) ListMultipartUploads(prefix string) ([]*MultipartUpload, error) {
+	result := []*MultipartUpload{}
+
+	var client *s3.S3
+	if !s.bucket.As(&client) {
+		return result, nil
+	}
+
+	input := &s3.ListMultipartUploadsInput{
+		Bucket: aws.String(s.s3BucketName),
+		Prefix: aws.String(prefix),
+	}
+
+	err := client.ListMultipartUploadsPagesWithContext(s.ctx, input, func(page *s3.ListMultipartUploadsOutput, lastPage bool) bool {
+		for _, u := range page.Uploads {
+			result = append(result, &MultipartUpload{
+				Key:       aws.StringValue(u.Key),
+				UploadId:  aws.StringValue(u.UploadId),
+				Initiated: aws.TimeValue(u.Initiated),
+			})
+		}
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// AbortMultipartUpload aborts the specified pending S3 multipart upload
+// and removes its already uploaded parts.
+//
+// For the local filesystem it is no-op.
+func (s *System) AbortMultipartUpload(upload *MultipartUpload) error {
+	var client *s3.S3
+	if !s.bucket.As(&client) {
+		return nil
+	}
+
+	_, err := client.AbortMultipartUploadWithContext(s.ctx, &s3.AbortMultipartUploadInput{
+		Bucket:   aws.String(s.s3BucketName),
+		Key:      aws.String(upload.Key),
+		UploadId: aws.String(upload.UploadId),
+	})
+
+	return err
+}
+
 // Delete deletes stored file at fileKey location.
 func (s *System) Delete(fileKey string) error {
 	return s.bucket.Delete(s.ctx, fileKey)
@@ -329,6 +442,9 @@ const forceAttachmentParam = "download"
 //
 // If the `download` query parameter is used the file will be always served for
 // download no matter of its type (aka. with "Content-Disposition: attachment").
+//
+// Single and multi-range requests (eg. "Range: bytes=0-100") are also supported
+// and they are responded with "206 Partial Content" (useful for video seeking).
 func (s *System) Serve(res http.ResponseWriter, req *http.Request, fileKey string, name string) error {
 	br, readErr := s.bucket.NewReader(s.ctx, fileKey, nil)
 	if readErr != nil {
@@ -358,16 +474,40 @@ func (s *System) Serve(res http.ResponseWriter, req *http.Request, fileKey strin
 	setHeaderIfMissing(res, "Content-Type", extContentType)
 	setHeaderIfMissing(res, "Content-Security-Policy", "default-src 'none'; media-src 'self'; style-src 'unsafe-inline'; sandbox")
 
+	// advertise the range requests support even for the invalid range responses
+	setHeaderIfMissing(res, "Accept-Ranges", "bytes")
+
 	// set a default cache-control header
 	// (valid for 30 days but the cache is allowed to reuse the file for any requests
 	// that are made in the last day while revalidating the res in the background)
 	setHeaderIfMissing(res, "Cache-Control", "max-age=2592000, stale-while-revalidate=86400")
 
+	// set a content hash based ETag to allow conditional requests
+	// (the matching If-None-Match requests are handled by http.ServeContent)
+	if etag := s.contentETag(fileKey); etag != "" {
+		setHeaderIfMissing(res, "Etag", etag)
+	}
+
 	http.ServeContent(res, req, name, br.ModTime(), br)
 
 	return nil
 }
 
+// contentETag returns the ETag of the file content at fileKey location
+// (it is empty if the storage doesn't provide a content hash).
+func (s *System) contentETag(fileKey string) string {
+	attrs, err := s.bucket.Attributes(s.ctx, fileKey)
+	if err != nil {
+		return ""
+	}
+
+	if len(attrs.MD5) > 0 {
+		return `"` + hex.EncodeToString(attrs.MD5) + `"`
+	}
+
+	return attrs.ETag
+}
+
 // note: expects key to be in a canonical form (eg. "accept-encoding" should be "Accept-Encoding").
 func setHeaderIfMissing(res http.ResponseWriter, key string, value string) {
 	if _, ok := res.Header()[key]; !ok {
@@ -441,19 +581,30 @@ func (s *System) CreateThumb(originalKey string, thumbKey, thumbSize string) err
 		ContentType: r.ContentType(),
 	}
 
+	// try to detect the thumb format based on the thumb file name
+	// (fallbacks to the original file format on error)
+	format, err := imaging.FormatFromFilename(thumbKey)
+	if err != nil {
+		format, err = imaging.FormatFromFilename(originalKey)
+		if err != nil {
+			format = imaging.PNG
+		}
+	}
+
+	// the thumb could be in a different format than the original
+	thumbExt := filepath.Ext(thumbKey)
+	if !strings.EqualFold(thumbExt, filepath.Ext(originalKey)) {
+		if contentType := mime.TypeByExtension(thumbExt); strings.HasPrefix(contentType, "image/") {
+			opts.ContentType = contentType
+		}
+	}
+
 	// open a thumb storage writer (aka. prepare for upload)
 	w, writerErr := s.bucket.NewWriter(s.ctx, thumbKey, opts)
 	if writerErr != nil {
 		return writerErr
 	}
 
-	// try to detect the thumb format based on the original file name
-	// (fallbacks to png on error)
-	format, err := imaging.FormatFromFilename(thumbKey)
-	if err != nil {
-		format = imaging.PNG
-	}
-
 	// thumb encode (aka. upload)
 	if err := imaging.Encode(w, thumbImg, format); err != nil {
 		w.Close()
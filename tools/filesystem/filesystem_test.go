@@ -2,6 +2,7 @@ package filesystem_test
 
 import (
 	"bytes"
+	"crypto/md5"
 	"image"
 	"image/png"
 	"mime/multipart"
@@ -219,6 +220,77 @@ func TestFileSystemUploadFile(t *testing.T) {
 	}
 }
 
+func TestFileSystemUploadFileWithOptions(t *testing.T) {
+	dir := createTestDir(t)
+	defer os.RemoveAll(dir)
+
+	fs, err := filesystem.NewLocal(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer fs.Close()
+
+	file, err := filesystem.NewFileFromBytes([]byte("test"), "test.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// checksum mismatch
+	invalidMD5 := md5.Sum([]byte("invalid"))
+	err = fs.UploadFileWithOptions(file, "invalid.txt", filesystem.UploadFileOptions{MD5: invalidMD5[:]})
+	if err == nil {
+		t.Fatal("Expected the upload to fail due to MD5 mismatch")
+	}
+	if exists, _ := fs.Exists("invalid.txt"); exists {
+		t.Fatal("Expected the file with MD5 mismatch to not be stored")
+	}
+
+	// valid checksum and custom metadata
+	validMD5 := md5.Sum([]byte("test"))
+	err = fs.UploadFileWithOptions(file, "valid.txt", filesystem.UploadFileOptions{
+		PartSize: 1 << 20,
+		MD5:      validMD5[:],
+		Metadata: map[string]string{"custom": "abc"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	attrs, err := fs.Attributes("valid.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if attrs.Metadata["custom"] != "abc" || attrs.Metadata["original-filename"] != "test.txt" {
+		t.Fatalf("Expected custom and original-filename metadata, got %v", attrs.Metadata)
+	}
+	if attrs.Size != 4 {
+		t.Fatalf("Expected size 4, got %d", attrs.Size)
+	}
+}
+
+func TestFileSystemMultipartUploadsLocal(t *testing.T) {
+	dir := createTestDir(t)
+	defer os.RemoveAll(dir)
+
+	fs, err := filesystem.NewLocal(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer fs.Close()
+
+	uploads, err := fs.ListMultipartUploads("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(uploads) != 0 {
+		t.Fatalf("Expected no local multipart uploads, got %v", uploads)
+	}
+
+	if err := fs.AbortMultipartUpload(&filesystem.MultipartUpload{Key: "test", UploadId: "test"}); err != nil {
+		t.Fatalf("Expected no-op abort, got %v", err)
+	}
+}
+
 func TestFileSystemUpload(t *testing.T) {
 	dir := createTestDir(t)
 	defer os.RemoveAll(dir)
@@ -490,6 +562,62 @@ func TestFileSystemList(t *testing.T) {
 	}
 }
 
+func TestFileSystemServeETag(t *testing.T) {
+	dir := createTestDir(t)
+	defer os.RemoveAll(dir)
+
+	fs, err := filesystem.NewLocal(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer fs.Close()
+
+	if err := fs.Upload([]byte("test"), "etag_test.txt"); err != nil {
+		t.Fatal(err)
+	}
+
+	scenarios := []struct {
+		key          string
+		expectedETag string
+	}{
+		// uploaded file (with stored content md5)
+		{"etag_test.txt", `"098f6bcd4621d373cade4e832627b4f6"`},
+		// file without stored attributes
+		{"image.png", ""},
+	}
+
+	for _, s := range scenarios {
+		res := httptest.NewRecorder()
+		req := httptest.NewRequest("GET", "/", nil)
+
+		if err := fs.Serve(res, req, s.key, s.key); err != nil {
+			t.Fatal(err)
+		}
+
+		etag := res.Result().Header.Get("Etag")
+		if etag == "" || (s.expectedETag != "" && etag != s.expectedETag) {
+			t.Fatalf("[%s] Expected ETag %q, got %q", s.key, s.expectedETag, etag)
+		}
+
+		// conditional request
+		res = httptest.NewRecorder()
+		req = httptest.NewRequest("GET", "/", nil)
+		req.Header.Set("If-None-Match", etag)
+
+		if err := fs.Serve(res, req, s.key, s.key); err != nil {
+			t.Fatal(err)
+		}
+
+		if code := res.Result().StatusCode; code != http.StatusNotModified {
+			t.Fatalf("[%s] Expected StatusCode %d, got %d", s.key, http.StatusNotModified, code)
+		}
+
+		if res.Body.Len() != 0 {
+			t.Fatalf("[%s] Expected empty body, got %q", s.key, res.Body.String())
+		}
+	}
+}
+
 func TestFileSystemServeSingleRange(t *testing.T) {
 	dir := createTestDir(t)
 	defer os.RemoveAll(dir)
@@ -600,6 +728,40 @@ func TestFileSystemCreateThumb(t *testing.T) {
 	}
 }
 
+func TestFileSystemCreateThumbWithDifferentFormat(t *testing.T) {
+	dir := createTestDir(t)
+	defer os.RemoveAll(dir)
+
+	fs, err := filesystem.NewLocal(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer fs.Close()
+
+	if err := fs.CreateThumb("image.png", "thumb_file.jpg", "100x0"); err != nil {
+		t.Fatal(err)
+	}
+
+	attrs, err := fs.Attributes("thumb_file.jpg")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if attrs.ContentType != "image/jpeg" {
+		t.Fatalf("Expected image/jpeg content type, got %q", attrs.ContentType)
+	}
+
+	r, err := fs.GetFile("thumb_file.jpg")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+
+	if _, format, err := image.DecodeConfig(r); err != nil || format != "jpeg" {
+		t.Fatalf("Expected jpeg encoded thumb, got %q (%v)", format, err)
+	}
+}
+
 // ---
 
 func createTestDir(t *testing.T) string {
@@ -0,0 +1,183 @@
+package filesystem_test
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/unkod/space/tools/filesystem"
+)
+
+// newMockS3Server creates a minimal path-style S3 compatible server
+// that supports only the basic object PUT, GET, HEAD and DELETE operations.
+func newMockS3Server(bucket string) (*httptest.Server, *[]string) {
+	var mux sync.Mutex
+	objects := map[string][]byte{}
+	requests := []string{}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mux.Lock()
+		defer mux.Unlock()
+
+		requests = append(requests, r.Method+" "+r.Host+r.URL.Path)
+
+		prefix := "/" + bucket + "/"
+		if !strings.HasPrefix(r.URL.Path, prefix) {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		key := strings.TrimPrefix(r.URL.Path, prefix)
+
+		switch r.Method {
+		case http.MethodPut:
+			body, _ := io.ReadAll(r.Body)
+			objects[key] = body
+			w.Header().Set("ETag", `"test"`)
+		case http.MethodGet, http.MethodHead:
+			body, ok := objects[key]
+			if !ok {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			w.Header().Set("Content-Type", "text/plain")
+			w.Header().Set("Content-Length", strconv.Itoa(len(body)))
+			w.Header().Set("ETag", `"test"`)
+			w.Header().Set("Last-Modified", "Mon, 02 Jan 2006 15:04:05 GMT")
+			if r.Method == http.MethodGet {
+				w.Write(body)
+			}
+		case http.MethodDelete:
+			delete(objects, key)
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			w.WriteHeader(http.StatusMethodNotAllowed)
+		}
+	}))
+
+	return server, &requests
+}
+
+func TestNewS3CustomEndpointWithPathStyle(t *testing.T) {
+	server, requests := newMockS3Server("test_bucket")
+	defer server.Close()
+
+	fs, err := filesystem.NewS3("test_bucket", "test_region", server.URL, "test_key", "test_secret", true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer fs.Close()
+
+	if err := fs.Upload([]byte("test"), "a/b.txt"); err != nil {
+		t.Fatalf("Failed to upload: %v", err)
+	}
+
+	exists, err := fs.Exists("a/b.txt")
+	if err != nil || !exists {
+		t.Fatalf("Expected the uploaded file to exist, got %v (%v)", exists, err)
+	}
+
+	r, err := fs.GetFile("a/b.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	content, _ := io.ReadAll(r)
+	r.Close()
+
+	if string(content) != "test" {
+		t.Fatalf("Expected file content %q, got %q", "test", content)
+	}
+
+	if err := fs.Delete("a/b.txt"); err != nil {
+		t.Fatalf("Failed to delete: %v", err)
+	}
+
+	if exists, _ := fs.Exists("a/b.txt"); exists {
+		t.Fatal("Expected the file to be deleted")
+	}
+
+	// all requests should be sent to the custom endpoint host
+	// using the path-style bucket addressing
+	host := strings.TrimPrefix(server.URL, "http://")
+	for _, req := range *requests {
+		if !strings.Contains(req, " "+host+"/test_bucket/a/b.txt") {
+			t.Errorf("Expected a path-style request to %s, got %q", host, req)
+		}
+	}
+
+	if len(*requests) == 0 {
+		t.Fatal("Expected the mock S3 server to be called")
+	}
+}
+
+func TestS3MultipartUploads(t *testing.T) {
+	var mux sync.Mutex
+	aborted := []string{}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mux.Lock()
+		defer mux.Unlock()
+
+		switch {
+		case r.Method == http.MethodGet && r.URL.Query().Has("uploads"):
+			if r.URL.Query().Get("prefix") != "test_" {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			w.Header().Set("Content-Type", "application/xml")
+			w.Write([]byte(`<?xml version="1.0" encoding="UTF-8"?>
+				<ListMultipartUploadsResult xmlns="http://s3.amazonaws.com/doc/2006-03-01/">
+					<Bucket>test_bucket</Bucket>
+					<IsTruncated>false</IsTruncated>
+					<Upload>
+						<Key>test_a.zip</Key>
+						<UploadId>upload_a</UploadId>
+						<Initiated>2023-01-01T10:00:00.000Z</Initiated>
+					</Upload>
+					<Upload>
+						<Key>test_b.zip</Key>
+						<UploadId>upload_b</UploadId>
+						<Initiated>2023-01-02T10:00:00.000Z</Initiated>
+					</Upload>
+				</ListMultipartUploadsResult>
+			`))
+		case r.Method == http.MethodDelete && r.URL.Query().Has("uploadId"):
+			aborted = append(aborted, r.URL.Path+":"+r.URL.Query().Get("uploadId"))
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			w.WriteHeader(http.StatusMethodNotAllowed)
+		}
+	}))
+	defer server.Close()
+
+	fs, err := filesystem.NewS3("test_bucket", "test_region", server.URL, "test_key", "test_secret", true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer fs.Close()
+
+	uploads, err := fs.ListMultipartUploads("test_")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(uploads) != 2 {
+		t.Fatalf("Expected 2 pending uploads, got %d", len(uploads))
+	}
+
+	if uploads[0].Key != "test_a.zip" || uploads[0].UploadId != "upload_a" || uploads[0].Initiated.Day() != 1 {
+		t.Fatalf("Unexpected first upload %v", uploads[0])
+	}
+
+	if err := fs.AbortMultipartUpload(uploads[1]); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(aborted) != 1 || aborted[0] != "/test_bucket/test_b.zip:upload_b" {
+		t.Fatalf("Expected test_b.zip upload to be aborted, got %v", aborted)
+	}
+}
@@ -0,0 +1,58 @@
+package jsonschema
+
+import (
+	"net"
+	"net/mail"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+)
+
+var (
+	uuidRegex     = regexp.MustCompile(`^(?i)[0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12}$`)
+	hostnameRegex = regexp.MustCompile(`^(?i)[a-z0-9]([a-z0-9-]{0,61}[a-z0-9])?(\.[a-z0-9]([a-z0-9-]{0,61}[a-z0-9])?)*$`)
+)
+
+// formatCheckers contains the supported "format" keyword checks.
+var formatCheckers = map[string]func(v string) bool{
+	"email": func(v string) bool {
+		addr, err := mail.ParseAddress(v)
+		return err == nil && addr.Address == v
+	},
+	"uri": isAbsoluteUrl,
+	"url": isAbsoluteUrl,
+	"date": func(v string) bool {
+		_, err := time.Parse(time.DateOnly, v)
+		return err == nil
+	},
+	"date-time": func(v string) bool {
+		_, err := time.Parse(time.RFC3339, v)
+		return err == nil
+	},
+	"time": func(v string) bool {
+		_, err := time.Parse("15:04:05Z07:00", v)
+		return err == nil
+	},
+	"uuid": uuidRegex.MatchString,
+	"ipv4": func(v string) bool {
+		ip := net.ParseIP(v)
+		return ip != nil && ip.To4() != nil && !strings.Contains(v, ":")
+	},
+	"ipv6": func(v string) bool {
+		ip := net.ParseIP(v)
+		return ip != nil && strings.Contains(v, ":")
+	},
+	"hostname": func(v string) bool {
+		return len(v) <= 253 && hostnameRegex.MatchString(v)
+	},
+	"regex": func(v string) bool {
+		_, err := regexp.Compile(v)
+		return err == nil
+	},
+}
+
+func isAbsoluteUrl(v string) bool {
+	u, err := url.Parse(v)
+	return err == nil && u.Scheme != "" && (u.Host != "" || u.Opaque != "")
+}
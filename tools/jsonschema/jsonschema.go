@@ -0,0 +1,691 @@
+// Package jsonschema implements a minimal JSON Schema validator.
+//
+// Only the following (draft 2020-12) keywords are supported,
+// the rest are ignored:
+//
+//	type, enum, const,
+//	minLength, maxLength, pattern, format,
+//	minimum, maximum, exclusiveMinimum, exclusiveMaximum, multipleOf,
+//	items, minItems, maxItems, uniqueItems,
+//	properties, required, additionalProperties, minProperties, maxProperties, dependentRequired,
+//	allOf, anyOf, oneOf, not, if, then, else
+//
+// The supported "format" values are:
+//
+//	email, uri, url, date, date-time, time, uuid, ipv4, ipv6, hostname, regex
+package jsonschema
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/unkod/space/tools/list"
+)
+
+// Error defines a single schema validation error.
+type Error struct {
+	// Path is the JSON pointer (RFC 6901) of the invalid value (eg. "/address/city").
+	Path string
+
+	// Keyword is the name of the failed schema keyword (eg. "pattern").
+	Keyword string
+
+	// Message is a human readable description of the error.
+	Message string
+}
+
+// Error implements the [error] interface.
+func (e *Error) Error() string {
+	if e.Path == "" {
+		return e.Message
+	}
+
+	return e.Path + ": " + e.Message
+}
+
+// PathSegments returns the unescaped JSON pointer segments of the error path.
+func (e *Error) PathSegments() []string {
+	if e.Path == "" {
+		return nil
+	}
+
+	segments := strings.Split(strings.TrimPrefix(e.Path, "/"), "/")
+	for i, s := range segments {
+		segments[i] = strings.ReplaceAll(strings.ReplaceAll(s, "~1", "/"), "~0", "~")
+	}
+
+	return segments
+}
+
+// Errors defines a list of schema validation errors.
+type Errors []*Error
+
+// Error implements the [error] interface.
+func (e Errors) Error() string {
+	messages := make([]string, len(e))
+	for i, err := range e {
+		messages[i] = err.Error()
+	}
+
+	return strings.Join(messages, "; ")
+}
+
+// Schema defines a compiled JSON Schema document.
+type Schema struct {
+	root *node
+}
+
+// Compile parses and validates the provided JSON Schema document.
+//
+// The document could be either a raw JSON ([]byte, [json.RawMessage])
+// or any JSON serializable value (eg. map[string]any).
+func Compile(document any) (*Schema, error) {
+	normalized, err := normalize(document)
+	if err != nil {
+		return nil, err
+	}
+
+	root, err := compileNode(normalized, "")
+	if err != nil {
+		return nil, err
+	}
+
+	return &Schema{root: root}, nil
+}
+
+// Validate checks the provided value against the current schema.
+//
+// The value is normalized to its JSON representation before the
+// validation, meaning that types implementing [json.Marshaler] are
+// validated by their serialized value.
+//
+// Returns [Errors] on validation failure.
+func (s *Schema) Validate(value any) error {
+	normalized, err := normalize(value)
+	if err != nil {
+		return err
+	}
+
+	errs := Errors{}
+
+	s.root.validate(normalized, "", &errs)
+
+	if len(errs) > 0 {
+		return errs
+	}
+
+	return nil
+}
+
+// -------------------------------------------------------------------
+
+type node struct {
+	// boolean schema (true allows everything, false nothing)
+	always *bool
+
+	types      []string
+	enum       []any
+	hasConst   bool
+	constValue any
+
+	minLength *int
+	maxLength *int
+	pattern   *regexp.Regexp
+	format    string
+
+	minimum          *float64
+	maximum          *float64
+	exclusiveMinimum *float64
+	exclusiveMaximum *float64
+	multipleOf       *float64
+
+	items       *node
+	minItems    *int
+	maxItems    *int
+	uniqueItems bool
+
+	properties           map[string]*node
+	required             []string
+	additionalProperties *node
+	minProperties        *int
+	maxProperties        *int
+	dependentRequired    map[string][]string
+
+	allOf    []*node
+	anyOf    []*node
+	oneOf    []*node
+	not      *node
+	ifNode   *node
+	thenNode *node
+	elseNode *node
+}
+
+var knownTypes = []string{"null", "boolean", "object", "array", "number", "integer", "string"}
+
+func compileNode(raw any, location string) (*node, error) {
+	if b, ok := raw.(bool); ok {
+		return &node{always: &b}, nil
+	}
+
+	obj, ok := raw.(map[string]any)
+	if !ok {
+		return nil, compileError(location, "the schema must be an object or a boolean")
+	}
+
+	n := &node{}
+
+	var err error
+
+	// type
+	switch v := obj["type"].(type) {
+	case nil:
+	case string:
+		n.types = []string{v}
+	case []any:
+		for _, t := range v {
+			s, _ := t.(string)
+			n.types = append(n.types, s)
+		}
+	default:
+		return nil, compileError(location+"/type", "must be a string or an array of strings")
+	}
+	for _, t := range n.types {
+		if !list.ExistInSlice(t, knownTypes) {
+			return nil, compileError(location+"/type", fmt.Sprintf("unknown type %q", t))
+		}
+	}
+
+	// enum and const
+	if v, ok := obj["enum"]; ok {
+		if n.enum, ok = v.([]any); !ok {
+			return nil, compileError(location+"/enum", "must be an array")
+		}
+	}
+	if v, ok := obj["const"]; ok {
+		n.hasConst = true
+		n.constValue = v
+	}
+
+	// string
+	if n.minLength, err = intKeyword(obj, "minLength", location); err != nil {
+		return nil, err
+	}
+	if n.maxLength, err = intKeyword(obj, "maxLength", location); err != nil {
+		return nil, err
+	}
+	if v, ok := obj["pattern"]; ok {
+		pattern, _ := v.(string)
+		if n.pattern, err = regexp.Compile(pattern); err != nil {
+			return nil, compileError(location+"/pattern", err.Error())
+		}
+	}
+	if v, ok := obj["format"]; ok {
+		if n.format, ok = v.(string); !ok {
+			return nil, compileError(location+"/format", "must be a string")
+		}
+	}
+
+	// number
+	numberKeywords := map[string]**float64{
+		"minimum":          &n.minimum,
+		"maximum":          &n.maximum,
+		"exclusiveMinimum": &n.exclusiveMinimum,
+		"exclusiveMaximum": &n.exclusiveMaximum,
+		"multipleOf":       &n.multipleOf,
+	}
+	for name, target := range numberKeywords {
+		if v, ok := obj[name]; ok {
+			f, ok := v.(float64)
+			if !ok {
+				return nil, compileError(location+"/"+name, "must be a number")
+			}
+			*target = &f
+		}
+	}
+	if n.multipleOf != nil && *n.multipleOf <= 0 {
+		return nil, compileError(location+"/multipleOf", "must be greater than 0")
+	}
+
+	// array
+	if v, ok := obj["items"]; ok {
+		if n.items, err = compileNode(v, location+"/items"); err != nil {
+			return nil, err
+		}
+	}
+	if n.minItems, err = intKeyword(obj, "minItems", location); err != nil {
+		return nil, err
+	}
+	if n.maxItems, err = intKeyword(obj, "maxItems", location); err != nil {
+		return nil, err
+	}
+	n.uniqueItems, _ = obj["uniqueItems"].(bool)
+
+	// object
+	if v, ok := obj["properties"]; ok {
+		props, ok := v.(map[string]any)
+		if !ok {
+			return nil, compileError(location+"/properties", "must be an object")
+		}
+		n.properties = make(map[string]*node, len(props))
+		for name, prop := range props {
+			if n.properties[name], err = compileNode(prop, location+"/properties/"+escapeSegment(name)); err != nil {
+				return nil, err
+			}
+		}
+	}
+	if n.required, err = stringsKeyword(obj["required"], location+"/required"); err != nil {
+		return nil, err
+	}
+	if v, ok := obj["additionalProperties"]; ok {
+		if n.additionalProperties, err = compileNode(v, location+"/additionalProperties"); err != nil {
+			return nil, err
+		}
+	}
+	if n.minProperties, err = intKeyword(obj, "minProperties", location); err != nil {
+		return nil, err
+	}
+	if n.maxProperties, err = intKeyword(obj, "maxProperties", location); err != nil {
+		return nil, err
+	}
+	if v, ok := obj["dependentRequired"]; ok {
+		deps, ok := v.(map[string]any)
+		if !ok {
+			return nil, compileError(location+"/dependentRequired", "must be an object")
+		}
+		n.dependentRequired = make(map[string][]string, len(deps))
+		for name, dep := range deps {
+			if n.dependentRequired[name], err = stringsKeyword(dep, location+"/dependentRequired/"+escapeSegment(name)); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	// composition
+	listKeywords := map[string]*[]*node{
+		"allOf": &n.allOf,
+		"anyOf": &n.anyOf,
+		"oneOf": &n.oneOf,
+	}
+	for name, target := range listKeywords {
+		v, ok := obj[name]
+		if !ok {
+			continue
+		}
+		subs, ok := v.([]any)
+		if !ok || len(subs) == 0 {
+			return nil, compileError(location+"/"+name, "must be a non-empty array")
+		}
+		for i, item := range subs {
+			sub, err := compileNode(item, fmt.Sprintf("%s/%s/%d", location, name, i))
+			if err != nil {
+				return nil, err
+			}
+			*target = append(*target, sub)
+		}
+	}
+
+	singleKeywords := map[string]**node{
+		"not":  &n.not,
+		"if":   &n.ifNode,
+		"then": &n.thenNode,
+		"else": &n.elseNode,
+	}
+	for name, target := range singleKeywords {
+		if v, ok := obj[name]; ok {
+			if *target, err = compileNode(v, location+"/"+name); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return n, nil
+}
+
+func (n *node) validate(value any, path string, errs *Errors) {
+	if n.always != nil {
+		if !*n.always {
+			errs.add(path, "false", "The value is not allowed.")
+		}
+		return
+	}
+
+	if len(n.types) > 0 && !n.matchesType(value) {
+		errs.add(path, "type", fmt.Sprintf("Must be of type %s.", strings.Join(n.types, " or ")))
+		return // the rest of the keywords are type specific
+	}
+
+	if len(n.enum) > 0 {
+		var found bool
+		for _, item := range n.enum {
+			if reflect.DeepEqual(item, value) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			errs.add(path, "enum", "Must be one of the allowed values.")
+		}
+	}
+
+	if n.hasConst && !reflect.DeepEqual(n.constValue, value) {
+		errs.add(path, "const", "Must be equal to the allowed value.")
+	}
+
+	switch v := value.(type) {
+	case string:
+		n.validateString(v, path, errs)
+	case float64:
+		n.validateNumber(v, path, errs)
+	case []any:
+		n.validateArray(v, path, errs)
+	case map[string]any:
+		n.validateObject(v, path, errs)
+	}
+
+	for _, sub := range n.allOf {
+		sub.validate(value, path, errs)
+	}
+
+	if len(n.anyOf) > 0 {
+		var valid bool
+		for _, sub := range n.anyOf {
+			if sub.isValid(value) {
+				valid = true
+				break
+			}
+		}
+		if !valid {
+			errs.add(path, "anyOf", "Must match at least one of the allowed schemas.")
+		}
+	}
+
+	if len(n.oneOf) > 0 {
+		var total int
+		for _, sub := range n.oneOf {
+			if sub.isValid(value) {
+				total++
+			}
+		}
+		if total != 1 {
+			errs.add(path, "oneOf", "Must match exactly one of the allowed schemas.")
+		}
+	}
+
+	if n.not != nil && n.not.isValid(value) {
+		errs.add(path, "not", "Must not match the disallowed schema.")
+	}
+
+	if n.ifNode != nil {
+		if n.ifNode.isValid(value) {
+			if n.thenNode != nil {
+				n.thenNode.validate(value, path, errs)
+			}
+		} else if n.elseNode != nil {
+			n.elseNode.validate(value, path, errs)
+		}
+	}
+}
+
+func (n *node) isValid(value any) bool {
+	errs := Errors{}
+
+	n.validate(value, "", &errs)
+
+	return len(errs) == 0
+}
+
+func (n *node) matchesType(value any) bool {
+	for _, t := range n.types {
+		switch t {
+		case "null":
+			if value == nil {
+				return true
+			}
+		case "boolean":
+			if _, ok := value.(bool); ok {
+				return true
+			}
+		case "object":
+			if _, ok := value.(map[string]any); ok {
+				return true
+			}
+		case "array":
+			if _, ok := value.([]any); ok {
+				return true
+			}
+		case "number":
+			if _, ok := value.(float64); ok {
+				return true
+			}
+		case "integer":
+			if f, ok := value.(float64); ok && f == math.Trunc(f) {
+				return true
+			}
+		case "string":
+			if _, ok := value.(string); ok {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+func (n *node) validateString(v string, path string, errs *Errors) {
+	length := utf8.RuneCountInString(v)
+
+	if n.minLength != nil && length < *n.minLength {
+		errs.add(path, "minLength", fmt.Sprintf("Must be at least %d character(s).", *n.minLength))
+	}
+
+	if n.maxLength != nil && length > *n.maxLength {
+		errs.add(path, "maxLength", fmt.Sprintf("Must be no more than %d character(s).", *n.maxLength))
+	}
+
+	if n.pattern != nil && !n.pattern.MatchString(v) {
+		errs.add(path, "pattern", "Must match the required pattern.")
+	}
+
+	if n.format != "" {
+		if check, ok := formatCheckers[n.format]; ok && !check(v) {
+			errs.add(path, "format", fmt.Sprintf("Must be a valid %s.", n.format))
+		}
+	}
+}
+
+func (n *node) validateNumber(v float64, path string, errs *Errors) {
+	if n.minimum != nil && v < *n.minimum {
+		errs.add(path, "minimum", fmt.Sprintf("Must be no less than %v.", *n.minimum))
+	}
+
+	if n.maximum != nil && v > *n.maximum {
+		errs.add(path, "maximum", fmt.Sprintf("Must be no greater than %v.", *n.maximum))
+	}
+
+	if n.exclusiveMinimum != nil && v <= *n.exclusiveMinimum {
+		errs.add(path, "exclusiveMinimum", fmt.Sprintf("Must be greater than %v.", *n.exclusiveMinimum))
+	}
+
+	if n.exclusiveMaximum != nil && v >= *n.exclusiveMaximum {
+		errs.add(path, "exclusiveMaximum", fmt.Sprintf("Must be less than %v.", *n.exclusiveMaximum))
+	}
+
+	if n.multipleOf != nil {
+		quotient := v / *n.multipleOf
+		if math.IsInf(quotient, 0) || quotient != math.Trunc(quotient) {
+			errs.add(path, "multipleOf", fmt.Sprintf("Must be a multiple of %v.", *n.multipleOf))
+		}
+	}
+}
+
+func (n *node) validateArray(v []any, path string, errs *Errors) {
+	if n.minItems != nil && len(v) < *n.minItems {
+		errs.add(path, "minItems", fmt.Sprintf("Must have at least %d item(s).", *n.minItems))
+	}
+
+	if n.maxItems != nil && len(v) > *n.maxItems {
+		errs.add(path, "maxItems", fmt.Sprintf("Must have no more than %d item(s).", *n.maxItems))
+	}
+
+	if n.uniqueItems {
+	outer:
+		for i := 0; i < len(v); i++ {
+			for j := i + 1; j < len(v); j++ {
+				if reflect.DeepEqual(v[i], v[j]) {
+					errs.add(path, "uniqueItems", "Must have only unique items.")
+					break outer
+				}
+			}
+		}
+	}
+
+	if n.items != nil {
+		for i, item := range v {
+			n.items.validate(item, fmt.Sprintf("%s/%d", path, i), errs)
+		}
+	}
+}
+
+func (n *node) validateObject(v map[string]any, path string, errs *Errors) {
+	if n.minProperties != nil && len(v) < *n.minProperties {
+		errs.add(path, "minProperties", fmt.Sprintf("Must have at least %d properties.", *n.minProperties))
+	}
+
+	if n.maxProperties != nil && len(v) > *n.maxProperties {
+		errs.add(path, "maxProperties", fmt.Sprintf("Must have no more than %d properties.", *n.maxProperties))
+	}
+
+	// the missing required properties are reported
+	// at the property path for easier errors association
+	for _, name := range n.required {
+		if _, ok := v[name]; !ok {
+			errs.add(path+"/"+escapeSegment(name), "required", "Missing required value.")
+		}
+	}
+
+	for _, name := range sortedKeys(n.dependentRequired) {
+		if _, ok := v[name]; !ok {
+			continue
+		}
+		for _, dep := range n.dependentRequired[name] {
+			if _, ok := v[dep]; !ok {
+				errs.add(path+"/"+escapeSegment(dep), "dependentRequired", fmt.Sprintf("Missing required value (required by %q).", name))
+			}
+		}
+	}
+
+	for _, name := range sortedKeys(v) {
+		propPath := path + "/" + escapeSegment(name)
+
+		if prop, ok := n.properties[name]; ok {
+			prop.validate(v[name], propPath, errs)
+		} else if n.additionalProperties != nil {
+			if n.additionalProperties.always != nil && !*n.additionalProperties.always {
+				errs.add(propPath, "additionalProperties", "Unknown property.")
+			} else {
+				n.additionalProperties.validate(v[name], propPath, errs)
+			}
+		}
+	}
+}
+
+// -------------------------------------------------------------------
+
+func (errs *Errors) add(path string, keyword string, message string) {
+	*errs = append(*errs, &Error{Path: path, Keyword: keyword, Message: message})
+}
+
+func compileError(location string, message string) error {
+	if location == "" {
+		location = "/"
+	}
+
+	return fmt.Errorf("invalid schema at %s: %s", location, message)
+}
+
+func intKeyword(obj map[string]any, name string, location string) (*int, error) {
+	v, ok := obj[name]
+	if !ok {
+		return nil, nil
+	}
+
+	f, ok := v.(float64)
+	if !ok || f < 0 || f != math.Trunc(f) {
+		return nil, compileError(location+"/"+name, "must be a non-negative integer")
+	}
+
+	result := int(f)
+
+	return &result, nil
+}
+
+func stringsKeyword(raw any, location string) ([]string, error) {
+	if raw == nil {
+		return nil, nil
+	}
+
+	items, ok := raw.([]any)
+	if !ok {
+		return nil, compileError(location, "must be an array of strings")
+	}
+
+	result := make([]string, 0, len(items))
+	for _, item := range items {
+		s, ok := item.(string)
+		if !ok {
+			return nil, compileError(location, "must be an array of strings")
+		}
+		result = append(result, s)
+	}
+
+	return result, nil
+}
+
+func normalize(value any) (any, error) {
+	var raw []byte
+
+	switch v := value.(type) {
+	case []byte:
+		raw = v
+	case json.RawMessage:
+		raw = v
+	default:
+		var err error
+		if raw, err = json.Marshal(v); err != nil {
+			return nil, err
+		}
+	}
+
+	if len(raw) == 0 {
+		return nil, errors.New("empty JSON document")
+	}
+
+	var result any
+	if err := json.Unmarshal(raw, &result); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+func escapeSegment(s string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(s, "~", "~0"), "/", "~1")
+}
+
+func sortedKeys[T any](m map[string]T) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+
+	sort.Strings(keys)
+
+	return keys
+}
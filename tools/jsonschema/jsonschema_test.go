@@ -0,0 +1,314 @@
+package jsonschema_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/unkod/space/tools/jsonschema"
+)
+
+func TestCompile(t *testing.T) {
+	scenarios := []struct {
+		name        string
+		document    any
+		expectError bool
+	}{
+		{"nil", nil, true},
+		{"empty raw", []byte{}, true},
+		{"invalid raw", []byte(`{`), true},
+		{"non-object", `"test"`, true},
+		{"unknown type", `{"type":"unknown"}`, true},
+		{"invalid pattern", `{"pattern":"(test"}`, true},
+		{"invalid minLength", `{"minLength":-1}`, true},
+		{"invalid multipleOf", `{"multipleOf":0}`, true},
+		{"invalid required", `{"required":[1]}`, true},
+		{"empty anyOf", `{"anyOf":[]}`, true},
+		{"invalid nested schema", `{"properties":{"a":{"type":123}}}`, true},
+		{"boolean schema", []byte(`true`), false},
+		{"empty schema", []byte(`{}`), false},
+		{"map schema", map[string]any{"type": "object", "required": []string{"a"}}, false},
+		{"unknown keywords", []byte(`{"$id":"test","title":"test","format":"unknown"}`), false},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			document := s.document
+			if str, ok := document.(string); ok {
+				document = []byte(str)
+			}
+
+			_, err := jsonschema.Compile(document)
+
+			hasErr := err != nil
+			if hasErr != s.expectError {
+				t.Fatalf("Expected hasErr %v, got %v (%v)", s.expectError, hasErr, err)
+			}
+		})
+	}
+}
+
+func TestSchemaValidate(t *testing.T) {
+	scenarios := []struct {
+		name           string
+		schema         string
+		value          any
+		expectedErrors []string // "path keyword" pairs
+	}{
+		{
+			"false schema",
+			`false`,
+			"test",
+			[]string{" false"},
+		},
+		{
+			"type mismatch",
+			`{"type":["string","null"]}`,
+			123,
+			[]string{" type"},
+		},
+		{
+			"integer type",
+			`{"type":"integer"}`,
+			1.5,
+			[]string{" type"},
+		},
+		{
+			"enum and const",
+			`{"properties":{"a":{"enum":["x","y"]},"b":{"const":1}}}`,
+			map[string]any{"a": "z", "b": 2},
+			[]string{"/a enum", "/b const"},
+		},
+		{
+			"string constraints",
+			`{"properties":{"a":{"minLength":3},"b":{"maxLength":2},"c":{"pattern":"^[a-z]+$"}}}`,
+			map[string]any{"a": "ab", "b": "abc", "c": "ABC"},
+			[]string{"/a minLength", "/b maxLength", "/c pattern"},
+		},
+		{
+			"valid string constraints",
+			`{"properties":{"a":{"minLength":3},"b":{"maxLength":2},"c":{"pattern":"^[a-z]+$"}}}`,
+			map[string]any{"a": "abc", "b": "ab", "c": "abc"},
+			nil,
+		},
+		{
+			"invalid formats",
+			`{"properties":{
+				"email":{"format":"email"},
+				"url":{"format":"uri"},
+				"date":{"format":"date"},
+				"datetime":{"format":"date-time"},
+				"uuid":{"format":"uuid"},
+				"ipv4":{"format":"ipv4"},
+				"ipv6":{"format":"ipv6"},
+				"hostname":{"format":"hostname"}
+			}}`,
+			map[string]any{
+				"email":    "test",
+				"url":      "example.com",
+				"date":     "2023-13-01",
+				"datetime": "2023-01-01 10:00:00",
+				"uuid":     "123",
+				"ipv4":     "::1",
+				"ipv6":     "127.0.0.1",
+				"hostname": "-example.com",
+			},
+			[]string{
+				"/date format",
+				"/datetime format",
+				"/email format",
+				"/hostname format",
+				"/ipv4 format",
+				"/ipv6 format",
+				"/url format",
+				"/uuid format",
+			},
+		},
+		{
+			"valid formats",
+			`{"properties":{
+				"email":{"format":"email"},
+				"url":{"format":"uri"},
+				"date":{"format":"date"},
+				"datetime":{"format":"date-time"},
+				"uuid":{"format":"uuid"},
+				"ipv4":{"format":"ipv4"},
+				"ipv6":{"format":"ipv6"},
+				"hostname":{"format":"hostname"},
+				"unknown":{"format":"unknown"}
+			}}`,
+			map[string]any{
+				"email":    "test@example.com",
+				"url":      "https://example.com/test",
+				"date":     "2023-01-01",
+				"datetime": "2023-01-01T10:00:00Z",
+				"uuid":     "123e4567-e89b-12d3-a456-426614174000",
+				"ipv4":     "127.0.0.1",
+				"ipv6":     "::1",
+				"hostname": "sub.example.com",
+				"unknown":  "test",
+			},
+			nil,
+		},
+		{
+			"format check is applied only to strings",
+			`{"format":"email"}`,
+			123,
+			nil,
+		},
+		{
+			"number constraints",
+			`{"properties":{
+				"a":{"minimum":5},
+				"b":{"maximum":5},
+				"c":{"exclusiveMinimum":5},
+				"d":{"exclusiveMaximum":5},
+				"e":{"multipleOf":0.5}
+			}}`,
+			map[string]any{"a": 4, "b": 6, "c": 5, "d": 5, "e": 1.2},
+			[]string{"/a minimum", "/b maximum", "/c exclusiveMinimum", "/d exclusiveMaximum", "/e multipleOf"},
+		},
+		{
+			"array constraints",
+			`{"properties":{
+				"a":{"minItems":2},
+				"b":{"maxItems":1},
+				"c":{"uniqueItems":true},
+				"d":{"items":{"type":"string","minLength":2}}
+			}}`,
+			map[string]any{
+				"a": []any{1},
+				"b": []any{1, 2},
+				"c": []any{1, 2, 1},
+				"d": []any{"ab", "a", 1},
+			},
+			[]string{"/a minItems", "/b maxItems", "/c uniqueItems", "/d/1 minLength", "/d/2 type"},
+		},
+		{
+			"object constraints",
+			`{
+				"required":["a","b"],
+				"minProperties":3,
+				"additionalProperties":false,
+				"properties":{"a":{},"b":{},"c~/d":{}},
+				"dependentRequired":{"a":["c~/d"]}
+			}`,
+			map[string]any{"a": 1, "e": 2},
+			[]string{" minProperties", "/b required", "/c~0~1d dependentRequired", "/e additionalProperties"},
+		},
+		{
+			"additionalProperties schema",
+			`{"properties":{"a":{}},"additionalProperties":{"type":"number"}}`,
+			map[string]any{"a": "test", "b": 1, "c": "test"},
+			[]string{"/c type"},
+		},
+		{
+			"allOf",
+			`{"allOf":[{"properties":{"a":{"type":"string"}}},{"properties":{"a":{"minLength":3}}}]}`,
+			map[string]any{"a": "ab"},
+			[]string{"/a minLength"},
+		},
+		{
+			"anyOf",
+			`{"anyOf":[{"type":"string"},{"type":"number"}]}`,
+			true,
+			[]string{" anyOf"},
+		},
+		{
+			"oneOf",
+			`{"oneOf":[{"type":"number"},{"minimum":0}]}`,
+			1,
+			[]string{" oneOf"},
+		},
+		{
+			"not",
+			`{"not":{"type":"string"}}`,
+			"test",
+			[]string{" not"},
+		},
+		{
+			"if then (conditional required)",
+			`{
+				"if":{"properties":{"type":{"const":"company"}},"required":["type"]},
+				"then":{"required":["vat"]},
+				"else":{"properties":{"vat":false}}
+			}`,
+			map[string]any{"type": "company"},
+			[]string{"/vat required"},
+		},
+		{
+			"if else",
+			`{
+				"if":{"properties":{"type":{"const":"company"}},"required":["type"]},
+				"then":{"required":["vat"]},
+				"else":{"properties":{"vat":false}}
+			}`,
+			map[string]any{"type": "person", "vat": "123"},
+			[]string{"/vat false"},
+		},
+		{
+			"json serializable value",
+			`{"properties":{"a":{"type":"string","format":"date-time"}}}`,
+			struct {
+				A string `json:"a"`
+			}{"2023-01-01T10:00:00Z"},
+			nil,
+		},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			schema, err := jsonschema.Compile([]byte(s.schema))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			expected := s.expectedErrors
+
+			validateErr := schema.Validate(s.value)
+
+			if len(expected) == 0 {
+				if validateErr != nil {
+					t.Fatalf("Expected no errors, got %v", validateErr)
+				}
+				return
+			}
+
+			var errs jsonschema.Errors
+			if !errors.As(validateErr, &errs) {
+				t.Fatalf("Expected jsonschema.Errors, got %v", validateErr)
+			}
+
+			result := make([]string, len(errs))
+			for i, e := range errs {
+				result[i] = e.Path + " " + e.Keyword
+			}
+
+			if strings.Join(result, ",") != strings.Join(expected, ",") {
+				t.Fatalf("Expected errors\n%v\ngot\n%v", expected, result)
+			}
+		})
+	}
+}
+
+func TestErrorPathSegments(t *testing.T) {
+	scenarios := []struct {
+		path     string
+		expected string
+	}{
+		{"", ""},
+		{"/a", "a"},
+		{"/a/0/b", "a|0|b"},
+		{"/a~1b/c~0d", "a/b|c~d"},
+	}
+
+	for _, s := range scenarios {
+		e := &jsonschema.Error{Path: s.path}
+
+		result := strings.Join(e.PathSegments(), "|")
+
+		if result != s.expected {
+			t.Errorf("(%q) Expected %q, got %q", s.path, s.expected, result)
+		}
+	}
+}
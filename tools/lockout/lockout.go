@@ -0,0 +1,147 @@
+// Package lockout implements a simple failed attempts tracker
+// that temporarily locks a key after too many failures.
+package lockout
+
+import (
+	"sync"
+	"time"
+)
+
+// Store defines a failed attempts counters storage.
+//
+// The default implementation is the in-memory [MemoryStore] but
+// it could be replaced with a persistent one (eg. Redis or db backed)
+// in order to preserve the lockouts between app restarts.
+type Store interface {
+	// Check returns the remaining lock duration of the key
+	// (it is 0 if the key is not locked).
+	Check(key string) (time.Duration, error)
+
+	// Fail registers a single failed attempt for the key.
+	//
+	// The key is locked for the specified `duration` once it reaches
+	// `maxAttempts` failed attempts within the `window` duration.
+	//
+	// lockedFor is the remaining lock duration of the key
+	// (it is 0 if the key is not locked).
+	Fail(key string, maxAttempts int, window time.Duration, duration time.Duration) (lockedFor time.Duration, err error)
+
+	// Reset clears the failed attempts and the lock of the key.
+	Reset(key string) error
+}
+
+// ensures that the MemoryStore implements the Store interface.
+var _ Store = (*MemoryStore)(nil)
+
+type entry struct {
+	attempts    int
+	windowEnd   time.Time
+	lockedUntil time.Time
+}
+
+// MemoryStore is a concurrent safe in-memory Store implementation.
+type MemoryStore struct {
+	mux         sync.Mutex
+	entries     map[string]*entry
+	lastCleanup time.Time
+
+	// now is the time source (used mostly for the tests).
+	now func() time.Time
+}
+
+// NewMemoryStore creates a new empty in-memory store.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		entries: map[string]*entry{},
+		now:     time.Now,
+	}
+}
+
+// cleanupInterval specifies how often to prune the expired entries.
+const cleanupInterval = time.Minute
+
+// Check implements [Store.Check].
+func (s *MemoryStore) Check(key string) (time.Duration, error) {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+
+	now := s.now()
+
+	e, ok := s.entries[key]
+	if !ok || !now.Before(e.lockedUntil) {
+		return 0, nil
+	}
+
+	return e.lockedUntil.Sub(now), nil
+}
+
+// Fail implements [Store.Fail].
+func (s *MemoryStore) Fail(key string, maxAttempts int, window time.Duration, duration time.Duration) (time.Duration, error) {
+	if maxAttempts <= 0 || window <= 0 || duration <= 0 {
+		return 0, nil // no lockout
+	}
+
+	s.mux.Lock()
+	defer s.mux.Unlock()
+
+	now := s.now()
+
+	s.cleanup(now)
+
+	e, ok := s.entries[key]
+	if !ok {
+		e = &entry{}
+		s.entries[key] = e
+	}
+
+	// already locked
+	if now.Before(e.lockedUntil) {
+		return e.lockedUntil.Sub(now), nil
+	}
+
+	// start a new window
+	if e.attempts == 0 || !now.Before(e.windowEnd) {
+		e.attempts = 0
+		e.windowEnd = now.Add(window)
+	}
+
+	e.attempts++
+
+	if e.attempts < maxAttempts {
+		return 0, nil
+	}
+
+	// lock and reset the counter for the next window after the lock expires
+	e.attempts = 0
+	e.lockedUntil = now.Add(duration)
+
+	return duration, nil
+}
+
+// Reset implements [Store.Reset].
+func (s *MemoryStore) Reset(key string) error {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+
+	delete(s.entries, key)
+
+	return nil
+}
+
+// cleanup removes the entries with expired window and lock since they
+// are equivalent to a new entry.
+//
+// It must be called while holding the store lock.
+func (s *MemoryStore) cleanup(now time.Time) {
+	if now.Sub(s.lastCleanup) < cleanupInterval {
+		return
+	}
+
+	s.lastCleanup = now
+
+	for k, e := range s.entries {
+		if !now.Before(e.windowEnd) && !now.Before(e.lockedUntil) {
+			delete(s.entries, k)
+		}
+	}
+}
@@ -0,0 +1,142 @@
+package lockout
+
+import (
+	"testing"
+	"time"
+)
+
+func TestMemoryStoreFail(t *testing.T) {
+	now := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	s := NewMemoryStore()
+	s.now = func() time.Time { return now }
+
+	// no lockout
+	for i := 0; i < 10; i++ {
+		if lockedFor, _ := s.Fail("test", 0, time.Minute, time.Minute); lockedFor != 0 {
+			t.Fatalf("(%d) Expected zero max attempts to never lock", i)
+		}
+	}
+
+	// failures below the threshold
+	for i := 0; i < 2; i++ {
+		lockedFor, err := s.Fail("a", 3, time.Minute, 5*time.Minute)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if lockedFor != 0 {
+			t.Fatalf("(%d) Expected the key to not be locked, got %v", i, lockedFor)
+		}
+	}
+
+	if lockedFor, _ := s.Check("a"); lockedFor != 0 {
+		t.Fatalf("Expected the key to not be locked yet, got %v", lockedFor)
+	}
+
+	// reach the threshold
+	if lockedFor, _ := s.Fail("a", 3, time.Minute, 5*time.Minute); lockedFor != 5*time.Minute {
+		t.Fatalf("Expected the key to be locked for %v, got %v", 5*time.Minute, lockedFor)
+	}
+
+	// other keys should have their own counters
+	if lockedFor, _ := s.Check("b"); lockedFor != 0 {
+		t.Fatalf("Expected the other key to not be locked, got %v", lockedFor)
+	}
+
+	// failures while locked shouldn't extend the lock
+	now = now.Add(time.Minute)
+	if lockedFor, _ := s.Fail("a", 3, time.Minute, 5*time.Minute); lockedFor != 4*time.Minute {
+		t.Fatalf("Expected the remaining lock duration %v, got %v", 4*time.Minute, lockedFor)
+	}
+	if lockedFor, _ := s.Check("a"); lockedFor != 4*time.Minute {
+		t.Fatalf("Expected the remaining lock duration %v, got %v", 4*time.Minute, lockedFor)
+	}
+
+	// expired lock
+	now = now.Add(4 * time.Minute)
+	if lockedFor, _ := s.Check("a"); lockedFor != 0 {
+		t.Fatalf("Expected the lock to be expired, got %v", lockedFor)
+	}
+	if lockedFor, _ := s.Fail("a", 3, time.Minute, 5*time.Minute); lockedFor != 0 {
+		t.Fatalf("Expected a new counter window after the lock expiration, got %v", lockedFor)
+	}
+
+	// expired window
+	s.Fail("c", 2, time.Minute, time.Hour)
+	now = now.Add(time.Minute)
+	if lockedFor, _ := s.Fail("c", 2, time.Minute, time.Hour); lockedFor != 0 {
+		t.Fatalf("Expected the failed attempts of the expired window to be ignored, got %v", lockedFor)
+	}
+	if lockedFor, _ := s.Fail("c", 2, time.Minute, time.Hour); lockedFor != time.Hour {
+		t.Fatalf("Expected the key to be locked for %v, got %v", time.Hour, lockedFor)
+	}
+}
+
+func TestMemoryStoreReset(t *testing.T) {
+	s := NewMemoryStore()
+
+	s.Fail("a", 2, time.Minute, time.Minute)
+	s.Fail("a", 2, time.Minute, time.Minute)
+	s.Fail("b", 2, time.Minute, time.Minute)
+
+	if lockedFor, _ := s.Check("a"); lockedFor == 0 {
+		t.Fatal("Expected the key to be locked")
+	}
+
+	if err := s.Reset("a"); err != nil {
+		t.Fatal(err)
+	}
+
+	if lockedFor, _ := s.Check("a"); lockedFor != 0 {
+		t.Fatalf("Expected the lock to be cleared, got %v", lockedFor)
+	}
+
+	if _, ok := s.entries["b"]; !ok {
+		t.Fatal("Expected the other key entry to remain")
+	}
+
+	// the failed attempts should be also cleared
+	if lockedFor, _ := s.Fail("b", 2, time.Minute, time.Minute); lockedFor == 0 {
+		t.Fatal("Expected the non-reset key to be locked")
+	}
+	s.Reset("b")
+	if lockedFor, _ := s.Fail("b", 2, time.Minute, time.Minute); lockedFor != 0 {
+		t.Fatalf("Expected the failed attempts to be cleared, got %v", lockedFor)
+	}
+}
+
+func TestMemoryStoreCleanup(t *testing.T) {
+	now := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	s := NewMemoryStore()
+	s.now = func() time.Time { return now }
+
+	s.Fail("a", 5, time.Second, time.Second)
+	s.Fail("b", 5, time.Hour, time.Second)
+	s.Fail("c", 1, time.Second, time.Hour)
+
+	if total := len(s.entries); total != 3 {
+		t.Fatalf("Expected 3 entries, got %d", total)
+	}
+
+	now = now.Add(cleanupInterval)
+
+	// trigger cleanup
+	s.Fail("d", 5, time.Second, time.Second)
+
+	if _, ok := s.entries["a"]; ok {
+		t.Fatal("Expected the expired entry a to be removed")
+	}
+
+	if _, ok := s.entries["b"]; !ok {
+		t.Fatal("Expected entry b with active window to remain")
+	}
+
+	if _, ok := s.entries["c"]; !ok {
+		t.Fatal("Expected the locked entry c to remain")
+	}
+
+	if _, ok := s.entries["d"]; !ok {
+		t.Fatal("Expected entry d to be created")
+	}
+}
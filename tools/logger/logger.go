@@ -0,0 +1,44 @@
+// Package logger implements the app structured logger helpers.
+package logger
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"strings"
+)
+
+// Supported logger output formats.
+const (
+	FormatText = "text"
+	FormatJSON = "json"
+)
+
+// New creates a new structured logger that writes to w the records
+// with level equal or higher than the specified one.
+//
+// format could be [FormatText] (the default if empty) or [FormatJSON].
+func New(w io.Writer, format string, level slog.Leveler) (*slog.Logger, error) {
+	opts := &slog.HandlerOptions{Level: level}
+
+	switch strings.ToLower(format) {
+	case "", FormatText:
+		return slog.New(slog.NewTextHandler(w, opts)), nil
+	case FormatJSON:
+		return slog.New(slog.NewJSONHandler(w, opts)), nil
+	default:
+		return nil, fmt.Errorf("unsupported log format %q", format)
+	}
+}
+
+// ParseLevel parses a case insensitive log level name
+// ("debug", "info", "warn" or "error").
+func ParseLevel(level string) (slog.Level, error) {
+	var result slog.Level
+
+	if err := result.UnmarshalText([]byte(level)); err != nil {
+		return result, fmt.Errorf("unsupported log level %q", level)
+	}
+
+	return result, nil
+}
@@ -0,0 +1,122 @@
+package logger_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/unkod/space/tools/logger"
+)
+
+func TestNew(t *testing.T) {
+	scenarios := []struct {
+		format      string
+		expectError bool
+	}{
+		{"", false},
+		{"text", false},
+		{"TEXT", false},
+		{"json", false},
+		{"xml", true},
+	}
+
+	for i, s := range scenarios {
+		l, err := logger.New(&bytes.Buffer{}, s.format, slog.LevelInfo)
+
+		hasErr := err != nil
+		if hasErr != s.expectError {
+			t.Errorf("(%d) Expected hasErr %v, got %v (%v)", i, s.expectError, hasErr, err)
+		}
+
+		if !hasErr && l == nil {
+			t.Errorf("(%d) Expected non-nil logger", i)
+		}
+	}
+}
+
+func TestNewText(t *testing.T) {
+	buf := &bytes.Buffer{}
+
+	l, err := logger.New(buf, logger.FormatText, slog.LevelInfo)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	l.Debug("debug message")
+	l.Info("info message", "status", 200)
+
+	out := buf.String()
+
+	if strings.Contains(out, "debug message") {
+		t.Fatalf("Didn't expect the debug message to be logged, got\n%s", out)
+	}
+
+	if !strings.Contains(out, `level=INFO msg="info message" status=200`) {
+		t.Fatalf("Expected the info message to be logged as text, got\n%s", out)
+	}
+}
+
+func TestNewJSON(t *testing.T) {
+	buf := &bytes.Buffer{}
+
+	l, err := logger.New(buf, logger.FormatJSON, slog.LevelWarn)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	l.Info("info message")
+	l.Warn("warn message", "method", "GET", "status", 404)
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 1 {
+		t.Fatalf("Expected 1 log line, got %d\n%s", len(lines), buf.String())
+	}
+
+	data := map[string]any{}
+	if err := json.Unmarshal([]byte(lines[0]), &data); err != nil {
+		t.Fatalf("Expected valid json log line, got %v", err)
+	}
+
+	expected := map[string]any{
+		"level":  "WARN",
+		"msg":    "warn message",
+		"method": "GET",
+		"status": float64(404),
+	}
+	for k, v := range expected {
+		if data[k] != v {
+			t.Errorf("Expected %q to be %v, got %v", k, v, data[k])
+		}
+	}
+}
+
+func TestParseLevel(t *testing.T) {
+	scenarios := []struct {
+		level       string
+		expected    slog.Level
+		expectError bool
+	}{
+		{"", slog.LevelInfo, true},
+		{"missing", slog.LevelInfo, true},
+		{"debug", slog.LevelDebug, false},
+		{"INFO", slog.LevelInfo, false},
+		{"warn", slog.LevelWarn, false},
+		{"error", slog.LevelError, false},
+	}
+
+	for _, s := range scenarios {
+		result, err := logger.ParseLevel(s.level)
+
+		hasErr := err != nil
+		if hasErr != s.expectError {
+			t.Errorf("[%s] Expected hasErr %v, got %v (%v)", s.level, s.expectError, hasErr, err)
+			continue
+		}
+
+		if !hasErr && result != s.expected {
+			t.Errorf("[%s] Expected level %v, got %v", s.level, s.expected, result)
+		}
+	}
+}
@@ -0,0 +1,109 @@
+package mailer
+
+import (
+	"log/slog"
+	"net/mail"
+	"strings"
+	"sync"
+)
+
+var _ Mailer = (*CaptureMailer)(nil)
+
+// CaptureMailer is a [Mailer] implementation that doesn't send
+// the messages but stores them in memory (and optionally logs them)
+// so that they could be inspected later (eg. in integration tests).
+//
+// It is safe for concurrent use.
+type CaptureMailer struct {
+	// Logger is an optional logger used to log the sent messages.
+	Logger *slog.Logger
+
+	mux      sync.RWMutex
+	messages []Message
+}
+
+// Send implements [Mailer.Send] interface.
+func (m *CaptureMailer) Send(message *Message) error {
+	m.mux.Lock()
+	m.messages = append(m.messages, *message)
+	m.mux.Unlock()
+
+	if m.Logger != nil {
+		m.Logger.Info(
+			"Mail message sent",
+			slog.Any("to", addressesToStrings(message.To, true)),
+			slog.String("subject", message.Subject),
+		)
+	}
+
+	return nil
+}
+
+// Messages returns a copy of all captured messages (in the order of sending).
+func (m *CaptureMailer) Messages() []Message {
+	m.mux.RLock()
+	defer m.mux.RUnlock()
+
+	return append([]Message{}, m.messages...)
+}
+
+// TotalSend returns the total number of captured messages.
+func (m *CaptureMailer) TotalSend() int {
+	m.mux.RLock()
+	defer m.mux.RUnlock()
+
+	return len(m.messages)
+}
+
+// LastMessage returns the last captured message
+// (or nil if no messages were sent).
+func (m *CaptureMailer) LastMessage() *Message {
+	m.mux.RLock()
+	defer m.mux.RUnlock()
+
+	if len(m.messages) == 0 {
+		return nil
+	}
+
+	msg := m.messages[len(m.messages)-1]
+
+	return &msg
+}
+
+// SentTo returns the captured messages sent to the specified email address
+// (the To, Cc and Bcc addresses are checked).
+func (m *CaptureMailer) SentTo(email string) []Message {
+	m.mux.RLock()
+	defer m.mux.RUnlock()
+
+	result := []Message{}
+
+	for _, msg := range m.messages {
+		for _, addresses := range [][]mail.Address{msg.To, msg.Cc, msg.Bcc} {
+			if containsAddress(addresses, email) {
+				result = append(result, msg)
+				break
+			}
+		}
+	}
+
+	return result
+}
+
+// Reset clears all captured messages.
+func (m *CaptureMailer) Reset() {
+	m.mux.Lock()
+	defer m.mux.Unlock()
+
+	m.messages = nil
+}
+
+func containsAddress(addresses []mail.Address, email string) bool {
+	for _, addr := range addresses {
+		if strings.EqualFold(addr.Address, email) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,72 @@
+package mailer
+
+import (
+	"net/mail"
+	"testing"
+)
+
+func TestNoopMailerSend(t *testing.T) {
+	m := &NoopMailer{}
+
+	if err := m.Send(&Message{Subject: "test"}); err != nil {
+		t.Fatalf("Expected nil error, got %v", err)
+	}
+}
+
+func TestCaptureMailer(t *testing.T) {
+	m := &CaptureMailer{}
+
+	if m.LastMessage() != nil {
+		t.Fatal("Expected nil last message")
+	}
+
+	messages := []*Message{
+		{Subject: "a", To: []mail.Address{{Address: "test1@example.com"}}},
+		{Subject: "b", To: []mail.Address{{Address: "test2@example.com"}}, Cc: []mail.Address{{Address: "TEST1@example.com"}}},
+		{Subject: "c", Bcc: []mail.Address{{Address: "test3@example.com"}}},
+	}
+
+	for _, msg := range messages {
+		if err := m.Send(msg); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if total := m.TotalSend(); total != 3 {
+		t.Fatalf("Expected 3 messages, got %d", total)
+	}
+
+	if last := m.LastMessage(); last == nil || last.Subject != "c" {
+		t.Fatalf("Expected last message c, got %v", last)
+	}
+
+	sentTo := []struct {
+		email    string
+		expected []string
+	}{
+		{"missing@example.com", nil},
+		{"test1@example.com", []string{"a", "b"}},
+		{"test3@example.com", []string{"c"}},
+	}
+
+	for _, s := range sentTo {
+		result := m.SentTo(s.email)
+
+		if len(result) != len(s.expected) {
+			t.Errorf("[%s] Expected %d messages, got %d", s.email, len(s.expected), len(result))
+			continue
+		}
+
+		for i, subject := range s.expected {
+			if result[i].Subject != subject {
+				t.Errorf("[%s] Expected message %d subject %q, got %q", s.email, i, subject, result[i].Subject)
+			}
+		}
+	}
+
+	m.Reset()
+
+	if total := m.TotalSend(); total != 0 {
+		t.Fatalf("Expected 0 messages after reset, got %d", total)
+	}
+}
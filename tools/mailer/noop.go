@@ -0,0 +1,12 @@
+package mailer
+
+var _ Mailer = (*NoopMailer)(nil)
+
+// NoopMailer is a [Mailer] implementation that silently
+// discards all messages (eg. for local development).
+type NoopMailer struct{}
+
+// Send implements [Mailer.Send] interface and does nothing.
+func (m *NoopMailer) Send(message *Message) error {
+	return nil
+}
@@ -0,0 +1,389 @@
+// Package metrics implements a minimal metrics registry that
+// could be exported in the Prometheus text exposition format
+// (https://prometheus.io/docs/instrumenting/exposition_formats/).
+package metrics
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// DefaultBuckets are the default histogram buckets (in seconds)
+// suitable for measuring the duration of most HTTP requests.
+var DefaultBuckets = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+
+const (
+	typeCounter   = "counter"
+	typeGauge     = "gauge"
+	typeHistogram = "histogram"
+)
+
+type collector interface {
+	write(w *bufio.Writer, name string)
+}
+
+type family struct {
+	name      string
+	help      string
+	kind      string
+	collector collector
+}
+
+// Registry is a concurrent safe collection of named metrics.
+type Registry struct {
+	mux      sync.RWMutex
+	families map[string]*family
+}
+
+// NewRegistry creates a new empty metrics registry.
+func NewRegistry() *Registry {
+	return &Registry{
+		families: map[string]*family{},
+	}
+}
+
+// NewCounterVec registers and returns a new counter
+// metric partitioned by the specified label names.
+//
+// It panics if a metric with the same name is already registered.
+func (r *Registry) NewCounterVec(name string, help string, labelNames ...string) *CounterVec {
+	c := &CounterVec{vec: newVec[float64](labelNames)}
+
+	r.register(name, help, typeCounter, c)
+
+	return c
+}
+
+// NewHistogramVec registers and returns a new histogram metric
+// with the specified (sorted) upper bucket bounds and partitioned
+// by the specified label names.
+//
+// It panics if a metric with the same name is already registered.
+func (r *Registry) NewHistogramVec(name string, help string, buckets []float64, labelNames ...string) *HistogramVec {
+	h := &HistogramVec{
+		vec:     newVec[*histogram](labelNames),
+		buckets: append([]float64{}, buckets...),
+	}
+
+	sort.Float64s(h.buckets)
+
+	r.register(name, help, typeHistogram, h)
+
+	return h
+}
+
+// NewGaugeFunc registers a new gauge metric whose
+// samples are collected on export by calling fn.
+//
+// The label values of each returned sample must be in
+// the same order as the specified label names.
+//
+// It panics if a metric with the same name is already registered.
+func (r *Registry) NewGaugeFunc(name string, help string, fn func() []GaugeSample, labelNames ...string) {
+	r.register(name, help, typeGauge, &gaugeFunc{labelNames: labelNames, fn: fn})
+}
+
+func (r *Registry) register(name string, help string, kind string, c collector) {
+	r.mux.Lock()
+	defer r.mux.Unlock()
+
+	if _, ok := r.families[name]; ok {
+		panic(fmt.Sprintf("metric %q is already registered", name))
+	}
+
+	r.families[name] = &family{name: name, help: help, kind: kind, collector: c}
+}
+
+// WriteText writes all registered metrics in the Prometheus text format.
+func (r *Registry) WriteText(w io.Writer) error {
+	r.mux.RLock()
+	families := make([]*family, 0, len(r.families))
+	for _, f := range r.families {
+		families = append(families, f)
+	}
+	r.mux.RUnlock()
+
+	sort.Slice(families, func(i, j int) bool {
+		return families[i].name < families[j].name
+	})
+
+	bw := bufio.NewWriter(w)
+
+	for _, f := range families {
+		if f.help != "" {
+			fmt.Fprintf(bw, "# HELP %s %s\n", f.name, escapeHelp(f.help))
+		}
+		fmt.Fprintf(bw, "# TYPE %s %s\n", f.name, f.kind)
+
+		f.collector.write(bw, f.name)
+	}
+
+	return bw.Flush()
+}
+
+// -------------------------------------------------------------------
+
+// vec stores a single metric series per unique label values combination.
+type vec[T any] struct {
+	mux        sync.RWMutex
+	labelNames []string
+	series     map[string]*series[T]
+}
+
+type series[T any] struct {
+	labelValues []string
+	value       T
+}
+
+func newVec[T any](labelNames []string) *vec[T] {
+	return &vec[T]{
+		labelNames: labelNames,
+		series:     map[string]*series[T]{},
+	}
+}
+
+// with calls fn with the value of the series associated with the
+// label values (creating a new one with the init func if missing).
+//
+// NB! The series value must be accessed only while holding
+// the vec lock, that's why it is available only inside fn.
+func (v *vec[T]) with(labelValues []string, init func() T, fn func(value *T)) {
+	if len(labelValues) != len(v.labelNames) {
+		panic(fmt.Sprintf("expected %d label values, got %d", len(v.labelNames), len(labelValues)))
+	}
+
+	key := strings.Join(labelValues, "\xff")
+
+	v.mux.Lock()
+	defer v.mux.Unlock()
+
+	s, ok := v.series[key]
+	if !ok {
+		s = &series[T]{labelValues: append([]string{}, labelValues...), value: init()}
+		v.series[key] = s
+	}
+
+	fn(&s.value)
+}
+
+// get calls fn with the value of the series associated
+// with the label values (if such series exists).
+func (v *vec[T]) get(labelValues []string, fn func(value T)) {
+	v.mux.RLock()
+	defer v.mux.RUnlock()
+
+	if s, ok := v.series[strings.Join(labelValues, "\xff")]; ok {
+		fn(s.value)
+	}
+}
+
+// each calls fn for every series sorted by their label values.
+func (v *vec[T]) each(fn func(labelValues []string, value T)) {
+	v.mux.RLock()
+	defer v.mux.RUnlock()
+
+	keys := make([]string, 0, len(v.series))
+	for k := range v.series {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		s := v.series[k]
+		fn(s.labelValues, s.value)
+	}
+}
+
+// -------------------------------------------------------------------
+
+// CounterVec is a monotonically increasing metric partitioned by labels.
+type CounterVec struct {
+	*vec[float64]
+}
+
+// Inc increments with 1 the counter associated with the label values.
+func (c *CounterVec) Inc(labelValues ...string) {
+	c.Add(1, labelValues...)
+}
+
+// Add increments with the specified (non-negative) delta
+// the counter associated with the label values.
+func (c *CounterVec) Add(delta float64, labelValues ...string) {
+	if delta < 0 {
+		panic("counters cannot decrease")
+	}
+
+	c.with(labelValues, func() float64 { return 0 }, func(value *float64) {
+		*value += delta
+	})
+}
+
+// Value returns the current value of the counter associated with the label values.
+func (c *CounterVec) Value(labelValues ...string) float64 {
+	var result float64
+
+	c.get(labelValues, func(value float64) {
+		result = value
+	})
+
+	return result
+}
+
+func (c *CounterVec) write(w *bufio.Writer, name string) {
+	c.each(func(labelValues []string, value float64) {
+		writeSample(w, name, c.labelNames, labelValues, "", "", value)
+	})
+}
+
+// -------------------------------------------------------------------
+
+type histogram struct {
+	counts []uint64 // non-cumulative count per bucket
+	count  uint64
+	sum    float64
+}
+
+// HistogramVec samples observations into configurable buckets, partitioned by labels.
+type HistogramVec struct {
+	*vec[*histogram]
+	buckets []float64
+}
+
+// Observe adds a single observation to the histogram associated with the label values.
+func (h *HistogramVec) Observe(value float64, labelValues ...string) {
+	init := func() *histogram {
+		return &histogram{counts: make([]uint64, len(h.buckets))}
+	}
+
+	h.with(labelValues, init, func(v **histogram) {
+		hist := *v
+
+		for i, bound := range h.buckets {
+			if value <= bound {
+				hist.counts[i]++
+				break
+			}
+		}
+
+		hist.count++
+		hist.sum += value
+	})
+}
+
+// Count returns the total number of observations of the histogram associated with the label values.
+func (h *HistogramVec) Count(labelValues ...string) uint64 {
+	var result uint64
+
+	h.get(labelValues, func(hist *histogram) {
+		result = hist.count
+	})
+
+	return result
+}
+
+func (h *HistogramVec) write(w *bufio.Writer, name string) {
+	h.each(func(labelValues []string, hist *histogram) {
+		var cumulative uint64
+		for i, bound := range h.buckets {
+			cumulative += hist.counts[i]
+			writeSample(w, name+"_bucket", h.labelNames, labelValues, "le", formatFloat(bound), float64(cumulative))
+		}
+		writeSample(w, name+"_bucket", h.labelNames, labelValues, "le", "+Inf", float64(hist.count))
+		writeSample(w, name+"_sum", h.labelNames, labelValues, "", "", hist.sum)
+		writeSample(w, name+"_count", h.labelNames, labelValues, "", "", float64(hist.count))
+	})
+}
+
+// -------------------------------------------------------------------
+
+// GaugeSample defines a single gauge metric series value.
+type GaugeSample struct {
+	LabelValues []string
+	Value       float64
+}
+
+type gaugeFunc struct {
+	labelNames []string
+	fn         func() []GaugeSample
+}
+
+func (g *gaugeFunc) write(w *bufio.Writer, name string) {
+	for _, s := range g.fn() {
+		if len(s.LabelValues) != len(g.labelNames) {
+			continue // invalid sample
+		}
+		writeSample(w, name, g.labelNames, s.LabelValues, "", "", s.Value)
+	}
+}
+
+// -------------------------------------------------------------------
+
+func writeSample(
+	w *bufio.Writer,
+	name string,
+	labelNames []string,
+	labelValues []string,
+	extraLabelName string,
+	extraLabelValue string,
+	value float64,
+) {
+	w.WriteString(name)
+
+	if len(labelNames) > 0 || extraLabelName != "" {
+		w.WriteByte('{')
+		for i, labelName := range labelNames {
+			if i > 0 {
+				w.WriteByte(',')
+			}
+			w.WriteString(labelName)
+			w.WriteString(`="`)
+			w.WriteString(escapeLabelValue(labelValues[i]))
+			w.WriteByte('"')
+		}
+		if extraLabelName != "" {
+			if len(labelNames) > 0 {
+				w.WriteByte(',')
+			}
+			w.WriteString(extraLabelName)
+			w.WriteString(`="`)
+			w.WriteString(extraLabelValue)
+			w.WriteByte('"')
+		}
+		w.WriteByte('}')
+	}
+
+	w.WriteByte(' ')
+	w.WriteString(formatFloat(value))
+	w.WriteByte('\n')
+}
+
+func formatFloat(value float64) string {
+	switch {
+	case math.IsInf(value, 1):
+		return "+Inf"
+	case math.IsInf(value, -1):
+		return "-Inf"
+	case math.IsNaN(value):
+		return "NaN"
+	default:
+		return strconv.FormatFloat(value, 'g', -1, 64)
+	}
+}
+
+var helpReplacer = strings.NewReplacer(`\`, `\\`, "\n", `\n`)
+
+func escapeHelp(help string) string {
+	return helpReplacer.Replace(help)
+}
+
+var labelValueReplacer = strings.NewReplacer(`\`, `\\`, "\n", `\n`, `"`, `\"`)
+
+func escapeLabelValue(value string) string {
+	return labelValueReplacer.Replace(value)
+}
@@ -0,0 +1,129 @@
+package metrics_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/unkod/space/tools/metrics"
+)
+
+func TestRegistryDuplicateName(t *testing.T) {
+	defer func() {
+		if r := recover(); r == nil {
+			t.Fatal("Expected duplicated metric name panic")
+		}
+	}()
+
+	r := metrics.NewRegistry()
+	r.NewCounterVec("test", "")
+	r.NewCounterVec("test", "")
+}
+
+func TestCounterVec(t *testing.T) {
+	r := metrics.NewRegistry()
+
+	c := r.NewCounterVec("test_total", "Test counter.", "a", "b")
+	c.Inc("1", "2")
+	c.Inc("1", "2")
+	c.Add(1.5, "3", "4")
+
+	if v := c.Value("1", "2"); v != 2 {
+		t.Fatalf("Expected 2, got %v", v)
+	}
+
+	if v := c.Value("3", "4"); v != 1.5 {
+		t.Fatalf("Expected 1.5, got %v", v)
+	}
+
+	if v := c.Value("missing", "missing"); v != 0 {
+		t.Fatalf("Expected 0, got %v", v)
+	}
+
+	// invalid label values
+	func() {
+		defer func() {
+			if r := recover(); r == nil {
+				t.Fatal("Expected invalid label values panic")
+			}
+		}()
+		c.Inc("1")
+	}()
+
+	// negative delta
+	func() {
+		defer func() {
+			if r := recover(); r == nil {
+				t.Fatal("Expected negative delta panic")
+			}
+		}()
+		c.Add(-1, "1", "2")
+	}()
+}
+
+func TestHistogramVec(t *testing.T) {
+	r := metrics.NewRegistry()
+
+	h := r.NewHistogramVec("test", "", []float64{1, 0.5}, "a")
+	h.Observe(0.1, "x")
+	h.Observe(0.7, "x")
+	h.Observe(3, "x")
+	h.Observe(0.2, "y")
+
+	if v := h.Count("x"); v != 3 {
+		t.Fatalf("Expected 3, got %v", v)
+	}
+
+	if v := h.Count("y"); v != 1 {
+		t.Fatalf("Expected 1, got %v", v)
+	}
+
+	if v := h.Count("missing"); v != 0 {
+		t.Fatalf("Expected 0, got %v", v)
+	}
+}
+
+func TestRegistryWriteText(t *testing.T) {
+	r := metrics.NewRegistry()
+
+	c := r.NewCounterVec("b_total", "Test\ncounter.", "label")
+	c.Inc(`value"\` + "\n")
+	c.Inc("another")
+
+	h := r.NewHistogramVec("c_seconds", "Test histogram.", []float64{1, 0.5})
+	h.Observe(0.1)
+	h.Observe(0.7)
+	h.Observe(3)
+
+	r.NewGaugeFunc("a", "", func() []metrics.GaugeSample {
+		return []metrics.GaugeSample{
+			{LabelValues: []string{"x", "y"}, Value: 1},
+			{LabelValues: []string{"invalid"}, Value: 2},
+		}
+	}, "l1", "l2")
+
+	var buf bytes.Buffer
+	if err := r.WriteText(&buf); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := strings.Join([]string{
+		`# TYPE a gauge`,
+		`a{l1="x",l2="y"} 1`,
+		`# HELP b_total Test\ncounter.`,
+		`# TYPE b_total counter`,
+		`b_total{label="another"} 1`,
+		`b_total{label="value\"\\\n"} 1`,
+		`# HELP c_seconds Test histogram.`,
+		`# TYPE c_seconds histogram`,
+		`c_seconds_bucket{le="0.5"} 1`,
+		`c_seconds_bucket{le="1"} 2`,
+		`c_seconds_bucket{le="+Inf"} 3`,
+		`c_seconds_sum 3.8`,
+		`c_seconds_count 3`,
+	}, "\n") + "\n"
+
+	if result := buf.String(); result != expected {
+		t.Fatalf("Expected\n%s\ngot\n%s", expected, result)
+	}
+}
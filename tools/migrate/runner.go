@@ -18,6 +18,7 @@ type Runner struct {
 	db             *dbx.DB
 	migrationsList MigrationsList
 	tableName      string
+	verifier       func(db dbx.Builder) error
 }
 
 // NewRunner creates and initializes a new db migrations Runner instance.
@@ -35,6 +36,14 @@ func NewRunner(db *dbx.DB, migrationsList MigrationsList) (*Runner, error) {
 	return runner, nil
 }
 
+// SetVerifier registers an optional db schema verification function
+// that is executed after the reverted migrations (in the same transaction).
+//
+// If the verifier returns an error, the entire revert is rolled back.
+func (r *Runner) SetVerifier(verifier func(db dbx.Builder) error) {
+	r.verifier = verifier
+}
+
 // Run interactively executes the current runner with the provided args.
 //
 // The following commands are supported:
@@ -77,6 +86,11 @@ func (r *Runner) Run(args ...string) error {
 			return err
 		}
 
+		// check before the prompt to fail early
+		if _, err := r.revertableMigrations(names); err != nil {
+			return err
+		}
+
 		confirm := false
 		prompt := &survey.Confirm{
 			Message: fmt.Sprintf(
@@ -154,7 +168,12 @@ func (r *Runner) Up() ([]string, error) {
 }
 
 // Down reverts the last `toRevertCount` applied migrations
-// (in the order they were applied).
+// (in reverse order of their application).
+//
+// All migrations are reverted in a single transaction and nothing is
+// reverted if any of them is missing a Down function or its file is
+// no longer registered. If a verifier is set (see [Runner.SetVerifier])
+// it is executed after the reverts and the transaction is rolled back on error.
 //
 // On success returns list with the reverted migrations file names.
 func (r *Runner) Down(toRevertCount int) ([]string, error) {
@@ -165,30 +184,27 @@ func (r *Runner) Down(toRevertCount int) ([]string, error) {
 		return nil, appliedErr
 	}
 
-	err := r.db.Transactional(func(tx *dbx.Tx) error {
-		for _, name := range names {
-			for _, m := range r.migrationsList.Items() {
-				if m.File != name {
-					continue
-				}
+	toRevert, err := r.revertableMigrations(names)
+	if err != nil {
+		return nil, err
+	}
 
-				// revert limit reached
-				if toRevertCount-len(reverted) <= 0 {
-					return nil
-				}
+	err = r.db.Transactional(func(tx *dbx.Tx) error {
+		for _, m := range toRevert {
+			if err := m.Down(tx); err != nil {
+				return fmt.Errorf("Failed to revert migration %s: %w", m.File, err)
+			}
 
-				// ignore empty Down action
-				if m.Down != nil {
-					if err := m.Down(tx); err != nil {
-						return fmt.Errorf("Failed to revert migration %s: %w", m.File, err)
-					}
-				}
+			if err := r.saveRevertedMigration(tx, m.File); err != nil {
+				return fmt.Errorf("Failed to save reverted migration info for %s: %w", m.File, err)
+			}
 
-				if err := r.saveRevertedMigration(tx, m.File); err != nil {
-					return fmt.Errorf("Failed to save reverted migration info for %s: %w", m.File, err)
-				}
+			reverted = append(reverted, m.File)
+		}
 
-				reverted = append(reverted, m.File)
+		if r.verifier != nil && len(reverted) > 0 {
+			if err := r.verifier(tx); err != nil {
+				return fmt.Errorf("Failed to verify the db schema after the revert: %w", err)
 			}
 		}
 
@@ -202,6 +218,37 @@ func (r *Runner) Down(toRevertCount int) ([]string, error) {
 	return reverted, nil
 }
 
+// revertableMigrations returns the registered migrations of the
+// provided applied migration file names (preserving their order).
+//
+// Returns an error if any of the migrations is not registered
+// or it doesn't have a Down function.
+func (r *Runner) revertableMigrations(names []string) ([]*Migration, error) {
+	result := make([]*Migration, 0, len(names))
+
+	for _, name := range names {
+		var migration *Migration
+		for _, m := range r.migrationsList.Items() {
+			if m.File == name {
+				migration = m
+				break
+			}
+		}
+
+		if migration == nil {
+			return nil, fmt.Errorf("Cannot revert migration %s because it is not registered (you may want to run \"history-sync\")", name)
+		}
+
+		if migration.Down == nil {
+			return nil, fmt.Errorf("Cannot revert migration %s because it doesn't have a Down function", name)
+		}
+
+		result = append(result, migration)
+	}
+
+	return result, nil
+}
+
 func (r *Runner) createMigrationsTable() error {
 	rawQuery := fmt.Sprintf(
 		"CREATE TABLE IF NOT EXISTS %v (file VARCHAR(255) PRIMARY KEY NOT NULL, applied INTEGER NOT NULL)",
@@ -4,6 +4,7 @@ import (
 	"context"
 	"database/sql"
 	"encoding/json"
+	"errors"
 	"testing"
 	"time"
 
@@ -138,6 +139,135 @@ func TestRunnerUpAndDown(t *testing.T) {
 	}
 }
 
+func TestRunnerDownIrreversible(t *testing.T) {
+	testDB, err := createTestDB()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer testDB.Close()
+
+	downCalls := 0
+
+	l := MigrationsList{}
+	l.Register(func(db dbx.Builder) error {
+		return nil
+	}, nil, "1_test")
+	l.Register(func(db dbx.Builder) error {
+		return nil
+	}, func(db dbx.Builder) error {
+		downCalls++
+		return nil
+	}, "2_test")
+
+	r, err := NewRunner(testDB.DB, l)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := r.Up(); err != nil {
+		t.Fatal(err)
+	}
+
+	// the last applied migration is reversible
+	reverted, err := r.Down(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(reverted) != 1 || reverted[0] != "2_test" || downCalls != 1 {
+		t.Fatalf("Expected 2_test to be reverted, got %v (down calls %d)", reverted, downCalls)
+	}
+
+	if _, err := r.Up(); err != nil {
+		t.Fatal(err)
+	}
+
+	// 1_test doesn't have a Down function
+	if _, err := r.Down(2); err == nil {
+		t.Fatal("Expected error for the migration without a Down function")
+	}
+	if downCalls != 1 {
+		t.Fatalf("Expected no migrations to be reverted, got %d down calls", downCalls)
+	}
+	for _, file := range []string{"1_test", "2_test"} {
+		if !r.isMigrationApplied(testDB.DB, file) {
+			t.Fatalf("Expected %s to be still applied", file)
+		}
+	}
+
+	// unregistered applied migration
+	r.saveAppliedMigration(testDB.DB, "3_test")
+	if _, err := r.Down(1); err == nil {
+		t.Fatal("Expected error for the unregistered migration")
+	}
+	if !r.isMigrationApplied(testDB.DB, "3_test") {
+		t.Fatal("Expected 3_test to be still applied")
+	}
+}
+
+func TestRunnerDownVerifier(t *testing.T) {
+	testDB, err := createTestDB()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer testDB.Close()
+
+	l := MigrationsList{}
+	l.Register(func(db dbx.Builder) error {
+		_, err := db.NewQuery("CREATE TABLE test1 (id TEXT)").Execute()
+		return err
+	}, func(db dbx.Builder) error {
+		_, err := db.NewQuery("DROP TABLE test1").Execute()
+		return err
+	}, "1_test")
+
+	r, err := NewRunner(testDB.DB, l)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := r.Up(); err != nil {
+		t.Fatal(err)
+	}
+
+	hasTable := func(db dbx.Builder) bool {
+		var exists bool
+		db.NewQuery("SELECT count(*) FROM sqlite_schema WHERE type = 'table' AND name = 'test1'").Row(&exists)
+		return exists
+	}
+
+	// failed verification
+	r.SetVerifier(func(db dbx.Builder) error {
+		if !hasTable(db) {
+			return errors.New("missing test1 table")
+		}
+		return nil
+	})
+
+	if _, err := r.Down(1); err == nil {
+		t.Fatal("Expected verification error")
+	}
+	if !hasTable(testDB.DB) || !r.isMigrationApplied(testDB.DB, "1_test") {
+		t.Fatal("Expected the revert to be rolled back")
+	}
+
+	// successful verification
+	verifierCalls := 0
+	r.SetVerifier(func(db dbx.Builder) error {
+		verifierCalls++
+		return nil
+	})
+
+	if _, err := r.Down(1); err != nil {
+		t.Fatal(err)
+	}
+	if verifierCalls != 1 {
+		t.Fatalf("Expected the verifier to be called once, got %d", verifierCalls)
+	}
+	if hasTable(testDB.DB) || r.isMigrationApplied(testDB.DB, "1_test") {
+		t.Fatal("Expected 1_test to be reverted")
+	}
+}
+
 func TestHistorySync(t *testing.T) {
 	testDB, err := createTestDB()
 	if err != nil {
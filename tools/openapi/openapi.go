@@ -0,0 +1,160 @@
+// Package openapi implements a minimal subset of the OpenAPI 3
+// specification types used to describe the app api routes.
+//
+// @see https://spec.openapis.org/oas/v3.0.3
+package openapi
+
+import (
+	"regexp"
+	"strings"
+)
+
+// Version is the OpenAPI specification version of the generated documents.
+const Version = "3.0.3"
+
+// Document defines the root object of an OpenAPI document.
+type Document struct {
+	OpenAPI    string               `json:"openapi"`
+	Info       Info                 `json:"info"`
+	Paths      map[string]*PathItem `json:"paths"`
+	Components Components           `json:"components"`
+}
+
+// Info defines the metadata of the described api.
+type Info struct {
+	Title   string `json:"title"`
+	Version string `json:"version"`
+}
+
+// Components holds the reusable document objects.
+type Components struct {
+	Schemas map[string]*Schema `json:"schemas"`
+}
+
+// PathItem describes the operations available on a single path
+// (indexed by their lowercased http method).
+type PathItem map[string]*Operation
+
+// Operation describes a single api operation on a path.
+type Operation struct {
+	OperationId string               `json:"operationId,omitempty"`
+	Summary     string               `json:"summary,omitempty"`
+	Description string               `json:"description,omitempty"`
+	Tags        []string             `json:"tags,omitempty"`
+	Parameters  []*Parameter         `json:"parameters,omitempty"`
+	RequestBody *RequestBody         `json:"requestBody,omitempty"`
+	Responses   map[string]*Response `json:"responses"`
+}
+
+// Parameter describes a single operation parameter.
+type Parameter struct {
+	Name        string  `json:"name"`
+	In          string  `json:"in"` // path, query, header or cookie
+	Description string  `json:"description,omitempty"`
+	Required    bool    `json:"required,omitempty"`
+	Schema      *Schema `json:"schema,omitempty"`
+}
+
+// RequestBody describes a single operation request body.
+type RequestBody struct {
+	Description string                `json:"description,omitempty"`
+	Required    bool                  `json:"required,omitempty"`
+	Content     map[string]*MediaType `json:"content"`
+}
+
+// Response describes a single operation response.
+type Response struct {
+	Description string                `json:"description"`
+	Content     map[string]*MediaType `json:"content,omitempty"`
+}
+
+// MediaType describes the schema of a single request or response content type.
+type MediaType struct {
+	Schema *Schema `json:"schema,omitempty"`
+}
+
+// Schema defines a (simplified) OpenAPI data type schema.
+type Schema struct {
+	Ref         string             `json:"$ref,omitempty"`
+	Type        string             `json:"type,omitempty"`
+	Format      string             `json:"format,omitempty"`
+	Description string             `json:"description,omitempty"`
+	Enum        []any              `json:"enum,omitempty"`
+	Items       *Schema            `json:"items,omitempty"`
+	Properties  map[string]*Schema `json:"properties,omitempty"`
+	Required    []string           `json:"required,omitempty"`
+	Nullable    bool               `json:"nullable,omitempty"`
+	ReadOnly    bool               `json:"readOnly,omitempty"`
+}
+
+// NewDocument creates a new empty OpenAPI document.
+func NewDocument(title string, version string) *Document {
+	return &Document{
+		OpenAPI: Version,
+		Info:    Info{Title: title, Version: version},
+		Paths:   map[string]*PathItem{},
+		Components: Components{
+			Schemas: map[string]*Schema{},
+		},
+	}
+}
+
+// AddOperation registers the operation for the specified method and path.
+//
+// Existing operations with the same method and path are replaced.
+func (d *Document) AddOperation(method string, path string, op *Operation) {
+	item, ok := d.Paths[path]
+	if !ok {
+		item = &PathItem{}
+		d.Paths[path] = item
+	}
+
+	if op.Responses == nil {
+		op.Responses = map[string]*Response{}
+	}
+
+	(*item)[strings.ToLower(method)] = op
+}
+
+// HasOperation checks whether an operation for the specified method and path exists.
+func (d *Document) HasOperation(method string, path string) bool {
+	item, ok := d.Paths[path]
+	if !ok {
+		return false
+	}
+
+	_, ok = (*item)[strings.ToLower(method)]
+
+	return ok
+}
+
+// RefSchema returns a new schema that references the named components schema.
+func RefSchema(name string) *Schema {
+	return &Schema{Ref: "#/components/schemas/" + name}
+}
+
+// JsonContent returns a new "application/json" content map with the provided schema.
+func JsonContent(schema *Schema) map[string]*MediaType {
+	return map[string]*MediaType{"application/json": {Schema: schema}}
+}
+
+var pathParamRegex = regexp.MustCompile(`:(\w+)`)
+
+// ConvertPath converts an echo route path (eg. "/api/items/:id/*")
+// to an OpenAPI one (eg. "/api/items/{id}/{path}") and returns
+// it together with the names of its path params.
+func ConvertPath(path string) (string, []string) {
+	params := []string{}
+
+	result := pathParamRegex.ReplaceAllStringFunc(path, func(match string) string {
+		params = append(params, match[1:])
+		return "{" + match[1:] + "}"
+	})
+
+	if strings.HasSuffix(result, "*") {
+		params = append(params, "path")
+		result = strings.TrimSuffix(result, "*") + "{path}"
+	}
+
+	return result, params
+}
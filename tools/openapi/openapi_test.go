@@ -0,0 +1,77 @@
+package openapi_test
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/unkod/space/tools/openapi"
+)
+
+func TestConvertPath(t *testing.T) {
+	scenarios := []struct {
+		path           string
+		expectedPath   string
+		expectedParams []string
+	}{
+		{"", "", []string{}},
+		{"/api/test", "/api/test", []string{}},
+		{"/api/test/:id", "/api/test/{id}", []string{"id"}},
+		{"/api/:collection/records/:id/restore", "/api/{collection}/records/{id}/restore", []string{"collection", "id"}},
+		{"/api/files/:collection/:id/*", "/api/files/{collection}/{id}/{path}", []string{"collection", "id", "path"}},
+	}
+
+	for _, s := range scenarios {
+		path, params := openapi.ConvertPath(s.path)
+
+		if path != s.expectedPath {
+			t.Errorf("[%s] Expected path %q, got %q", s.path, s.expectedPath, path)
+		}
+
+		if strings.Join(params, ",") != strings.Join(s.expectedParams, ",") {
+			t.Errorf("[%s] Expected params %v, got %v", s.path, s.expectedParams, params)
+		}
+	}
+}
+
+func TestDocumentAddOperation(t *testing.T) {
+	doc := openapi.NewDocument("test", "1.0.0")
+
+	if doc.HasOperation("GET", "/test") {
+		t.Fatal("Expected no GET /test operation")
+	}
+
+	doc.AddOperation("GET", "/test", &openapi.Operation{Summary: "a"})
+	doc.AddOperation("post", "/test", &openapi.Operation{
+		Summary:   "b",
+		Responses: map[string]*openapi.Response{"204": {Description: "No content."}},
+	})
+	doc.AddOperation("GET", "/test", &openapi.Operation{Summary: "c"}) // replace
+
+	if !doc.HasOperation("get", "/test") || !doc.HasOperation("POST", "/test") {
+		t.Fatal("Expected GET and POST /test operations")
+	}
+
+	doc.Components.Schemas["item"] = &openapi.Schema{Type: "object"}
+
+	raw, err := json.Marshal(doc)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `{"openapi":"3.0.3","info":{"title":"test","version":"1.0.0"},"paths":{"/test":{"get":{"summary":"c","responses":{}},"post":{"summary":"b","responses":{"204":{"description":"No content."}}}}},"components":{"schemas":{"item":{"type":"object"}}}}`
+
+	if string(raw) != expected {
+		t.Fatalf("Expected \n%s, \ngot \n%s", expected, raw)
+	}
+}
+
+func TestRefSchema(t *testing.T) {
+	raw, _ := json.Marshal(openapi.RefSchema("test"))
+
+	expected := `{"$ref":"#/components/schemas/test"}`
+
+	if string(raw) != expected {
+		t.Fatalf("Expected %s, got %s", expected, raw)
+	}
+}
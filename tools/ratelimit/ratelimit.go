@@ -0,0 +1,108 @@
+// Package ratelimit implements a simple token bucket based rate limiter.
+package ratelimit
+
+import (
+	"math"
+	"sync"
+	"time"
+)
+
+// Store defines a rate limiter tokens storage.
+//
+// The default implementation is the in-memory [MemoryStore] but
+// it could be replaced for example with a Redis backed one.
+type Store interface {
+	// Allow consumes a single token from the bucket associated with the key.
+	//
+	// The bucket has `capacity` tokens and it is fully refilled
+	// for the specified `interval` duration.
+	//
+	// retryAfter is the duration until the next token will be available
+	// (it is always 0 if the request is allowed).
+	Allow(key string, capacity int, interval time.Duration) (allowed bool, retryAfter time.Duration, err error)
+}
+
+// ensures that the MemoryStore implements the Store interface.
+var _ Store = (*MemoryStore)(nil)
+
+type bucket struct {
+	tokens    float64
+	updatedAt time.Time
+	fullAt    time.Time
+}
+
+// MemoryStore is a concurrent safe in-memory Store implementation.
+type MemoryStore struct {
+	mux         sync.Mutex
+	buckets     map[string]*bucket
+	lastCleanup time.Time
+
+	// now is the time source (used mostly for the tests).
+	now func() time.Time
+}
+
+// NewMemoryStore creates a new empty in-memory store.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		buckets: map[string]*bucket{},
+		now:     time.Now,
+	}
+}
+
+// cleanupInterval specifies how often to prune the fully refilled buckets.
+const cleanupInterval = time.Minute
+
+// Allow implements [Store.Allow].
+func (s *MemoryStore) Allow(key string, capacity int, interval time.Duration) (bool, time.Duration, error) {
+	if capacity <= 0 || interval <= 0 {
+		return true, 0, nil // no limit
+	}
+
+	s.mux.Lock()
+	defer s.mux.Unlock()
+
+	now := s.now()
+
+	s.cleanup(now)
+
+	rate := float64(capacity) / float64(interval) // tokens per nanosecond
+
+	b, ok := s.buckets[key]
+	if !ok {
+		b = &bucket{tokens: float64(capacity), updatedAt: now}
+		s.buckets[key] = b
+	} else {
+		// refill
+		elapsed := now.Sub(b.updatedAt)
+		b.tokens = math.Min(float64(capacity), b.tokens+float64(elapsed)*rate)
+		b.updatedAt = now
+	}
+
+	if b.tokens < 1 {
+		retryAfter := time.Duration(math.Ceil((1 - b.tokens) / rate))
+		return false, retryAfter, nil
+	}
+
+	b.tokens--
+	b.fullAt = now.Add(time.Duration(math.Ceil((float64(capacity) - b.tokens) / rate)))
+
+	return true, 0, nil
+}
+
+// cleanup removes the already fully refilled buckets since they
+// are equivalent to a new bucket.
+//
+// It must be called while holding the store lock.
+func (s *MemoryStore) cleanup(now time.Time) {
+	if now.Sub(s.lastCleanup) < cleanupInterval {
+		return
+	}
+
+	s.lastCleanup = now
+
+	for k, b := range s.buckets {
+		if !now.Before(b.fullAt) {
+			delete(s.buckets, k)
+		}
+	}
+}
@@ -0,0 +1,105 @@
+package ratelimit
+
+import (
+	"testing"
+	"time"
+)
+
+func TestMemoryStoreAllow(t *testing.T) {
+	now := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	s := NewMemoryStore()
+	s.now = func() time.Time { return now }
+
+	// no limit
+	for i := 0; i < 10; i++ {
+		if allowed, _, _ := s.Allow("test", 0, time.Second); !allowed {
+			t.Fatalf("(%d) Expected zero capacity to be unlimited", i)
+		}
+	}
+
+	// consume the entire bucket
+	for i := 0; i < 3; i++ {
+		allowed, retryAfter, err := s.Allow("a", 3, 3*time.Second)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !allowed || retryAfter != 0 {
+			t.Fatalf("(%d) Expected request to be allowed, got %v (retryAfter %v)", i, allowed, retryAfter)
+		}
+	}
+
+	allowed, retryAfter, _ := s.Allow("a", 3, 3*time.Second)
+	if allowed {
+		t.Fatal("Expected the request to be rejected")
+	}
+	if retryAfter != time.Second {
+		t.Fatalf("Expected retryAfter %v, got %v", time.Second, retryAfter)
+	}
+
+	// other keys should have their own bucket
+	if allowed, _, _ := s.Allow("b", 3, 3*time.Second); !allowed {
+		t.Fatal("Expected the other key request to be allowed")
+	}
+
+	// partial refill
+	now = now.Add(500 * time.Millisecond)
+	allowed, retryAfter, _ = s.Allow("a", 3, 3*time.Second)
+	if allowed {
+		t.Fatal("Expected the request to be rejected after partial refill")
+	}
+	if retryAfter != 500*time.Millisecond {
+		t.Fatalf("Expected retryAfter %v, got %v", 500*time.Millisecond, retryAfter)
+	}
+
+	// single token refill
+	now = now.Add(500 * time.Millisecond)
+	if allowed, _, _ := s.Allow("a", 3, 3*time.Second); !allowed {
+		t.Fatal("Expected the request to be allowed after a single token refill")
+	}
+	if allowed, _, _ := s.Allow("a", 3, 3*time.Second); allowed {
+		t.Fatal("Expected the request to be rejected after consuming the refilled token")
+	}
+
+	// the bucket shouldn't exceed its capacity
+	now = now.Add(time.Hour)
+	for i := 0; i < 3; i++ {
+		if allowed, _, _ := s.Allow("a", 3, 3*time.Second); !allowed {
+			t.Fatalf("(%d) Expected the request to be allowed after full refill", i)
+		}
+	}
+	if allowed, _, _ := s.Allow("a", 3, 3*time.Second); allowed {
+		t.Fatal("Expected the bucket to not exceed its capacity")
+	}
+}
+
+func TestMemoryStoreCleanup(t *testing.T) {
+	now := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	s := NewMemoryStore()
+	s.now = func() time.Time { return now }
+
+	s.Allow("a", 2, time.Second)
+	s.Allow("b", 2, time.Hour)
+
+	if total := len(s.buckets); total != 2 {
+		t.Fatalf("Expected 2 buckets, got %d", total)
+	}
+
+	now = now.Add(cleanupInterval)
+
+	// trigger cleanup
+	s.Allow("c", 2, time.Second)
+
+	if _, ok := s.buckets["a"]; ok {
+		t.Fatal("Expected the fully refilled bucket a to be removed")
+	}
+
+	if _, ok := s.buckets["b"]; !ok {
+		t.Fatal("Expected bucket b to remain")
+	}
+
+	if _, ok := s.buckets["c"]; !ok {
+		t.Fatal("Expected bucket c to be created")
+	}
+}
@@ -2,6 +2,8 @@ package rest
 
 import (
 	"encoding/json"
+	"fmt"
+	"net/http"
 	"strings"
 
 	"github.com/labstack/echo/v5"
@@ -19,7 +21,41 @@ type Serializer struct {
 // Serialize converts an interface into a json and writes it to the response.
 //
 // It also provides a generic response data fields picker via the FieldsParam query parameter (default to "fields").
+//
+// Nested fields (eg. of expanded relations) could be picked with dot-notation,
+// eg. "fields=id,title,expand.author.name". Nonexisting paths are ignored.
+//
+// The "*" wildcard picks all fields of its level (eg. "fields=*" or "fields=expand.author.*")
+// and fields prefixed with "-" are excluded, eg. "fields=*,-content,-expand.author.bio".
+// Exclusions always take precedence over the picked fields and
+// if there are only exclusions, all other fields are returned.
+//
+// Fields could be also returned under a different key with the "alias:field"
+// syntax, eg. "fields=name:title,expand.author.nick:name". The aliased field
+// must be from the same level as the alias and it is not returned under its
+// original key unless it is also picked (eg. "fields=*,name:title").
+// Duplicated output keys (eg. "fields=name:title,name") result in 400 error.
+//
+// The fields picker is not applied to error values.
+//
+// If the [ContextXMLKey] context value is set, the (picked) data is
+// serialized as XML instead of JSON (see [EncodeXML]).
 func (s *Serializer) Serialize(c echo.Context, i any, indent string) error {
+	data, err := s.pick(c, i)
+	if err != nil {
+		return err
+	}
+
+	if isXML, _ := c.Get(ContextXMLKey).(bool); isXML {
+		c.Response().Header().Set(echo.HeaderContentType, echo.MIMEApplicationXMLCharsetUTF8)
+		return EncodeXML(c.Response(), data, indent)
+	}
+
+	return s.DefaultJSONSerializer.Serialize(c, data, indent)
+}
+
+// pick returns the response data with only the fields of the FieldsParam query parameter.
+func (s *Serializer) pick(c echo.Context, i any) (any, error) {
 	fieldsParam := s.FieldsParam
 	if fieldsParam == "" {
 		fieldsParam = "fields"
@@ -27,7 +63,12 @@ func (s *Serializer) Serialize(c echo.Context, i any, indent string) error {
 
 	param := c.QueryParam(fieldsParam)
 	if param == "" {
-		return s.DefaultJSONSerializer.Serialize(c, i, indent)
+		return i, nil
+	}
+
+	// return the errors as they are
+	if _, ok := i.(error); ok {
+		return i, nil
 	}
 
 	fields := strings.Split(param, ",")
@@ -35,15 +76,19 @@ func (s *Serializer) Serialize(c echo.Context, i any, indent string) error {
 		fields[i] = strings.TrimSpace(f)
 	}
 
+	if err := validateFieldAliases(fields); err != nil {
+		return nil, invalidFieldsError(fieldsParam, err)
+	}
+
 	encoded, err := json.Marshal(i)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	var decoded any
 
 	if err := json.Unmarshal(encoded, &decoded); err != nil {
-		return err
+		return nil, err
 	}
 
 	var isSearchResult bool
@@ -55,67 +100,205 @@ func (s *Serializer) Serialize(c echo.Context, i any, indent string) error {
 
 	if isSearchResult {
 		if decodedMap, ok := decoded.(map[string]any); ok {
-			pickFields(decodedMap["items"], fields)
+			err = pickFields(decodedMap["items"], fields)
 		}
 	} else {
-		pickFields(decoded, fields)
+		err = pickFields(decoded, fields)
+	}
+
+	if err != nil {
+		return nil, invalidFieldsError(fieldsParam, err)
+	}
+
+	return decoded, nil
+}
+
+func invalidFieldsError(fieldsParam string, err error) error {
+	return echo.NewHTTPErrorWithInternal(
+		http.StatusBadRequest,
+		err,
+		fmt.Sprintf("Invalid %s query parameter: %v.", fieldsParam, err),
+	)
+}
+
+// splitFieldAlias splits a single "alias:field" entry of the current level.
+//
+// Returns false if the entry is not an alias of the current level
+// (eg. nested "expand.author.nick:name" aliases are returned as false).
+func splitFieldAlias(f string) (alias string, field string, ok bool) {
+	alias, field, ok = strings.Cut(f, ":")
+	if !ok || strings.Contains(alias, ".") {
+		return "", "", false
 	}
 
-	return s.DefaultJSONSerializer.Serialize(c, decoded, indent)
+	return alias, field, true
 }
 
-func pickFields(data any, fields []string) {
+// validateFieldAliases checks the fields list for invalid
+// aliases and duplicated output keys.
+func validateFieldAliases(fields []string) error {
+	// output keys grouped by their level path
+	aliasKeys := map[string]struct{}{}
+	plainKeys := map[string]struct{}{}
+
+	for _, f := range fields {
+		if !strings.Contains(f, ":") {
+			if !strings.HasPrefix(f, "-") {
+				plainKeys[f] = struct{}{}
+			}
+			continue
+		}
+
+		if strings.HasPrefix(f, "-") {
+			return fmt.Errorf("aliased field %q cannot be excluded", f)
+		}
+
+		path, alias, field := "", f, ""
+		if i := strings.LastIndex(f[:strings.Index(f, ":")], "."); i >= 0 {
+			path, alias = f[:i+1], f[i+1:]
+		}
+		alias, field, _ = strings.Cut(alias, ":")
+
+		if alias == "" || field == "" || alias == "*" || field == "*" || strings.ContainsAny(field, ".:") {
+			return fmt.Errorf("invalid field alias %q (expected alias:field)", f)
+		}
+
+		key := path + alias
+		if _, ok := aliasKeys[key]; ok {
+			return fmt.Errorf("duplicated output key %q", key)
+		}
+		aliasKeys[key] = struct{}{}
+	}
+
+	for key := range aliasKeys {
+		if _, ok := plainKeys[key]; ok {
+			return fmt.Errorf("duplicated output key %q", key)
+		}
+	}
+
+	return nil
+}
+
+func pickFields(data any, fields []string) error {
 	switch v := data.(type) {
 	case map[string]any:
-		pickMapFields(v, fields)
+		return pickMapFields(v, fields)
 	case []map[string]any:
 		for _, item := range v {
-			pickMapFields(item, fields)
+			if err := pickMapFields(item, fields); err != nil {
+				return err
+			}
 		}
 	case []any:
 		if len(v) == 0 {
-			return // nothing to pick
+			return nil // nothing to pick
 		}
 
 		if _, ok := v[0].(map[string]any); !ok {
-			return // for now ignore non-map values
+			return nil // for now ignore non-map values
 		}
 
 		for _, item := range v {
-			pickMapFields(item.(map[string]any), fields)
+			if err := pickMapFields(item.(map[string]any), fields); err != nil {
+				return err
+			}
 		}
 	}
+
+	return nil
 }
 
-func pickMapFields(data map[string]any, fields []string) {
+func pickMapFields(data map[string]any, fields []string) error {
 	if len(fields) == 0 {
-		return // nothing to pick
+		return nil // nothing to pick
+	}
+
+	includes := make([]string, 0, len(fields))
+	excludes := make([]string, 0, len(fields))
+	aliases := make([][2]string, 0, len(fields))
+	for _, f := range fields {
+		if strings.HasPrefix(f, "-") {
+			excludes = append(excludes, f[1:])
+		} else if alias, field, ok := splitFieldAlias(f); ok {
+			aliases = append(aliases, [2]string{alias, field})
+		} else {
+			includes = append(includes, f)
+		}
+	}
+
+	// exclusions only
+	if len(includes) == 0 && len(aliases) == 0 {
+		includes = append(includes, "*")
+	}
+
+	// store the aliased values before the picking since
+	// the original fields could be removed from the data
+	aliasedValues := make(map[string]any, len(aliases))
+AliasesLoop:
+	for _, pair := range aliases {
+		for _, f := range excludes {
+			if f == pair[1] || f == "*" {
+				continue AliasesLoop
+			}
+		}
+
+		if v, ok := data[pair[1]]; ok {
+			aliasedValues[pair[0]] = v
+		}
 	}
 
 DataLoop:
 	for k := range data {
+		// nested fields of the current key (with the key trimmed)
 		matchingFields := make([]string, 0, len(fields))
-		for _, f := range fields {
-			if strings.HasPrefix(f+".", k+".") {
-				matchingFields = append(matchingFields, f)
+
+		for _, f := range excludes {
+			if f == k || f == "*" {
+				delete(data, k)
+				continue DataLoop
+			}
+
+			if strings.HasPrefix(f, k+".") {
+				matchingFields = append(matchingFields, "-"+strings.TrimPrefix(f, k+"."))
+			}
+		}
+
+		var isFullMatch bool
+		nestedIncludes := make([]string, 0, len(includes))
+
+		for _, f := range includes {
+			if f == k || f == "*" {
+				isFullMatch = true
 				continue
 			}
+
+			if strings.HasPrefix(f, k+".") {
+				nestedIncludes = append(nestedIncludes, strings.TrimPrefix(f, k+"."))
+			}
 		}
 
-		if len(matchingFields) == 0 {
+		if !isFullMatch && len(nestedIncludes) == 0 {
 			delete(data, k)
 			continue DataLoop
 		}
 
-		// trim the key from the fields
-		for i, v := range matchingFields {
-			trimmed := strings.TrimSuffix(strings.TrimPrefix(v+".", k+"."), ".")
-			if trimmed == "" {
-				continue DataLoop
-			}
-			matchingFields[i] = trimmed
+		// pick only the nested fields if the key is not fully matched
+		if !isFullMatch {
+			matchingFields = append(matchingFields, nestedIncludes...)
 		}
 
-		pickFields(data[k], matchingFields)
+		if err := pickFields(data[k], matchingFields); err != nil {
+			return err
+		}
 	}
+
+	for alias, v := range aliasedValues {
+		if _, ok := data[alias]; ok {
+			return fmt.Errorf("duplicated output key %q", alias)
+		}
+
+		data[alias] = v
+	}
+
+	return nil
 }
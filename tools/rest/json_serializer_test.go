@@ -136,6 +136,136 @@ func TestSerialize(t *testing.T) {
 			"fields=a, c, anySlice.A, mapSlice.C, mapSlice.D.DA, anySlice.D,fullMap",
 			`{"a":1,"anySlice":[{"A":[1,2,3],"D":{"DA":1,"DB":2}},{"A":"test"}],"c":"test","fullMap":[{"A":[1,2,3],"B":["1","2",3],"C":"test"},{"B":["1","2",3],"D":[{"DA":2},{"DA":3}]}],"mapSlice":[{"C":"test","D":[{"DA":1}]},{"D":[{"DA":2},{"DA":3},{}]}]}`,
 		},
+		{
+			"multiple levels of expanded records",
+			rest.Serializer{},
+			map[string]any{
+				"id":    "r1",
+				"title": "test1",
+				"expand": map[string]any{
+					"author": map[string]any{
+						"id":   "a1",
+						"name": "author1",
+						"expand": map[string]any{
+							"company": map[string]any{"id": "c1", "name": "company1"},
+						},
+					},
+					"tags": []any{
+						map[string]any{"id": "t1", "name": "tag1"},
+						map[string]any{"id": "t2", "name": "tag2"},
+					},
+				},
+			},
+			"fields=id,expand.author.name,expand.author.expand.company.name,expand.tags.id,expand.missing.id,expand.author.missing",
+			`{"expand":{"author":{"expand":{"company":{"name":"company1"}},"name":"author1"},"tags":[{"id":"t1"},{"id":"t2"}]},"id":"r1"}`,
+		},
+		{
+			"wildcard",
+			rest.Serializer{},
+			map[string]any{"a": 1, "b": 2, "c": map[string]any{"ca": 1}},
+			"fields=*",
+			`{"a":1,"b":2,"c":{"ca":1}}`,
+		},
+		{
+			"exclude only",
+			rest.Serializer{},
+			map[string]any{"a": 1, "b": 2, "c": "test"},
+			"fields=-b, -missing",
+			`{"a":1,"c":"test"}`,
+		},
+		{
+			"wildcard + exclude",
+			rest.Serializer{},
+			map[string]any{"a": 1, "b": 2, "c": "test"},
+			"fields=*,-b,-c",
+			`{"a":1}`,
+		},
+		{
+			"include + exclude (exclude takes precedence)",
+			rest.Serializer{},
+			map[string]any{"a": 1, "b": 2, "c": "test"},
+			"fields=a,b,-b",
+			`{"a":1}`,
+		},
+		{
+			"exclude all",
+			rest.Serializer{},
+			map[string]any{"a": 1, "b": 2, "c": "test"},
+			"fields=a,-*",
+			`{}`,
+		},
+		{
+			"slice of maps with excluded fields",
+			rest.Serializer{},
+			[]any{
+				map[string]any{"a": 11, "b": 11, "c": "test1"},
+				map[string]any{"a": 22, "b": 22, "c": "test2"},
+			},
+			"fields=-a,-c",
+			`[{"b":11},{"b":22}]`,
+		},
+		{
+			"nested exclusion of expanded records",
+			rest.Serializer{},
+			map[string]any{
+				"id":      "r1",
+				"title":   "test1",
+				"content": "large",
+				"expand": map[string]any{
+					"author": map[string]any{
+						"id":   "a1",
+						"name": "author1",
+						"bio":  "large",
+						"expand": map[string]any{
+							"company": map[string]any{"id": "c1", "name": "company1"},
+						},
+					},
+					"tags": []any{
+						map[string]any{"id": "t1", "name": "tag1"},
+						map[string]any{"id": "t2", "name": "tag2"},
+					},
+				},
+			},
+			"fields=-content,-expand.author.bio,-expand.author.expand.company.id,-expand.tags.name,-expand.missing.id",
+			`{"expand":{"author":{"expand":{"company":{"name":"company1"}},"id":"a1","name":"author1"},"tags":[{"id":"t1"},{"id":"t2"}]},"id":"r1","title":"test1"}`,
+		},
+		{
+			"nested include + exclusion of expanded records",
+			rest.Serializer{},
+			map[string]any{
+				"id":    "r1",
+				"title": "test1",
+				"expand": map[string]any{
+					"author": map[string]any{
+						"id":   "a1",
+						"name": "author1",
+						"bio":  "large",
+					},
+					"tags": []any{
+						map[string]any{"id": "t1", "name": "tag1"},
+						map[string]any{"id": "t2", "name": "tag2"},
+					},
+				},
+			},
+			"fields=id,expand.author.*,-expand.author.bio,expand.tags,-expand.tags.id",
+			`{"expand":{"author":{"id":"a1","name":"author1"},"tags":[{"name":"tag1"},{"name":"tag2"}]},"id":"r1"}`,
+		},
+		{
+			"SearchResult with excluded fields",
+			rest.Serializer{},
+			search.Result{
+				Page:       1,
+				PerPage:    10,
+				TotalItems: 20,
+				TotalPages: 30,
+				Items: []any{
+					map[string]any{"a": 11, "b": 11, "c": "test1"},
+					map[string]any{"a": 22, "b": 22, "c": "test2"},
+				},
+			},
+			"fields=-c",
+			`{"items":[{"a":11,"b":11},{"a":22,"b":22}],"page":1,"perPage":10,"totalItems":20,"totalPages":30}`,
+		},
 		{
 			"SearchResult",
 			rest.Serializer{},
@@ -168,6 +298,80 @@ func TestSerialize(t *testing.T) {
 			"fields=a,c",
 			`{"items":[{"a":11,"c":"test1"},{"a":22,"c":"test2"}],"page":1,"perPage":10,"totalItems":20,"totalPages":30}`,
 		},
+		{
+			"top-level field alias",
+			rest.Serializer{},
+			map[string]any{"a": 1, "b": 2, "c": "test"},
+			"fields=title:c,a",
+			`{"a":1,"title":"test"}`,
+		},
+		{
+			"field alias with wildcard",
+			rest.Serializer{},
+			map[string]any{"a": 1, "b": 2, "c": "test"},
+			"fields=*,title:c",
+			`{"a":1,"b":2,"c":"test","title":"test"}`,
+		},
+		{
+			"field alias with exclusions",
+			rest.Serializer{},
+			map[string]any{"a": 1, "b": 2, "c": "test"},
+			"fields=title:c,-b",
+			`{"title":"test"}`,
+		},
+		{
+			"field alias of excluded field",
+			rest.Serializer{},
+			map[string]any{"a": 1, "b": 2, "c": "test"},
+			"fields=a,title:c,-c",
+			`{"a":1}`,
+		},
+		{
+			"swapped field aliases",
+			rest.Serializer{},
+			map[string]any{"a": 1, "b": 2, "c": "test"},
+			"fields=a:b,b:a",
+			`{"a":2,"b":1}`,
+		},
+		{
+			"field alias of missing field",
+			rest.Serializer{},
+			map[string]any{"a": 1, "b": 2, "c": "test"},
+			"fields=a,title:missing",
+			`{"a":1}`,
+		},
+		{
+			"expand field aliases",
+			rest.Serializer{},
+			map[string]any{
+				"id": "123",
+				"expand": map[string]any{
+					"author": map[string]any{"id": "456", "name": "test", "email": "test@example.com"},
+					"tags": []any{
+						map[string]any{"id": "t1", "label": "a"},
+						map[string]any{"id": "t2", "label": "b"},
+					},
+				},
+			},
+			"fields=id,expand.author.nick:name,expand.author.id,expand.tags.title:label,-expand.tags.id",
+			`{"expand":{"author":{"id":"456","nick":"test"},"tags":[{"title":"a"},{"title":"b"}]},"id":"123"}`,
+		},
+		{
+			"SearchResult field aliases",
+			rest.Serializer{},
+			search.Result{
+				Page:       1,
+				PerPage:    10,
+				TotalItems: 20,
+				TotalPages: 30,
+				Items: []any{
+					map[string]any{"a": 11, "b": 11, "c": "test1"},
+					map[string]any{"a": 22, "b": 22, "c": "test2"},
+				},
+			},
+			"fields=a,title:c",
+			`{"items":[{"a":11,"title":"test1"},{"a":22,"title":"test2"}],"page":1,"perPage":10,"totalItems":20,"totalPages":30}`,
+		},
 	}
 
 	for _, s := range scenarios {
@@ -193,3 +397,69 @@ func TestSerialize(t *testing.T) {
 		}
 	}
 }
+
+func TestSerializeInvalidFieldAliases(t *testing.T) {
+	scenarios := []struct {
+		name  string
+		data  any
+		query string
+	}{
+		{"empty alias", map[string]any{"a": 1}, "fields=:a"},
+		{"empty aliased field", map[string]any{"a": 1}, "fields=title:"},
+		{"wildcard aliased field", map[string]any{"a": 1}, "fields=title:*"},
+		{"nested aliased field", map[string]any{"a": 1}, "fields=title:a.b"},
+		{"excluded alias", map[string]any{"a": 1}, "fields=-title:a"},
+		{"duplicated aliases", map[string]any{"a": 1, "b": 2}, "fields=title:a,title:b"},
+		{"alias matching a picked field", map[string]any{"a": 1, "b": 2}, "fields=a,a:b"},
+		{"duplicated expand aliases", map[string]any{"expand": map[string]any{}}, "fields=expand.rel.title:a,expand.rel.title:b"},
+		{"alias matching an existing field", map[string]any{"a": 1, "b": 2}, "fields=*,a:b"},
+	}
+
+	for _, s := range scenarios {
+		e := echo.New()
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.URL.RawQuery = s.query
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+
+		err := (&rest.Serializer{}).Serialize(c, s.data, "")
+
+		httpErr, ok := err.(*echo.HTTPError)
+		if !ok {
+			t.Errorf("[%s] Expected *echo.HTTPError, got %v", s.name, err)
+			continue
+		}
+
+		if httpErr.Code != http.StatusBadRequest {
+			t.Errorf("[%s] Expected status code %d, got %d", s.name, http.StatusBadRequest, httpErr.Code)
+		}
+	}
+}
+
+func TestSerializeXML(t *testing.T) {
+	e := echo.New()
+	req := httptest.NewRequest(http.MethodGet, "/?fields=a", nil)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	c.Set(rest.ContextXMLKey, true)
+
+	data := search.Result{
+		Page:  1,
+		Items: []map[string]any{{"a": 1, "b": 2}, {"a": 3, "b": 4}},
+	}
+
+	if err := (&rest.Serializer{}).Serialize(c, data, ""); err != nil {
+		t.Fatal(err)
+	}
+
+	if v := rec.Header().Get(echo.HeaderContentType); v != echo.MIMEApplicationXMLCharsetUTF8 {
+		t.Fatalf("Expected %q content type, got %q", echo.MIMEApplicationXMLCharsetUTF8, v)
+	}
+
+	expected := `<?xml version="1.0" encoding="UTF-8"?>` + "\n" +
+		`<response><items><item><a>1</a></item><item><a>3</a></item></items><page>1</page><perPage>0</perPage><totalItems>0</totalItems><totalPages>0</totalPages></response>`
+
+	if v := rec.Body.String(); v != expected {
+		t.Fatalf("Expected body\n%v\ngot\n%v", expected, v)
+	}
+}
@@ -12,6 +12,9 @@ import (
 	"github.com/spf13/cast"
 )
 
+// MIMEApplicationMergePatchJSON is the JSON Merge Patch content type (RFC 7386).
+const MIMEApplicationMergePatchJSON = "application/merge-patch+json"
+
 // BindBody binds request body content to i.
 //
 // This is similar to `echo.BindBody()`, but for JSON requests uses
@@ -24,7 +27,7 @@ func BindBody(c echo.Context, i interface{}) error {
 
 	ctype := req.Header.Get(echo.HeaderContentType)
 	switch {
-	case strings.HasPrefix(ctype, echo.MIMEApplicationJSON):
+	case strings.HasPrefix(ctype, echo.MIMEApplicationJSON), strings.HasPrefix(ctype, MIMEApplicationMergePatchJSON):
 		err := CopyJsonBody(c.Request(), i)
 		if err != nil {
 			return echo.NewHTTPErrorWithInternal(http.StatusBadRequest, err, err.Error())
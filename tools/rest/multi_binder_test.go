@@ -38,6 +38,12 @@ func TestBindBody(t *testing.T) {
 			`{"test":123}`,
 			false,
 		},
+		{
+			strings.NewReader(`{"test":null}`),
+			rest.MIMEApplicationMergePatchJSON,
+			`{"test":null}`,
+			false,
+		},
 		{
 			strings.NewReader(
 				url.Values{
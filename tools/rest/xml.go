@@ -0,0 +1,148 @@
+package rest
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"io"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ContextXMLKey is the context key that marks the requests
+// whose responses should be serialized by [Serializer] as XML.
+const ContextXMLKey = "@xmlResponse"
+
+// XMLRootName is the name of the root element of the XML serialized responses.
+const XMLRootName = "response"
+
+// xmlItemName is the element name of the XML serialized array items.
+const xmlItemName = "item"
+
+var xmlNameRegex = regexp.MustCompile(`^[A-Za-z_][\w.-]*$`)
+
+// PrefersXML checks whether XML ("application/xml" or "text/xml")
+// is the most preferred media type of the provided Accept header value.
+//
+// Media types with equal quality are preferred in the order of their
+// appearance, eg. "application/xml, application/json" prefers XML,
+// while "text/html, application/xml;q=0.9, */*;q=0.8" (aka. a browser) doesn't.
+func PrefersXML(accept string) bool {
+	var preferred string
+	preferredQ := 0.0
+
+	for _, part := range strings.Split(accept, ",") {
+		mediaType, params, _ := strings.Cut(part, ";")
+
+		q := 1.0
+		for _, param := range strings.Split(params, ";") {
+			k, v, _ := strings.Cut(param, "=")
+			if strings.TrimSpace(k) != "q" {
+				continue
+			}
+			if parsed, err := strconv.ParseFloat(strings.TrimSpace(v), 64); err == nil {
+				q = parsed
+			}
+		}
+
+		if q > preferredQ {
+			preferred = strings.ToLower(strings.TrimSpace(mediaType))
+			preferredQ = q
+		}
+	}
+
+	return preferred == "application/xml" || preferred == "text/xml"
+}
+
+// EncodeXML writes the XML representation of data (wrapped in a [XMLRootName] element) to w.
+//
+// The data is normalized via its JSON representation, where:
+//   - the object keys are mapped to elements with the same name (sorted alphabetically),
+//     or to "<field name="key">" elements if the key is not a valid XML name
+//   - the array values are mapped to repeated "<item>" elements
+//   - the null values are mapped to empty elements with nil="true" attribute
+//   - the strings, numbers and booleans are mapped to the element text
+func EncodeXML(w io.Writer, data any, indent string) error {
+	raw, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+
+	var normalized any
+
+	decoder := json.NewDecoder(bytes.NewReader(raw))
+	decoder.UseNumber()
+	if err := decoder.Decode(&normalized); err != nil {
+		return err
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+
+	enc := xml.NewEncoder(w)
+	enc.Indent("", indent)
+
+	if err := encodeXMLElement(enc, XMLRootName, normalized); err != nil {
+		return err
+	}
+
+	return enc.Flush()
+}
+
+func encodeXMLElement(enc *xml.Encoder, name string, value any) error {
+	start := xml.StartElement{Name: xml.Name{Local: name}}
+	if !xmlNameRegex.MatchString(name) || strings.HasPrefix(strings.ToLower(name), "xml") {
+		start = xml.StartElement{
+			Name: xml.Name{Local: "field"},
+			Attr: []xml.Attr{{Name: xml.Name{Local: "name"}, Value: name}},
+		}
+	}
+
+	if value == nil {
+		start.Attr = append(start.Attr, xml.Attr{Name: xml.Name{Local: "nil"}, Value: "true"})
+	}
+
+	if err := enc.EncodeToken(start); err != nil {
+		return err
+	}
+
+	switch v := value.(type) {
+	case nil:
+		// empty element
+	case map[string]any:
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+
+		for _, k := range keys {
+			if err := encodeXMLElement(enc, k, v[k]); err != nil {
+				return err
+			}
+		}
+	case []any:
+		for _, item := range v {
+			if err := encodeXMLElement(enc, xmlItemName, item); err != nil {
+				return err
+			}
+		}
+	case string:
+		if err := enc.EncodeToken(xml.CharData(v)); err != nil {
+			return err
+		}
+	case json.Number:
+		if err := enc.EncodeToken(xml.CharData(v.String())); err != nil {
+			return err
+		}
+	case bool:
+		if err := enc.EncodeToken(xml.CharData(strconv.FormatBool(v))); err != nil {
+			return err
+		}
+	}
+
+	return enc.EncodeToken(start.End())
+}
@@ -0,0 +1,118 @@
+package rest_test
+
+import (
+	"bytes"
+	"encoding/xml"
+	"testing"
+
+	"github.com/unkod/space/tools/rest"
+)
+
+func TestPrefersXML(t *testing.T) {
+	scenarios := []struct {
+		accept   string
+		expected bool
+	}{
+		{"", false},
+		{"*/*", false},
+		{"application/json", false},
+		{"application/xml", true},
+		{"text/xml", true},
+		{"Application/XML; charset=utf-8", true},
+		{"application/json, application/xml", false},
+		{"application/xml, application/json", true},
+		{"application/json;q=0.5, application/xml", true},
+		{"application/xml;q=0.5, application/json", false},
+		{"application/xml;q=0, */*", false},
+		{"text/html,application/xhtml+xml,application/xml;q=0.9,*/*;q=0.8", false},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.accept, func(t *testing.T) {
+			result := rest.PrefersXML(s.accept)
+			if result != s.expected {
+				t.Fatalf("Expected %v, got %v", s.expected, result)
+			}
+		})
+	}
+}
+
+func TestEncodeXML(t *testing.T) {
+	scenarios := []struct {
+		name     string
+		data     any
+		indent   string
+		expected string
+	}{
+		{
+			"nil",
+			nil,
+			"",
+			`<response nil="true"></response>`,
+		},
+		{
+			"scalar",
+			"a<b>&c",
+			"",
+			`<response>a&lt;b&gt;&amp;c</response>`,
+		},
+		{
+			"array",
+			[]any{1, 2.5, true, "test", nil},
+			"",
+			`<response><item>1</item><item>2.5</item><item>true</item><item>test</item><item nil="true"></item></response>`,
+		},
+		{
+			"nested objects and arrays",
+			map[string]any{
+				"id":    "test",
+				"total": 123456789012,
+				"expand": map[string]any{
+					"rel":  map[string]any{"id": "a"},
+					"rels": []any{map[string]any{"id": "b"}, map[string]any{"id": "c"}},
+				},
+				"tags": []string{},
+			},
+			"",
+			`<response><expand><rel><id>a</id></rel><rels><item><id>b</id></item><item><id>c</id></item></rels></expand><id>test</id><tags></tags><total>123456789012</total></response>`,
+		},
+		{
+			"invalid xml names",
+			map[string]any{"1a": 1, "a b": 2, "xmlTest": 3, "@c": 4, "ok_name-1.2": 5},
+			"",
+			`<response><field name="1a">1</field><field name="@c">4</field><field name="a b">2</field><ok_name-1.2>5</ok_name-1.2><field name="xmlTest">3</field></response>`,
+		},
+		{
+			"indent",
+			map[string]any{"a": []any{1}},
+			"  ",
+			"<response>\n  <a>\n    <item>1</item>\n  </a>\n</response>",
+		},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			var buf bytes.Buffer
+
+			if err := rest.EncodeXML(&buf, s.data, s.indent); err != nil {
+				t.Fatal(err)
+			}
+
+			result := buf.String()
+
+			if !bytes.HasPrefix(buf.Bytes(), []byte(xml.Header)) {
+				t.Fatalf("Expected the XML header, got\n%s", result)
+			}
+
+			if body := result[len(xml.Header):]; body != s.expected {
+				t.Fatalf("Expected\n%s\ngot\n%s", s.expected, result[len(xml.Header):])
+			}
+
+			// ensure that the result is a well-formed xml
+			var v struct{}
+			if err := xml.Unmarshal([]byte(result), &v); err != nil {
+				t.Fatalf("Invalid XML: %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,181 @@
+package search
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/pocketbase/dbx"
+)
+
+// ErrInvalidCursor is returned when the provided pagination cursor
+// is malformed or it was generated for a different sort order.
+var ErrInvalidCursor = errors.New("invalid or mismatched pagination cursor")
+
+// cursorData defines the payload of a single encoded pagination cursor.
+type cursorData struct {
+	// Sort is the normalized sort signature that was used
+	// when the cursor was generated (eg. "-created,id").
+	Sort string `json:"s"`
+
+	// Values contains the sort key values of the last page item
+	// (the last one is always the tie-breaker column value).
+	Values []any `json:"v"`
+}
+
+// encodeCursor encodes the provided cursor data into an opaque url safe token.
+func encodeCursor(data *cursorData) (string, error) {
+	raw, err := json.Marshal(data)
+	if err != nil {
+		return "", err
+	}
+
+	return base64.RawURLEncoding.EncodeToString(raw), nil
+}
+
+// decodeCursor decodes an opaque cursor token generated with encodeCursor.
+func decodeCursor(token string) (*cursorData, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(raw))
+	decoder.UseNumber()
+
+	data := &cursorData{}
+	if err := decoder.Decode(data); err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	// normalize the json numbers
+	for i, v := range data.Values {
+		n, ok := v.(json.Number)
+		if !ok {
+			continue
+		}
+		if intVal, err := n.Int64(); err == nil {
+			data.Values[i] = intVal
+		} else if floatVal, err := n.Float64(); err == nil {
+			data.Values[i] = floatVal
+		} else {
+			return nil, ErrInvalidCursor
+		}
+	}
+
+	return data, nil
+}
+
+// cursorSortSignature returns a normalized string representation
+// of the provided sort fields (eg. "-created,id").
+func cursorSortSignature(fields []SortField) string {
+	parts := make([]string, len(fields))
+
+	for i, f := range fields {
+		if f.Direction == SortDesc {
+			parts[i] = "-" + f.Name
+		} else {
+			parts[i] = f.Name
+		}
+	}
+
+	return strings.Join(parts, ",")
+}
+
+// buildCursorExpr builds a keyset WHERE expression that matches
+// all rows positioned after the provided sort key values, eg.:
+//
+//	(a > {:a}) OR (a = {:a} AND b > {:b}) OR (a = {:a} AND b = {:b} AND id > {:id})
+//
+// NULL sort values are compared following the SQLite ordering rules,
+// aka. NULLs are positioned before any other value in ASC order
+// and after any other value in DESC order.
+func buildCursorExpr(fields []SortField, identifiers []string, values []any) dbx.Expression {
+	params := make(dbx.Params, len(values))
+	conditions := make([]string, 0, len(identifiers))
+
+	for i, identifier := range identifiers {
+		param := fmt.Sprintf("cursor%d", i)
+		params[param] = values[i]
+
+		after := cursorAfterCondition(identifier, param, fields[i].Direction, values[i] == nil)
+		if after == "" {
+			continue // there are no rows positioned after the current value
+		}
+
+		parts := make([]string, 0, i+1)
+		for j := 0; j < i; j++ {
+			if values[j] == nil {
+				parts = append(parts, identifiers[j]+" IS NULL")
+			} else {
+				parts = append(parts, fmt.Sprintf("%s = {:cursor%d}", identifiers[j], j))
+			}
+		}
+		parts = append(parts, after)
+
+		conditions = append(conditions, "("+strings.Join(parts, " AND ")+")")
+	}
+
+	if len(conditions) == 0 {
+		return dbx.NewExp("1=0")
+	}
+
+	return dbx.NewExp("("+strings.Join(conditions, " OR ")+")", params)
+}
+
+// cursorAfterCondition returns the condition that matches the identifier
+// values positioned after the cursor param value in the specified direction.
+//
+// Returns an empty string if no value could be positioned after it
+// (eg. a NULL cursor value in DESC order).
+func cursorAfterCondition(identifier string, param string, direction string, isNull bool) string {
+	if direction == SortDesc {
+		if isNull {
+			return ""
+		}
+
+		return fmt.Sprintf("(%s < {:%s} OR %s IS NULL)", identifier, param, identifier)
+	}
+
+	if isNull {
+		return identifier + " IS NOT NULL"
+	}
+
+	return fmt.Sprintf("%s > {:%s}", identifier, param)
+}
+
+// lastItemId returns the id of the last element of the provided
+// pointer to a slice of models.
+//
+// The slice elements are expected to implement `GetId() string`.
+func lastItemId(items any) (id string, total int, err error) {
+	rv := reflect.ValueOf(items)
+	for rv.Kind() == reflect.Pointer {
+		rv = rv.Elem()
+	}
+
+	if rv.Kind() != reflect.Slice {
+		return "", 0, errors.New("cursor pagination expects a pointer to a slice of items")
+	}
+
+	total = rv.Len()
+	if total == 0 {
+		return "", 0, nil
+	}
+
+	last := rv.Index(total - 1)
+	if last.Kind() != reflect.Pointer && last.CanAddr() {
+		last = last.Addr()
+	}
+
+	model, ok := last.Interface().(interface{ GetId() string })
+	if !ok {
+		return "", 0, errors.New("cursor pagination expects the items to implement GetId() string")
+	}
+
+	return model.GetId(), total, nil
+}
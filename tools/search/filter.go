@@ -19,6 +19,10 @@ import (
 // The filter string can also contain dbx placeholder parameters (eg. "title = {:name}"),
 // that will be safely replaced and properly quoted inplace with the placeholderReplacements values.
 //
+// In addition to the `fexpr` operators, the filter string can also contain
+// "in" and "not in" list operators (eg. "category in ['a', 'b', 1]") and
+// relative date macros (eg. "created > @now(-7d)" or "created > @daysAgo(7)").
+//
 // Example:
 //
 //	var filter FilterData = "id = null || (name = 'test' && status = true) || (total >= {:min} && total <= {:max})"
@@ -64,6 +68,8 @@ func (f FilterData) BuildExpr(
 		}
 	}
 
+	raw = normalizeModifierCalls(normalizeIndexAccessors(normalizeRelativeDateCalls(normalizeListOperators(raw))))
+
 	if parsedFilterData.Has(raw) {
 		return buildParsedFilterExpr(parsedFilterData.Get(raw), fieldResolver)
 	}
@@ -130,9 +136,58 @@ func resolveTokenizedExpr(expr fexpr.Expr, fieldResolver FieldResolver) (dbx.Exp
 		return nil, fmt.Errorf("invalid right operand %q - %v", expr.Right.Literal, rErr)
 	}
 
+	if lResult.EncryptValue != nil || rResult.EncryptValue != nil {
+		if err := encryptOperands(lResult, expr.Op, rResult); err != nil {
+			return nil, fmt.Errorf("invalid expression %s %s %s - %v", expr.Left.Literal, expr.Op, expr.Right.Literal, err)
+		}
+	}
+
 	return buildResolversExpr(lResult, expr.Op, rResult)
 }
 
+// encryptOperands encrypts the placeholder values of the operand
+// opposite to the encrypted one (see [ResolverResult.EncryptValue]).
+//
+// Only equal/not-equal comparisons with another encrypted
+// identifier or with plain placeholder values are allowed.
+func encryptOperands(left *ResolverResult, op fexpr.SignOp, right *ResolverResult) error {
+	switch op {
+	case fexpr.SignEq, fexpr.SignAnyEq, fexpr.SignNeq, fexpr.SignAnyNeq:
+	default:
+		return errors.New("encrypted fields support only exact-match (=, !=) comparisons")
+	}
+
+	// both operands are encrypted columns
+	if left.EncryptValue != nil && right.EncryptValue != nil {
+		return nil
+	}
+
+	encrypted, other := left, right
+	if encrypted.EncryptValue == nil {
+		encrypted, other = right, left
+	}
+
+	if len(other.Params) == 0 && !other.isList {
+		if isEmptyIdentifier(other) {
+			return nil // nothing to encrypt
+		}
+
+		return errors.New("encrypted fields could be compared only with plain values or other encrypted fields")
+	}
+
+	params := make(dbx.Params, len(other.Params))
+	for k, v := range other.Params {
+		value, err := encrypted.EncryptValue(v)
+		if err != nil {
+			return err
+		}
+		params[k] = value
+	}
+	other.Params = params
+
+	return nil
+}
+
 func buildResolversExpr(
 	left *ResolverResult,
 	op fexpr.SignOp,
@@ -140,32 +195,51 @@ func buildResolversExpr(
 ) (dbx.Expression, error) {
 	var expr dbx.Expression
 
-	switch op {
-	case fexpr.SignEq, fexpr.SignAnyEq:
+	if left.isList || right.isList {
+		var err error
+
+		switch op {
+		case fexpr.SignEq, fexpr.SignAnyEq:
+			expr, err = resolveListExpr(true, left, right)
+		case fexpr.SignNeq, fexpr.SignAnyNeq:
+			expr, err = resolveListExpr(false, left, right)
+		default:
+			err = errors.New("the list operand could be used only with the in and not in operators")
+		}
+
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	switch {
+	case expr != nil:
+		// already resolved list expression
+	case op == fexpr.SignEq || op == fexpr.SignAnyEq:
 		expr = resolveEqualExpr(true, left, right)
-	case fexpr.SignNeq, fexpr.SignAnyNeq:
+	case op == fexpr.SignNeq || op == fexpr.SignAnyNeq:
 		expr = resolveEqualExpr(false, left, right)
-	case fexpr.SignLike, fexpr.SignAnyLike:
+	case op == fexpr.SignLike || op == fexpr.SignAnyLike:
 		// the right side is a column and therefor wrap it with "%" for contains like behavior
 		if len(right.Params) == 0 {
 			expr = dbx.NewExp(fmt.Sprintf("%s LIKE ('%%' || %s || '%%') ESCAPE '\\'", left.Identifier, right.Identifier), left.Params)
 		} else {
 			expr = dbx.NewExp(fmt.Sprintf("%s LIKE %s ESCAPE '\\'", left.Identifier, right.Identifier), mergeParams(left.Params, wrapLikeParams(right.Params)))
 		}
-	case fexpr.SignNlike, fexpr.SignAnyNlike:
+	case op == fexpr.SignNlike || op == fexpr.SignAnyNlike:
 		// the right side is a column and therefor wrap it with "%" for not-contains like behavior
 		if len(right.Params) == 0 {
 			expr = dbx.NewExp(fmt.Sprintf("%s NOT LIKE ('%%' || %s || '%%') ESCAPE '\\'", left.Identifier, right.Identifier), left.Params)
 		} else {
 			expr = dbx.NewExp(fmt.Sprintf("%s NOT LIKE %s ESCAPE '\\'", left.Identifier, right.Identifier), mergeParams(left.Params, wrapLikeParams(right.Params)))
 		}
-	case fexpr.SignLt, fexpr.SignAnyLt:
+	case op == fexpr.SignLt || op == fexpr.SignAnyLt:
 		expr = dbx.NewExp(fmt.Sprintf("%s < %s", left.Identifier, right.Identifier), mergeParams(left.Params, right.Params))
-	case fexpr.SignLte, fexpr.SignAnyLte:
+	case op == fexpr.SignLte || op == fexpr.SignAnyLte:
 		expr = dbx.NewExp(fmt.Sprintf("%s <= %s", left.Identifier, right.Identifier), mergeParams(left.Params, right.Params))
-	case fexpr.SignGt, fexpr.SignAnyGt:
+	case op == fexpr.SignGt || op == fexpr.SignAnyGt:
 		expr = dbx.NewExp(fmt.Sprintf("%s > %s", left.Identifier, right.Identifier), mergeParams(left.Params, right.Params))
-	case fexpr.SignGte, fexpr.SignAnyGte:
+	case op == fexpr.SignGte || op == fexpr.SignAnyGte:
 		expr = dbx.NewExp(fmt.Sprintf("%s >= %s", left.Identifier, right.Identifier), mergeParams(left.Params, right.Params))
 	}
 
@@ -233,6 +307,19 @@ func resolveToken(token fexpr.Token, fieldResolver FieldResolver) (*ResolverResu
 			}, nil
 		}
 
+		if macroValue, ok, err := resolveRelativeDateMacro(token.Literal); ok {
+			if err != nil {
+				return nil, err
+			}
+
+			placeholder := "t" + security.PseudorandomString(5)
+
+			return &ResolverResult{
+				Identifier: "{:" + placeholder + "}",
+				Params:     dbx.Params{placeholder: macroValue},
+			}, nil
+		}
+
 		// custom resolver
 		// ---
 		result, err := fieldResolver.Resolve(token.Literal)
@@ -254,6 +341,10 @@ func resolveToken(token fexpr.Token, fieldResolver FieldResolver) (*ResolverResu
 
 		return result, err
 	case fexpr.TokenText:
+		if result, ok, err := resolveListLiteral(token.Literal); ok {
+			return result, err
+		}
+
 		placeholder := "t" + security.PseudorandomString(5)
 
 		return &ResolverResult{
@@ -570,6 +661,7 @@ func (e *manyVsOneExpr) Build(db *dbx.DB, params dbx.Params) string {
 	r2 := &ResolverResult{
 		Identifier: e.otherOperand.Identifier,
 		Params:     e.otherOperand.Params,
+		isList:     e.otherOperand.isList,
 	}
 
 	var whereExpr dbx.Expression
@@ -9,6 +9,7 @@ import (
 	"time"
 
 	"github.com/pocketbase/dbx"
+	"github.com/spf13/cast"
 	"github.com/unkod/space/tools/search"
 )
 
@@ -114,6 +115,24 @@ func TestFilterDataBuildExpr(t *testing.T) {
 			false,
 			"([[test4.1]] > {:TEST} AND [[test4.2]] > {:TEST} AND [[test4.3]] > {:TEST} AND [[test4.4]] > {:TEST} AND [[test4.5]] > {:TEST} AND [[test4.6]] > {:TEST} AND [[test4.7]] > {:TEST} AND [[test4.9]] > {:TEST} AND [[test4.9]] > {:TEST} AND [[test4.10]] > {:TEST} AND [[test4.11]] > {:TEST} AND [[test4.12]] > {:TEST} AND [[test4.13]] > {:TEST} AND [[test4.14]] > {:TEST})",
 		},
+		{
+			"relative date macros",
+			`test4.1 > @now(-7d) && test4.2 <= @now( +1d12h ) && test4.3 > @daysAgo(30) && test4.4 = "@now(-1d)"`,
+			false,
+			"([[test4.1]] > {:TEST} AND [[test4.2]] <= {:TEST} AND [[test4.3]] > {:TEST} AND [[test4.4]] = {:TEST})",
+		},
+		{
+			"relative date macro with invalid unit",
+			"test1 > @now(-7y)",
+			true,
+			"",
+		},
+		{
+			"relative date macro with too large offset",
+			"test1 > @now(-100000d)",
+			true,
+			"",
+		},
 		{
 			"complex expression",
 			"((test1 > 1) || (test2 != 2)) && test3 ~ '%%example' && test4.sub = null",
@@ -232,3 +251,81 @@ func TestFilterDataBuildExprWithParams(t *testing.T) {
 		t.Fatalf("Expected query \n%s, \ngot \n%s", expectedQuery, calledQueries[0])
 	}
 }
+
+// encryptedTestResolver is a test resolver that marks the "secret*" fields as encrypted.
+type encryptedTestResolver struct {
+	*search.SimpleFieldResolver
+}
+
+func (r *encryptedTestResolver) Resolve(field string) (*search.ResolverResult, error) {
+	result, err := r.SimpleFieldResolver.Resolve(field)
+	if err == nil && strings.HasPrefix(field, "secret") {
+		result.EncryptValue = func(value any) (any, error) {
+			if value == "" {
+				return value, nil
+			}
+			return "encrypted_" + cast.ToString(value), nil
+		}
+	}
+
+	return result, err
+}
+
+func TestFilterDataBuildExprWithEncryptedFields(t *testing.T) {
+	resolver := &encryptedTestResolver{search.NewSimpleFieldResolver(`^test\w*$`, `^secret\w*$`)}
+
+	scenarios := []struct {
+		filterData     search.FilterData
+		expectError    bool
+		expectedParams []any
+	}{
+		{"secret = 'abc'", false, []any{"encrypted_abc"}},
+		{"'abc' != secret", false, []any{"encrypted_abc"}},
+		{"secret ?= 123", false, []any{"encrypted_123"}},
+		{"secret = ''", false, []any{""}},
+		{"secret = null", false, nil},
+		{"secret1 = secret2", false, nil},
+		{"secret in ['abc', 123]", false, []any{"encrypted_abc", "encrypted_123"}},
+		{"secret not in []", false, nil},
+		{"test1 = 'abc' && secret = 'abc'", false, []any{"abc", "encrypted_abc"}},
+		{"secret ~ 'abc'", true, nil},
+		{"secret > 'abc'", true, nil},
+		{"secret ?<= 'abc'", true, nil},
+		{"secret = test1", true, nil},
+	}
+
+	for _, s := range scenarios {
+		t.Run(string(s.filterData), func(t *testing.T) {
+			expr, err := s.filterData.BuildExpr(resolver)
+
+			hasErr := err != nil
+			if hasErr != s.expectError {
+				t.Fatalf("Expected hasErr %v, got %v (%v)", s.expectError, hasErr, err)
+			}
+
+			if hasErr {
+				return
+			}
+
+			params := dbx.Params{}
+			expr.Build(&dbx.DB{}, params)
+
+			if len(params) != len(s.expectedParams) {
+				t.Fatalf("Expected %d params, got %v", len(s.expectedParams), params)
+			}
+
+			for _, expected := range s.expectedParams {
+				var exists bool
+				for _, v := range params {
+					if v == expected {
+						exists = true
+						break
+					}
+				}
+				if !exists {
+					t.Fatalf("Missing expected param value %v in %v", expected, params)
+				}
+			}
+		})
+	}
+}
@@ -1,7 +1,11 @@
 package search
 
 import (
+	"errors"
 	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/unkod/space/tools/types"
@@ -113,3 +117,113 @@ var identifierMacros = map[string]func() (any, error){
 		return d.String(), nil
 	},
 }
+
+// maxRelativeDateOffset is the max allowed absolute offset of the relative date macros.
+const maxRelativeDateOffset = 100 * 365 * 24 * time.Hour
+
+// relativeDateCallRegex matches either a quoted text literal or a single
+// "@now(offset)" or "@daysAgo(days)" relative date call expression.
+var relativeDateCallRegex = regexp.MustCompile(`'(?:[^'\\]|\\.)*'|"(?:[^"\\]|\\.)*"|@(now|daysAgo)\(\s*([^()]*?)\s*\)`)
+
+// relativeDateOffsetRegex matches a single relative date offset (eg. -7d, 1d12h, +30m).
+var relativeDateOffsetRegex = regexp.MustCompile(`^[-+]?(\d+[smhd])+$`)
+
+// relativeDateMacroRegex matches a single normalized relative date identifier (eg. @now:n7d).
+var relativeDateMacroRegex = regexp.MustCompile(`^@now:(n?)((?:\d+[smhd])+)$`)
+
+// relativeDateUnitRegex matches a single relative date offset unit part (eg. 12h).
+var relativeDateUnitRegex = regexp.MustCompile(`(\d+)([smhd])`)
+
+// normalizeRelativeDateCalls rewrites the relative date calls of the provided
+// filter expression into their fexpr compatible identifier form, eg.:
+//
+//	@now(-7d)    -> @now:n7d
+//	@now(+1d12h) -> @now:1d12h
+//	@daysAgo(7)  -> @now:n7d
+//
+// The supported offset units are "s" (seconds), "m" (minutes), "h" (hours) and "d" (days).
+//
+// Quoted text literals and malformed calls are left unmodified.
+func normalizeRelativeDateCalls(expr string) string {
+	if !strings.Contains(expr, "@") {
+		return expr // nothing to normalize
+	}
+
+	return relativeDateCallRegex.ReplaceAllStringFunc(expr, func(match string) string {
+		parts := relativeDateCallRegex.FindStringSubmatch(match)
+		if parts[1] == "" {
+			return match // quoted text
+		}
+
+		offset := parts[2]
+
+		if parts[1] == "daysAgo" {
+			if _, err := strconv.ParseUint(offset, 10, 32); err != nil {
+				return match
+			}
+			offset = "-" + offset + "d"
+		}
+
+		if !relativeDateOffsetRegex.MatchString(offset) {
+			return match
+		}
+
+		offset = strings.TrimPrefix(offset, "+")
+		offset = strings.Replace(offset, "-", "n", 1)
+
+		return "@now:" + offset
+	})
+}
+
+// resolveRelativeDateMacro resolves a single normalized relative date
+// identifier (eg. @now:n7d) into its current datetime string value.
+//
+// Returns false if the identifier is not a relative date macro.
+func resolveRelativeDateMacro(identifier string) (any, bool, error) {
+	parts := relativeDateMacroRegex.FindStringSubmatch(identifier)
+	if parts == nil {
+		return nil, false, nil
+	}
+
+	var offset time.Duration
+
+	for _, unitParts := range relativeDateUnitRegex.FindAllStringSubmatch(parts[2], -1) {
+		value, err := strconv.ParseInt(unitParts[1], 10, 64)
+		if err != nil {
+			return nil, true, fmt.Errorf("@now: invalid offset %q", unitParts[0])
+		}
+
+		var unit time.Duration
+		switch unitParts[2] {
+		case "s":
+			unit = time.Second
+		case "m":
+			unit = time.Minute
+		case "h":
+			unit = time.Hour
+		default:
+			unit = 24 * time.Hour
+		}
+
+		if value > int64(maxRelativeDateOffset/unit) {
+			return nil, true, errors.New("@now: the offset is too large")
+		}
+
+		offset += time.Duration(value) * unit
+
+		if offset > maxRelativeDateOffset {
+			return nil, true, errors.New("@now: the offset is too large")
+		}
+	}
+
+	if parts[1] == "n" {
+		offset = -offset
+	}
+
+	d, err := types.ParseDateTime(timeNow().UTC().Add(offset))
+	if err != nil {
+		return nil, true, fmt.Errorf("@now: %w", err)
+	}
+
+	return d.String(), true, nil
+}
@@ -54,3 +54,91 @@ func TestIdentifierMacros(t *testing.T) {
 	// restore
 	timeNow = originalTimeNow
 }
+
+func TestNormalizeRelativeDateCalls(t *testing.T) {
+	scenarios := []struct {
+		expr     string
+		expected string
+	}{
+		{"", ""},
+		{"created > @now", "created > @now"},
+		{"created > @now(-7d)", "created > @now:n7d"},
+		{"created > @now( +1d12h )", "created > @now:1d12h"},
+		{"created > @now(30m) && updated < @now(-10s)", "created > @now:30m && updated < @now:n10s"},
+		{"created > @daysAgo(7)", "created > @now:n7d"},
+		{"created > @daysAgo( 0 )", "created > @now:n0d"},
+		// quoted text
+		{`title = "@now(-7d)" && created > @now(1h)`, `title = "@now(-7d)" && created > @now:1h`},
+		{`title = '@daysAgo(7)'`, `title = '@daysAgo(7)'`},
+		// malformed calls
+		{"created > @now()", "created > @now()"},
+		{"created > @now(-7)", "created > @now(-7)"},
+		{"created > @now(-7y)", "created > @now(-7y)"},
+		{"created > @now(1.5h)", "created > @now(1.5h)"},
+		{"created > @now(--7d)", "created > @now(--7d)"},
+		{"created > @daysAgo(-7)", "created > @daysAgo(-7)"},
+		{"created > @daysAgo(7d)", "created > @daysAgo(7d)"},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.expr, func(t *testing.T) {
+			result := normalizeRelativeDateCalls(s.expr)
+			if result != s.expected {
+				t.Fatalf("Expected %q, got %q", s.expected, result)
+			}
+		})
+	}
+}
+
+func TestResolveRelativeDateMacro(t *testing.T) {
+	originalTimeNow := timeNow
+	defer func() {
+		timeNow = originalTimeNow
+	}()
+
+	timeNow = func() time.Time {
+		return time.Date(2023, 2, 3, 4, 5, 6, 7, time.UTC)
+	}
+
+	scenarios := []struct {
+		identifier    string
+		expectMacro   bool
+		expectError   bool
+		expectedValue any
+	}{
+		{"@now", false, false, nil},
+		{"@now:", false, false, nil},
+		{"@now:7", false, false, nil},
+		{"@now:7y", false, false, nil},
+		{"@other:n7d", false, false, nil},
+		{"@now:n7d", true, false, "2023-01-27 04:05:06.000Z"},
+		{"@now:7d", true, false, "2023-02-10 04:05:06.000Z"},
+		{"@now:n0d", true, false, "2023-02-03 04:05:06.000Z"},
+		{"@now:1d12h", true, false, "2023-02-04 16:05:06.000Z"},
+		{"@now:n30m", true, false, "2023-02-03 03:35:06.000Z"},
+		{"@now:n6s", true, false, "2023-02-03 04:05:00.000Z"},
+		{"@now:36500d", true, false, "2123-01-10 04:05:06.000Z"},
+		{"@now:36501d", true, true, nil},
+		{"@now:n36500d1s", true, true, nil},
+		{"@now:n99999999999999999999d", true, true, nil},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.identifier, func(t *testing.T) {
+			value, isMacro, err := resolveRelativeDateMacro(s.identifier)
+
+			if isMacro != s.expectMacro {
+				t.Fatalf("Expected isMacro %v, got %v", s.expectMacro, isMacro)
+			}
+
+			hasErr := err != nil
+			if hasErr != s.expectError {
+				t.Fatalf("Expected hasErr %v, got %v (%v)", s.expectError, hasErr, err)
+			}
+
+			if value != s.expectedValue {
+				t.Fatalf("Expected %v, got %v", s.expectedValue, value)
+			}
+		})
+	}
+}
@@ -0,0 +1,35 @@
+package search
+
+import (
+	"regexp"
+	"strings"
+)
+
+// indexAccessorRegex matches either a quoted text literal or
+// an identifier followed by one or more "[N]" index accessors.
+var indexAccessorRegex = regexp.MustCompile(`'(?:[^'\\]|\\.)*'|"(?:[^"\\]|\\.)*"|(@?[\w\.\:]+)((?:\[\s*\d+\s*\])+)`)
+
+// indexAccessorPartRegex matches a single "[N]" index accessor.
+var indexAccessorPartRegex = regexp.MustCompile(`\[\s*(\d+)\s*\]`)
+
+// normalizeIndexAccessors rewrites the identifier array index accessors
+// of the provided filter or sort expression into their fexpr
+// compatible dot notation form, eg.:
+//
+//	metadata.tags[0].name -> metadata.tags.0.name
+//
+// Quoted text literals are left unmodified.
+func normalizeIndexAccessors(expr string) string {
+	if !strings.Contains(expr, "[") {
+		return expr // nothing to normalize
+	}
+
+	return indexAccessorRegex.ReplaceAllStringFunc(expr, func(match string) string {
+		parts := indexAccessorRegex.FindStringSubmatch(match)
+		if parts[1] == "" {
+			return match // quoted text
+		}
+
+		return parts[1] + indexAccessorPartRegex.ReplaceAllString(parts[2], ".$1")
+	})
+}
@@ -0,0 +1,73 @@
+package search_test
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/pocketbase/dbx"
+	"github.com/unkod/space/tools/search"
+)
+
+func TestIndexAccessorsSortNormalization(t *testing.T) {
+	scenarios := []struct {
+		value        string
+		expectedJson string
+	}{
+		{"json.tags", `[{"name":"json.tags","direction":"ASC"}]`},
+		{"-json.tags[0]", `[{"name":"json.tags.0","direction":"DESC"}]`},
+		{"json.a[1][ 2 ].b,created", `[{"name":"json.a.1.2.b","direction":"ASC"},{"name":"created","direction":"ASC"}]`},
+		// non-numeric indexes are not normalized
+		{"json.tags[a]", `[{"name":"json.tags[a]","direction":"ASC"}]`},
+	}
+
+	for i, s := range scenarios {
+		result := search.ParseSortFromString(s.value)
+		encoded, _ := json.Marshal(result)
+
+		if string(encoded) != s.expectedJson {
+			t.Errorf("(%d) Expected expression %v, got %v", i, s.expectedJson, string(encoded))
+		}
+	}
+}
+
+func TestIndexAccessorsFilterNormalization(t *testing.T) {
+	resolver := search.NewSimpleFieldResolver(`^json\.[\w\.]+$`, "title")
+
+	scenarios := []struct {
+		filterData  search.FilterData
+		expectError bool
+		expectSql   string
+	}{
+		{"json.tags[0] = 1", false, "[[json.tags.0]] = {:TEST}"},
+		{"json.a[0][1].b > 1 && title = 'json.a[2]'", false, "([[json.a.0.1.b]] > {:TEST} AND [[title]] = {:TEST})"},
+		{"json.tags[a] = 1", true, ""},
+		{"json.tags[-1] = 1", true, ""},
+	}
+
+	for i, s := range scenarios {
+		expr, err := s.filterData.BuildExpr(resolver)
+
+		hasErr := err != nil
+		if hasErr != s.expectError {
+			t.Errorf("(%d) Expected hasErr %v, got %v (%v)", i, s.expectError, hasErr, err)
+			continue
+		}
+
+		if hasErr {
+			continue
+		}
+
+		params := dbx.Params{}
+		rawSql := expr.Build(&dbx.DB{}, params)
+
+		// normalize the random placeholder names
+		for k := range params {
+			rawSql = strings.ReplaceAll(rawSql, "{:"+k+"}", "{:TEST}")
+		}
+
+		if rawSql != s.expectSql {
+			t.Errorf("(%d) Expected \n%v, \ngot \n%v", i, s.expectSql, rawSql)
+		}
+	}
+}
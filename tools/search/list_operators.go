@@ -0,0 +1,261 @@
+package search
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/pocketbase/dbx"
+	"github.com/spf13/cast"
+	"github.com/unkod/space/tools/security"
+)
+
+// MaxFilterListItems is the max number of the "in" and "not in" list operands items
+// (it is there to keep the total query params bellow the SQLite variables limit).
+const MaxFilterListItems = 10000
+
+// listLiteralPrefix is the prefix of the normalized list operand text literals.
+const listLiteralPrefix = "\x1flist:"
+
+// listOperatorRegex matches the start of a single "in", "not in", "?in"
+// or "?not in" list operator followed by the list opening bracket.
+var listOperatorRegex = regexp.MustCompile(`^(\?)?(not\s+)?in\s*\[`)
+
+// listNumberRegex matches a single numeric list item.
+var listNumberRegex = regexp.MustCompile(`^[-+]?\d+(\.\d+)?`)
+
+// normalizeListOperators rewrites the "in" and "not in" list operators of the
+// provided filter expression into their fexpr compatible comparison form
+// where the list is encoded as a single special text literal, eg.:
+//
+//	category in ["a", "b", 1] -> category = "\x1flist:WyJhIiwiYiIsMV0"
+//	category not in ["a", "b"] -> category != "\x1flist:WyJhIiwiYiJd"
+//
+// Similar to the other operators, the "?in" and "?not in" variants
+// are rewritten to the any-match "?=" and "?!=" operators.
+//
+// Quoted text literals and malformed lists are left unmodified.
+func normalizeListOperators(expr string) string {
+	if !strings.Contains(expr, "[") {
+		return expr // nothing to normalize
+	}
+
+	var result strings.Builder
+
+	for i := 0; i < len(expr); {
+		ch := expr[i]
+
+		// quoted text
+		if ch == '"' || ch == '\'' {
+			end := skipQuotedText(expr, i)
+			result.WriteString(expr[i:end])
+			i = end
+			continue
+		}
+
+		// (the any-match "?" prefix could be also directly after the left operand)
+		if ch != '?' && (i == 0 || !isListOperatorBoundary(expr[i-1])) {
+			result.WriteByte(ch)
+			i++
+			continue
+		}
+
+		loc := listOperatorRegex.FindStringSubmatchIndex(expr[i:])
+		if loc == nil {
+			result.WriteByte(ch)
+			i++
+			continue
+		}
+
+		items, end, ok := parseListItems(expr, i+loc[1])
+		if !ok {
+			result.WriteByte(ch)
+			i++
+			continue
+		}
+
+		op := "="
+		if loc[4] != -1 {
+			op = "!="
+		}
+		if loc[2] != -1 {
+			op = "?" + op
+		}
+
+		encoded, _ := json.Marshal(items)
+
+		result.WriteString(op)
+		result.WriteString(` "`)
+		result.WriteString(listLiteralPrefix)
+		result.WriteString(base64.RawURLEncoding.EncodeToString(encoded))
+		result.WriteString(`"`)
+
+		i = end
+	}
+
+	return result.String()
+}
+
+// isListOperatorBoundary checks whether the char could precede a list operator.
+func isListOperatorBoundary(ch byte) bool {
+	switch ch {
+	case ' ', '\t', '\n', '\r', ')', '"', '\'':
+		return true
+	}
+
+	return false
+}
+
+// skipQuotedText returns the position after the end of
+// the quoted text literal starting at the specified position.
+func skipQuotedText(expr string, start int) int {
+	quote := expr[start]
+
+	for i := start + 1; i < len(expr); i++ {
+		if expr[i] == quote && expr[i-1] != '\\' {
+			return i + 1
+		}
+	}
+
+	return len(expr)
+}
+
+// parseListItems parses the comma separated text and number list
+// items starting at the specified position (right after the opening bracket).
+//
+// Returns the parsed items and the position after the closing bracket.
+func parseListItems(expr string, start int) ([]any, int, bool) {
+	items := []any{}
+
+	i := skipSpaces(expr, start)
+	if i < len(expr) && expr[i] == ']' {
+		return items, i + 1, true // empty list
+	}
+
+	for i < len(expr) {
+		switch ch := expr[i]; {
+		case ch == '"' || ch == '\'':
+			end := skipQuotedText(expr, i)
+			if end > len(expr) || end-i < 2 || expr[end-1] != ch {
+				return nil, 0, false
+			}
+			text := expr[i+1 : end-1]
+			items = append(items, strings.ReplaceAll(text, `\`+string(ch), string(ch)))
+			i = end
+		default:
+			number := listNumberRegex.FindString(expr[i:])
+			if number == "" {
+				return nil, 0, false
+			}
+			items = append(items, cast.ToFloat64(number))
+			i += len(number)
+		}
+
+		i = skipSpaces(expr, i)
+		if i >= len(expr) {
+			return nil, 0, false
+		}
+
+		switch expr[i] {
+		case ']':
+			return items, i + 1, true
+		case ',':
+			i = skipSpaces(expr, i+1)
+		default:
+			return nil, 0, false
+		}
+	}
+
+	return nil, 0, false
+}
+
+func skipSpaces(expr string, start int) int {
+	for start < len(expr) && strings.ContainsRune(" \t\n\r", rune(expr[start])) {
+		start++
+	}
+
+	return start
+}
+
+// resolveListLiteral resolves a normalized list operand text literal
+// into a "({:p0}, {:p1}, ...)" placeholders list.
+//
+// Returns false if the literal is not a normalized list operand.
+func resolveListLiteral(literal string) (*ResolverResult, bool, error) {
+	raw, ok := strings.CutPrefix(literal, listLiteralPrefix)
+	if !ok {
+		return nil, false, nil
+	}
+
+	decoded, err := base64.RawURLEncoding.DecodeString(raw)
+	if err != nil {
+		return nil, true, err
+	}
+
+	items := []any{}
+	if err := json.Unmarshal(decoded, &items); err != nil {
+		return nil, true, err
+	}
+
+	if len(items) > MaxFilterListItems {
+		return nil, true, fmt.Errorf("the list operand must have no more than %d items", MaxFilterListItems)
+	}
+
+	prefix := "t" + security.PseudorandomString(5) + "_"
+
+	placeholders := make([]string, len(items))
+	params := make(dbx.Params, len(items))
+	for i, item := range items {
+		name := fmt.Sprintf("%s%d", prefix, i)
+		placeholders[i] = "{:" + name + "}"
+		params[name] = item
+	}
+
+	return &ResolverResult{
+		Identifier: "(" + strings.Join(placeholders, ", ") + ")",
+		Params:     params,
+		isList:     true,
+	}, true, nil
+}
+
+// resolveListExpr resolves "in" (aka. = with list operand)
+// and "not in" (aka. != with list operand) expressions.
+//
+// An empty list matches nothing for the "in" operator
+// and everything for the "not in" operator.
+func resolveListExpr(in bool, left, right *ResolverResult) (dbx.Expression, error) {
+	if left.isList {
+		return nil, errors.New("the list operand must be on the right side of the expression")
+	}
+
+	if len(right.Params) == 0 {
+		if in {
+			return dbx.NewExp("0=1", left.Params), nil
+		}
+		return dbx.NewExp("1=1", left.Params), nil
+	}
+
+	params := mergeParams(left.Params, right.Params)
+
+	// the list has an empty value and therefore NULL should be treated as ""
+	if hasEmptyParamValue(right) {
+		op := "IN"
+		if !in {
+			op = "NOT IN"
+		}
+
+		return dbx.NewExp(fmt.Sprintf("COALESCE(%s, '') %s %s", left.Identifier, op, right.Identifier), params), nil
+	}
+
+	if in {
+		return dbx.NewExp(fmt.Sprintf("%s IN %s", left.Identifier, right.Identifier), params), nil
+	}
+
+	return dbx.NewExp(
+		fmt.Sprintf("(%s NOT IN %s OR %s IS NULL)", left.Identifier, right.Identifier, left.Identifier),
+		params,
+	), nil
+}
@@ -0,0 +1,148 @@
+package search_test
+
+import (
+	"database/sql"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/pocketbase/dbx"
+	"github.com/unkod/space/tools/search"
+	_ "modernc.org/sqlite"
+)
+
+func TestListOperatorsFilterNormalization(t *testing.T) {
+	resolver := search.NewSimpleFieldResolver("id", "title", "total")
+
+	scenarios := []struct {
+		filterData  search.FilterData
+		expectError bool
+		expectSql   string
+	}{
+		// text list
+		{`title in ["a", 'b', "c\"d"]`, false, "[[title]] IN ({:TEST}, {:TEST}, {:TEST})"},
+		// numeric list
+		{"total in [1, -2.5,3]", false, "[[total]] IN ({:TEST}, {:TEST}, {:TEST})"},
+		// not in
+		{`title not in ["a", "b"]`, false, "(([[title]] NOT IN ({:TEST}, {:TEST}) OR [[title]] IS NULL))"},
+		// list with empty value
+		{`title in ["", "a"]`, false, "COALESCE([[title]], '') IN ({:TEST}, {:TEST})"},
+		{`title not in ["a", ""]`, false, "COALESCE([[title]], '') NOT IN ({:TEST}, {:TEST})"},
+		// any-match variants
+		{`title ?in ["a"]`, false, "[[title]] IN ({:TEST})"},
+		{`title?not in ["a"]`, false, "(([[title]] NOT IN ({:TEST}) OR [[title]] IS NULL))"},
+		// empty lists
+		{"title in []", false, "0=1"},
+		{"title not in [ ]", false, "1=1"},
+		// combined with other expressions
+		{`(id in ["1", "2"] || total > 1) && title != "in [1]"`, false, "(([[id]] IN ({:TEST}, {:TEST}) OR [[total]] > {:TEST}) AND [[title]] != {:TEST})"},
+		// list as left operand
+		{`["a"] in ["a"]`, true, ""},
+		// list with non-equal operator
+		{"title ~ \"\x1flist:WyJhIl0\"", true, ""},
+		// malformed lists
+		{`title in ["a", ]`, true, ""},
+		{`title in [a]`, true, ""},
+		{`title in ["a"`, true, ""},
+		// identifier ending with "in"
+		{`titlein ["a"]`, true, ""},
+	}
+
+	for i, s := range scenarios {
+		expr, err := s.filterData.BuildExpr(resolver)
+
+		hasErr := err != nil
+		if hasErr != s.expectError {
+			t.Errorf("(%d) Expected hasErr %v, got %v (%v)", i, s.expectError, hasErr, err)
+			continue
+		}
+
+		if hasErr {
+			continue
+		}
+
+		params := dbx.Params{}
+		rawSql := expr.Build(&dbx.DB{}, params)
+
+		// normalize the random placeholder names
+		for k := range params {
+			rawSql = strings.ReplaceAll(rawSql, "{:"+k+"}", "{:TEST}")
+		}
+
+		if rawSql != s.expectSql {
+			t.Errorf("(%d) Expected \n%v, \ngot \n%v", i, s.expectSql, rawSql)
+		}
+	}
+}
+
+func TestListOperatorsFilterExec(t *testing.T) {
+	sqlDB, err := sql.Open("sqlite", ":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	db := dbx.NewFromDB(sqlDB, "sqlite")
+	defer db.Close()
+
+	db.CreateTable("test", map[string]string{"id": "int", "title": "text"}).Execute()
+	db.Insert("test", dbx.Params{"id": 1, "title": "a"}).Execute()
+	db.Insert("test", dbx.Params{"id": 2, "title": "b"}).Execute()
+	db.Insert("test", dbx.Params{"id": 3, "title": nil}).Execute()
+
+	largeList := func(total int) string {
+		items := make([]string, total)
+		for i := range items {
+			items[i] = strconv.Itoa(i + 1)
+		}
+		return "[" + strings.Join(items, ",") + "]"
+	}
+
+	resolver := search.NewSimpleFieldResolver("id", "title")
+
+	scenarios := []struct {
+		filterData  search.FilterData
+		expectError bool
+		expectIds   []int
+	}{
+		{`title in ["a", "c"]`, false, []int{1}},
+		{`title not in ["a", "c"]`, false, []int{2, 3}},
+		{`title in ["", "b"]`, false, []int{2, 3}},
+		{`title not in ["", "b"]`, false, []int{1}},
+		{"id in [1, 3]", false, []int{1, 3}},
+		{"title in []", false, []int{}},
+		{"title not in []", false, []int{1, 2, 3}},
+		{search.FilterData("id in " + largeList(search.MaxFilterListItems)), false, []int{1, 2, 3}},
+		{search.FilterData("id in " + largeList(search.MaxFilterListItems+1)), true, nil},
+	}
+
+	for i, s := range scenarios {
+		expr, err := s.filterData.BuildExpr(resolver)
+
+		hasErr := err != nil
+		if hasErr != s.expectError {
+			t.Errorf("(%d) Expected hasErr %v, got %v (%v)", i, s.expectError, hasErr, err)
+			continue
+		}
+
+		if hasErr {
+			continue
+		}
+
+		ids := []int{}
+		if err := db.Select("id").From("test").Where(expr).OrderBy("id").Column(&ids); err != nil {
+			t.Errorf("(%d) Failed to execute the query: %v", i, err)
+			continue
+		}
+
+		if len(ids) != len(s.expectIds) {
+			t.Errorf("(%d) Expected ids %v, got %v", i, s.expectIds, ids)
+			continue
+		}
+
+		for j, id := range s.expectIds {
+			if ids[j] != id {
+				t.Errorf("(%d) Expected ids %v, got %v", i, s.expectIds, ids)
+				break
+			}
+		}
+	}
+}
@@ -0,0 +1,64 @@
+package search
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// modifierCallRegex matches either a quoted text literal or
+// a single "field:modifier(arg1, arg2, ...)" call expression.
+var modifierCallRegex = regexp.MustCompile(`'(?:[^'\\]|\\.)*'|"(?:[^"\\]|\\.)*"|(@?[\w\.]+:\w+)\(([^()]*)\)`)
+
+// numericArgRegex matches a plain decimal number (eg. -122.4).
+var numericArgRegex = regexp.MustCompile(`^[-+]?\d+(\.\d+)?$`)
+
+// normalizedArgRegex matches a single normalized numeric argument (eg. n122_4).
+var normalizedArgRegex = regexp.MustCompile(`^n?\d+(_\d+)?$`)
+
+// normalizeModifierCalls rewrites the numeric modifier calls of the provided
+// filter or sort expression into their fexpr compatible identifier form, eg.:
+//
+//	location:distance(37.7, -122.4) -> location:distance:37_7:n122_4
+//
+// Quoted text literals and calls with non-numeric arguments are left unmodified.
+func normalizeModifierCalls(expr string) string {
+	if !strings.Contains(expr, "(") {
+		return expr // nothing to normalize
+	}
+
+	return modifierCallRegex.ReplaceAllStringFunc(expr, func(match string) string {
+		parts := modifierCallRegex.FindStringSubmatch(match)
+		if parts[1] == "" {
+			return match // quoted text
+		}
+
+		args := strings.Split(parts[2], ",")
+		for i, arg := range args {
+			arg = strings.TrimSpace(arg)
+			if !numericArgRegex.MatchString(arg) {
+				return match
+			}
+			arg = strings.TrimPrefix(arg, "+")
+			arg = strings.Replace(arg, "-", "n", 1)
+			arg = strings.Replace(arg, ".", "_", 1)
+			args[i] = arg
+		}
+
+		return parts[1] + ":" + strings.Join(args, ":")
+	})
+}
+
+// ParseModifierArg parses a single numeric modifier argument
+// normalized by the filter and sort parsers (eg. "n122_4" -> -122.4).
+func ParseModifierArg(arg string) (float64, error) {
+	if !normalizedArgRegex.MatchString(arg) {
+		return 0, fmt.Errorf("invalid modifier argument %q", arg)
+	}
+
+	arg = strings.Replace(arg, "n", "-", 1)
+	arg = strings.Replace(arg, "_", ".", 1)
+
+	return strconv.ParseFloat(arg, 64)
+}
@@ -0,0 +1,108 @@
+package search_test
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/pocketbase/dbx"
+	"github.com/unkod/space/tools/search"
+)
+
+func TestParseModifierArg(t *testing.T) {
+	scenarios := []struct {
+		arg         string
+		expectError bool
+		expected    float64
+	}{
+		{"", true, 0},
+		{"abc", true, 0},
+		{"Inf", true, 0},
+		{"1e5", true, 0},
+		{"-1", true, 0},
+		{"1.5", true, 0},
+		{"n", true, 0},
+		{"0", false, 0},
+		{"123", false, 123},
+		{"37_7", false, 37.7},
+		{"n122_4", false, -122.4},
+	}
+
+	for i, s := range scenarios {
+		result, err := search.ParseModifierArg(s.arg)
+
+		hasErr := err != nil
+		if hasErr != s.expectError {
+			t.Errorf("(%d) Expected hasErr %v, got %v (%v)", i, s.expectError, hasErr, err)
+			continue
+		}
+
+		if result != s.expected {
+			t.Errorf("(%d) Expected %v, got %v", i, s.expected, result)
+		}
+	}
+}
+
+func TestModifierCallsSortNormalization(t *testing.T) {
+	scenarios := []struct {
+		value        string
+		expectedJson string
+	}{
+		{"location:distance", `[{"name":"location:distance","direction":"ASC"}]`},
+		{"-location:distance(37.7, -122.4)", `[{"name":"location:distance:37_7:n122_4","direction":"DESC"}]`},
+		{"test,location:distance(+1,2),-created", `[{"name":"test","direction":"ASC"},{"name":"location:distance:1:2","direction":"ASC"},{"name":"created","direction":"DESC"}]`},
+		// non-numeric args are not normalized
+		{"location:distance(a, 2)", `[{"name":"location:distance(a","direction":"ASC"},{"name":"2)","direction":"ASC"}]`},
+	}
+
+	for i, s := range scenarios {
+		result := search.ParseSortFromString(s.value)
+		encoded, _ := json.Marshal(result)
+
+		if string(encoded) != s.expectedJson {
+			t.Errorf("(%d) Expected expression %v, got %v", i, s.expectedJson, string(encoded))
+		}
+	}
+}
+
+func TestModifierCallsFilterNormalization(t *testing.T) {
+	resolver := search.NewSimpleFieldResolver(`^\w+:distance:[\w\:]+$`, "title")
+
+	scenarios := []struct {
+		filterData  search.FilterData
+		expectError bool
+		expectSql   string
+	}{
+		{"location:distance(37.7) < 100", false, "[[locationdistance37_7]] < {:TEST}"},
+		{"location:distance(37.7, -122.4) < 5000", false, "[[locationdistance37_7n122_4]] < {:TEST}"},
+		{"location:distance( 1 ,2 ) < 1 && title = 'location:distance(3, 4)'", false, "([[locationdistance12]] < {:TEST} AND [[title]] = {:TEST})"},
+		{"location:distance(a, b) < 1", true, ""},
+		{"location:distance((1, 2)) < 1", true, ""},
+	}
+
+	for i, s := range scenarios {
+		expr, err := s.filterData.BuildExpr(resolver)
+
+		hasErr := err != nil
+		if hasErr != s.expectError {
+			t.Errorf("(%d) Expected hasErr %v, got %v (%v)", i, s.expectError, hasErr, err)
+			continue
+		}
+
+		if hasErr {
+			continue
+		}
+
+		params := dbx.Params{}
+		rawSql := expr.Build(&dbx.DB{}, params)
+
+		// normalize the random placeholder names
+		for k := range params {
+			rawSql = strings.ReplaceAll(rawSql, "{:"+k+"}", "{:TEST}")
+		}
+
+		if rawSql != s.expectSql {
+			t.Errorf("(%d) Expected \n%v, \ngot \n%v", i, s.expectSql, rawSql)
+		}
+	}
+}
@@ -23,6 +23,8 @@ const (
 	SortQueryParam      string = "sort"
 	FilterQueryParam    string = "filter"
 	SkipTotalQueryParam string = "skipTotal"
+	CursorQueryParam    string = "cursor"
+	CountOnlyQueryParam string = "countOnly"
 )
 
 // Result defines the returned search result structure.
@@ -31,7 +33,11 @@ type Result struct {
 	PerPage    int `json:"perPage"`
 	TotalItems int `json:"totalItems"`
 	TotalPages int `json:"totalPages"`
-	Items      any `json:"items"`
+	Items      any `json:"items,omitempty"`
+
+	// NextCursor is set only in cursor pagination mode and when
+	// there are (probably) more items to fetch.
+	NextCursor string `json:"nextCursor,omitempty"`
 }
 
 // Provider represents a single configured search provider instance.
@@ -39,6 +45,9 @@ type Provider struct {
 	fieldResolver FieldResolver
 	query         *dbx.SelectQuery
 	skipTotal     bool
+	countOnly     bool
+	useCursor     bool
+	cursor        string
 	countCol      string
 	page          int
 	perPage       int
@@ -80,6 +89,29 @@ func (s *Provider) SkipTotal(skipTotal bool) *Provider {
 	return s
 }
 
+// CountOnly changes the `countOnly` field of the current search provider.
+//
+// When enabled, only the total count query is executed and the result
+// items are not fetched (the `skipTotal` field is ignored).
+func (s *Provider) CountOnly(countOnly bool) *Provider {
+	s.countOnly = countOnly
+	return s
+}
+
+// Cursor enables the keyset (aka. cursor) pagination mode and sets
+// the cursor token of the current search provider.
+//
+// An empty cursor starts a new pagination from the first item and
+// the token for the next page is returned with `Result.NextCursor`.
+//
+// In cursor mode the `page` field and the base query ORDER BY clause are ignored,
+// and the `sort` fields are always complemented with the countCol tie-breaker.
+func (s *Provider) Cursor(cursor string) *Provider {
+	s.useCursor = true
+	s.cursor = cursor
+	return s
+}
+
 // CountCol allows changing the default column (id) that is used
 // to generated the COUNT SQL query statement.
 //
@@ -151,6 +183,14 @@ func (s *Provider) Parse(urlQuery string) error {
 		s.SkipTotal(v)
 	}
 
+	if raw := params.Get(CountOnlyQueryParam); raw != "" {
+		v, err := strconv.ParseBool(raw)
+		if err != nil {
+			return err
+		}
+		s.CountOnly(v)
+	}
+
 	if raw := params.Get(PageQueryParam); raw != "" {
 		v, err := strconv.Atoi(raw)
 		if err != nil {
@@ -167,6 +207,10 @@ func (s *Provider) Parse(urlQuery string) error {
 		s.PerPage(v)
 	}
 
+	if params.Has(CursorQueryParam) {
+		s.Cursor(params.Get(CursorQueryParam))
+	}
+
 	if raw := params.Get(SortQueryParam); raw != "" {
 		for _, sortField := range ParseSortFromString(raw) {
 			s.AddSort(sortField)
@@ -202,13 +246,31 @@ func (s *Provider) Exec(items any) (*Result, error) {
 	}
 
 	// apply sorting
-	for _, sortField := range s.sort {
-		expr, err := sortField.BuildExpr(s.fieldResolver)
+	sortFields := s.sort
+	var sortIdentifiers []string
+	var cursorExpr dbx.Expression
+	if s.useCursor {
+		var err error
+		sortFields, sortIdentifiers, cursorExpr, err = s.applyCursor(&modelsQuery)
 		if err != nil {
 			return nil, err
 		}
-		if expr != "" {
-			modelsQuery.AndOrderBy(expr)
+	} else {
+		for _, sortField := range sortFields {
+			expr, err := sortField.BuildExpr(s.fieldResolver)
+			if err != nil {
+				return nil, err
+			}
+			if expr != "" {
+				modelsQuery.AndOrderBy(expr)
+			}
+		}
+
+		if tieBreaker := s.sortTieBreaker(); tieBreaker != nil {
+			// ignore unresolvable tie-breaker (eg. not allowed field)
+			if expr, err := tieBreaker.BuildExpr(s.fieldResolver); err == nil {
+				modelsQuery.AndOrderBy(expr)
+			}
 		}
 	}
 
@@ -259,11 +321,30 @@ func (s *Provider) Exec(items any) (*Result, error) {
 	// apply pagination to the original query and fetch the models
 	modelsExec := func() error {
 		modelsQuery.Limit(int64(s.perPage))
-		modelsQuery.Offset(int64(s.perPage * (s.page - 1)))
+		if !s.useCursor {
+			modelsQuery.Offset(int64(s.perPage * (s.page - 1)))
+		} else if cursorExpr != nil {
+			// applied only to the models query so that the total count
+			// still reflects all items matching the search filter
+			modelsQuery.AndWhere(cursorExpr)
+		}
 
 		return modelsQuery.All(items)
 	}
 
+	if s.countOnly {
+		if err := countExec(); err != nil {
+			return nil, err
+		}
+
+		return &Result{
+			Page:       s.page,
+			PerPage:    s.perPage,
+			TotalItems: totalCount,
+			TotalPages: totalPages,
+		}, nil
+	}
+
 	if !s.skipTotal {
 		// execute the 2 queries concurrently
 		errg := new(errgroup.Group)
@@ -287,9 +368,139 @@ func (s *Provider) Exec(items any) (*Result, error) {
 		Items:      items,
 	}
 
+	if s.useCursor {
+		nextCursor, err := s.nextCursor(modelsQuery, items, sortFields, sortIdentifiers)
+		if err != nil {
+			return nil, err
+		}
+		result.NextCursor = nextCursor
+	}
+
 	return result, nil
 }
 
+// sortTieBreaker returns the countCol sort field that should be appended
+// to the explicit sort fields so that the items order is total
+// (aka. pagination is stable for items with equal sort values).
+//
+// The tie-breaker has the same direction as the primary sort field.
+//
+// Returns nil if there are no sort fields, countCol is already
+// part of the sort fields or when sorting randomly.
+func (s *Provider) sortTieBreaker() *SortField {
+	if len(s.sort) == 0 || s.countCol == "" {
+		return nil
+	}
+
+	for _, f := range s.sort {
+		if f.Name == s.countCol || f.Name == randomSortKey {
+			return nil
+		}
+	}
+
+	direction := SortAsc
+	if s.sort[0].Direction == SortDesc {
+		direction = SortDesc
+	}
+
+	return &SortField{Name: s.countCol, Direction: direction}
+}
+
+// applyCursor applies the cursor pagination sorting to the provided query.
+//
+// Returns the normalized sort fields (including the tie-breaker), their resolved
+// identifiers and the keyset condition for the current cursor (if any).
+func (s *Provider) applyCursor(query *dbx.SelectQuery) ([]SortField, []string, dbx.Expression, error) {
+	fields := make([]SortField, 0, len(s.sort)+1)
+	hasTieBreaker := false
+	for _, f := range s.sort {
+		if f.Name == randomSortKey {
+			return nil, nil, nil, errors.New("random sort is not supported with cursor pagination")
+		}
+		if f.Name == s.countCol {
+			hasTieBreaker = true
+		}
+		fields = append(fields, f)
+	}
+	if !hasTieBreaker {
+		fields = append(fields, SortField{Name: s.countCol, Direction: SortAsc})
+	}
+
+	identifiers := make([]string, len(fields))
+	query.OrderBy( /* reset */ )
+	for i, f := range fields {
+		identifier, err := f.resolveIdentifier(s.fieldResolver)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		identifiers[i] = identifier
+		query.AndOrderBy(identifier + " " + f.Direction)
+	}
+
+	if s.cursor == "" {
+		return fields, identifiers, nil, nil
+	}
+
+	data, err := decodeCursor(s.cursor)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	if data.Sort != cursorSortSignature(fields) || len(data.Values) != len(fields) {
+		return nil, nil, nil, ErrInvalidCursor
+	}
+
+	return fields, identifiers, buildCursorExpr(fields, identifiers, data.Values), nil
+}
+
+// nextCursor generates the cursor token for the page following the fetched items.
+//
+// Returns an empty string if the items are less than perPage (aka. there are no more items).
+func (s *Provider) nextCursor(query dbx.SelectQuery, items any, fields []SortField, identifiers []string) (string, error) {
+	lastId, total, err := lastItemId(items)
+	if err != nil {
+		return "", err
+	}
+
+	if total < s.perPage {
+		return "", nil
+	}
+
+	countCol := s.countCol
+	if queryInfo := query.Info(); len(queryInfo.From) > 0 {
+		countCol = queryInfo.From[0] + "." + countCol
+	}
+
+	values := make([]any, len(identifiers))
+	pointers := make([]any, len(identifiers))
+	for i := range values {
+		pointers[i] = &values[i]
+	}
+
+	// note: query is shallow cloned and slice/map in-place modifications should be avoided
+	err = query.
+		Select(identifiers...).
+		AndWhere(dbx.HashExp{countCol: lastId}).
+		OrderBy( /* reset */ ).
+		Limit(1).
+		Offset(0).
+		Row(pointers...)
+	if err != nil {
+		return "", err
+	}
+
+	for i, v := range values {
+		if b, ok := v.([]byte); ok {
+			values[i] = string(b)
+		}
+	}
+
+	return encodeCursor(&cursorData{
+		Sort:   cursorSortSignature(fields),
+		Values: values,
+	})
+}
+
 // ParseAndExec is a short convenient method to trigger both
 // `Parse()` and `Exec()` in a single call.
 func (s *Provider) ParseAndExec(urlQuery string, modelsSlice any) (*Result, error) {
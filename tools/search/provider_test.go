@@ -6,6 +6,8 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"strconv"
+	"strings"
 	"testing"
 	"time"
 
@@ -56,6 +58,20 @@ func TestProviderSkipTotal(t *testing.T) {
 	}
 }
 
+func TestProviderCountOnly(t *testing.T) {
+	p := NewProvider(&testFieldResolver{})
+
+	if p.countOnly {
+		t.Fatalf("Expected the default countOnly to be %v, got %v", false, p.countOnly)
+	}
+
+	p.CountOnly(true)
+
+	if !p.countOnly {
+		t.Fatalf("Expected countOnly to change to %v, got %v", true, p.countOnly)
+	}
+}
+
 func TestProviderCountCol(t *testing.T) {
 	p := NewProvider(&testFieldResolver{})
 
@@ -159,6 +175,15 @@ func TestProviderParse(t *testing.T) {
 			`[{"name":"test1","direction":"ASC"},{"name":"test2","direction":"ASC"}]`,
 			`["test1","test2"]`,
 		},
+		// invalid countOnly
+		{
+			"countOnly=a",
+			true,
+			initialPage,
+			initialPerPage,
+			`[{"name":"test1","direction":"ASC"},{"name":"test2","direction":"ASC"}]`,
+			`["test1","test2"]`,
+		},
 		// invalid perPage
 		{
 			"perPage=a",
@@ -309,7 +334,7 @@ func TestProviderExecNonEmptyQuery(t *testing.T) {
 			`{"page":1,"perPage":` + fmt.Sprint(MaxPerPage) + `,"totalItems":1,"totalPages":1,"items":[{"test1":2,"test2":"test2.2","test3":""}]}`,
 			[]string{
 				"SELECT COUNT(DISTINCT [[test.id]]) FROM `test` WHERE ((NOT (`test1` IS NULL)) AND (((test2 != '' AND test2 IS NOT NULL)))) AND (test1 >= 2)",
-				"SELECT * FROM `test` WHERE ((NOT (`test1` IS NULL)) AND (((test2 != '' AND test2 IS NOT NULL)))) AND (test1 >= 2) ORDER BY `test1` ASC, `test2` DESC LIMIT " + fmt.Sprint(MaxPerPage),
+				"SELECT * FROM `test` WHERE ((NOT (`test1` IS NULL)) AND (((test2 != '' AND test2 IS NOT NULL)))) AND (test1 >= 2) ORDER BY `test1` ASC, `test2` DESC, `id` DESC LIMIT " + fmt.Sprint(MaxPerPage),
 			},
 		},
 		{
@@ -322,7 +347,7 @@ func TestProviderExecNonEmptyQuery(t *testing.T) {
 			false,
 			`{"page":1,"perPage":` + fmt.Sprint(MaxPerPage) + `,"totalItems":-1,"totalPages":-1,"items":[{"test1":2,"test2":"test2.2","test3":""}]}`,
 			[]string{
-				"SELECT * FROM `test` WHERE ((NOT (`test1` IS NULL)) AND (((test2 != '' AND test2 IS NOT NULL)))) AND (test1 >= 2) ORDER BY `test1` ASC, `test2` DESC LIMIT " + fmt.Sprint(MaxPerPage),
+				"SELECT * FROM `test` WHERE ((NOT (`test1` IS NULL)) AND (((test2 != '' AND test2 IS NOT NULL)))) AND (test1 >= 2) ORDER BY `test1` ASC, `test2` DESC, `id` DESC LIMIT " + fmt.Sprint(MaxPerPage),
 			},
 		},
 		{
@@ -336,7 +361,7 @@ func TestProviderExecNonEmptyQuery(t *testing.T) {
 			`{"page":1,"perPage":10,"totalItems":0,"totalPages":0,"items":[]}`,
 			[]string{
 				"SELECT COUNT(DISTINCT [[test.id]]) FROM `test` WHERE (NOT (`test1` IS NULL)) AND (((test3 != '' AND test3 IS NOT NULL)))",
-				"SELECT * FROM `test` WHERE (NOT (`test1` IS NULL)) AND (((test3 != '' AND test3 IS NOT NULL))) ORDER BY `test1` ASC, `test3` ASC LIMIT 10",
+				"SELECT * FROM `test` WHERE (NOT (`test1` IS NULL)) AND (((test3 != '' AND test3 IS NOT NULL))) ORDER BY `test1` ASC, `test3` ASC, `id` ASC LIMIT 10",
 			},
 		},
 		{
@@ -349,7 +374,7 @@ func TestProviderExecNonEmptyQuery(t *testing.T) {
 			false,
 			`{"page":1,"perPage":10,"totalItems":-1,"totalPages":-1,"items":[]}`,
 			[]string{
-				"SELECT * FROM `test` WHERE (NOT (`test1` IS NULL)) AND (((test3 != '' AND test3 IS NOT NULL))) ORDER BY `test1` ASC, `test3` ASC LIMIT 10",
+				"SELECT * FROM `test` WHERE (NOT (`test1` IS NULL)) AND (((test3 != '' AND test3 IS NOT NULL))) ORDER BY `test1` ASC, `test3` ASC, `id` ASC LIMIT 10",
 			},
 		},
 		{
@@ -427,6 +452,77 @@ func TestProviderExecNonEmptyQuery(t *testing.T) {
 	}
 }
 
+func TestProviderExecCountOnly(t *testing.T) {
+	testDB, err := createTestDB()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer testDB.Close()
+
+	query := testDB.Select("*").
+		From("test").
+		Where(dbx.Not(dbx.HashExp{"test1": nil})).
+		OrderBy("test1 ASC")
+
+	scenarios := []struct {
+		name          string
+		skipTotal     bool
+		filter        []FilterData
+		expectResult  string
+		expectQueries []string
+	}{
+		{
+			"without filter",
+			false,
+			[]FilterData{},
+			`{"page":1,"perPage":1,"totalItems":2,"totalPages":2}`,
+			[]string{
+				"SELECT COUNT(DISTINCT [[test.id]]) FROM `test` WHERE NOT (`test1` IS NULL)",
+			},
+		},
+		{
+			"with filter and skipTotal (ignored)",
+			true,
+			[]FilterData{"test1 >= 2"},
+			`{"page":1,"perPage":1,"totalItems":1,"totalPages":1}`,
+			[]string{
+				"SELECT COUNT(DISTINCT [[test.id]]) FROM `test` WHERE (NOT (`test1` IS NULL)) AND (test1 >= 2)",
+			},
+		},
+	}
+
+	for _, s := range scenarios {
+		testDB.CalledQueries = []string{} // reset
+
+		result, err := NewProvider(&testFieldResolver{}).
+			Query(query).
+			PerPage(1).
+			SkipTotal(s.skipTotal).
+			CountOnly(true).
+			Filter(s.filter).
+			Exec(&[]testTableStruct{})
+		if err != nil {
+			t.Fatalf("[%s] Expected nil, got error %v", s.name, err)
+		}
+
+		encoded, _ := json.Marshal(result)
+		if string(encoded) != s.expectResult {
+			t.Errorf("[%s] Expected result %v, got \n%v", s.name, s.expectResult, string(encoded))
+		}
+
+		if len(s.expectQueries) != len(testDB.CalledQueries) {
+			t.Errorf("[%s] Expected %d queries, got %d: \n%v", s.name, len(s.expectQueries), len(testDB.CalledQueries), testDB.CalledQueries)
+			continue
+		}
+
+		for _, q := range testDB.CalledQueries {
+			if !list.ExistInSliceWithRegex(q, s.expectQueries) {
+				t.Fatalf("[%s] Didn't expect query \n%v \nin \n%v", s.name, q, s.expectQueries)
+			}
+		}
+	}
+}
+
 func TestProviderParseAndExec(t *testing.T) {
 	testDB, err := createTestDB()
 	if err != nil {
@@ -550,6 +646,213 @@ func TestProviderParseAndExec(t *testing.T) {
 	}
 }
 
+func TestProviderExecCursor(t *testing.T) {
+	testDB, err := createTestDB()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer testDB.Close()
+
+	query := testDB.Select("*").
+		From("test").
+		OrderBy("test1 ASC") // should be ignored
+
+	exec := func(queryString string) (*Result, []testTableStructWithId, error) {
+		items := []testTableStructWithId{}
+		result, err := NewProvider(&testFieldResolver{}).
+			Query(query).
+			PerPage(1).
+			ParseAndExec(queryString, &items)
+		return result, items, err
+	}
+
+	// first page
+	result1, items1, err := exec("cursor=&sort=-test1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(items1) != 1 || items1[0].Id != 2 {
+		t.Fatalf("Expected only item with id 2, got %v", items1)
+	}
+	if result1.TotalItems != 2 {
+		t.Fatalf("Expected totalItems 2, got %d", result1.TotalItems)
+	}
+	if result1.NextCursor == "" {
+		t.Fatal("Expected nextCursor to be set")
+	}
+
+	// second page
+	result2, items2, err := exec("cursor=" + result1.NextCursor + "&sort=-test1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(items2) != 1 || items2[0].Id != 1 {
+		t.Fatalf("Expected only item with id 1, got %v", items2)
+	}
+	if result2.TotalItems != 2 {
+		t.Fatalf("Expected totalItems 2, got %d", result2.TotalItems)
+	}
+	if result2.NextCursor == "" || result2.NextCursor == result1.NextCursor {
+		t.Fatalf("Expected new nextCursor, got %q", result2.NextCursor)
+	}
+
+	// last (empty) page
+	result3, items3, err := exec("cursor=" + result2.NextCursor + "&sort=-test1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(items3) != 0 {
+		t.Fatalf("Expected no items, got %v", items3)
+	}
+	if result3.NextCursor != "" {
+		t.Fatalf("Expected empty nextCursor, got %q", result3.NextCursor)
+	}
+
+	// no nextCursor in non-cursor mode
+	resultOffset, _, err := exec("sort=-test1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resultOffset.NextCursor != "" {
+		t.Fatalf("Expected empty nextCursor in offset mode, got %q", resultOffset.NextCursor)
+	}
+
+	errorScenarios := []struct {
+		name          string
+		queryString   string
+		expectCursErr bool
+	}{
+		{"malformed cursor", "cursor=invalid!&sort=-test1", true},
+		{"mismatched sort field", "cursor=" + result1.NextCursor + "&sort=-test2", true},
+		{"mismatched sort direction", "cursor=" + result1.NextCursor + "&sort=test1", true},
+		{"random sort", "cursor=&sort=@random", false},
+		{"invalid sort field", "cursor=&sort=unknown", false},
+	}
+
+	for _, s := range errorScenarios {
+		_, _, err := exec(s.queryString)
+		if err == nil {
+			t.Errorf("[%s] Expected error, got nil", s.name)
+			continue
+		}
+
+		if isCursErr := errors.Is(err, ErrInvalidCursor); isCursErr != s.expectCursErr {
+			t.Errorf("[%s] Expected ErrInvalidCursor %v, got %v (%v)", s.name, s.expectCursErr, isCursErr, err)
+		}
+	}
+}
+
+func TestProviderExecCursorNullSortValues(t *testing.T) {
+	testDB, err := createTestDB()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer testDB.Close()
+
+	testDB.CreateTable("test_nulls", map[string]string{"id": "int default 0", "title": "text"}).Execute()
+	defer testDB.DropTable("test_nulls").Execute()
+
+	for id, title := range map[int]any{1: "a", 2: nil, 3: "b", 4: nil, 5: "c"} {
+		if _, err := testDB.Insert("test_nulls", dbx.Params{"id": id, "title": title}).Execute(); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	scenarios := []struct {
+		sort      string
+		expectIds [][]int // per page ids (perPage=2)
+	}{
+		// NULLs are first in ASC order and the first page ends with a NULL value
+		{"title", [][]int{{2, 4}, {1, 3}, {5}}},
+		// NULLs are last in DESC order and the second page ends with a NULL value
+		{"-title", [][]int{{5, 3}, {1, 2}, {4}}},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.sort, func(t *testing.T) {
+			var cursor string
+
+			for page, expectIds := range s.expectIds {
+				items := []testTableStructWithId{}
+				result, err := NewProvider(&testFieldResolver{}).
+					Query(testDB.Select("id").From("test_nulls")).
+					PerPage(2).
+					SkipTotal(true).
+					ParseAndExec("cursor="+cursor+"&sort="+s.sort, &items)
+				if err != nil {
+					t.Fatalf("[page %d] %v", page+1, err)
+				}
+
+				ids := make([]int, len(items))
+				for i, item := range items {
+					ids[i] = item.Id
+				}
+				if fmt.Sprint(ids) != fmt.Sprint(expectIds) {
+					t.Fatalf("[page %d] Expected ids %v, got %v", page+1, expectIds, ids)
+				}
+
+				isLastPage := page == len(s.expectIds)-1
+				if isLastPage != (result.NextCursor == "") {
+					t.Fatalf("[page %d] Expected empty nextCursor %v, got %q", page+1, isLastPage, result.NextCursor)
+				}
+
+				cursor = result.NextCursor
+			}
+		})
+	}
+}
+
+func TestProviderExecSortTieBreaker(t *testing.T) {
+	testDB, err := createTestDB()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer testDB.Close()
+
+	scenarios := []struct {
+		sort          string
+		expectOrderBy string
+		expectIds     []int // per page ids (perPage=1)
+	}{
+		// test3 is the same for all items
+		{"test3", "ORDER BY `test3` ASC, `id` ASC LIMIT", []int{1, 2}},
+		{"-test3", "ORDER BY `test3` DESC, `id` DESC LIMIT", []int{2, 1}},
+		{"-test3,test2", "ORDER BY `test3` DESC, `test2` ASC, `id` DESC LIMIT", []int{1, 2}},
+		{"test3,-id", "ORDER BY `test3` ASC, `id` DESC LIMIT", []int{2, 1}},
+		{"@random", "ORDER BY RANDOM() LIMIT", nil},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.sort, func(t *testing.T) {
+			for page := 1; page <= 2; page++ {
+				testDB.CalledQueries = []string{} // reset
+
+				items := []testTableStructWithId{}
+				_, err := NewProvider(&testFieldResolver{}).
+					Query(testDB.Select("*").From("test")).
+					PerPage(1).
+					SkipTotal(true).
+					ParseAndExec(fmt.Sprintf("page=%d&sort=%s", page, s.sort), &items)
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				if len(testDB.CalledQueries) != 1 || !strings.Contains(testDB.CalledQueries[0], s.expectOrderBy) {
+					t.Fatalf("Expected query with %q, got %v", s.expectOrderBy, testDB.CalledQueries)
+				}
+
+				if s.expectIds == nil {
+					continue
+				}
+
+				if len(items) != 1 || items[0].Id != s.expectIds[page-1] {
+					t.Fatalf("[page %d] Expected only item with id %d, got %v", page, s.expectIds[page-1], items)
+				}
+			}
+		})
+	}
+}
+
 // -------------------------------------------------------------------
 // Helpers
 // -------------------------------------------------------------------
@@ -560,6 +863,17 @@ type testTableStruct struct {
 	Test3 string `db:"test3" json:"test3"`
 }
 
+type testTableStructWithId struct {
+	Id    int    `db:"id" json:"id"`
+	Test1 int    `db:"test1" json:"test1"`
+	Test2 string `db:"test2" json:"test2"`
+	Test3 string `db:"test3" json:"test3"`
+}
+
+func (s *testTableStructWithId) GetId() string {
+	return strconv.Itoa(s.Id)
+}
+
 type testDB struct {
 	*dbx.DB
 	CalledQueries []string
@@ -25,6 +25,18 @@ type ResolverResult struct {
 	// AfterBuild is an optional function that will be called after building
 	// and combining the result of both resolved operands/sides in a single expression.
 	AfterBuild func(expr dbx.Expression) dbx.Expression
+
+	// EncryptValue is an optional function indicating that the resolved
+	// identifier holds deterministically encrypted values.
+	//
+	// When set, the identifier could be used only in equal/not-equal
+	// expressions and the opposite operand placeholder values are
+	// encrypted with the function before the comparison.
+	EncryptValue func(value any) (any, error)
+
+	// isList indicates that the identifier is a "({:p0}, {:p1}, ...)"
+	// placeholders list of an "in" or "not in" expression.
+	isList bool
 }
 
 // FieldResolver defines an interface for managing search fields.
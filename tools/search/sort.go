@@ -26,6 +26,16 @@ func (s *SortField) BuildExpr(fieldResolver FieldResolver) (string, error) {
 		return "RANDOM()", nil
 	}
 
+	identifier, err := s.resolveIdentifier(fieldResolver)
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("%s %s", identifier, s.Direction), nil
+}
+
+// resolveIdentifier resolves the sort field name into a plain db column identifier.
+func (s *SortField) resolveIdentifier(fieldResolver FieldResolver) (string, error) {
 	result, err := fieldResolver.Resolve(s.Name)
 
 	// invalidate empty fields and non-column identifiers
@@ -33,7 +43,11 @@ func (s *SortField) BuildExpr(fieldResolver FieldResolver) (string, error) {
 		return "", fmt.Errorf("invalid sort field %q", s.Name)
 	}
 
-	return fmt.Sprintf("%s %s", result.Identifier, s.Direction), nil
+	if result.EncryptValue != nil {
+		return "", fmt.Errorf("encrypted field %q can't be used for sorting", s.Name)
+	}
+
+	return result.Identifier, nil
 }
 
 // ParseSortFromString parses the provided string expression
@@ -43,7 +57,7 @@ func (s *SortField) BuildExpr(fieldResolver FieldResolver) (string, error) {
 //
 //	fields := search.ParseSortFromString("-name,+created")
 func ParseSortFromString(str string) (fields []SortField) {
-	data := strings.Split(str, ",")
+	data := strings.Split(normalizeModifierCalls(normalizeIndexAccessors(str)), ",")
 
 	for _, field := range data {
 		// trim whitespaces
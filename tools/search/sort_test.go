@@ -46,6 +46,18 @@ func TestSortFieldBuildExpr(t *testing.T) {
 	}
 }
 
+func TestSortFieldBuildExprWithEncryptedField(t *testing.T) {
+	resolver := &encryptedTestResolver{search.NewSimpleFieldResolver("test1", "secret")}
+
+	if _, err := (&search.SortField{"test1", search.SortAsc}).BuildExpr(resolver); err != nil {
+		t.Fatalf("Expected nil error, got %v", err)
+	}
+
+	if _, err := (&search.SortField{"secret", search.SortAsc}).BuildExpr(resolver); err == nil {
+		t.Fatal("Expected the encrypted field sort to fail")
+	}
+}
+
 func TestParseSortFromString(t *testing.T) {
 	scenarios := []struct {
 		value        string
@@ -0,0 +1,32 @@
+package security
+
+import "strings"
+
+// commonPasswords is a list with some of the most frequently used passwords.
+var commonPasswords = map[string]struct{}{}
+
+func init() {
+	for _, p := range strings.Fields(`
+		123456 123456789 12345678 1234567890 1234567 12345 123123 1234 111111 000000
+		654321 666666 121212 112233 123321 987654321 1q2w3e4r 1q2w3e4r5t 1qaz2wsx qwerty
+		qwerty123 qwertyuiop qwerty1 asdfgh asdfghjkl zxcvbnm zxcvbn 1qazxsw2 q1w2e3r4 password
+		password1 password12 password123 passw0rd p@ssw0rd p@ssword pass1234 admin admin123 administrator
+		root toor letmein welcome welcome1 iloveyou iloveyou1 monkey dragon master
+		sunshine princess football baseball superman batman trustno1 shadow michael jennifer
+		abc123 abcd1234 abcdef abcdefg a123456 aa123456 123abc qazwsx changeme secret
+		login guest default hello hello123 freedom whatever starwars pokemon computer
+		internet killer hunter2 charlie jordan23 liverpool chelsea arsenal daniel soccer
+		hockey ranger harley thomas summer winter flower lovely loveme mustang access
+		696969 7777777 888888 11111111 00000000 12341234 55555555 123654 159753 147258369
+	`) {
+		commonPasswords[p] = struct{}{}
+	}
+}
+
+// IsCommonPassword checks (case-insensitive) whether the password
+// is in the builtin list of commonly used passwords.
+func IsCommonPassword(password string) bool {
+	_, ok := commonPasswords[strings.ToLower(password)]
+
+	return ok
+}
@@ -0,0 +1,26 @@
+package security_test
+
+import (
+	"testing"
+
+	"github.com/unkod/space/tools/security"
+)
+
+func TestIsCommonPassword(t *testing.T) {
+	scenarios := []struct {
+		password string
+		expected bool
+	}{
+		{"", false},
+		{"123456", true},
+		{"Password", true},
+		{"QWERTY123", true},
+		{"hN8#tq2!Lw", false},
+	}
+
+	for i, s := range scenarios {
+		if result := security.IsCommonPassword(s.password); result != s.expected {
+			t.Errorf("(%d) Expected %v, got %v", i, s.expected, result)
+		}
+	}
+}
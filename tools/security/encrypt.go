@@ -3,9 +3,12 @@ package security
 import (
 	"crypto/aes"
 	"crypto/cipher"
+	"crypto/hmac"
 	crand "crypto/rand"
 	"crypto/sha256"
 	"encoding/base64"
+	"encoding/hex"
+	"errors"
 	"io"
 	"strings"
 )
@@ -20,6 +23,13 @@ func S256Challenge(code string) string {
 	return strings.TrimRight(base64.URLEncoding.EncodeToString(h.Sum(nil)), "=")
 }
 
+// HS256 creates a hex encoded HMAC-SHA256 signature of text with secret.
+func HS256(text string, secret string) string {
+	h := hmac.New(sha256.New, []byte(secret))
+	h.Write([]byte(text))
+	return hex.EncodeToString(h.Sum(nil))
+}
+
 // Encrypt encrypts data with key (must be valid 32 char aes key).
 func Encrypt(data []byte, key string) (string, error) {
 	block, err := aes.NewCipher([]byte(key))
@@ -46,6 +56,37 @@ func Encrypt(data []byte, key string) (string, error) {
 	return result, nil
 }
 
+// EncryptDeterministic encrypts data with key (must be valid 32 char aes key)
+// using a nonce derived from the data HMAC, aka. the same data and key
+// always produce the same cipher text.
+//
+// This allows exact-match lookups of the encrypted values but also leaks
+// whether two cipher texts have the same plain value, so prefer [Encrypt]
+// when lookups are not needed.
+//
+// The result could be decrypted with [Decrypt].
+func EncryptDeterministic(data []byte, key string) (string, error) {
+	block, err := aes.NewCipher([]byte(key))
+	if err != nil {
+		return "", err
+	}
+
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return "", err
+	}
+
+	mac := hmac.New(sha256.New, []byte(key))
+	mac.Write(data)
+	nonce := mac.Sum(nil)[:gcm.NonceSize()]
+
+	cipherByte := gcm.Seal(nonce, nonce, data, nil)
+
+	result := base64.StdEncoding.EncodeToString(cipherByte)
+
+	return result, nil
+}
+
 // Decrypt decrypts encrypted text with key (must be valid 32 chars aes key).
 func Decrypt(cipherText string, key string) ([]byte, error) {
 	block, err := aes.NewCipher([]byte(key))
@@ -65,6 +106,10 @@ func Decrypt(cipherText string, key string) ([]byte, error) {
 		return nil, err
 	}
 
+	if len(cipherByte) < nonceSize {
+		return nil, errors.New("malformed cipher text")
+	}
+
 	nonce, cipherByteClean := cipherByte[:nonceSize], cipherByte[nonceSize:]
 	return gcm.Open(nil, nonce, cipherByteClean, nil)
 }
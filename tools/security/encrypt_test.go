@@ -24,6 +24,25 @@ func TestS256Challenge(t *testing.T) {
 	}
 }
 
+func TestHS256(t *testing.T) {
+	scenarios := []struct {
+		text     string
+		secret   string
+		expected string
+	}{
+		{"", "", "b613679a0814d9ec772f95d778c35fc5ff1697c493715653c6c712144292c5ad"},
+		{"hello", "test", "f151ea24bda91a18e89b8bb5793ef324b2a02133cce15a28a719acbd2e58a986"},
+	}
+
+	for i, scenario := range scenarios {
+		result := security.HS256(scenario.text, scenario.secret)
+
+		if result != scenario.expected {
+			t.Errorf("(%d) Expected %q, got %q", i, scenario.expected, result)
+		}
+	}
+}
+
 func TestEncrypt(t *testing.T) {
 	scenarios := []struct {
 		data        string
@@ -62,6 +81,53 @@ func TestEncrypt(t *testing.T) {
 	}
 }
 
+func TestEncryptDeterministic(t *testing.T) {
+	scenarios := []struct {
+		data        string
+		key         string
+		expectError bool
+	}{
+		{"", "", true},
+		{"123", "test", true}, // key must be valid 32 char aes string
+		{"", "abcdabcdabcdabcdabcdabcdabcdabcd", false},
+		{"123", "abcdabcdabcdabcdabcdabcdabcdabcd", false},
+	}
+
+	for i, scenario := range scenarios {
+		result, err := security.EncryptDeterministic([]byte(scenario.data), scenario.key)
+
+		hasErr := err != nil
+		if hasErr != scenario.expectError {
+			t.Errorf("(%d) Expected hasErr %v, got %v (%v)", i, scenario.expectError, hasErr, err)
+			continue
+		}
+
+		if scenario.expectError {
+			if result != "" {
+				t.Errorf("(%d) Expected empty string, got %q", i, result)
+			}
+			continue
+		}
+
+		// the same data and key should produce the same cipher text
+		result2, _ := security.EncryptDeterministic([]byte(scenario.data), scenario.key)
+		if result != result2 {
+			t.Errorf("(%d) Expected the same cipher text, got %q and %q", i, result, result2)
+		}
+
+		// different data should produce different cipher text
+		result3, _ := security.EncryptDeterministic([]byte(scenario.data+"4"), scenario.key)
+		if result == result3 {
+			t.Errorf("(%d) Expected different cipher texts, got %q", i, result)
+		}
+
+		decrypted, err := security.Decrypt(result, scenario.key)
+		if err != nil || string(decrypted) != scenario.data {
+			t.Errorf("(%d) Expected decrypted value to match with the data input, got %q (%v)", i, decrypted, err)
+		}
+	}
+}
+
 func TestDecrypt(t *testing.T) {
 	scenarios := []struct {
 		cipher       string
@@ -73,6 +139,7 @@ func TestDecrypt(t *testing.T) {
 		{"123", "test", true, ""}, // key must be valid 32 char aes string
 		{"8kcEqilvvYKYcfnSr0aSC54gmnQCsB02SaB8ATlnA==", "abcdabcdabcdabcdabcdabcdabcdabcd", true, ""}, // illegal base64 encoded cipherText
 		{"8kcEqilvv+YKYcfnSr0aSC54gmnQCsB02SaB8ATlnA==", "abcdabcdabcdabcdabcdabcdabcdabcd", false, "123"},
+		{"YWJj", "abcdabcdabcdabcdabcdabcdabcdabcd", true, ""}, // shorter than the nonce
 	}
 
 	for i, scenario := range scenarios {
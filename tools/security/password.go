@@ -0,0 +1,165 @@
+package security
+
+import (
+	crand "crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// list with the supported password hashing algorithms
+const (
+	PasswordAlgorithmBcrypt   = "bcrypt"
+	PasswordAlgorithmArgon2id = "argon2id"
+)
+
+const (
+	argon2idSaltLength = 16
+	argon2idKeyLength  = 32
+)
+
+// PasswordHashOptions defines the password hashing algorithm and its parameters.
+type PasswordHashOptions struct {
+	// Algorithm is the name of the hashing algorithm ("bcrypt" or "argon2id").
+	Algorithm string
+
+	// BcryptCost is the bcrypt hashing cost.
+	BcryptCost int
+
+	// Argon2Memory is the argon2id memory usage in KiB.
+	Argon2Memory uint32
+
+	// Argon2Iterations is the number of argon2id passes over the memory.
+	Argon2Iterations uint32
+
+	// Argon2Parallelism is the number of argon2id threads.
+	Argon2Parallelism uint8
+}
+
+// DefaultPasswordHashOptions returns the default password hashing options
+// (bcrypt with cost 12).
+func DefaultPasswordHashOptions() PasswordHashOptions {
+	return PasswordHashOptions{
+		Algorithm:         PasswordAlgorithmBcrypt,
+		BcryptCost:        12,
+		Argon2Memory:      64 * 1024,
+		Argon2Iterations:  3,
+		Argon2Parallelism: 2,
+	}
+}
+
+// HashPassword hashes the provided plain password with the specified options.
+func HashPassword(password string, opts PasswordHashOptions) (string, error) {
+	switch opts.Algorithm {
+	case PasswordAlgorithmArgon2id:
+		salt := make([]byte, argon2idSaltLength)
+		if _, err := crand.Read(salt); err != nil {
+			return "", err
+		}
+
+		key := argon2.IDKey([]byte(password), salt, opts.Argon2Iterations, opts.Argon2Memory, opts.Argon2Parallelism, argon2idKeyLength)
+
+		return fmt.Sprintf(
+			"$argon2id$v=%d$m=%d,t=%d,p=%d$%s$%s",
+			argon2.Version,
+			opts.Argon2Memory,
+			opts.Argon2Iterations,
+			opts.Argon2Parallelism,
+			base64.RawStdEncoding.EncodeToString(salt),
+			base64.RawStdEncoding.EncodeToString(key),
+		), nil
+	case PasswordAlgorithmBcrypt, "":
+		hash, err := bcrypt.GenerateFromPassword([]byte(password), opts.BcryptCost)
+		if err != nil {
+			return "", err
+		}
+
+		return string(hash), nil
+	default:
+		return "", fmt.Errorf("unsupported password hashing algorithm %q", opts.Algorithm)
+	}
+}
+
+// ComparePassword checks whether the plain password matches the provided hash.
+//
+// The hash algorithm is detected from the hash format, so the old format
+// hashes are still verified after changing the hashing options.
+func ComparePassword(hash string, password string) bool {
+	if strings.HasPrefix(hash, "$argon2id$") {
+		params, salt, key, err := decodeArgon2idHash(hash)
+		if err != nil {
+			return false
+		}
+
+		otherKey := argon2.IDKey([]byte(password), salt, params.Argon2Iterations, params.Argon2Memory, params.Argon2Parallelism, uint32(len(key)))
+
+		return subtle.ConstantTimeCompare(key, otherKey) == 1
+	}
+
+	return bcrypt.CompareHashAndPassword([]byte(hash), []byte(password)) == nil
+}
+
+// PasswordNeedsRehash checks whether the provided hash was generated with
+// a different algorithm or weaker parameters than the specified options.
+//
+// Returns false for invalid or unrecognized hashes.
+func PasswordNeedsRehash(hash string, opts PasswordHashOptions) bool {
+	if strings.HasPrefix(hash, "$argon2id$") {
+		params, _, _, err := decodeArgon2idHash(hash)
+		if err != nil {
+			return false
+		}
+
+		return opts.Algorithm != PasswordAlgorithmArgon2id ||
+			params.Argon2Memory < opts.Argon2Memory ||
+			params.Argon2Iterations < opts.Argon2Iterations ||
+			params.Argon2Parallelism < opts.Argon2Parallelism
+	}
+
+	cost, err := bcrypt.Cost([]byte(hash))
+	if err != nil {
+		return false
+	}
+
+	return opts.Algorithm == PasswordAlgorithmArgon2id || cost < opts.BcryptCost
+}
+
+// decodeArgon2idHash parses a "$argon2id$v=19$m=65536,t=3,p=2$salt$key" hash string.
+func decodeArgon2idHash(hash string) (PasswordHashOptions, []byte, []byte, error) {
+	params := PasswordHashOptions{Algorithm: PasswordAlgorithmArgon2id}
+
+	parts := strings.Split(hash, "$")
+	if len(parts) != 6 {
+		return params, nil, nil, errors.New("invalid argon2id hash format")
+	}
+
+	var version int
+	if _, err := fmt.Sscanf(parts[2], "v=%d", &version); err != nil {
+		return params, nil, nil, err
+	}
+	if version != argon2.Version {
+		return params, nil, nil, errors.New("unsupported argon2id version")
+	}
+
+	_, err := fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &params.Argon2Memory, &params.Argon2Iterations, &params.Argon2Parallelism)
+	if err != nil {
+		return params, nil, nil, err
+	}
+
+	salt, err := base64.RawStdEncoding.DecodeString(parts[4])
+	if err != nil {
+		return params, nil, nil, err
+	}
+
+	key, err := base64.RawStdEncoding.DecodeString(parts[5])
+	if err != nil || len(key) == 0 {
+		return params, nil, nil, errors.New("invalid argon2id hash key")
+	}
+
+	return params, salt, key, nil
+}
@@ -0,0 +1,116 @@
+package security_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/unkod/space/tools/security"
+)
+
+// use low cost params to speed up the tests
+func testBcryptOptions(cost int) security.PasswordHashOptions {
+	return security.PasswordHashOptions{
+		Algorithm:  security.PasswordAlgorithmBcrypt,
+		BcryptCost: cost,
+	}
+}
+
+func testArgon2Options(memory uint32, iterations uint32) security.PasswordHashOptions {
+	return security.PasswordHashOptions{
+		Algorithm:         security.PasswordAlgorithmArgon2id,
+		Argon2Memory:      memory,
+		Argon2Iterations:  iterations,
+		Argon2Parallelism: 1,
+	}
+}
+
+func TestHashPasswordAndComparePassword(t *testing.T) {
+	scenarios := []struct {
+		name           string
+		opts           security.PasswordHashOptions
+		expectedPrefix string
+	}{
+		{"bcrypt", testBcryptOptions(4), "$2a$04$"},
+		{"argon2id", testArgon2Options(1024, 1), "$argon2id$v=19$m=1024,t=1,p=1$"},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			hash1, err := security.HashPassword("123456", s.opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			hash2, _ := security.HashPassword("123456", s.opts)
+
+			if !strings.HasPrefix(hash1, s.expectedPrefix) {
+				t.Fatalf("Expected hash with prefix %q, got %q", s.expectedPrefix, hash1)
+			}
+
+			if hash1 == hash2 {
+				t.Fatalf("Expected different salted hashes, got %q", hash1)
+			}
+
+			if !security.ComparePassword(hash1, "123456") {
+				t.Fatal("Expected the password to match")
+			}
+
+			if security.ComparePassword(hash1, "1234567") {
+				t.Fatal("Expected the password to not match")
+			}
+		})
+	}
+}
+
+func TestHashPasswordUnsupportedAlgorithm(t *testing.T) {
+	if _, err := security.HashPassword("123456", security.PasswordHashOptions{Algorithm: "md5"}); err == nil {
+		t.Fatal("Expected error, got nil")
+	}
+}
+
+func TestComparePasswordInvalidHash(t *testing.T) {
+	hashes := []string{
+		"",
+		"invalid",
+		"$argon2id$v=19$m=1024,t=1,p=1$invalid",
+		"$argon2id$v=16$m=1024,t=1,p=1$c2FsdA$a2V5",
+	}
+
+	for i, hash := range hashes {
+		if security.ComparePassword(hash, "123456") {
+			t.Errorf("(%d) Expected the invalid hash %q to not match", i, hash)
+		}
+	}
+}
+
+func TestPasswordNeedsRehash(t *testing.T) {
+	bcryptHash, _ := security.HashPassword("123456", testBcryptOptions(5))
+	argon2Hash, _ := security.HashPassword("123456", testArgon2Options(1024, 2))
+
+	scenarios := []struct {
+		name     string
+		hash     string
+		opts     security.PasswordHashOptions
+		expected bool
+	}{
+		{"invalid hash", "invalid", testBcryptOptions(10), false},
+		{"bcrypt with the same cost", bcryptHash, testBcryptOptions(5), false},
+		{"bcrypt with lower cost", bcryptHash, testBcryptOptions(4), false},
+		{"bcrypt with higher cost", bcryptHash, testBcryptOptions(6), true},
+		{"bcrypt to argon2id", bcryptHash, testArgon2Options(1024, 2), true},
+		{"argon2id with the same params", argon2Hash, testArgon2Options(1024, 2), false},
+		{"argon2id with weaker params", argon2Hash, testArgon2Options(1024, 1), false},
+		{"argon2id with more memory", argon2Hash, testArgon2Options(2048, 2), true},
+		{"argon2id with more iterations", argon2Hash, testArgon2Options(1024, 3), true},
+		{"argon2id to bcrypt", argon2Hash, testBcryptOptions(4), true},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			result := security.PasswordNeedsRehash(s.hash, s.opts)
+			if result != s.expected {
+				t.Fatalf("Expected %v, got %v", s.expected, result)
+			}
+		})
+	}
+}
@@ -0,0 +1,69 @@
+package security
+
+import (
+	"bufio"
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// DefaultPwnedPasswordsUrl is the default "Have I Been Pwned" passwords range API url.
+const DefaultPwnedPasswordsUrl = "https://api.pwnedpasswords.com/range/"
+
+// IsPwnedPassword checks via the k-anonymity "Have I Been Pwned" range API
+// whether the password was exposed in a known data breach.
+//
+// Only the first 5 characters of the password SHA-1 hash are sent to rangeUrl
+// (fallbacks to [DefaultPwnedPasswordsUrl] if empty), so it could be also
+// used with a self-hosted mirror of the passwords range API.
+func IsPwnedPassword(ctx context.Context, client *http.Client, rangeUrl string, password string) (bool, error) {
+	if rangeUrl == "" {
+		rangeUrl = DefaultPwnedPasswordsUrl
+	}
+
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	sum := sha1.Sum([]byte(password))
+	hash := strings.ToUpper(hex.EncodeToString(sum[:]))
+	prefix, suffix := hash[:5], hash[5:]
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, strings.TrimSuffix(rangeUrl, "/")+"/"+prefix, nil)
+	if err != nil {
+		return false, err
+	}
+
+	// pad the response to prevent guessing the prefix from its size
+	req.Header.Set("Add-Padding", "true")
+
+	res, err := client.Do(req)
+	if err != nil {
+		return false, err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return false, fmt.Errorf("failed to fetch the pwned passwords range (%d)", res.StatusCode)
+	}
+
+	// each line has the format "HASH_SUFFIX:COUNT"
+	scanner := bufio.NewScanner(res.Body)
+	for scanner.Scan() {
+		lineSuffix, count, _ := strings.Cut(strings.TrimSpace(scanner.Text()), ":")
+		if !strings.EqualFold(lineSuffix, suffix) {
+			continue
+		}
+
+		// the padding entries have zero count
+		n, _ := strconv.Atoi(count)
+
+		return n > 0, nil
+	}
+
+	return false, scanner.Err()
+}
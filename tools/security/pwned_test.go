@@ -0,0 +1,53 @@
+package security_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/unkod/space/tools/security"
+)
+
+func TestIsPwnedPassword(t *testing.T) {
+	// sha1("password") = 5BAA61E4C9B93F3F0682250B6CF8331B7EE68FD8
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/range/5BAA6" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		w.Write([]byte(strings.Join([]string{
+			"003D68EB55068C33ACE09247EE4C639306B:3",
+			"1E4C9B93F3F0682250B6CF8331B7EE68FD8:3861493",
+			"F0F5423BD3F0DF7BD25BAE42A04D5A8A360:0",
+		}, "\r\n")))
+	}))
+	defer server.Close()
+
+	scenarios := []struct {
+		password    string
+		url         string
+		expected    bool
+		expectError bool
+	}{
+		{"password", server.URL + "/range/", true, false},
+		{"password", server.URL + "/range", true, false},
+		{"abc", server.URL + "/range", false, true}, // different prefix
+		{"password", server.URL + "/missing", false, true},
+	}
+
+	for i, s := range scenarios {
+		result, err := security.IsPwnedPassword(context.Background(), nil, s.url, s.password)
+
+		hasErr := err != nil
+		if hasErr != s.expectError {
+			t.Errorf("(%d) Expected hasErr %v, got %v (%v)", i, s.expectError, hasErr, err)
+		}
+
+		if result != s.expected {
+			t.Errorf("(%d) Expected %v, got %v", i, s.expected, result)
+		}
+	}
+}
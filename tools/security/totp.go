@@ -0,0 +1,132 @@
+package security
+
+import (
+	"crypto/hmac"
+	crand "crypto/rand"
+	"crypto/sha1"
+	"crypto/subtle"
+	"encoding/base32"
+	"encoding/binary"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// list with the TOTP [RFC 6238] defaults (compatible with most authenticator apps)
+//
+// [RFC 6238]: https://datatracker.ietf.org/doc/html/rfc6238
+const (
+	TOTPDigits = 6
+	TOTPPeriod = 30 * time.Second
+)
+
+var totpEncoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// NewTOTPSecret generates a new random base32 encoded (without padding) TOTP secret.
+func NewTOTPSecret() (string, error) {
+	key := make([]byte, 20)
+
+	if _, err := crand.Read(key); err != nil {
+		return "", err
+	}
+
+	return totpEncoding.EncodeToString(key), nil
+}
+
+// TOTPCode generates the TOTP code of the base32 encoded secret for the specified time.
+func TOTPCode(secret string, t time.Time) (string, error) {
+	key, err := decodeTOTPSecret(secret)
+	if err != nil {
+		return "", err
+	}
+
+	return hotpCode(key, uint64(t.Unix()/int64(TOTPPeriod.Seconds())), TOTPDigits), nil
+}
+
+// ValidateTOTPCode checks whether the code is a valid TOTP code of
+// the base32 encoded secret for the specified time.
+//
+// To account for clock drifts, the codes of the previous and the next
+// time step are also accepted.
+//
+// Note that a valid code could be reused within its time steps.
+// Use [MatchTOTPCode] if you need to prevent replays.
+func ValidateTOTPCode(secret string, code string, t time.Time) bool {
+	_, ok := MatchTOTPCode(secret, code, t)
+
+	return ok
+}
+
+// MatchTOTPCode is similar to [ValidateTOTPCode] but in addition
+// returns the time step counter of the matched code.
+//
+// The counter could be stored and compared with the next matched
+// counters in order to reject already used codes.
+func MatchTOTPCode(secret string, code string, t time.Time) (uint64, bool) {
+	code = strings.TrimSpace(code)
+	if len(code) != TOTPDigits {
+		return 0, false
+	}
+
+	key, err := decodeTOTPSecret(secret)
+	if err != nil {
+		return 0, false
+	}
+
+	counter := uint64(t.Unix() / int64(TOTPPeriod.Seconds()))
+
+	var matched uint64
+	valid := false
+	for _, c := range []uint64{counter - 1, counter, counter + 1} {
+		// don't break early to keep the check time constant
+		if subtle.ConstantTimeCompare([]byte(hotpCode(key, c, TOTPDigits)), []byte(code)) == 1 {
+			matched = c
+			valid = true
+		}
+	}
+
+	return matched, valid
+}
+
+// TOTPAuthURL returns the "otpauth://" key uri of the secret
+// (usually presented as QR code to the authenticator apps).
+func TOTPAuthURL(issuer string, account string, secret string) string {
+	params := url.Values{}
+	params.Set("secret", secret)
+	params.Set("issuer", issuer)
+	params.Set("digits", fmt.Sprint(TOTPDigits))
+	params.Set("period", fmt.Sprint(int(TOTPPeriod.Seconds())))
+
+	label := url.PathEscape(issuer) + ":" + url.PathEscape(account)
+
+	return "otpauth://totp/" + label + "?" + params.Encode()
+}
+
+func decodeTOTPSecret(secret string) ([]byte, error) {
+	normalized := strings.ToUpper(strings.ReplaceAll(secret, " ", ""))
+
+	return totpEncoding.DecodeString(strings.TrimRight(normalized, "="))
+}
+
+// hotpCode generates a HOTP code as described in [RFC 4226].
+//
+// [RFC 4226]: https://datatracker.ietf.org/doc/html/rfc4226#section-5.3
+func hotpCode(key []byte, counter uint64, digits int) string {
+	msg := make([]byte, 8)
+	binary.BigEndian.PutUint64(msg, counter)
+
+	mac := hmac.New(sha1.New, key)
+	mac.Write(msg)
+	sum := mac.Sum(nil)
+
+	offset := sum[len(sum)-1] & 0xf
+	value := binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff
+
+	mod := uint32(1)
+	for i := 0; i < digits; i++ {
+		mod *= 10
+	}
+
+	return fmt.Sprintf("%0*d", digits, value%mod)
+}
@@ -0,0 +1,153 @@
+package security_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/unkod/space/tools/security"
+)
+
+// base32 encoded RFC 6238 test secret ("12345678901234567890")
+const testTOTPSecret = "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+
+func TestNewTOTPSecret(t *testing.T) {
+	secret1, err := security.NewTOTPSecret()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	secret2, err := security.NewTOTPSecret()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(secret1) != 32 {
+		t.Fatalf("Expected 32 chars secret, got %q", secret1)
+	}
+
+	if secret1 == secret2 {
+		t.Fatalf("Expected different secrets, got %q", secret1)
+	}
+
+	if _, err := security.TOTPCode(secret1, time.Now()); err != nil {
+		t.Fatalf("Expected valid base32 secret, got %v", err)
+	}
+}
+
+func TestTOTPCode(t *testing.T) {
+	scenarios := []struct {
+		secret      string
+		unix        int64
+		expected    string
+		expectError bool
+	}{
+		{"invalid!", 59, "", true},
+		// RFC 6238 appendix B test vectors (truncated to 6 digits)
+		{testTOTPSecret, 59, "287082", false},
+		{testTOTPSecret, 1111111109, "081804", false},
+		{testTOTPSecret, 1234567890, "005924", false},
+		{testTOTPSecret, 2000000000, "279037", false},
+		// normalized secret
+		{strings.ToLower(testTOTPSecret), 59, "287082", false},
+	}
+
+	for i, s := range scenarios {
+		result, err := security.TOTPCode(s.secret, time.Unix(s.unix, 0))
+
+		hasErr := err != nil
+		if hasErr != s.expectError {
+			t.Fatalf("[%d] Expected hasErr %v, got %v (%v)", i, s.expectError, hasErr, err)
+		}
+
+		if result != s.expected {
+			t.Fatalf("[%d] Expected code %q, got %q", i, s.expected, result)
+		}
+	}
+}
+
+func TestValidateTOTPCode(t *testing.T) {
+	now := time.Unix(1111111109, 0)
+
+	scenarios := []struct {
+		secret   string
+		code     string
+		expected bool
+	}{
+		{testTOTPSecret, "", false},
+		{testTOTPSecret, "123", false},
+		{testTOTPSecret, "000000", false},
+		{"invalid!", "081804", false},
+		{testTOTPSecret, "081804", true},
+		{testTOTPSecret, " 081804 ", true},
+		// previous step
+		{testTOTPSecret, mustTOTPCode(t, now.Add(-30*time.Second)), true},
+		// next step
+		{testTOTPSecret, mustTOTPCode(t, now.Add(30*time.Second)), true},
+		// too old
+		{testTOTPSecret, mustTOTPCode(t, now.Add(-90*time.Second)), false},
+	}
+
+	for i, s := range scenarios {
+		result := security.ValidateTOTPCode(s.secret, s.code, now)
+
+		if result != s.expected {
+			t.Errorf("[%d] Expected %v, got %v", i, s.expected, result)
+		}
+	}
+}
+
+func TestMatchTOTPCode(t *testing.T) {
+	now := time.Unix(1111111109, 0)
+
+	counter := uint64(now.Unix() / 30)
+
+	scenarios := []struct {
+		secret          string
+		code            string
+		expectedCounter uint64
+		expected        bool
+	}{
+		{testTOTPSecret, "", 0, false},
+		{testTOTPSecret, "000000", 0, false},
+		{"invalid!", "081804", 0, false},
+		{testTOTPSecret, "081804", counter, true},
+		// previous step
+		{testTOTPSecret, mustTOTPCode(t, now.Add(-30*time.Second)), counter - 1, true},
+		// next step
+		{testTOTPSecret, mustTOTPCode(t, now.Add(30*time.Second)), counter + 1, true},
+		// too old
+		{testTOTPSecret, mustTOTPCode(t, now.Add(-90*time.Second)), 0, false},
+	}
+
+	for i, s := range scenarios {
+		resultCounter, result := security.MatchTOTPCode(s.secret, s.code, now)
+
+		if result != s.expected {
+			t.Errorf("[%d] Expected %v, got %v", i, s.expected, result)
+		}
+
+		if resultCounter != s.expectedCounter {
+			t.Errorf("[%d] Expected counter %d, got %d", i, s.expectedCounter, resultCounter)
+		}
+	}
+}
+
+func TestTOTPAuthURL(t *testing.T) {
+	result := security.TOTPAuthURL("Acme Inc", "test@example.com", testTOTPSecret)
+
+	expected := "otpauth://totp/Acme%20Inc:test@example.com?digits=6&issuer=Acme+Inc&period=30&secret=" + testTOTPSecret
+
+	if result != expected {
+		t.Fatalf("Expected \n%s, \ngot \n%s", expected, result)
+	}
+}
+
+func mustTOTPCode(t *testing.T, date time.Time) string {
+	code, err := security.TOTPCode(testTOTPSecret, date)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return code
+}
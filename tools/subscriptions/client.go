@@ -95,11 +95,19 @@ func (c *DefaultClient) Channel() chan Message {
 }
 
 // Subscriptions implements the [Client.Subscriptions] interface method.
+//
+// It returns a shallow copy of the client subscriptions so that
+// it is safe to iterate over them while the client is modified.
 func (c *DefaultClient) Subscriptions() map[string]struct{} {
 	c.mux.RLock()
 	defer c.mux.RUnlock()
 
-	return c.subscriptions
+	result := make(map[string]struct{}, len(c.subscriptions))
+	for s := range c.subscriptions {
+		result[s] = struct{}{}
+	}
+
+	return result
 }
 
 // Subscribe implements the [Client.Subscribe] interface method.
@@ -0,0 +1,89 @@
+package types
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"math"
+)
+
+// EarthRadius is the mean Earth radius in meters used for the distance calculations.
+const EarthRadius float64 = 6371008.8
+
+// GeoPoint defines a geographic coordinates (longitude and latitude) struct
+// that is safe for json and db read/write.
+type GeoPoint struct {
+	Lon float64 `form:"lon" json:"lon"`
+	Lat float64 `form:"lat" json:"lat"`
+}
+
+// String returns the string representation of the current GeoPoint instance.
+func (p GeoPoint) String() string {
+	raw, _ := json.Marshal(p)
+	return string(raw)
+}
+
+// IsZero checks whether the current GeoPoint has zero coordinates.
+func (p GeoPoint) IsZero() bool {
+	return p.Lon == 0 && p.Lat == 0
+}
+
+// DistanceTo returns the great-circle distance in meters between
+// the current and the provided GeoPoint (using the haversine formula).
+func (p GeoPoint) DistanceTo(other GeoPoint) float64 {
+	lat1 := p.Lat * math.Pi / 180
+	lat2 := other.Lat * math.Pi / 180
+	dLat := lat2 - lat1
+	dLon := (other.Lon - p.Lon) * math.Pi / 180
+
+	a := math.Pow(math.Sin(dLat/2), 2) +
+		math.Cos(lat1)*math.Cos(lat2)*math.Pow(math.Sin(dLon/2), 2)
+
+	return 2 * EarthRadius * math.Asin(math.Sqrt(a))
+}
+
+// Value implements the [driver.Valuer] interface.
+func (p GeoPoint) Value() (driver.Value, error) {
+	data, err := json.Marshal(p)
+
+	return string(data), err
+}
+
+// Scan implements [sql.Scanner] interface to scan the provided value
+// into the current `GeoPoint` instance.
+func (p *GeoPoint) Scan(value any) error {
+	var data []byte
+	switch v := value.(type) {
+	case nil:
+		// no cast needed
+	case GeoPoint:
+		*p = v
+		return nil
+	case *GeoPoint:
+		if v != nil {
+			*p = *v
+		}
+		return nil
+	case []byte:
+		data = v
+	case string:
+		data = []byte(v)
+	case map[string]any, JsonMap, JsonRaw:
+		var err error
+		data, err = json.Marshal(v)
+		if err != nil {
+			return err
+		}
+	default:
+		return fmt.Errorf("Failed to unmarshal GeoPoint value: %q.", value)
+	}
+
+	if len(data) == 0 {
+		data = []byte("{}")
+	}
+
+	// reset
+	*p = GeoPoint{}
+
+	return json.Unmarshal(data, p)
+}
@@ -0,0 +1,128 @@
+package types_test
+
+import (
+	"math"
+	"testing"
+
+	"github.com/unkod/space/tools/types"
+)
+
+func TestGeoPointString(t *testing.T) {
+	scenarios := []struct {
+		point    types.GeoPoint
+		expected string
+	}{
+		{types.GeoPoint{}, `{"lon":0,"lat":0}`},
+		{types.GeoPoint{Lon: -122.4, Lat: 37.7}, `{"lon":-122.4,"lat":37.7}`},
+	}
+
+	for i, s := range scenarios {
+		if v := s.point.String(); v != s.expected {
+			t.Errorf("(%d) Expected %s, got %s", i, s.expected, v)
+		}
+	}
+}
+
+func TestGeoPointIsZero(t *testing.T) {
+	scenarios := []struct {
+		point    types.GeoPoint
+		expected bool
+	}{
+		{types.GeoPoint{}, true},
+		{types.GeoPoint{Lon: 1}, false},
+		{types.GeoPoint{Lat: 1}, false},
+	}
+
+	for i, s := range scenarios {
+		if v := s.point.IsZero(); v != s.expected {
+			t.Errorf("(%d) Expected %v, got %v", i, s.expected, v)
+		}
+	}
+}
+
+func TestGeoPointDistanceTo(t *testing.T) {
+	scenarios := []struct {
+		a        types.GeoPoint
+		b        types.GeoPoint
+		expected float64 // in meters
+	}{
+		{types.GeoPoint{}, types.GeoPoint{}, 0},
+		{types.GeoPoint{Lon: 2.3522, Lat: 48.8566}, types.GeoPoint{Lon: 2.3522, Lat: 48.8566}, 0},
+		// 1 latitude degree
+		{types.GeoPoint{Lon: 0, Lat: 0}, types.GeoPoint{Lon: 0, Lat: 1}, 111195},
+		// Paris -> London
+		{types.GeoPoint{Lon: 2.3522, Lat: 48.8566}, types.GeoPoint{Lon: -0.1276, Lat: 51.5072}, 343530},
+		// San Francisco -> New York
+		{types.GeoPoint{Lon: -122.4194, Lat: 37.7749}, types.GeoPoint{Lon: -74.006, Lat: 40.7128}, 4129092},
+		// antipodal points
+		{types.GeoPoint{Lon: 0, Lat: 0}, types.GeoPoint{Lon: 180, Lat: 0}, math.Pi * types.EarthRadius},
+	}
+
+	for i, s := range scenarios {
+		result := s.a.DistanceTo(s.b)
+
+		if math.Abs(result-s.expected) > 1 {
+			t.Errorf("(%d) Expected ~%f, got %f", i, s.expected, result)
+		}
+
+		// the distance should be symmetric
+		if reversed := s.b.DistanceTo(s.a); math.Abs(result-reversed) > 0.000001 {
+			t.Errorf("(%d) Expected the reversed distance to be %f, got %f", i, result, reversed)
+		}
+	}
+}
+
+func TestGeoPointValue(t *testing.T) {
+	scenarios := []struct {
+		point    types.GeoPoint
+		expected string
+	}{
+		{types.GeoPoint{}, `{"lon":0,"lat":0}`},
+		{types.GeoPoint{Lon: 10.5, Lat: -20}, `{"lon":10.5,"lat":-20}`},
+	}
+
+	for i, s := range scenarios {
+		result, err := s.point.Value()
+		if err != nil {
+			t.Errorf("(%d) %v", i, err)
+			continue
+		}
+		if result != s.expected {
+			t.Errorf("(%d) Expected %s, got %v", i, s.expected, result)
+		}
+	}
+}
+
+func TestGeoPointScan(t *testing.T) {
+	scenarios := []struct {
+		value       any
+		expectError bool
+		expected    string
+	}{
+		{nil, false, `{"lon":0,"lat":0}`},
+		{"", false, `{"lon":0,"lat":0}`},
+		{"invalid", true, `{"lon":0,"lat":0}`},
+		{`{"lon":1.5,"lat":-2}`, false, `{"lon":1.5,"lat":-2}`},
+		{[]byte(`{"lat":3}`), false, `{"lon":0,"lat":3}`},
+		{map[string]any{"lon": 4, "lat": 5}, false, `{"lon":4,"lat":5}`},
+		{types.JsonMap{"lon": 6}, false, `{"lon":6,"lat":0}`},
+		{types.GeoPoint{Lon: 7, Lat: 8}, false, `{"lon":7,"lat":8}`},
+		{&types.GeoPoint{Lon: 9, Lat: 10}, false, `{"lon":9,"lat":10}`},
+		{123, true, `{"lon":0,"lat":0}`},
+	}
+
+	for i, s := range scenarios {
+		point := types.GeoPoint{}
+		err := point.Scan(s.value)
+
+		hasErr := err != nil
+		if hasErr != s.expectError {
+			t.Errorf("(%d) Expected hasErr %v, got %v (%v)", i, s.expectError, hasErr, err)
+			continue
+		}
+
+		if v := point.String(); v != s.expected {
+			t.Errorf("(%d) Expected %s, got %s", i, s.expected, v)
+		}
+	}
+}
@@ -0,0 +1,74 @@
+package types
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+)
+
+// JsonMapOf defines a map with typed values that is safe for json and db read/write.
+//
+// It is similar to [JsonMap] but could be used when all
+// map values are of the same known type, eg. JsonMapOf[int].
+type JsonMapOf[T any] map[string]T
+
+// internal alias to prevent recursion during marshalization.
+type jsonMapOfAlias[T any] JsonMapOf[T]
+
+// MarshalJSON implements the [json.Marshaler] interface.
+func (m JsonMapOf[T]) MarshalJSON() ([]byte, error) {
+	// initialize an empty map to ensure that `{}` is returned as json
+	if m == nil {
+		m = JsonMapOf[T]{}
+	}
+
+	return json.Marshal(jsonMapOfAlias[T](m))
+}
+
+// Get retrieves a single value from the current JsonMapOf[T]
+// (returns the zero T value if the key is missing).
+//
+// This helper was added primarily to assist the goja integration since custom map types
+// don't have direct access to the map keys (https://pkg.go.dev/github.com/dop251/goja#hdr-Maps_with_methods).
+func (m JsonMapOf[T]) Get(key string) T {
+	return m[key]
+}
+
+// Set sets a single value in the current JsonMapOf[T].
+//
+// This helper was added primarily to assist the goja integration since custom map types
+// don't have direct access to the map keys (https://pkg.go.dev/github.com/dop251/goja#hdr-Maps_with_methods).
+func (m JsonMapOf[T]) Set(key string, value T) {
+	m[key] = value
+}
+
+// Value implements the [driver.Valuer] interface.
+func (m JsonMapOf[T]) Value() (driver.Value, error) {
+	data, err := json.Marshal(m)
+
+	return string(data), err
+}
+
+// Scan implements [sql.Scanner] interface to scan the provided value
+// into the current JsonMapOf[T] instance.
+//
+// NULL and empty values are scanned as empty map.
+func (m *JsonMapOf[T]) Scan(value any) error {
+	var data []byte
+	switch v := value.(type) {
+	case nil:
+		// no cast needed
+	case []byte:
+		data = v
+	case string:
+		data = []byte(v)
+	default:
+		return fmt.Errorf("Failed to unmarshal JsonMapOf value: %q.", value)
+	}
+
+	if len(data) == 0 || string(data) == "null" {
+		data = []byte("{}")
+	}
+
+	return json.Unmarshal(data, m)
+}
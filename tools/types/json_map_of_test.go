@@ -0,0 +1,111 @@
+package types_test
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"testing"
+
+	"github.com/unkod/space/tools/types"
+)
+
+func TestJsonMapOfMarshalJSON(t *testing.T) {
+	scenarios := []struct {
+		json     json.Marshaler
+		expected string
+	}{
+		{types.JsonMapOf[int](nil), "{}"},
+		{types.JsonMapOf[int]{}, `{}`},
+		{types.JsonMapOf[int]{"test1": 123, "test2": 456}, `{"test1":123,"test2":456}`},
+		{types.JsonMapOf[[]string]{"test": {"a", "b"}}, `{"test":["a","b"]}`},
+	}
+
+	for i, s := range scenarios {
+		result, err := s.json.MarshalJSON()
+		if err != nil {
+			t.Errorf("(%d) %v", i, err)
+			continue
+		}
+		if string(result) != s.expected {
+			t.Errorf("(%d) Expected %s, got %s", i, s.expected, string(result))
+		}
+	}
+}
+
+func TestJsonMapOfGetAndSet(t *testing.T) {
+	m := types.JsonMapOf[int]{}
+
+	if v := m.Get("missing"); v != 0 {
+		t.Fatalf("Expected zero value, got %v", v)
+	}
+
+	m.Set("test", 123)
+
+	if v := m.Get("test"); v != 123 {
+		t.Fatalf("Expected 123, got %v", v)
+	}
+}
+
+func TestJsonMapOfValue(t *testing.T) {
+	scenarios := []struct {
+		json     driver.Valuer
+		expected driver.Value
+	}{
+		{types.JsonMapOf[int](nil), `{}`},
+		{types.JsonMapOf[int]{}, `{}`},
+		{types.JsonMapOf[string]{"test1": "a", "test2": "b"}, `{"test1":"a","test2":"b"}`},
+	}
+
+	for i, s := range scenarios {
+		result, err := s.json.Value()
+		if err != nil {
+			t.Errorf("(%d) %v", i, err)
+			continue
+		}
+		if result != s.expected {
+			t.Errorf("(%d) Expected %s, got %v", i, s.expected, result)
+		}
+	}
+}
+
+func TestJsonMapOfScan(t *testing.T) {
+	scenarios := []struct {
+		value       any
+		expectError bool
+		expectJson  string
+	}{
+		{``, false, `{}`},
+		{nil, false, `{}`},
+		{[]byte{}, false, `{}`},
+		{`null`, false, `{}`},
+		{`{}`, false, `{}`},
+		{123, true, `{}`},
+		{`""`, true, `{}`},
+		{`invalid_json`, true, `{}`},
+		{`[1,2,3]`, true, `{}`},
+		{`{"test": 1`, true, `{}`},
+		{`{"test": "a"}`, true, `{}`}, // invalid value type
+		{`{"test": 1}`, false, `{"test":1}`},
+		{[]byte(`{"a": 1, "b": 2}`), false, `{"a":1,"b":2}`},
+	}
+
+	for i, s := range scenarios {
+		m := types.JsonMapOf[int]{}
+		scanErr := m.Scan(s.value)
+
+		hasErr := scanErr != nil
+		if hasErr != s.expectError {
+			t.Errorf("(%d) Expected %v, got %v (%v)", i, s.expectError, hasErr, scanErr)
+			continue
+		}
+
+		if hasErr {
+			continue
+		}
+
+		result, _ := m.MarshalJSON()
+
+		if string(result) != s.expectJson {
+			t.Errorf("(%d) Expected %s, got %v", i, s.expectJson, string(result))
+		}
+	}
+}
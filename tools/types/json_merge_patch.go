@@ -0,0 +1,36 @@
+package types
+
+// JsonMergePatch applies the patch to the target in accordance with the
+// JSON Merge Patch algorithm (https://www.rfc-editor.org/rfc/rfc7386).
+//
+// Both values are expected to be decoded json values (aka. nil, bool,
+// float64, string, []any or map[string]any).
+//
+// In short the object patches are applied recursively per key where a nil
+// (aka. json null) value removes the target key, while any other
+// patch value (including arrays) replaces entirely the target.
+//
+// The target is not modified and a new value is returned.
+func JsonMergePatch(target any, patch any) any {
+	patchObj, ok := patch.(map[string]any)
+	if !ok {
+		return patch
+	}
+
+	targetObj, _ := target.(map[string]any)
+
+	result := make(map[string]any, len(targetObj)+len(patchObj))
+	for k, v := range targetObj {
+		result[k] = v
+	}
+
+	for k, v := range patchObj {
+		if v == nil {
+			delete(result, k)
+		} else {
+			result[k] = JsonMergePatch(result[k], v)
+		}
+	}
+
+	return result
+}
@@ -0,0 +1,62 @@
+package types_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/unkod/space/tools/types"
+)
+
+func TestJsonMergePatch(t *testing.T) {
+	// https://www.rfc-editor.org/rfc/rfc7386#appendix-A
+	scenarios := []struct {
+		target   string
+		patch    string
+		expected string
+	}{
+		{`{"a":"b"}`, `{"a":"c"}`, `{"a":"c"}`},
+		{`{"a":"b"}`, `{"b":"c"}`, `{"a":"b","b":"c"}`},
+		{`{"a":"b"}`, `{"a":null}`, `{}`},
+		{`{"a":"b","b":"c"}`, `{"a":null}`, `{"b":"c"}`},
+		{`{"a":["b"]}`, `{"a":"c"}`, `{"a":"c"}`},
+		{`{"a":"c"}`, `{"a":["b"]}`, `{"a":["b"]}`},
+		{`{"a":{"b":"c"}}`, `{"a":{"b":"d","c":null}}`, `{"a":{"b":"d"}}`},
+		{`{"a":[{"b":"c"}]}`, `{"a":[1]}`, `{"a":[1]}`},
+		{`["a","b"]`, `["c","d"]`, `["c","d"]`},
+		{`{"a":"b"}`, `["c"]`, `["c"]`},
+		{`{"a":"foo"}`, `null`, `null`},
+		{`{"a":"foo"}`, `"bar"`, `"bar"`},
+		{`{"e":null}`, `{"a":1}`, `{"a":1,"e":null}`},
+		{`[1,2]`, `{"a":"b","c":null}`, `{"a":"b"}`},
+		{`{}`, `{"a":{"bb":{"ccc":null}}}`, `{"a":{"bb":{}}}`},
+		{`null`, `{"a":1}`, `{"a":1}`},
+	}
+
+	for i, s := range scenarios {
+		var target, patch any
+
+		if err := json.Unmarshal([]byte(s.target), &target); err != nil {
+			t.Fatal(err)
+		}
+
+		if err := json.Unmarshal([]byte(s.patch), &patch); err != nil {
+			t.Fatal(err)
+		}
+
+		targetBefore, _ := json.Marshal(target)
+
+		raw, err := json.Marshal(types.JsonMergePatch(target, patch))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if result := string(raw); result != s.expected {
+			t.Errorf("(%d) Expected %s, got %s", i, s.expected, result)
+		}
+
+		// the target shouldn't be modified
+		if targetAfter, _ := json.Marshal(target); string(targetAfter) != string(targetBefore) {
+			t.Errorf("(%d) Expected the target to remain %s, got %s", i, targetBefore, targetAfter)
+		}
+	}
+}
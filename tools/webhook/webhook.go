@@ -0,0 +1,305 @@
+// Package webhook implements a simple queued webhook dispatcher
+// with HMAC signed payloads and exponential backoff retries.
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/unkod/space/tools/routine"
+)
+
+// SignatureHeader is the name of the request header with the HMAC-SHA256
+// signature of the request body in the format "sha256=HEX_DIGEST".
+const SignatureHeader = "X-Webhook-Signature"
+
+// ErrQueueFull is returned when trying to send a message to a full queue.
+var ErrQueueFull = errors.New("the webhook queue is full")
+
+// ErrStopped is returned when trying to send a message to a stopped dispatcher.
+var ErrStopped = errors.New("the webhook dispatcher is stopped")
+
+// Message defines a single webhook delivery.
+type Message struct {
+	// Url is the endpoint that will receive the POST request.
+	Url string
+
+	// Secret is the shared secret used to sign the Body.
+	Secret string
+
+	// Body is the raw request body (usually a json payload).
+	Body []byte
+
+	// Headers specifies optional extra request headers.
+	Headers map[string]string
+
+	// Timeout is the max duration of a single delivery attempt.
+	//
+	// Fallbacks to the dispatcher DefaultTimeout if not set.
+	Timeout time.Duration
+
+	// MaxRetries is the max number of delivery retries after the first failed attempt.
+	MaxRetries int
+}
+
+// Sign returns the HMAC-SHA256 signature of the provided body
+// in the format used by the SignatureHeader.
+func Sign(body []byte, secret string) string {
+	h := hmac.New(sha256.New, []byte(secret))
+	h.Write(body)
+
+	return "sha256=" + hex.EncodeToString(h.Sum(nil))
+}
+
+// VerifySignature reports whether signature is a valid SignatureHeader
+// value for the provided body and secret.
+func VerifySignature(body []byte, secret string, signature string) bool {
+	return hmac.Equal([]byte(Sign(body, secret)), []byte(signature))
+}
+
+// FailureFunc is called after each failed delivery attempt.
+//
+// status is the response status code (0 if no response was received)
+// and final indicates that the message will no longer be retried.
+type FailureFunc func(msg *Message, attempt int, status int, err error, final bool)
+
+type delivery struct {
+	msg     *Message
+	attempt int
+}
+
+// Dispatcher is a concurrent safe webhook messages queue.
+//
+// The queue workers are started lazily on the first Send call.
+type Dispatcher struct {
+	// Client is the http client used to deliver the messages.
+	Client *http.Client
+
+	// Workers is the number of concurrent delivery workers.
+	Workers int
+
+	// QueueSize is the max number of queued messages.
+	QueueSize int
+
+	// DefaultTimeout is the message attempt timeout if Message.Timeout is not set.
+	DefaultTimeout time.Duration
+
+	// BaseBackoff is the delay before the first retry
+	// (each next retry doubles the previous delay).
+	BaseBackoff time.Duration
+
+	// MaxBackoff is the max allowed delay between 2 retries.
+	MaxBackoff time.Duration
+
+	// OnFailure is an optional callback that is called
+	// after each failed delivery attempt.
+	OnFailure FailureFunc
+
+	mux     sync.Mutex
+	queue   chan *delivery
+	stopCh  chan struct{}
+	wg      sync.WaitGroup
+	running bool
+}
+
+// NewDispatcher creates a new Dispatcher with the default options.
+func NewDispatcher() *Dispatcher {
+	return &Dispatcher{
+		Client:         &http.Client{},
+		Workers:        2,
+		QueueSize:      1000,
+		DefaultTimeout: 10 * time.Second,
+		BaseBackoff:    2 * time.Second,
+		MaxBackoff:     5 * time.Minute,
+	}
+}
+
+// Send queues the provided message for delivery.
+//
+// It doesn't block and returns ErrQueueFull if the queue is full.
+func (d *Dispatcher) Send(msg *Message) error {
+	d.mux.Lock()
+	defer d.mux.Unlock()
+
+	d.start()
+
+	return d.enqueue(&delivery{msg: msg, attempt: 1})
+}
+
+// Stop stops the queue workers and discards all
+// pending messages and scheduled retries.
+//
+// It blocks until the in progress deliveries complete.
+// The dispatcher will be restarted on the next Send call.
+func (d *Dispatcher) Stop() {
+	d.mux.Lock()
+	if !d.running {
+		d.mux.Unlock()
+		return
+	}
+	d.running = false
+	close(d.stopCh)
+	d.mux.Unlock()
+
+	d.wg.Wait()
+}
+
+// start initializes the queue and its workers (if not already).
+//
+// It must be called while holding the dispatcher lock.
+func (d *Dispatcher) start() {
+	if d.running {
+		return
+	}
+
+	d.running = true
+	d.queue = make(chan *delivery, d.QueueSize)
+	d.stopCh = make(chan struct{})
+
+	workers := d.Workers
+	if workers <= 0 {
+		workers = 1
+	}
+
+	for i := 0; i < workers; i++ {
+		routine.FireAndForget(func() {
+			d.work(d.queue, d.stopCh)
+		}, &d.wg)
+	}
+}
+
+// enqueue adds the delivery to the current queue.
+//
+// It must be called while holding the dispatcher lock.
+func (d *Dispatcher) enqueue(item *delivery) error {
+	if !d.running {
+		return ErrStopped
+	}
+
+	select {
+	case d.queue <- item:
+		return nil
+	default:
+		return ErrQueueFull
+	}
+}
+
+func (d *Dispatcher) work(queue chan *delivery, stopCh chan struct{}) {
+	for {
+		select {
+		case <-stopCh:
+			return
+		case item := <-queue:
+			d.process(item, stopCh)
+		}
+	}
+}
+
+func (d *Dispatcher) process(item *delivery, stopCh chan struct{}) {
+	status, err := d.deliver(item.msg)
+	if err == nil {
+		return
+	}
+
+	final := item.attempt > item.msg.MaxRetries
+
+	if d.OnFailure != nil {
+		d.OnFailure(item.msg, item.attempt, status, err, final)
+	}
+
+	if final {
+		return
+	}
+
+	retry := &delivery{msg: item.msg, attempt: item.attempt + 1}
+
+	timer := time.NewTimer(d.backoff(item.attempt))
+
+	routine.FireAndForget(func() {
+		defer timer.Stop()
+
+		select {
+		case <-stopCh:
+			// dispatcher stopped
+		case <-timer.C:
+			var enqueueErr error
+
+			d.mux.Lock()
+			// the dispatcher could have been restarted in the meantime
+			if d.stopCh == stopCh {
+				enqueueErr = d.enqueue(retry)
+			}
+			d.mux.Unlock()
+
+			if enqueueErr != nil && d.OnFailure != nil {
+				d.OnFailure(retry.msg, retry.attempt, 0, enqueueErr, true)
+			}
+		}
+	}, &d.wg)
+}
+
+// backoff returns the delay before the retry of the specified failed attempt.
+func (d *Dispatcher) backoff(attempt int) time.Duration {
+	delay := d.BaseBackoff
+
+	for i := 1; i < attempt && delay < d.MaxBackoff; i++ {
+		delay *= 2
+	}
+
+	if d.MaxBackoff > 0 && delay > d.MaxBackoff {
+		return d.MaxBackoff
+	}
+
+	return delay
+}
+
+// deliver sends a single POST request with the message data.
+func (d *Dispatcher) deliver(msg *Message) (int, error) {
+	timeout := msg.Timeout
+	if timeout <= 0 {
+		timeout = d.DefaultTimeout
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, msg.Url, bytes.NewReader(msg.Body))
+	if err != nil {
+		return 0, err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range msg.Headers {
+		req.Header.Set(k, v)
+	}
+	req.Header.Set(SignatureHeader, Sign(msg.Body, msg.Secret))
+
+	client := d.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	res, err := client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer res.Body.Close()
+
+	// drain the body to allow connection reuse
+	io.Copy(io.Discard, io.LimitReader(res.Body, 1<<20))
+
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		return res.StatusCode, fmt.Errorf("unexpected response status %d", res.StatusCode)
+	}
+
+	return res.StatusCode, nil
+}
@@ -0,0 +1,258 @@
+package webhook_test
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/unkod/space/tools/webhook"
+)
+
+func TestSignAndVerifySignature(t *testing.T) {
+	body := []byte(`{"test":123}`)
+
+	signature := webhook.Sign(body, "secret")
+
+	expected := "sha256=427d07dc601006a874fdf1414954884feaea157c94644545d30d61a60d8529db"
+	if signature != expected {
+		t.Fatalf("Expected signature %q, got %q", expected, signature)
+	}
+
+	scenarios := []struct {
+		body      []byte
+		secret    string
+		signature string
+		expected  bool
+	}{
+		{body, "secret", signature, true},
+		{body, "secret2", signature, false},
+		{[]byte(`{"test":1234}`), "secret", signature, false},
+		{body, "secret", "", false},
+		{body, "secret", "sha256=invalid", false},
+	}
+
+	for i, s := range scenarios {
+		if result := webhook.VerifySignature(s.body, s.secret, s.signature); result != s.expected {
+			t.Errorf("(%d) Expected %v, got %v", i, s.expected, result)
+		}
+	}
+}
+
+func TestDispatcherSend(t *testing.T) {
+	body := []byte(`{"test":123}`)
+
+	received := make(chan *http.Request, 1)
+	receivedBody := make(chan []byte, 1)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		raw, _ := io.ReadAll(r.Body)
+		received <- r
+		receivedBody <- raw
+	}))
+	defer server.Close()
+
+	d := webhook.NewDispatcher()
+	defer d.Stop()
+
+	d.OnFailure = func(msg *webhook.Message, attempt int, status int, err error, final bool) {
+		t.Errorf("Unexpected failure: %v", err)
+	}
+
+	err := d.Send(&webhook.Message{
+		Url:     server.URL,
+		Secret:  "secret",
+		Body:    body,
+		Headers: map[string]string{"X-Test": "test"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case r := <-received:
+		raw := <-receivedBody
+
+		if r.Method != http.MethodPost {
+			t.Fatalf("Expected POST method, got %s", r.Method)
+		}
+		if string(raw) != string(body) {
+			t.Fatalf("Expected body %s, got %s", body, raw)
+		}
+		if v := r.Header.Get("X-Test"); v != "test" {
+			t.Fatalf("Expected X-Test header, got %q", v)
+		}
+		if v := r.Header.Get("Content-Type"); v != "application/json" {
+			t.Fatalf("Expected json content type, got %q", v)
+		}
+		if !webhook.VerifySignature(raw, "secret", r.Header.Get(webhook.SignatureHeader)) {
+			t.Fatalf("Invalid signature %q", r.Header.Get(webhook.SignatureHeader))
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("The webhook was not delivered")
+	}
+}
+
+func TestDispatcherRetries(t *testing.T) {
+	var mux sync.Mutex
+	var calls int
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mux.Lock()
+		calls++
+		current := calls
+		mux.Unlock()
+
+		if current <= 2 {
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}))
+	defer server.Close()
+
+	d := webhook.NewDispatcher()
+	d.BaseBackoff = 10 * time.Millisecond
+	defer d.Stop()
+
+	type failure struct {
+		attempt int
+		status  int
+		final   bool
+	}
+
+	failures := make(chan failure, 10)
+
+	d.OnFailure = func(msg *webhook.Message, attempt int, status int, err error, final bool) {
+		failures <- failure{attempt, status, final}
+	}
+
+	// succeed on the third attempt
+	if err := d.Send(&webhook.Message{Url: server.URL, MaxRetries: 3}); err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 1; i <= 2; i++ {
+		select {
+		case f := <-failures:
+			if f.attempt != i || f.status != http.StatusInternalServerError || f.final {
+				t.Fatalf("(%d) Unexpected failure %v", i, f)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("(%d) Missing failure callback", i)
+		}
+	}
+
+	time.Sleep(100 * time.Millisecond)
+
+	mux.Lock()
+	if calls != 3 {
+		t.Fatalf("Expected 3 calls, got %d", calls)
+	}
+	mux.Unlock()
+
+	select {
+	case f := <-failures:
+		t.Fatalf("Unexpected failure %v", f)
+	default:
+	}
+
+	// exhaust the retries
+	mux.Lock()
+	calls = -10 // always fail
+	mux.Unlock()
+
+	if err := d.Send(&webhook.Message{Url: server.URL, MaxRetries: 1}); err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 1; i <= 2; i++ {
+		select {
+		case f := <-failures:
+			if f.attempt != i || f.final != (i == 2) {
+				t.Fatalf("(%d) Unexpected failure %v", i, f)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("(%d) Missing failure callback", i)
+		}
+	}
+}
+
+func TestDispatcherTimeout(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(200 * time.Millisecond)
+	}))
+	defer server.Close()
+
+	d := webhook.NewDispatcher()
+	defer d.Stop()
+
+	failures := make(chan error, 1)
+
+	d.OnFailure = func(msg *webhook.Message, attempt int, status int, err error, final bool) {
+		failures <- err
+	}
+
+	if err := d.Send(&webhook.Message{Url: server.URL, Timeout: 10 * time.Millisecond}); err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case err := <-failures:
+		if err == nil {
+			t.Fatal("Expected timeout error")
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Expected the delivery to timeout")
+	}
+}
+
+func TestDispatcherQueueFullAndStop(t *testing.T) {
+	started := make(chan struct{}, 10)
+	release := make(chan struct{})
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		started <- struct{}{}
+		<-release
+	}))
+	defer server.Close()
+
+	d := webhook.NewDispatcher()
+	d.Workers = 1
+	d.QueueSize = 1
+
+	// stop before start should be no-op
+	d.Stop()
+
+	// processed by the worker
+	if err := d.Send(&webhook.Message{Url: server.URL}); err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case <-started:
+	case <-time.After(5 * time.Second):
+		t.Fatal("The webhook was not delivered")
+	}
+
+	// queued
+	if err := d.Send(&webhook.Message{Url: server.URL}); err != nil {
+		t.Fatal(err)
+	}
+
+	// queue full
+	if err := d.Send(&webhook.Message{Url: server.URL}); err != webhook.ErrQueueFull {
+		t.Fatalf("Expected ErrQueueFull, got %v", err)
+	}
+
+	close(release)
+
+	d.Stop()
+
+	// should be restarted
+	if err := d.Send(&webhook.Message{Url: server.URL}); err != nil {
+		t.Fatalf("Expected the dispatcher to be restarted, got %v", err)
+	}
+
+	d.Stop()
+}